@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/positronico/snapem/internal/cli"
+	"github.com/positronico/snapem/internal/errors"
 )
 
 // Version information (set by ldflags during build)
@@ -15,8 +16,9 @@ var (
 
 func main() {
 	cli.SetVersionInfo(version, commit, date)
+	cli.SetArgs(os.Args[1:])
 
 	if err := cli.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(errors.ExitCodeFor(err))
 	}
 }
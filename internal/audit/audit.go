@@ -0,0 +1,207 @@
+// Package audit keeps an append-only JSON-lines log of what snapem scanned
+// and installed, and of every decision to block or force past a block.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// Decision is the outcome recorded for a command
+type Decision string
+
+const (
+	// DecisionAllowed records a scan that passed policy or an install that
+	// ran without a block
+	DecisionAllowed Decision = "allowed"
+	// DecisionBlocked records a scan or install stopped by policy
+	DecisionBlocked Decision = "blocked"
+	// DecisionForced records an install that proceeded past a block
+	DecisionForced Decision = "forced"
+)
+
+// Entry is one line of the audit log
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Project string    `json:"project"`
+	User    string    `json:"user,omitempty"`
+
+	// Packages are the packages named on the command line
+	Packages []string `json:"packages,omitempty"`
+
+	// Scanned is the number of packages scanned, 0 if the scan was skipped
+	Scanned  int      `json:"scanned"`
+	Findings Summary  `json:"findings"`
+	Flagged  []string `json:"flagged,omitempty"` // name@version of packages with findings
+
+	Decision Decision `json:"decision"`
+	Reason   string   `json:"reason,omitempty"`
+
+	// Forced is set when --force or an interactive override was used, and
+	// Unsecure when the scan ran without malware detection
+	Forced   bool `json:"forced"`
+	Unsecure bool `json:"unsecure"`
+
+	// PolicySets are the --policy-set overrides the command ran with, such
+	// as cve.high=warn
+	PolicySets []string `json:"policy_sets,omitempty"`
+}
+
+// Summary counts the findings of a scan
+type Summary struct {
+	Total    int `json:"total"`
+	Malware  int `json:"malware"`
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+// Summarize counts the findings of result and lists the packages that
+// have any. A nil result is an empty summary.
+func Summarize(result *types.AggregatedResult) (Summary, []string) {
+	if result == nil {
+		return Summary{}, nil
+	}
+	s := Summary{
+		Total:    result.TotalFindings,
+		Malware:  result.CountByType(types.FindingTypeMalware) + result.CountByType(types.FindingTypeTyposquat),
+		Critical: result.CountBySeverity(types.SeverityCritical),
+		High:     result.CountBySeverity(types.SeverityHigh),
+		Medium:   result.CountBySeverity(types.SeverityMedium),
+		Low:      result.CountBySeverity(types.SeverityLow),
+	}
+
+	seen := make(map[string]bool)
+	var flagged []string
+	for f := range result.Findings() {
+		key := f.Package + "@" + f.Version
+		if !seen[key] {
+			seen[key] = true
+			flagged = append(flagged, key)
+		}
+	}
+	return s, flagged
+}
+
+// DefaultPath returns the default audit log location:
+// $XDG_DATA_HOME/snapem/audit.log, or ~/.local/share/snapem/audit.log
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "snapem", "audit.log")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "snapem", "audit.log")
+}
+
+// Log appends entries to an audit log file
+type Log struct {
+	path string
+	warn func(string)
+}
+
+// NewLog returns a log writing to path, or nil, which records nothing, if
+// path is empty. warn receives write failures, which never fail the
+// command being recorded.
+func NewLog(path string, warn func(string)) *Log {
+	if path == "" {
+		return nil
+	}
+	return &Log{path: path, warn: warn}
+}
+
+// Record appends an entry, stamping its time and user if unset
+func (l *Log) Record(e Entry) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.User == "" {
+		e.User = CurrentUser()
+	}
+	if err := l.write(e); err != nil && l.warn != nil {
+		l.warn(fmt.Sprintf("Could not write the audit log %s: %v", l.path, err))
+	}
+}
+
+// write appends one JSON line. The file is only readable by its owner,
+// since it lists project paths and package names.
+func (l *Log) write(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read returns the entries of the log at path, oldest first. A missing
+// log has no entries; lines that are not valid entries are skipped.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// CurrentUser names the user running snapem, for the log
+func CurrentUser() string {
+	for _, name := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if u := os.Getenv(name); u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// Filter selects log entries; zero fields match everything
+type Filter struct {
+	Project    string
+	Since      time.Time
+	ForcedOnly bool
+}
+
+// Match returns true if the entry passes the filter
+func (f Filter) Match(e Entry) bool {
+	if f.Project != "" && e.Project != f.Project {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	return !f.ForcedOnly || e.Forced
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	t.Setenv("USER", "alex")
+
+	result := &types.AggregatedResult{
+		TotalPackages: 12,
+		TotalFindings: 2,
+		Results: []*types.ScanResult{{Findings: []types.Finding{
+			{Package: "evil", Version: "1.0.0", Type: types.FindingTypeMalware, Severity: types.SeverityCritical},
+			{Package: "lodash", Version: "4.17.20", Type: types.FindingTypeCVE, Severity: types.SeverityHigh},
+		}}},
+	}
+	summary, flagged := Summarize(result)
+
+	log := NewLog(path, func(msg string) { t.Errorf("unexpected warning: %s", msg) })
+	log.Record(Entry{Command: "install", Project: "/app", Packages: []string{"evil"}, Scanned: 12, Findings: summary, Flagged: flagged, Decision: DecisionForced, Forced: true, PolicySets: []string{"cve.high=warn"}})
+	log.Record(Entry{Command: "scan", Project: "/other", Decision: DecisionAllowed})
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Read() = %d entries, want 2", len(entries))
+	}
+	got := entries[0]
+	if got.User != "alex" || got.Time.IsZero() || got.Decision != DecisionForced || !got.Forced {
+		t.Errorf("entry = %+v", got)
+	}
+	if got.Findings != (Summary{Total: 2, Malware: 1, Critical: 1, High: 1}) {
+		t.Errorf("findings = %+v", got.Findings)
+	}
+	if strings.Join(got.Flagged, ",") != "evil@1.0.0,lodash@4.17.20" {
+		t.Errorf("flagged = %v", got.Flagged)
+	}
+	if strings.Join(got.PolicySets, ",") != "cve.high=warn" || entries[1].PolicySets != nil {
+		t.Errorf("policy sets = %v, %v", got.PolicySets, entries[1].PolicySets)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("log mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestRecordFailureWarns(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	log := NewLog(filepath.Join(blocker, "audit.log"), func(msg string) { warnings = append(warnings, msg) })
+	log.Record(Entry{Command: "scan"})
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one", warnings)
+	}
+
+	// A disabled log records nothing
+	NewLog("", nil).Record(Entry{Command: "scan"})
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entry := Entry{Time: now, Project: "/app", Forced: false}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"project", Filter{Project: "/app"}, true},
+		{"other project", Filter{Project: "/other"}, false},
+		{"since before", Filter{Since: now.Add(-time.Hour)}, true},
+		{"since after", Filter{Since: now.Add(time.Hour)}, false},
+		{"forced only", Filter{ForcedOnly: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(entry); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
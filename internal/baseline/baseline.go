@@ -0,0 +1,136 @@
+// Package baseline records the findings a project has acknowledged, so that
+// adopting snapem on an existing project blocks only on new findings.
+package baseline
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// DefaultFile is the baseline file looked for in the project directory
+const DefaultFile = ".snapem-baseline.json"
+
+// Entry identifies an acknowledged finding. A finding matches only in the
+// same package version, so upgrading a package drops its entries.
+type Entry struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	ID      string `json:"id"`
+}
+
+// EntryFor returns the entry matching a finding. Findings without an
+// advisory ID, such as install scripts, are identified by their type.
+func EntryFor(f types.Finding) Entry {
+	id := f.ID
+	if id == "" {
+		id = string(f.Type)
+	}
+	return Entry{Package: f.Package, Version: f.Version, ID: id}
+}
+
+// Baseline is a set of acknowledged findings
+type Baseline struct {
+	entries map[Entry]bool
+}
+
+// fileData is the baseline file format
+type fileData struct {
+	Created  time.Time `json:"created"`
+	Findings []Entry   `json:"findings"`
+}
+
+// Load reads a baseline file. A missing file returns a nil Baseline, which
+// contains nothing.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored fileData
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	b := &Baseline{entries: make(map[Entry]bool, len(stored.Findings))}
+	for _, e := range stored.Findings {
+		b.entries[e] = true
+	}
+	return b, nil
+}
+
+// Write records every finding of result in a baseline file at path,
+// replacing it, and returns the number of entries written. created is
+// the time recorded in the file.
+func Write(path string, result *types.AggregatedResult, created time.Time) (int, error) {
+	seen := make(map[Entry]bool)
+	entries := []Entry{}
+	for f := range result.Findings() {
+		e := EntryFor(*f)
+		if !seen[e] {
+			seen[e] = true
+			entries = append(entries, e)
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.Package, b.Package), cmp.Compare(a.Version, b.Version), cmp.Compare(a.ID, b.ID))
+	})
+
+	data, err := json.MarshalIndent(fileData{Created: created.UTC().Truncate(time.Second), Findings: entries}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Contains returns true if the finding was acknowledged
+func (b *Baseline) Contains(f types.Finding) bool {
+	if b == nil {
+		return false
+	}
+	if b.entries[EntryFor(f)] {
+		return true
+	}
+	// An entry may name the advisory by an alias, e.g. its CVE
+	for _, alias := range f.Aliases {
+		if b.entries[Entry{Package: f.Package, Version: f.Version, ID: alias}] {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns result without the acknowledged findings, which are moved
+// to its Baselined list
+func (b *Baseline) Apply(result *types.AggregatedResult) *types.AggregatedResult {
+	if b == nil || len(b.entries) == 0 {
+		return result
+	}
+	var baselined []types.Finding
+	filtered := result.Filter(func(f *types.Finding) bool {
+		if b.Contains(*f) {
+			baselined = append(baselined, *f)
+			return false
+		}
+		return true
+	})
+	filtered.Baselined = append(filtered.Baselined, baselined...)
+	return filtered
+}
@@ -0,0 +1,83 @@
+package baseline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestWriteLoadApply(t *testing.T) {
+	cve := types.Finding{Package: "lodash", Version: "4.17.20", Type: types.FindingTypeCVE, Severity: types.SeverityHigh, ID: "GHSA-35jh-r3h4-6jhm"}
+	script := types.Finding{Package: "esbuild", Version: "0.19.0", Type: types.FindingTypeInstallScript, Severity: types.SeverityLow}
+	result := &types.AggregatedResult{
+		Results:       []*types.ScanResult{{Scanner: "osv", Findings: []types.Finding{cve, cve, script}}},
+		TotalFindings: 3,
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", DefaultFile)
+	n, err := Write(path, result, time.Now())
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Write() = %d entries, want 2 (duplicates merged)", n)
+	}
+
+	b, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	upgraded := cve
+	upgraded.Version = "4.17.21"
+	newCVE := cve
+	newCVE.ID = "GHSA-29mw-wpgm-hmr9"
+	later := &types.AggregatedResult{
+		Results:       []*types.ScanResult{{Scanner: "osv", Findings: []types.Finding{cve, script, upgraded, newCVE}}},
+		TotalFindings: 4,
+	}
+
+	applied := b.Apply(later)
+	if applied.TotalFindings != 2 {
+		t.Errorf("TotalFindings = %d, want 2", applied.TotalFindings)
+	}
+	for _, f := range applied.AllFindings() {
+		if b.Contains(f) {
+			t.Errorf("acknowledged finding %+v kept", f)
+		}
+	}
+	if len(applied.Baselined) != 2 {
+		t.Errorf("Baselined = %+v, want the two acknowledged findings", applied.Baselined)
+	}
+	if !applied.HasHigh {
+		t.Error("HasHigh = false, want true for the new and upgraded findings")
+	}
+}
+
+func TestContainsAlias(t *testing.T) {
+	b := &Baseline{entries: map[Entry]bool{{Package: "lodash", Version: "4.17.20", ID: "CVE-2021-23337"}: true}}
+
+	osv := types.Finding{Package: "lodash", Version: "4.17.20", ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}}
+	if !b.Contains(osv) {
+		t.Error("Contains() = false for a finding whose alias is acknowledged")
+	}
+	osv.Aliases = nil
+	if b.Contains(osv) {
+		t.Error("Contains() = true for a finding without the acknowledged alias")
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	b, err := Load(filepath.Join(t.TempDir(), DefaultFile))
+	if err != nil || b != nil {
+		t.Fatalf("Load() = %v, %v, want nil, nil", b, err)
+	}
+
+	// A nil baseline acknowledges nothing
+	result := &types.AggregatedResult{TotalFindings: 1}
+	if got := b.Apply(result); got != result {
+		t.Error("Apply() on a nil baseline changed the result")
+	}
+}
@@ -0,0 +1,20 @@
+package ci
+
+import (
+	"os"
+	"strings"
+)
+
+// Detected returns true when running under a CI service: GitHub Actions, or
+// any service that sets CI, as GitLab, CircleCI, Buildkite, Travis and most
+// others do
+func Detected() bool {
+	if GitHubActions() {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("CI")) {
+	case "", "0", "false", "no":
+		return false
+	}
+	return true
+}
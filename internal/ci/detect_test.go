@@ -0,0 +1,26 @@
+package ci
+
+import "testing"
+
+func TestDetected(t *testing.T) {
+	tests := []struct {
+		ci, actions string
+		want        bool
+	}{
+		{"", "", false},
+		{"true", "", true},
+		{"1", "", true},
+		{"false", "", false},
+		{"", "true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run("CI="+tt.ci+",GITHUB_ACTIONS="+tt.actions, func(t *testing.T) {
+			t.Setenv("CI", tt.ci)
+			t.Setenv("GITHUB_ACTIONS", tt.actions)
+			if got := Detected(); got != tt.want {
+				t.Errorf("Detected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
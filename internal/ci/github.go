@@ -0,0 +1,68 @@
+// Package ci detects CI environments and integrates with GitHub Actions.
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitHubActions returns true when running in a GitHub Actions job
+func GitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Output is a step output written to $GITHUB_OUTPUT
+type Output struct {
+	Name  string
+	Value string
+}
+
+// SetOutputs appends step outputs to the file named by $GITHUB_OUTPUT. It
+// does nothing outside GitHub Actions.
+func SetOutputs(outputs ...Output) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if !GitHubActions() || path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open GITHUB_OUTPUT: %w", err)
+	}
+
+	var b strings.Builder
+	for _, o := range outputs {
+		fmt.Fprintf(&b, "%s=%s\n", o.Name, o.Value)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+	}
+	return f.Close()
+}
+
+// AddMatcher writes a problem matcher to $RUNNER_TEMP and registers it by
+// printing the add-matcher workflow command to w. The returned function
+// unregisters it. Outside GitHub Actions it does nothing.
+func AddMatcher(w io.Writer, owner string, matcher []byte) (remove func(), err error) {
+	if !GitHubActions() {
+		return func() {}, nil
+	}
+
+	dir := os.Getenv("RUNNER_TEMP")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	path := filepath.Join(dir, owner+"-matcher.json")
+	if err := os.WriteFile(path, matcher, 0644); err != nil {
+		return func() {}, fmt.Errorf("failed to write problem matcher: %w", err)
+	}
+
+	fmt.Fprintf(w, "::add-matcher::%s\n", path)
+	return func() {
+		fmt.Fprintf(w, "::remove-matcher owner=%s::\n", owner)
+	}, nil
+}
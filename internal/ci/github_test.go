@@ -0,0 +1,72 @@
+package ci
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(path, []byte("earlier=step\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	if err := SetOutputs(Output{"findings_count", "3"}, Output{"blocked", "true"}); err != nil {
+		t.Fatalf("SetOutputs() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if want := "earlier=step\nfindings_count=3\nblocked=true\n"; string(data) != want {
+		t.Errorf("GITHUB_OUTPUT =\n%q\nwant\n%q", data, want)
+	}
+}
+
+func TestOutsideActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	if err := SetOutputs(Output{"blocked", "false"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("SetOutputs wrote a file outside GitHub Actions")
+	}
+
+	var buf bytes.Buffer
+	remove, err := AddMatcher(&buf, "snapem", []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove()
+	if buf.Len() != 0 {
+		t.Errorf("AddMatcher printed %q outside GitHub Actions", buf.String())
+	}
+}
+
+func TestAddMatcher(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("RUNNER_TEMP", dir)
+
+	var buf bytes.Buffer
+	remove, err := AddMatcher(&buf, "snapem", []byte(`{"problemMatcher": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove()
+
+	path := filepath.Join(dir, "snapem-matcher.json")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("matcher not written: %v", err)
+	}
+	want := "::add-matcher::" + path + "\n::remove-matcher owner=snapem::\n"
+	if buf.String() != want {
+		t.Errorf("commands =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// artifactTimestamp is the --timestamp flag
+var artifactTimestamp string
+
+// artifactTime returns the time embedded in persisted artifacts such as
+// SBOMs, baseline files and support bundles: --timestamp, else
+// SOURCE_DATE_EPOCH, else the current time. Terminal output always shows
+// the real time.
+func artifactTime(display *ui.UI) (time.Time, error) {
+	t, err := parseArtifactTime(artifactTimestamp, os.Getenv("SOURCE_DATE_EPOCH"))
+	if err != nil {
+		display.Error(err.Error())
+		return time.Time{}, errors.New(errors.ExitGeneralError, err.Error())
+	}
+	if t.IsZero() {
+		return time.Now(), nil
+	}
+	return t, nil
+}
+
+// parseArtifactTime parses a --timestamp value, Unix seconds or RFC3339,
+// falling back to SOURCE_DATE_EPOCH, Unix seconds. It returns the zero
+// time if neither is set.
+func parseArtifactTime(flag, epoch string) (time.Time, error) {
+	if flag != "" {
+		if secs, err := strconv.ParseInt(flag, 10, 64); err == nil {
+			return time.Unix(secs, 0).UTC(), nil
+		}
+		t, err := time.Parse(time.RFC3339, flag)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --timestamp %q: want Unix seconds or an RFC3339 time", flag)
+		}
+		return t.UTC(), nil
+	}
+	if epoch != "" {
+		secs, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: want Unix seconds", epoch)
+		}
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/baseline"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/sbom"
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+func TestParseArtifactTime(t *testing.T) {
+	tests := []struct {
+		flag    string
+		epoch   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", "", time.Time{}, false},
+		{"", "1700000000", time.Unix(1700000000, 0).UTC(), false},
+		{"1600000000", "1700000000", time.Unix(1600000000, 0).UTC(), false},
+		{"2024-05-01T12:00:00+02:00", "", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), false},
+		{"yesterday", "", time.Time{}, true},
+		{"", "2024-05-01", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseArtifactTime(tt.flag, tt.epoch)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseArtifactTime(%q, %q) = %v, %v, want %v (error %v)", tt.flag, tt.epoch, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestArtifactsReproducible generates each persisted artifact twice, with
+// inputs in a different order, and expects the same bytes
+func TestArtifactsReproducible(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	display := ui.NewWriter(&bytes.Buffer{}, false, true, false)
+
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.20", Ecosystem: "npm", Direct: true},
+		{Name: "chalk", Version: "5.3.0", Ecosystem: "npm", Direct: true},
+		{Name: "ansi-styles", Version: "6.2.1", Ecosystem: "npm"},
+	}
+	osv := &types.ScanResult{Scanner: "OSV", Findings: []types.Finding{
+		{Package: "lodash", Version: "4.17.20", Type: types.FindingTypeCVE, Severity: types.SeverityHigh, ID: "GHSA-35jh-r3h4-6jhm", Title: "Command injection"},
+		{Package: "chalk", Version: "5.3.0", Type: types.FindingTypeCVE, Severity: types.SeverityLow, ID: "GHSA-0000-0000-0000", Title: "Example"},
+	}}
+	socket := &types.ScanResult{Scanner: "Socket.dev", Findings: []types.Finding{
+		{Package: "ansi-styles", Version: "6.2.1", Type: types.FindingTypeMalware, Severity: types.SeverityCritical, Title: "malware"},
+	}}
+
+	generate := func(order []int, results []*types.ScanResult) map[string][]byte {
+		t.Helper()
+		out := make(map[string][]byte)
+
+		created, err := artifactTime(display)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reordered []manifest.Package
+		for _, i := range order {
+			reordered = append(reordered, packages[i])
+		}
+		var buf bytes.Buffer
+		if err := sbom.NewSPDX(sbom.Project{Name: "app", Version: "1.0.0", Tool: "snapem-test", Created: created}, reordered).Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		out["sbom"] = buf.Bytes()
+
+		result := &types.AggregatedResult{Results: results, TotalPackages: 3, TotalFindings: 3}
+		for _, format := range []string{"json", "sarif", "markdown"} {
+			renderer, err := report.Get(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := renderer.Render(&buf, &report.Report{Result: result}); err != nil {
+				t.Fatal(err)
+			}
+			out[format] = buf.Bytes()
+		}
+
+		path := filepath.Join(t.TempDir(), "baseline.json")
+		if _, err := baseline.Write(path, result, created); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out["baseline"] = data
+		return out
+	}
+
+	first := generate([]int{0, 1, 2}, []*types.ScanResult{osv, socket})
+	second := generate([]int{2, 0, 1}, []*types.ScanResult{socket, osv})
+
+	for name, data := range first {
+		if !bytes.Equal(data, second[name]) {
+			t.Errorf("%s differs between runs:\n%s\n---\n%s", name, data, second[name])
+		}
+	}
+	if !strings.Contains(string(first["sbom"]), `"created": "2023-11-14T22:13:20Z"`) {
+		t.Errorf("SBOM does not carry SOURCE_DATE_EPOCH:\n%s", first["sbom"])
+	}
+}
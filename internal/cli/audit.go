@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/notify"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// auditTrail records the decisions of one command in the audit log, and
+// reports blocks and overrides to the webhook
+type auditTrail struct {
+	log     *audit.Log
+	webhook *notify.Webhook
+	cfg     *config.Config
+	entry   audit.Entry
+
+	// result is the scan the next decision is based on, if any
+	result *scanner.AggregatedResult
+}
+
+// newAuditTrail starts the audit record of a command run in projectDir.
+// With audit.enabled off it records nothing, and with --no-notify it
+// sends nothing; failed writes and deliveries only warn.
+func newAuditTrail(cfg *config.Config, display *ui.UI, command, projectDir string, packages []string) *auditTrail {
+	var log *audit.Log
+	if cfg.Audit.Enabled {
+		log = audit.NewLog(cfg.Audit.File, display.Warning)
+	}
+	var webhook *notify.Webhook
+	if !noNotify {
+		webhook = notify.NewWebhook(cfg.Notifications.Webhook, display.Warning)
+	}
+	return &auditTrail{
+		log:     log,
+		webhook: webhook,
+		cfg:     cfg,
+		entry: audit.Entry{
+			Command:    command,
+			Project:    projectDir,
+			Packages:   packages,
+			PolicySets: appliedPolicySets,
+		},
+	}
+}
+
+// record appends a decision, with the findings of the scan behind it
+func (t *auditTrail) record(decision audit.Decision, reason string) {
+	e := t.entry
+	e.Time = time.Now().UTC()
+	e.User = audit.CurrentUser()
+	e.Decision = decision
+	e.Reason = reason
+	e.Forced = decision == audit.DecisionForced
+	if t.result != nil {
+		e.Scanned = t.result.TotalPackages
+		e.Findings, e.Flagged = audit.Summarize(t.result)
+		e.Unsecure = !t.cfg.HasSocketToken() || !t.cfg.Scanning.Socket.Enabled
+	}
+	t.log.Record(e)
+	t.webhook.Notify(context.Background(), e)
+}
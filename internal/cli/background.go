@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	logsFollow bool
+	stopGrace  time.Duration
+	cleanForce bool
+)
+
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "List snapem's containers and their ports",
+	Long: `Lists snapem's containers, running or stopped, with their published
+ports: those started with snapem run --detach, and commands running in
+other terminals.
+
+A background container is named after its project and script. The logs, attach and
+stop commands take that name, or just the script name for the current
+project.`,
+	Args: cobra.NoArgs,
+	RunE: runPs,
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <name>",
+	Short: "Show the output of a background container",
+	Long: `Shows the output of a container started with snapem run --detach.
+
+Examples:
+  snapem logs dev                  # Output of this project's dev script
+  snapem logs -f dev               # Keep following it
+  snapem logs snapem-web-dev-3fa9c1`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogs,
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop <name>...",
+	Short: "Stop and remove background containers",
+	Long: `Stops containers started with snapem run --detach and removes them.
+
+Each container gets SIGTERM, and SIGKILL if it is still running once the
+grace period (--time) has passed.
+
+Examples:
+  snapem stop dev                  # Stop this project's dev script
+  snapem stop --time 30s dev       # Give it 30 seconds to shut down`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runStop,
+}
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <name>",
+	Short: "Attach the terminal to a background container",
+	Long: `Connects the terminal to a container started with snapem run --detach.
+With Docker, ctrl-p ctrl-q detaches again and leaves it running. Apple's
+container CLI cannot reattach a terminal, so its output is followed
+instead, as with snapem logs -f.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAttach,
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove containers snapem left behind",
+	Long: `Removes the containers snapem created that are still around: stopped
+background containers, and containers left behind when snapem was
+interrupted. Every container snapem creates is named snapem-..., so no other
+containers are touched.
+
+Running containers are kept unless --force is given, which stops them
+first. That includes dev servers started with run --detach and commands
+running in other terminals.
+
+Examples:
+  snapem clean           # Remove stopped containers
+  snapem clean --force   # Stop and remove running ones too`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "also stop and remove running containers")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep streaming output until the container stops")
+	stopCmd.Flags().DurationVarP(&stopGrace, "time", "t", 10*time.Second, "how long to wait after SIGTERM before sending SIGKILL")
+
+	rootCmd.AddCommand(psCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// backgroundRuntime loads the config and the container runtime for the
+// background container commands
+func backgroundRuntime() (*ui.UI, container.Runtime, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return nil, nil, err
+	}
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return nil, nil, err
+	}
+	return display, runtime, nil
+}
+
+// containerName returns the background container a name refers to: a
+// container name as ps shows it, or a script of the current project
+func containerName(display *ui.UI, name string) (string, error) {
+	if strings.HasPrefix(name, container.NamePrefix) {
+		return name, nil
+	}
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return "", err
+	}
+	return container.DetachedName(projectDir, name), nil
+}
+
+// findContainer returns the background container with the given name, or
+// nil if there is none
+func findContainer(ctx context.Context, display *ui.UI, runtime container.Runtime, name string) (*container.Container, error) {
+	containers, err := runtime.List(ctx)
+	if err != nil {
+		display.Error(fmt.Sprintf("Failed to list containers: %v", err))
+		return nil, err
+	}
+	for _, c := range containers {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// runDetached starts a container in the background under name. A stopped
+// container left with that name is removed first; a running one is an
+// error.
+func runDetached(ctx context.Context, display *ui.UI, runtime container.Runtime, opts *container.RunOptions, name string) error {
+	existing, err := findContainer(ctx, display, runtime, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.Running {
+			display.Error(fmt.Sprintf("%s is already running", name))
+			display.Info(fmt.Sprintf("Stop it with: snapem stop %s", name))
+			return errors.New(errors.ExitGeneralError, name+" is already running")
+		}
+		if err := runtime.Stop(ctx, name, time.Second); err != nil {
+			display.Error(err.Error())
+			return err
+		}
+	}
+
+	opts.Name = name
+	opts.Detach = true
+	opts.Remove = false // keep the logs until snapem stop
+
+	display.ContainerHeader(runtime.CommandString(opts))
+
+	if err := runtime.Run(ctx, opts); err != nil {
+		return err
+	}
+
+	display.Success(fmt.Sprintf("Started %s in the background", name))
+	if len(opts.Ports) > 0 {
+		display.Info("Published ports: " + container.FormatPorts(opts.Ports))
+	}
+	display.Info(fmt.Sprintf("Follow it with: snapem logs -f %s", name))
+	display.Info(fmt.Sprintf("Stop it with: snapem stop %s", name))
+	return nil
+}
+
+func runPs(cmd *cobra.Command, args []string) error {
+	display, runtime, err := backgroundRuntime()
+	if err != nil {
+		return err
+	}
+
+	containers, err := runtime.List(cmd.Context())
+	if err != nil {
+		display.Error(fmt.Sprintf("Failed to list containers: %v", err))
+		return err
+	}
+	if len(containers) == 0 {
+		display.Info("No snapem containers (start one in the background with snapem run --detach <script>)")
+		return nil
+	}
+
+	rows := [][]string{{"NAME", "STATUS", "PORTS", "IMAGE"}}
+	for _, c := range containers {
+		ports := container.FormatPorts(c.Ports)
+		if ports == "" {
+			ports = "-"
+		}
+		rows = append(rows, []string{c.Name, c.Status, ports, c.Image})
+	}
+	for _, line := range formatColumns(rows) {
+		display.Print(line)
+	}
+	return nil
+}
+
+// formatColumns pads each column to its widest cell
+func formatColumns(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		lines[r] = strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+	return lines
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	display, runtime, err := backgroundRuntime()
+	if err != nil {
+		return err
+	}
+
+	name, err := containerName(display, args[0])
+	if err != nil {
+		return err
+	}
+	return runtime.Logs(cmd.Context(), name, logsFollow)
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	display, runtime, err := backgroundRuntime()
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		name, err := containerName(display, arg)
+		if err != nil {
+			return err
+		}
+		if err := runtime.Stop(cmd.Context(), name, stopGrace); err != nil {
+			display.Error(err.Error())
+			return err
+		}
+		display.Success(fmt.Sprintf("Stopped %s", name))
+	}
+	return nil
+}
+
+func runAttach(cmd *cobra.Command, args []string) error {
+	display, runtime, err := backgroundRuntime()
+	if err != nil {
+		return err
+	}
+
+	name, err := containerName(display, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !container.Supports(runtime, container.CapAttach) {
+		display.Warning(fmt.Sprintf("%s cannot reattach a terminal; following the output instead (ctrl+c to stop)", runtime.Name()))
+		return runtime.Logs(cmd.Context(), name, true)
+	}
+	display.Info(fmt.Sprintf("Attached to %s (ctrl-p ctrl-q to detach)", name))
+	return runtime.Attach(cmd.Context(), name)
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	display, runtime, err := backgroundRuntime()
+	if err != nil {
+		return err
+	}
+
+	containers, err := runtime.List(cmd.Context())
+	if err != nil {
+		display.Error(fmt.Sprintf("Failed to list containers: %v", err))
+		return err
+	}
+
+	removed, running := 0, 0
+	for _, c := range containers {
+		if c.Running && !cleanForce {
+			running++
+			display.Verbose(fmt.Sprintf("Keeping %s (running)", c.Name))
+			continue
+		}
+		if err := runtime.Stop(cmd.Context(), c.Name, stopGrace); err != nil {
+			display.Error(err.Error())
+			return err
+		}
+		display.Info(fmt.Sprintf("Removed %s (%s)", c.Name, c.Status))
+		removed++
+	}
+
+	if removed == 0 && running == 0 {
+		display.Success("No snapem containers to remove")
+	} else if removed > 0 {
+		display.Success(fmt.Sprintf("Removed %d container(s)", removed))
+	}
+	if running > 0 {
+		display.Info(fmt.Sprintf("%d running container(s) kept; stop them with snapem stop, or use --force", running))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/baseline"
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// noBaseline holds --no-baseline for the current invocation
+var noBaseline bool
+
+// addNoBaselineFlag registers --no-baseline on a command that scans
+func addNoBaselineFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noBaseline, "no-baseline", false, "ignore the baseline file and treat every finding as new")
+}
+
+// baselinePath returns scanning.baseline resolved against the project
+// directory, or "" if it is disabled
+func baselinePath(cfg *config.Config, projectDir string) string {
+	path := cfg.Scanning.Baseline
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectDir, path)
+}
+
+// applyBaseline removes the findings acknowledged in the project's
+// baseline file from result, unless --no-baseline is given. A baseline
+// that cannot be read is ignored with a warning, so every finding counts.
+func applyBaseline(cfg *config.Config, display *ui.UI, projectDir string, result *scanner.AggregatedResult) *scanner.AggregatedResult {
+	path := baselinePath(cfg, projectDir)
+	if noBaseline || path == "" || result == nil {
+		return result
+	}
+
+	b, err := baseline.Load(path)
+	if err != nil {
+		display.Warning(fmt.Sprintf("Ignoring the baseline: %v", err))
+		return result
+	}
+	if b == nil {
+		return result
+	}
+	display.Verbose("Using baseline " + path)
+	return b.Apply(result)
+}
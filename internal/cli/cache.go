@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/scanner/cache"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var cacheClearPM bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage snapem's caches",
+	Long: `snapem caches scan results and, with container.cache_volume, the
+package manager's downloads in scanning.cache.directory.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached scan results or package downloads",
+	Long: `Removes cached scan results, so the next scan queries every scanner
+again. With --pm, removes the npm, bun and yarn download caches mounted
+into containers instead.
+
+Examples:
+  snapem cache clear        # Forget cached scan results
+  snapem cache clear --pm   # Wipe the package manager caches`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+func init() {
+	cacheClearCmd.Flags().BoolVar(&cacheClearPM, "pm", false, "clear the package manager download caches")
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if !cacheClearPM {
+		if err := cache.New(cfg.Scanning.Cache).Clear(); err != nil {
+			display.Error(fmt.Sprintf("Failed to clear scan cache: %v", err))
+			return errors.Wrap(errors.ExitGeneralError, "failed to clear scan cache", err)
+		}
+		display.Success("Cleared cached scan results")
+		return nil
+	}
+
+	for _, name := range pkgmanager.Names {
+		dir := pkgmanager.CacheDir(cfg.Scanning.Cache.Directory, name)
+		if err := os.RemoveAll(dir); err != nil {
+			display.Error(fmt.Sprintf("Failed to clear %s cache: %v", name, err))
+			return errors.Wrap(errors.ExitGeneralError, "failed to clear "+name+" cache", err)
+		}
+		display.Verbose(fmt.Sprintf("Removed %s", dir))
+	}
+	display.Success("Cleared package manager caches")
+	return nil
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/hooks"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Clean install from the lockfile, for CI pipelines",
+	Long: `Scans the lockfile dependencies, then installs exactly what the lockfile
+records with npm ci, bun install --frozen-lockfile or yarn install
+--frozen-lockfile (--immutable for Yarn 2+), failing if package.json and the
+lockfile are out of sync.
+
+snapem ci never prompts. A missing lockfile or SOCKET_API_TOKEN is an error,
+and a blocked scan exits with code 2 unless --force is given.
+
+Examples:
+  snapem ci                    # Scan, then clean install
+  snapem ci --skip-scan        # Clean install without scanning`,
+	Args: cobra.NoArgs,
+	RunE: runCI,
+}
+
+func init() {
+	ciCmd.Flags().BoolVar(&skipScan, "skip-scan", false, "skip security scanning")
+	ciCmd.Flags().BoolVar(&force, "force", false, "install even if the scan finds blocking threats")
+	ciCmd.Flags().BoolVar(&allowUnsecure, "allow-unsecure", false, "continue without malware detection when SOCKET_API_TOKEN is not set")
+	ciCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	addPolicySetFlag(ciCmd)
+	addStrictScannersFlag(ciCmd)
+	addNoBaselineFlag(ciCmd)
+	addVolumeOptFlag(ciCmd)
+	addNpmrcFlag(ciCmd)
+
+	rootCmd.AddCommand(ciCmd)
+}
+
+func runCI(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// CI runs unattended: fail instead of asking
+	defer func(prev bool) { nonInteractive = prev }(nonInteractive)
+	nonInteractive = true
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := refuseReadOnlySource(cfg, display, "ci"); err != nil {
+		return err
+	}
+
+	applyNpmrcFlag(cfg)
+
+	if err := applyPolicySets(cfg, display); err != nil {
+		return err
+	}
+	warnExpiredAllowlist(cfg, display)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	// Check for package.json
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	// Detect package manager
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
+
+	if lockfile, ok := managerLockfile(parser, mgr.Name()); !ok {
+		display.Error(fmt.Sprintf("No %s found; snapem ci installs only from a lockfile", lockfile))
+		display.Info("Run `snapem install` to create one and commit it")
+		return errors.ManifestError("no "+lockfile+" found", nil)
+	}
+
+	hookRunner := newHookRunner(cfg, display)
+	trail := newAuditTrail(cfg, display, "ci", projectDir, nil)
+	installData := hooks.InstallData{PackageManager: mgr.Name()}
+
+	// Run security scan (unless skipped)
+	if cfg.Scanning.Enabled && !skipScan {
+		result, err := runSecurityScan(ctx, cfg, display, parser, nil, nil)
+		trail.result = result
+		if result != nil {
+			installData.Scan = report.NewDocument(result)
+			hookRunner.Fire(ctx, hooks.ScanComplete, projectDir, installData.Scan)
+		}
+		if err != nil {
+			if err := overrideScanBlock(ctx, cfg, display, hookRunner, trail, projectDir, &installData, err); err != nil {
+				return err
+			}
+		} else {
+			trail.record(audit.DecisionAllowed, "")
+		}
+	} else {
+		trail.record(audit.DecisionAllowed, "scan skipped")
+	}
+
+	// Build container options
+	ciCommand := mgr.CleanInstallCommand()
+	networkMode := container.NetworkMode(cfg.Container.Network)
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, ciCommand)
+
+	// Run in container (unless disabled)
+	if cfg.Container.Enabled && !noContainer {
+		runtime, err := newRuntime(cfg, display)
+		if err != nil {
+			return err
+		}
+
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+
+		deps, _ := parser.GetDependencies(true)
+		if err := runPreflight(cfg, display, projectDir, len(deps)); err != nil {
+			return err
+		}
+
+		display.ContainerHeader(runtime.CommandString(opts))
+
+		if err := runtime.Run(ctx, opts); err != nil {
+			return err
+		}
+
+		display.Success("Installation complete")
+		installData.Reason = ""
+		hookRunner.Fire(ctx, hooks.InstallComplete, projectDir, installData)
+	} else {
+		display.Warning("Running without container isolation (--no-container)")
+		display.Info(fmt.Sprintf("Command: %s %v", mgr.Name(), ciCommand))
+	}
+
+	return nil
+}
+
+// managerLockfile returns the lockfile a clean install with the package
+// manager needs, and whether it exists
+func managerLockfile(parser *manifest.Parser, manager string) (string, bool) {
+	switch manager {
+	case "bun":
+		return "bun.lock", parser.HasBunLockfile()
+	case "yarn":
+		return "yarn.lock", parser.HasYarnLockfile()
+	default:
+		return parser.LockfileName(), parser.HasLockfile()
+	}
+}
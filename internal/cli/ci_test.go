@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// TestCI checks that snapem ci refuses to run without a lockfile and fails a
+// blocking scan without prompting
+func TestCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile bool
+		want     int
+	}{
+		{"no lockfile", false, errors.ExitManifestError},
+		{"blocked scan", true, errors.ExitSecurityBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			if err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.lockfile {
+				lock := `{"name": "app", "lockfileVersion": 3, "packages": {"": {}, "node_modules/left-pad": {"version": "1.3.0"}}}`
+				if err := os.WriteFile(filepath.Join(project, "package-lock.json"), []byte(lock), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			t.Chdir(project)
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("GITHUB_ACTIONS", "")
+			t.Setenv("SNAPEM_SIMULATE", "1")
+			t.Setenv("SOCKET_API_TOKEN", "")
+
+			rootCmd.SetArgs([]string{"ci", "--no-container", "--simulate", "block"})
+			err := Execute()
+
+			if code := errors.ExitCodeFor(err); code != tt.want {
+				t.Fatalf("exit code = %d (%v), want %d", code, err, tt.want)
+			}
+			if nonInteractive {
+				t.Error("ci left --non-interactive set")
+			}
+		})
+	}
+}
@@ -1,14 +1,22 @@
 package cli
 
 import (
+	stderrors "errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	yaml "go.yaml.in/yaml/v3"
 
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/config"
 	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/redact"
 	"github.com/positronico/snapem/internal/ui"
 )
 
@@ -33,9 +41,62 @@ var configShowCmd = &cobra.Command{
 	RunE:  runConfigShow,
 }
 
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long: `Prints the effective value of a setting, such as
+scanning.policy.cve.high, from the config file, environment or defaults.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a configuration value",
+	Long: `Writes a setting to the active config file, creating it from the
+default template if there is none. Comments and other settings are kept.
+
+Values are checked against the setting's type: booleans take true or
+false, durations take values such as 30s or 72h, and policy actions take
+block, warn or ignore. Lists take comma-separated values; a value starting
+with + appends one item instead.`,
+	Example: `  snapem config set scanning.policy.cve.high block
+  snapem config set scanning.osv.timeout 1m
+  snapem config set scanning.policy.blocklist +event-stream`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the configuration for mistakes",
+	Long: `Checks the active config file, or the given one, together with
+SNAPEM_* environment variables and flags. Every problem is reported at once
+with its line in the file: unknown keys, values of the wrong type, policy
+actions other than block, warn or ignore, and conflicting settings.
+
+The same checks run before every command, which refuses to start with an
+invalid configuration.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigValidate,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Long: `Removes a setting from the active config file, so the default
+applies again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUnset,
+}
+
 func init() {
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configValidateCmd)
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -44,24 +105,124 @@ const defaultConfigTemplate = `# snapem Configuration
 
 # Package manager settings
 package_manager:
-  # Which package manager to use: auto, npm, bun
+  # Which package manager to use: auto, npm, bun, yarn
   preferred: auto
+  # Skip lifecycle scripts on snapem install, like --ignore-scripts
+  ignore_scripts: false
+  # Registry for snapem's metadata lookups and, when not the public one,
+  # the package manager in the container (same as --registry). Left at the
+  # public registry, registry= from .npmrc is used; @scope:registry= lines
+  # in the user and project .npmrc always are
+  registry: https://registry.npmjs.org
 
 # Security scanning settings
 scanning:
   enabled: true
 
+  # Fail when any scanner fails instead of continuing with partial results
+  strict_scanners: false
+
+  # Findings acknowledged with snapem scan --write-baseline, relative to
+  # the project; they are listed but never block. Empty disables it
+  baseline: .snapem-baseline.json
+
+  # Shared policy merged into scanning.policy: a URL (cached for offline
+  # use) or a path relative to this file. A file with "enforced: true"
+  # can only be made stricter by local settings
+  policy_file: ""
+
+  # Proxy for every request snapem makes; empty uses HTTP_PROXY and
+  # HTTPS_PROXY. NO_PROXY applies either way
+  proxy: ""
+  # Extra CA certificates (PEM) for proxies that intercept TLS
+  ca_bundle: ""
+
   # Socket.dev settings (malware detection)
   socket:
     enabled: true
     # Set SOCKET_API_TOKEN environment variable for authentication
     # Get a free API key at https://socket.dev
     timeout: 30s
+    # API endpoint; point at an internal gateway if you mirror Socket
+    base_url: https://api.socket.dev/v0
+    # Report packages whose Socket score (0-100) is below this;
+    # scanning.policy.low_score decides what happens. 0 disables it
+    min_score: 0
+    # Most packages per request; larger scans are split
+    batch_size: 1000
 
   # Google OSV settings (CVE detection)
   osv:
     enabled: true
     timeout: 30s
+    # References kept per finding (advisories and fixes first, 0 keeps all)
+    max_references: 10
+    # API endpoint; point at an internal mirror of OSV
+    base_url: https://api.osv.dev/v1
+
+  # GitHub Advisory Database (npm packages, needs GITHUB_TOKEN or token)
+  github:
+    enabled: false
+    timeout: 30s
+
+  # Offline check for names one typo away from popular npm packages
+  typosquat:
+    enabled: true
+    # Names or globs never reported as typosquats
+    allow: []
+
+  # Look up lifecycle scripts (preinstall, install, postinstall) in the npm
+  # registry; scanning.policy.install_scripts decides what happens
+  install_scripts:
+    enabled: true
+    timeout: 30s
+
+  # Check package-lock.json integrity hashes against the npm registry
+  # (snapem verify); on_install also runs it before every install
+  verify:
+    on_install: false
+    concurrency: 16
+    timeout: 30s
+
+  # Dependency tree of new packages resolved before snapem install <pkg>;
+  # max_depth 0 scans only the named packages, max_packages 0 is unlimited
+  preinstall:
+    max_depth: 25
+    max_packages: 2000
+    timeout: 2m
+
+  # Registry metadata checks for packages named on snapem install:
+  # recent releases, few downloads, a single maintainer, and maintainer
+  # email domain changes. Severities: critical, high, medium, low, info
+  heuristics:
+    enabled: true
+    new_version_days: 7
+    min_weekly_downloads: 100
+    severity:
+      new_version: medium
+      low_downloads: low
+      single_maintainer: info
+      maintainer_change: high
+    timeout: 30s
+
+  # Versions deprecated on npm, reported with the maintainer's message
+  deprecated:
+    enabled: true
+    severity: low
+    timeout: 30s
+
+  # npm provenance attestations; require blocks any version without one
+  # (like snapem install --require-provenance)
+  provenance:
+    enabled: false
+    require: false
+    timeout: 30s
+
+  # Dependency freshness thresholds (snapem scan --freshness)
+  freshness:
+    aging_days: 180
+    stale_days: 730
+    # Set GITHUB_TOKEN to detect archived repositories
 
   # Result caching
   cache:
@@ -80,41 +241,165 @@ scanning:
       medium: warn
       low: ignore
 
+    # Action on license findings from Socket.dev: block, warn, ignore.
+    # Denied licenses always block; allowed licenses are never reported.
+    license:
+      action: warn
+      allowed_licenses: []
+      denied_licenses: []
+
+    # Action on packages that run preinstall, install or postinstall
+    # scripts: block, warn, ignore
+    install_scripts: warn
+
+    # Action when a version lacks the provenance attestation earlier
+    # versions had (needs scanning.provenance.enabled): block, warn, ignore
+    provenance: warn
+
+    # Quarantine package versions published less than min_release_age ago
+    # (new packages and lockfile changes on snapem install; 0 disables)
+    min_release_age: 72h
+    release_age: block
+
+    # Action on packages below scanning.socket.min_score: block, warn,
+    # ignore
+    low_score: warn
+
     # Allow user to override blocks with 'force'
     allow_override: true
 
-    # Packages to skip scanning (trusted)
+    # Packages to skip scanning (trusted): name, name@version, name@range
+    # or a scope glob like "@mycorp/*". Use an object for a temporary
+    # exception:
+    #   - package: left-pad@1.3.0
+    #     reason: reviewed by security
+    #     expires: 2026-12-31
     allowlist: []
 
-    # Packages to always block
+    # Packages to always block, e.g. event-stream@>=3.3.6
     blocklist: []
 
+  # Change the severity or type of findings, by scanner (socket, osv,
+  # github, typosquat, install_scripts, deprecated, provenance,
+  # heuristics, release_age) and then by Socket alert type,
+  # finding ID or finding type. For example, to stop protestware counting
+  # as malware:
+  #   overrides:
+  #     socket:
+  #       protestware: {severity: low, type: quality}
+  overrides: {}
+
+  # External scanners: each command reads {"packages": [...]} as JSON on
+  # stdin and writes a scan result ({"findings": [...]}) on stdout. Its
+  # findings count like any other scanner's. For example:
+  #   custom:
+  #     - name: Acme
+  #       command: acme-scan
+  #       args: [--format, snapem]
+  #       timeout: 60s
+  custom: []
+
 # Container settings
 container:
   enabled: true
 
-  # Container images by package manager
+  # Container runtime: auto (Apple container, then Docker), apple, docker
+  runtime: auto
+
+  # Container images by package manager. Unless set here, npm and yarn
+  # use the Node.js version from .nvmrc, .node-version or engines.node,
+  # falling back to node:lts-slim. Pin an image by digest, e.g.
+  # node:20-slim@sha256:<hex>, or run snapem image pin
   image:
-    npm: node:lts-slim
+    # npm: node:lts-slim
     bun: oven/bun:latest
+    # yarn: node:lts-slim
+
+  # Refuse images not pinned by digest (same as --strict-images)
+  require_digest: false
+
+  # Resource limits (override with --memory and --cpus); empty means no
+  # limit. The Apple runtime rounds fractional CPUs up
+  limits:
+    memory: ""   # e.g. 2g
+    cpus: ""     # e.g. 2
+
+  # Keep npm, bun and yarn download caches in scanning.cache.directory
+  # between containers; clear them with snapem cache clear --pm
+  cache_volume: true
+
+  # Mount your ~/.npmrc read-only so private registries authenticate (same
+  # as --with-npmrc on install and ci). Install scripts can read any tokens
+  # in it; prefer ${NPM_TOKEN} in .npmrc plus environment: [NPM_TOKEN]
+  mount_npmrc: false
+
+  # Mount the project read-only for run and exec (same as --read-only-src);
+  # node_modules and /tmp live in memory and are discarded on exit. install,
+  # ci, update, uninstall and fix refuse to run while it is set
+  read_only_source: false
+
+  # User to run as: "host" for your uid:gid, so files written to the
+  # project are owned by you; a name or uid:gid; empty for the image default
+  user: ""
 
   # Network mode: host, none
   network: host
 
-  # Environment variables to pass to container
+  # Project mount options: cached, delegated, consistent. Options the
+  # runtime does not support are skipped (see snapem -v output)
+  volume_options: []
+
+  # Environment variables to pass to the container: NAME passes the host's
+  # value if set, NAME=value sets a literal. Secret-looking values are
+  # masked in the displayed command
   environment:
     - NODE_ENV
     - NPM_TOKEN
 
+# Host checks before mounting the project (skip with --no-preflight)
+preflight:
+  enabled: true
+  # Free disk space required, as a multiple of the estimated install size
+  disk_headroom: 2
+
+# Shell commands run on events; each receives a JSON payload on stdin
+hooks:
+  timeout: 10s
+  # scan_complete: ./scripts/on-scan.sh
+  # install_blocked: ./scripts/notify.sh
+  # override_used: ""
+  # install_complete: ""
+
+# Append-only JSON-lines record of scans, installs and overrides, for
+# compliance; read it with "snapem history"
+audit:
+  enabled: true
+  # Empty for ~/.local/share/snapem/audit.log ($XDG_DATA_HOME if set)
+  file: ""
+
+# POST a JSON notification when a scan blocks a command or someone forces
+# past a block (--no-notify skips it for one run)
+notifications:
+  webhook:
+    url: ""
+    # json, or slack for a readable message in a Slack incoming webhook
+    format: json
+    timeout: 5s
+
 # UI settings
 ui:
   color: true
   verbose: false
   quiet: false
+  # text for people, or json for one event per line on stderr (--log-format)
+  log_format: text
+  # Variable names whose values are masked in output, beyond names
+  # containing TOKEN, KEY, SECRET, PASSWORD or AUTH
+  redact: []
 `
 
 func runConfigInit(cmd *cobra.Command, args []string) error {
-	display := ui.New(verbose, quiet, !noColor)
+	display := ui.New(verbose, quiet, useColor())
 
 	configPath := filepath.Join(".", "snapem.yaml")
 
@@ -138,7 +423,7 @@ func runConfigInit(cmd *cobra.Command, args []string) error {
 }
 
 func runConfigShow(cmd *cobra.Command, args []string) error {
-	display := ui.New(verbose, quiet, !noColor)
+	display := ui.New(verbose, quiet, useColor())
 
 	// Show config file location
 	configFile := viper.ConfigFileUsed()
@@ -153,11 +438,18 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	// Show key settings
 	display.Print("Package Manager:")
 	display.Print(fmt.Sprintf("  preferred: %s", viper.GetString("package_manager.preferred")))
+	display.Print(fmt.Sprintf("  ignore_scripts: %v", viper.GetBool("package_manager.ignore_scripts")))
+	display.Print(fmt.Sprintf("  registry: %s", viper.GetString("package_manager.registry")))
 
 	display.Print("")
 	display.Print("Scanning:")
 	display.Print(fmt.Sprintf("  enabled: %v", viper.GetBool("scanning.enabled")))
+	display.Print(fmt.Sprintf("  proxy: %s", redact.String(viper.GetString("scanning.proxy"))))
+	display.Print(fmt.Sprintf("  ca_bundle: %s", viper.GetString("scanning.ca_bundle")))
+	display.Print(fmt.Sprintf("  baseline: %s", viper.GetString("scanning.baseline")))
 	display.Print(fmt.Sprintf("  socket.enabled: %v", viper.GetBool("scanning.socket.enabled")))
+	display.Print(fmt.Sprintf("  socket.base_url: %s", viper.GetString("scanning.socket.base_url")))
+	display.Print(fmt.Sprintf("  socket.min_score: %d", viper.GetInt("scanning.socket.min_score")))
 
 	// Check for Socket token
 	token := os.Getenv("SOCKET_API_TOKEN")
@@ -168,7 +460,23 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	}
 
 	display.Print(fmt.Sprintf("  osv.enabled: %v", viper.GetBool("scanning.osv.enabled")))
-	display.Print(fmt.Sprintf("  policy.malware: %s", viper.GetString("scanning.policy.malware")))
+	display.Print(fmt.Sprintf("  osv.base_url: %s", viper.GetString("scanning.osv.base_url")))
+	display.Print(fmt.Sprintf("  heuristics.enabled: %v", viper.GetBool("scanning.heuristics.enabled")))
+	display.Print(fmt.Sprintf("  deprecated.enabled: %v", viper.GetBool("scanning.deprecated.enabled")))
+	cfg, err := config.Load()
+	if err == nil {
+		for _, c := range cfg.Scanning.Custom {
+			display.Print(fmt.Sprintf("  custom: %s (%s)", c.Name, strings.Join(append([]string{c.Command}, c.Args...), " ")))
+		}
+	}
+	if err != nil {
+		display.Print(fmt.Sprintf("  policy.malware: %s", viper.GetString("scanning.policy.malware")))
+		display.Print(fmt.Sprintf("  policy.install_scripts: %s", viper.GetString("scanning.policy.install_scripts")))
+		display.Print(fmt.Sprintf("  policy.min_release_age: %s (%s)", viper.GetDuration("scanning.policy.min_release_age"), viper.GetString("scanning.policy.release_age")))
+		display.Warning("  policy.allowlist: (invalid configuration; run snapem config validate)")
+	} else {
+		showPolicy(display, cfg)
+	}
 
 	display.Print("")
 	display.Print("Container:")
@@ -176,6 +484,290 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	display.Print(fmt.Sprintf("  network: %s", viper.GetString("container.network")))
 	display.Print(fmt.Sprintf("  image.npm: %s", viper.GetString("container.image.npm")))
 	display.Print(fmt.Sprintf("  image.bun: %s", viper.GetString("container.image.bun")))
+	display.Print(fmt.Sprintf("  image.yarn: %s", viper.GetString("container.image.yarn")))
+	display.Print(fmt.Sprintf("  require_digest: %v", viper.GetBool("container.require_digest")))
+	display.Print(fmt.Sprintf("  environment: %v", viper.GetStringSlice("container.environment")))
+	display.Print(fmt.Sprintf("  limits.memory: %s", viper.GetString("container.limits.memory")))
+	display.Print(fmt.Sprintf("  limits.cpus: %s", viper.GetString("container.limits.cpus")))
+	display.Print(fmt.Sprintf("  user: %s", viper.GetString("container.user")))
+	display.Print(fmt.Sprintf("  read_only_source: %v", viper.GetBool("container.read_only_source")))
+	display.Print(fmt.Sprintf("  cache_volume: %v", viper.GetBool("container.cache_volume")))
+	display.Print(fmt.Sprintf("  mount_npmrc: %v", viper.GetBool("container.mount_npmrc")))
+
+	display.Print("")
+	display.Print("Audit:")
+	display.Print(fmt.Sprintf("  enabled: %v", viper.GetBool("audit.enabled")))
+	auditFile := viper.GetString("audit.file")
+	if auditFile == "" {
+		auditFile = audit.DefaultPath()
+	}
+	display.Print(fmt.Sprintf("  file: %s", auditFile))
+
+	display.Print("")
+	display.Print("Notifications:")
+	if viper.GetString("notifications.webhook.url") != "" {
+		// Webhook URLs carry their credentials
+		display.Print(fmt.Sprintf("  webhook.url: (set, %s)", viper.GetString("notifications.webhook.format")))
+	} else {
+		display.Print("  webhook.url: (not set)")
+	}
 
 	return nil
 }
+
+// showPolicy prints the effective policy, marking the values that come
+// from scanning.policy_file
+func showPolicy(display *ui.UI, cfg *config.Config) {
+	policy := cfg.Scanning.Policy
+	src := cfg.PolicySource
+	from := func(keys ...string) string {
+		for _, key := range keys {
+			if src != nil && src.Keys[key] {
+				return " (from policy file)"
+			}
+		}
+		return ""
+	}
+
+	if src != nil {
+		var notes []string
+		if src.Enforced {
+			notes = append(notes, "enforced: local settings can only make it stricter")
+		}
+		if src.Cached {
+			notes = append(notes, "cached copy, the URL could not be fetched")
+		}
+		line := "  policy_file: " + src.Location
+		if len(notes) > 0 {
+			line += " (" + strings.Join(notes, "; ") + ")"
+		}
+		display.Print(line)
+	}
+
+	display.Print(fmt.Sprintf("  policy.malware: %s%s", policy.Malware, from("malware")))
+	for _, severity := range []string{"critical", "high", "medium", "low"} {
+		display.Print(fmt.Sprintf("  policy.cve.%s: %s%s", severity, cfg.GetCVEAction(severity), from("cve."+severity)))
+	}
+	display.Print(fmt.Sprintf("  policy.install_scripts: %s%s", policy.InstallScripts, from("install_scripts")))
+	display.Print(fmt.Sprintf("  policy.min_release_age: %s (%s)%s", policy.MinReleaseAge, policy.ReleaseAge, from("min_release_age", "release_age")))
+	display.Print(fmt.Sprintf("  policy.low_score: %s%s", policy.LowScore, from("low_score")))
+	display.Print(fmt.Sprintf("  policy.allow_override: %v%s", policy.AllowOverride, from("allow_override")))
+	if len(policy.Blocklist) > 0 {
+		display.Print(fmt.Sprintf("  policy.blocklist: %s%s", strings.Join(policy.Blocklist, ", "), from("blocklist")))
+	}
+
+	if len(policy.Allowlist) > 0 {
+		display.Print("  policy.allowlist:" + from("allowlist"))
+		now := time.Now()
+		for _, entry := range policy.Allowlist {
+			display.Print("    - " + describeAllowlistEntry(entry, now))
+		}
+	}
+	if src != nil {
+		for _, ignored := range src.Ignored {
+			display.Warning(fmt.Sprintf("  ignored %s (enforced by the policy file)", ignored))
+		}
+	}
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	display := ui.New(verbose, quiet, useColor())
+	key := args[0]
+
+	if _, err := config.KeyType(key); err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+
+	name := key[strings.LastIndex(key, ".")+1:]
+	switch v := viper.Get(key).(type) {
+	case nil:
+		return nil
+	case string:
+		if v != "" && redact.SecretName(name) {
+			v = redact.Mask
+		}
+		fmt.Println(v)
+	case map[string]any, []any, []string:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return errors.Wrap(errors.ExitGeneralError, "failed to format "+key, err)
+		}
+		fmt.Print(string(out))
+	default:
+		fmt.Println(v)
+	}
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	display := ui.New(verbose, quiet, useColor())
+	key, raw := args[0], args[1]
+
+	t, err := config.KeyType(key)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+
+	item, appendItem := strings.CutPrefix(raw, "+")
+	appendItem = appendItem && t.Kind() == reflect.Slice
+	var value any
+	if !appendItem {
+		if value, err = config.ParseValue(key, raw); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+	}
+
+	path, err := editableConfigFile()
+	if err != nil {
+		display.Error(err.Error())
+		return err
+	}
+
+	err = editConfigFile(display, path, key, func() error {
+		if appendItem {
+			return config.AppendFileValue(path, key, item)
+		}
+		return config.SetFileValue(path, key, value)
+	})
+	if err != nil {
+		return err
+	}
+
+	display.Success(fmt.Sprintf("Set %s in %s", key, path))
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	display := ui.New(verbose, quiet, useColor())
+	key := args[0]
+
+	if _, err := config.KeyType(key); err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+
+	path := activeConfigFile()
+	if _, err := os.Stat(path); err != nil {
+		display.Info(fmt.Sprintf("%s is not set (no config file)", key))
+		return nil
+	}
+
+	removed := false
+	err := editConfigFile(display, path, key, func() error {
+		var err error
+		removed, err = config.UnsetFileValue(path, key)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if removed {
+		display.Success(fmt.Sprintf("Removed %s from %s", key, path))
+	} else {
+		display.Info(fmt.Sprintf("%s is not set in %s", key, path))
+	}
+	return nil
+}
+
+// activeConfigFile returns the config file snapem reads: --config, the file
+// found on startup, or snapem.yaml in the project directory
+func activeConfigFile() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used
+	}
+	dir := workDir
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(dir, "snapem.yaml")
+}
+
+// editableConfigFile returns the active config file, creating it from the
+// default template if it does not exist
+func editableConfigFile() (string, error) {
+	path := activeConfigFile()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.WriteFile(path, []byte(defaultConfigTemplate), 0644); err != nil {
+		return "", errors.Wrap(errors.ExitGeneralError, "failed to write "+path, err)
+	}
+	return path, nil
+}
+
+// editConfigFile applies edit to the config file at path and reloads it.
+// If the result has problems with key, the file is put back as it was;
+// problems elsewhere in the file are only reported.
+func editConfigFile(display *ui.UI, path, key string, edit func() error) error {
+	old, err := os.ReadFile(path)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.Wrap(errors.ExitGeneralError, "failed to read "+path, err)
+	}
+
+	if err = edit(); err != nil {
+		err = errors.Wrap(errors.ExitGeneralError, "failed to update "+path, err)
+	} else {
+		viper.SetConfigFile(path)
+		if err = viper.ReadInConfig(); err == nil {
+			_, err = config.Load()
+		}
+		var invalid *config.ValidationError
+		if stderrors.As(err, &invalid) && !invalid.Concerns(key) {
+			display.Warning("The configuration has other problems; run snapem config validate")
+			err = nil
+		}
+		if err != nil {
+			err = errors.ConfigError(err.Error())
+		}
+	}
+	if err != nil {
+		if restoreErr := os.WriteFile(path, old, 0644); restoreErr != nil {
+			display.Warning(fmt.Sprintf("Could not restore %s: %v", path, restoreErr))
+		}
+		display.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	display := ui.New(verbose, quiet, useColor())
+
+	if len(args) == 1 {
+		if _, err := os.Stat(args[0]); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+		viper.SetConfigFile(args[0])
+		if err := viper.ReadInConfig(); err != nil {
+			// Drop the settings of the file read on startup; config.Load
+			// reports the syntax error with the rest
+			viper.ReadConfig(strings.NewReader(""))
+		}
+	}
+
+	file := viper.ConfigFileUsed()
+	cfg, err := config.Load()
+	if err != nil {
+		reportConfigError(display, err)
+		return errors.ConfigError(err.Error())
+	}
+	for _, warning := range cfg.Warnings {
+		display.Warning(warning)
+	}
+
+	if file == "" {
+		display.Success("Configuration is valid (no config file, using defaults)")
+	} else {
+		display.Success(fmt.Sprintf("%s is valid", file))
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// diffFiles are the files read from the git ref to list its dependencies:
+// package.json and every lockfile the parser understands
+var diffFiles = []string{"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock"}
+
+// changeNew labels packages that the git ref does not have
+const changeNew = "new package"
+
+// diffPackages narrows packages to those added or upgraded since the git
+// ref, and returns the change label of each by name@version. Without a
+// lockfile at the ref, every package is new.
+func diffPackages(display *ui.UI, projectDir, ref string, class manifest.DependencyClass, packages []manifest.Package) ([]manifest.Package, map[string]string, error) {
+	previous, err := packagesAtRef(projectDir, ref, class)
+	if err != nil {
+		display.Error(err.Error())
+		return nil, nil, err
+	}
+	if previous == nil {
+		display.Info(fmt.Sprintf("No lockfile at %s; every package is new", ref))
+	}
+
+	changed, changes := changedPackages(packages, previous)
+	display.Info(fmt.Sprintf("Dependency changes since %s: %d of %d packages", ref, len(changed), len(packages)))
+	return changed, changes, nil
+}
+
+// changedPackages returns the packages whose name@version is not among
+// previous, labelled "new package", or "upgraded from X" when previous has
+// other versions of the package
+func changedPackages(current, previous []manifest.Package) ([]manifest.Package, map[string]string) {
+	versions := make(map[string][]string)
+	for _, pkg := range previous {
+		if !slices.Contains(versions[pkg.Name], pkg.Version) {
+			versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+		}
+	}
+
+	var changed []manifest.Package
+	changes := make(map[string]string)
+	for _, pkg := range current {
+		old := versions[pkg.Name]
+		if slices.Contains(old, pkg.Version) {
+			continue
+		}
+		label := changeNew
+		if len(old) > 0 {
+			slices.Sort(old)
+			label = "upgraded from " + strings.Join(old, ", ")
+		}
+		changed = append(changed, pkg)
+		changes[pkg.Name+"@"+pkg.Version] = label
+	}
+	return changed, changes
+}
+
+// packagesAtRef lists the project's dependencies as of the git ref, by
+// parsing its package.json and lockfile from a temporary directory. It
+// returns nil if the ref has no lockfile.
+func packagesAtRef(projectDir, ref string, class manifest.DependencyClass) ([]manifest.Package, error) {
+	if _, err := git(projectDir, "rev-parse", "--git-dir"); err != nil {
+		return nil, errors.New(errors.ExitGeneralError, fmt.Sprintf("--diff compares against git history, but %s is not in a git repository", projectDir))
+	}
+	if _, err := git(projectDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, errors.New(errors.ExitGeneralError, fmt.Sprintf("--diff: unknown git ref %q", ref))
+	}
+
+	dir, err := os.MkdirTemp("", "snapem-diff-")
+	if err != nil {
+		return nil, errors.Wrap(errors.ExitGeneralError, "failed to create a temporary directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hasLockfile, err := writeGitDependencyFiles(projectDir, ref, dir)
+	if err != nil {
+		return nil, err
+	}
+	if !hasLockfile {
+		return nil, nil
+	}
+
+	// The lockfile lists every package; package.json only marks the direct
+	// ones, so an empty one will do if the ref lacks it
+	manifestPath := filepath.Join(dir, "package.json")
+	if _, err := os.Stat(manifestPath); err != nil {
+		if err := os.WriteFile(manifestPath, []byte("{}"), 0644); err != nil {
+			return nil, errors.Wrap(errors.ExitGeneralError, "failed to write package.json", err)
+		}
+	}
+
+	packages, err := manifest.NewParser(dir).GetDependenciesFiltered(class)
+	if err != nil {
+		return nil, errors.ManifestError("failed to parse dependencies at "+ref, err)
+	}
+	return packages, nil
+}
+
+// stagedPackages lists the project's dependencies as staged for commit, by
+// parsing its package.json and lockfile from the git index, so a
+// pre-commit hook scans what is committed rather than the working tree
+func stagedPackages(projectDir string, class manifest.DependencyClass) ([]manifest.Package, error) {
+	if _, err := git(projectDir, "rev-parse", "--git-dir"); err != nil {
+		return nil, errors.New(errors.ExitGeneralError, fmt.Sprintf("--staged reads the git index, but %s is not in a git repository", projectDir))
+	}
+
+	dir, err := os.MkdirTemp("", "snapem-staged-")
+	if err != nil {
+		return nil, errors.Wrap(errors.ExitGeneralError, "failed to create a temporary directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := writeGitDependencyFiles(projectDir, "", dir); err != nil {
+		return nil, err
+	}
+	parser := manifest.NewParser(dir)
+	if !parser.HasManifest() {
+		return nil, errors.ManifestError("no package.json staged in "+projectDir, nil)
+	}
+	packages, err := parser.GetDependenciesFiltered(class)
+	if err != nil {
+		return nil, errors.ManifestError("failed to parse the staged dependencies", err)
+	}
+	return packages, nil
+}
+
+// writeGitDependencyFiles copies the project's dependency files as of the
+// git ref into dir, reading the index when ref is empty, and reports
+// whether a lockfile was among them
+func writeGitDependencyFiles(projectDir, ref, dir string) (bool, error) {
+	source := ref
+	if ref == "" {
+		source = "the git index"
+	}
+
+	hasLockfile := false
+	for _, name := range diffFiles {
+		// A "./" path is relative to the project directory, which may be
+		// below the repository root
+		data, err := git(projectDir, "show", ref+":./"+name)
+		if err != nil {
+			continue // not in the ref
+		}
+		if name != "package.json" {
+			hasLockfile = true
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return false, errors.Wrap(errors.ExitGeneralError, "failed to write "+name+" from "+source, err)
+		}
+	}
+	return hasLockfile, nil
+}
+
+// git runs a git command in dir and returns its standard output
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
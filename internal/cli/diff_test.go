@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+func TestChangedPackages(t *testing.T) {
+	previous := []manifest.Package{
+		{Name: "lodash", Version: "4.17.20"},
+		{Name: "ms", Version: "2.1.2"},
+		{Name: "debug", Version: "4.3.4"},
+		{Name: "debug", Version: "2.6.9"},
+	}
+	current := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "ms", Version: "2.1.2"},
+		{Name: "debug", Version: "4.3.5"},
+		{Name: "left-pad", Version: "1.3.0"},
+	}
+
+	changed, changes := changedPackages(current, previous)
+
+	var names []string
+	for _, pkg := range changed {
+		names = append(names, pkg.Name)
+	}
+	if got := strings.Join(names, ","); got != "lodash,debug,left-pad" {
+		t.Errorf("changed = %s, want lodash,debug,left-pad", got)
+	}
+	want := map[string]string{
+		"lodash@4.17.21": "upgraded from 4.17.20",
+		"debug@4.3.5":    "upgraded from 2.6.9, 4.3.4",
+		"left-pad@1.3.0": "new package",
+	}
+	for key, label := range want {
+		if changes[key] != label {
+			t.Errorf("changes[%s] = %q, want %q", key, changes[key], label)
+		}
+	}
+
+	// Without a previous lockfile every package is new
+	changed, changes = changedPackages(current, nil)
+	if len(changed) != len(current) || changes["ms@2.1.2"] != "new package" {
+		t.Errorf("changedPackages(nil) = %v, %v; want every package new", changed, changes)
+	}
+}
+
+func TestScanDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	project := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(project, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", project, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	t.Chdir(project)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+	t.Cleanup(func() { scanDiff, scanStaged = "", false })
+
+	scan := func(args ...string) int {
+		t.Helper()
+		scanDiff, scanStaged = "", false
+		rootCmd.SetArgs(append([]string{"scan", "--format", "text", "--quiet", "--simulate", "block"}, args...))
+		if err := Execute(); err != nil {
+			return errors.ExitCodeFor(err)
+		}
+		return 0
+	}
+
+	write("package.json", `{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`)
+	write("package-lock.json", `{"lockfileVersion": 3, "packages": {"": {}, "node_modules/left-pad": {"version": "1.3.0"}}}`)
+
+	if code := scan("--diff"); code != errors.ExitGeneralError {
+		t.Errorf("--diff outside a git repository exit code = %d, want %d", code, errors.ExitGeneralError)
+	}
+
+	run("init", "-q")
+	run("add", "package.json")
+	run("commit", "-q", "-m", "manifest only")
+
+	// No lockfile at HEAD: left-pad is new, and the simulated finding blocks
+	if code := scan("--diff"); code != errors.ExitSecurityBlock {
+		t.Errorf("--diff without a lockfile at the ref exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+
+	// Once committed, nothing has changed, so nothing is scanned
+	run("add", "package-lock.json")
+	run("commit", "-q", "-m", "lockfile")
+	if code := scan("--diff"); code != 0 {
+		t.Errorf("--diff with no changes exit code = %d, want 0", code)
+	}
+	if code := scan("--diff=HEAD~1"); code != errors.ExitSecurityBlock {
+		t.Errorf("--diff=HEAD~1 exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+	if code := scan("--diff=no-such-ref"); code != errors.ExitGeneralError {
+		t.Errorf("--diff with an unknown ref exit code = %d, want %d", code, errors.ExitGeneralError)
+	}
+
+	// --staged reads the index, so an unstaged upgrade is not scanned
+	write("package-lock.json", `{"lockfileVersion": 3, "packages": {"": {}, "node_modules/left-pad": {"version": "1.3.1"}}}`)
+	if code := scan("--diff"); code != errors.ExitSecurityBlock {
+		t.Errorf("--diff with an unstaged upgrade exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+	if code := scan("--staged", "--diff"); code != 0 {
+		t.Errorf("--staged --diff with an unstaged upgrade exit code = %d, want 0", code)
+	}
+	run("add", "package-lock.json")
+	if code := scan("--staged", "--diff"); code != errors.ExitSecurityBlock {
+		t.Errorf("--staged --diff with a staged upgrade exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+	if code := scan("--staged", "left-pad"); code != errors.ExitGeneralError {
+		t.Errorf("--staged with package arguments exit code = %d, want %d", code, errors.ExitGeneralError)
+	}
+}
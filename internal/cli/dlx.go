@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/hooks"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+var (
+	dlxPackage      string
+	dlxNoNetwork    bool
+	dlxPublishPorts []string
+)
+
+var dlxCmd = &cobra.Command{
+	Use:     "dlx <package[@version]> [args...]",
+	Aliases: []string{"x"},
+	Short:   "Scan a package and run its binary in a container, like npx",
+	Long: `Resolves a package and its dependencies, scans them, and runs the
+package's binary inside an isolated container with npx --yes (bunx for bun,
+yarn dlx for yarn berry). Nothing is added to package.json.
+
+The project directory is mounted at /app, so the binary can read and write
+the project. A scan that blocks stops the command; --force or
+scanning.policy.allow_override lets you continue, as with install.
+
+The binary runs the version that was scanned. Use --package when the binary
+is named differently from its package.
+
+Examples:
+  snapem dlx cowsay hello                      # Run cowsay's binary
+  snapem x create-vite@5 my-app                # Alias, pinned version
+  snapem dlx --package @angular/cli ng new app # Binary from another package
+  snapem dlx -p 8080 http-server               # Publish a port
+  snapem dlx --no-network prettier --check .   # Run without network`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDlx,
+}
+
+func init() {
+	dlxCmd.Flags().SetInterspersed(false) // flags after the package go to its binary
+	dlxCmd.Flags().StringVar(&dlxPackage, "package", "", "package providing the binary, when it differs from the binary name")
+	dlxCmd.Flags().BoolVar(&dlxNoNetwork, "no-network", false, "disable network access in container (the package must already be in the cache)")
+	dlxCmd.Flags().StringArrayVarP(&dlxPublishPorts, "publish", "p", nil, "publish container port to host (e.g., -p 3000 or -p 8080:80)")
+	dlxCmd.Flags().BoolVar(&skipScan, "skip-scan", false, "skip security scanning")
+	dlxCmd.Flags().BoolVar(&force, "force", false, "override security blocks")
+	dlxCmd.Flags().BoolVar(&allowUnsecure, "allow-unsecure", false, "continue without malware detection when SOCKET_API_TOKEN is not set")
+	dlxCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	addVolumeOptFlag(dlxCmd)
+	addLimitFlags(dlxCmd)
+	addEnvFlags(dlxCmd)
+
+	rootCmd.AddCommand(dlxCmd)
+}
+
+func runDlx(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := applyLimitFlags(cfg, display); err != nil {
+		return err
+	}
+
+	if err := applyPolicySets(cfg, display); err != nil {
+		return err
+	}
+	warnExpiredAllowlist(cfg, display)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	// The package comes from --package or the first argument; with
+	// --package, the first argument is the binary
+	spec, bin, binArgs := args[0], "", args[1:]
+	if dlxPackage != "" {
+		spec, bin, binArgs = dlxPackage, args[0], args[1:]
+	}
+	requested := requestedPackage(spec)
+
+	// Detect package manager
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
+
+	hookRunner := newHookRunner(cfg, display)
+	trail := newAuditTrail(cfg, display, "dlx", projectDir, []string{spec})
+	installData := hooks.InstallData{Packages: []string{spec}, PackageManager: mgr.Name()}
+
+	// Run security scan (unless skipped)
+	if cfg.Scanning.Enabled && !skipScan {
+		display.ScanningHeader()
+		if err := checkSocketToken(cfg, display); err != nil {
+			return err
+		}
+
+		packages := resolveNewPackages(ctx, cfg, display, []manifest.Package{requested})
+		spec = pinnedSpec(spec, requested.Name, packages)
+
+		result, err := scanPackages(ctx, cfg, display, projectDir, packages, nil)
+		trail.result = result
+		if result != nil {
+			installData.Scan = report.NewDocument(result)
+			hookRunner.Fire(ctx, hooks.ScanComplete, projectDir, installData.Scan)
+		}
+		if err != nil {
+			if err := overrideScanBlock(ctx, cfg, display, hookRunner, trail, projectDir, &installData, err); err != nil {
+				return err
+			}
+		} else {
+			trail.record(audit.DecisionAllowed, "")
+		}
+	} else {
+		trail.record(audit.DecisionAllowed, "scan skipped")
+	}
+
+	// Build container options
+	dlxCommand := mgr.DlxCommand(spec, bin, binArgs)
+	networkMode := container.NetworkHost
+	if dlxNoNetwork || cfg.Container.Network == "none" {
+		networkMode = container.NetworkNone
+	}
+
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, dlxCommand)
+	opts.Ports = append(opts.Ports, publishedPorts(dlxPublishPorts)...)
+
+	// Run in container (unless disabled)
+	if cfg.Container.Enabled && !noContainer {
+		runtime, err := newRuntime(cfg, display)
+		if err != nil {
+			return err
+		}
+
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+		if err := applyEnvFlags(display, opts); err != nil {
+			return err
+		}
+
+		if err := runPreflight(cfg, display, projectDir, 0); err != nil {
+			return err
+		}
+
+		display.ContainerHeader(runtime.CommandString(opts))
+
+		if err := runtime.Run(ctx, opts); err != nil {
+			return err
+		}
+	} else {
+		display.Warning("Running without container isolation (--no-container)")
+		display.Info(fmt.Sprintf("Command: %v", dlxCommand))
+	}
+
+	return nil
+}
+
+// pinnedSpec returns the package spec at the version the scan resolved, so
+// the binary that runs is the one that was scanned. The spec is returned
+// as given if the package was not resolved.
+func pinnedSpec(spec, name string, resolved []manifest.Package) string {
+	for _, pkg := range resolved {
+		if pkg.Direct && pkg.Name == name && pkg.Version != "" && pkg.Version != "latest" {
+			return pkg.Name + "@" + pkg.Version
+		}
+	}
+	return spec
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+func TestPinnedSpec(t *testing.T) {
+	resolved := []manifest.Package{
+		{Name: "create-vite", Version: "5.2.3", Direct: true},
+		{Name: "kolorist", Version: "1.8.0"},
+	}
+	tests := []struct {
+		name     string
+		spec     string
+		pkg      string
+		resolved []manifest.Package
+		want     string
+	}{
+		{"latest is pinned", "create-vite", "create-vite", resolved, "create-vite@5.2.3"},
+		{"range is pinned", "create-vite@5", "create-vite", resolved, "create-vite@5.2.3"},
+		{"transitive package is not used", "kolorist", "kolorist", resolved, "kolorist"},
+		{"unresolved keeps the spec", "@angular/cli@17", "@angular/cli", nil, "@angular/cli@17"},
+		{"unresolved latest keeps the spec", "cowsay", "cowsay", []manifest.Package{{Name: "cowsay", Version: "latest", Direct: true}}, "cowsay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pinnedSpec(tt.spec, tt.pkg, tt.resolved); got != tt.want {
+				t.Errorf("pinnedSpec(%q) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package cli
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/scanner/custom"
+	"github.com/positronico/snapem/internal/scanner/socket"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// doctorTimeout bounds each network check
+const doctorTimeout = 10 * time.Second
+
+var doctorJSON bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that snapem can work in this environment",
+	Long: `Runs a series of checks and prints pass, warn or fail for each, with a
+hint on how to fix problems:
+
+  - config file discovery and parsing
+  - platform, container runtime and its version
+  - whether the package manager images are pulled
+  - Socket.dev token validity (one authenticated request)
+  - that the commands of custom scanners (scanning.custom) are installed
+  - reachability of the registry and advisory APIs
+  - package.json and lockfile presence and version
+  - cache directory writability
+
+Network checks go through scanning.proxy (or HTTP_PROXY and HTTPS_PROXY)
+and scanning.ca_bundle. Exits non-zero if any check fails.
+
+Examples:
+  snapem doctor          # Print each check
+  snapem doctor --json   # Machine-readable results for support scripts`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "output results as JSON")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// Doctor check statuses
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCheck is the outcome of one doctor check
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// doctorReport is the --json document
+type doctorReport struct {
+	OK     bool          `json:"ok"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	checks := []doctorCheck{configFileCheck()}
+
+	cfg, err := config.Load()
+	if err != nil {
+		// Nothing else can be checked without a usable config
+		checks = append(checks, doctorCheck{
+			Name:   "Config",
+			Status: doctorFail,
+			Detail: err.Error(),
+			Hint:   "Fix the setting above; snapem config show prints the values in effect",
+		})
+		return reportDoctor(ui.New(false, false, useColor()), checks)
+	}
+
+	// Initialize UI; with --json, stdout is reserved for the document
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+	if doctorJSON {
+		display = ui.NewWriter(os.Stderr, cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	checks = append(checks, platformCheck(cfg))
+	checks = append(checks, runtimeChecks(ctx, cfg, display, projectDir)...)
+	checks = append(checks, socketTokenCheck(ctx, cfg))
+	checks = append(checks, customScannerChecks(cfg)...)
+	checks = append(checks, networkChecks(ctx, cfg)...)
+	checks = append(checks, projectChecks(projectDir)...)
+	checks = append(checks, cacheDirCheck(cfg.Scanning.Cache.Directory))
+
+	return reportDoctor(display, checks)
+}
+
+// reportDoctor prints the checks, or writes them as JSON with --json, and
+// fails if any check failed
+func reportDoctor(display *ui.UI, checks []doctorCheck) error {
+	report := doctorReport{OK: true, Checks: checks}
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			report.OK = false
+		}
+	}
+
+	if doctorJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range checks {
+			line := fmt.Sprintf("%s: %s", c.Name, c.Detail)
+			switch c.Status {
+			case doctorPass:
+				display.Success(line)
+			case doctorWarn:
+				display.Warning(line)
+			default:
+				display.Error(line)
+			}
+			if c.Hint != "" {
+				display.Info("  " + c.Hint)
+			}
+		}
+	}
+
+	if !report.OK {
+		return errors.New(errors.ExitGeneralError, "doctor found problems")
+	}
+	return nil
+}
+
+// configFileCheck reports which config file is used and whether it parses.
+// Startup ignores read errors, so the file is read again here.
+func configFileCheck() doctorCheck {
+	check := doctorCheck{Name: "Config file"}
+	err := viper.ReadInConfig()
+
+	var notFound viper.ConfigFileNotFoundError
+	switch {
+	case err == nil:
+		check.Status = doctorPass
+		check.Detail = viper.ConfigFileUsed()
+	case stderrors.As(err, &notFound):
+		check.Status = doctorWarn
+		check.Detail = "no snapem.yaml found, using defaults"
+		check.Hint = "Run snapem config init to create one"
+	default:
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		check.Hint = "Fix the syntax error, or point --config at another file"
+	}
+	return check
+}
+
+// platformCheck reports the OS and architecture, which the Apple runtime
+// requires to be macOS on Apple silicon
+func platformCheck(cfg *config.Config) doctorCheck {
+	platform := goruntime.GOOS + "/" + goruntime.GOARCH
+	if cfg.Container.Enabled && cfg.Container.Runtime == "apple" && platform != "darwin/arm64" {
+		return doctorCheck{
+			Name:   "Platform",
+			Status: doctorFail,
+			Detail: platform + ": the Apple container runtime needs macOS on Apple silicon",
+			Hint:   "Set container.runtime to docker",
+		}
+	}
+	return doctorCheck{Name: "Platform", Status: doctorPass, Detail: platform}
+}
+
+// runtimeChecks reports the container runtime and whether each package
+// manager image is present locally
+func runtimeChecks(ctx context.Context, cfg *config.Config, display *ui.UI, projectDir string) []doctorCheck {
+	if !cfg.Container.Enabled {
+		return []doctorCheck{{
+			Name:   "Container runtime",
+			Status: doctorWarn,
+			Detail: "container.enabled is false; package managers run on the host",
+			Hint:   "Set container.enabled to true to isolate installs",
+		}}
+	}
+
+	r, err := container.NewRuntime(cfg.Container.Runtime)
+	if err != nil {
+		return []doctorCheck{{Name: "Container runtime", Status: doctorFail, Detail: err.Error(), Hint: "Set container.runtime to auto, apple or docker"}}
+	}
+	if !r.IsAvailable() {
+		hint := "Install with: brew install --cask container, or install Docker"
+		if cfg.Container.Runtime == "docker" {
+			hint = "Install Docker Desktop, or start the Docker daemon"
+		}
+		return []doctorCheck{{Name: "Container runtime", Status: doctorFail, Detail: r.Name() + " not available", Hint: hint}}
+	}
+
+	checks := []doctorCheck{{Name: "Container runtime", Status: doctorPass, Detail: r.Name() + " " + container.Version(r)}}
+
+	images := slices.Compact(slices.Sorted(maps.Values(containerImages(cfg, display, projectDir))))
+	for _, image := range images {
+		check := doctorCheck{Name: "Image " + image}
+		exists, err := r.ImageExists(ctx, image)
+		switch {
+		case err != nil:
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("could not check: %v", err)
+		case exists:
+			check.Status = doctorPass
+			check.Detail = "present"
+		default:
+			check.Status = doctorWarn
+			check.Detail = "not pulled yet; the first run downloads it"
+			check.Hint = "Run snapem image pull to download it now"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// socketTokenCheck validates the Socket.dev token with one authenticated
+// request
+func socketTokenCheck(ctx context.Context, cfg *config.Config) doctorCheck {
+	check := doctorCheck{Name: "Socket.dev token"}
+	switch {
+	case !cfg.Scanning.Socket.Enabled:
+		check.Status = doctorWarn
+		check.Detail = "scanning.socket.enabled is false; malware is not detected"
+	case cfg.Scanning.Socket.APIToken == "":
+		check.Status = doctorWarn
+		check.Detail = "SOCKET_API_TOKEN not set; malware is not detected"
+		check.Hint = "Get a free API key at https://socket.dev and export SOCKET_API_TOKEN"
+	default:
+		if err := socket.NewClient(cfg.Scanning.Socket).CheckToken(ctx); err != nil {
+			check.Status = doctorFail
+			check.Detail = err.Error()
+			check.Hint = "Check SOCKET_API_TOKEN, or create a new key at https://socket.dev"
+		} else {
+			check.Status = doctorPass
+			check.Detail = "valid"
+		}
+	}
+	return check
+}
+
+// customScannerChecks reports whether the command of each custom scanner
+// can be found
+func customScannerChecks(cfg *config.Config) []doctorCheck {
+	var checks []doctorCheck
+	for _, c := range cfg.Scanning.Custom {
+		check := doctorCheck{Name: "Custom scanner " + c.Name}
+		if custom.NewScanner(c).IsAvailable() {
+			check.Status = doctorPass
+			check.Detail = c.Command
+		} else {
+			check.Status = doctorWarn
+			check.Detail = c.Command + " not found; scans run without it"
+			check.Hint = "Install " + c.Command + " or fix scanning.custom in snapem.yaml"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// networkChecks reports whether each endpoint snapem uses can be reached
+// through the configured proxy
+func networkChecks(ctx context.Context, cfg *config.Config) []doctorCheck {
+	type endpoint struct {
+		name, url string
+	}
+	endpoints := []endpoint{{"npm registry", cfg.PackageManager.Registries().URL("")}}
+	if cfg.Scanning.Socket.Enabled {
+		endpoints = append(endpoints, endpoint{"Socket.dev API", cfg.Scanning.Socket.BaseURL})
+	}
+	if cfg.Scanning.OSV.Enabled {
+		endpoints = append(endpoints, endpoint{"OSV API", cfg.Scanning.OSV.BaseURL})
+	}
+	if cfg.Scanning.GitHub.Enabled {
+		endpoints = append(endpoints, endpoint{"GitHub API", "https://api.github.com"})
+	}
+
+	checks := make([]doctorCheck, 0, len(endpoints))
+	for _, e := range endpoints {
+		route := httpclient.ProxyFor(e.url)
+		if route != "direct" {
+			route = "via " + route
+		}
+
+		if err := httpclient.Reachable(ctx, e.url, doctorTimeout); err != nil {
+			checks = append(checks, doctorCheck{
+				Name:   e.name,
+				Status: doctorFail,
+				Detail: fmt.Sprintf("%s unreachable (%s): %v", e.url, route, err),
+				Hint:   networkHint(err),
+			})
+			continue
+		}
+		checks = append(checks, doctorCheck{
+			Name:   e.name,
+			Status: doctorPass,
+			Detail: fmt.Sprintf("%s reachable (%s)", e.url, route),
+		})
+	}
+	return checks
+}
+
+// networkHint suggests a fix for a failed request
+func networkHint(err error) string {
+	var unknownCA x509.UnknownAuthorityError
+	if stderrors.As(err, &unknownCA) {
+		return "A proxy may be intercepting TLS; set scanning.ca_bundle to its CA certificate (PEM)"
+	}
+	return "Check your connection, and scanning.proxy or HTTPS_PROXY and NO_PROXY if you are behind a proxy"
+}
+
+// projectChecks reports package.json and the lockfile of the detected
+// package manager
+func projectChecks(projectDir string) []doctorCheck {
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		return []doctorCheck{{
+			Name:   "package.json",
+			Status: doctorWarn,
+			Detail: "none in " + projectDir,
+			Hint:   "Run snapem from your project directory, or pass --dir",
+		}}
+	}
+	checks := []doctorCheck{{Name: "package.json", Status: doctorPass, Detail: filepath.Join(projectDir, "package.json")}}
+
+	manager := parser.DetectPackageManager()
+	name, ok := managerLockfile(parser, manager)
+	lockfile := doctorCheck{Name: "Lockfile", Status: doctorPass, Detail: name}
+	switch {
+	case !ok:
+		lockfile.Status = doctorWarn
+		lockfile.Detail = "no " + name + "; installs are not reproducible and snapem ci refuses to run"
+		lockfile.Hint = "Run snapem install and commit the lockfile"
+	case manager == "npm":
+		lock, err := parser.ParseLockfile()
+		switch {
+		case err != nil:
+			lockfile.Status = doctorFail
+			lockfile.Detail = err.Error()
+			lockfile.Hint = "Regenerate it with snapem install"
+		case lock.LockfileVersion < 2:
+			lockfile.Status = doctorWarn
+			lockfile.Detail = fmt.Sprintf("%s (lockfileVersion %d)", name, lock.LockfileVersion)
+			lockfile.Hint = "npm 7 and later rewrite it as version 3 on the next install"
+		default:
+			lockfile.Detail = fmt.Sprintf("%s (lockfileVersion %d)", name, lock.LockfileVersion)
+		}
+	case manager == "yarn" && parser.IsYarnBerry():
+		lockfile.Detail = name + " (Yarn 2+)"
+	case manager == "yarn":
+		lockfile.Detail = name + " (Yarn classic)"
+	}
+	return append(checks, lockfile)
+}
+
+// cacheDirCheck reports whether snapem can write its cache directory
+func cacheDirCheck(dir string) doctorCheck {
+	check := doctorCheck{Name: "Cache directory", Status: doctorPass, Detail: dir}
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		var f *os.File
+		if f, err = os.CreateTemp(dir, ".doctor-*"); err == nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		check.Hint = "Fix its permissions, or set scanning.cache.directory"
+	}
+	return check
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // status of each check
+	}{
+		{"no package.json", nil, []string{doctorWarn}},
+		{"no lockfile", map[string]string{"package.json": `{}`}, []string{doctorPass, doctorWarn}},
+		{"lockfile v3", map[string]string{"package.json": `{}`, "package-lock.json": `{"lockfileVersion": 3}`}, []string{doctorPass, doctorPass}},
+		{"lockfile v1", map[string]string{"package.json": `{}`, "package-lock.json": `{"lockfileVersion": 1}`}, []string{doctorPass, doctorWarn}},
+		{"corrupt lockfile", map[string]string{"package.json": `{}`, "package-lock.json": `{`}, []string{doctorPass, doctorFail}},
+		{"yarn", map[string]string{"package.json": `{}`, "yarn.lock": ""}, []string{doctorPass, doctorPass}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			checks := projectChecks(dir)
+			if len(checks) != len(tt.want) {
+				t.Fatalf("projectChecks() = %+v, want %d checks", checks, len(tt.want))
+			}
+			for i, c := range checks {
+				if c.Status != tt.want[i] {
+					t.Errorf("%s: status = %s, want %s (%s)", c.Name, c.Status, tt.want[i], c.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheDirCheck(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapem")
+	if c := cacheDirCheck(dir); c.Status != doctorPass {
+		t.Errorf("cacheDirCheck() = %+v, want pass", c)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0644)
+	if c := cacheDirCheck(filepath.Join(file, "snapem")); c.Status != doctorFail {
+		t.Errorf("cacheDirCheck() under a file = %+v, want fail", c)
+	}
+}
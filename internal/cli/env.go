@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	// envVars holds --env values for the current invocation
+	envVars []string
+
+	// envFiles holds --env-file paths for the current invocation
+	envFiles []string
+)
+
+// addEnvFlags registers the repeatable --env and --env-file flags on a command
+func addEnvFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&envVars, "env", "e", nil, "set a container environment variable: KEY=VALUE, or KEY to pass the host's value (repeatable)")
+	cmd.Flags().StringArrayVar(&envFiles, "env-file", nil, "read container environment variables from a .env file (repeatable)")
+}
+
+// applyEnvFlags adds --env-file and then --env variables to the container
+// environment; later values win over earlier ones and over config
+func applyEnvFlags(display *ui.UI, opts *container.RunOptions) error {
+	if opts.Environment == nil {
+		opts.Environment = make(map[string]string)
+	}
+
+	for _, path := range envFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			display.Error(fmt.Sprintf("Cannot read env file %s", path))
+			return errors.Wrap(errors.ExitConfigError, "cannot read env file "+path, err)
+		}
+		env, err := container.ParseEnvFile(f)
+		f.Close()
+		if err != nil {
+			display.Error(fmt.Sprintf("Invalid env file %s: %v", path, err))
+			return errors.ConfigError(fmt.Sprintf("invalid env file %s: %v", path, err))
+		}
+		maps.Copy(opts.Environment, env)
+	}
+
+	for _, entry := range envVars {
+		name, _, _ := strings.Cut(entry, "=")
+		if !container.ValidEnvName(name) {
+			display.Error(fmt.Sprintf("Invalid --env value %q (expected KEY=VALUE or KEY)", entry))
+			return errors.ConfigError(fmt.Sprintf("invalid --env value %q", entry))
+		}
+		env := container.HostEnvironment([]string{entry}, os.LookupEnv)
+		if len(env) == 0 {
+			display.Verbose(fmt.Sprintf("--env %s: not set on the host, skipping", name))
+		}
+		maps.Copy(opts.Environment, env)
+	}
+	return nil
+}
@@ -1,14 +1,13 @@
 package cli
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/positronico/snapem/internal/config"
 	"github.com/positronico/snapem/internal/container"
-	"github.com/positronico/snapem/internal/errors"
 	"github.com/positronico/snapem/internal/pkgmanager"
 	"github.com/positronico/snapem/internal/ui"
 )
@@ -30,7 +29,8 @@ Examples:
   snapem exec node index.js       # Run node directly
   snapem exec npx prisma migrate  # Run npx command
   snapem exec sh -c "ls -la"      # Run shell command
-  snapem exec --no-network curl   # Run without network`,
+  snapem exec --no-network curl   # Run without network
+  snapem exec -e DEBUG=1 node index.js  # Set an environment variable`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runExec,
 }
@@ -39,6 +39,9 @@ func init() {
 	execCmd.Flags().BoolVar(&execNoNetwork, "no-network", false, "disable network access in container")
 	execCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
 	execCmd.Flags().StringVar(&execImage, "image", "", "custom container image")
+	addVolumeOptFlag(execCmd)
+	addLimitFlags(execCmd)
+	addEnvFlags(execCmd)
 
 	rootCmd.AddCommand(execCmd)
 }
@@ -47,23 +50,30 @@ func runExec(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
-		return errors.ConfigError(err.Error())
+		return err
 	}
 
 	// Initialize UI
-	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color && !noColor)
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
 
-	// Get current directory
-	projectDir, err := os.Getwd()
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := applyLimitFlags(cfg, display); err != nil {
+		return err
+	}
+
+	projectDir, err := projectDirectory(display)
 	if err != nil {
-		display.Error("Failed to get current directory")
-		return errors.New(errors.ExitGeneralError, "failed to get current directory")
+		return err
 	}
+	loadRegistries(cfg, display, projectDir)
 
 	// Detect package manager for default image
-	mgr := pkgmanager.Detect(projectDir, pkgMgr, cfg.Container.Image)
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
 
 	// Use custom image if specified
 	image := mgr.Image()
@@ -79,31 +89,30 @@ func runExec(cmd *cobra.Command, args []string) error {
 		networkMode = container.NetworkNone
 	}
 
-	opts := &container.RunOptions{
-		Image:       image,
-		Command:     args,
-		WorkDir:     "/app",
-		Network:     networkMode,
-		Interactive: true,
-		TTY:         true,
-		Remove:      true,
-		Volumes: []container.VolumeMount{
-			{
-				HostPath:      projectDir,
-				ContainerPath: "/app",
-				ReadOnly:      false,
-			},
-		},
-		Environment: make(map[string]string),
-	}
+	return execInContainer(ctx, cfg, display, projectDir, image, networkMode, args)
+}
+
+// execInContainer runs a command in a container with the project mounted
+// at /app, or only prints it with --no-container
+func execInContainer(ctx context.Context, cfg *config.Config, display *ui.UI, projectDir, image string, networkMode container.NetworkMode, args []string) error {
+	opts := execOptions(projectDir, image, networkMode, args)
 
 	// Run in container (unless disabled)
 	if cfg.Container.Enabled && !noContainer {
-		runtime := container.NewAppleRuntime()
-		if !runtime.IsAvailable() {
-			display.Error("Apple container runtime not available")
-			display.Info("Install with: brew install --cask container")
-			return errors.ContainerNotAvailableError()
+		runtime, err := newRuntime(cfg, display)
+		if err != nil {
+			return err
+		}
+
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+		if err := applyEnvFlags(display, opts); err != nil {
+			return err
+		}
+
+		if err := runPreflight(cfg, display, projectDir, 0); err != nil {
+			return err
 		}
 
 		display.ContainerHeader(runtime.CommandString(opts))
@@ -118,3 +127,25 @@ func runExec(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// execOptions returns the options for an interactive command in image with
+// the project mounted at /app as the working directory
+func execOptions(projectDir, image string, networkMode container.NetworkMode, args []string) *container.RunOptions {
+	return &container.RunOptions{
+		Image:       image,
+		Command:     args,
+		WorkDir:     "/app",
+		Network:     networkMode,
+		Interactive: true,
+		TTY:         true,
+		Remove:      true,
+		Volumes: []container.VolumeMount{
+			{
+				HostPath:      projectDir,
+				ContainerPath: "/app",
+				ReadOnly:      false,
+			},
+		},
+		Environment: make(map[string]string),
+	}
+}
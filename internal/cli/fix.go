@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var fixDryRun bool
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Upgrade vulnerable dependencies to fixed versions",
+	Long: `Scans the project, then upgrades each vulnerable direct dependency to
+the lowest version that fixes all of its advisories, as long as that
+version is within the dependency's semver-compatible (caret) range. The
+upgrades run in a container like snapem install, and the project is
+scanned again afterwards.
+
+Upgrades that need a new major version, advisories without a fixed
+version, and vulnerabilities in transitive dependencies are listed but
+not changed: transitive packages need an npm override or Yarn resolution.
+
+Examples:
+  snapem fix                # Apply the upgrades
+  snapem fix --dry-run      # Only print the planned upgrades`,
+	Args: cobra.NoArgs,
+	RunE: runFix,
+}
+
+func init() {
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "print the planned upgrades without applying them")
+	fixCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	addVolumeOptFlag(fixCmd)
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := refuseReadOnlySource(cfg, display, "fix"); err != nil {
+		return err
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	orch := newOrchestrator(cfg)
+	before, err := scanProject(ctx, orch, parser)
+	if err != nil {
+		return err
+	}
+
+	plan := planFixes(before)
+	showFixPlan(display, plan, findingIntroducers(parser, before))
+
+	if len(plan.upgrades) == 0 {
+		display.Info("Nothing to upgrade automatically")
+		return nil
+	}
+	if fixDryRun {
+		return nil
+	}
+
+	m, err := parser.ParseManifest()
+	if err != nil {
+		return err
+	}
+
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	var commands [][]string
+	for _, saveDev := range []bool{false, true} {
+		var specs []string
+		for _, u := range plan.upgrades {
+			if _, dev := m.DevDependencies[u.name]; dev == saveDev {
+				specs = append(specs, u.name+"@"+u.to)
+			}
+		}
+		if len(specs) > 0 {
+			commands = append(commands, mgr.InstallCommand(specs, pkgmanager.InstallOptions{SaveDev: saveDev}))
+		}
+	}
+
+	if !cfg.Container.Enabled || noContainer {
+		display.Warning("Running without container isolation (--no-container)")
+		for _, command := range commands {
+			display.Info(fmt.Sprintf("Command: %s %v", mgr.Name(), command))
+		}
+		display.Info("For security, consider using container isolation")
+		return nil
+	}
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), container.NetworkMode(cfg.Container.Network), command)
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+		display.ContainerHeader(runtime.CommandString(opts))
+		if err := runtime.Run(ctx, opts); err != nil {
+			return err
+		}
+	}
+
+	after, err := scanProject(ctx, orch, parser)
+	if err != nil {
+		return err
+	}
+	display.Print("")
+	display.Success(fmt.Sprintf("Findings: %d before, %d after", before.TotalFindings, after.TotalFindings))
+	return nil
+}
+
+// scanProject scans every dependency of the project
+func scanProject(ctx context.Context, orch *scanner.Orchestrator, parser *manifest.Parser) (*scanner.AggregatedResult, error) {
+	packages, err := parser.GetDependencies(true)
+	if err != nil {
+		return nil, errors.ManifestError("failed to parse dependencies", err)
+	}
+	result, err := orch.Scan(ctx, packages)
+	if err != nil {
+		return nil, errors.ScannerError("security", err)
+	}
+	return result, nil
+}
+
+// fixUpgrade moves a vulnerable direct dependency to a fixed version
+type fixUpgrade struct {
+	name, from, to string
+	advisories     int
+}
+
+// fixPlan sorts vulnerable packages by what snapem fix can do about them
+type fixPlan struct {
+	// upgrades are within the dependency's caret range
+	upgrades []fixUpgrade
+
+	// major upgrades need a new major version
+	major []fixUpgrade
+
+	// unfixable lists direct dependencies, as name@version, with an
+	// advisory that names no fixed version
+	unfixable []string
+
+	// transitive lists vulnerable transitive packages as name@version
+	transitive []string
+}
+
+// planFixes works out, for each direct dependency with vulnerabilities,
+// the lowest version that clears all of them
+func planFixes(result *scanner.AggregatedResult) fixPlan {
+	type vulnerable struct {
+		name, version string
+		fixes         []string
+		unfixed       bool
+		direct        bool
+	}
+	var order []string
+	byPackage := make(map[string]*vulnerable)
+	for f := range result.FindingsOfType(scanner.FindingTypeCVE) {
+		key := f.Package + "@" + f.Version
+		v, ok := byPackage[key]
+		if !ok {
+			v = &vulnerable{name: f.Package, version: f.Version, direct: f.Direct}
+			byPackage[key] = v
+			order = append(order, key)
+		}
+		if f.FixedIn == "" {
+			v.unfixed = true
+		} else {
+			v.fixes = append(v.fixes, f.FixedIn)
+		}
+	}
+	slices.Sort(order)
+
+	var plan fixPlan
+	for _, key := range order {
+		v := byPackage[key]
+		switch {
+		case !v.direct:
+			plan.transitive = append(plan.transitive, key)
+		case v.unfixed:
+			plan.unfixable = append(plan.unfixable, key)
+		default:
+			target, ok := highestVersion(v.fixes)
+			if !ok {
+				plan.unfixable = append(plan.unfixable, key)
+				continue
+			}
+			u := fixUpgrade{name: v.name, from: v.version, to: target, advisories: len(v.fixes)}
+			if compatible(v.version, target) {
+				plan.upgrades = append(plan.upgrades, u)
+			} else {
+				plan.major = append(plan.major, u)
+			}
+		}
+	}
+	return plan
+}
+
+// highestVersion returns the highest of the semver versions
+func highestVersion(versions []string) (string, bool) {
+	var best *semver.Version
+	var bestRaw string
+	for _, raw := range versions {
+		v, err := semver.NewVersion(raw)
+		if err != nil {
+			return "", false
+		}
+		if best == nil || v.GreaterThan(best) {
+			best, bestRaw = v, raw
+		}
+	}
+	return bestRaw, best != nil
+}
+
+// compatible reports whether target is within the caret range of current
+func compatible(current, target string) bool {
+	constraint, err := semver.NewConstraint("^" + current)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(target)
+	return err == nil && constraint.Check(v)
+}
+
+// showFixPlan prints the planned upgrades and what is left for the user
+func showFixPlan(display *ui.UI, plan fixPlan, via map[string][]string) {
+	if len(plan.upgrades) > 0 {
+		display.Print("")
+		display.Print("Upgrades:")
+		for _, u := range plan.upgrades {
+			display.Print(fmt.Sprintf("  %s %s -> %s (fixes %d advisories)", u.name, u.from, u.to, u.advisories))
+		}
+	}
+
+	if len(plan.major) > 0 {
+		display.Print("")
+		display.Warning("Needs a major upgrade (not applied):")
+		for _, u := range plan.major {
+			display.Print(fmt.Sprintf("  %s %s -> %s", u.name, u.from, u.to))
+		}
+	}
+
+	if len(plan.unfixable) > 0 {
+		display.Print("")
+		display.Warning("No fixed version for every advisory:")
+		for _, pkg := range plan.unfixable {
+			display.Print("  " + pkg)
+		}
+	}
+
+	if len(plan.transitive) > 0 {
+		display.Print("")
+		display.Warning("Transitive, requires override/resolution:")
+		for _, pkg := range plan.transitive {
+			line := "  " + pkg
+			if names := via[pkg]; len(names) > 0 {
+				line += " (via " + strings.Join(names, ", ") + ")"
+			}
+			display.Print(line)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/positronico/snapem/internal/scanner"
+)
+
+func TestPlanFixes(t *testing.T) {
+	cve := func(pkg, version, fixedIn string, direct bool) scanner.Finding {
+		return scanner.Finding{Package: pkg, Version: version, Type: scanner.FindingTypeCVE, FixedIn: fixedIn, Direct: direct}
+	}
+	result := &scanner.AggregatedResult{Results: []*scanner.ScanResult{{Findings: []scanner.Finding{
+		cve("lodash", "4.17.15", "4.17.19", true),
+		cve("lodash", "4.17.15", "4.17.21", true),
+		cve("axios", "0.21.0", "0.21.2", true),
+		cve("axios", "0.21.0", "1.6.0", true),
+		cve("request", "2.88.2", "", true),
+		cve("minimist", "1.2.5", "1.2.6", false),
+		{Package: "evil", Version: "1.0.0", Type: scanner.FindingTypeMalware, Direct: true},
+	}}}}
+
+	plan := planFixes(result)
+
+	got := fmt.Sprint(plan.upgrades, plan.major, plan.unfixable, plan.transitive)
+	want := fmt.Sprint(
+		[]fixUpgrade{{name: "lodash", from: "4.17.15", to: "4.17.21", advisories: 2}},
+		[]fixUpgrade{{name: "axios", from: "0.21.0", to: "1.6.0", advisories: 2}},
+		[]string{"request@2.88.2"},
+		[]string{"minimist@1.2.5"},
+	)
+	if got != want {
+		t.Errorf("planFixes() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/scanner/cache"
+	"github.com/positronico/snapem/internal/scanner/freshness"
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// addFreshness rates the project's direct dependencies and adds the report
+// and a quality finding per stale dependency to result
+func addFreshness(ctx context.Context, cfg *config.Config, display *ui.UI, parser *manifest.Parser, class manifest.DependencyClass, scanned []manifest.Package, result *scanner.AggregatedResult) {
+	direct, err := parser.GetDirectDependenciesFiltered(class)
+	if err != nil || len(direct) == 0 {
+		return
+	}
+
+	// Report the resolved version rather than the package.json range
+	resolved := make(map[string]string, len(scanned))
+	for _, pkg := range scanned {
+		if _, ok := resolved[pkg.Name]; !ok {
+			resolved[pkg.Name] = pkg.Version
+		}
+	}
+	for i := range direct {
+		if v, ok := resolved[direct[i].Name]; ok {
+			direct[i].Version = v
+		}
+	}
+
+	advisories := make(map[string]int)
+	for f := range result.FindingsOfType(types.FindingTypeCVE) {
+		advisories[f.Package+"@"+f.Version]++
+	}
+
+	var c *cache.Cache
+	if cfg.Scanning.Cache.Enabled && cfg.Scanning.Cache.Directory != "" {
+		c = cache.New(cfg.Scanning.Cache)
+	}
+
+	display.Verbose(fmt.Sprintf("Checking freshness of %d direct dependencies...", len(direct)))
+	health := freshness.NewChecker(cfg.Scanning.Freshness, registry.NewClient(cfg.PackageManager.Registries()), c).Check(ctx, direct, advisories)
+	findings := freshness.Findings(health)
+
+	result.Freshness = health
+	result.Results = append(result.Results, &scanner.ScanResult{
+		Scanner:  "Freshness",
+		Packages: len(direct),
+		Findings: findings,
+	})
+	result.TotalFindings += len(findings)
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	gitHookType  string
+	gitHookHusky bool
+
+	// gitHookRemove holds uninstall's --hook, which defaults to every hook
+	gitHookRemove string
+)
+
+// gitHookTypes lists the git hooks snapem can install
+var gitHookTypes = []string{"pre-commit", "pre-push"}
+
+// gitHookMarker identifies hook scripts written by snapem, so uninstall
+// never removes someone else's hook
+const gitHookMarker = "# snapem dependency scan"
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a git hook that scans dependency changes",
+	Long: `Writes a git hook that runs "snapem scan --diff --fail-on high" when a
+commit or push changes package.json or a lockfile. The pre-commit hook adds
+--staged, so it scans the staged files rather than the working tree.
+Commits and pushes that don't touch dependency files are never scanned or
+blocked.
+
+The hook looks snapem up on the PATH each time it runs, and skips the scan
+with a note when snapem is not installed.
+
+Examples:
+  snapem hooks install                  # pre-commit hook
+  snapem hooks install --hook pre-push  # Scan on push instead
+  snapem hooks install --husky          # Print a snippet for .husky/pre-commit`,
+	Args: cobra.NoArgs,
+	RunE: runHooksInstall,
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the git hooks installed by snapem",
+	Long: `Removes the pre-commit and pre-push hooks written by "snapem hooks
+install". Hooks written by anything else are left alone.`,
+	Args: cobra.NoArgs,
+	RunE: runHooksUninstall,
+}
+
+func init() {
+	hooksInstallCmd.Flags().StringVar(&gitHookType, "hook", "pre-commit", "git hook to install: "+strings.Join(gitHookTypes, ", "))
+	hooksInstallCmd.Flags().BoolVar(&gitHookHusky, "husky", false, "print the hook for a husky hook file instead of writing .git/hooks")
+	hooksInstallCmd.Flags().BoolVar(&force, "force", false, "replace an existing hook that snapem did not write")
+	hooksUninstallCmd.Flags().StringVar(&gitHookRemove, "hook", "", "only remove this git hook: "+strings.Join(gitHookTypes, ", "))
+
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	display := ui.New(false, false, useColor())
+	if gitHookHusky {
+		// Keep stdout to the snippet itself
+		display = ui.NewWriter(os.Stderr, false, false, useColor())
+	}
+
+	if !slices.Contains(gitHookTypes, gitHookType) {
+		msg := fmt.Sprintf("invalid --hook %q (expected %s)", gitHookType, strings.Join(gitHookTypes, ", "))
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	prefix, err := git(projectDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		display.Error(fmt.Sprintf("%s is not in a git repository", projectDir))
+		return errors.New(errors.ExitGeneralError, projectDir+" is not in a git repository")
+	}
+	script := gitHookScript(gitHookType, strings.TrimSpace(string(prefix)))
+
+	if gitHookHusky {
+		fmt.Print(script)
+		display.Info(fmt.Sprintf("Add these lines to .husky/%s", gitHookType))
+		return nil
+	}
+
+	hooksDir, err := gitHooksDir(projectDir)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.Wrap(errors.ExitGeneralError, "failed to locate the git hooks directory", err)
+	}
+	path := filepath.Join(hooksDir, gitHookType)
+
+	if data, err := os.ReadFile(path); err == nil && !strings.Contains(string(data), gitHookMarker) && !force {
+		msg := fmt.Sprintf("%s already exists; add the lines from `snapem hooks install --husky --hook %s` to it, or pass --force to replace it", path, gitHookType)
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		display.Error(fmt.Sprintf("Could not create %s: %v", hooksDir, err))
+		return errors.Wrap(errors.ExitGeneralError, "failed to create "+hooksDir, err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		display.Error(fmt.Sprintf("Could not write %s: %v", path, err))
+		return errors.Wrap(errors.ExitGeneralError, "failed to write "+path, err)
+	}
+
+	display.Success(fmt.Sprintf("Installed the %s hook in %s", gitHookType, path))
+	display.Info("Changes to package.json or a lockfile are scanned with `snapem scan --diff --fail-on high`")
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	display := ui.New(false, false, useColor())
+
+	hookTypes := gitHookTypes
+	if gitHookRemove != "" {
+		if !slices.Contains(gitHookTypes, gitHookRemove) {
+			msg := fmt.Sprintf("invalid --hook %q (expected %s)", gitHookRemove, strings.Join(gitHookTypes, ", "))
+			display.Error(msg)
+			return errors.New(errors.ExitGeneralError, msg)
+		}
+		hookTypes = []string{gitHookRemove}
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	hooksDir, err := gitHooksDir(projectDir)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.Wrap(errors.ExitGeneralError, "failed to locate the git hooks directory", err)
+	}
+
+	removed := 0
+	for _, hook := range hookTypes {
+		path := filepath.Join(hooksDir, hook)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(string(data), gitHookMarker) {
+			display.Warning(fmt.Sprintf("Leaving %s alone; snapem did not write it", path))
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			display.Error(fmt.Sprintf("Could not remove %s: %v", path, err))
+			return errors.Wrap(errors.ExitGeneralError, "failed to remove "+path, err)
+		}
+		display.Success(fmt.Sprintf("Removed the %s hook", hook))
+		removed++
+	}
+
+	if removed == 0 {
+		display.Info("No snapem git hooks installed")
+	}
+	return nil
+}
+
+// gitHooksDir returns the directory git runs hooks from, honoring
+// core.hooksPath and worktrees
+func gitHooksDir(projectDir string) (string, error) {
+	out, err := git(projectDir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("%s is not in a git repository", projectDir)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectDir, dir)
+	}
+	return dir, nil
+}
+
+// gitHookScript returns the body of a hook that scans the project at
+// prefix, relative to the repository root, when a commit or push changes
+// its dependency files. A pre-commit hook scans the staged files, so
+// unstaged edits neither block nor pass a commit. The script has no
+// shebang, so it also works as a husky hook file.
+func gitHookScript(hook, prefix string) string {
+	// Pathspecs are relative to the project directory; without glob magic
+	// a * also matches slashes, so these cover nested package.json files
+	var pathspecs []string
+	for _, name := range diffFiles {
+		pathspecs = append(pathspecs, shellSingleQuote(name), shellSingleQuote("*/"+name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: written by `snapem hooks install`, removed by `snapem hooks uninstall`\n", gitHookMarker)
+	fmt.Fprintf(&b, `snapem=$(command -v snapem) || {
+	echo "snapem not found on PATH; skipping the dependency scan" >&2
+	exit 0
+}
+cd "$(git rev-parse --show-toplevel)"/%s || exit 1
+`, shellSingleQuote(prefix))
+
+	scan := `"$snapem" scan --non-interactive`
+	if hook == "pre-push" {
+		fmt.Fprintf(&b, `
+# Compare with the upstream branch, or the remote's default branch for a
+# branch that has not been pushed yet
+base=$(git rev-parse --verify --quiet '@{upstream}') ||
+	base=$(git rev-parse --verify --quiet refs/remotes/origin/HEAD) ||
+	exit 0
+
+# Leave pushes that don't touch dependency files alone
+git diff --quiet "$base" HEAD -- %s && exit 0
+`, strings.Join(pathspecs, " "))
+	} else {
+		scan += " --staged"
+		fmt.Fprintf(&b, `
+# Leave commits that don't touch dependency files alone
+git diff --cached --quiet -- %s && exit 0
+
+base=HEAD
+git rev-parse --verify --quiet HEAD >/dev/null || base=
+`, strings.Join(pathspecs, " "))
+	}
+
+	fmt.Fprintf(&b, `
+if [ -n "$base" ]; then
+	exec %s --diff="$base" --fail-on high
+fi
+exec %s --fail-on high
+`, scan, scan)
+	return b.String()
+}
+
+// shellSingleQuote quotes s as one shell word, whatever it contains
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGitHookInstall installs the pre-commit hook in a repository and runs
+// it against a stub snapem that records its arguments
+func TestGitHookInstall(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	// The hook must quote the project's path for the shell
+	repo := t.TempDir()
+	dir := `it's "web" $HOME`
+	project := filepath.Join(repo, dir)
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+
+	t.Chdir(project)
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { gitHookType, gitHookHusky, force = "pre-commit", false, false })
+
+	rootCmd.SetArgs([]string{"hooks", "install"})
+	if err := Execute(); err != nil {
+		t.Fatalf("hooks install: %v", err)
+	}
+	hook := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	data, err := os.ReadFile(hook)
+	if err != nil {
+		t.Fatalf("hook not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "#!/bin/sh\n") || !strings.Contains(string(data), gitHookMarker) {
+		t.Errorf("hook script:\n%s", data)
+	}
+
+	// A stub snapem on the PATH records how the hook calls it
+	bin := t.TempDir()
+	calls := filepath.Join(t.TempDir(), "calls")
+	stub := "#!/bin/sh\necho \"$PWD $*\" >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "snapem"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runHook := func(path string) {
+		t.Helper()
+		cmd := exec.Command("sh", hook)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "PATH="+path)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("hook failed: %v\n%s", err, out)
+		}
+	}
+	recorded := func() string {
+		data, _ := os.ReadFile(calls)
+		return string(data)
+	}
+	path := bin + string(os.PathListSeparator) + os.Getenv("PATH")
+
+	// Commits that don't touch dependency files are not scanned
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "README.md")
+	runHook(path)
+	if got := recorded(); got != "" {
+		t.Errorf("hook scanned a commit without dependency changes: %q", got)
+	}
+
+	// The first commit has no HEAD to diff against
+	if err := os.WriteFile(filepath.Join(project, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", dir+"/package.json")
+	runHook(path)
+	if got, want := recorded(), project+" scan --non-interactive --staged --fail-on high\n"; got != want {
+		t.Errorf("hook ran %q, want %q", got, want)
+	}
+
+	git("commit", "-q", "-m", "init")
+	if err := os.WriteFile(filepath.Join(project, "package-lock.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", dir+"/package-lock.json")
+	os.Remove(calls)
+	runHook(path)
+	if got, want := recorded(), project+" scan --non-interactive --staged --diff=HEAD --fail-on high\n"; got != want {
+		t.Errorf("hook ran %q, want %q", got, want)
+	}
+
+	// Without snapem on the PATH the hook lets the commit through
+	runHook("/nonexistent")
+
+	rootCmd.SetArgs([]string{"hooks", "uninstall"})
+	if err := Execute(); err != nil {
+		t.Fatalf("hooks uninstall: %v", err)
+	}
+	if _, err := os.Stat(hook); !os.IsNotExist(err) {
+		t.Errorf("hook still present after uninstall: %v", err)
+	}
+}
+
+func TestGitHookInstallKeepsForeignHook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	hook := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	if err := os.MkdirAll(filepath.Dir(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nmake lint\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(repo)
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() { gitHookType, gitHookHusky, force = "pre-commit", false, false })
+
+	for _, args := range [][]string{{"hooks", "install", "--hook", "pre-commit"}, {"hooks", "uninstall"}} {
+		force = false
+		rootCmd.SetArgs(args)
+		Execute()
+		if data, _ := os.ReadFile(hook); string(data) != "#!/bin/sh\nmake lint\n" {
+			t.Errorf("%v changed the existing hook:\n%s", args, data)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	historyProject    string
+	historySince      string
+	historyForcedOnly bool
+	historyLimit      int
+	historyJSON       bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recent entries of the audit log",
+	Long: `Shows the most recent scans, installs and overrides recorded in the
+audit log (audit.file), newest last.
+
+Examples:
+  snapem history                  # Last 20 entries, all projects
+  snapem history --project .      # Only this project
+  snapem history --since 7d       # The last week
+  snapem history --since 2026-01-01 --forced-only  # Overrides this year
+  snapem history --json           # Raw JSON lines for other tools`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyProject, "project", "", "only entries for this project directory")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only entries since a date (2006-01-02) or an age such as 24h or 7d")
+	historyCmd.Flags().BoolVar(&historyForcedOnly, "forced-only", false, "only entries where a block was overridden")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "show at most this many entries, newest last; 0 for all")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "print the matching entries as JSON lines")
+
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	filter := audit.Filter{ForcedOnly: historyForcedOnly}
+	if historySince != "" {
+		filter.Since, err = parseSince(historySince, time.Now())
+		if err != nil {
+			display.Error(err.Error())
+			return errors.New(errors.ExitGeneralError, err.Error())
+		}
+	}
+	if historyProject != "" {
+		filter.Project, err = filepath.Abs(historyProject)
+		if err != nil {
+			display.Error(fmt.Sprintf("Invalid project directory %s", historyProject))
+			return errors.Wrap(errors.ExitGeneralError, "invalid project directory "+historyProject, err)
+		}
+	}
+
+	entries, err := audit.Read(cfg.Audit.File)
+	if err != nil {
+		display.Error(fmt.Sprintf("Could not read the audit log: %v", err))
+		return errors.Wrap(errors.ExitGeneralError, "failed to read "+cfg.Audit.File, err)
+	}
+
+	var matched []audit.Entry
+	for _, e := range entries {
+		if filter.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	if historyLimit > 0 && len(matched) > historyLimit {
+		matched = matched[len(matched)-historyLimit:]
+	}
+
+	if historyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range matched {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(matched) == 0 {
+		if !cfg.Audit.Enabled {
+			display.Info("The audit log is disabled (audit.enabled: false)")
+		}
+		display.Info("No matching entries in " + cfg.Audit.File)
+		return nil
+	}
+	for _, e := range matched {
+		display.HistoryEntry(e.Time.Local().Format("2006-01-02 15:04"), e.Command, string(e.Decision), e.Project, historyDetails(e)...)
+	}
+	return nil
+}
+
+// historyDetails describes an entry's packages, findings and overrides
+func historyDetails(e audit.Entry) []string {
+	var details []string
+	if len(e.Packages) > 0 {
+		details = append(details, "packages: "+strings.Join(e.Packages, ", "))
+	}
+
+	scan := "scan skipped"
+	if e.Scanned > 0 {
+		scan = fmt.Sprintf("scanned %d packages, %s", e.Scanned, describeSummary(e.Findings))
+	}
+	if e.User != "" {
+		scan += " · by " + e.User
+	}
+	details = append(details, scan)
+
+	if e.Reason != "" && e.Decision != audit.DecisionAllowed {
+		details = append(details, "reason: "+e.Reason)
+	}
+	if len(e.Flagged) > 0 && e.Decision != audit.DecisionAllowed {
+		details = append(details, "flagged: "+strings.Join(e.Flagged, ", "))
+	}
+	var flags []string
+	if e.Forced {
+		flags = append(flags, "block overridden")
+	}
+	if e.Unsecure {
+		flags = append(flags, "no malware detection")
+	}
+	if len(e.PolicySets) > 0 {
+		flags = append(flags, "--policy-set "+strings.Join(e.PolicySets, ", "))
+	}
+	if len(flags) > 0 {
+		details = append(details, "! "+strings.Join(flags, ", "))
+	}
+	return details
+}
+
+// describeSummary counts findings by kind, e.g. "3 findings (1 malware,
+// 2 high)"
+func describeSummary(s audit.Summary) string {
+	if s.Total == 0 {
+		return "no findings"
+	}
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{{s.Malware, "malware"}, {s.Critical, "critical"}, {s.High, "high"}, {s.Medium, "medium"}, {s.Low, "low"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	noun := "findings"
+	if s.Total == 1 {
+		noun = "finding"
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d %s", s.Total, noun)
+	}
+	return fmt.Sprintf("%d %s (%s)", s.Total, noun, strings.Join(parts, ", "))
+}
+
+// parseSince reads --since: a date, a Go duration such as 24h, or a
+// number of days such as 7d, counted back from now
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected a date such as 2026-01-02, or an age such as 24h or 7d)", value)
+}
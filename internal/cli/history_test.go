@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// TestMain keeps the commands under test from appending to the real audit
+// log; tests that check it point XDG_DATA_HOME elsewhere themselves
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "snapem-audit-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_DATA_HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"36h", now.Add(-36 * time.Hour), false},
+		{"2026-01-02", time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local), false},
+		{"-1d", time.Time{}, true},
+		{"last week", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestScanAuditLog checks that a scan is recorded, and that a log that
+// cannot be written leaves the scan's exit code alone
+func TestScanAuditLog(t *testing.T) {
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+	t.Cleanup(func() { policySets = nil })
+
+	scan := func() int {
+		t.Helper()
+		policySets = nil
+		rootCmd.SetArgs([]string{"scan", "--format", "text", "--quiet", "--simulate", "block", "--policy-set", "cve.low=warn"})
+		if err := Execute(); err != nil {
+			return errors.ExitCodeFor(err)
+		}
+		return 0
+	}
+
+	if code := scan(); code != errors.ExitSecurityBlock {
+		t.Fatalf("scan exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+	entries, err := audit.Read(filepath.Join(dataDir, "snapem", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Command != "scan" || entries[0].Decision != audit.DecisionBlocked || entries[0].Project != project {
+		t.Fatalf("audit entries = %+v", entries)
+	}
+	if got := entries[0].PolicySets; len(got) != 1 || got[0] != "cve.low=warn" {
+		t.Errorf("audit entry policy sets = %v, want [cve.low=warn]", got)
+	}
+
+	// A file where the log directory should be makes the write fail
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dataDir, "snapem", "audit.log"))
+	if code := scan(); code != errors.ExitSecurityBlock {
+		t.Errorf("scan with an unwritable audit log exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/hooks"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage event hooks and git hooks",
+	Long: `Hooks run shell commands when snapem events occur. Each command
+receives a JSON payload on stdin.
+
+Events: scan_complete, install_blocked, override_used, install_complete
+
+"hooks install" adds a git hook that scans dependency changes before they
+are committed or pushed.`,
+}
+
+var hooksTestCmd = &cobra.Command{
+	Use:   "test <event>",
+	Short: "Fire a hook with a synthetic payload",
+	Long: `Runs the command configured for an event with a synthetic payload
+and shows its output, so hook scripts can be developed without a real scan.
+
+Examples:
+  snapem hooks test scan_complete
+  snapem hooks test install_blocked`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHooksTest,
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksTestCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
+
+// newHookRunner creates the hook runner, logging hook output to verbose
+func newHookRunner(cfg *config.Config, display *ui.UI) *hooks.Runner {
+	return hooks.NewRunner(cfg.Hooks, display.Verbose)
+}
+
+func runHooksTest(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	event, err := hooks.Parse(args[0])
+	if err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+
+	// Show hook output directly instead of only in verbose mode
+	runner := hooks.NewRunner(cfg.Hooks, display.Print)
+	if !runner.Configured(event) {
+		display.Error(fmt.Sprintf("No command configured for hooks.%s", event))
+		return errors.ConfigError(fmt.Sprintf("no command configured for hooks.%s", event))
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+
+	if err := runner.Fire(cmd.Context(), event, projectDir, hooks.Synthetic(event)); err != nil {
+		return errors.Wrap(errors.ExitGeneralError, "hook failed", err)
+	}
+
+	display.Success(fmt.Sprintf("Hook %s completed", event))
+	return nil
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var imagePinRefresh bool
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage container images",
+	Long: `Manage the container images package managers run in.
+
+Images are set per package manager in container.image. A reference
+pinned by digest, such as node:20-slim@sha256:<hex>, always runs the
+exact same image; container.require_digest (or --strict-images) refuses
+any other.`,
+}
+
+var imagePinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Pin the configured images by digest",
+	Long: `Pulls each configured image, looks up the digest its tag currently
+points to, and writes the pinned reference to snapem.yaml (or the file
+given with --config). Comments and other settings in the file are kept.
+
+Images that are already pinned are left alone unless --refresh is given.
+
+Examples:
+  snapem image pin             # Pin floating tags
+  snapem image pin --refresh   # Re-resolve every tag, including pinned ones`,
+	Args: cobra.NoArgs,
+	RunE: runImagePin,
+}
+
+var imagePullCmd = &cobra.Command{
+	Use:   "pull [image...]",
+	Short: "Download container images ahead of time",
+	Long: `Pulls the images snapem runs package managers in, so the first
+install or run does not stall on the download. With no arguments, the
+images configured for npm, bun and yarn are pulled.
+
+Commands also pull a missing image before running, showing the
+runtime's progress.
+
+Examples:
+  snapem image pull                 # Pull the configured images
+  snapem image pull node:20-slim    # Pull a specific image`,
+	RunE: runImagePull,
+}
+
+func init() {
+	imageCmd.AddCommand(imagePullCmd)
+	imagePinCmd.Flags().BoolVar(&imagePinRefresh, "refresh", false, "re-resolve images that are already pinned")
+	imageCmd.AddCommand(imagePinCmd)
+	rootCmd.AddCommand(imageCmd)
+}
+
+// nodeImageManagers are the package managers that run in a Node.js image
+var nodeImageManagers = []string{"npm", "yarn"}
+
+// containerImages returns the image for each package manager: the
+// configured one, or for Node.js images left at the default, the major
+// version the project asks for in .nvmrc, .node-version or engines.node
+func containerImages(cfg *config.Config, display *ui.UI, projectDir string) map[string]string {
+	images := maps.Clone(cfg.Container.Image)
+	image, source, ok := pkgmanager.NodeImage(projectDir)
+	if !ok {
+		return images
+	}
+
+	display.Verbose(fmt.Sprintf("Node.js image from %s: %s", source, image))
+	for _, name := range nodeImageManagers {
+		if cfg.Container.PinnedImages[name] {
+			display.Verbose(fmt.Sprintf("container.image.%s is pinned in config, keeping %s", name, images[name]))
+			continue
+		}
+		images[name] = image
+	}
+	return images
+}
+
+// checkImage rejects a malformed digest and, when container.require_digest
+// is set, an image that is not pinned by digest
+func checkImage(cfg *config.Config, display *ui.UI, image string) error {
+	if err := container.ValidateImage(image); err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+	if container.IsDigestPinned(image) {
+		return nil
+	}
+
+	if !cfg.Container.RequireDigest {
+		display.Verbose(fmt.Sprintf("Image %s is not pinned by digest", image))
+		return nil
+	}
+	display.Error(fmt.Sprintf("Image %s is not pinned by digest", image))
+	display.Info("Run 'snapem image pin' to pin the configured images, or drop --strict-images")
+	return errors.ConfigError("image " + image + " is not pinned by digest")
+}
+
+// ensureImage pulls the image before a run if it is not present locally, so
+// the download shows progress instead of stalling silently inside Run
+func ensureImage(ctx context.Context, cfg *config.Config, display *ui.UI, runtime container.Runtime, image string) error {
+	cache := container.LoadImageCache(cfg.Scanning.Cache.Directory)
+	if cache.Has(runtime, image) {
+		return nil
+	}
+
+	exists, err := runtime.ImageExists(ctx, image)
+	if err != nil {
+		// Leave the pull to the runtime
+		display.Verbose(fmt.Sprintf("Could not check for image %s: %v", image, err))
+		return nil
+	}
+	if !exists {
+		if err := pullImage(ctx, display, runtime, image); err != nil {
+			return err
+		}
+	}
+
+	cache.Add(runtime, image)
+	if err := cache.Save(); err != nil {
+		display.Verbose(fmt.Sprintf("Could not save image cache: %v", err))
+	}
+	return nil
+}
+
+// pullImage pulls an image with the runtime's progress output
+func pullImage(ctx context.Context, display *ui.UI, runtime container.Runtime, image string) error {
+	display.Info(fmt.Sprintf("Pulling %s...", image))
+	if err := runtime.Pull(ctx, image); err != nil {
+		display.Error(fmt.Sprintf("Failed to pull %s", image))
+		return err
+	}
+	display.Success(fmt.Sprintf("Pulled %s", image))
+	return nil
+}
+
+func runImagePull(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	images := args
+	if len(images) == 0 {
+		projectDir, err := projectDirectory(display)
+		if err != nil {
+			return err
+		}
+		images = slices.Sorted(maps.Values(containerImages(cfg, display, projectDir)))
+		images = slices.Compact(images)
+	}
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return err
+	}
+
+	cache := container.LoadImageCache(cfg.Scanning.Cache.Directory)
+	for _, image := range images {
+		if err := container.ValidateImage(image); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+		if err := pullImage(ctx, display, runtime, image); err != nil {
+			return err
+		}
+		cache.Add(runtime, image)
+	}
+	if err := cache.Save(); err != nil {
+		display.Verbose(fmt.Sprintf("Could not save image cache: %v", err))
+	}
+	return nil
+}
+
+func runImagePin(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return err
+	}
+
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = filepath.Join(projectDir, "snapem.yaml")
+	}
+
+	images := containerImages(cfg, display, projectDir)
+	digests := make(map[string]string) // npm and yarn usually share a tag
+	for _, name := range slices.Sorted(maps.Keys(images)) {
+		image := images[name]
+		if err := container.ValidateImage(image); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+		if container.IsDigestPinned(image) && !imagePinRefresh {
+			display.Info(fmt.Sprintf("%s: %s (already pinned)", name, image))
+			continue
+		}
+
+		tag := container.StripDigest(image)
+		digest, ok := digests[tag]
+		if !ok {
+			display.Progress(fmt.Sprintf("Resolving %s…", tag))
+			digest, err = container.ResolveDigest(ctx, runtime, tag)
+			if err != nil {
+				display.ProgressDone(fmt.Sprintf("%s: could not resolve %s", name, tag))
+				display.Error(err.Error())
+				return errors.ContainerError(err)
+			}
+			digests[tag] = digest
+		}
+
+		pinned := tag + "@" + digest
+		display.ProgressDone(fmt.Sprintf("%s: %s", name, pinned))
+		if err := config.SetFileValue(configPath, "container.image."+name, pinned); err != nil {
+			display.Error(fmt.Sprintf("Failed to update %s: %v", configPath, err))
+			return errors.Wrap(errors.ExitGeneralError, "failed to update "+configPath, err)
+		}
+	}
+
+	display.Success(fmt.Sprintf("Images pinned in %s", configPath))
+	return nil
+}
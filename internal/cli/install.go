@@ -7,20 +7,30 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/positronico/snapem/internal/audit"
 	"github.com/positronico/snapem/internal/config"
 	"github.com/positronico/snapem/internal/container"
 	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/hooks"
 	"github.com/positronico/snapem/internal/manifest"
 	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/registry"
 	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/seen"
 	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
 )
 
 var (
-	skipScan    bool
-	force       bool
-	noContainer bool
-	saveDev     bool
+	skipScan        bool
+	force           bool
+	allowUnsecure   bool
+	noContainer     bool
+	saveDev         bool
+	showAllWarnings bool
+	ignoreScripts   bool
+
+	requireProvenance bool
 )
 
 var installCmd = &cobra.Command{
@@ -35,15 +45,31 @@ Examples:
   snapem install              # Install all dependencies
   snapem install lodash       # Install lodash
   snapem install -D jest      # Install jest as dev dependency
-  snapem install --skip-scan  # Install without scanning`,
+  snapem install ./my-lib-1.2.3.tgz  # Install a local tarball
+  snapem install ../local-pkg # Install a local package directory
+  snapem install --skip-scan  # Install without scanning
+  snapem install --ignore-scripts  # Do not run install scripts
+  snapem install --workspaces --parallel 8  # Install every workspace`,
 	RunE: runInstall,
 }
 
 func init() {
 	installCmd.Flags().BoolVar(&skipScan, "skip-scan", false, "skip security scanning")
 	installCmd.Flags().BoolVar(&force, "force", false, "override security blocks")
+	installCmd.Flags().BoolVar(&allowUnsecure, "allow-unsecure", false, "continue without malware detection when SOCKET_API_TOKEN is not set")
 	installCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
 	installCmd.Flags().BoolVarP(&saveDev, "save-dev", "D", false, "install as devDependency")
+	installCmd.Flags().BoolVar(&ignoreScripts, "ignore-scripts", false, "do not run lifecycle scripts of installed packages")
+	installCmd.Flags().BoolVar(&requireProvenance, "require-provenance", false, "block packages published without an npm provenance attestation")
+	installCmd.Flags().BoolVar(&showAllWarnings, "show-all-warnings", false, "re-print warnings already reported by a previous install")
+	addWorkspaceFlag(installCmd)
+	addWorkspacesFlags(installCmd)
+	addPolicySetFlag(installCmd)
+	addStrictScannersFlag(installCmd)
+	addNoBaselineFlag(installCmd)
+	addVolumeOptFlag(installCmd)
+	addNpmrcFlag(installCmd)
+	addLimitFlags(installCmd)
 
 	rootCmd.AddCommand(installCmd)
 }
@@ -52,69 +78,156 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
-		return errors.ConfigError(err.Error())
+		return err
 	}
 
 	// Initialize UI
-	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color && !noColor)
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
 
-	// Get current directory
-	projectDir, err := os.Getwd()
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := refuseReadOnlySource(cfg, display, "install"); err != nil {
+		return err
+	}
+
+	if err := checkWorkspacesFlags(cmd, display, args); err != nil {
+		return err
+	}
+
+	applyNpmrcFlag(cfg)
+
+	if err := applyLimitFlags(cfg, display); err != nil {
+		return err
+	}
+
+	if err := applyPolicySets(cfg, display); err != nil {
+		return err
+	}
+	warnExpiredAllowlist(cfg, display)
+	if requireProvenance {
+		cfg.Scanning.Provenance.Require = true
+	}
+
+	projectDir, err := projectDirectory(display)
 	if err != nil {
-		display.Error("Failed to get current directory")
-		return errors.New(errors.ExitGeneralError, "failed to get current directory")
+		return err
 	}
+	loadRegistries(cfg, display, projectDir)
 
 	// Check for package.json
 	parser := manifest.NewParser(projectDir)
 	if !parser.HasManifest() {
-		display.Error("No package.json found in current directory")
-		return errors.ManifestError("no package.json found", nil)
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	ws, err := selectedWorkspace(display, parser)
+	if err != nil {
+		return err
+	}
+	var workspaces []manifest.Workspace
+	if installWorkspaces {
+		if workspaces, err = projectWorkspaces(display, parser); err != nil {
+			return err
+		}
+	}
+
+	// Resolve tarball and directory specs before anything else runs
+	local, err := resolveLocalPackages(display, projectDir, args, cfg.Container.Enabled && !noContainer)
+	if err != nil {
+		return err
 	}
 
 	// Detect package manager
-	mgr := pkgmanager.Detect(projectDir, pkgMgr, cfg.Container.Image)
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
 	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
 
+	hookRunner := newHookRunner(cfg, display)
+	trail := newAuditTrail(cfg, display, "install", projectDir, args)
+	installData := hooks.InstallData{Packages: args, PackageManager: mgr.Name()}
+
 	// Run security scan (unless skipped)
 	if cfg.Scanning.Enabled && !skipScan {
-		if err := runSecurityScan(ctx, cfg, display, parser, args); err != nil {
-			if !force && !cfg.Scanning.Policy.AllowOverride {
-				return err
+		// Verify the lockfile first; an unreachable registry only warns
+		var verifyErr error
+		if cfg.Scanning.Verify.OnInstall && parser.HasLockfile() {
+			verifyErr = verifyLockfile(ctx, cfg, display, parser, true)
+		}
+
+		var seenStore *seen.Store
+		if !showAllWarnings {
+			var wsName string
+			if ws != nil {
+				wsName = ws.Name
 			}
-			// If force flag or override allowed, prompt user
-			if cfg.Scanning.Policy.AllowOverride && !force {
-				if !display.PromptForce() {
-					return errors.UserAbortError()
-				}
+			seenStore = seen.Load(projectDir, wsName)
+		}
+		result, err := runSecurityScan(ctx, cfg, display, parser, seenStore, local.scanArgs)
+		trail.result = result
+		if result != nil {
+			installData.Scan = report.NewDocument(result)
+			hookRunner.Fire(ctx, hooks.ScanComplete, projectDir, installData.Scan)
+		}
+		// One confirmation covers both a verification and a scan block
+		if err := joinBlocks(verifyErr, err); err != nil {
+			if err := overrideScanBlock(ctx, cfg, display, hookRunner, trail, projectDir, &installData, err); err != nil {
+				return err
 			}
-			display.Warning("Proceeding despite security warnings...")
+		} else {
+			trail.record(audit.DecisionAllowed, "")
 		}
+	} else {
+		trail.record(audit.DecisionAllowed, "scan skipped")
 	}
 
 	// Build container options
-	installCmd := mgr.InstallCommand(args, saveDev)
+	installCmd := mgr.InstallCommand(local.args, pkgmanager.InstallOptions{
+		SaveDev:       saveDev,
+		IgnoreScripts: ignoreScripts || cfg.PackageManager.IgnoreScripts,
+		Workspace:     workspaceOption(ws),
+	})
+	var plan workspacePlan
+	if installWorkspaces {
+		plan = planWorkspaceInstall(mgr, installParallel, ignoreScripts || cfg.PackageManager.IgnoreScripts)
+		installCmd = plan.install
+	}
 	networkMode := container.NetworkMode(cfg.Container.Network)
-	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, networkMode, installCmd)
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, installCmd)
 
 	// Run in container (unless disabled)
 	if cfg.Container.Enabled && !noContainer {
-		runtime := container.NewAppleRuntime()
-		if !runtime.IsAvailable() {
-			display.Error("Apple container runtime not available")
-			display.Info("Install with: brew install --cask container")
-			return errors.ContainerNotAvailableError()
+		deps, _ := parser.GetDependencies(true)
+		if err := runPreflight(cfg, display, projectDir, len(deps)+len(args)); err != nil {
+			return err
 		}
 
-		display.ContainerHeader(runtime.CommandString(opts))
+		runtime, err := newRuntime(cfg, display)
+		if err != nil {
+			return err
+		}
+
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+		opts.Volumes = append(opts.Volumes, local.mounts...)
 
-		if err := runtime.Run(ctx, opts); err != nil {
+		if installWorkspaces {
+			err = runWorkspacePlan(ctx, display, runtime, opts, plan, workspaces, installParallel, os.Stdout, os.Stderr)
+		} else {
+			display.ContainerHeader(runtime.CommandString(opts))
+			err = runtime.Run(ctx, opts)
+		}
+		if err != nil {
 			return err
 		}
 
 		display.Success("Installation complete")
+		installData.Reason = ""
+		hookRunner.Fire(ctx, hooks.InstallComplete, projectDir, installData)
 	} else {
 		// Run without container - just warn
 		display.Warning("Running without container isolation (--no-container)")
@@ -125,85 +238,202 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runSecurityScan(ctx context.Context, cfg *config.Config, display *ui.UI, parser *manifest.Parser, newPackages []string) error {
-	display.ScanningHeader()
+// joinBlocks combines the lockfile verification and scan errors so one
+// override decision covers both. A security block from either sets the
+// exit code.
+func joinBlocks(verifyErr, scanErr error) error {
+	switch {
+	case verifyErr == nil:
+		return scanErr
+	case scanErr == nil:
+		return verifyErr
+	}
+	code := errors.ExitCodeFor(scanErr)
+	if errors.ExitCodeFor(verifyErr) == errors.ExitSecurityBlock {
+		code = errors.ExitSecurityBlock
+	}
+	return errors.Wrap(code, verifyErr.Error(), scanErr)
+}
 
-	// Check for Socket API token
-	if !cfg.HasSocketToken() && cfg.Scanning.Socket.Enabled {
-		if !display.PromptUnsecure() {
+// overrideScanBlock decides whether a failed or blocking scan stops the
+// command. It returns nil when --force or an interactive override lets the
+// command continue, and fires the matching hook and records the decision
+// in the audit log either way.
+func overrideScanBlock(ctx context.Context, cfg *config.Config, display *ui.UI, hookRunner *hooks.Runner, trail *auditTrail, projectDir string, data *hooks.InstallData, err error) error {
+	data.Reason = err.Error()
+	blocked := errors.ExitCodeFor(err) == errors.ExitSecurityBlock
+	if !force && !cfg.Scanning.Policy.AllowOverride {
+		if blocked {
+			hookRunner.Fire(ctx, hooks.InstallBlocked, projectDir, *data)
+			trail.record(audit.DecisionBlocked, data.Reason)
+		}
+		return err
+	}
+	// If force flag or override allowed, prompt user
+	if cfg.Scanning.Policy.AllowOverride && !force {
+		if promptsDisabled() {
+			display.Info("Prompts are disabled; pass --force to override")
+			if blocked {
+				hookRunner.Fire(ctx, hooks.InstallBlocked, projectDir, *data)
+				trail.record(audit.DecisionBlocked, data.Reason)
+			}
+			return err
+		}
+		if !display.PromptForce() {
+			if blocked {
+				hookRunner.Fire(ctx, hooks.InstallBlocked, projectDir, *data)
+				trail.record(audit.DecisionBlocked, data.Reason)
+			}
 			return errors.UserAbortError()
 		}
-		cfg.Scanning.Socket.Enabled = false
+	}
+	display.Warning("Proceeding despite security warnings...")
+	hookRunner.Fire(ctx, hooks.OverrideUsed, projectDir, *data)
+	trail.record(audit.DecisionForced, data.Reason)
+	return nil
+}
+
+// runSecurityScan scans the project and newly requested packages. The scan
+// result is returned whenever a scan ran, even if policy blocks the install.
+func runSecurityScan(ctx context.Context, cfg *config.Config, display *ui.UI, parser *manifest.Parser, seenStore *seen.Store, newPackages []string) (*scanner.AggregatedResult, error) {
+	display.ScanningHeader()
+
+	if err := checkSocketToken(cfg, display); err != nil {
+		return nil, err
 	}
 
 	// Get packages to scan
-	packages, err := parser.GetDependencies(true)
+	packages, err := workspaceDependencies(display, parser, manifest.DependenciesAll)
 	if err != nil {
 		display.Warning("Could not parse dependencies, scanning new packages only")
 		packages = []manifest.Package{}
 	}
+	markLockfileChanges(display, parser, packages)
 
 	// Add new packages being installed (parse name@version format)
+	var requested []manifest.Package
 	for _, pkg := range newPackages {
-		name, version := parsePackageArg(pkg)
-		packages = append(packages, manifest.Package{
-			Name:      name,
-			Version:   version,
-			Ecosystem: "npm",
-		})
+		requested = append(requested, requestedPackage(pkg))
 	}
+	packages = append(packages, resolveNewPackages(ctx, cfg, display, requested)...)
+
+	return scanPackages(ctx, cfg, display, parser.Dir(), packages, seenStore)
+}
+
+// checkSocketToken asks whether to go on without malware detection when
+// Socket.dev is enabled but has no token, and turns it off if so
+func checkSocketToken(cfg *config.Config, display *ui.UI) error {
+	if cfg.HasSocketToken() || !cfg.Scanning.Socket.Enabled || simulation.AffectsScan() {
+		return nil
+	}
+	switch {
+	case allowUnsecure:
+		display.Warning("No SOCKET_API_TOKEN set. Continuing without malware detection (--allow-unsecure)")
+	case promptsDisabled():
+		display.Error("No SOCKET_API_TOKEN set and prompts are disabled")
+		display.Info("Set SOCKET_API_TOKEN, pass --allow-unsecure to continue without malware detection, or --skip-scan to install without scanning")
+		return errors.ConfigError("SOCKET_API_TOKEN not set")
+	case !display.PromptUnsecure():
+		return errors.UserAbortError()
+	}
+	cfg.Scanning.Socket.Enabled = false
+	return nil
+}
 
+// requestedPackage returns a package named on the command line, such as
+// lodash@4.17.20, as a new direct dependency
+func requestedPackage(arg string) manifest.Package {
+	name, version := parsePackageArg(arg)
+	return manifest.Package{
+		Name:      name,
+		Version:   version,
+		Ecosystem: "npm",
+		Direct:    true,
+		New:       true,
+	}
+}
+
+// scanPackages scans packages and applies the baseline of projectDir and
+// the policy. The result is returned whenever a scan ran, even if policy
+// blocks.
+func scanPackages(ctx context.Context, cfg *config.Config, display *ui.UI, projectDir string, packages []manifest.Package, seenStore *seen.Store) (*scanner.AggregatedResult, error) {
 	if len(packages) == 0 {
 		display.Info("No packages to scan")
-		return nil
+		return nil, nil
 	}
 
 	display.Verbose(fmt.Sprintf("Scanning %d packages...", len(packages)))
 
 	// Create orchestrator and scan
-	orch := scanner.NewOrchestrator(cfg)
+	orch := newOrchestrator(cfg)
 
 	scanners := orch.AvailableScanners()
 	if len(scanners) == 0 {
 		display.Warning("No scanners available")
-		return nil
+		return nil, nil
 	}
 
-	result, err := orch.ScanWithProgress(ctx, packages, func(name string, done bool) {
-		if done {
-			display.ScannerStatus(name, "complete", false)
-		} else {
-			display.ScannerStatus(name, "scanning...", true)
-		}
-	})
+	progress := display.StartScanProgress()
+	result, err := orch.ScanWithProgress(ctx, packages, progress.Update)
+	progress.Stop()
 
 	if err != nil {
-		return errors.ScannerError("security", err)
+		return nil, errors.ScannerError("security", err)
+	}
+	if err := checkScannerErrors(cfg, display, result); err != nil {
+		return nil, err
+	}
+	result = applyBaseline(cfg, display, projectDir, result)
+
+	if result.CacheHits > 0 {
+		display.Verbose(fmt.Sprintf("%d of %d packages served from cache", result.CacheHits, result.TotalPackages))
 	}
 
 	// Display results
-	return evaluateScanResults(cfg, display, result)
+	return result, evaluateScanResults(cfg, display, result, seenStore)
 }
 
-func evaluateScanResults(cfg *config.Config, display *ui.UI, result *scanner.AggregatedResult) error {
+// evaluateScanResults displays findings and applies the install policy.
+// Non-blocking findings already recorded in seenStore are summarized in a
+// single line instead of being printed again; pass nil to show everything.
+func evaluateScanResults(cfg *config.Config, display *ui.UI, result *scanner.AggregatedResult, seenStore *seen.Store) error {
 	if result.TotalFindings == 0 {
-		display.Success("No security issues found")
+		if len(result.Baselined) > 0 {
+			display.Success("No new security issues found")
+			display.Info(fmt.Sprintf("Baseline (%d suppressed)", len(result.Baselined)))
+		} else {
+			display.Success("No security issues found")
+		}
 		return nil
 	}
 
 	display.Print(fmt.Sprintf("\nFound %d issue(s):", result.TotalFindings))
 
 	var hasBlockingIssue bool
+	var suppressed int
 
 	// Display malware findings
 	malwareFindings := result.MalwareFindings()
 	if len(malwareFindings) > 0 {
-		display.Print("")
-		display.Error("Malware/Supply Chain Threats:")
+		blocksMalware := cfg.ShouldBlock(cfg.Scanning.Policy.Malware)
+
+		var shown []scanner.Finding
 		for _, f := range malwareFindings {
-			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Description)
+			if !blocksMalware && seenStore.Record(f) {
+				suppressed++
+				continue
+			}
+			shown = append(shown, f)
 		}
-		if cfg.ShouldBlock(cfg.Scanning.Policy.Malware) {
+
+		if len(shown) > 0 {
+			display.Print("")
+			display.Error("Malware/Supply Chain Threats:")
+			for _, f := range shown {
+				display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Description)
+			}
+		}
+		if blocksMalware {
 			hasBlockingIssue = true
 		}
 	}
@@ -211,9 +441,6 @@ func evaluateScanResults(cfg *config.Config, display *ui.UI, result *scanner.Agg
 	// Display CVE findings by severity
 	cveFindings := result.CVEFindings()
 	if len(cveFindings) > 0 {
-		display.Print("")
-		display.Warning("Vulnerabilities (CVEs):")
-
 		// Group by severity
 		severities := []scanner.Severity{
 			scanner.SeverityCritical,
@@ -222,23 +449,213 @@ func evaluateScanResults(cfg *config.Config, display *ui.UI, result *scanner.Agg
 			scanner.SeverityLow,
 		}
 
+		var shown []scanner.Finding
 		for _, sev := range severities {
-			count := 0
 			for _, f := range cveFindings {
-				if f.Severity == sev {
-					count++
-					display.ThreatFound(string(sev), f.Package+"@"+f.Version, f.Title)
-
-					// Check if this severity blocks
-					action := cfg.GetCVEAction(string(sev))
-					if cfg.ShouldBlock(action) {
-						hasBlockingIssue = true
-					}
+				if f.Severity != sev {
+					continue
 				}
+
+				// Check if this severity blocks
+				action := cfg.GetCVEAction(string(sev))
+				if cfg.ShouldBlock(action) {
+					hasBlockingIssue = true
+				} else if seenStore.Record(f) {
+					suppressed++
+					continue
+				}
+				shown = append(shown, f)
+			}
+		}
+
+		if len(shown) > 0 {
+			display.Print("")
+			display.Warning("Vulnerabilities (CVEs):")
+			for _, f := range shown {
+				display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Title)
+				if f.Remediation != "" {
+					display.ThreatDetail(f.Remediation)
+				}
+			}
+		}
+	}
+
+	// Display license findings the policy does not ignore
+	var shownLicenses []scanner.Finding
+	for _, f := range result.LicenseFindings() {
+		action := cfg.GetLicenseAction(f.License)
+		switch {
+		case cfg.ShouldBlock(action):
+			hasBlockingIssue = true
+		case !cfg.ShouldWarn(action):
+			continue
+		case seenStore.Record(f):
+			suppressed++
+			continue
+		}
+		shownLicenses = append(shownLicenses, f)
+	}
+	if len(shownLicenses) > 0 {
+		display.Print("")
+		display.Warning("License Issues:")
+		for _, f := range shownLicenses {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, licenseDescription(f))
+		}
+	}
+
+	// Display install scripts unless the policy ignores them
+	scriptFindings := result.InstallScriptFindings()
+	scriptAction := cfg.Scanning.Policy.InstallScripts
+	if len(scriptFindings) > 0 && (cfg.ShouldBlock(scriptAction) || cfg.ShouldWarn(scriptAction)) {
+		blocksScripts := cfg.ShouldBlock(scriptAction)
+
+		var shown []scanner.Finding
+		for _, f := range scriptFindings {
+			if !blocksScripts && seenStore.Record(f) {
+				suppressed++
+				continue
 			}
+			shown = append(shown, f)
+		}
+
+		if len(shown) > 0 {
+			display.Print("")
+			display.Warning("Install Scripts:")
+			for _, f := range shown {
+				display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Description)
+			}
+			display.Info("Review these scripts, or install with --ignore-scripts to skip them")
+		}
+		if blocksScripts {
+			hasBlockingIssue = true
+		}
+	}
+
+	// Display provenance findings. Without require, only versions that
+	// dropped provenance are shown; the rest are counted.
+	var shownProvenance []scanner.Finding
+	var unattested int
+	provenanceAction := cfg.Scanning.Policy.Provenance
+	for _, f := range result.ProvenanceFindings() {
+		switch {
+		case cfg.Scanning.Provenance.Require:
+			hasBlockingIssue = true
+		case f.Severity == scanner.SeverityInfo:
+			unattested++
+			continue
+		case cfg.ShouldBlock(provenanceAction):
+			hasBlockingIssue = true
+		case !cfg.ShouldWarn(provenanceAction):
+			continue
+		case seenStore.Record(f):
+			suppressed++
+			continue
+		}
+		shownProvenance = append(shownProvenance, f)
+	}
+	if len(shownProvenance) > 0 {
+		display.Print("")
+		display.Warning("Provenance:")
+		for _, f := range shownProvenance {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Title)
+		}
+	}
+	if unattested > 0 && (cfg.ShouldBlock(provenanceAction) || cfg.ShouldWarn(provenanceAction)) {
+		display.Print("")
+		display.Info(fmt.Sprintf("%d package(s) have no provenance attestation", unattested))
+	}
+
+	// Display versions inside the min_release_age quarantine
+	var shownReleaseAge []scanner.Finding
+	releaseAgeAction := cfg.Scanning.Policy.ReleaseAge
+	for f := range result.FindingsOfType(scanner.FindingTypeReleaseAge) {
+		switch {
+		case cfg.ShouldBlock(releaseAgeAction):
+			hasBlockingIssue = true
+		case !cfg.ShouldWarn(releaseAgeAction):
+			continue
+		case seenStore.Record(*f):
+			suppressed++
+			continue
+		}
+		shownReleaseAge = append(shownReleaseAge, *f)
+	}
+	if len(shownReleaseAge) > 0 {
+		display.Print("")
+		display.Warning("Release Age Quarantine:")
+		for _, f := range shownReleaseAge {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Title)
+			display.ThreatDetail(f.Remediation)
+		}
+	}
+
+	// Display packages below scanning.socket.min_score
+	var shownLowScore []scanner.Finding
+	lowScoreAction := cfg.Scanning.Policy.LowScore
+	for f := range result.FindingsOfType(scanner.FindingTypeLowScore) {
+		switch {
+		case cfg.ShouldBlock(lowScoreAction):
+			hasBlockingIssue = true
+		case !cfg.ShouldWarn(lowScoreAction):
+			continue
+		case seenStore.Record(*f):
+			suppressed++
+			continue
+		}
+		shownLowScore = append(shownLowScore, *f)
+	}
+	if len(shownLowScore) > 0 {
+		display.Print("")
+		display.Warning("Low Socket Scores:")
+		for _, f := range shownLowScore {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Title)
+			display.ThreatDetail(f.Description)
+		}
+	}
+
+	// Display deprecated packages and registry metadata risk signals; they
+	// never block
+	var shownSignals, shownDeprecated []scanner.Finding
+	for f := range result.FindingsOfType(scanner.FindingTypeMaintainer, scanner.FindingTypeQuality) {
+		if seenStore.Record(*f) {
+			suppressed++
+			continue
+		}
+		if f.Deprecated != "" {
+			shownDeprecated = append(shownDeprecated, *f)
+		} else {
+			shownSignals = append(shownSignals, *f)
+		}
+	}
+	if len(shownDeprecated) > 0 {
+		display.Print("")
+		display.Warning("Deprecated Packages:")
+		for _, f := range shownDeprecated {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Deprecated)
+		}
+	}
+	if len(shownSignals) > 0 {
+		display.Print("")
+		display.Warning("Risk Signals:")
+		for _, f := range shownSignals {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Title)
+			display.ThreatDetail(f.Description)
 		}
 	}
 
+	if suppressed > 0 {
+		display.Print("")
+		display.Info(fmt.Sprintf("%d previously reported warning(s) unchanged — run `snapem scan` to review", suppressed))
+	}
+	if len(result.Baselined) > 0 {
+		display.Print("")
+		display.Info(fmt.Sprintf("Baseline (%d suppressed) — run `snapem scan --verbose` to review", len(result.Baselined)))
+	}
+
+	if err := seenStore.Save(); err != nil {
+		display.Verbose(fmt.Sprintf("Could not save seen findings: %v", err))
+	}
+
 	if hasBlockingIssue {
 		display.Print("")
 		display.Error("Security scan blocked installation due to detected threats")
@@ -248,6 +665,73 @@ func evaluateScanResults(cfg *config.Config, display *ui.UI, result *scanner.Agg
 	return nil
 }
 
+// licenseDescription names the license, when known, before the scanner's
+// message
+func licenseDescription(f scanner.Finding) string {
+	if f.License == "" {
+		return f.Description
+	}
+	return f.License + ": " + f.Description
+}
+
+// markLockfileChanges marks the locked packages that npm has not installed
+// yet as new, so checks for packages about to be installed cover lockfile
+// changes too
+func markLockfileChanges(display *ui.UI, parser *manifest.Parser, packages []manifest.Package) {
+	installed, ok := parser.InstalledPackages()
+	if !ok {
+		return
+	}
+	var changed int
+	for i, pkg := range packages {
+		if !installed[pkg.Name+"@"+pkg.Version] {
+			packages[i].New = true
+			changed++
+		}
+	}
+	if changed > 0 {
+		display.Verbose(fmt.Sprintf("%d package(s) in the lockfile are not installed yet", changed))
+	}
+}
+
+// resolveNewPackages resolves tags and ranges of packages about to be
+// installed to the versions the registry would serve, and adds their
+// dependency tree within the scanning.preinstall limits. If the registry
+// cannot be reached, whatever was resolved is scanned.
+func resolveNewPackages(ctx context.Context, cfg *config.Config, display *ui.UI, packages []manifest.Package) []manifest.Package {
+	if len(packages) == 0 || simulation.AffectsScan() {
+		return packages
+	}
+
+	preinstall := cfg.Scanning.Preinstall
+	if preinstall.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, preinstall.Timeout)
+		defer cancel()
+	}
+
+	limits := registry.Limits{MaxDepth: preinstall.MaxDepth, MaxPackages: preinstall.MaxPackages}
+	tree, err := registry.NewClient(cfg.PackageManager.Registries()).ResolveTree(ctx, packages, limits, func(resolved int) {
+		display.Progress(fmt.Sprintf("Resolving dependency tree… %d packages", resolved))
+	})
+	display.ProgressDone(fmt.Sprintf("Resolved dependency tree: %d packages", len(tree.Packages)))
+
+	if err != nil {
+		display.Warning(fmt.Sprintf("Could not resolve versions from %s: %v", cfg.PackageManager.Registry, err))
+		display.Warning("Scanning the packages resolved so far; versions may not match what is installed")
+	}
+	if tree.Truncated {
+		display.Warning(fmt.Sprintf("Dependency tree cut short by scanning.preinstall limits (max_depth %d, max_packages %d); the rest is not scanned before install",
+			preinstall.MaxDepth, preinstall.MaxPackages))
+	}
+	for _, pkg := range tree.Packages {
+		if pkg.Direct {
+			display.Verbose(fmt.Sprintf("Resolved %s@%s", pkg.Name, pkg.Version))
+		}
+	}
+	return tree.Packages
+}
+
 // parsePackageArg parses a package argument like "lodash@4.17.20" into name and version
 func parsePackageArg(pkg string) (name, version string) {
 	// Handle scoped packages like @types/node@1.0.0
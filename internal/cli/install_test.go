@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// cleanScanner reports no findings
+type cleanScanner struct{}
+
+func (cleanScanner) Name() string      { return "clean" }
+func (cleanScanner) IsAvailable() bool { return true }
+
+func (cleanScanner) Scan(ctx context.Context, packages []manifest.Package) (*scanner.ScanResult, error) {
+	return &scanner.ScanResult{Scanner: "clean", Packages: len(packages)}, nil
+}
+
+// TestBlocklistBlocksInstall scans through the progress path install uses
+// and checks that the install decision refuses a blocklisted package that
+// no scanner reports, and allows the same package when it is not listed
+func TestBlocklistBlocksInstall(t *testing.T) {
+	tests := []struct {
+		name      string
+		blocklist []string
+		want      int
+	}{
+		{"blocklisted", []string{"left-pad"}, errors.ExitSecurityBlock},
+		{"not blocklisted", []string{"is-odd"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Scanning.Policy.Malware = "block"
+			cfg.Scanning.Policy.Blocklist = tt.blocklist
+
+			orch := scanner.NewOrchestrator(cfg)
+			orch.SetScanners(cleanScanner{})
+
+			var reported []string
+			progress := func(name string, completed, total int, done bool) {
+				if done {
+					reported = append(reported, name)
+				}
+			}
+			packages := []manifest.Package{{Name: "left-pad", Version: "1.3.0", Ecosystem: "npm", Direct: true, New: true}}
+			result, err := orch.ScanWithProgress(context.Background(), packages, progress)
+			if err != nil {
+				t.Fatalf("ScanWithProgress() error = %v", err)
+			}
+			if len(reported) != 1 || reported[0] != "clean" {
+				t.Errorf("progress reported %v, want the clean scanner", reported)
+			}
+
+			err = evaluateScanResults(cfg, ui.NewWriter(io.Discard, false, false, false), result, nil)
+			if tt.want == 0 {
+				if err != nil {
+					t.Errorf("evaluateScanResults() = %v, want the install allowed", err)
+				}
+				return
+			}
+			if code := errors.ExitCodeFor(err); code != tt.want {
+				t.Errorf("exit code = %d (%v), want %d", code, err, tt.want)
+			}
+			if !result.HasMalware {
+				t.Error("HasMalware = false for a blocklisted package")
+			}
+		})
+	}
+}
+
+func TestJoinBlocks(t *testing.T) {
+	verify := errors.SecurityBlockError("lockfile verification failed")
+	scan := errors.New(errors.ExitGeneralError, "scan failed")
+
+	tests := []struct {
+		name      string
+		verifyErr error
+		scanErr   error
+		want      int
+	}{
+		{"neither", nil, nil, 0},
+		{"verify only", verify, nil, errors.ExitSecurityBlock},
+		{"scan only", nil, scan, errors.ExitGeneralError},
+		{"verify block wins", verify, scan, errors.ExitSecurityBlock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := joinBlocks(tt.verifyErr, tt.scanErr)
+			if tt.want == 0 {
+				if err != nil {
+					t.Fatalf("joinBlocks() = %v, want nil", err)
+				}
+				return
+			}
+			if code := errors.ExitCodeFor(err); code != tt.want {
+				t.Errorf("exit code = %d (%v), want %d", code, err, tt.want)
+			}
+		})
+	}
+
+	// Both messages reach the single confirmation
+	err := joinBlocks(verify, scan)
+	for _, msg := range []string{verify.Error(), scan.Error()} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("joinBlocks() = %q, want it to mention %q", err, msg)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	// limitMemory holds --memory for the current invocation
+	limitMemory string
+
+	// limitCPUs holds --cpus for the current invocation
+	limitCPUs string
+)
+
+// addLimitFlags registers the --memory and --cpus flags on a command
+func addLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&limitMemory, "memory", "", "container memory limit, e.g. 2g (container.limits.memory)")
+	cmd.Flags().StringVar(&limitCPUs, "cpus", "", "container CPU limit, e.g. 2 (container.limits.cpus)")
+}
+
+// applyLimitFlags overrides the configured resource limits with --memory
+// and --cpus, failing before anything runs if a value is malformed
+func applyLimitFlags(cfg *config.Config, display *ui.UI) error {
+	if limitMemory != "" {
+		if err := container.ValidateMemory(limitMemory); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+		cfg.Container.Limits.Memory = limitMemory
+	}
+	if limitCPUs != "" {
+		if err := container.ValidateCPUs(limitCPUs); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+		cfg.Container.Limits.CPUs = limitCPUs
+	}
+	return nil
+}
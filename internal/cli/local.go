@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// localPackageMount is where local packages outside the project are mounted
+const localPackageMount = "/snapem/local"
+
+// localInstall holds install arguments with tarball and directory specs
+// resolved
+type localInstall struct {
+	// args are passed to the package manager, with local paths translated
+	// to their location in the container
+	args []string
+
+	// scanArgs are name@version for the scanners
+	scanArgs []string
+
+	// mounts are read-only mounts for local packages outside the project
+	mounts []container.VolumeMount
+}
+
+// resolveLocalPackages reads the package.json of every local spec in args
+// so that missing paths fail before a scan or container starts. Paths are
+// translated only when the install runs in a container.
+func resolveLocalPackages(display *ui.UI, projectDir string, args []string, inContainer bool) (*localInstall, error) {
+	local := &localInstall{}
+	mounted := make(map[string]string)
+
+	for _, arg := range args {
+		if !manifest.IsLocalSpec(arg) {
+			local.args = append(local.args, arg)
+			local.scanArgs = append(local.scanArgs, arg)
+			continue
+		}
+
+		pkg, err := manifest.ReadLocalPackage(arg, projectDir)
+		if err != nil {
+			display.Error(err.Error())
+			return nil, err
+		}
+		display.Verbose(fmt.Sprintf("%s is %s@%s", arg, pkg.Name, pkg.Version))
+		local.scanArgs = append(local.scanArgs, pkg.Name+"@"+pkg.Version)
+
+		if scripts := pkg.InstallScripts(); len(scripts) > 0 {
+			display.Warning(fmt.Sprintf("%s runs install scripts: %s", arg, strings.Join(scripts, ", ")))
+		}
+
+		if !inContainer {
+			local.args = append(local.args, arg)
+			continue
+		}
+
+		// Paths inside the project are already visible under /app
+		if rel, err := filepath.Rel(projectDir, pkg.Path); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			local.args = append(local.args, "file:"+filepath.ToSlash(rel))
+			continue
+		}
+
+		hostDir, file := pkg.Path, ""
+		if pkg.Tarball {
+			hostDir, file = filepath.Dir(pkg.Path), filepath.Base(pkg.Path)
+		}
+		mountPoint, ok := mounted[hostDir]
+		if !ok {
+			mountPoint = fmt.Sprintf("%s/%d", localPackageMount, len(local.mounts))
+			mounted[hostDir] = mountPoint
+			local.mounts = append(local.mounts, container.VolumeMount{
+				HostPath:      hostDir,
+				ContainerPath: mountPoint,
+				ReadOnly:      true,
+			})
+		}
+
+		containerPath := path.Join(mountPoint, file)
+		local.args = append(local.args, "file:"+containerPath)
+		display.Warning(fmt.Sprintf("%s is outside the project; package.json will reference it as file:%s", arg, containerPath))
+		display.Info("  Copy it into the project to keep the dependency resolvable outside the container")
+	}
+
+	return local, nil
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// withNpmrc holds --with-npmrc for the current invocation
+var withNpmrc bool
+
+// addNpmrcFlag registers the --with-npmrc flag on a command
+func addNpmrcFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&withNpmrc, "with-npmrc", false, "mount your ~/.npmrc read-only for private registry auth (container.mount_npmrc)")
+}
+
+// applyNpmrcFlag turns on container.mount_npmrc for this invocation
+func applyNpmrcFlag(cfg *config.Config) {
+	if withNpmrc {
+		cfg.Container.MountNpmrc = true
+	}
+}
+
+// containerNpmrc is where the user's .npmrc is mounted in the container;
+// npm_config_userconfig points npm there whatever HOME is
+const containerNpmrc = "/snapem/npmrc"
+
+// userNpmrc returns the host's user-level .npmrc path
+func userNpmrc() string {
+	if path := os.Getenv("NPM_CONFIG_USERCONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".npmrc")
+}
+
+// applyNpmrc mounts the user's .npmrc read-only so private registry auth
+// works in the container. The project's .npmrc is already in the project
+// mount. The file's path is shown in the command, never its contents.
+func applyNpmrc(display *ui.UI, opts *container.RunOptions) {
+	path := userNpmrc()
+	if info, err := os.Stat(path); path == "" || err != nil || info.IsDir() {
+		display.Verbose("No user .npmrc to mount")
+		return
+	}
+
+	opts.Volumes = append(opts.Volumes, container.VolumeMount{
+		HostPath:      path,
+		ContainerPath: containerNpmrc,
+		ReadOnly:      true,
+	})
+	if opts.Environment == nil {
+		opts.Environment = make(map[string]string)
+	}
+	opts.Environment["npm_config_userconfig"] = containerNpmrc
+	display.Warning(fmt.Sprintf("Mounting %s into the container; any registry tokens in it are readable by install scripts", path))
+}
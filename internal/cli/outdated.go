@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var outdatedJSON bool
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List outdated dependencies",
+	Long: `Runs the package manager's outdated check inside an isolated container
+and lists each outdated dependency with its current, wanted and latest
+version.
+
+npm and Yarn classic are parsed into a table; --json prints the same rows as
+a JSON array. bun only prints its own table, which is passed through.
+
+Examples:
+  snapem outdated              # Table of outdated dependencies
+  snapem outdated --json       # JSON array for scripts`,
+	Args: cobra.NoArgs,
+	RunE: runOutdated,
+}
+
+func init() {
+	outdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "output results as JSON")
+	outdatedCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	addVolumeOptFlag(outdatedCmd)
+
+	rootCmd.AddCommand(outdatedCmd)
+}
+
+func runOutdated(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI; with --json, stdout is reserved for the document
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+	if outdatedJSON {
+		display = ui.NewWriter(os.Stderr, cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+	}
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	// Check for package.json
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	// Detect package manager
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
+
+	outdatedCommand := mgr.OutdatedCommand()
+	if outdatedCommand == nil {
+		display.Error(fmt.Sprintf("%s has no outdated command", mgr.Name()))
+		return errors.New(errors.ExitGeneralError, mgr.Name()+" has no outdated command")
+	}
+
+	if !cfg.Container.Enabled || noContainer {
+		display.Warning("Running without container isolation (--no-container)")
+		display.Info(fmt.Sprintf("Command: %s %v", mgr.Name(), outdatedCommand))
+		return nil
+	}
+
+	networkMode := container.NetworkMode(cfg.Container.Network)
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, outdatedCommand)
+	var out bytes.Buffer
+	opts.Stdout = &out
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+		return err
+	}
+
+	display.ContainerHeader(runtime.CommandString(opts))
+
+	// npm and yarn exit non-zero when anything is outdated, so the output
+	// decides whether the run failed
+	runErr := runtime.Run(ctx, opts)
+
+	packages, err := pkgmanager.ParseOutdated(mgr, out.Bytes())
+	if stderrors.Is(err, pkgmanager.ErrNoStructuredOutput) {
+		if outdatedJSON {
+			display.Warning(fmt.Sprintf("%s has no JSON outdated output; showing its table", mgr.Name()))
+		}
+		os.Stdout.Write(out.Bytes())
+		return runErr
+	}
+	if err != nil {
+		if runErr != nil {
+			return runErr
+		}
+		display.Error(err.Error())
+		return errors.Wrap(errors.ExitGeneralError, "failed to parse outdated output", err)
+	}
+	if runErr != nil && len(packages) == 0 {
+		return runErr
+	}
+
+	if outdatedJSON {
+		if packages == nil {
+			packages = []pkgmanager.OutdatedPackage{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(packages)
+	}
+
+	renderOutdated(display, packages)
+	return nil
+}
+
+// renderOutdated prints one row per outdated package
+func renderOutdated(display *ui.UI, packages []pkgmanager.OutdatedPackage) {
+	if len(packages) == 0 {
+		display.Success("All dependencies are up to date")
+		return
+	}
+
+	display.Print("")
+	display.Print(fmt.Sprintf("  %-40s %-12s %-12s %-12s %s", "PACKAGE", "CURRENT", "WANTED", "LATEST", "TYPE"))
+	for _, p := range packages {
+		current := p.Current
+		if current == "" {
+			current = "missing"
+		}
+		display.Print(fmt.Sprintf("  %-40s %-12s %-12s %-12s %s", p.Name, current, p.Wanted, p.Latest, dependencyType(p.Type)))
+	}
+}
+
+// dependencyType shortens a package.json section name
+func dependencyType(section string) string {
+	switch section {
+	case "dependencies":
+		return "prod"
+	case "devDependencies":
+		return "dev"
+	case "optionalDependencies":
+		return "optional"
+	case "peerDependencies":
+		return "peer"
+	}
+	return section
+}
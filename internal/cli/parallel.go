@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// defaultParallel is the number of workspace containers install
+// --workspaces runs at once
+const defaultParallel = 4
+
+var (
+	installWorkspaces bool
+	installParallel   int
+)
+
+// workspaceLifecycleScripts are the scripts npm runs for a workspace
+// package during install, in order
+var workspaceLifecycleScripts = []string{"preinstall", "install", "postinstall", "prepare"}
+
+// addWorkspacesFlags registers --workspaces and --parallel on install
+func addWorkspacesFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&installWorkspaces, "workspaces", false, "install every workspace, running their lifecycle scripts in parallel containers")
+	cmd.Flags().IntVar(&installParallel, "parallel", defaultParallel, "with --workspaces, how many workspaces to run at once")
+}
+
+// checkWorkspacesFlags rejects --workspaces and --parallel where they do
+// not apply
+func checkWorkspacesFlags(cmd *cobra.Command, display *ui.UI, args []string) error {
+	var msg string
+	switch {
+	case !installWorkspaces && cmd.Flags().Changed("parallel"):
+		msg = "--parallel needs --workspaces"
+	case !installWorkspaces:
+		return nil
+	case len(args) > 0:
+		msg = "--workspaces installs the project's dependencies and cannot be combined with packages"
+	case workspaceName != "":
+		msg = "--workspaces and --workspace cannot be combined"
+	case installParallel < 1:
+		msg = fmt.Sprintf("--parallel must be at least 1, got %d", installParallel)
+	default:
+		return nil
+	}
+	display.Error(msg)
+	return errors.New(errors.ExitGeneralError, msg)
+}
+
+// projectWorkspaces returns the workspaces install --workspaces covers
+func projectWorkspaces(display *ui.UI, parser *manifest.Parser) ([]manifest.Workspace, error) {
+	workspaces, err := parser.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		display.Error("--workspaces needs a package.json with a workspaces field")
+		return nil, errors.ManifestError("no workspaces defined", nil)
+	}
+	return workspaces, nil
+}
+
+// workspacePlan is how install --workspaces runs for a package manager
+type workspacePlan struct {
+	// install is run once, in a container with the whole project
+	install []string
+
+	// scripts, if set, is then run in each workspace directory, in
+	// parallel containers
+	scripts []string
+}
+
+// planWorkspaceInstall chooses how to install every workspace with
+// parallel workspaces at once. Workspaces share the root node_modules and
+// lockfile, so npm installs them together without lifecycle scripts,
+// runs the scripts of the dependencies and the root, and leaves the
+// workspaces' own scripts for parallel containers. bun runs scripts
+// concurrently itself; yarn has no parallel install and installs the
+// workspaces in one container.
+func planWorkspaceInstall(mgr pkgmanager.Manager, parallel int, ignoreScripts bool) workspacePlan {
+	install := mgr.InstallCommand(nil, pkgmanager.InstallOptions{IgnoreScripts: ignoreScripts})
+	switch mgr.Name() {
+	case "npm":
+		if ignoreScripts {
+			return workspacePlan{install: install}
+		}
+		scripts := make([]string, len(workspaceLifecycleScripts))
+		for i, script := range workspaceLifecycleScripts {
+			scripts[i] = "npm run --if-present " + script
+		}
+		return workspacePlan{
+			install: []string{"sh", "-c", "npm install --ignore-scripts && npm rebuild --workspaces=false"},
+			scripts: []string{"sh", "-c", strings.Join(scripts, " && ")},
+		}
+	case "bun":
+		return workspacePlan{install: append(install, "--concurrent-scripts="+strconv.Itoa(parallel))}
+	}
+	return workspacePlan{install: install}
+}
+
+// runWorkspacePlan runs the plan's install, then its scripts in each
+// workspace, parallel at a time. Output of the workspace containers is
+// written to stdout and stderr line by line behind the workspace name.
+func runWorkspacePlan(ctx context.Context, display *ui.UI, runtime container.Runtime, opts *container.RunOptions, plan workspacePlan, workspaces []manifest.Workspace, parallel int, stdout, stderr io.Writer) error {
+	display.ContainerHeader(runtime.CommandString(opts))
+	if err := runtime.Run(ctx, opts); err != nil {
+		return err
+	}
+	if plan.scripts == nil {
+		return nil
+	}
+
+	display.Print("")
+	display.Info(fmt.Sprintf("Running lifecycle scripts of %d workspace(s), %d at a time", len(workspaces), parallel))
+
+	var mu sync.Mutex
+	errs := make([]error, len(workspaces))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, ws := range workspaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+
+			prefix := "[" + ws.Name + "] "
+			out := &prefixWriter{w: stdout, mu: &mu, prefix: prefix}
+			errOut := &prefixWriter{w: stderr, mu: &mu, prefix: prefix}
+			wsOpts := workspaceRunOptions(opts, ws, plan.scripts)
+			wsOpts.Name = fmt.Sprintf("%s-%d", container.ForegroundName(wsOpts), i+1)
+			wsOpts.Stdout, wsOpts.Stderr = out, errOut
+			errs[i] = runtime.Run(ctx, wsOpts)
+			out.Flush()
+			errOut.Flush()
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, workspaces[i].Name)
+			display.Error(fmt.Sprintf("%s: %v", workspaces[i].Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		msg := fmt.Sprintf("lifecycle scripts failed in %d of %d workspace(s): %s", len(failed), len(workspaces), strings.Join(failed, ", "))
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+	return nil
+}
+
+// workspaceRunOptions returns the options for running command in a
+// workspace: the project is mounted read-only, so the shared node_modules
+// and lockfile cannot change, and only the workspace directory is
+// writable
+func workspaceRunOptions(opts *container.RunOptions, ws manifest.Workspace, command []string) *container.RunOptions {
+	wsOpts := *opts
+	wsOpts.Command = command
+	wsOpts.WorkDir = path.Join(opts.WorkDir, ws.Dir)
+	wsOpts.Volumes = slices.Clone(opts.Volumes)
+	for i, v := range wsOpts.Volumes {
+		if v.ContainerPath != opts.WorkDir {
+			continue
+		}
+		wsOpts.Volumes[i].ReadOnly = true
+		wsOpts.Volumes = append(wsOpts.Volumes, container.VolumeMount{
+			HostPath:      filepath.Join(v.HostPath, filepath.FromSlash(ws.Dir)),
+			ContainerPath: wsOpts.WorkDir,
+			Options:       v.Options,
+		})
+		break
+	}
+	return &wsOpts
+}
+
+// prefixWriter writes each line to w behind prefix. Writers sharing mu
+// never interleave their lines.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+// Write buffers b and writes out its complete lines
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes out a final line without a newline
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	io.WriteString(p.w, p.prefix)
+	p.w.Write(line)
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+func TestPlanWorkspaceInstall(t *testing.T) {
+	tests := []struct {
+		mgr           pkgmanager.Manager
+		ignoreScripts bool
+		install       string
+		scripts       bool
+	}{
+		{pkgmanager.NewNPM(""), false, "sh -c npm install --ignore-scripts && npm rebuild --workspaces=false", true},
+		{pkgmanager.NewNPM(""), true, "npm install --ignore-scripts", false},
+		{pkgmanager.NewBun(""), false, "bun install --concurrent-scripts=3", false},
+		{pkgmanager.NewYarn("", false), false, "yarn install", false},
+		{pkgmanager.NewYarn("", true), true, "yarn install --mode=skip-build", false},
+	}
+	for _, tt := range tests {
+		plan := planWorkspaceInstall(tt.mgr, 3, tt.ignoreScripts)
+		if got := strings.Join(plan.install, " "); got != tt.install {
+			t.Errorf("%s (ignore scripts %v): install = %q, want %q", tt.mgr.Name(), tt.ignoreScripts, got, tt.install)
+		}
+		if (plan.scripts != nil) != tt.scripts {
+			t.Errorf("%s (ignore scripts %v): scripts = %q", tt.mgr.Name(), tt.ignoreScripts, plan.scripts)
+		}
+	}
+
+	plan := planWorkspaceInstall(pkgmanager.NewNPM(""), 3, false)
+	if got := plan.scripts[len(plan.scripts)-1]; !strings.Contains(got, "npm run --if-present postinstall") {
+		t.Errorf("npm scripts = %q, want the postinstall script", got)
+	}
+}
+
+func TestWorkspaceRunOptions(t *testing.T) {
+	base := pkgmanager.BuildContainerOptions(pkgmanager.NewNPM(""), "/src/mono", "/cache", container.NetworkBridge, []string{"npm", "install"})
+	opts := workspaceRunOptions(base, manifest.Workspace{Name: "@mono/web", Dir: "packages/web"}, []string{"true"})
+
+	if opts.WorkDir != "/app/packages/web" {
+		t.Errorf("WorkDir = %q, want /app/packages/web", opts.WorkDir)
+	}
+	var project, ws bool
+	for _, v := range opts.Volumes {
+		switch v.ContainerPath {
+		case "/app":
+			project = v.ReadOnly
+		case "/app/packages/web":
+			ws = !v.ReadOnly && v.HostPath == "/src/mono/packages/web"
+		}
+	}
+	if !project || !ws {
+		t.Errorf("Volumes = %+v, want the project read-only and the workspace writable", opts.Volumes)
+	}
+	if base.Volumes[0].ReadOnly || len(base.Volumes) != 2 {
+		t.Errorf("base options were changed: %+v", base.Volumes)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{w: &out, mu: &sync.Mutex{}, prefix: "[web] "}
+	fmt.Fprint(w, "one\ntw")
+	fmt.Fprint(w, "o\nthree")
+	w.Flush()
+
+	want := "[web] one\n[web] two\n[web] three\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+// workspaceRuntime runs the install, and fails the workspace containers
+// whose working directory is in fail
+type workspaceRuntime struct {
+	container.Runtime
+	mu      sync.Mutex
+	workDir []string
+	fail    []string
+}
+
+func (r *workspaceRuntime) CommandString(opts *container.RunOptions) string {
+	return strings.Join(opts.Command, " ")
+}
+
+func (r *workspaceRuntime) Run(ctx context.Context, opts *container.RunOptions) error {
+	r.mu.Lock()
+	r.workDir = append(r.workDir, opts.WorkDir)
+	r.mu.Unlock()
+	if opts.Stdout != nil {
+		fmt.Fprintln(opts.Stdout, "built")
+	}
+	if slices.Contains(r.fail, opts.WorkDir) {
+		return errors.New(errors.ExitGeneralError, "container command failed")
+	}
+	return nil
+}
+
+func TestRunWorkspacePlan(t *testing.T) {
+	workspaces := []manifest.Workspace{
+		{Name: "api", Dir: "packages/api"},
+		{Name: "web", Dir: "packages/web"},
+		{Name: "docs", Dir: "docs"},
+	}
+	base := pkgmanager.BuildContainerOptions(pkgmanager.NewNPM(""), "/src/mono", "", container.NetworkBridge, nil)
+	plan := planWorkspaceInstall(pkgmanager.NewNPM(""), 2, false)
+	display := ui.NewWriter(io.Discard, false, false, false)
+
+	runtime := &workspaceRuntime{}
+	var stdout bytes.Buffer
+	if err := runWorkspacePlan(context.Background(), display, runtime, base, plan, workspaces, 2, &stdout, io.Discard); err != nil {
+		t.Fatalf("runWorkspacePlan() error = %v", err)
+	}
+	if len(runtime.workDir) != 4 || runtime.workDir[0] != "/app" {
+		t.Errorf("containers ran in %q, want the install then each workspace", runtime.workDir)
+	}
+	for _, ws := range workspaces {
+		if !strings.Contains(stdout.String(), "["+ws.Name+"] built\n") {
+			t.Errorf("output %q lacks the prefixed line of %s", stdout.String(), ws.Name)
+		}
+	}
+
+	runtime = &workspaceRuntime{fail: []string{"/app/packages/web", "/app/docs"}}
+	err := runWorkspacePlan(context.Background(), display, runtime, base, plan, workspaces, 2, io.Discard, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 workspace(s): web, docs") {
+		t.Errorf("runWorkspacePlan() error = %v, want web and docs listed as failed", err)
+	}
+	if len(runtime.workDir) != 4 {
+		t.Errorf("ran %d containers, want every workspace to run despite failures", len(runtime.workDir))
+	}
+
+	runtime = &workspaceRuntime{}
+	yarn := planWorkspaceInstall(pkgmanager.NewYarn("", false), 2, false)
+	if err := runWorkspacePlan(context.Background(), display, runtime, base, yarn, workspaces, 2, io.Discard, io.Discard); err != nil {
+		t.Fatalf("runWorkspacePlan() error = %v", err)
+	}
+	if len(runtime.workDir) != 1 {
+		t.Errorf("yarn ran %d containers, want a single install", len(runtime.workDir))
+	}
+}
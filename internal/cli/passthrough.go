@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var rawPassthrough bool
+
+// passthroughCommand is the hidden command that unknown subcommands are
+// rewritten to
+const passthroughCommand = "passthrough"
+
+// scannedSubcommands maps package manager subcommands that add
+// dependencies to the snapem command that scans them first. They are
+// never passed through, even with --raw.
+var scannedSubcommands = map[string]string{
+	"install":       "install",
+	"i":             "install",
+	"in":            "install",
+	"add":           "install",
+	"ci":            "ci",
+	"clean-install": "ci",
+	"ic":            "ci",
+	"install-clean": "ci",
+	"update":        "update",
+	"up":            "update",
+	"upgrade":       "update",
+}
+
+var passthroughCmd = &cobra.Command{
+	Use:    passthroughCommand + " <args...>",
+	Short:  "Run a package manager subcommand in a container",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE:   runPassthrough,
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&rawPassthrough, "raw", false, "pass the command to the package manager in a container even if snapem has a command of that name")
+
+	rootCmd.AddCommand(passthroughCmd)
+}
+
+// passthroughArgs rewrites the command line so that subcommands snapem
+// doesn't know, or any with --raw, run through the package manager.
+// Subcommands that add dependencies are routed to the scanning command
+// instead.
+func passthroughArgs(args []string) []string {
+	flags, rest := splitGlobalFlags(args)
+	if len(rest) == 0 || strings.HasPrefix(rest[0], "-") {
+		return args
+	}
+	name := rest[0]
+
+	if target, ok := scannedSubcommands[name]; ok {
+		if name == target {
+			return args
+		}
+		return concat(flags, []string{target}, rest[1:])
+	}
+	if !hasRawFlag(flags) && !passthroughTarget(name) {
+		return args
+	}
+	return concat(flags, []string{passthroughCommand, "--"}, rest)
+}
+
+// passthroughTarget returns true if name is not a snapem command
+func passthroughTarget(name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitGlobalFlags splits args into the global flags before the
+// subcommand and the rest, starting with the subcommand
+func splitGlobalFlags(args []string) (flags, rest []string) {
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := rootCmd.PersistentFlags().Lookup(name)
+		if f == nil && !strings.HasPrefix(arg, "--") && len(name) == 1 {
+			f = rootCmd.PersistentFlags().ShorthandLookup(name)
+		}
+		if f == nil {
+			break
+		}
+		i++
+		if !hasValue && f.NoOptDefVal == "" && i < len(args) {
+			i++ // the flag's value
+		}
+	}
+	return args[:i], args[i:]
+}
+
+// hasRawFlag returns true if the global flags turn on --raw
+func hasRawFlag(flags []string) bool {
+	raw := false
+	for _, f := range flags {
+		switch f {
+		case "--raw", "--raw=true":
+			raw = true
+		case "--raw=false":
+			raw = false
+		}
+	}
+	return raw
+}
+
+func concat(parts ...[]string) []string {
+	var all []string
+	for _, p := range parts {
+		all = append(all, p...)
+	}
+	return all
+}
+
+func runPassthrough(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	if rawPassthrough {
+		display.Verbose(fmt.Sprintf("Passing %q to %s in a container (--raw)", args[0], mgr.Name()))
+	} else {
+		display.Verbose(fmt.Sprintf("%q is not a snapem command; passing it to %s in a container", args[0], mgr.Name()))
+	}
+
+	networkMode := container.NetworkHost
+	if cfg.Container.Network == "none" {
+		networkMode = container.NetworkNone
+	}
+
+	return execInContainer(ctx, cfg, display, projectDir, mgr.Image(), networkMode, append([]string{mgr.Name()}, args...))
+}
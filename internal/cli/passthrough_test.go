@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPassthroughArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"snapem command", []string{"scan", "--json"}, []string{"scan", "--json"}},
+		{"command alias", []string{"rm", "lodash"}, []string{"rm", "lodash"}},
+		{"unknown command", []string{"ls", "--all"}, []string{"passthrough", "--", "ls", "--all"}},
+		{"global flags kept", []string{"-v", "-C", "app", "pack"}, []string{"-v", "-C", "app", "passthrough", "--", "pack"}},
+		{"flag value is not the command", []string{"--config", "ls", "scan"}, []string{"--config", "ls", "scan"}},
+		{"add is scanned", []string{"add", "lodash"}, []string{"install", "lodash"}},
+		{"i is scanned", []string{"-q", "i", "-D", "vitest"}, []string{"-q", "install", "-D", "vitest"}},
+		{"raw passes a snapem command", []string{"--raw", "outdated"}, []string{"--raw", "passthrough", "--", "outdated"}},
+		{"raw still scans installs", []string{"--raw", "install", "lodash"}, []string{"--raw", "install", "lodash"}},
+		{"raw still scans add", []string{"--raw", "add", "lodash"}, []string{"--raw", "install", "lodash"}},
+		{"help", []string{"help", "run"}, []string{"help", "run"}},
+		{"completion", []string{"__complete", "in"}, []string{"__complete", "in"}},
+		{"flags only", []string{"--version"}, []string{"--version"}},
+		{"no args", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passthroughArgs(tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("passthroughArgs(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
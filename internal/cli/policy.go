@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// policySets holds --policy-set overrides for the current invocation
+var policySets []string
+
+// appliedPolicySets are the --policy-set overrides applyPolicySets applied,
+// for the audit log
+var appliedPolicySets []string
+
+// addPolicySetFlag registers the repeatable --policy-set flag on a command
+func addPolicySetFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&policySets, "policy-set", nil, "override a policy setting for this run (e.g. --policy-set cve.high=warn)")
+}
+
+// applyPolicySets applies --policy-set overrides after all config layers
+// and keeps the applied ones in appliedPolicySets. Invalid overrides fail
+// before any scanning starts.
+func applyPolicySets(cfg *config.Config, display *ui.UI) error {
+	src := cfg.PolicySource
+	appliedPolicySets = nil
+	for _, assignment := range policySets {
+		if enforced := cfg.PolicyLocked(assignment); enforced != "" {
+			display.Warning(fmt.Sprintf("Ignoring --policy-set %s: the enforced policy in %s requires %s", assignment, src.Location, enforced))
+			continue
+		}
+		if err := cfg.SetPolicy(assignment); err != nil {
+			display.Error(err.Error())
+			return errors.ConfigError(err.Error())
+		}
+		appliedPolicySets = append(appliedPolicySets, assignment)
+	}
+
+	// Make temporary policy changes visible in the output
+	for _, assignment := range appliedPolicySets {
+		display.Warning(fmt.Sprintf("Policy override for this run: %s", assignment))
+	}
+
+	// Say which local settings the shared policy file overrode
+	if src != nil {
+		if src.Cached {
+			display.Warning(fmt.Sprintf("Could not fetch the policy file %s; using the cached copy", src.Location))
+		}
+		for _, ignored := range src.Ignored {
+			display.Warning(fmt.Sprintf("The policy in %s is enforced; ignoring local %s", src.Location, ignored))
+		}
+	}
+
+	return nil
+}
+
+// warnExpiredAllowlist names the allowlist exceptions that have lapsed, so
+// it is clear why a trusted package is scanned again
+func warnExpiredAllowlist(cfg *config.Config, display *ui.UI) {
+	for _, entry := range cfg.ExpiredAllowlist(time.Now()) {
+		display.Warning(fmt.Sprintf("Allowlist exception for %s expired %s; scanning it again", entry.Package, entry.Expires))
+	}
+}
+
+// describeAllowlistEntry summarizes an exception's expiry and reason
+func describeAllowlistEntry(entry config.AllowlistEntry, now time.Time) string {
+	status := "permanent"
+	if entry.Expires != "" {
+		expiresAt, err := entry.ExpiresAt()
+		switch {
+		case err != nil:
+			status = "invalid expiry " + entry.Expires
+		case entry.Expired(now):
+			status = "expired " + entry.Expires
+		default:
+			days := int(math.Ceil(expiresAt.Sub(now).Hours() / 24))
+			status = fmt.Sprintf("expires %s, %d days left", entry.Expires, days)
+		}
+	}
+
+	if entry.Reason != "" {
+		return fmt.Sprintf("%s (%s): %s", entry.Package, status, entry.Reason)
+	}
+	return fmt.Sprintf("%s (%s)", entry.Package, status)
+}
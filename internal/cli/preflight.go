@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/preflight"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// runPreflight checks the host before the project is mounted. packages is
+// the number of packages about to be installed, or 0 when nothing is.
+func runPreflight(cfg *config.Config, display *ui.UI, projectDir string, packages int) error {
+	if noPreflight || !cfg.Preflight.Enabled {
+		return nil
+	}
+
+	problems := preflight.Run(preflight.Options{
+		ProjectDir:    projectDir,
+		Packages:      packages,
+		DiskHeadroom:  cfg.Preflight.DiskHeadroom,
+		ContainerUser: cfg.Container.User,
+	})
+
+	for _, p := range problems {
+		if p.Level == preflight.LevelError {
+			display.Error(p.Message)
+		} else {
+			display.Warning(p.Message)
+		}
+		display.Info("  " + p.Hint)
+	}
+
+	if preflight.HasErrors(problems) {
+		display.Info("Use --no-preflight to skip these checks")
+		return errors.New(errors.ExitGeneralError, "preflight checks failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/redact"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// prepareContainer applies the container settings shared by every command
+// to opts and makes sure the image is available before it runs
+func prepareContainer(ctx context.Context, cfg *config.Config, display *ui.UI, runtime container.Runtime, opts *container.RunOptions) error {
+	if err := checkImage(cfg, display, opts.Image); err != nil {
+		return err
+	}
+	if err := ensureImage(ctx, cfg, display, runtime, opts.Image); err != nil {
+		return err
+	}
+	applyEnvironment(cfg, opts)
+	opts.Memory = cfg.Container.Limits.Memory
+	opts.CPUs = cfg.Container.Limits.CPUs
+	if cfg.Container.User != "" {
+		opts.SetUser(container.ResolveUser(cfg.Container.User))
+	}
+	applyRegistry(cfg, opts)
+	if cfg.Container.MountNpmrc {
+		applyNpmrc(display, opts)
+	}
+	if cfg.Container.ReadOnlySource {
+		applyReadOnlySource(display, opts)
+	}
+	return applyVolumeOptions(cfg, display, runtime, opts)
+}
+
+// applyEnvironment passes the variables listed in container.environment to
+// the container. Variables the command already sets are kept.
+func applyEnvironment(cfg *config.Config, opts *container.RunOptions) {
+	if opts.Environment == nil {
+		opts.Environment = make(map[string]string)
+	}
+	for k, v := range container.HostEnvironment(cfg.Container.Environment, os.LookupEnv) {
+		if _, ok := opts.Environment[k]; !ok {
+			opts.Environment[k] = v
+		}
+		if redact.SecretName(k) {
+			redact.AddValues(v)
+		}
+	}
+}
+
+// applyReadOnlySource mounts the project read-only, seeding a writable
+// in-memory node_modules from the project's own
+func applyReadOnlySource(display *ui.UI, opts *container.RunOptions) {
+	var nodeModules string
+	for _, v := range opts.Volumes {
+		if v.ContainerPath != opts.WorkDir {
+			continue
+		}
+		dir := filepath.Join(v.HostPath, "node_modules")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			nodeModules = dir
+		}
+	}
+
+	opts.ReadOnlySource(nodeModules)
+	display.Verbose("Project mounted read-only; node_modules and /tmp are discarded when the container exits")
+}
+
+// refuseReadOnlySource stops commands that must write the project, such as
+// install, when container.read_only_source or --read-only-src is set
+func refuseReadOnlySource(cfg *config.Config, display *ui.UI, command string) error {
+	if !cfg.Container.ReadOnlySource || !cfg.Container.Enabled || noContainer {
+		return nil
+	}
+	display.Error(fmt.Sprintf("snapem %s writes node_modules and the lockfile, but the project is mounted read-only", command))
+	display.Info(fmt.Sprintf("Run 'snapem %s --read-only-src=false' to allow it for this command", command))
+	return errors.ConfigError(command + " cannot run with a read-only project mount")
+}
+
+// packageCacheDir returns the host directory persisting the package
+// manager's cache, or "" when container.cache_volume is off
+func packageCacheDir(cfg *config.Config, display *ui.UI, mgr pkgmanager.Manager) string {
+	if !cfg.Container.CacheVolume || cfg.Scanning.Cache.Directory == "" {
+		return ""
+	}
+	dir := pkgmanager.CacheDir(cfg.Scanning.Cache.Directory, mgr.Name())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		display.Verbose(fmt.Sprintf("Not caching %s downloads: %v", mgr.Name(), err))
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// loadRegistries reads the registry settings of the user and project
+// .npmrc, so snapem's own metadata lookups reach the same registries as
+// the package manager. Scoped registries are merged, the project's
+// winning; registry= applies only while package_manager.registry (or
+// --registry) is left at the public registry.
+func loadRegistries(cfg *config.Config, display *ui.UI, projectDir string) {
+	pm := &cfg.PackageManager
+	if pm.Scopes == nil {
+		pm.Scopes = make(map[string]string)
+	}
+
+	var npmrcRegistry, source string
+	for _, path := range []string{userNpmrc(), filepath.Join(projectDir, ".npmrc")} {
+		if path == "" {
+			continue
+		}
+		rc, err := registry.ReadNpmrc(path)
+		if err != nil {
+			display.Warning(fmt.Sprintf("Could not read %s: %v", path, err))
+			continue
+		}
+		if rc.Registry != "" {
+			npmrcRegistry, source = rc.Registry, path
+		}
+		maps.Copy(pm.Scopes, rc.Scopes)
+	}
+
+	if npmrcRegistry != "" && isPublicRegistry(cfg) {
+		display.Verbose(fmt.Sprintf("Registry from %s: %s", source, npmrcRegistry))
+		pm.Registry = npmrcRegistry
+	}
+	for _, scope := range slices.Sorted(maps.Keys(pm.Scopes)) {
+		display.Verbose(fmt.Sprintf("Registry for %s: %s", scope, pm.Scopes[scope]))
+	}
+}
+
+// isPublicRegistry returns true if package_manager.registry is the public
+// npm registry
+func isPublicRegistry(cfg *config.Config) bool {
+	return cfg.PackageManager.Registries().URL("") == registry.DefaultURL
+}
+
+// applyRegistry points the package manager in the container at a private
+// package_manager.registry. Scoped registries come from the project's
+// .npmrc, which is part of the mount.
+func applyRegistry(cfg *config.Config, opts *container.RunOptions) {
+	if isPublicRegistry(cfg) {
+		return
+	}
+	if opts.Environment == nil {
+		opts.Environment = make(map[string]string)
+	}
+	url := strings.TrimSuffix(cfg.PackageManager.Registry, "/") + "/"
+	for _, name := range []string{"NPM_CONFIG_REGISTRY", "YARN_NPM_REGISTRY_SERVER"} {
+		if _, ok := opts.Environment[name]; !ok {
+			opts.Environment[name] = url
+		}
+	}
+}
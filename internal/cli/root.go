@@ -1,11 +1,20 @@
 package cli
 
 import (
+	stderrors "errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/positronico/snapem/internal/baseline"
+	"github.com/positronico/snapem/internal/ci"
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
 )
 
 var (
@@ -14,6 +23,16 @@ var (
 	quiet   bool
 	noColor bool
 	pkgMgr  string
+	workDir string
+
+	noPreflight    bool
+	nonInteractive bool
+	strictImages   bool
+	readOnlySrc    bool
+	registryURL    string
+	simulateFlag   string
+	logFormat      string
+	noNotify       bool
 )
 
 var rootCmd = &cobra.Command{
@@ -34,6 +53,7 @@ Examples:
   snapem install lodash       # Scan and install a specific package
   snapem run dev              # Run 'npm run dev' in a container
   snapem exec node index.js   # Execute a command in a container
+  snapem dlx cowsay hello     # Scan a package and run its binary, like npx
   snapem scan                 # Run security scan without installing`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -44,6 +64,12 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// SetArgs sets the command line to run, passing subcommands snapem doesn't
+// know through to the package manager
+func SetArgs(args []string) {
+	rootCmd.SetArgs(passthroughArgs(args))
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -52,13 +78,101 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress non-error output")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
-	rootCmd.PersistentFlags().StringVar(&pkgMgr, "package-manager", "", "force package manager (npm or bun)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "message format: text, or json for one event per line on stderr (ui.log_format)")
+	rootCmd.PersistentFlags().StringVarP(&workDir, "dir", "C", "", "project directory to use instead of the current directory")
+	rootCmd.PersistentFlags().StringVar(&pkgMgr, "package-manager", "", "force package manager (npm, bun or yarn)")
+	rootCmd.PersistentFlags().BoolVar(&noPreflight, "no-preflight", false, "skip host checks (disk space, path, sync folders) before mounting")
+	rootCmd.PersistentFlags().BoolVar(&strictImages, "strict-images", false, "refuse container images not pinned by digest (container.require_digest)")
+	rootCmd.PersistentFlags().BoolVar(&readOnlySrc, "read-only-src", false, "mount the project read-only for run and exec (container.read_only_source)")
+	rootCmd.PersistentFlags().StringVar(&registryURL, "registry", "", "npm registry for snapem's lookups and the package manager (package_manager.registry)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "never prompt; take the safe default instead (on by default in CI and without a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&noNotify, "no-notify", false, "don't send blocks and overrides to notifications.webhook")
+	rootCmd.PersistentFlags().StringVar(&artifactTimestamp, "timestamp", "", "time to embed in SBOMs, baselines and support bundles, as Unix seconds or RFC3339 (default: SOURCE_DATE_EPOCH, else now)")
+	rootCmd.PersistentFlags().StringVar(&simulateFlag, "simulate", "", "inject a simulated failure: block, scanner-failure, container-failure, timeout (requires SNAPEM_SIMULATE=1)")
+	rootCmd.PersistentFlags().MarkHidden("simulate")
 
 	// Bind flags to viper
 	viper.BindPFlag("ui.verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("ui.quiet", rootCmd.PersistentFlags().Lookup("quiet"))
-	viper.BindPFlag("ui.color", rootCmd.PersistentFlags().Lookup("no-color"))
+	viper.BindPFlag("ui.log_format", rootCmd.PersistentFlags().Lookup("log-format"))
 	viper.BindPFlag("package_manager.preferred", rootCmd.PersistentFlags().Lookup("package-manager"))
+	viper.BindPFlag("container.require_digest", rootCmd.PersistentFlags().Lookup("strict-images"))
+	viper.BindPFlag("container.read_only_source", rootCmd.PersistentFlags().Lookup("read-only-src"))
+	viper.BindPFlag("package_manager.registry", rootCmd.PersistentFlags().Lookup("registry"))
+}
+
+// promptsDisabled returns true if snapem must not wait for input: with
+// --non-interactive, in CI, or when stdin is not a terminal
+func promptsDisabled() bool {
+	return nonInteractive || ci.Detected() || !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// resolveColor settles ui.color once for every command: the config file
+// or SNAPEM_UI_COLOR decide, and --no-color or NO_COLOR force it off
+func resolveColor() {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		viper.Set("ui.color", false)
+	}
+}
+
+// useColor returns the resolved ui.color, for output before the config is
+// loaded
+func useColor() bool {
+	return viper.GetBool("ui.color")
+}
+
+// loadConfig loads and validates the configuration, printing every problem
+// found before failing
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		reportConfigError(ui.New(verbose, quiet, useColor()), err)
+		return nil, errors.ConfigError(err.Error())
+	}
+	if len(cfg.Warnings) > 0 {
+		display := ui.New(verbose, quiet, useColor())
+		for _, warning := range cfg.Warnings {
+			display.Warning(warning)
+		}
+	}
+	return cfg, nil
+}
+
+// reportConfigError prints a config error, one line per problem
+func reportConfigError(display *ui.UI, err error) {
+	var invalid *config.ValidationError
+	if !stderrors.As(err, &invalid) {
+		display.Error(err.Error())
+		return
+	}
+	for _, line := range invalid.Lines() {
+		display.Error(line)
+	}
+}
+
+// projectDirectory returns the absolute project directory: --dir if given,
+// otherwise the current directory
+func projectDirectory(display *ui.UI) (string, error) {
+	if workDir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			display.Error("Failed to get current directory")
+			return "", errors.New(errors.ExitGeneralError, "failed to get current directory")
+		}
+		return dir, nil
+	}
+
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		display.Error(fmt.Sprintf("Invalid project directory %s", workDir))
+		return "", errors.Wrap(errors.ExitGeneralError, "invalid project directory "+workDir, err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		display.Error(fmt.Sprintf("Project directory %s does not exist", dir))
+		return "", errors.New(errors.ExitGeneralError, "project directory "+dir+" does not exist")
+	}
+	return dir, nil
 }
 
 func initConfig() {
@@ -66,10 +180,14 @@ func initConfig() {
 		// Use config file from the flag
 		viper.SetConfigFile(cfgFile)
 	} else {
-		// Search for config in current directory and home config
+		// Search for config in the project directory and home config
 		viper.SetConfigName("snapem")
 		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
+		if workDir != "" {
+			viper.AddConfigPath(workDir)
+		} else {
+			viper.AddConfigPath(".")
+		}
 		viper.AddConfigPath("$HOME/.config/snapem")
 	}
 
@@ -78,26 +196,69 @@ func initConfig() {
 	viper.AutomaticEnv()
 
 	// Read config file (ignore if not found)
-	if err := viper.ReadInConfig(); err == nil {
-		if verbose {
-			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
-		}
-	}
+	err := viper.ReadInConfig()
 
 	// Set defaults
 	setDefaults()
+	resolveColor()
+
+	ui.SetLogFormat(viper.GetString("ui.log_format"))
+	if err == nil && verbose {
+		ui.NewWriter(os.Stderr, true, false, useColor()).Verbose("Using config file: " + viper.ConfigFileUsed())
+	}
 }
 
 func setDefaults() {
 	// Package manager defaults
 	viper.SetDefault("package_manager.preferred", "auto")
+	viper.SetDefault("package_manager.ignore_scripts", false)
+	viper.SetDefault("package_manager.registry", "https://registry.npmjs.org")
 
 	// Scanning defaults
 	viper.SetDefault("scanning.enabled", true)
+	viper.SetDefault("scanning.strict_scanners", false)
+	viper.SetDefault("scanning.baseline", baseline.DefaultFile)
+	viper.SetDefault("scanning.policy_file", "")
+	viper.SetDefault("scanning.proxy", "")
+	viper.SetDefault("scanning.ca_bundle", "")
 	viper.SetDefault("scanning.socket.enabled", true)
 	viper.SetDefault("scanning.socket.timeout", "30s")
+	viper.SetDefault("scanning.socket.base_url", "https://api.socket.dev/v0")
+	viper.SetDefault("scanning.socket.min_score", 0)
+	viper.SetDefault("scanning.socket.batch_size", 1000)
 	viper.SetDefault("scanning.osv.enabled", true)
 	viper.SetDefault("scanning.osv.timeout", "30s")
+	viper.SetDefault("scanning.osv.base_url", "https://api.osv.dev/v1")
+	viper.SetDefault("scanning.osv.max_references", 10)
+	viper.SetDefault("scanning.github.enabled", false)
+	viper.SetDefault("scanning.github.timeout", "30s")
+	viper.SetDefault("scanning.typosquat.enabled", true)
+	viper.SetDefault("scanning.typosquat.allow", []string{})
+	viper.SetDefault("scanning.install_scripts.enabled", true)
+	viper.SetDefault("scanning.install_scripts.timeout", "30s")
+	viper.SetDefault("scanning.verify.on_install", false)
+	viper.SetDefault("scanning.verify.concurrency", 16)
+	viper.SetDefault("scanning.verify.timeout", "30s")
+	viper.SetDefault("scanning.preinstall.max_depth", 25)
+	viper.SetDefault("scanning.preinstall.max_packages", 2000)
+	viper.SetDefault("scanning.preinstall.timeout", "2m")
+	viper.SetDefault("scanning.heuristics.enabled", true)
+	viper.SetDefault("scanning.heuristics.new_version_days", 7)
+	viper.SetDefault("scanning.heuristics.min_weekly_downloads", 100)
+	viper.SetDefault("scanning.heuristics.severity.new_version", "medium")
+	viper.SetDefault("scanning.heuristics.severity.low_downloads", "low")
+	viper.SetDefault("scanning.heuristics.severity.single_maintainer", "info")
+	viper.SetDefault("scanning.heuristics.severity.maintainer_change", "high")
+	viper.SetDefault("scanning.heuristics.timeout", "30s")
+	viper.SetDefault("scanning.deprecated.enabled", true)
+	viper.SetDefault("scanning.deprecated.severity", "low")
+	viper.SetDefault("scanning.deprecated.timeout", "30s")
+	viper.SetDefault("scanning.provenance.enabled", false)
+	viper.SetDefault("scanning.provenance.require", false)
+	viper.SetDefault("scanning.provenance.timeout", "30s")
+	viper.SetDefault("scanning.freshness.aging_days", 180)
+	viper.SetDefault("scanning.freshness.stale_days", 730)
+	viper.SetDefault("scanning.freshness.timeout", "30s")
 	viper.SetDefault("scanning.cache.enabled", true)
 	viper.SetDefault("scanning.cache.ttl", "24h")
 	viper.SetDefault("scanning.policy.malware", "block")
@@ -105,17 +266,51 @@ func setDefaults() {
 	viper.SetDefault("scanning.policy.cve.high", "block")
 	viper.SetDefault("scanning.policy.cve.medium", "block")
 	viper.SetDefault("scanning.policy.cve.low", "warn")
+	viper.SetDefault("scanning.policy.license.action", "warn")
+	viper.SetDefault("scanning.policy.install_scripts", "warn")
+	viper.SetDefault("scanning.policy.provenance", "warn")
+	viper.SetDefault("scanning.policy.min_release_age", "72h")
+	viper.SetDefault("scanning.policy.release_age", "block")
+	viper.SetDefault("scanning.policy.low_score", "warn")
 	viper.SetDefault("scanning.policy.allow_override", false)
 
 	// Container defaults
 	viper.SetDefault("container.enabled", true)
+	viper.SetDefault("container.runtime", "auto")
 	viper.SetDefault("container.image.npm", "node:lts-slim")
 	viper.SetDefault("container.image.bun", "oven/bun:latest")
+	viper.SetDefault("container.image.yarn", "node:lts-slim")
 	viper.SetDefault("container.network", "host")
+	viper.SetDefault("container.volume_options", []string{})
+	viper.SetDefault("container.require_digest", false)
+	viper.SetDefault("container.limits.memory", "")
+	viper.SetDefault("container.limits.cpus", "")
+	viper.SetDefault("container.user", "")
+	viper.SetDefault("container.read_only_source", false)
+	viper.SetDefault("container.cache_volume", true)
+	viper.SetDefault("container.mount_npmrc", false)
+
+	// Preflight defaults
+	viper.SetDefault("preflight.enabled", true)
+	viper.SetDefault("preflight.disk_headroom", 2.0)
+
+	// Hook defaults
+	viper.SetDefault("hooks.timeout", "10s")
+
+	// Audit log defaults; an empty file means the default location
+	viper.SetDefault("audit.enabled", true)
+	viper.SetDefault("audit.file", "")
+
+	// Notification defaults
+	viper.SetDefault("notifications.webhook.url", "")
+	viper.SetDefault("notifications.webhook.format", "json")
+	viper.SetDefault("notifications.webhook.timeout", "5s")
 
 	// UI defaults
 	viper.SetDefault("ui.color", true)
 	viper.SetDefault("ui.progress", true)
 	viper.SetDefault("ui.verbose", false)
 	viper.SetDefault("ui.quiet", false)
+	viper.SetDefault("ui.redact", []string{})
+	viper.SetDefault("ui.log_format", "text")
 }
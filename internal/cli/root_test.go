@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		flag    bool   // --no-color
+		noColor string // NO_COLOR
+		want    bool
+	}{
+		{name: "default", want: true},
+		{name: "config off", file: "ui:\n  color: false\n", want: false},
+		{name: "config on", file: "ui:\n  color: true\n", want: true},
+		{name: "flag", flag: true, want: false},
+		{name: "flag beats config", file: "ui:\n  color: true\n", flag: true, want: false},
+		{name: "NO_COLOR", noColor: "1", want: false},
+		{name: "NO_COLOR beats config", file: "ui:\n  color: true\n", noColor: "1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			t.Setenv("NO_COLOR", tt.noColor)
+			defer func(prev bool) { noColor = prev }(noColor)
+			noColor = tt.flag
+
+			viper.SetConfigType("yaml")
+			if err := viper.ReadConfig(strings.NewReader(tt.file)); err != nil {
+				t.Fatal(err)
+			}
+			setDefaults()
+			resolveColor()
+
+			if got := useColor(); got != tt.want {
+				t.Errorf("useColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
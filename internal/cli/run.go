@@ -2,12 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/positronico/snapem/internal/config"
 	"github.com/positronico/snapem/internal/container"
 	"github.com/positronico/snapem/internal/errors"
 	"github.com/positronico/snapem/internal/manifest"
@@ -19,35 +21,48 @@ var (
 	runNoNetwork    bool
 	runNoPorts      bool
 	runPublishPorts []string
+	runDetach       bool
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run <script> [args...]",
+	Use:   "run [script] [args...]",
 	Short: "Run a package.json script in a container",
 	Long: `Runs a script defined in package.json inside an isolated container.
 
 The script runs with the project directory mounted at /app.
 By default, the container has host network access for dev servers.
 
+Without a script, lists the scripts in package.json and, in a terminal,
+lets you pick one to run.
+
 Port auto-detection: For dev/start/serve scripts, snapem automatically
-detects and publishes the framework's default port (e.g., 3000 for Next.js,
-5173 for Vite). Use -p to override or --no-ports to disable.
+publishes every port the script sets and the default port of each dev server
+it starts (e.g., 3000 for Next.js, 5173 for Vite), following scripts run
+through concurrently or npm-run-all. Use -p to override or --no-ports to
+disable.
 
 Examples:
+  snapem run                     # List scripts and pick one
   snapem run dev                 # Auto-detects and exposes port
   snapem run dev -p 8080         # Override with custom port
   snapem run dev --no-ports      # Disable auto port detection
   snapem run build               # No port needed for build
-  snapem run test -- --watch     # Run 'npm run test -- --watch'`,
-	Args: cobra.MinimumNArgs(1),
-	RunE: runRun,
+  snapem run dev --detach        # Run in the background (see snapem ps)
+  snapem run test -- --watch     # Run 'npm run test -- --watch'
+  snapem run dev --env API_URL=http://localhost:4000 --env-file .env.local`,
+	ValidArgsFunction: completeScripts,
+	RunE:              runRun,
 }
 
 func init() {
 	runCmd.Flags().BoolVar(&runNoNetwork, "no-network", false, "disable network access in container")
 	runCmd.Flags().BoolVar(&runNoPorts, "no-ports", false, "disable automatic port detection")
 	runCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	runCmd.Flags().BoolVarP(&runDetach, "detach", "d", false, "run in the background; manage it with ps, logs, attach and stop")
 	runCmd.Flags().StringArrayVarP(&runPublishPorts, "publish", "p", nil, "publish container port to host (e.g., -p 3000 or -p 8080:80)")
+	addVolumeOptFlag(runCmd)
+	addLimitFlags(runCmd)
+	addEnvFlags(runCmd)
 
 	rootCmd.AddCommand(runCmd)
 }
@@ -56,30 +71,46 @@ func runRun(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
-		return errors.ConfigError(err.Error())
+		return err
 	}
 
 	// Initialize UI
-	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color && !noColor)
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := applyLimitFlags(cfg, display); err != nil {
+		return err
+	}
 
-	// Get current directory
-	projectDir, err := os.Getwd()
+	projectDir, err := projectDirectory(display)
 	if err != nil {
-		display.Error("Failed to get current directory")
-		return errors.New(errors.ExitGeneralError, "failed to get current directory")
+		return err
 	}
+	loadRegistries(cfg, display, projectDir)
 
 	// Check for package.json
 	parser := manifest.NewParser(projectDir)
 	if !parser.HasManifest() {
-		display.Error("No package.json found in current directory")
-		return errors.ManifestError("no package.json found", nil)
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	// Without a script, list them and offer to run one
+	if len(args) == 0 {
+		script, err := pickScript(display, parser)
+		if err != nil || script == "" {
+			return err
+		}
+		args = []string{script}
 	}
 
 	// Detect package manager
-	mgr := pkgmanager.Detect(projectDir, pkgMgr, cfg.Container.Image)
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
 	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
 
 	// Parse script and args
@@ -95,39 +126,45 @@ func runRun(cmd *cobra.Command, args []string) error {
 		networkMode = container.NetworkNone
 	}
 
-	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, networkMode, runCommand)
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, runCommand)
 
 	// Port handling: explicit -p flags take precedence
 	if len(runPublishPorts) > 0 {
-		for _, portSpec := range runPublishPorts {
-			parts := strings.SplitN(portSpec, ":", 2)
-			var pm container.PortMapping
-			if len(parts) == 2 {
-				pm = container.PortMapping{HostPort: parts[0], ContainerPort: parts[1]}
-			} else {
-				pm = container.PortMapping{HostPort: parts[0], ContainerPort: parts[0]}
-			}
-			opts.Ports = append(opts.Ports, pm)
-		}
+		opts.Ports = append(opts.Ports, publishedPorts(runPublishPorts)...)
 	} else if !runNoPorts && isDevScript(script) {
-		// Auto-detect port for dev-like scripts
-		if detectedPort := parser.DetectPort(); detectedPort > 0 {
-			portStr := fmt.Sprintf("%d", detectedPort)
-			opts.Ports = append(opts.Ports, container.PortMapping{
-				HostPort:      portStr,
-				ContainerPort: portStr,
-			})
-			display.Info(fmt.Sprintf("Auto-detected port %d (use -p to override, --no-ports to disable)", detectedPort))
+		// Auto-detect ports for dev-like scripts
+		if ports := parser.DetectPorts(script); len(ports) > 0 {
+			for _, port := range ports {
+				portStr := fmt.Sprintf("%d", port)
+				opts.Ports = append(opts.Ports, container.PortMapping{
+					HostPort:      portStr,
+					ContainerPort: portStr,
+				})
+			}
+			display.Info(fmt.Sprintf("Auto-detected %s (use -p to override, --no-ports to disable)", formatPortList(ports)))
 		}
 	}
 
 	// Run in container (unless disabled)
 	if cfg.Container.Enabled && !noContainer {
-		runtime := container.NewAppleRuntime()
-		if !runtime.IsAvailable() {
-			display.Error("Apple container runtime not available")
-			display.Info("Install with: brew install --cask container")
-			return errors.ContainerNotAvailableError()
+		runtime, err := newRuntime(cfg, display)
+		if err != nil {
+			return err
+		}
+
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+		if err := applyEnvFlags(display, opts); err != nil {
+			return err
+		}
+
+		if err := runPreflight(cfg, display, projectDir, 0); err != nil {
+			return err
+		}
+
+		if runDetach {
+			return runDetached(ctx, display, runtime, opts, container.DetachedName(projectDir, script))
 		}
 
 		display.ContainerHeader(runtime.CommandString(opts))
@@ -143,6 +180,111 @@ func runRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pickScript lists the scripts in package.json and, when prompts are
+// allowed, returns the one the user picks. It returns "" if none was
+// picked.
+func pickScript(display *ui.UI, parser *manifest.Parser) (string, error) {
+	pkg, err := parser.ParseManifest()
+	if err != nil {
+		display.Error(fmt.Sprintf("Failed to parse package.json: %v", err))
+		return "", errors.ManifestError("failed to parse package.json", err)
+	}
+	if len(pkg.Scripts) == 0 {
+		display.Info("No scripts in package.json")
+		return "", nil
+	}
+
+	names := slices.Sorted(maps.Keys(pkg.Scripts))
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	display.Print("Scripts in package.json:")
+	for _, name := range names {
+		display.ScriptEntry(name, truncateCommand(pkg.Scripts[name], ui.TerminalWidth(os.Stdout)-width-30), scriptNote(parser, name), width)
+	}
+
+	if promptsDisabled() {
+		display.Info("Run one with: snapem run <script>")
+		return "", nil
+	}
+	display.Print("")
+	i, ok := display.PromptSelect("Run a script:", names)
+	if !ok {
+		return "", nil
+	}
+	return names[i], nil
+}
+
+// scriptNote marks dev-like scripts, with the ports run publishes for them
+func scriptNote(parser *manifest.Parser, name string) string {
+	if !isDevScript(name) {
+		return ""
+	}
+	if ports := parser.DetectPorts(name); len(ports) > 0 {
+		return "dev server, " + formatPortList(ports)
+	}
+	return "dev server"
+}
+
+// formatPortList renders ports as "port 3000" or "ports 3000, 4000"
+func formatPortList(ports []int) string {
+	list := make([]string, len(ports))
+	for i, port := range ports {
+		list[i] = strconv.Itoa(port)
+	}
+	if len(ports) == 1 {
+		return "port " + list[0]
+	}
+	return "ports " + strings.Join(list, ", ")
+}
+
+// truncateCommand shortens a script's command to fit maxLen columns; a
+// maxLen too small to be useful leaves it whole
+func truncateCommand(command string, maxLen int) string {
+	runes := []rune(command)
+	if maxLen < 20 || len(runes) <= maxLen {
+		return command
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// completeScripts completes the script argument from package.json
+func completeScripts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	dir := workDir
+	if dir == "" {
+		dir = "."
+	}
+	pkg, err := manifest.NewParser(dir).ParseManifest()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, name := range slices.Sorted(maps.Keys(pkg.Scripts)) {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"\t"+pkg.Scripts[name])
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// publishedPorts parses -p specs: a port published on the same host port,
+// or host:container
+func publishedPorts(specs []string) []container.PortMapping {
+	var ports []container.PortMapping
+	for _, spec := range specs {
+		host, ctr, found := strings.Cut(spec, ":")
+		if !found {
+			ctr = host
+		}
+		ports = append(ports, container.PortMapping{HostPort: host, ContainerPort: ctr})
+	}
+	return ports
+}
+
 // isDevScript returns true if the script name suggests a development server
 func isDevScript(script string) bool {
 	devScripts := []string{"dev", "start", "serve", "develop", "server"}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteScripts(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"scripts": {"dev": "vite", "build": "vite build", "test": "vitest"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	workDir = dir
+	t.Cleanup(func() { workDir = "" })
+
+	got, directive := completeScripts(runCmd, nil, "")
+	want := []string{"build\tvite build", "dev\tvite", "test\tvitest"}
+	if !slices.Equal(got, want) || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("completeScripts() = %q, %d; want %q", got, directive, want)
+	}
+
+	if got, _ := completeScripts(runCmd, nil, "d"); !slices.Equal(got, []string{"dev\tvite"}) {
+		t.Errorf("completeScripts(d) = %q", got)
+	}
+	if got, _ := completeScripts(runCmd, []string{"dev"}, ""); got != nil {
+		t.Errorf("completeScripts after the script = %q, want none", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/sbom"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	sbomFormat  string
+	sbomInclude string
+	sbomOutput  string
+)
+
+// sbomFormats lists the SBOM formats snapem can write
+var sbomFormats = []string{"spdx"}
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Generate a software bill of materials",
+	Long: `Writes a software bill of materials for the project's resolved
+dependencies.
+
+The spdx format is SPDX 2.3 JSON. Download locations and checksums come
+from the resolved and integrity fields of package-lock.json; other
+lockfiles do not record them, so those fields are NOASSERTION.
+
+Examples:
+  snapem sbom                      # SPDX JSON to stdout
+  snapem sbom -o sbom.spdx.json    # Write to a file
+  snapem sbom --include prod       # Production dependencies only`,
+	Args: cobra.NoArgs,
+	RunE: runSBOM,
+}
+
+func init() {
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", "spdx", "SBOM format: spdx")
+	sbomCmd.Flags().StringVar(&sbomInclude, "include", "all", "which dependencies to include, comma-separated: all, prod, dev, optional, peer")
+	sbomCmd.Flags().StringVarP(&sbomOutput, "output", "o", "", "write the SBOM to a file instead of stdout")
+	rootCmd.AddCommand(sbomCmd)
+}
+
+func runSBOM(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// stdout is reserved for the document
+	display := ui.NewWriter(os.Stderr, cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if !slices.Contains(sbomFormats, sbomFormat) {
+		msg := fmt.Sprintf("unknown SBOM format %q (available formats: %s)", sbomFormat, strings.Join(sbomFormats, ", "))
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	m, err := parser.ParseManifest()
+	if err != nil {
+		return err
+	}
+
+	class, err := manifest.ParseDependencyClass(sbomInclude)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.New(errors.ExitGeneralError, err.Error())
+	}
+	packages, err := parser.GetDependenciesFiltered(class)
+	if err != nil {
+		return errors.ManifestError("failed to parse dependencies", err)
+	}
+	if !parser.HasLockfile() {
+		display.Warning("No package-lock.json; download locations and checksums will be NOASSERTION")
+	}
+
+	created, err := artifactTime(display)
+	if err != nil {
+		return err
+	}
+
+	doc := sbom.NewSPDX(sbom.Project{
+		Name:    m.Name,
+		Version: m.Version,
+		Tool:    "snapem-" + versionStr,
+		Created: created,
+
+		Registries: cfg.PackageManager.Registries(),
+	}, packages)
+
+	var w io.Writer = os.Stdout
+	if sbomOutput != "" {
+		f, err := os.Create(sbomOutput)
+		if err != nil {
+			display.Error(fmt.Sprintf("Failed to create %s: %v", sbomOutput, err))
+			return errors.Wrap(errors.ExitGeneralError, "failed to create SBOM file", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := doc.Write(w); err != nil {
+		return errors.Wrap(errors.ExitGeneralError, "failed to write SBOM", err)
+	}
+
+	if sbomOutput != "" {
+		display.Success(fmt.Sprintf("Wrote %s (%d packages)", sbomOutput, len(doc.Packages)-1))
+	}
+	return nil
+}
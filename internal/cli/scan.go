@@ -1,42 +1,104 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/baseline"
+	"github.com/positronico/snapem/internal/ci"
 	"github.com/positronico/snapem/internal/config"
 	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/hooks"
 	"github.com/positronico/snapem/internal/manifest"
 	"github.com/positronico/snapem/internal/scanner"
 	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
 )
 
 var (
-	scanJSON    bool
-	scanInclude string
+	scanJSON      bool
+	scanFormat    string
+	scanInclude   string
+	scanFreshness bool
+	scanCoverage  bool
+	scanDirect    bool
+	scanFailOn    string
+	scanEcosystem string
+	scanGroupBy   string
+	scanOutput    string
+	scanDiff      string
+	scanStaged    bool
+
+	scanWriteBaseline string
 )
 
+// failOnLevels lists the accepted --fail-on values
+var failOnLevels = []string{"critical", "high", "medium", "low", "none"}
+
 var scanCmd = &cobra.Command{
-	Use:   "scan",
+	Use:   "scan [packages...]",
 	Short: "Run security scan on dependencies",
 	Long: `Scans all dependencies in package.json and package-lock.json for
 known vulnerabilities (CVEs) and malicious packages.
 
+With package arguments, scans only those packages, e.g. to vet a package
+before adding it. No package.json is needed. --ecosystem scans packages
+from another ecosystem, such as PyPI or Go modules.
+
 Uses Socket.dev for malware detection and Google OSV for CVE lookup.
 
 Examples:
   snapem scan                # Scan all dependencies
   snapem scan --json         # Output results as JSON
-  snapem scan --include dev  # Include devDependencies`,
+  snapem scan express@4.18.2 left-pad  # Scan packages before adding them
+  snapem scan --ecosystem pypi requests@2.31.0  # Scan a PyPI package
+  snapem scan --format json  # Same as --json
+  snapem scan --format gha-matcher  # GitHub Actions annotations
+  snapem scan --format sarif # SARIF 2.1.0 for code scanning
+  snapem scan --format table # One row per finding
+  snapem scan --format markdown  # Markdown table for a pull request
+  snapem scan --format sarif --output reports/snapem.sarif  # Report to a file, progress to the terminal
+  snapem scan --include dev  # Include devDependencies
+  snapem scan --workspace api  # Only the api workspace's dependencies
+  snapem scan --freshness    # Also flag stale direct dependencies
+  snapem scan --coverage     # Show which scanners checked each package
+  snapem scan --direct-only  # Only findings in direct dependencies
+  snapem scan --diff         # Only packages added or upgraded since HEAD
+  snapem scan --diff=main    # Only packages changed since main
+  snapem scan --staged --diff  # Only packages a commit would add, as staged
+  snapem scan --group-by severity  # List findings by category, not package
+  snapem scan --write-baseline .snapem-baseline.json  # Acknowledge current findings
+  snapem scan --fail-on high # Exit 2 only for high or critical findings`,
 	RunE: runScan,
 }
 
 func init() {
-	scanCmd.Flags().BoolVar(&scanJSON, "json", false, "output results as JSON")
-	scanCmd.Flags().StringVar(&scanInclude, "include", "all", "which dependencies to scan: all, prod, dev")
+	scanCmd.Flags().BoolVar(&scanJSON, "json", false, "output results as JSON (alias for --format json)")
+	scanCmd.Flags().StringVar(&scanFormat, "format", "text", "output format: "+strings.Join(report.Formats(), ", "))
+	scanCmd.Flags().StringVarP(&scanOutput, "output", "o", "", "write the --format report to this file, creating its directory; the terminal gets the text output")
+	scanCmd.Flags().StringVar(&scanInclude, "include", "all", "which dependencies to scan, comma-separated: all, prod, dev, optional, peer")
+	scanCmd.Flags().BoolVar(&allowUnsecure, "allow-unsecure", false, "scan without malware detection when SOCKET_API_TOKEN is not set, without asking")
+	scanCmd.Flags().BoolVar(&scanFreshness, "freshness", false, "report last release and archive status of direct dependencies")
+	scanCmd.Flags().BoolVar(&scanCoverage, "coverage", false, "show which scanners checked each package, and why others skipped it")
+	scanCmd.Flags().StringVar(&scanEcosystem, "ecosystem", "npm", "ecosystem of package arguments: "+strings.Join(manifest.Ecosystems, ", "))
+	scanCmd.Flags().BoolVar(&scanDirect, "direct-only", false, "report only findings in direct dependencies")
+	scanCmd.Flags().StringVar(&scanGroupBy, "group-by", report.GroupByPackage, "how text output groups findings: "+strings.Join(report.GroupByModes, ", "))
+	scanCmd.Flags().StringVar(&scanDiff, "diff", "", "scan only packages added or upgraded since a git ref (--diff=<ref>, default HEAD)")
+	scanCmd.Flags().Lookup("diff").NoOptDefVal = "HEAD"
+	scanCmd.Flags().BoolVar(&scanStaged, "staged", false, "read package.json and the lockfile staged in the git index instead of the working tree")
+	addWorkspaceFlag(scanCmd)
+	scanCmd.Flags().StringVar(&scanFailOn, "fail-on", "", "exit with code 2 when a finding is at or above this severity, instead of applying the config policy: "+strings.Join(failOnLevels, ", "))
+	addPolicySetFlag(scanCmd)
+	addStrictScannersFlag(scanCmd)
+	scanCmd.Flags().StringVar(&scanWriteBaseline, "write-baseline", "", "record the current findings in this baseline file, so only new findings block")
+	addNoBaselineFlag(scanCmd)
 
 	rootCmd.AddCommand(scanCmd)
 }
@@ -45,35 +107,121 @@ func runScan(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
-		return errors.ConfigError(err.Error())
+		return err
+	}
+
+	// Resolve output format before doing any work
+	format := scanFormat
+	if scanJSON {
+		format = "json"
 	}
+	renderer, formatErr := report.Get(format)
+
+	// Progress, prompts and policy exit codes only apply to the text format;
+	// machine formats keep stdout clean for the rendered document. With
+	// --output the document goes to the file, so the terminal gets the
+	// text output whatever the format. The gha-matcher format is for CI,
+	// so it keeps the policy exit codes, as does any format when --fail-on
+	// asks for them explicitly.
+	interactive := format == "text" || scanOutput != ""
+	enforcePolicy := format == "text" || format == "gha-matcher" || scanFailOn != ""
 
 	// Initialize UI
-	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color && !noColor)
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+	if !interactive {
+		display = ui.NewWriter(os.Stderr, cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+	}
+
+	if formatErr != nil {
+		display.Error(formatErr.Error())
+		return errors.New(errors.ExitGeneralError, formatErr.Error())
+	}
+
+	// Determine which dependencies to include
+	class, err := manifest.ParseDependencyClass(scanInclude)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.New(errors.ExitGeneralError, err.Error())
+	}
+
+	if scanFailOn != "" && !slices.Contains(failOnLevels, scanFailOn) {
+		msg := fmt.Sprintf("invalid --fail-on %q (expected %s)", scanFailOn, strings.Join(failOnLevels, ", "))
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	if !slices.Contains(report.GroupByModes, scanGroupBy) {
+		msg := fmt.Sprintf("invalid --group-by %q (expected %s)", scanGroupBy, strings.Join(report.GroupByModes, ", "))
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	ecosystem, err := manifest.NormalizeEcosystem(scanEcosystem)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.New(errors.ExitGeneralError, err.Error())
+	}
+	if scanWriteBaseline != "" && len(args) > 0 {
+		msg := "--write-baseline records the project's findings and takes no package arguments"
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	if scanDiff != "" && len(args) > 0 {
+		msg := "--diff compares the project's lockfile and takes no package arguments; give a ref as --diff=<ref>"
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+	if scanStaged && (len(args) > 0 || workspaceName != "") {
+		msg := "--staged scans the project's staged lockfile and cannot be combined with package arguments or --workspace"
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+	if scanDiff != "" && scanWriteBaseline != "" {
+		msg := "--write-baseline records every finding and cannot be combined with --diff"
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	if ecosystem != "npm" && len(args) == 0 {
+		msg := "--ecosystem applies to package arguments; project scans are npm only"
+		display.Error(msg)
+		return errors.New(errors.ExitGeneralError, msg)
+	}
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := applyPolicySets(cfg, display); err != nil {
+		return err
+	}
+	warnExpiredAllowlist(cfg, display)
 
-	// Get current directory
-	projectDir, err := os.Getwd()
+	projectDir, err := projectDirectory(display)
 	if err != nil {
-		display.Error("Failed to get current directory")
-		return errors.New(errors.ExitGeneralError, "failed to get current directory")
+		return err
 	}
+	loadRegistries(cfg, display, projectDir)
 
-	// Check for package.json
+	// Check for package.json; ad-hoc package scans don't need one
 	parser := manifest.NewParser(projectDir)
-	if !parser.HasManifest() {
-		display.Error("No package.json found in current directory")
-		return errors.ManifestError("no package.json found", nil)
+	if len(args) == 0 && !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
 	}
 
-	if !scanJSON {
+	if interactive {
 		display.ScanningHeader()
 	}
 
 	// Check for Socket API token
-	if !cfg.HasSocketToken() && cfg.Scanning.Socket.Enabled {
-		if !scanJSON {
+	if !cfg.HasSocketToken() && cfg.Scanning.Socket.Enabled && !simulation.AffectsScan() {
+		if interactive && (allowUnsecure || promptsDisabled()) {
+			display.Warning("No SOCKET_API_TOKEN set. Malware detection is disabled.")
+		} else if interactive {
 			if !display.PromptUnsecure() {
 				return errors.UserAbortError()
 			}
@@ -81,170 +229,334 @@ func runScan(cmd *cobra.Command, args []string) error {
 		cfg.Scanning.Socket.Enabled = false
 	}
 
-	// Determine which dependencies to include
-	includeDev := scanInclude == "all" || scanInclude == "dev"
-
-	// Get packages to scan
-	packages, err := parser.GetDependencies(includeDev)
-	if err != nil {
-		return errors.ManifestError("failed to parse dependencies", err)
+	// Get packages to scan: the named packages, or the project's, as
+	// staged with --staged. With --diff, only the project's packages that
+	// changed since the ref.
+	var packages []manifest.Package
+	var changes map[string]string
+	if len(args) > 0 {
+		packages = adHocPackages(args, ecosystem)
+	} else if scanStaged {
+		packages, err = stagedPackages(projectDir, class)
+		if err != nil {
+			display.Error(err.Error())
+			return err
+		}
+	} else {
+		packages, err = workspaceDependencies(display, parser, class)
+		if err != nil {
+			return errors.ManifestError("failed to parse dependencies", err)
+		}
+	}
+	if scanDiff != "" {
+		packages, changes, err = diffPackages(display, projectDir, scanDiff, class, packages)
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(packages) == 0 {
-		if scanJSON {
-			outputJSONResult(&scanner.AggregatedResult{})
-		} else {
+		setActionsOutputs(display, &scanner.AggregatedResult{}, false)
+		if scanOutput != "" {
+			if err := writeScanOutput(display, renderer, newScanReport(cfg, &scanner.AggregatedResult{})); err != nil {
+				return err
+			}
+		}
+		if interactive {
 			display.Info("No packages to scan")
+			return nil
 		}
-		return nil
+		return renderer.Render(os.Stdout, newScanReport(cfg, &scanner.AggregatedResult{}))
 	}
 
-	if !scanJSON {
+	if interactive {
 		display.Verbose(fmt.Sprintf("Scanning %d packages...", len(packages)))
 	}
 
 	// Create orchestrator and scan
-	orch := scanner.NewOrchestrator(cfg)
+	orch := newOrchestrator(cfg)
 
 	scanners := orch.AvailableScanners()
 	if len(scanners) == 0 {
-		if !scanJSON {
-			display.Warning("No scanners available")
-		}
-		return nil
+		return noScanners(cfg, display, renderer, interactive)
 	}
 
 	var result *scanner.AggregatedResult
-	if scanJSON {
+	if !interactive {
 		result, err = orch.Scan(ctx, packages)
 	} else {
-		result, err = orch.ScanWithProgress(ctx, packages, func(name string, done bool) {
-			if done {
-				display.ScannerStatus(name, "complete", false)
-			} else {
-				display.ScannerStatus(name, "scanning...", true)
-			}
-		})
+		progress := display.StartScanProgress()
+		result, err = orch.ScanWithProgress(ctx, packages, progress.Update)
+		progress.Stop()
 	}
 
 	if err != nil {
 		return errors.ScannerError("security", err)
 	}
+	if err := checkScannerErrors(cfg, display, result); err != nil {
+		return err
+	}
 
-	// Output results
-	if scanJSON {
-		return outputJSONResult(result)
+	if scanWriteBaseline != "" {
+		return writeBaseline(display, result)
 	}
 
-	return outputTextResult(cfg, display, result)
-}
+	if scanDirect {
+		result = result.Filter(func(f *scanner.Finding) bool { return f.Direct })
+	}
+	if len(args) == 0 {
+		result = applyBaseline(cfg, display, projectDir, result)
+	}
 
-func outputJSONResult(result *scanner.AggregatedResult) error {
-	output := struct {
-		Packages int               `json:"packages_scanned"`
-		Findings []scanner.Finding `json:"findings"`
-		Summary  struct {
-			Total    int `json:"total"`
-			Critical int `json:"critical"`
-			High     int `json:"high"`
-			Medium   int `json:"medium"`
-			Low      int `json:"low"`
-			Malware  int `json:"malware"`
-		} `json:"summary"`
-	}{
-		Packages: result.TotalPackages,
-		Findings: result.AllFindings(),
-	}
-
-	output.Summary.Total = result.TotalFindings
-	output.Summary.Critical = result.CountBySeverity(scanner.SeverityCritical)
-	output.Summary.High = result.CountBySeverity(scanner.SeverityHigh)
-	output.Summary.Medium = result.CountBySeverity(scanner.SeverityMedium)
-	output.Summary.Low = result.CountBySeverity(scanner.SeverityLow)
-	output.Summary.Malware = result.CountByType(scanner.FindingTypeMalware) + result.CountByType(scanner.FindingTypeTyposquat)
-
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(output)
-}
+	if scanFreshness && len(args) > 0 {
+		display.Warning("--freshness rates the project's direct dependencies and is skipped for package arguments")
+	} else if scanFreshness {
+		addFreshness(ctx, cfg, display, parser, class, packages, result)
+	}
+
+	newHookRunner(cfg, display).Fire(ctx, hooks.ScanComplete, projectDir, report.NewDocument(result))
+
+	// Under GitHub Actions, register the matcher that turns the
+	// gha-matcher lines into annotations
+	if format == "gha-matcher" && scanOutput == "" {
+		remove, err := ci.AddMatcher(os.Stdout, report.GHAMatcherOwner, report.GHAMatcher)
+		if err != nil {
+			display.Warning(err.Error())
+		}
+		defer remove()
+	}
+
+	// Output results; verbose output names the direct dependencies that
+	// pull in each transitive finding
+	rep := newScanReport(cfg, result)
+	rep.Changes = changes
+	if cfg.UI.Verbose && len(args) == 0 {
+		rep.Via = findingIntroducers(parser, result)
+	}
+	if scanOutput == "" {
+		if err := renderer.Render(os.Stdout, rep); err != nil {
+			return err
+		}
+	} else {
+		text, _ := report.Get("text")
+		if err := text.Render(os.Stdout, rep); err != nil {
+			return err
+		}
+		if err := writeScanOutput(display, renderer, rep); err != nil {
+			return err
+		}
+	}
 
-func outputTextResult(cfg *config.Config, display *ui.UI, result *scanner.AggregatedResult) error {
-	display.Print("")
-	display.Print(fmt.Sprintf("Scanned %d packages in %s", result.TotalPackages, result.Duration.Round(1e6)))
+	policyErr := enforceScanPolicy(cfg, result)
+	if scanFailOn != "" {
+		policyErr = enforceFailOn(scanFailOn, result)
+	}
+	setActionsOutputs(display, result, policyErr != nil)
 
-	if result.TotalFindings == 0 {
-		display.Success("No security issues found")
+	trail := newAuditTrail(cfg, display, "scan", projectDir, args)
+	trail.result = result
+	if policyErr != nil {
+		trail.record(audit.DecisionBlocked, policyErr.Error())
+	} else {
+		trail.record(audit.DecisionAllowed, "")
+	}
+
+	if !enforcePolicy {
 		return nil
 	}
 
-	display.Print(fmt.Sprintf("\nFound %d issue(s):", result.TotalFindings))
+	return policyErr
+}
+
+// writeBaseline records every finding of result in the --write-baseline
+// file
+func writeBaseline(display *ui.UI, result *scanner.AggregatedResult) error {
+	created, err := artifactTime(display)
+	if err != nil {
+		return err
+	}
+	n, err := baseline.Write(scanWriteBaseline, result, created)
+	if err != nil {
+		display.Error(fmt.Sprintf("Could not write %s: %v", scanWriteBaseline, err))
+		return errors.Wrap(errors.ExitGeneralError, "failed to write "+scanWriteBaseline, err)
+	}
+	display.Success(fmt.Sprintf("Recorded %d finding(s) in %s", n, scanWriteBaseline))
+	display.Info("Commit the file; later scans and installs list these findings without blocking on them")
+	return nil
+}
 
-	// Summary counts
-	critical := result.CountBySeverity(scanner.SeverityCritical)
-	high := result.CountBySeverity(scanner.SeverityHigh)
-	medium := result.CountBySeverity(scanner.SeverityMedium)
-	low := result.CountBySeverity(scanner.SeverityLow)
-	malware := result.CountByType(scanner.FindingTypeMalware) + result.CountByType(scanner.FindingTypeTyposquat)
+// writeScanOutput renders the report in the --format format to the
+// --output file, creating its directory. The file is never styled.
+func writeScanOutput(display *ui.UI, renderer report.Renderer, rep *report.Report) error {
+	plain := *rep
+	plain.Color = false
+	if err := writeReportFile(scanOutput, renderer, &plain); err != nil {
+		display.Error(err.Error())
+		return errors.Wrap(errors.ExitGeneralError, "failed to write "+scanOutput, err)
+	}
+	display.Info("Report written to " + scanOutput)
+	return nil
+}
 
-	if malware > 0 {
-		display.Error(fmt.Sprintf("  Malware/Supply Chain: %d", malware))
+// writeReportFile renders rep to path, creating its directory
+func writeReportFile(path string, renderer report.Renderer, rep *report.Report) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-	if critical > 0 {
-		display.Error(fmt.Sprintf("  Critical: %d", critical))
+	if err := renderer.Render(f, rep); err != nil {
+		f.Close()
+		return err
 	}
-	if high > 0 {
-		display.Warning(fmt.Sprintf("  High: %d", high))
+	return f.Close()
+}
+
+// adHocPackages turns name[@version] arguments into packages of the given
+// ecosystem to scan. Bare names are scanned as "latest".
+func adHocPackages(args []string, ecosystem string) []manifest.Package {
+	packages := make([]manifest.Package, 0, len(args))
+	for _, arg := range args {
+		name, version := parsePackageArg(arg)
+		packages = append(packages, manifest.Package{
+			Name:      name,
+			Version:   version,
+			Ecosystem: ecosystem,
+			Direct:    true,
+		})
+	}
+	return packages
+}
+
+// setActionsOutputs writes the findings_count and blocked step outputs
+// when running under GitHub Actions
+func setActionsOutputs(display *ui.UI, result *scanner.AggregatedResult, blocked bool) {
+	err := ci.SetOutputs(
+		ci.Output{Name: "findings_count", Value: strconv.Itoa(result.TotalFindings)},
+		ci.Output{Name: "blocked", Value: strconv.FormatBool(blocked)},
+	)
+	if err != nil {
+		display.Warning(err.Error())
 	}
-	if medium > 0 {
-		display.Info(fmt.Sprintf("  Medium: %d", medium))
+}
+
+// newScanReport wraps a scan result with the presentation settings in effect
+func newScanReport(cfg *config.Config, result *scanner.AggregatedResult) *report.Report {
+	return &report.Report{
+		Result:   result,
+		Verbose:  cfg.UI.Verbose,
+		Quiet:    cfg.UI.Quiet,
+		Color:    cfg.UI.Color,
+		Coverage: scanCoverage,
+		GroupBy:  scanGroupBy,
 	}
-	if low > 0 {
-		display.Verbose(fmt.Sprintf("  Low: %d", low))
+}
+
+// enforceFailOn returns an error if any finding is at or above the
+// threshold severity. Malware counts as critical; "none" never fails.
+func enforceFailOn(threshold string, result *scanner.AggregatedResult) error {
+	if threshold == "none" {
+		return nil
 	}
+	limit := scanner.SeverityOrder(scanner.Severity(threshold))
 
-	// Display malware findings
-	malwareFindings := result.MalwareFindings()
-	if len(malwareFindings) > 0 {
-		display.Print("")
-		display.Error("Malware/Supply Chain Threats:")
-		for _, f := range malwareFindings {
-			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Description)
+	for _, f := range result.AllFindings() {
+		severity := f.Severity
+		if f.Type == scanner.FindingTypeMalware || f.Type == scanner.FindingTypeTyposquat {
+			severity = scanner.SeverityCritical
+		}
+		if scanner.SeverityOrder(severity) <= limit {
+			return errors.SecurityBlockError(fmt.Sprintf("%s finding at or above --fail-on %s: %s@%s", severity, threshold, f.Package, f.Version))
 		}
 	}
+	return nil
+}
 
-	// Display CVE findings by severity
-	cveFindings := result.CVEFindings()
-	if len(cveFindings) > 0 {
-		display.Print("")
-		display.Warning("Vulnerabilities (CVEs):")
+// strictScanners holds --strict-scanners for the current invocation
+var strictScanners bool
 
-		severities := []scanner.Severity{
-			scanner.SeverityCritical,
-			scanner.SeverityHigh,
-			scanner.SeverityMedium,
-			scanner.SeverityLow,
+// addStrictScannersFlag registers --strict-scanners on a command that scans
+func addStrictScannersFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&strictScanners, "strict-scanners", false, "fail if any scanner fails, instead of continuing with partial results")
+}
+
+// noScanners handles a scan with every scanner disabled or unavailable.
+// Reports must not read as a clean scan, so they are still written, with
+// the missing scanners listed as a scanner error, and the command fails.
+// A text scan without --output only warns.
+func noScanners(cfg *config.Config, display *ui.UI, renderer report.Renderer, interactive bool) error {
+	const msg = "no scanners available"
+	result := &scanner.AggregatedResult{Errors: []scanner.ScannerError{{Scanner: "security", Message: msg}}}
+	setActionsOutputs(display, result, false)
+
+	if interactive {
+		display.Warning("No scanners available")
+	}
+	switch {
+	case scanOutput != "":
+		if err := writeScanOutput(display, renderer, newScanReport(cfg, result)); err != nil {
+			return err
+		}
+	case !interactive:
+		if err := renderer.Render(os.Stdout, newScanReport(cfg, result)); err != nil {
+			return err
 		}
+	default:
+		return nil
+	}
+	return errors.New(errors.ExitScannerError, msg)
+}
 
-		for _, sev := range severities {
-			for _, f := range cveFindings {
-				if f.Severity == sev {
-					desc := f.Title
-					if f.ID != "" {
-						desc = f.ID + ": " + f.Title
-					}
-					display.ThreatFound(string(sev), f.Package+"@"+f.Version, desc)
-				}
-			}
+// checkScannerErrors warns about scanners that failed while others
+// succeeded. With --strict-scanners or scanning.strict_scanners the first
+// failure fails the command.
+func checkScannerErrors(cfg *config.Config, display *ui.UI, result *scanner.AggregatedResult) error {
+	if result == nil || len(result.Errors) == 0 {
+		return nil
+	}
+
+	strict := strictScanners || cfg.Scanning.StrictScanners
+	for _, e := range result.Errors {
+		msg := fmt.Sprintf("%s scan failed (%s) — results may be incomplete", e.Scanner, e.Message)
+		if strict {
+			display.Error(msg)
+		} else {
+			display.Warning(msg)
 		}
 	}
 
-	// Return error if blocking issues
+	if strict {
+		return errors.ScannerError(result.Errors[0].Scanner, result.Errors[0])
+	}
+	return nil
+}
+
+// enforceScanPolicy returns an error if the result contains blocking issues
+func enforceScanPolicy(cfg *config.Config, result *scanner.AggregatedResult) error {
 	if result.HasMalware && cfg.ShouldBlock(cfg.Scanning.Policy.Malware) {
 		return errors.SecurityBlockError("malware detected")
 	}
 	if result.HasCritical && cfg.ShouldBlock(cfg.GetCVEAction("critical")) {
 		return errors.SecurityBlockError("critical vulnerabilities detected")
 	}
+	for _, f := range result.LicenseFindings() {
+		if cfg.ShouldBlock(cfg.GetLicenseAction(f.License)) {
+			return errors.SecurityBlockError("denied licenses detected")
+		}
+	}
+	if len(result.InstallScriptFindings()) > 0 && cfg.ShouldBlock(cfg.Scanning.Policy.InstallScripts) {
+		return errors.SecurityBlockError("install scripts detected")
+	}
+	for _, f := range result.ProvenanceFindings() {
+		if cfg.Scanning.Provenance.Require || (f.Severity != scanner.SeverityInfo && cfg.ShouldBlock(cfg.Scanning.Policy.Provenance)) {
+			return errors.SecurityBlockError("missing provenance detected")
+		}
+	}
+	if result.CountByType(scanner.FindingTypeLowScore) > 0 && cfg.ShouldBlock(cfg.Scanning.Policy.LowScore) {
+		return errors.SecurityBlockError("packages below the minimum Socket score detected")
+	}
 
 	return nil
 }
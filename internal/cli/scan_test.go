@@ -0,0 +1,450 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/spf13/viper"
+)
+
+// TestScanGitHubActions runs a simulated blocking scan with the environment
+// GitHub Actions provides and checks the step outputs and annotations
+func TestScanGitHubActions(t *testing.T) {
+	project := t.TempDir()
+	runnerTemp := t.TempDir()
+	outputFile := filepath.Join(runnerTemp, "github_output")
+
+	if err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(project)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_OUTPUT", outputFile)
+	t.Setenv("RUNNER_TEMP", runnerTemp)
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+
+	stdout, err := os.Create(filepath.Join(runnerTemp, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = origStdout })
+
+	rootCmd.SetArgs([]string{"scan", "--format", "gha-matcher", "--simulate", "block"})
+	err = Execute()
+	stdout.Close()
+	os.Stdout = origStdout
+
+	if code := errors.ExitCodeFor(err); code != errors.ExitSecurityBlock {
+		t.Fatalf("exit code = %d (%v), want %d", code, err, errors.ExitSecurityBlock)
+	}
+
+	outputs, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("GITHUB_OUTPUT not written: %v", err)
+	}
+	if want := "findings_count=1\nblocked=true\n"; string(outputs) != want {
+		t.Errorf("GITHUB_OUTPUT =\n%q\nwant\n%q", outputs, want)
+	}
+
+	out, _ := os.ReadFile(filepath.Join(runnerTemp, "stdout"))
+	matcherPath := filepath.Join(runnerTemp, "snapem-matcher.json")
+	for _, want := range []string{
+		"::add-matcher::" + matcherPath,
+		"snapem: package.json: error: [SNAPEM-SIMULATED] left-pad@1.3.0 (critical):",
+		"::remove-matcher owner=snapem::",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("stdout missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(matcherPath); err != nil {
+		t.Errorf("problem matcher not written: %v", err)
+	}
+}
+
+func TestEnforceFailOn(t *testing.T) {
+	result := func(findings ...scanner.Finding) *scanner.AggregatedResult {
+		return &scanner.AggregatedResult{
+			Results:       []*scanner.ScanResult{{Scanner: "test", Findings: findings}},
+			TotalFindings: len(findings),
+		}
+	}
+	medium := scanner.Finding{Package: "a", Version: "1.0.0", Type: scanner.FindingTypeCVE, Severity: scanner.SeverityMedium}
+	malware := scanner.Finding{Package: "b", Version: "1.0.0", Type: scanner.FindingTypeMalware, Severity: scanner.SeverityLow}
+
+	tests := []struct {
+		name      string
+		threshold string
+		result    *scanner.AggregatedResult
+		wantBlock bool
+	}{
+		{"medium at medium", "medium", result(medium), true},
+		{"medium at low", "low", result(medium), true},
+		{"medium below high", "high", result(medium), false},
+		{"malware counts as critical", "critical", result(malware), true},
+		{"none never fails", "none", result(malware, medium), false},
+		{"no findings", "low", result(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := enforceFailOn(tt.threshold, tt.result)
+			if blocked := errors.ExitCodeFor(err) == errors.ExitSecurityBlock; blocked != tt.wantBlock {
+				t.Errorf("enforceFailOn(%s) = %v, want block %v", tt.threshold, err, tt.wantBlock)
+			}
+		})
+	}
+}
+
+// TestScanPackageArgs scans named packages in a directory without a
+// package.json
+func TestScanPackageArgs(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = origStdout })
+
+	rootCmd.SetArgs([]string{"scan", "--format", "json", "--simulate", "block", "express@4.18.2", "@types/node@20.1.0", "left-pad"})
+	err = Execute()
+	stdout.Close()
+	os.Stdout = origStdout
+	if err != nil {
+		t.Fatalf("scan error = %v", err)
+	}
+
+	out, _ := os.ReadFile(stdout.Name())
+	var doc report.Document
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("output is not a JSON document: %v\n%s", err, out)
+	}
+	if doc.Packages != 3 {
+		t.Errorf("packages_scanned = %d, want 3", doc.Packages)
+	}
+}
+
+func TestAdHocPackages(t *testing.T) {
+	var got []string
+	for _, p := range adHocPackages([]string{"express@4.18.2", "@types/node@20.1.0", "left-pad", "@scope/pkg"}, "npm") {
+		got = append(got, p.Name+" "+p.Version)
+	}
+	want := []string{"express 4.18.2", "@types/node 20.1.0", "left-pad latest", "@scope/pkg latest"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("adHocPackages() = %v, want %v", got, want)
+	}
+}
+
+// TestScanDir scans a project given with --dir from another directory
+func TestScanDir(t *testing.T) {
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := t.TempDir()
+
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+	t.Cleanup(func() { workDir = "" })
+
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = origStdout })
+
+	rootCmd.SetArgs([]string{"scan", "--dir", project, "--format", "json", "--simulate", "block"})
+	err = Execute()
+	stdout.Close()
+	os.Stdout = origStdout
+	if err != nil {
+		t.Fatalf("scan error = %v", err)
+	}
+
+	out, _ := os.ReadFile(stdout.Name())
+	var doc report.Document
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("output is not a JSON document: %v\n%s", err, out)
+	}
+	if doc.Packages != 1 {
+		t.Errorf("packages scanned = %d, want 1", doc.Packages)
+	}
+
+	rootCmd.SetArgs([]string{"scan", "-C", empty, "--format", "json"})
+	err = Execute()
+	if code := errors.ExitCodeFor(err); code != errors.ExitManifestError {
+		t.Fatalf("exit code = %d (%v), want %d", code, err, errors.ExitManifestError)
+	}
+	if !strings.Contains(err.Error(), empty) {
+		t.Errorf("error %q does not name %s", err, empty)
+	}
+}
+
+func TestDescribeAllowlistEntry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		entry config.AllowlistEntry
+		want  string
+	}{
+		{config.AllowlistEntry{Package: "lodash"}, "lodash (permanent)"},
+		{config.AllowlistEntry{Package: "left-pad", Reason: "reviewed", Expires: "2026-03-20"}, "left-pad (expires 2026-03-20, 11 days left): reviewed"},
+		{config.AllowlistEntry{Package: "debug", Expires: "2026-03-01"}, "debug (expired 2026-03-01)"},
+	}
+
+	for _, tt := range tests {
+		if got := describeAllowlistEntry(tt.entry, now); got != tt.want {
+			t.Errorf("describeAllowlistEntry() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCheckScannerErrors(t *testing.T) {
+	partial := &scanner.AggregatedResult{
+		Errors: []scanner.ScannerError{{Scanner: "Socket.dev", Message: "Socket API rate limit exceeded"}},
+	}
+
+	tests := []struct {
+		name    string
+		result  *scanner.AggregatedResult
+		strict  bool
+		wantErr bool
+	}{
+		{"no failures", &scanner.AggregatedResult{}, true, false},
+		{"partial", partial, false, false},
+		{"partial strict", partial, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Scanning.StrictScanners = tt.strict
+
+			var out strings.Builder
+			err := checkScannerErrors(cfg, ui.NewWriter(&out, false, false, false), tt.result)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && errors.ExitCodeFor(err) != errors.ExitScannerError {
+				t.Errorf("exit code = %d, want %d", errors.ExitCodeFor(err), errors.ExitScannerError)
+			}
+			if len(tt.result.Errors) > 0 && !strings.Contains(out.String(), "Socket.dev scan failed (Socket API rate limit exceeded)") {
+				t.Errorf("output missing scanner warning:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestScanEcosystemFlag(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+	t.Cleanup(func() { scanEcosystem = "npm" })
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"pypi packages", []string{"--ecosystem", "pypi", "requests@2.31.0"}, false},
+		{"unknown ecosystem", []string{"--ecosystem", "cobol", "x@1.0.0"}, true},
+		{"project scan", []string{"--ecosystem", "go"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			origStdout := os.Stdout
+			os.Stdout = stdout
+			t.Cleanup(func() { os.Stdout = origStdout })
+
+			rootCmd.SetArgs(append([]string{"scan", "--format", "json", "--simulate", "block"}, tt.args...))
+			err = Execute()
+			stdout.Close()
+			os.Stdout = origStdout
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scan error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			out, _ := os.ReadFile(stdout.Name())
+			var doc report.Document
+			if err := json.Unmarshal(out, &doc); err != nil {
+				t.Fatalf("output is not a JSON document: %v\n%s", err, out)
+			}
+			if len(doc.Findings) != 1 || doc.Findings[0].Package != "requests" {
+				t.Errorf("findings = %+v, want the simulated requests finding", doc.Findings)
+			}
+		})
+	}
+}
+
+func TestScanOutput(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+	t.Cleanup(func() { scanOutput = "" })
+
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = origStdout })
+
+	path := filepath.Join(t.TempDir(), "reports", "scan.json")
+	rootCmd.SetArgs([]string{"scan", "--format", "json", "--output", path, "--simulate", "block", "left-pad@1.3.0"})
+	err = Execute()
+	stdout.Close()
+	os.Stdout = origStdout
+	if err != nil {
+		t.Fatalf("scan error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	var doc report.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("report is not a JSON document: %v\n%s", err, data)
+	}
+	if doc.Packages != 1 {
+		t.Errorf("packages_scanned = %d, want 1", doc.Packages)
+	}
+
+	// The terminal gets the text output instead of the document
+	out, _ := os.ReadFile(stdout.Name())
+	if !strings.Contains(string(out), "Scanned 1 packages") || strings.Contains(string(out), `"packages_scanned"`) {
+		t.Errorf("stdout = %q, want the text summary", out)
+	}
+}
+
+func TestScanBaseline(t *testing.T) {
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SNAPEM_SIMULATE", "1")
+	t.Setenv("SOCKET_API_TOKEN", "")
+	t.Cleanup(func() { scanWriteBaseline, noBaseline = "", false })
+
+	scan := func(args ...string) int {
+		t.Helper()
+		scanWriteBaseline, noBaseline = "", false
+		rootCmd.SetArgs(append([]string{"scan", "--format", "text", "--quiet", "--simulate", "block"}, args...))
+		if err := Execute(); err != nil {
+			return errors.ExitCodeFor(err)
+		}
+		return 0
+	}
+
+	if code := scan(); code != errors.ExitSecurityBlock {
+		t.Fatalf("scan before the baseline exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+	if code := scan("--write-baseline", ".snapem-baseline.json"); code != 0 {
+		t.Fatalf("--write-baseline exit code = %d, want 0", code)
+	}
+	if _, err := os.Stat(filepath.Join(project, ".snapem-baseline.json")); err != nil {
+		t.Fatalf("baseline not written: %v", err)
+	}
+	if code := scan(); code != 0 {
+		t.Errorf("scan with the baseline exit code = %d, want 0", code)
+	}
+	if code := scan("--no-baseline"); code != errors.ExitSecurityBlock {
+		t.Errorf("--no-baseline exit code = %d, want %d", code, errors.ExitSecurityBlock)
+	}
+}
+
+// TestScanNoScanners checks that machine-readable output is still a valid
+// report when every scanner is disabled, and that the scan fails
+func TestScanNoScanners(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	simulateFlag = ""
+
+	dir := t.TempDir()
+	settings := `scanning:
+  socket: {enabled: false}
+  osv: {enabled: false}
+  typosquat: {enabled: false}
+  install_scripts: {enabled: false}
+  heuristics: {enabled: false}
+  deprecated: {enabled: false}
+  policy: {min_release_age: 0}
+`
+	if err := os.WriteFile(filepath.Join(dir, "snapem.yaml"), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHUB_ACTIONS", "")
+
+	for _, format := range []string{"json", "sarif"} {
+		t.Run(format, func(t *testing.T) {
+			stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			origStdout := os.Stdout
+			os.Stdout = stdout
+			t.Cleanup(func() { os.Stdout = origStdout })
+
+			rootCmd.SetArgs([]string{"scan", "--format", format, "left-pad@1.3.0"})
+			err = Execute()
+			stdout.Close()
+			os.Stdout = origStdout
+			if code := errors.ExitCodeFor(err); code != errors.ExitScannerError {
+				t.Errorf("exit code = %d (%v), want %d", code, err, errors.ExitScannerError)
+			}
+
+			out, _ := os.ReadFile(stdout.Name())
+			var doc map[string]any
+			if err := json.Unmarshal(out, &doc); err != nil {
+				t.Fatalf("output is not a JSON document: %v\n%s", err, out)
+			}
+			if format == "json" && !strings.Contains(string(out), "no scanners available") {
+				t.Errorf("output = %s, want the missing scanners listed", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	shellNoNetwork bool
+	shellImage     string
+)
+
+// shellCommand starts bash if the image has it, else sh
+var shellCommand = []string{"sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Open an interactive shell in the project container",
+	Long: `Opens an interactive shell (bash if the image has it, else sh) in the
+container snapem runs the project in, with the project directory mounted at
+/app as the working directory.
+
+The container has host network access unless --no-network or
+container.network is none, and gets the variables listed in
+container.environment. The shell's exit code is snapem's.
+
+Examples:
+  snapem shell                        # Shell in the detected image
+  snapem shell --image node:22-slim   # Shell in another image
+  snapem shell --no-network           # Shell without network access
+  snapem shell -e DEBUG=1             # Set an environment variable`,
+	Args: cobra.NoArgs,
+	RunE: runShell,
+}
+
+func init() {
+	shellCmd.Flags().BoolVar(&shellNoNetwork, "no-network", false, "disable network access in container")
+	shellCmd.Flags().StringVar(&shellImage, "image", "", "custom container image")
+	addVolumeOptFlag(shellCmd)
+	addLimitFlags(shellCmd)
+	addEnvFlags(shellCmd)
+
+	rootCmd.AddCommand(shellCmd)
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if !cfg.Container.Enabled {
+		display.Error("snapem shell needs a container, but container.enabled is false")
+		return errors.ConfigError("container.enabled is false")
+	}
+
+	if err := applyLimitFlags(cfg, display); err != nil {
+		return err
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	// Detect package manager for default image
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+
+	image := mgr.Image()
+	if shellImage != "" {
+		image = shellImage
+	}
+
+	networkMode := container.NetworkHost
+	if shellNoNetwork || cfg.Container.Network == "none" {
+		networkMode = container.NetworkNone
+	}
+
+	opts := execOptions(projectDir, image, networkMode, shellCommand)
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+		return err
+	}
+	if err := applyEnvFlags(display, opts); err != nil {
+		return err
+	}
+
+	if err := runPreflight(cfg, display, projectDir, 0); err != nil {
+		return err
+	}
+
+	display.Verbose(runtime.CommandString(opts))
+	display.Info(fmt.Sprintf("Shell in %s with %s mounted at /app (type exit to leave)", opts.Image, projectDir))
+
+	return runtime.Run(ctx, opts)
+}
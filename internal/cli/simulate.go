@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/simulate"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// simulation is the validated --simulate mode for the current invocation
+var simulation simulate.Mode
+
+// checkSimulation validates the --simulate flag and labels the output when a
+// simulation is active
+func checkSimulation(display *ui.UI) error {
+	mode, err := simulate.Parse(simulateFlag)
+	if err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+
+	if mode != simulate.None && !simulate.Allowed() {
+		display.Error("--simulate requires " + simulate.EnvVar + "=1")
+		return errors.ConfigError("--simulate used without " + simulate.EnvVar + "=1")
+	}
+
+	simulation = mode
+	if mode != simulate.None {
+		display.SimulationBanner(string(mode))
+	}
+
+	return nil
+}
+
+// newOrchestrator creates the scanner orchestrator, substituting the
+// simulated scanner when a scan simulation is active
+func newOrchestrator(cfg *config.Config) *scanner.Orchestrator {
+	if !simulation.AffectsScan() {
+		return scanner.NewOrchestrator(cfg)
+	}
+
+	// Keep synthetic results out of the shared scan cache
+	cfg.Scanning.Cache.Enabled = false
+	orch := scanner.NewOrchestrator(cfg)
+	orch.SetScanners(simulate.NewScanner(simulation))
+	return orch
+}
+
+// newRuntime returns the configured container runtime, substituting the
+// failing simulated runtime when a container simulation is active
+func newRuntime(cfg *config.Config, display *ui.UI) (container.Runtime, error) {
+	if simulation.AffectsContainer() {
+		return simulate.NewRuntime(), nil
+	}
+
+	runtime, err := container.NewRuntime(cfg.Container.Runtime)
+	if err != nil {
+		display.Error(err.Error())
+		return nil, errors.ConfigError(err.Error())
+	}
+
+	if !runtime.IsAvailable() {
+		reportRuntimeUnavailable(display, cfg.Container.Runtime)
+		return nil, errors.ContainerNotAvailableError()
+	}
+
+	return runtime, nil
+}
+
+// reportRuntimeUnavailable explains how to install the selected runtime
+func reportRuntimeUnavailable(display *ui.UI, name string) {
+	switch name {
+	case "docker":
+		display.Error("Docker not available")
+		display.Info("Install Docker Desktop or set container.runtime to apple")
+	case "apple":
+		display.Error("Apple container runtime not available")
+		display.Info("Install with: brew install --cask container")
+	default:
+		display.Error("No container runtime available (tried Apple container and Docker)")
+		display.Info("Install with: brew install --cask container, or install Docker")
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// TestInstallSimulateBlock runs a simulated blocking install through the
+// real policy and checks the decision, exit code and labeled output
+func TestInstallSimulateBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		decision audit.Decision
+		output   string
+	}{
+		{"blocked", nil, errors.ExitSecurityBlock, audit.DecisionBlocked, ""},
+		{"allowed", []string{"--policy-set", "malware=warn"}, 0, audit.DecisionAllowed, "Running without container isolation"},
+		{"forced", []string{"--force"}, 0, audit.DecisionForced, "Proceeding despite security warnings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			if err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name": "app", "dependencies": {"left-pad": "1.3.0"}}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(project)
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("GITHUB_ACTIONS", "")
+			t.Setenv("SNAPEM_SIMULATE", "1")
+			t.Setenv("SOCKET_API_TOKEN", "")
+			dataDir := t.TempDir()
+			t.Setenv("XDG_DATA_HOME", dataDir)
+			policySets, force, nonInteractive = nil, false, false
+			t.Cleanup(func() { policySets, force, nonInteractive = nil, false, false })
+
+			out, err := os.Create(filepath.Join(t.TempDir(), "output"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			origStdout, origStderr := os.Stdout, os.Stderr
+			os.Stdout, os.Stderr = out, out
+			t.Cleanup(func() { os.Stdout, os.Stderr = origStdout, origStderr })
+
+			rootCmd.SetArgs(append([]string{"install", "--no-container", "--non-interactive", "--simulate", "block"}, tt.args...))
+			err = Execute()
+			out.Close()
+			os.Stdout, os.Stderr = origStdout, origStderr
+
+			code := 0
+			if err != nil {
+				code = errors.ExitCodeFor(err)
+			}
+			if code != tt.code {
+				t.Fatalf("exit code = %d (%v), want %d", code, err, tt.code)
+			}
+
+			output, _ := os.ReadFile(out.Name())
+			for _, want := range []string{"SIMULATION MODE (block)", "[SIMULATED] Synthetic finding", tt.output} {
+				if !strings.Contains(string(output), want) {
+					t.Errorf("output missing %q:\n%s", want, output)
+				}
+			}
+
+			entries, err := audit.Read(filepath.Join(dataDir, "snapem", "audit.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Command != "install" || entries[0].Decision != tt.decision {
+				t.Fatalf("audit entries = %+v, want one %s install", entries, tt.decision)
+			}
+			if forced := tt.decision == audit.DecisionForced; entries[0].Forced != forced {
+				t.Errorf("audit entry Forced = %v, want %v", entries[0].Forced, forced)
+			}
+		})
+	}
+}
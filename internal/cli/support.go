@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/preflight"
+	"github.com/positronico/snapem/internal/support"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var (
+	supportYes    bool
+	supportOutput string
+)
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Create a zip with diagnostics for a bug report",
+	Long: `Collects the information maintainers need to triage an issue into a
+zip file: environment fingerprint, effective configuration and where each
+value came from, and host check results.
+
+Secrets are masked in every file, but review the list of contents before
+attaching the bundle to a public issue.
+
+Examples:
+  snapem support-bundle
+  snapem support-bundle --yes -o bundle.zip`,
+	Args: cobra.NoArgs,
+	RunE: runSupportBundle,
+}
+
+func init() {
+	supportBundleCmd.Flags().BoolVarP(&supportYes, "yes", "y", false, "write the bundle without asking for confirmation")
+	supportBundleCmd.Flags().StringVarP(&supportOutput, "output", "o", "", "output path (default: snapem-support-<timestamp>.zip)")
+	rootCmd.AddCommand(supportBundleCmd)
+}
+
+// boundFlags maps config keys to the global flags bound to them in root.go
+var boundFlags = map[string]string{
+	"ui.verbose":                 "verbose",
+	"ui.quiet":                   "quiet",
+	"ui.color":                   "no-color",
+	"package_manager.preferred":  "package-manager",
+	"package_manager.registry":   "registry",
+	"container.require_digest":   "strict-images",
+	"container.read_only_source": "read-only-src",
+}
+
+// unavailableSections are parts of a support bundle this version of snapem
+// does not record yet
+var unavailableSections = []string{
+	"audit log (not recorded by this version)",
+	"last scan report (scans are not saved by this version)",
+	"snapem log files (snapem does not write logs)",
+}
+
+func runSupportBundle(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+
+	modified, err := artifactTime(display)
+	if err != nil {
+		return err
+	}
+	output := supportOutput
+	if output == "" {
+		output = fmt.Sprintf("snapem-support-%s.zip", time.Now().Format("20060102-150405"))
+	}
+
+	files := []support.File{
+		{Name: "environment.txt", Description: "snapem version, OS, container runtime and version, shell (snapem version --full)", Content: formatVersion(true)},
+		{Name: "config.yaml", Description: "effective configuration, with the source of each value", Content: effectiveConfig()},
+		{Name: "checks.txt", Description: "container runtime availability and host preflight checks for " + projectDir, Content: hostChecks(cfg, projectDir)},
+	}
+
+	// The notice goes to stdout regardless of --quiet: it is the point of
+	// the confirmation
+	fmt.Printf("The support bundle %s will contain:\n", output)
+	for _, f := range files {
+		fmt.Printf("  %-16s %s\n", f.Name, f.Description)
+	}
+	fmt.Println("Not included:")
+	for _, s := range unavailableSections {
+		fmt.Printf("  %s\n", s)
+	}
+	fmt.Println("Values of secret-looking settings and variables are replaced with ****.")
+	fmt.Println("Review the bundle before attaching it to a public issue.")
+	fmt.Println()
+
+	if !supportYes {
+		if promptsDisabled() {
+			display.Error("Confirmation required; pass --yes to write the bundle non-interactively")
+			return errors.New(errors.ExitGeneralError, "support bundle not confirmed")
+		}
+		if !display.PromptConfirm("Write support bundle?", false) {
+			display.Info("Cancelled")
+			return errors.UserAbortError()
+		}
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		display.Error(fmt.Sprintf("Failed to create %s: %v", output, err))
+		return errors.Wrap(errors.ExitGeneralError, "failed to create support bundle", err)
+	}
+	if err := support.Write(f, files, modified); err != nil {
+		f.Close()
+		display.Error(fmt.Sprintf("Failed to write %s: %v", output, err))
+		return errors.Wrap(errors.ExitGeneralError, "failed to write support bundle", err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(errors.ExitGeneralError, "failed to write support bundle", err)
+	}
+
+	display.Success(fmt.Sprintf("Wrote %s", output))
+	return nil
+}
+
+// effectiveConfig lists every config value with its source: flag, env,
+// file or default
+func effectiveConfig() string {
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+
+	var b strings.Builder
+	if file := viper.ConfigFileUsed(); file != "" {
+		fmt.Fprintf(&b, "# config file: %s\n", file)
+	} else {
+		b.WriteString("# config file: none (using defaults)\n")
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %v # %s\n", key, viper.Get(key), configSource(key))
+	}
+
+	// Read directly from the environment by config.Load
+	fmt.Fprintf(&b, "scanning.socket.api_token: # SOCKET_API_TOKEN %s\n", setOrNot(os.Getenv("SOCKET_API_TOKEN")))
+	fmt.Fprintf(&b, "scanning.freshness.github_token: # GITHUB_TOKEN %s\n", setOrNot(os.Getenv("GITHUB_TOKEN")))
+
+	return b.String()
+}
+
+// configSource reports where viper took a key's value from
+func configSource(key string) string {
+	if name, ok := boundFlags[key]; ok && rootCmd.PersistentFlags().Changed(name) {
+		return "flag --" + name
+	}
+	env := "SNAPEM_" + strings.ToUpper(key)
+	if _, ok := os.LookupEnv(env); ok {
+		return "env " + env
+	}
+	if viper.InConfig(key) {
+		return "file"
+	}
+	return "default"
+}
+
+// hostChecks reports runtime availability and preflight problems
+func hostChecks(cfg *config.Config, projectDir string) string {
+	var b strings.Builder
+
+	r, err := container.NewRuntime(cfg.Container.Runtime)
+	switch {
+	case err != nil:
+		fmt.Fprintf(&b, "runtime: %v\n", err)
+	case r.IsAvailable():
+		fmt.Fprintf(&b, "runtime: %s %s (available)\n", r.Name(), container.Version(r))
+	default:
+		fmt.Fprintf(&b, "runtime: %s (not installed)\n", r.Name())
+	}
+
+	problems := preflight.Run(preflight.Options{
+		ProjectDir:   projectDir,
+		DiskHeadroom: cfg.Preflight.DiskHeadroom,
+	})
+	if len(problems) == 0 {
+		b.WriteString("preflight: no problems\n")
+	}
+	for _, p := range problems {
+		fmt.Fprintf(&b, "preflight %s [%s]: %s (%s)\n", p.Check, p.Level, p.Message, p.Hint)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var uninstallSaveDev bool
+
+var uninstallCmd = &cobra.Command{
+	Use:     "uninstall <packages...>",
+	Aliases: []string{"remove", "rm"},
+	Short:   "Remove dependencies in a container",
+	Long: `Removes packages from package.json and node_modules inside an isolated
+container, using the project's package manager.
+
+No security scan runs, since nothing new is installed.
+
+Examples:
+  snapem uninstall lodash         # Remove lodash
+  snapem rm -D jest               # Remove jest from devDependencies`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runUninstall,
+}
+
+func init() {
+	uninstallCmd.Flags().BoolVarP(&uninstallSaveDev, "save-dev", "D", false, "remove from devDependencies")
+	uninstallCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	addVolumeOptFlag(uninstallCmd)
+
+	rootCmd.AddCommand(uninstallCmd)
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := refuseReadOnlySource(cfg, display, "uninstall"); err != nil {
+		return err
+	}
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	// Check for package.json
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	// Detect package manager
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
+
+	// Build container options
+	uninstallCommand := mgr.UninstallCommand(args, uninstallSaveDev)
+	networkMode := container.NetworkMode(cfg.Container.Network)
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, uninstallCommand)
+
+	// Run in container (unless disabled)
+	if cfg.Container.Enabled && !noContainer {
+		runtime, err := newRuntime(cfg, display)
+		if err != nil {
+			return err
+		}
+
+		if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+			return err
+		}
+
+		if err := runPreflight(cfg, display, projectDir, 0); err != nil {
+			return err
+		}
+
+		display.ContainerHeader(runtime.CommandString(opts))
+
+		if err := runtime.Run(ctx, opts); err != nil {
+			return err
+		}
+
+		display.Success("Removal complete")
+	} else {
+		display.Warning("Running without container isolation (--no-container)")
+		display.Info(fmt.Sprintf("Command: %s %v", mgr.Name(), uninstallCommand))
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/hooks"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/pkgmanager"
+	"github.com/positronico/snapem/internal/seen"
+	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update [packages...]",
+	Short: "Update dependencies in a container and re-scan",
+	Long: `Updates dependencies within their package.json ranges inside an isolated
+container, then scans the refreshed lockfile.
+
+The scan covers every resolved package, so transitive dependencies bumped
+by the update are checked too. Packages whose versions changed are listed
+before the scan, and the install policy applies to the result: a blocked
+scan exits with code 2, leaving the updated lockfile for you to review or
+revert.
+
+Examples:
+  snapem update                # Update all dependencies
+  snapem update lodash         # Update lodash only
+  snapem update --skip-scan    # Update without re-scanning`,
+	RunE: runUpdate,
+}
+
+func init() {
+	updateCmd.Flags().BoolVar(&skipScan, "skip-scan", false, "skip the post-update security scan")
+	updateCmd.Flags().BoolVar(&force, "force", false, "override security blocks")
+	updateCmd.Flags().BoolVar(&allowUnsecure, "allow-unsecure", false, "continue without malware detection when SOCKET_API_TOKEN is not set")
+	updateCmd.Flags().BoolVar(&noContainer, "no-container", false, "run without container isolation")
+	addStrictScannersFlag(updateCmd)
+	addNoBaselineFlag(updateCmd)
+	addVolumeOptFlag(updateCmd)
+
+	rootCmd.AddCommand(updateCmd)
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Initialize UI
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	if err := checkSimulation(display); err != nil {
+		return err
+	}
+
+	if err := refuseReadOnlySource(cfg, display, "update"); err != nil {
+		return err
+	}
+
+	if err := applyPolicySets(cfg, display); err != nil {
+		return err
+	}
+	warnExpiredAllowlist(cfg, display)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	// Check for package.json
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+
+	// Detect package manager
+	mgr := pkgmanager.Detect(projectDir, pkgMgr, containerImages(cfg, display, projectDir))
+	display.Verbose(fmt.Sprintf("Using package manager: %s", mgr.Name()))
+
+	// Build container options
+	updateCommand := mgr.UpdateCommand(args)
+	networkMode := container.NetworkMode(cfg.Container.Network)
+	opts := pkgmanager.BuildContainerOptions(mgr, projectDir, packageCacheDir(cfg, display, mgr), networkMode, updateCommand)
+
+	if !cfg.Container.Enabled || noContainer {
+		display.Warning("Running without container isolation (--no-container)")
+		display.Info(fmt.Sprintf("Command: %s %v", mgr.Name(), updateCommand))
+		display.Info("For security, consider using container isolation")
+		return nil
+	}
+
+	before, _ := parser.GetDependencies(true)
+	if err := runPreflight(cfg, display, projectDir, len(before)); err != nil {
+		return err
+	}
+
+	runtime, err := newRuntime(cfg, display)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareContainer(ctx, cfg, display, runtime, opts); err != nil {
+		return err
+	}
+
+	display.ContainerHeader(runtime.CommandString(opts))
+
+	if err := runtime.Run(ctx, opts); err != nil {
+		return err
+	}
+
+	after, err := parser.GetDependencies(true)
+	if err != nil {
+		display.Warning("Could not parse the updated lockfile")
+	} else {
+		showDependencyChanges(display, dependencyChanges(before, after))
+	}
+
+	// Re-scan the refreshed lockfile (unless skipped)
+	trail := newAuditTrail(cfg, display, "update", projectDir, args)
+	if cfg.Scanning.Enabled && !skipScan {
+		hookRunner := newHookRunner(cfg, display)
+		data := hooks.InstallData{Packages: args, PackageManager: mgr.Name()}
+
+		result, err := runSecurityScan(ctx, cfg, display, parser, seen.Load(projectDir, ""), nil)
+		trail.result = result
+		if result != nil {
+			data.Scan = report.NewDocument(result)
+			hookRunner.Fire(ctx, hooks.ScanComplete, projectDir, data.Scan)
+		}
+		if err != nil {
+			if errors.ExitCodeFor(err) == errors.ExitSecurityBlock {
+				display.Info("The update has already been applied; review or revert the lockfile changes")
+			}
+			if err := overrideScanBlock(ctx, cfg, display, hookRunner, trail, projectDir, &data, err); err != nil {
+				return err
+			}
+		} else {
+			trail.record(audit.DecisionAllowed, "")
+		}
+	} else {
+		trail.record(audit.DecisionAllowed, "scan skipped")
+	}
+
+	display.Success("Update complete")
+	return nil
+}
+
+// dependencyChange is a package whose resolved versions changed
+type dependencyChange struct {
+	name string
+	from []string
+	to   []string
+}
+
+// dependencyChanges compares the resolved versions of each package before
+// and after an update, sorted by name. A package may resolve to several
+// versions when it is nested.
+func dependencyChanges(before, after []manifest.Package) []dependencyChange {
+	versions := func(packages []manifest.Package) map[string][]string {
+		m := make(map[string][]string)
+		for _, pkg := range packages {
+			if !slices.Contains(m[pkg.Name], pkg.Version) {
+				m[pkg.Name] = append(m[pkg.Name], pkg.Version)
+			}
+		}
+		for _, v := range m {
+			slices.Sort(v)
+		}
+		return m
+	}
+	old, updated := versions(before), versions(after)
+
+	names := make(map[string]bool)
+	for name := range old {
+		names[name] = true
+	}
+	for name := range updated {
+		names[name] = true
+	}
+
+	var changes []dependencyChange
+	for name := range names {
+		if !slices.Equal(old[name], updated[name]) {
+			changes = append(changes, dependencyChange{name: name, from: old[name], to: updated[name]})
+		}
+	}
+	slices.SortFunc(changes, func(a, b dependencyChange) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return changes
+}
+
+// showDependencyChanges prints one line per changed package
+func showDependencyChanges(display *ui.UI, changes []dependencyChange) {
+	if len(changes) == 0 {
+		display.Info("No resolved versions changed")
+		return
+	}
+
+	display.Print("")
+	display.Print(fmt.Sprintf("%d package(s) changed:", len(changes)))
+	for _, c := range changes {
+		switch {
+		case len(c.from) == 0:
+			display.Print(fmt.Sprintf("  + %s %s", c.name, strings.Join(c.to, ", ")))
+		case len(c.to) == 0:
+			display.Print(fmt.Sprintf("  - %s %s", c.name, strings.Join(c.from, ", ")))
+		default:
+			display.Print(fmt.Sprintf("  ~ %s %s -> %s", c.name, strings.Join(c.from, ", "), strings.Join(c.to, ", ")))
+		}
+	}
+}
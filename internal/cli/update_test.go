@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+func TestDependencyChanges(t *testing.T) {
+	before := []manifest.Package{
+		{Name: "lodash", Version: "4.17.20"},
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "debug", Version: "4.3.4"},
+		{Name: "left-pad", Version: "1.3.0"},
+		{Name: "chalk", Version: "5.3.0"},
+	}
+	after := []manifest.Package{
+		{Name: "debug", Version: "4.3.5"},
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "chalk", Version: "5.3.0"},
+		{Name: "ms", Version: "2.1.3"},
+	}
+
+	var got []string
+	for _, c := range dependencyChanges(before, after) {
+		got = append(got, fmt.Sprintf("%s %v->%v", c.name, c.from, c.to))
+	}
+
+	want := []string{
+		"debug [2.6.9 4.3.4]->[2.6.9 4.3.5]",
+		"left-pad [1.3.0]->[]",
+		"lodash [4.17.20]->[4.17.21]",
+		"ms []->[2.1.3]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("dependencyChanges() =\n%v\nwant\n%v", got, want)
+	}
+
+	if changes := dependencyChanges(before, before); len(changes) != 0 {
+		t.Errorf("unchanged dependencies reported %v", changes)
+	}
+}
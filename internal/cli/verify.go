@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/ui"
+	"github.com/positronico/snapem/internal/verify"
+)
+
+var verifyOfflineOK bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify lockfile integrity hashes against the registry",
+	Long: `Checks every package in package-lock.json against the npm registry:
+the integrity hash must match what the registry publishes for that
+version, and a resolved URL on the registry must match its tarball URL.
+
+A mismatch can mean the lockfile was poisoned or the registry tampered
+with, and exits with code 2. Packages resolved from a mirror, git or a
+local path are skipped.
+
+Set scanning.verify.on_install to run the same check before every
+install.
+
+Examples:
+  snapem verify                # Verify package-lock.json
+  snapem verify --offline-ok   # Succeed with a warning when offline`,
+	Args: cobra.NoArgs,
+	RunE: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyOfflineOK, "offline-ok", false, "skip verification with a warning when the registry is unreachable")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+	loadRegistries(cfg, display, projectDir)
+
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasManifest() {
+		display.Error(fmt.Sprintf("No package.json found in %s", projectDir))
+		return errors.ManifestError("no package.json found in "+projectDir, nil)
+	}
+	if !parser.HasLockfile() {
+		display.Error("No package-lock.json or npm-shrinkwrap.json found; other lockfiles do not record integrity hashes")
+		return errors.ManifestError("no package-lock.json found in "+projectDir, nil)
+	}
+
+	return verifyLockfile(ctx, cfg, display, parser, verifyOfflineOK)
+}
+
+// verifyLockfile checks the lockfile against the registry and reports the
+// outcome. Mismatches return a security block error; an unreachable
+// registry is only a warning when offlineOK is set.
+func verifyLockfile(ctx context.Context, cfg *config.Config, display *ui.UI, parser *manifest.Parser, offlineOK bool) error {
+	packages, err := parser.GetDependencies(true)
+	if err != nil {
+		return errors.ManifestError("failed to parse dependencies", err)
+	}
+
+	display.Verbose(fmt.Sprintf("Verifying %d packages against the npm registry...", len(packages)))
+
+	result, err := verify.NewVerifier(cfg.Scanning.Verify, registry.NewClient(cfg.PackageManager.Registries())).Verify(ctx, packages)
+	if err != nil {
+		if offlineOK && stderrors.Is(err, verify.ErrUnreachable) {
+			display.Warning("npm registry unreachable; skipping lockfile verification")
+			return nil
+		}
+		display.Error(fmt.Sprintf("Lockfile verification failed: %v", err))
+		return errors.NetworkError("npm registry", err)
+	}
+
+	for _, pkg := range result.Missing {
+		display.Warning(fmt.Sprintf("%s@%s is not in the registry (unpublished or private?)", pkg.Name, pkg.Version))
+	}
+	if len(result.Skipped) > 0 {
+		display.Verbose(fmt.Sprintf("%d package(s) skipped: no integrity hash or not from the registry", len(result.Skipped)))
+	}
+
+	if len(result.Mismatches) > 0 {
+		display.Print("")
+		display.Error("Lockfile Integrity Mismatches:")
+		for _, f := range result.Mismatches {
+			display.ThreatFound(string(f.Severity), f.Package+"@"+f.Version, f.Title+": "+f.Description)
+		}
+		display.Print("")
+		display.Error(fmt.Sprintf("%d package(s) in the lockfile do not match the registry", len(result.Mismatches)))
+		return errors.SecurityBlockError("lockfile integrity mismatch")
+	}
+
+	display.Success(fmt.Sprintf("Verified %d package(s) against the registry", result.Verified))
+	return nil
+}
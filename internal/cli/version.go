@@ -2,15 +2,25 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/manifest"
 )
 
 var (
 	versionStr string
 	commitStr  string
 	dateStr    string
+
+	versionFull bool
 )
 
 // SetVersionInfo sets version information from build flags
@@ -23,15 +33,98 @@ func SetVersionInfo(version, commit, date string) {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
+	Long: `Print version information.
+
+With --full, also prints an environment fingerprint (container runtime,
+config file, shell, CI detection) that is useful in bug reports.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("snapem %s\n", versionStr)
-		fmt.Printf("  commit: %s\n", commitStr)
-		fmt.Printf("  built:  %s\n", dateStr)
-		fmt.Printf("  go:     %s\n", runtime.Version())
-		fmt.Printf("  os:     %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		fmt.Print(formatVersion(versionFull))
 	},
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&versionFull, "full", false, "include an environment fingerprint for bug reports")
 	rootCmd.AddCommand(versionCmd)
 }
+
+// versionField is one "name: value" line of version output
+type versionField struct {
+	name  string
+	value string
+}
+
+// formatVersion renders the version output, optionally with the
+// environment fingerprint
+func formatVersion(full bool) string {
+	fields := []versionField{
+		{"commit", commitStr},
+		{"built", dateStr},
+		{"go", runtime.Version()},
+		{"os", runtime.GOOS + "/" + runtime.GOARCH},
+	}
+	if full {
+		fields = append(fields, environmentFingerprint()...)
+	}
+
+	width := 0
+	for _, f := range fields {
+		width = max(width, len(f.name)+1)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "snapem %s\n", versionStr)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "  %-*s %s\n", width, f.name+":", f.value)
+	}
+	return b.String()
+}
+
+// environmentFingerprint describes the parts of the host that most often
+// explain behavior differences between users
+func environmentFingerprint() []versionField {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		configFile = "(defaults)"
+	}
+
+	runtimeName := viper.GetString("container.runtime")
+	runtimeDesc := "unknown runtime " + runtimeName
+	if r, err := container.NewRuntime(runtimeName); err == nil {
+		if r.IsAvailable() {
+			runtimeDesc = r.Name() + " " + container.Version(r)
+		} else {
+			runtimeDesc = r.Name() + " (not installed)"
+		}
+	}
+
+	pm := "(no package.json)"
+	// filepath.Abs("") is the current directory
+	if dir, err := filepath.Abs(workDir); err == nil {
+		if parser := manifest.NewParser(dir); parser.HasManifest() {
+			pm = parser.DetectPackageManager()
+		}
+	}
+
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if os.Getenv("SHELL") == "" {
+		shell = "unknown"
+	}
+
+	return []versionField{
+		{"runtime", runtimeDesc},
+		{"config", configFile},
+		{"package manager", pm},
+		{"shell", shell},
+		{"terminal", fmt.Sprintf("%v", term.IsTerminal(int(os.Stdout.Fd())))},
+		{"ci", fmt.Sprintf("%v", os.Getenv("CI") != "")},
+		{"socket token", setOrNot(os.Getenv("SOCKET_API_TOKEN"))},
+	}
+}
+
+// setOrNot describes whether a secret is configured without revealing it
+func setOrNot(v string) string {
+	if v == "" {
+		return "not set"
+	}
+	return "set"
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// volumeOpts holds --volume-opt values for the current invocation
+var volumeOpts []string
+
+// addVolumeOptFlag registers the repeatable --volume-opt flag on a command
+func addVolumeOptFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&volumeOpts, "volume-opt", nil, "project mount option: "+strings.Join(container.VolumeOptions(), ", ")+" (repeatable)")
+}
+
+// applyVolumeOptions adds configured and --volume-opt mount options to the
+// project mount. Options the runtime does not support are dropped with a
+// verbose note; unknown option names are a configuration error.
+func applyVolumeOptions(cfg *config.Config, display *ui.UI, runtime container.Runtime, opts *container.RunOptions) error {
+	options := append(append([]string{}, cfg.Container.VolumeOptions...), volumeOpts...)
+	if len(options) == 0 {
+		return nil
+	}
+
+	if err := container.ValidateVolumeOptions(options); err != nil {
+		display.Error(err.Error())
+		return errors.ConfigError(err.Error())
+	}
+
+	supported, dropped := container.FilterVolumeOptions(runtime, options)
+	if len(dropped) > 0 {
+		display.Verbose(fmt.Sprintf("Ignoring volume options not supported by %s: %s", runtime.Name(), strings.Join(dropped, ", ")))
+	}
+
+	for i := range opts.Volumes {
+		opts.Volumes[i].Options = supported
+	}
+	return nil
+}
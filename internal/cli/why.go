@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/scanner"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <package>[@version]",
+	Short: "Explain which dependencies pull in a package",
+	Long: `Prints how a package got into the dependency tree: the shortest
+chain from each direct dependency down to every installed copy of it,
+like npm explain. Without a version every installed version is shown.
+
+The tree is read from package-lock.json or npm-shrinkwrap.json.
+
+Examples:
+  snapem why minimist           # Every copy of minimist
+  snapem why minimist@1.2.5     # Only version 1.2.5`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWhy,
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+}
+
+func runWhy(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	display := ui.New(cfg.UI.Verbose, cfg.UI.Quiet, cfg.UI.Color)
+
+	projectDir, err := projectDirectory(display)
+	if err != nil {
+		return err
+	}
+
+	parser := manifest.NewParser(projectDir)
+	if !parser.HasLockfile() {
+		display.Error("No package-lock.json or npm-shrinkwrap.json found; snapem why reads the tree from it")
+		return errors.ManifestError("no package-lock.json found in "+projectDir, nil)
+	}
+
+	graph, err := parser.DependencyGraph()
+	if err != nil {
+		return err
+	}
+
+	name, version := parsePackageArg(args[0])
+	if version == "latest" && !strings.HasSuffix(args[0], "@latest") {
+		version = ""
+	}
+
+	chains := graph.Why(name, version)
+	if len(chains) == 0 {
+		msg := fmt.Sprintf("%s is not in the dependency tree", args[0])
+		display.Error(msg)
+		return errors.ManifestError(msg, nil)
+	}
+
+	for _, chain := range chains {
+		line := chain.String()
+		if chain.Workspace != "" {
+			line = chain.Workspace + ": " + line
+		}
+		display.Print(line)
+	}
+	return nil
+}
+
+// findingIntroducers maps name@version of each package with a finding to
+// the direct dependencies that pull it in. It returns nil when there is no
+// usable npm lockfile.
+func findingIntroducers(parser *manifest.Parser, result *scanner.AggregatedResult) map[string][]string {
+	if result.TotalFindings == 0 || !parser.HasLockfile() {
+		return nil
+	}
+	graph, err := parser.DependencyGraph()
+	if err != nil {
+		return nil
+	}
+
+	via := make(map[string][]string)
+	for f := range result.Findings() {
+		key := f.Package + "@" + f.Version
+		if _, done := via[key]; done {
+			continue
+		}
+		via[key] = graph.Introducers(f.Package, f.Version)
+	}
+	return via
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// workspaceName holds --workspace for the current invocation
+var workspaceName string
+
+// addWorkspaceFlag registers --workspace on a command
+func addWorkspaceFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&workspaceName, "workspace", "w", "", "restrict to one workspace package, by name or directory")
+}
+
+// selectedWorkspace returns the workspace named by --workspace, or nil if
+// the flag is not set
+func selectedWorkspace(display *ui.UI, parser *manifest.Parser) (*manifest.Workspace, error) {
+	if workspaceName == "" {
+		return nil, nil
+	}
+
+	workspaces, err := parser.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		display.Error("--workspace needs a package.json with a workspaces field")
+		return nil, errors.ManifestError("no workspaces defined", nil)
+	}
+
+	ws, ok := manifest.FindWorkspace(workspaces, workspaceName)
+	if !ok {
+		msg := fmt.Sprintf("unknown workspace %q (workspaces: %s)", workspaceName, describeWorkspaces(workspaces))
+		display.Error(msg)
+		return nil, errors.ManifestError(msg, nil)
+	}
+	return &ws, nil
+}
+
+// workspaceDependencies returns the dependencies to scan: the selected
+// workspace's, or the whole project's. It prints which workspaces are
+// included.
+func workspaceDependencies(display *ui.UI, parser *manifest.Parser, class manifest.DependencyClass) ([]manifest.Package, error) {
+	ws, err := selectedWorkspace(display, parser)
+	if err != nil {
+		return nil, err
+	}
+	var packages []manifest.Package
+	if ws != nil {
+		display.Info(fmt.Sprintf("Workspace: %s (%s)", ws.Name, ws.Dir))
+		packages, err = parser.WorkspaceDependencies(*ws, class)
+	} else {
+		packages, err = parser.GetDependenciesFiltered(class)
+		if workspaces, wsErr := parser.Workspaces(); err == nil && wsErr == nil && len(workspaces) > 0 {
+			display.Info(fmt.Sprintf("Workspaces: %s", describeWorkspaces(workspaces)))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		if pkg.Overridden {
+			display.Verbose(fmt.Sprintf("Override applied: %s@%s", pkg.Name, pkg.Version))
+		}
+	}
+	return packages, nil
+}
+
+// describeWorkspaces lists workspaces as "name (dir)"
+func describeWorkspaces(workspaces []manifest.Workspace) string {
+	names := make([]string, len(workspaces))
+	for i, ws := range workspaces {
+		names[i] = fmt.Sprintf("%s (%s)", ws.Name, ws.Dir)
+	}
+	return strings.Join(names, ", ")
+}
+
+// workspaceOption returns the workspace name to pass to the package
+// manager, or "" for the whole project
+func workspaceOption(ws *manifest.Workspace) string {
+	if ws == nil {
+		return ""
+	}
+	return ws.Name
+}
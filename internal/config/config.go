@@ -1,10 +1,19 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/redact"
+	"github.com/positronico/snapem/internal/registry"
 )
 
 // Config holds all configuration for snapem
@@ -12,21 +21,86 @@ type Config struct {
 	PackageManager PackageManagerConfig `mapstructure:"package_manager"`
 	Scanning       ScanningConfig       `mapstructure:"scanning"`
 	Container      ContainerConfig      `mapstructure:"container"`
+	Preflight      PreflightConfig      `mapstructure:"preflight"`
+	Hooks          HooksConfig          `mapstructure:"hooks"`
+	Audit          AuditConfig          `mapstructure:"audit"`
+	Notifications  NotificationsConfig  `mapstructure:"notifications"`
 	UI             UIConfig             `mapstructure:"ui"`
+
+	// PolicySource is the scanning.policy_file merged into the policy, or
+	// nil without one
+	PolicySource *PolicySource `mapstructure:"-"`
+
+	// Warnings are settings that look wrong but do not stop snapem, each
+	// prefixed with file:line where it is known
+	Warnings []string `mapstructure:"-"`
 }
 
 // PackageManagerConfig holds package manager settings
 type PackageManagerConfig struct {
-	Preferred string `mapstructure:"preferred"` // "auto", "npm", "bun"
+	Preferred     string `mapstructure:"preferred"` // "auto", "npm", "bun", "yarn"
+	IgnoreScripts bool   `mapstructure:"ignore_scripts"`
+	Registry      string `mapstructure:"registry"` // npm registry for resolving versions
+
+	// Scopes maps scopes such as @mycorp to their registry, as read from
+	// .npmrc
+	Scopes map[string]string `mapstructure:"-"`
+}
+
+// Registries returns the registries snapem fetches package metadata from
+func (p PackageManagerConfig) Registries() registry.Registries {
+	return registry.Registries{Default: p.Registry, Scopes: p.Scopes}
 }
 
 // ScanningConfig holds security scanning settings
 type ScanningConfig struct {
-	Enabled bool         `mapstructure:"enabled"`
-	Socket  SocketConfig `mapstructure:"socket"`
-	OSV     OSVConfig    `mapstructure:"osv"`
-	Cache   CacheConfig  `mapstructure:"cache"`
-	Policy  PolicyConfig `mapstructure:"policy"`
+	Enabled   bool            `mapstructure:"enabled"`
+	Socket    SocketConfig    `mapstructure:"socket"`
+	OSV       OSVConfig       `mapstructure:"osv"`
+	Cache     CacheConfig     `mapstructure:"cache"`
+	Policy    PolicyConfig    `mapstructure:"policy"`
+	Freshness FreshnessConfig `mapstructure:"freshness"`
+	GitHub    GitHubConfig    `mapstructure:"github"`
+	Typosquat TyposquatConfig `mapstructure:"typosquat"`
+
+	InstallScripts InstallScriptsConfig `mapstructure:"install_scripts"`
+	Verify         VerifyConfig         `mapstructure:"verify"`
+	Provenance     ProvenanceConfig     `mapstructure:"provenance"`
+	Preinstall     PreinstallConfig     `mapstructure:"preinstall"`
+	Heuristics     HeuristicsConfig     `mapstructure:"heuristics"`
+	Deprecated     DeprecatedConfig     `mapstructure:"deprecated"`
+
+	// StrictScanners fails the scan when any scanner fails, instead of
+	// continuing with the results of the others
+	StrictScanners bool `mapstructure:"strict_scanners"`
+
+	// Baseline is the file of acknowledged findings, relative to the
+	// project directory; empty disables it
+	Baseline string `mapstructure:"baseline"`
+
+	// PolicyFile is a shared policy, a URL or a path relative to the
+	// config file, merged into Policy; empty disables it
+	PolicyFile string `mapstructure:"policy_file"`
+
+	// Overrides change the severity or type of findings, by scanner and
+	// then by alert type, finding ID or finding type
+	Overrides map[string]map[string]FindingOverride `mapstructure:"overrides"`
+
+	// raiseOnly is set by an enforced policy file: overrides may then
+	// raise a finding's severity but not lower it or change its type
+	raiseOnly bool
+
+	// Custom are external scanners, run as commands that read packages as
+	// JSON on stdin and write a scan result as JSON on stdout
+	Custom []CustomScannerConfig `mapstructure:"custom"`
+
+	// Proxy is used for every request snapem makes instead of HTTP_PROXY
+	// and HTTPS_PROXY; NO_PROXY still applies
+	Proxy string `mapstructure:"proxy"`
+
+	// CABundle is a PEM file of extra CA certificates, for proxies that
+	// intercept TLS
+	CABundle string `mapstructure:"ca_bundle"`
 }
 
 // SocketConfig holds Socket.dev settings
@@ -34,11 +108,105 @@ type SocketConfig struct {
 	Enabled  bool          `mapstructure:"enabled"`
 	APIToken string        `mapstructure:"api_token"`
 	Timeout  time.Duration `mapstructure:"timeout"`
+	BaseURL  string        `mapstructure:"base_url"` // API endpoint, for internal gateways
+
+	// MinScore reports packages whose overall Socket score, from 0 to
+	// 100, is below it; scanning.policy.low_score is the action. 0
+	// disables it
+	MinScore int `mapstructure:"min_score"`
+
+	// BatchSize is the most packages sent in one request; larger scans
+	// are split
+	BatchSize int `mapstructure:"batch_size"`
 }
 
 // OSVConfig holds Google OSV settings
 type OSVConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+	MaxReferences int           `mapstructure:"max_references"` // 0 keeps all references
+	BaseURL       string        `mapstructure:"base_url"`       // API endpoint, for internal gateways
+}
+
+// CustomScannerConfig describes an external scanner command
+type CustomScannerConfig struct {
+	Name    string        `mapstructure:"name"`    // reported as the scanner's name
+	Command string        `mapstructure:"command"` // executable, looked up in PATH
+	Args    []string      `mapstructure:"args"`
+	Timeout time.Duration `mapstructure:"timeout"` // 0 uses the default of 60s
+}
+
+// FreshnessConfig holds dependency freshness thresholds
+type FreshnessConfig struct {
+	AgingDays   int           `mapstructure:"aging_days"` // days since last release before a dependency is aging
+	StaleDays   int           `mapstructure:"stale_days"` // days since last release before a dependency is stale
+	GitHubToken string        `mapstructure:"github_token"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+// GitHubConfig holds GitHub Advisory Database settings
+type GitHubConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Token   string        `mapstructure:"token"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// TyposquatConfig holds offline typosquat detection settings
+type TyposquatConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Allow   []string `mapstructure:"allow"` // names or globs never reported, e.g. @mycorp/*
+}
+
+// InstallScriptsConfig holds install script detection settings
+type InstallScriptsConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// VerifyConfig holds lockfile integrity verification settings
+type VerifyConfig struct {
+	OnInstall   bool          `mapstructure:"on_install"`  // verify before every install
+	Concurrency int           `mapstructure:"concurrency"` // registry requests in flight
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+// PreinstallConfig bounds the dependency tree resolved for packages about
+// to be installed
+type PreinstallConfig struct {
+	MaxDepth    int           `mapstructure:"max_depth"`    // dependency levels below the new packages
+	MaxPackages int           `mapstructure:"max_packages"` // 0 for no limit
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+// HeuristicsConfig holds the registry metadata checks run on packages
+// about to be installed
+type HeuristicsConfig struct {
+	Enabled            bool                     `mapstructure:"enabled"`
+	NewVersionDays     int                      `mapstructure:"new_version_days"`     // versions published more recently are flagged
+	MinWeeklyDownloads int                      `mapstructure:"min_weekly_downloads"` // packages with fewer are flagged
+	Severity           HeuristicsSeverityConfig `mapstructure:"severity"`
+	Timeout            time.Duration            `mapstructure:"timeout"`
+}
+
+// HeuristicsSeverityConfig sets the severity reported for each heuristic
+type HeuristicsSeverityConfig struct {
+	NewVersion       string `mapstructure:"new_version"`
+	LowDownloads     string `mapstructure:"low_downloads"`
+	SingleMaintainer string `mapstructure:"single_maintainer"`
+	MaintainerChange string `mapstructure:"maintainer_change"`
+}
+
+// DeprecatedConfig holds detection of versions deprecated on npm
+type DeprecatedConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Severity string        `mapstructure:"severity"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// ProvenanceConfig holds npm provenance attestation checks
+type ProvenanceConfig struct {
 	Enabled bool          `mapstructure:"enabled"`
+	Require bool          `mapstructure:"require"` // block every version without provenance
 	Timeout time.Duration `mapstructure:"timeout"`
 }
 
@@ -54,45 +222,173 @@ type PolicyConfig struct {
 	Malware       string            `mapstructure:"malware"` // "block", "warn", "ignore"
 	CVE           map[string]string `mapstructure:"cve"`     // severity -> action
 	AllowOverride bool              `mapstructure:"allow_override"`
-	Allowlist     []string          `mapstructure:"allowlist"`
-	Blocklist     []string          `mapstructure:"blocklist"`
+	Allowlist     []AllowlistEntry  `mapstructure:"allowlist"`
+	Blocklist     []string          `mapstructure:"blocklist"` // "name", "name@version", "name@range" or "@scope/*"
+	License       LicensePolicy     `mapstructure:"license"`
+
+	InstallScripts string `mapstructure:"install_scripts"` // "block", "warn", "ignore"
+	Provenance     string `mapstructure:"provenance"`      // action when a version drops provenance
+
+	// MinReleaseAge quarantines versions published more recently than
+	// this; ReleaseAge is the action for them
+	MinReleaseAge time.Duration `mapstructure:"min_release_age"`
+	ReleaseAge    string        `mapstructure:"release_age"`
+
+	// LowScore is the action for packages below scanning.socket.min_score
+	LowScore string `mapstructure:"low_score"`
+}
+
+// LicensePolicy holds the action for license findings. Denied licenses
+// always block and allowed licenses are ignored, whatever the action.
+type LicensePolicy struct {
+	Action  string   `mapstructure:"action"` // "block", "warn", "ignore"
+	Allowed []string `mapstructure:"allowed_licenses"`
+	Denied  []string `mapstructure:"denied_licenses"`
+}
+
+// AllowlistEntry is a trusted package exception. A plain string in the
+// config file is an entry with only Package set, which never expires.
+type AllowlistEntry struct {
+	Package string `mapstructure:"package"` // same syntax as blocklist entries
+	Reason  string `mapstructure:"reason"`
+	Expires string `mapstructure:"expires"` // RFC3339 date or timestamp
 }
 
 // ContainerConfig holds container execution settings
 type ContainerConfig struct {
-	Enabled     bool              `mapstructure:"enabled"`
-	Image       map[string]string `mapstructure:"image"`       // "npm" -> "node:lts-slim"
-	Network     string            `mapstructure:"network"`     // "host", "none"
-	Environment []string          `mapstructure:"environment"` // env vars to pass through
+	Enabled       bool              `mapstructure:"enabled"`
+	Runtime       string            `mapstructure:"runtime"`        // "auto", "apple", "docker"
+	Image         map[string]string `mapstructure:"image"`          // "npm" -> "node:lts-slim"
+	Network       string            `mapstructure:"network"`        // "host", "none"
+	VolumeOptions []string          `mapstructure:"volume_options"` // e.g. "cached", dropped if the runtime lacks support
+	Environment   []string          `mapstructure:"environment"`    // env vars to pass through
+	RequireDigest bool              `mapstructure:"require_digest"` // refuse images not pinned by @sha256 digest
+	Limits        LimitsConfig      `mapstructure:"limits"`
+	User          string            `mapstructure:"user"`         // "host" for the host's uid:gid, or a user passed to the runtime
+	CacheVolume   bool              `mapstructure:"cache_volume"` // persist the package manager cache between containers
+	MountNpmrc    bool              `mapstructure:"mount_npmrc"`  // mount the user's .npmrc read-only for registry auth
+
+	// ReadOnlySource mounts the project read-only for run and exec, with
+	// node_modules and /tmp in the container's memory
+	ReadOnlySource bool `mapstructure:"read_only_source"`
+
+	// PinnedImages records the package managers whose image is set in the
+	// config file rather than left at the default
+	PinnedImages map[string]bool `mapstructure:"-"`
+}
+
+// LimitsConfig holds container resource limits; empty values mean no limit
+type LimitsConfig struct {
+	Memory string `mapstructure:"memory"` // e.g. "2g"
+	CPUs   string `mapstructure:"cpus"`   // e.g. "2" or "1.5"
+}
+
+// PreflightConfig holds host checks run before mounting the project
+type PreflightConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	DiskHeadroom float64 `mapstructure:"disk_headroom"` // free space needed, as a multiple of the estimated install size
+}
+
+// HooksConfig holds shell commands run on snapem events
+type HooksConfig struct {
+	Timeout         time.Duration `mapstructure:"timeout"`
+	ScanComplete    string        `mapstructure:"scan_complete"`
+	InstallBlocked  string        `mapstructure:"install_blocked"`
+	OverrideUsed    string        `mapstructure:"override_used"`
+	InstallComplete string        `mapstructure:"install_complete"`
+}
+
+// AuditConfig holds the audit log of scans, installs and overrides
+type AuditConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	File    string `mapstructure:"file"` // default ~/.local/share/snapem/audit.log
+}
+
+// NotificationsConfig holds notifications of blocks and overrides
+type NotificationsConfig struct {
+	Webhook WebhookConfig `mapstructure:"webhook"`
+}
+
+// WebhookConfig holds the webhook told when a scan blocks a command or a
+// user forces past a block
+type WebhookConfig struct {
+	URL     string        `mapstructure:"url"`    // empty disables it
+	Format  string        `mapstructure:"format"` // json, or slack for a readable message
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 // UIConfig holds UI settings
 type UIConfig struct {
-	Color   bool `mapstructure:"color"`
-	Verbose bool `mapstructure:"verbose"`
-	Quiet   bool `mapstructure:"quiet"`
+	Color   bool     `mapstructure:"color"`
+	Verbose bool     `mapstructure:"verbose"`
+	Quiet   bool     `mapstructure:"quiet"`
+	Redact  []string `mapstructure:"redact"` // variable names masked in output, beyond the secret-looking ones
+
+	// LogFormat is "text" for people or "json" for one event per line on
+	// stderr
+	LogFormat string `mapstructure:"log_format"`
 }
 
-// Load loads configuration from viper
+// Load loads configuration from viper and validates it, returning a
+// *ValidationError that lists every problem found
 func Load() (*Config, error) {
 	cfg := &Config{}
+	file := viper.ConfigFileUsed()
+	fileProblems, lines := checkFile(file)
 
 	// Unmarshal entire config
-	if err := viper.Unmarshal(cfg); err != nil {
+	if err := viper.Unmarshal(cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		stringToAllowlistEntryHook,
+		timeToStringHook,
+	))); err != nil {
+		// The file check explains a bad value better than the decoder
+		if len(fileProblems) > 0 {
+			return nil, &ValidationError{File: file, Problems: fileProblems}
+		}
 		return nil, err
 	}
 
+	if problems := mergeProblems(fileProblems, cfg.validate(), lines); len(problems) > 0 {
+		return nil, &ValidationError{File: file, Problems: problems}
+	}
+	if warnings := mergeProblems(nil, cfg.Scanning.overrideWarnings(), lines); len(warnings) > 0 {
+		cfg.Warnings = (&ValidationError{File: file, Problems: warnings}).Lines()
+	}
+
+	if err := httpclient.Configure(cfg.Scanning.Proxy, cfg.Scanning.CABundle); err != nil {
+		return nil, fmt.Errorf("scanning: %w", err)
+	}
+
 	// Handle Socket API token from environment
 	if cfg.Scanning.Socket.APIToken == "" {
 		cfg.Scanning.Socket.APIToken = os.Getenv("SOCKET_API_TOKEN")
 	}
 
+	// Handle GitHub token from environment
+	if cfg.Scanning.Freshness.GitHubToken == "" {
+		cfg.Scanning.Freshness.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	}
+
+	if cfg.Scanning.GitHub.Token == "" {
+		cfg.Scanning.GitHub.Token = os.Getenv("GITHUB_TOKEN")
+	}
+
+	// Never show the tokens, even where an API echoes them back
+	redact.Configure(cfg.UI.Redact, cfg.Scanning.Socket.APIToken, cfg.Scanning.Freshness.GitHubToken, cfg.Scanning.GitHub.Token, cfg.Notifications.Webhook.URL)
+
 	// Set default cache directory
 	if cfg.Scanning.Cache.Directory == "" {
 		cacheDir, _ := os.UserCacheDir()
 		cfg.Scanning.Cache.Directory = cacheDir + "/snapem"
 	}
 
+	// Set default audit log location
+	if cfg.Audit.File == "" {
+		cfg.Audit.File = audit.DefaultPath()
+	}
+
 	// Set default CVE policy if not set - block by default for security
 	if cfg.Scanning.Policy.CVE == nil {
 		cfg.Scanning.Policy.CVE = map[string]string{
@@ -103,11 +399,25 @@ func Load() (*Config, error) {
 		}
 	}
 
+	if cfg.Scanning.PolicyFile != "" {
+		if err := cfg.loadPolicyFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Container.PinnedImages = make(map[string]bool)
+	for name := range cfg.Container.Image {
+		if viper.InConfig("container.image." + name) {
+			cfg.Container.PinnedImages[name] = true
+		}
+	}
+
 	// Set default images if not set
 	if cfg.Container.Image == nil {
 		cfg.Container.Image = map[string]string{
-			"npm": "node:lts-slim",
-			"bun": "oven/bun:latest",
+			"npm":  "node:lts-slim",
+			"bun":  "oven/bun:latest",
+			"yarn": "node:lts-slim",
 		}
 	}
 
@@ -145,22 +455,81 @@ func (c *Config) GetCVEAction(severity string) string {
 	return "ignore"
 }
 
-// IsPackageAllowlisted returns true if the package is in the allowlist
-func (c *Config) IsPackageAllowlisted(name string) bool {
-	for _, pkg := range c.Scanning.Policy.Allowlist {
-		if pkg == name {
-			return true
+// GetLicenseAction returns the action for a license finding with the given
+// SPDX identifier, which may be empty if the scanner did not report one
+func (c *Config) GetLicenseAction(license string) string {
+	policy := c.Scanning.Policy.License
+	if license != "" {
+		for _, l := range policy.Denied {
+			if strings.EqualFold(l, license) {
+				return "block"
+			}
+		}
+		for _, l := range policy.Allowed {
+			if strings.EqualFold(l, license) {
+				return "ignore"
+			}
 		}
 	}
-	return false
+	if policy.Action == "" {
+		return "warn"
+	}
+	return policy.Action
+}
+
+// IsPackageAllowlisted returns true if an allowlist entry that has not
+// expired covers the package version
+func (c *Config) IsPackageAllowlisted(name, version string) bool {
+	now := time.Now()
+	var active []string
+	for _, entry := range c.Scanning.Policy.Allowlist {
+		if !entry.Expired(now) {
+			active = append(active, entry.Package)
+		}
+	}
+	return matchPackage(active, name, version)
+}
+
+// ExpiredAllowlist returns the allowlist entries that have lapsed
+func (c *Config) ExpiredAllowlist(now time.Time) []AllowlistEntry {
+	var expired []AllowlistEntry
+	for _, entry := range c.Scanning.Policy.Allowlist {
+		if entry.Expired(now) {
+			expired = append(expired, entry)
+		}
+	}
+	return expired
+}
+
+// IsPackageBlocklisted returns true if a blocklist entry covers the package
+// version. A version that cannot be checked against an entry's range counts
+// as covered.
+func (c *Config) IsPackageBlocklisted(name, version string) bool {
+	return blockPackage(c.Scanning.Policy.Blocklist, name, version)
+}
+
+// severities are the values accepted wherever a severity is configured
+var severities = []string{"critical", "high", "medium", "low", "info"}
+
+// validateSeverity checks that a configured severity, if set, is known
+func validateSeverity(key, value string) error {
+	if value != "" && !slices.Contains(severities, value) {
+		return fmt.Errorf("%s: unknown severity %q (use %s)", key, value, strings.Join(severities, ", "))
+	}
+	return nil
 }
 
-// IsPackageBlocklisted returns true if the package is in the blocklist
-func (c *Config) IsPackageBlocklisted(name string) bool {
-	for _, pkg := range c.Scanning.Policy.Blocklist {
-		if pkg == name {
-			return true
+// validate checks that every heuristic set has a known severity
+func (s HeuristicsSeverityConfig) validate() error {
+	for _, h := range []struct{ key, value string }{
+		{"new_version", s.NewVersion},
+		{"low_downloads", s.LowDownloads},
+		{"single_maintainer", s.SingleMaintainer},
+		{"maintainer_change", s.MaintainerChange},
+	} {
+		if err := validateSeverity("scanning.heuristics.severity."+h.key, h.value); err != nil {
+			return err
 		}
 	}
-	return false
+	return nil
 }
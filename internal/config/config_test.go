@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestGetLicenseAction(t *testing.T) {
+	cfg := &Config{}
+	cfg.Scanning.Policy.License = LicensePolicy{
+		Action:  "warn",
+		Allowed: []string{"LGPL-3.0"},
+		Denied:  []string{"GPL-3.0", "AGPL-3.0"},
+	}
+
+	tests := []struct {
+		license string
+		want    string
+	}{
+		{"GPL-3.0", "block"},
+		{"agpl-3.0", "block"},
+		{"LGPL-3.0", "ignore"},
+		{"MPL-2.0", "warn"},
+		{"", "warn"},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.GetLicenseAction(tt.license); got != tt.want {
+			t.Errorf("GetLicenseAction(%q) = %q, want %q", tt.license, got, tt.want)
+		}
+	}
+
+	cfg.Scanning.Policy.License.Action = ""
+	if got := cfg.GetLicenseAction("MPL-2.0"); got != "warn" {
+		t.Errorf("GetLicenseAction() with no action = %q, want warn", got)
+	}
+}
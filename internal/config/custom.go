@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// builtinScannerNames are the names built-in scanners report results
+// under, which custom scanners cannot take
+var builtinScannerNames = []string{
+	"Socket.dev", "Google OSV", "GitHub Advisories", "Typosquat", "Install scripts",
+	"Deprecation", "Provenance", "Heuristics", "Release age", "policy",
+}
+
+// OverrideKey returns the custom scanner's key in scanning.overrides: its
+// name in lower case, as config keys are
+func (c CustomScannerConfig) OverrideKey() string {
+	return strings.ToLower(c.Name)
+}
+
+// validateCustom checks that every custom scanner has a unique name and a
+// command
+func (s ScanningConfig) validateCustom() []Problem {
+	var problems []Problem
+	seen := make(map[string]bool)
+	for i, custom := range s.Custom {
+		key := fmt.Sprintf("scanning.custom[%d]", i)
+		add := func(format string, args ...any) {
+			problems = append(problems, Problem{Key: "scanning.custom", Message: key + ": " + fmt.Sprintf(format, args...)})
+		}
+
+		name := strings.TrimSpace(custom.Name)
+		switch {
+		case name == "":
+			add("name is required")
+		case slices.ContainsFunc(builtinScannerNames, func(b string) bool { return strings.EqualFold(b, name) }) ||
+			slices.Contains(OverrideScanners, custom.OverrideKey()):
+			add("name %q is taken by a built-in scanner", name)
+		case seen[custom.OverrideKey()]:
+			add("name %q is used by another custom scanner", name)
+		}
+		seen[custom.OverrideKey()] = true
+
+		if strings.TrimSpace(custom.Command) == "" {
+			add("command is required")
+		}
+		if custom.Timeout < 0 {
+			add("timeout cannot be negative")
+		}
+	}
+	return problems
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadCustom(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+scanning:
+  custom:
+    - name: Acme
+      command: acme-scan
+      args: [--format, json]
+      timeout: 2m
+  overrides:
+    acme:
+      cve: {severity: low}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := CustomScannerConfig{Name: "Acme", Command: "acme-scan", Args: []string{"--format", "json"}, Timeout: 2 * time.Minute}
+	if len(cfg.Scanning.Custom) != 1 || !equalCustom(cfg.Scanning.Custom[0], want) {
+		t.Errorf("custom = %+v, want %+v", cfg.Scanning.Custom, want)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Errorf("warnings = %q, want acme accepted as an overrides key", cfg.Warnings)
+	}
+}
+
+func TestValidateCustom(t *testing.T) {
+	s := ScanningConfig{Custom: []CustomScannerConfig{
+		{Name: "Acme", Command: "acme-scan"},
+		{Name: "acme", Command: "other"},
+		{Name: "Google OSV", Command: "osv-scanner"},
+		{Name: "osv", Command: "osv-scanner"},
+		{Command: "nameless"},
+		{Name: "Empty", Timeout: -time.Second},
+	}}
+
+	var got []string
+	for _, p := range s.validateCustom() {
+		got = append(got, p.Message)
+	}
+	want := []string{
+		`scanning.custom[1]: name "acme" is used by another custom scanner`,
+		`scanning.custom[2]: name "Google OSV" is taken by a built-in scanner`,
+		`scanning.custom[3]: name "osv" is taken by a built-in scanner`,
+		"scanning.custom[4]: name is required",
+		"scanning.custom[5]: command is required",
+		"scanning.custom[5]: timeout cannot be negative",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// equalCustom compares custom scanner settings
+func equalCustom(a, b CustomScannerConfig) bool {
+	return a.Name == b.Name && a.Command == b.Command && slices.Equal(a.Args, b.Args) && a.Timeout == b.Timeout
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// SetFileValue sets a dotted key such as container.image.npm in a YAML
+// config file, keeping the rest of the file and its comments. Missing
+// parent keys are added, and the file is created if it does not exist.
+func SetFileValue(path, key string, value any) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	var encoded yaml.Node
+	if err := encoded.Encode(value); err != nil {
+		return err
+	}
+
+	parent, last, err := parentMapping(path, doc, key, true)
+	if err != nil {
+		return err
+	}
+	if child := mappingValue(parent, last); child != nil {
+		// Keep the comments attached to the old value
+		encoded.LineComment, encoded.HeadComment, encoded.FootComment = child.LineComment, child.HeadComment, child.FootComment
+		*child = encoded
+	} else {
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: last}, &encoded)
+	}
+	return writeDocument(path, doc)
+}
+
+// AppendFileValue appends an item to the list at a dotted key in a YAML
+// config file, creating the list if the key is not set
+func AppendFileValue(path, key string, item any) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	var encoded yaml.Node
+	if err := encoded.Encode(item); err != nil {
+		return err
+	}
+
+	parent, last, err := parentMapping(path, doc, key, true)
+	if err != nil {
+		return err
+	}
+	list := mappingValue(parent, last)
+	switch {
+	case list == nil:
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: last}, list)
+	case list.Kind == yaml.ScalarNode && list.Tag == "!!null":
+		list.Kind, list.Tag, list.Value = yaml.SequenceNode, "", ""
+	case list.Kind != yaml.SequenceNode:
+		return fmt.Errorf("%s: %s is not a list", path, key)
+	}
+	// An empty flow list such as [] reads better as a block once it has items
+	list.Style = 0
+	list.Content = append(list.Content, &encoded)
+	return writeDocument(path, doc)
+}
+
+// UnsetFileValue removes a dotted key from a YAML config file. It returns
+// false if the key was not set there.
+func UnsetFileValue(path, key string) (bool, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return false, err
+	}
+
+	parent, last, err := parentMapping(path, doc, key, false)
+	if err != nil || parent == nil {
+		return false, err
+	}
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == last {
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+			return true, writeDocument(path, doc)
+		}
+	}
+	return false, nil
+}
+
+// readDocument parses a YAML file, returning an empty mapping document if
+// the file does not exist
+func readDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	if doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level is not a mapping", path)
+	}
+	return doc, nil
+}
+
+// writeDocument writes a YAML document with two-space indentation
+func writeDocument(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// parentMapping walks a dotted key to the mapping holding its last part,
+// adding missing parents if create is set. Without create, a missing
+// parent returns a nil mapping.
+func parentMapping(path string, doc *yaml.Node, key string, create bool) (*yaml.Node, string, error) {
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts[:len(parts)-1] {
+		child := mappingValue(node, part)
+		if child == nil {
+			if !create {
+				return nil, "", nil
+			}
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+		}
+		if child.Kind != yaml.MappingNode {
+			return nil, "", fmt.Errorf("%s: %s is not a mapping", path, strings.Join(parts[:i+1], "."))
+		}
+		node = child
+	}
+	return node, parts[len(parts)-1], nil
+}
+
+// mappingValue returns the value node for key in a mapping, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetFileValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string
+		key     string
+		value   any
+		want    string
+	}{
+		{
+			name: "replace keeps comments",
+			initial: `# Container settings
+container:
+  image:
+    npm: node:lts-slim # default
+    bun: oven/bun:latest
+`,
+			key:   "container.image.npm",
+			value: "node:lts-slim@sha256:abc",
+			want: `# Container settings
+container:
+  image:
+    npm: node:lts-slim@sha256:abc # default
+    bun: oven/bun:latest
+`,
+		},
+		{
+			name: "add missing parents",
+			initial: `scanning:
+  enabled: true
+`,
+			key:   "container.image.yarn",
+			value: "node:20-slim",
+			want: `scanning:
+  enabled: true
+container:
+  image:
+    yarn: node:20-slim
+`,
+		},
+		{
+			name:  "new file",
+			key:   "container.require_digest",
+			value: true,
+			want: `container:
+  require_digest: true
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapem.yaml")
+			if tt.initial != "" {
+				if err := os.WriteFile(path, []byte(tt.initial), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := SetFileValue(path, tt.key, tt.value); err != nil {
+				t.Fatalf("SetFileValue() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFileValueNotMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapem.yaml")
+	if err := os.WriteFile(path, []byte("container: docker\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFileValue(path, "container.image.npm", "node:20-slim"); err == nil {
+		t.Error("SetFileValue() error = nil, want error for a scalar parent")
+	}
+}
+
+func TestAppendFileValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string
+		want    string
+	}{
+		{
+			name: "existing list",
+			initial: `scanning:
+  policy:
+    # Packages to always block
+    blocklist:
+      - event-stream
+`,
+			want: `scanning:
+  policy:
+    # Packages to always block
+    blocklist:
+      - event-stream
+      - flatmap-stream
+`,
+		},
+		{
+			name: "empty flow list",
+			initial: `scanning:
+  policy:
+    blocklist: []
+`,
+			want: `scanning:
+  policy:
+    blocklist:
+      - flatmap-stream
+`,
+		},
+		{
+			name: "missing key",
+			want: `scanning:
+  policy:
+    blocklist:
+      - flatmap-stream
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapem.yaml")
+			if tt.initial != "" {
+				if err := os.WriteFile(path, []byte(tt.initial), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := AppendFileValue(path, "scanning.policy.blocklist", "flatmap-stream"); err != nil {
+				t.Fatalf("AppendFileValue() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsetFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapem.yaml")
+	initial := `scanning:
+  osv:
+    enabled: true # CVEs
+    timeout: 1m
+`
+	if err := os.WriteFile(path, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := UnsetFileValue(path, "scanning.osv.timeout")
+	if err != nil || !removed {
+		t.Fatalf("UnsetFileValue() = %v, %v, want true, nil", removed, err)
+	}
+	want := `scanning:
+  osv:
+    enabled: true # CVEs
+`
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+
+	for _, key := range []string{"scanning.osv.timeout", "container.image.npm"} {
+		if removed, err := UnsetFileValue(path, key); err != nil || removed {
+			t.Errorf("UnsetFileValue(%q) = %v, %v, want false, nil", key, removed, err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// enumValues lists the values accepted by settings with a fixed set of
+// choices
+var enumValues = map[string][]string{
+	"package_manager.preferred":       {"auto", "npm", "bun", "yarn"},
+	"container.runtime":               container.Runtimes,
+	"container.network":               {"host", "none"},
+	"scanning.policy.malware":         PolicyActions,
+	"scanning.policy.cve":             PolicyActions,
+	"scanning.policy.license.action":  PolicyActions,
+	"scanning.policy.install_scripts": PolicyActions,
+	"scanning.policy.provenance":      PolicyActions,
+	"scanning.policy.release_age":     PolicyActions,
+	"scanning.policy.low_score":       PolicyActions,
+	"scanning.deprecated.severity":    severities,
+	"scanning.heuristics.severity":    severities,
+	"notifications.webhook.format":    {"json", "slack"},
+	"ui.log_format":                   {ui.LogFormatText, ui.LogFormatJSON},
+}
+
+// optionalEnums lists the choice settings where an empty value picks the
+// default
+var optionalEnums = map[string]bool{
+	"container.runtime":            true,
+	"scanning.deprecated.severity": true,
+	"scanning.heuristics.severity": true,
+}
+
+// valueChecks validate string settings with a format of their own
+var valueChecks = map[string]func(string) error{
+	"container.limits.memory": container.ValidateMemory,
+	"container.limits.cpus":   container.ValidateCPUs,
+
+	"notifications.webhook.url": validateWebhookURL,
+}
+
+// mapKeys lists the keys accepted by map settings that only know some
+var mapKeys = map[string][]string{
+	"scanning.policy.cve": {"critical", "high", "medium", "low"},
+	"container.image":     {"npm", "bun", "yarn"},
+}
+
+// validateWebhookURL accepts an empty URL, which disables the webhook, or
+// an http or https URL
+func validateWebhookURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q (expected an http or https URL)", value)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// KeyType returns the Go type of the setting at a dotted key, such as
+// bool for container.enabled. Keys inside map settings, such as
+// container.image.npm, have the map's element type.
+func KeyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		prefix := strings.Join(parts[:i], ".")
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(t, part)
+			if !ok {
+				if guess := closestTag(t, part); guess != "" {
+					return nil, fmt.Errorf("unknown setting %q (did you mean %s?)", key, strings.Join(append(parts[:i:i], guess), "."))
+				}
+				return nil, fmt.Errorf("unknown setting %q", key)
+			}
+			t = field.Type
+		case reflect.Map:
+			if known, ok := mapKeys[prefix]; ok && !slices.Contains(known, part) {
+				return nil, fmt.Errorf("unknown setting %q (%s takes %s)", key, prefix, strings.Join(known, ", "))
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("unknown setting %q: %s is not a section", key, prefix)
+		}
+	}
+	return t, nil
+}
+
+// fieldByTag finds the struct field decoded from the mapstructure key name
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ","); tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// closestTag returns the key of the struct field within two edits of name,
+// or "" if there is none
+func closestTag(t reflect.Type, name string) string {
+	best, bestDist := "", 3
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if d := editDistance(name, tag); d < bestDist {
+			best, bestDist = tag, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// ParseValue converts a value given on the command line to the type of
+// the setting at key, checking it against the setting's allowed values.
+// Durations are kept as strings, as they are written in the config file;
+// lists are comma-separated.
+func ParseValue(key, raw string) (any, error) {
+	t, err := KeyType(key)
+	if err != nil {
+		return nil, err
+	}
+	return parseAs(key, t, raw)
+}
+
+// parseAs converts raw to type t, the type of the setting at key
+func parseAs(key string, t reflect.Type, raw string) (any, error) {
+	switch {
+	case t == durationType:
+		if _, err := time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("%s: invalid duration %q (e.g. 30s, 5m, 72h)", key, raw)
+		}
+		return raw, nil
+	case t.Kind() == reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid boolean %q (use true or false)", key, raw)
+		}
+		return v, nil
+	case t.Kind() == reflect.Int:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid integer %q", key, raw)
+		}
+		return v, nil
+	case t.Kind() == reflect.Float64:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q", key, raw)
+		}
+		return v, nil
+	case t.Kind() == reflect.String:
+		if err := checkValue(key, raw); err != nil {
+			return nil, err
+		}
+		return raw, checkEnum(key, raw)
+	case t.Kind() == reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("%s is a section; set one of its keys", key)
+}
+
+// checkValue runs the format check of the setting at key, if it has one
+func checkValue(key, value string) error {
+	if check, ok := valueChecks[key]; ok {
+		if err := check(value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// checkEnum rejects a value outside the choices of the setting at key, or
+// of the map setting containing it
+func checkEnum(key, value string) error {
+	enumKey := key
+	allowed, ok := enumValues[key]
+	if !ok {
+		enumKey, _, _ = cutLast(key)
+		if allowed, ok = enumValues[enumKey]; !ok {
+			return nil
+		}
+	}
+	if value == "" && optionalEnums[enumKey] {
+		return nil
+	}
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("%s: invalid value %q (use %s)", key, value, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// cutLast splits a dotted key at its last dot
+func cutLast(key string) (parent, last string, ok bool) {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return "", key, false
+	}
+	return key[:i], key[i+1:], true
+}
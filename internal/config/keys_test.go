@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		key     string
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{key: "scanning.policy.cve.high", raw: "warn", want: "warn"},
+		{key: "scanning.policy.cve.high", raw: "maybe", wantErr: true},
+		{key: "scanning.policy.cve.unknown", raw: "warn", wantErr: true},
+		{key: "scanning.policy.malware", raw: "ignore", want: "ignore"},
+		{key: "container.enabled", raw: "false", want: false},
+		{key: "container.enabled", raw: "nope", wantErr: true},
+		{key: "scanning.osv.timeout", raw: "1m", want: "1m"},
+		{key: "scanning.osv.timeout", raw: "60", wantErr: true},
+		{key: "scanning.osv.max_references", raw: "5", want: 5},
+		{key: "container.image.npm", raw: "node:22-slim", want: "node:22-slim"},
+		{key: "container.image.pnpm", raw: "node:22-slim", wantErr: true},
+		{key: "scanning.policy.blocklist", raw: "event-stream, flatmap-stream", want: []string{"event-stream", "flatmap-stream"}},
+		{key: "scanning.osv", raw: "true", wantErr: true},
+		{key: "scanning.nothing", raw: "true", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.raw, func(t *testing.T) {
+			got, err := ParseValue(tt.key, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// FindingOverride changes the severity or type of matching findings;
+// empty fields keep the scanner's value
+type FindingOverride struct {
+	Severity string `mapstructure:"severity"`
+	Type     string `mapstructure:"type"`
+}
+
+// OverrideScanners lists the scanner keys of scanning.overrides. Findings
+// of the policy itself, for blocklisted packages and low Socket scores,
+// cannot be overridden.
+var OverrideScanners = []string{
+	"socket", "osv", "github", "typosquat", "install_scripts", "deprecated",
+	"provenance", "heuristics", "release_age",
+}
+
+// socketAlertTypes are the Socket.dev alert types snapem knows of, for
+// catching misspelled override keys. Other alert types still match.
+var socketAlertTypes = []string{
+	"malware", "potentialVulnerability", "protestware", "typosquat", "didYouMean",
+	"socketPkgWithoutProvenance", "cve", "vulnerability", "criticalCVE", "highCVE",
+	"moderateCVE", "lowCVE", "copyleftLicense", "nonpermissiveLicense", "unknownLicense",
+	"newAuthor", "noAuthor", "suspiciousAuthorEmail", "unstableOwnership", "unmaintained",
+	"deprecated", "installScripts", "networkAccess", "shellAccess", "filesystemAccess",
+	"envVars", "obfuscatedFile", "minifiedFile", "telemetry", "trivialPackage",
+	"unpopularPackage", "gitDependency", "httpDependency", "hasNativeCode",
+	"manifestConfusion", "troll", "majorRefactor", "emptyPackage",
+}
+
+// FindingOverride returns the override for a finding from the scanner
+// with the given override key. A finding ID or alias takes precedence
+// over a Socket alert type (the finding's title), which takes precedence
+// over the finding type. Keys are matched regardless of case. Under an
+// enforced policy file, only a severity that raises the finding's applies.
+func (s ScanningConfig) FindingOverride(scanner string, f *types.Finding) (FindingOverride, bool) {
+	overrides := s.Overrides[scanner]
+	if len(overrides) == 0 {
+		return FindingOverride{}, false
+	}
+	candidates := f.IDs()
+	if scanner == "socket" {
+		candidates = append(candidates, f.Title)
+	}
+	candidates = append(candidates, string(f.Type))
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if o, ok := overrides[strings.ToLower(candidate)]; ok {
+			if s.raiseOnly {
+				return o.raising(f)
+			}
+			return o, true
+		}
+	}
+	return FindingOverride{}, false
+}
+
+// raising returns the part of the override an enforced policy keeps: its
+// severity, if that is above the finding's
+func (o FindingOverride) raising(f *types.Finding) (FindingOverride, bool) {
+	if o.Severity == "" || types.SeverityOrder(types.Severity(o.Severity)) >= types.SeverityOrder(f.Severity) {
+		return FindingOverride{}, false
+	}
+	return FindingOverride{Severity: o.Severity}, true
+}
+
+// restrictOverrides limits the overrides to raising severity, for an
+// enforced policy file, and describes the parts it ignores
+func (s *ScanningConfig) restrictOverrides() []string {
+	s.raiseOnly = true
+	var ignored []string
+	for _, scanner := range slices.Sorted(maps.Keys(s.Overrides)) {
+		for _, key := range slices.Sorted(maps.Keys(s.Overrides[scanner])) {
+			o := s.Overrides[scanner][key]
+			prefix := "scanning.overrides." + scanner + "." + key
+			if o.Type != "" {
+				ignored = append(ignored, fmt.Sprintf("%s: type %s", prefix, o.Type))
+			}
+			if o.Severity != "" && o.Severity != string(types.SeverityCritical) {
+				ignored = append(ignored, fmt.Sprintf("%s: severity %s, where it would lower a finding's severity", prefix, o.Severity))
+			}
+		}
+	}
+	return ignored
+}
+
+// validateOverrides checks the severity and type of every override
+func (s ScanningConfig) validateOverrides() []Problem {
+	var problems []Problem
+	findingTypes := findingTypeNames()
+	for _, scanner := range slices.Sorted(maps.Keys(s.Overrides)) {
+		if scanner == "policy" {
+			problems = append(problems, Problem{Key: "scanning.overrides.policy", Message: "scanning.overrides.policy: blocklist and score findings cannot be overridden; change scanning.policy instead"})
+			continue
+		}
+		for _, key := range slices.Sorted(maps.Keys(s.Overrides[scanner])) {
+			o := s.Overrides[scanner][key]
+			prefix := "scanning.overrides." + scanner + "." + key
+			if err := validateSeverity(prefix+".severity", o.Severity); err != nil {
+				problems = append(problems, Problem{Key: prefix + ".severity", Message: err.Error()})
+			}
+			if o.Type != "" && !slices.Contains(findingTypes, o.Type) {
+				problems = append(problems, Problem{Key: prefix + ".type", Message: fmt.Sprintf("%s.type: unknown finding type %q (use %s)", prefix, o.Type, strings.Join(findingTypes, ", "))})
+			}
+			if o.Severity == "" && o.Type == "" {
+				problems = append(problems, Problem{Key: prefix, Message: prefix + ": set severity, type or both"})
+			}
+		}
+	}
+	return problems
+}
+
+// overrideWarnings reports override keys that match no known scanner, and
+// keys that are neither a known alert or finding type nor shaped like an
+// advisory ID, which are most likely misspelled
+func (s ScanningConfig) overrideWarnings() []Problem {
+	var warnings []Problem
+	findingTypes := findingTypeNames()
+	for _, scanner := range slices.Sorted(maps.Keys(s.Overrides)) {
+		prefix := "scanning.overrides." + scanner
+		scanners := s.overrideScanners()
+		if !slices.Contains(scanners, scanner) {
+			warnings = append(warnings, Problem{Key: prefix, Message: fmt.Sprintf("%s: unknown scanner %q, ignored (use %s)", prefix, scanner, strings.Join(scanners, ", "))})
+			continue
+		}
+
+		known := findingTypes
+		if scanner == "socket" {
+			known = append(slices.Clone(known), socketAlertTypes...)
+		}
+		for _, key := range slices.Sorted(maps.Keys(s.Overrides[scanner])) {
+			if looksLikeFindingID(key) || slices.ContainsFunc(known, func(k string) bool { return strings.EqualFold(k, key) }) {
+				continue
+			}
+			message := fmt.Sprintf("%s.%s: %q is not a known alert or finding type; it only matches findings with that ID", prefix, key, key)
+			if guess := closestName(known, key); guess != "" {
+				message += fmt.Sprintf(" (did you mean %s?)", guess)
+			}
+			warnings = append(warnings, Problem{Key: prefix + "." + key, Message: message})
+		}
+	}
+	return warnings
+}
+
+// overrideScanners returns the scanner keys of scanning.overrides,
+// including those of custom scanners
+func (s ScanningConfig) overrideScanners() []string {
+	scanners := slices.Clone(OverrideScanners)
+	for _, custom := range s.Custom {
+		scanners = append(scanners, custom.OverrideKey())
+	}
+	return scanners
+}
+
+// findingTypeNames returns the finding types as strings
+func findingTypeNames() []string {
+	names := make([]string, len(types.FindingTypes))
+	for i, t := range types.FindingTypes {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// looksLikeFindingID returns true for advisory IDs such as CVE-2021-23337
+// or GHSA-35jh-r3h4-6jhm: letters, a dash, then an identifier with a digit
+func looksLikeFindingID(key string) bool {
+	prefix, rest, ok := strings.Cut(key, "-")
+	if !ok || prefix == "" || rest == "" {
+		return false
+	}
+	for _, r := range prefix {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return strings.ContainsAny(rest, "0123456789")
+}
+
+// closestName returns the name within two edits of key, ignoring case, or
+// "" if there is none
+func closestName(names []string, key string) string {
+	best, bestDist := "", 3
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(key)); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
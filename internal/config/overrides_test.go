@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+scanning:
+  overrides:
+    socket:
+      protestware: {severity: low, type: quality}
+      protestwear: {severity: low}
+      GHSA-35jh-r3h4-6jhm: {severity: info}
+    sokcet:
+      malware: {severity: low}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Warnings) != 2 || !strings.Contains(cfg.Warnings[0], "did you mean protestware?") || !strings.Contains(cfg.Warnings[1], `unknown scanner "sokcet"`) {
+		t.Errorf("warnings = %q", cfg.Warnings)
+	}
+
+	tests := []struct {
+		scanner string
+		finding types.Finding
+		want    FindingOverride
+		ok      bool
+	}{
+		{"socket", types.Finding{Type: types.FindingTypeMalware, Title: "protestware"}, FindingOverride{Severity: "low", Type: "quality"}, true},
+		{"socket", types.Finding{Type: types.FindingTypeMalware, Title: "malware"}, FindingOverride{}, false},
+		{"socket", types.Finding{Type: types.FindingTypeCVE, ID: "GHSA-35JH-R3H4-6JHM", Title: "protestware"}, FindingOverride{Severity: "info"}, true},
+		{"osv", types.Finding{Type: types.FindingTypeMalware, Title: "protestware"}, FindingOverride{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.Scanning.FindingOverride(tt.scanner, &tt.finding)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FindingOverride(%s, %+v) = %+v, %v, want %+v, %v", tt.scanner, tt.finding, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	s := ScanningConfig{Overrides: map[string]map[string]FindingOverride{
+		"socket": {
+			"protestware": {Severity: "lowest"},
+			"malware":     {Type: "harmless"},
+			"typosquat":   {},
+		},
+	}}
+	problems := s.validateOverrides()
+	var keys []string
+	for _, p := range problems {
+		keys = append(keys, p.Key)
+	}
+	want := "scanning.overrides.socket.malware.type scanning.overrides.socket.protestware.severity scanning.overrides.socket.typosquat"
+	if got := strings.Join(keys, " "); got != want {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+
+	// The policy's own findings cannot be overridden
+	s.Overrides = map[string]map[string]FindingOverride{"policy": {"malware": {Severity: "low"}}}
+	keys = nil
+	for _, p := range s.validateOverrides() {
+		keys = append(keys, p.Key)
+	}
+	want = "scanning.overrides.policy"
+	if got := strings.Join(keys, " "); got != want {
+		t.Errorf("problems = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// packageEntry is a parsed allowlist or blocklist entry: a package name or
+// glob such as @mycorp/*, optionally followed by @version or @range
+type packageEntry struct {
+	pattern    string
+	constraint *semver.Constraints
+}
+
+// parsePackageEntry parses "name", "name@version" or "name@range". The
+// leading @ of a scoped name is not a version separator.
+func parsePackageEntry(entry string) (packageEntry, error) {
+	name, spec := entry, ""
+	if i := strings.LastIndex(entry, "@"); i > 0 {
+		name, spec = entry[:i], entry[i+1:]
+	}
+	if name == "" {
+		return packageEntry{}, fmt.Errorf("missing package name")
+	}
+	if _, err := path.Match(name, ""); err != nil {
+		return packageEntry{}, fmt.Errorf("invalid pattern %q: %w", name, err)
+	}
+
+	parsed := packageEntry{pattern: name}
+	if spec != "" && spec != "*" {
+		constraint, err := semver.NewConstraint(spec)
+		if err != nil {
+			return packageEntry{}, fmt.Errorf("invalid version %q: %w", spec, err)
+		}
+		parsed.constraint = constraint
+	}
+	return parsed, nil
+}
+
+// matches returns true if the entry covers the package version. An entry
+// with a version never matches a version that is not valid semver, and a
+// range only matches prereleases it names, as npm does.
+func (e packageEntry) matches(name, version string) bool {
+	if ok, _ := path.Match(e.pattern, name); !ok {
+		return false
+	}
+	if e.constraint == nil {
+		return true
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return e.constraint.Check(v)
+}
+
+// blocks is matches for the blocklist, which fails closed: an entry with a
+// version blocks any version of the package that is not valid semver, such
+// as a dist-tag, an unresolved range or a git URL, and ranges cover
+// prereleases.
+func (e packageEntry) blocks(name, version string) bool {
+	if ok, _ := path.Match(e.pattern, name); !ok {
+		return false
+	}
+	if e.constraint == nil {
+		return true
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return true
+	}
+	constraint := *e.constraint
+	constraint.IncludePrerelease = true
+	return constraint.Check(v)
+}
+
+// matchPackage returns true if any valid entry matches the package version
+func matchPackage(entries []string, name, version string) bool {
+	for _, entry := range entries {
+		parsed, err := parsePackageEntry(entry)
+		if err == nil && parsed.matches(name, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockPackage returns true if any valid entry blocks the package version
+func blockPackage(entries []string, name, version string) bool {
+	for _, entry := range entries {
+		parsed, err := parsePackageEntry(entry)
+		if err == nil && parsed.blocks(name, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePackageEntries returns an error naming the first entry that cannot
+// be parsed
+func validatePackageEntries(list string, entries []string) error {
+	for _, entry := range entries {
+		if _, err := parsePackageEntry(entry); err != nil {
+			return fmt.Errorf("invalid %s entry %q: %w", list, entry, err)
+		}
+	}
+	return nil
+}
+
+// stringToAllowlistEntryHook decodes a plain string allowlist entry
+func stringToAllowlistEntryHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(AllowlistEntry{}) {
+		return data, nil
+	}
+	return AllowlistEntry{Package: data.(string)}, nil
+}
+
+// timeToStringHook keeps YAML timestamps, such as an unquoted expires date,
+// as strings. Midnight UTC is written back as a plain date.
+func timeToStringHook(from, to reflect.Type, data any) (any, error) {
+	if from != reflect.TypeOf(time.Time{}) || to.Kind() != reflect.String {
+		return data, nil
+	}
+	t := data.(time.Time)
+	if t.Equal(t.UTC().Truncate(24 * time.Hour)) {
+		return t.UTC().Format(time.DateOnly), nil
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// ExpiresAt returns when the entry lapses, or the zero time if it never
+// does. A date without a time lasts until the end of that day (UTC).
+func (e AllowlistEntry) ExpiresAt() (time.Time, error) {
+	if e.Expires == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, e.Expires); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, e.Expires)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expires %q: want a date (2006-01-02) or RFC3339 timestamp", e.Expires)
+	}
+	return t, nil
+}
+
+// Expired returns true if the entry has an expiry date that has passed. An
+// unparsable date counts as expired.
+func (e AllowlistEntry) Expired(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+	t, err := e.ExpiresAt()
+	return err != nil || !now.Before(t)
+}
+
+// validateAllowlist returns an error naming the first invalid entry
+func validateAllowlist(entries []AllowlistEntry) error {
+	for _, entry := range entries {
+		if _, err := parsePackageEntry(entry.Package); err != nil {
+			return fmt.Errorf("invalid allowlist entry %q: %w", entry.Package, err)
+		}
+		if _, err := entry.ExpiresAt(); err != nil {
+			return fmt.Errorf("invalid allowlist entry %q: %w", entry.Package, err)
+		}
+	}
+	return nil
+}
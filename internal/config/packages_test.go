@@ -0,0 +1,169 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestPackageEntryMatches(t *testing.T) {
+	tests := []struct {
+		entry   string
+		name    string
+		version string
+		want    bool
+	}{
+		{"lodash", "lodash", "1.0.0", true},
+		{"lodash", "lodash-es", "1.0.0", false},
+		{"lodash@4.17.21", "lodash", "4.17.21", true},
+		{"lodash@4.17.21", "lodash", "4.17.22", false},
+		{"event-stream@>=3.3.6", "event-stream", "3.3.6", true},
+		{"event-stream@>=3.3.6", "event-stream", "3.3.5", false},
+		{"event-stream@>=3.3.6", "event-stream", "latest", false},
+		{"debug@^4.0.0", "debug", "4.3.4", true},
+		{"@mycorp/*", "@mycorp/ui", "2.0.0", true},
+		{"@mycorp/*", "@other/ui", "2.0.0", false},
+		{"@mycorp/*@<2", "@mycorp/ui", "1.9.0", true},
+		{"@mycorp/*@<2", "@mycorp/ui", "2.0.0", false},
+		{"@types/node", "@types/node", "20.1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry+" "+tt.name+"@"+tt.version, func(t *testing.T) {
+			entry, err := parsePackageEntry(tt.entry)
+			if err != nil {
+				t.Fatalf("parsePackageEntry(%q) error = %v", tt.entry, err)
+			}
+			if got := entry.matches(tt.name, tt.version); got != tt.want {
+				t.Errorf("matches(%s@%s) = %v, want %v", tt.name, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageEntryBlocks(t *testing.T) {
+	tests := []struct {
+		entry   string
+		name    string
+		version string
+		want    bool
+	}{
+		{"evil", "evil", "1.0.0", true},
+		{"evil", "evil-twin", "1.0.0", false},
+		{"evil@>=1.0.0", "evil", "0.9.0", false},
+		{"evil@>=1.0.0", "evil", "1.2.0", true},
+		// Versions that cannot be checked fail closed
+		{"evil@>=1.0.0", "evil", "latest", true},
+		{"evil@>=1.0.0", "evil", "^1.2.0", true},
+		{"evil@>=1.0.0", "evil", "git+https://github.com/evil/evil.git", true},
+		{"evil@>=1.0.0", "evil", "", true},
+		{"evil@>=1.0.0", "other", "latest", false},
+		// Ranges cover prereleases
+		{"evil@>=1.0.0", "evil", "2.0.0-beta.1", true},
+		{"evil@1.x", "evil", "1.5.0-rc.0", true},
+		{"evil@>=1.0.0", "evil", "0.9.0-beta.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry+" "+tt.name+"@"+tt.version, func(t *testing.T) {
+			entry, err := parsePackageEntry(tt.entry)
+			if err != nil {
+				t.Fatalf("parsePackageEntry(%q) error = %v", tt.entry, err)
+			}
+			if got := entry.blocks(tt.name, tt.version); got != tt.want {
+				t.Errorf("blocks(%s@%s) = %v, want %v", tt.name, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowlistStaysStrict(t *testing.T) {
+	cfg := &Config{}
+	cfg.Scanning.Policy.Allowlist = []AllowlistEntry{{Package: "lodash@>=4.0.0"}}
+	cfg.Scanning.Policy.Blocklist = []string{"lodash@>=4.0.0"}
+
+	for _, version := range []string{"latest", "^4.0.0", "5.0.0-beta.1"} {
+		if cfg.IsPackageAllowlisted("lodash", version) {
+			t.Errorf("IsPackageAllowlisted(lodash@%s) = true, want false", version)
+		}
+		if !cfg.IsPackageBlocklisted("lodash", version) {
+			t.Errorf("IsPackageBlocklisted(lodash@%s) = false, want true", version)
+		}
+	}
+}
+
+func TestParsePackageEntryInvalid(t *testing.T) {
+	for _, entry := range []string{"lodash@not-a-range!", "[abc", ""} {
+		if _, err := parsePackageEntry(entry); err == nil {
+			t.Errorf("parsePackageEntry(%q) expected error", entry)
+		}
+	}
+}
+
+func TestLoadAllowlist(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+scanning:
+  cache:
+    ttl: 24h
+  policy:
+    allowlist:
+      - lodash
+      - package: left-pad@1.3.0
+        reason: reviewed by security
+        expires: 2000-01-01
+      - package: "@mycorp/*"
+        expires: 2999-12-31T00:00:00Z
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Scanning.Cache.TTL != 24*time.Hour {
+		t.Errorf("cache ttl = %v, want 24h", cfg.Scanning.Cache.TTL)
+	}
+
+	allowlist := cfg.Scanning.Policy.Allowlist
+	if len(allowlist) != 3 || allowlist[0].Package != "lodash" || allowlist[1].Reason != "reviewed by security" {
+		t.Fatalf("allowlist = %+v", allowlist)
+	}
+	if !cfg.IsPackageAllowlisted("lodash", "4.17.21") || !cfg.IsPackageAllowlisted("@mycorp/ui", "1.0.0") {
+		t.Error("active entries should be allowlisted")
+	}
+	if cfg.IsPackageAllowlisted("left-pad", "1.3.0") {
+		t.Error("expired entry should not be allowlisted")
+	}
+	if expired := cfg.ExpiredAllowlist(time.Now()); len(expired) != 1 || expired[0].Package != "left-pad@1.3.0" {
+		t.Errorf("ExpiredAllowlist() = %+v", expired)
+	}
+}
+
+func TestAllowlistEntryExpired(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expires string
+		want    bool
+	}{
+		{"", false},
+		{"2026-03-10", false},
+		{"2026-03-09", true},
+		{"2026-03-10T11:00:00Z", true},
+		{"2026-03-10T13:00:00Z", false},
+		{"next week", true},
+	}
+
+	for _, tt := range tests {
+		entry := AllowlistEntry{Package: "lodash", Expires: tt.expires}
+		if got := entry.Expired(now); got != tt.want {
+			t.Errorf("Expired(%q) = %v, want %v", tt.expires, got, tt.want)
+		}
+	}
+}
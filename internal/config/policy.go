@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolicyActions lists the valid policy actions
+var PolicyActions = []string{"block", "warn", "ignore"}
+
+// PolicyKeys lists the policy settings that can be overridden per invocation
+var PolicyKeys = []string{"malware", "cve.critical", "cve.high", "cve.medium", "cve.low", "license", "install_scripts", "provenance", "release_age", "low_score", "allow_override"}
+
+// SetPolicy applies a "key=value" policy override on top of the loaded
+// configuration, validating both the key and the value
+func (c *Config) SetPolicy(assignment string) error {
+	key, value, ok := strings.Cut(assignment, "=")
+	if !ok {
+		return fmt.Errorf("invalid policy override %q: expected key=value (allowed keys: %s)", assignment, strings.Join(PolicyKeys, ", "))
+	}
+	key = strings.TrimSpace(key)
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if key == "allow_override" {
+		switch value {
+		case "true":
+			c.Scanning.Policy.AllowOverride = true
+		case "false":
+			c.Scanning.Policy.AllowOverride = false
+		default:
+			return fmt.Errorf("invalid value %q for allow_override (allowed values: true, false)", value)
+		}
+		return nil
+	}
+
+	if !isPolicyKey(key) {
+		return fmt.Errorf("unknown policy key %q (allowed keys: %s)", key, strings.Join(PolicyKeys, ", "))
+	}
+
+	if !IsPolicyAction(value) {
+		return fmt.Errorf("invalid value %q for %s (allowed values: %s)", value, key, strings.Join(PolicyActions, ", "))
+	}
+
+	if key == "malware" {
+		c.Scanning.Policy.Malware = value
+		return nil
+	}
+	if key == "provenance" {
+		c.Scanning.Policy.Provenance = value
+		return nil
+	}
+	if key == "release_age" {
+		c.Scanning.Policy.ReleaseAge = value
+		return nil
+	}
+	if key == "low_score" {
+		c.Scanning.Policy.LowScore = value
+		return nil
+	}
+	if key == "install_scripts" {
+		c.Scanning.Policy.InstallScripts = value
+		return nil
+	}
+	if key == "license" {
+		c.Scanning.Policy.License.Action = value
+		return nil
+	}
+
+	if c.Scanning.Policy.CVE == nil {
+		c.Scanning.Policy.CVE = make(map[string]string)
+	}
+	c.Scanning.Policy.CVE[strings.TrimPrefix(key, "cve.")] = value
+	return nil
+}
+
+// IsPolicyAction returns true if the value is a valid policy action
+func IsPolicyAction(value string) bool {
+	for _, a := range PolicyActions {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isPolicyKey(key string) bool {
+	for _, k := range PolicyKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+
+	"github.com/positronico/snapem/internal/httpclient"
+)
+
+// policyFetchTimeout bounds fetching scanning.policy_file from a URL
+const policyFetchTimeout = 10 * time.Second
+
+// PolicySource describes the policy file merged into scanning.policy
+type PolicySource struct {
+	// Location is the URL or path set in scanning.policy_file
+	Location string
+
+	// Enforced is set when the file marks itself non-overridable: local
+	// settings may tighten its policy but not relax it
+	Enforced bool
+
+	// Cached is set when the URL could not be fetched and the copy saved
+	// by an earlier fetch was used
+	Cached bool
+
+	// Keys are the scanning.policy keys, such as cve.high or blocklist,
+	// whose value comes from the file
+	Keys map[string]bool
+
+	// Ignored describes the local settings the enforced policy overrode
+	Ignored []string
+
+	// policy is the file's policy, for checking --policy-set overrides
+	policy PolicyConfig
+}
+
+// policyFile is the format of scanning.policy_file: the keys of
+// scanning.policy, and enforced
+type policyFile struct {
+	Enforced bool         `mapstructure:"enforced"`
+	Policy   PolicyConfig `mapstructure:",squash"`
+}
+
+// loadPolicyFile reads scanning.policy_file and merges it into the
+// policy. A relative path is resolved against the config file's
+// directory; a URL is cached in cacheDir for offline use.
+func (c *Config) loadPolicyFile(configFile string) error {
+	location := c.Scanning.PolicyFile
+	var data []byte
+	var cached bool
+	var err error
+	if isURL(location) {
+		data, cached, err = fetchPolicyFile(location, filepath.Join(c.Scanning.Cache.Directory, "policy"))
+	} else {
+		path := location
+		if !filepath.IsAbs(path) && configFile != "" {
+			path = filepath.Join(filepath.Dir(configFile), path)
+		}
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("scanning.policy_file: %w", err)
+	}
+
+	if err := c.mergePolicy(data, location); err != nil {
+		return fmt.Errorf("scanning.policy_file %s: %w", location, err)
+	}
+	c.PolicySource.Cached = cached
+	return nil
+}
+
+// isURL returns true if location is an http or https URL
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://")
+}
+
+// fetchPolicyFile downloads a policy file, revalidating the cached copy
+// with its ETag. If the URL cannot be fetched, the cached copy is used and
+// cached is true.
+func fetchPolicyFile(url, cacheDir string) (data []byte, cached bool, err error) {
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+".yaml")
+	etagPath := path + ".etag"
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if etag, err := os.ReadFile(etagPath); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	fetchErr := func() error {
+		resp, err := httpclient.NewNoRetry(policyFetchTimeout).Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusNotModified:
+			data, err = os.ReadFile(path)
+			return err
+		case http.StatusOK:
+			data, err = io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			// A failed cache write only costs offline use
+			if os.MkdirAll(cacheDir, 0755) == nil && os.WriteFile(path, data, 0644) == nil {
+				if etag := resp.Header.Get("ETag"); etag != "" {
+					os.WriteFile(etagPath, []byte(etag), 0644)
+				} else {
+					os.Remove(etagPath)
+				}
+			}
+			return nil
+		default:
+			return fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+	}()
+	if fetchErr == nil {
+		return data, false, nil
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w (and no cached copy)", fetchErr)
+	}
+	return data, true, nil
+}
+
+// mergePolicy merges a policy file into the policy. Settings the file
+// leaves out keep their local value. Without enforced, the settings in
+// the local config file win; with it, local settings may only be
+// stricter, scanning.overrides may only raise severities, and relaxing
+// settings are recorded in PolicySource.Ignored. Blocklists and denied
+// licenses are always combined.
+func (c *Config) mergePolicy(data []byte, location string) error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	for _, key := range v.AllKeys() {
+		if key == "enforced" {
+			continue
+		}
+		if _, err := KeyType("scanning.policy." + key); err != nil {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+	}
+
+	var file policyFile
+	if err := v.Unmarshal(&file, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		stringToAllowlistEntryHook,
+		timeToStringHook,
+	))); err != nil {
+		return err
+	}
+	check := &Config{}
+	check.Scanning.Policy = file.Policy
+	for _, p := range check.validate() {
+		if strings.HasPrefix(p.Key, "scanning.policy.") {
+			return fmt.Errorf("%s", p.Message)
+		}
+	}
+
+	src := &PolicySource{Location: location, Enforced: file.Enforced, Keys: make(map[string]bool), policy: file.Policy}
+	local := &c.Scanning.Policy
+	remote := file.Policy
+
+	// merge settles one setting; stricter reports whether the local value
+	// is at least as strict as the file's
+	merge := func(key string, stricter bool, useRemote func(), describe string) {
+		if !v.IsSet(key) {
+			return
+		}
+		if viper.InConfig("scanning.policy." + key) {
+			if !src.Enforced || stricter {
+				return
+			}
+			src.Ignored = append(src.Ignored, fmt.Sprintf("scanning.policy.%s: %s", key, describe))
+		}
+		useRemote()
+		src.Keys[key] = true
+	}
+	action := func(key string, value *string, remote string) {
+		merge(key, actionRank(*value) >= actionRank(remote), func() { *value = remote }, *value)
+	}
+
+	action("malware", &local.Malware, remote.Malware)
+	action("install_scripts", &local.InstallScripts, remote.InstallScripts)
+	action("provenance", &local.Provenance, remote.Provenance)
+	action("release_age", &local.ReleaseAge, remote.ReleaseAge)
+	action("low_score", &local.LowScore, remote.LowScore)
+	action("license.action", &local.License.Action, remote.License.Action)
+	if local.CVE == nil {
+		local.CVE = make(map[string]string)
+	}
+	for _, severity := range slices.Sorted(maps.Keys(remote.CVE)) {
+		value := local.CVE[severity]
+		action("cve."+severity, &value, remote.CVE[severity])
+		local.CVE[severity] = value
+	}
+	merge("allow_override", !local.AllowOverride || remote.AllowOverride,
+		func() { local.AllowOverride = remote.AllowOverride }, fmt.Sprint(local.AllowOverride))
+	merge("min_release_age", local.MinReleaseAge >= remote.MinReleaseAge,
+		func() { local.MinReleaseAge = remote.MinReleaseAge }, local.MinReleaseAge.String())
+
+	// Exceptions can only be granted by the enforced file
+	if src.Enforced {
+		if v.IsSet("allowlist") || len(local.Allowlist) > 0 {
+			if len(local.Allowlist) > 0 && viper.InConfig("scanning.policy.allowlist") {
+				src.Ignored = append(src.Ignored, fmt.Sprintf("scanning.policy.allowlist: %d local exception(s)", len(local.Allowlist)))
+			}
+			local.Allowlist = remote.Allowlist
+			src.Keys["allowlist"] = true
+		}
+		if v.IsSet("license.allowed_licenses") || len(local.License.Allowed) > 0 {
+			if len(local.License.Allowed) > 0 && viper.InConfig("scanning.policy.license.allowed_licenses") {
+				src.Ignored = append(src.Ignored, fmt.Sprintf("scanning.policy.license.allowed_licenses: %s", strings.Join(local.License.Allowed, ", ")))
+			}
+			local.License.Allowed = remote.License.Allowed
+			src.Keys["license.allowed_licenses"] = true
+		}
+	} else {
+		if len(remote.Allowlist) > 0 {
+			local.Allowlist = append(slices.Clone(remote.Allowlist), local.Allowlist...)
+			src.Keys["allowlist"] = true
+		}
+		if len(remote.License.Allowed) > 0 {
+			local.License.Allowed = union(remote.License.Allowed, local.License.Allowed)
+			src.Keys["license.allowed_licenses"] = true
+		}
+	}
+	if len(remote.Blocklist) > 0 {
+		local.Blocklist = union(remote.Blocklist, local.Blocklist)
+		src.Keys["blocklist"] = true
+	}
+	if len(remote.License.Denied) > 0 {
+		local.License.Denied = union(remote.License.Denied, local.License.Denied)
+		src.Keys["license.denied_licenses"] = true
+	}
+
+	// Reclassifying findings could let them past the policy
+	if src.Enforced {
+		src.Ignored = append(src.Ignored, c.Scanning.restrictOverrides()...)
+	}
+
+	c.PolicySource = src
+	return nil
+}
+
+// PolicyLocked returns the enforced policy file's value for a --policy-set
+// assignment that would relax it, or "" if the assignment may apply
+func (c *Config) PolicyLocked(assignment string) string {
+	src := c.PolicySource
+	if src == nil || !src.Enforced {
+		return ""
+	}
+	key, value, _ := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	remote := src.policy
+	var enforced string
+	switch key {
+	case "allow_override":
+		if !src.Keys[key] || remote.AllowOverride || value != "true" {
+			return ""
+		}
+		return "false"
+	case "malware":
+		enforced = remote.Malware
+	case "install_scripts":
+		enforced = remote.InstallScripts
+	case "provenance":
+		enforced = remote.Provenance
+	case "release_age":
+		enforced = remote.ReleaseAge
+	case "low_score":
+		enforced = remote.LowScore
+	case "license":
+		key, enforced = "license.action", remote.License.Action
+	default:
+		enforced = remote.CVE[strings.TrimPrefix(key, "cve.")]
+	}
+	if !src.Keys[key] || enforced == "" || !IsPolicyAction(value) || actionRank(value) >= actionRank(enforced) {
+		return ""
+	}
+	return enforced
+}
+
+// actionRank orders policy actions from most lenient to strictest
+func actionRank(action string) int {
+	switch action {
+	case "block":
+		return 2
+	case "warn":
+		return 1
+	}
+	return 0
+}
+
+// union returns a followed by the items of b it lacks
+func union(a, b []string) []string {
+	out := slices.Clone(a)
+	for _, item := range b {
+		if !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
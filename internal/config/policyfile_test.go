@@ -0,0 +1,234 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// loadWithPolicyFile loads a local config whose scanning.policy_file is a
+// file with the given content
+func loadWithPolicyFile(t *testing.T, local, remote string) *Config {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(remote), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "snapem.yaml")
+	if err := os.WriteFile(path, []byte("scanning:\n  policy_file: policy.yaml\n"+local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetDefault("scanning.policy.malware", "block")
+	viper.SetDefault("scanning.policy.install_scripts", "warn")
+	viper.SetDefault("scanning.policy.allow_override", false)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg
+}
+
+func TestPolicyFileMerge(t *testing.T) {
+	local := `  policy:
+    malware: warn
+    cve:
+      high: ignore
+      low: block
+    allow_override: true
+    blocklist: [event-stream]
+    allowlist: [left-pad]
+`
+	remote := `malware: block
+install_scripts: block
+cve:
+  high: block
+  low: warn
+allow_override: false
+blocklist: [flatmap-stream]
+allowlist: ["@mycorp/*"]
+`
+
+	t.Run("not enforced", func(t *testing.T) {
+		cfg := loadWithPolicyFile(t, local, remote)
+		policy := cfg.Scanning.Policy
+		if policy.Malware != "warn" || policy.CVE["high"] != "ignore" || !policy.AllowOverride {
+			t.Errorf("local values should win: %+v", policy)
+		}
+		if policy.InstallScripts != "block" || !cfg.PolicySource.Keys["install_scripts"] {
+			t.Errorf("install_scripts = %q, want the file's block", policy.InstallScripts)
+		}
+		if !slices.Equal(policy.Blocklist, []string{"flatmap-stream", "event-stream"}) {
+			t.Errorf("blocklist = %v", policy.Blocklist)
+		}
+		if len(policy.Allowlist) != 2 {
+			t.Errorf("allowlist = %+v, want both entries", policy.Allowlist)
+		}
+		if len(cfg.PolicySource.Ignored) != 0 {
+			t.Errorf("ignored = %v", cfg.PolicySource.Ignored)
+		}
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		cfg := loadWithPolicyFile(t, local, "enforced: true\n"+remote)
+		policy := cfg.Scanning.Policy
+		if policy.Malware != "block" || policy.CVE["high"] != "block" || policy.AllowOverride {
+			t.Errorf("local relaxations should be ignored: %+v", policy)
+		}
+		if policy.CVE["low"] != "block" {
+			t.Errorf("cve.low = %q, the stricter local block should stay", policy.CVE["low"])
+		}
+		if len(policy.Allowlist) != 1 || policy.Allowlist[0].Package != "@mycorp/*" {
+			t.Errorf("allowlist = %+v, want only the file's entry", policy.Allowlist)
+		}
+		if !slices.Equal(policy.Blocklist, []string{"flatmap-stream", "event-stream"}) {
+			t.Errorf("blocklist = %v", policy.Blocklist)
+		}
+
+		var ignored []string
+		for _, item := range cfg.PolicySource.Ignored {
+			key, _, _ := strings.Cut(item, ":")
+			ignored = append(ignored, key)
+		}
+		want := []string{"scanning.policy.malware", "scanning.policy.cve.high", "scanning.policy.allow_override", "scanning.policy.allowlist"}
+		if !slices.Equal(ignored, want) {
+			t.Errorf("ignored = %v, want %v", ignored, want)
+		}
+
+		tests := []struct {
+			assignment string
+			want       string
+		}{
+			{"cve.high=warn", "block"},
+			{"cve.high=block", ""},
+			{"cve.medium=ignore", ""},
+			{"malware=ignore", "block"},
+			{"allow_override=true", "false"},
+			{"cve.high=bogus", ""},
+		}
+		for _, tt := range tests {
+			if got := cfg.PolicyLocked(tt.assignment); got != tt.want {
+				t.Errorf("PolicyLocked(%q) = %q, want %q", tt.assignment, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestPolicyFileEnforcedOverrides(t *testing.T) {
+	local := `  overrides:
+    socket:
+      malware: {severity: low, type: quality}
+    osv:
+      GHSA-35jh-r3h4-6jhm: {severity: critical}
+      cve: {severity: high}
+`
+	malware := types.Finding{Type: types.FindingTypeMalware, Severity: types.SeverityCritical, Title: "malware"}
+	advisory := types.Finding{Type: types.FindingTypeCVE, Severity: types.SeverityLow, ID: "GHSA-35jh-r3h4-6jhm"}
+	cve := types.Finding{Type: types.FindingTypeCVE, Severity: types.SeverityCritical, ID: "CVE-2024-0001"}
+	lowCVE := types.Finding{Type: types.FindingTypeCVE, Severity: types.SeverityMedium, ID: "CVE-2024-0002"}
+
+	t.Run("not enforced", func(t *testing.T) {
+		cfg := loadWithPolicyFile(t, local, "malware: block\n")
+		if o, ok := cfg.Scanning.FindingOverride("socket", &malware); !ok || o.Severity != "low" || o.Type != "quality" {
+			t.Errorf("FindingOverride(socket malware) = %+v, %v", o, ok)
+		}
+		if len(cfg.PolicySource.Ignored) != 0 {
+			t.Errorf("ignored = %v", cfg.PolicySource.Ignored)
+		}
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		cfg := loadWithPolicyFile(t, local, "enforced: true\nmalware: block\n")
+
+		tests := []struct {
+			scanner string
+			finding types.Finding
+			want    FindingOverride
+			ok      bool
+		}{
+			{"socket", malware, FindingOverride{}, false},
+			{"osv", advisory, FindingOverride{Severity: "critical"}, true},
+			{"osv", cve, FindingOverride{}, false},
+			{"osv", lowCVE, FindingOverride{Severity: "high"}, true},
+		}
+		for _, tt := range tests {
+			got, ok := cfg.Scanning.FindingOverride(tt.scanner, &tt.finding)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("FindingOverride(%s, %s) = %+v, %v, want %+v, %v", tt.scanner, tt.finding.Title+tt.finding.ID, got, ok, tt.want, tt.ok)
+			}
+		}
+
+		want := []string{
+			"scanning.overrides.osv.cve: severity high, where it would lower a finding's severity",
+			"scanning.overrides.socket.malware: type quality",
+			"scanning.overrides.socket.malware: severity low, where it would lower a finding's severity",
+		}
+		if !slices.Equal(cfg.PolicySource.Ignored, want) {
+			t.Errorf("ignored = %q, want %q", cfg.PolicySource.Ignored, want)
+		}
+	})
+}
+
+func TestPolicyFileInvalid(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	for _, remote := range []string{"malwre: block\n", "malware: blcok\n"} {
+		cfg := &Config{}
+		if err := cfg.mergePolicy([]byte(remote), "policy.yaml"); err == nil {
+			t.Errorf("mergePolicy(%q) succeeded, want an error", remote)
+		}
+	}
+}
+
+func TestFetchPolicyFile(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("malware: block\n"))
+	}))
+	cacheDir := t.TempDir()
+	url := server.URL + "/policy.yaml"
+
+	for i := 0; i < 2; i++ {
+		data, cached, err := fetchPolicyFile(url, cacheDir)
+		if err != nil || cached || string(data) != "malware: block\n" {
+			t.Fatalf("fetch %d = %q, %v, %v", i, data, cached, err)
+		}
+	}
+	if !slices.Equal(requests, []string{"", `"v1"`}) {
+		t.Errorf("If-None-Match headers = %q, want the ETag on the second request", requests)
+	}
+
+	// Offline, the cached copy is used
+	server.Close()
+	data, cached, err := fetchPolicyFile(url, cacheDir)
+	if err != nil || !cached || string(data) != "malware: block\n" {
+		t.Errorf("offline fetch = %q, %v, %v, want the cached copy", data, cached, err)
+	}
+
+	// Without a cached copy there is no policy to fall back on
+	if _, _, err := fetchPolicyFile(url, t.TempDir()); err == nil {
+		t.Error("offline fetch without a cache succeeded, want an error")
+	}
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// Problem is an invalid setting found while validating the configuration
+type Problem struct {
+	Key     string // dotted key, empty for problems with the file itself
+	Line    int    // line in the config file, 0 if the value came from elsewhere
+	Message string
+}
+
+// ValidationError lists every problem found in the configuration
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := e.Lines()
+	if len(lines) == 1 {
+		return "invalid configuration: " + lines[0]
+	}
+	return fmt.Sprintf("invalid configuration (%d problems):\n  %s", len(lines), strings.Join(lines, "\n  "))
+}
+
+// Lines returns each problem, prefixed with file:line where it is known
+func (e *ValidationError) Lines() []string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = e.location(p) + p.Message
+	}
+	return lines
+}
+
+// Concerns returns true if any problem is with key or a setting below it
+func (e *ValidationError) Concerns(key string) bool {
+	for _, p := range e.Problems {
+		if p.Key == key || strings.HasPrefix(p.Key, key+".") || strings.HasPrefix(key, p.Key+".") || p.Key == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// location prefixes a problem with file:line when it was found in the file
+func (e *ValidationError) location(p Problem) string {
+	switch {
+	case e.File == "":
+		return ""
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d: ", e.File, p.Line)
+	case p.Key == "":
+		return e.File + ": "
+	}
+	return ""
+}
+
+// checkFile validates the keys and values written in a YAML config file:
+// unknown keys, values of the wrong type and values outside a setting's
+// choices. It also returns the line of every key, so problems found later
+// in the decoded config can point at the file.
+func checkFile(path string) ([]Problem, map[string]int) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Problem{{Message: err.Error()}}, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Problem{{Message: err.Error()}}, nil
+	}
+	if doc.Kind == 0 {
+		return nil, nil // empty file
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return []Problem{{Line: doc.Content[0].Line, Message: "top level is not a mapping"}}, nil
+	}
+
+	var problems []Problem
+	lines := make(map[string]int)
+	checkMapping(doc.Content[0], "", &problems, lines)
+	return problems, lines
+}
+
+// checkMapping validates the keys of a YAML mapping below prefix
+func checkMapping(node *yaml.Node, prefix string, problems *[]Problem, lines map[string]int) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		key := k.Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		lines[key] = k.Line
+
+		t, err := KeyType(key)
+		if err != nil {
+			*problems = append(*problems, Problem{Key: key, Line: k.Line, Message: err.Error()})
+			continue
+		}
+		if err := checkNode(key, t, v); err != nil {
+			*problems = append(*problems, Problem{Key: key, Line: k.Line, Message: err.Error()})
+			continue
+		}
+		if v.Kind == yaml.MappingNode {
+			checkMapping(v, key, problems, lines)
+		}
+	}
+}
+
+// checkNode checks that a YAML value fits the type of the setting at key
+func checkNode(key string, t reflect.Type, v *yaml.Node) error {
+	section := t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+	switch v.Kind {
+	case yaml.MappingNode:
+		if !section {
+			return fmt.Errorf("%s: expected a value, not a section", key)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice {
+			return fmt.Errorf("%s: expected a single value, not a list", key)
+		}
+	case yaml.ScalarNode:
+		if v.Tag == "!!null" {
+			return nil
+		}
+		if section {
+			return fmt.Errorf("%s is a section; set its keys below it", key)
+		}
+		_, err := parseAs(key, t, v.Value)
+		return err
+	}
+	return nil
+}
+
+// validate checks the decoded configuration, wherever its values came from
+func (c *Config) validate() []Problem {
+	var problems []Problem
+	add := func(key string, err error) {
+		if err != nil {
+			problems = append(problems, Problem{Key: key, Message: err.Error()})
+		}
+	}
+
+	// Empty values are left to the defaults; in the config file they are
+	// caught by checkFile
+	enum := func(key, value string) {
+		if value != "" {
+			add(key, checkEnum(key, value))
+		}
+	}
+	policy := c.Scanning.Policy
+	enum("scanning.policy.malware", policy.Malware)
+	for _, severity := range slices.Sorted(maps.Keys(policy.CVE)) {
+		key := "scanning.policy.cve." + severity
+		if _, err := KeyType(key); err != nil {
+			add(key, err)
+			continue
+		}
+		enum(key, policy.CVE[severity])
+	}
+	enum("scanning.policy.license.action", policy.License.Action)
+	enum("scanning.policy.install_scripts", policy.InstallScripts)
+	enum("scanning.policy.provenance", policy.Provenance)
+	enum("scanning.policy.release_age", policy.ReleaseAge)
+	enum("scanning.policy.low_score", policy.LowScore)
+	enum("package_manager.preferred", c.PackageManager.Preferred)
+	enum("container.runtime", c.Container.Runtime)
+	enum("container.network", c.Container.Network)
+	enum("notifications.webhook.format", c.Notifications.Webhook.Format)
+	enum("ui.log_format", c.UI.LogFormat)
+
+	add("scanning.policy.allowlist", validateAllowlist(policy.Allowlist))
+	add("scanning.policy.blocklist", validatePackageEntries("blocklist", policy.Blocklist))
+	add("scanning.heuristics.severity", c.Scanning.Heuristics.Severity.validate())
+	add("scanning.deprecated.severity", validateSeverity("scanning.deprecated.severity", c.Scanning.Deprecated.Severity))
+	problems = append(problems, c.Scanning.validateOverrides()...)
+	problems = append(problems, c.Scanning.validateCustom()...)
+	add("container.limits.memory", checkValue("container.limits.memory", c.Container.Limits.Memory))
+	add("container.limits.cpus", checkValue("container.limits.cpus", c.Container.Limits.CPUs))
+	add("notifications.webhook.url", checkValue("notifications.webhook.url", c.Notifications.Webhook.URL))
+
+	if score := c.Scanning.Socket.MinScore; score < 0 || score > 100 {
+		add("scanning.socket.min_score", fmt.Errorf("scanning.socket.min_score: %d is outside 0-100", score))
+	}
+	if c.Scanning.Socket.BatchSize < 0 {
+		add("scanning.socket.batch_size", fmt.Errorf("scanning.socket.batch_size cannot be negative"))
+	}
+
+	if c.UI.Quiet && c.UI.Verbose {
+		add("ui.quiet", fmt.Errorf("ui.quiet and ui.verbose cannot both be set"))
+	}
+	return problems
+}
+
+// mergeProblems adds the problems found in the decoded config to those
+// found in the file, skipping keys the file check already reported and
+// pointing the rest at their line in the file
+func mergeProblems(fileProblems, problems []Problem, lines map[string]int) []Problem {
+	reported := make(map[string]bool)
+	for _, p := range fileProblems {
+		reported[p.Key] = true
+	}
+	for _, p := range problems {
+		if reported[p.Key] {
+			continue
+		}
+		p.Line = lines[p.Key]
+		fileProblems = append(fileProblems, p)
+	}
+	return fileProblems
+}
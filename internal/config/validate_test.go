@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckFile(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []Problem // keys and lines only
+	}{
+		{
+			name: "valid",
+			yaml: `scanning:
+  policy:
+    malware: block
+    cve:
+      high: warn
+    blocklist: [event-stream]
+container:
+  image:
+    npm: node:22-slim
+  limits:
+    memory: 2g
+`,
+		},
+		{
+			name: "misspelled section",
+			yaml: `scannning:
+  enabled: true
+`,
+			want: []Problem{{Key: "scannning", Line: 1}},
+		},
+		{
+			name: "bad values",
+			yaml: `scanning:
+  policy:
+    malware: blcok
+    cve:
+      severe: block
+  osv:
+    timeout: 5x
+container:
+  enabled: yes
+  network: bridge
+  limits:
+    memory: lots
+`,
+			want: []Problem{
+				{Key: "scanning.policy.malware", Line: 3},
+				{Key: "scanning.policy.cve.severe", Line: 5},
+				{Key: "scanning.osv.timeout", Line: 7},
+				{Key: "container.enabled", Line: 9},
+				{Key: "container.network", Line: 10},
+				{Key: "container.limits.memory", Line: 12},
+			},
+		},
+		{
+			name: "wrong shape",
+			yaml: `container: docker
+scanning:
+  enabled:
+    osv: true
+`,
+			want: []Problem{{Key: "container", Line: 1}, {Key: "scanning.enabled", Line: 3}},
+		},
+		{
+			name: "syntax error",
+			yaml: "scanning: [\n",
+			want: []Problem{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapem.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := checkFile(path)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkFile() = %+v, want %d problems", got, len(tt.want))
+			}
+			for i, p := range got {
+				if p.Key != tt.want[i].Key || p.Line != tt.want[i].Line {
+					t.Errorf("problem %d = %s line %d, want %s line %d", i, p.Key, p.Line, tt.want[i].Key, tt.want[i].Line)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "snapem.yaml")
+	content := `scanning:
+  policy:
+    malware: blcok
+    release_age: never
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("ui.quiet", true)
+	viper.Set("ui.verbose", true)
+
+	_, err := Load()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load() error = %v, want a *ValidationError", err)
+	}
+
+	want := []string{
+		path + ":3: scanning.policy.malware",
+		path + ":4: scanning.policy.release_age",
+		"ui.quiet and ui.verbose",
+	}
+	lines := invalid.Lines()
+	if len(lines) != len(want) {
+		t.Fatalf("Lines() = %q, want %d lines", lines, len(want))
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+}
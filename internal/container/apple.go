@@ -3,9 +3,11 @@ package container
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/positronico/snapem/internal/errors"
 	"golang.org/x/term"
@@ -38,29 +40,72 @@ func (r *AppleRuntime) IsAvailable() bool {
 	return r.binaryPath != ""
 }
 
+// Version returns the container CLI's version
+func (r *AppleRuntime) Version() (string, error) {
+	out, err := exec.Command(r.binaryPath, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ResolveDigest pulls the image and returns its index digest
+func (r *AppleRuntime) ResolveDigest(ctx context.Context, image string) (string, error) {
+	if out, err := exec.CommandContext(ctx, r.binaryPath, "image", "pull", image).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to pull %s: %s", image, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.CommandContext(ctx, r.binaryPath, "image", "inspect", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s: %w", image, err)
+	}
+	return parseAppleInspect(out)
+}
+
 // Run executes a command in an Apple container
 func (r *AppleRuntime) Run(ctx context.Context, opts *RunOptions) error {
 	if !r.IsAvailable() {
 		return errors.ContainerNotAvailableError()
 	}
 
-	// Check if stdin is a terminal - only use TTY flags if it is
+	// Check if stdin is a terminal - only use TTY flags if it is and output
+	// is not being captured
 	isTTY := term.IsTerminal(int(os.Stdin.Fd()))
-	if !isTTY {
+	if !isTTY || opts.Stdout != nil {
 		opts.Interactive = false
 		opts.TTY = false
 	}
 
+	// Name every container so one left behind can be found and stopped
+	if opts.Name == "" {
+		opts.Name = ForegroundName(opts)
+	}
+
 	args := r.buildArgs(opts)
-	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
+	cmd := exec.Command(r.binaryPath, args...)
 
-	// Connect stdio
+	// Connect stdio; a detached run only prints the container ID
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if opts.Stdout != nil {
+		cmd.Stdout = opts.Stdout
+	} else if opts.Detach {
+		cmd.Stdout = io.Discard
+	}
+	if opts.Stderr != nil {
+		cmd.Stderr = opts.Stderr
+	}
 
-	// Run the command
-	if err := cmd.Run(); err != nil {
+	// Run the command, stopping the container if snapem is interrupted
+	var err error
+	if opts.Detach {
+		err = cmd.Run()
+	} else {
+		err = runForeground(ctx, cmd, func() error {
+			return r.Stop(context.Background(), opts.Name, stopGrace)
+		})
+	}
+	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			// Return the exit code from the container
 			return &errors.SnapemError{
@@ -84,6 +129,11 @@ func (r *AppleRuntime) buildArgs(opts *RunOptions) []string {
 		args = append(args, "--rm")
 	}
 
+	// Run in the background
+	if opts.Detach {
+		args = append(args, "--detach")
+	}
+
 	// Interactive mode
 	if opts.Interactive {
 		args = append(args, "--interactive")
@@ -108,6 +158,11 @@ func (r *AppleRuntime) buildArgs(opts *RunOptions) []string {
 		args = append(args, "--volume", mount)
 	}
 
+	// In-memory mounts
+	for _, p := range opts.Tmpfs {
+		args = append(args, "--tmpfs", p)
+	}
+
 	// Working directory
 	if opts.WorkDir != "" {
 		args = append(args, "--workdir", opts.WorkDir)
@@ -127,9 +182,22 @@ func (r *AppleRuntime) buildArgs(opts *RunOptions) []string {
 	// NetworkHost is the default - containers have network access
 	}
 
-	// Environment variables
-	for k, v := range opts.Environment {
-		args = append(args, "--env", fmt.Sprintf("%s=%s", k, v))
+	// User
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+
+	// Resource limits; the Apple runtime assigns whole CPUs only
+	if opts.Memory != "" {
+		args = append(args, "--memory", opts.Memory)
+	}
+	if opts.CPUs != "" {
+		args = append(args, "--cpus", wholeCPUs(opts.CPUs))
+	}
+
+	// Environment variables, sorted for a stable command line
+	for _, k := range sortedKeys(opts.Environment) {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", k, opts.Environment[k]))
 	}
 
 	// Image
@@ -141,10 +209,86 @@ func (r *AppleRuntime) buildArgs(opts *RunOptions) []string {
 	return args
 }
 
+// ImageExists checks if the image is present locally
+func (r *AppleRuntime) ImageExists(ctx context.Context, image string) (bool, error) {
+	if !r.IsAvailable() {
+		return false, errors.ContainerNotAvailableError()
+	}
+
+	cmd := exec.CommandContext(ctx, r.binaryPath, "image", "inspect", image)
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, errors.ContainerError(err)
+	}
+	return true, nil
+}
+
+// Pull downloads the image, streaming progress to stderr
+func (r *AppleRuntime) Pull(ctx context.Context, image string) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	cmd := exec.CommandContext(ctx, r.binaryPath, "image", "pull", image)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(errors.ExitContainerError, "failed to pull "+image, err)
+	}
+	return nil
+}
+
+// List returns snapem's background containers
+func (r *AppleRuntime) List(ctx context.Context) ([]Container, error) {
+	if !r.IsAvailable() {
+		return nil, errors.ContainerNotAvailableError()
+	}
+
+	out, err := exec.CommandContext(ctx, r.binaryPath, "list", "--all", "--format", "json").Output()
+	if err != nil {
+		return nil, errors.ContainerError(err)
+	}
+	return parseAppleList(out)
+}
+
+// Logs streams a container's output to the terminal
+func (r *AppleRuntime) Logs(ctx context.Context, name string, follow bool) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "--follow")
+	}
+	return runStdio(ctx, r.binaryPath, append(args, name)...)
+}
+
+// Stop stops and deletes a container, sending SIGTERM and then SIGKILL
+// once grace has passed
+func (r *AppleRuntime) Stop(ctx context.Context, name string, grace time.Duration) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	return stopAndRemove(ctx, r.binaryPath, name,
+		[]string{"stop", "--signal", "SIGTERM", "--time", graceSeconds(grace), name},
+		[]string{"delete", "--force", name})
+}
+
+// Attach is not supported: the container CLI cannot reconnect a terminal
+// to a running container, so callers check CapAttach and follow the logs
+// instead
+func (r *AppleRuntime) Attach(ctx context.Context, name string) error {
+	return errors.New(errors.ExitContainerError, "Apple container cannot attach to a running container")
+}
+
 // CommandString returns the full command as a string for display
 func (r *AppleRuntime) CommandString(opts *RunOptions) string {
-	args := r.buildArgs(opts)
-	return containerBinary + " " + strings.Join(args, " ")
+	args := r.buildArgs(maskEnvironment(opts))
+	return containerBinary + " " + shellJoin(args)
 }
 
 // BuildNpmOptions creates RunOptions for npm commands
@@ -0,0 +1,186 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// NamePrefix starts the name of every container snapem runs in the
+// background, so List can tell them from the user's other containers
+const NamePrefix = "snapem-"
+
+// Container is a background container
+type Container struct {
+	Name    string
+	Image   string
+	Status  string
+	Running bool
+	Ports   []PortMapping
+}
+
+// DetachedName returns the name of the background container for a script
+// in a project: the project directory's name, the script and a short hash
+// of the project's path, so the same script in two checkouts doesn't clash
+func DetachedName(projectDir, script string) string {
+	sum := sha256.Sum256([]byte(projectDir))
+	return NamePrefix + nameSafe(filepath.Base(projectDir)) + "-" + nameSafe(script) + "-" + hex.EncodeToString(sum[:3])
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// nameSafe replaces the characters container names may not contain
+func nameSafe(s string) string {
+	s = strings.Trim(unsafeNameChars.ReplaceAllString(s, "-"), "-.")
+	if s == "" {
+		return "x"
+	}
+	return strings.ToLower(s)
+}
+
+// FormatPorts renders port mappings as host:container pairs
+func FormatPorts(ports []PortMapping) string {
+	pairs := make([]string, len(ports))
+	for i, p := range ports {
+		pairs[i] = p.HostPort + ":" + p.ContainerPort
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// dockerContainer is a line of docker ps --format '{{json .}}'
+type dockerContainer struct {
+	Names  string `json:"Names"`
+	Image  string `json:"Image"`
+	State  string `json:"State"`
+	Status string `json:"Status"`
+	Ports  string `json:"Ports"`
+}
+
+// dockerPort matches a published port in docker ps output, such as
+// 0.0.0.0:3000->3000/tcp
+var dockerPort = regexp.MustCompile(`:(\d+)->(\d+)/`)
+
+// parseDockerList reads docker ps output, one JSON object per line, keeping
+// snapem's containers
+func parseDockerList(out []byte) ([]Container, error) {
+	var containers []Container
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var c dockerContainer
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("failed to parse docker ps output: %w", err)
+		}
+		if !strings.HasPrefix(c.Names, NamePrefix) {
+			continue
+		}
+		var ports []PortMapping
+		seen := make(map[string]bool)
+		for _, m := range dockerPort.FindAllStringSubmatch(c.Ports, -1) {
+			// Docker lists a port once for IPv4 and once for IPv6
+			if key := m[1] + ":" + m[2]; !seen[key] {
+				seen[key] = true
+				ports = append(ports, PortMapping{HostPort: m[1], ContainerPort: m[2]})
+			}
+		}
+		containers = append(containers, Container{
+			Name:    c.Names,
+			Image:   c.Image,
+			Status:  c.Status,
+			Running: c.State == "running",
+			Ports:   ports,
+		})
+	}
+	return containers, nil
+}
+
+// appleContainer is an entry of container list --all --format json
+type appleContainer struct {
+	Status        string `json:"status"`
+	Configuration struct {
+		ID    string `json:"id"`
+		Image struct {
+			Reference string `json:"reference"`
+		} `json:"image"`
+		PublishedPorts []struct {
+			HostPort      int `json:"hostPort"`
+			ContainerPort int `json:"containerPort"`
+		} `json:"publishedPorts"`
+	} `json:"configuration"`
+}
+
+// parseAppleList reads container list output, keeping snapem's containers
+func parseAppleList(out []byte) ([]Container, error) {
+	var list []appleContainer
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse container list output: %w", err)
+	}
+	var containers []Container
+	for _, c := range list {
+		if !strings.HasPrefix(c.Configuration.ID, NamePrefix) {
+			continue
+		}
+		var ports []PortMapping
+		for _, p := range c.Configuration.PublishedPorts {
+			ports = append(ports, PortMapping{HostPort: fmt.Sprint(p.HostPort), ContainerPort: fmt.Sprint(p.ContainerPort)})
+		}
+		containers = append(containers, Container{
+			Name:    c.Configuration.ID,
+			Image:   c.Configuration.Image.Reference,
+			Status:  c.Status,
+			Running: c.Status == "running",
+			Ports:   ports,
+		})
+	}
+	return containers, nil
+}
+
+// graceSeconds rounds a grace period up to whole seconds, at least one
+func graceSeconds(d time.Duration) string {
+	return fmt.Sprint(max(1, int((d+time.Second-1)/time.Second)))
+}
+
+// runStdio runs a runtime command connected to the terminal. A non-zero
+// exit is returned with its exit code, as Run does.
+func runStdio(ctx context.Context, binary string, args ...string) error {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return &errors.SnapemError{
+				Code:    exitErr.ExitCode(),
+				Message: "container command failed",
+				Cause:   err,
+			}
+		}
+		return errors.ContainerError(err)
+	}
+	return nil
+}
+
+// stopAndRemove stops a container with stopArgs and then removes it with
+// removeArgs. Removal is tried even if stopping failed, since a container
+// that already exited cannot be stopped.
+func stopAndRemove(ctx context.Context, binary, name string, stopArgs, removeArgs []string) error {
+	stopOut, stopErr := exec.CommandContext(ctx, binary, stopArgs...).CombinedOutput()
+	if out, err := exec.CommandContext(ctx, binary, removeArgs...).CombinedOutput(); err != nil {
+		if stopErr != nil {
+			out = stopOut
+		}
+		return errors.Wrap(errors.ExitContainerError, fmt.Sprintf("failed to stop %s: %s", name, strings.TrimSpace(string(out))), err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package container
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetachedName(t *testing.T) {
+	a := DetachedName("/Users/dev/My App", "dev:web")
+	if !strings.HasPrefix(a, "snapem-my-app-dev-web-") || len(a) != len("snapem-my-app-dev-web-")+6 {
+		t.Errorf("DetachedName() = %q", a)
+	}
+	if b := DetachedName("/Users/dev/My App", "dev:web"); a != b {
+		t.Errorf("DetachedName() is not deterministic: %q, %q", a, b)
+	}
+	if b := DetachedName("/tmp/My App", "dev:web"); a == b {
+		t.Errorf("projects with the same name share %q", a)
+	}
+}
+
+func TestParseDockerList(t *testing.T) {
+	out := `{"Names":"snapem-web-dev-3fa9c1","Image":"node:lts-slim","State":"running","Status":"Up 5 minutes","Ports":"0.0.0.0:3000->3000/tcp, :::3000->3000/tcp, 0.0.0.0:9229->9229/tcp"}
+{"Names":"postgres","Image":"postgres:16","State":"running","Status":"Up 2 hours","Ports":"5432/tcp"}
+{"Names":"snapem-web-build-3fa9c1","Image":"node:lts-slim","State":"exited","Status":"Exited (0) 1 minute ago","Ports":""}
+`
+	got, err := parseDockerList([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d containers, want 2 (others are not snapem's): %+v", len(got), got)
+	}
+	if !got[0].Running || FormatPorts(got[0].Ports) != "3000:3000, 9229:9229" {
+		t.Errorf("first container = %+v", got[0])
+	}
+	if got[1].Running || got[1].Ports != nil {
+		t.Errorf("second container = %+v", got[1])
+	}
+}
+
+func TestParseAppleList(t *testing.T) {
+	out := `[
+  {"status":"running","configuration":{"id":"snapem-web-dev-3fa9c1","image":{"reference":"docker.io/library/node:lts-slim"},"publishedPorts":[{"hostAddress":"0.0.0.0","hostPort":5173,"containerPort":5173,"proto":"tcp"}]}},
+  {"status":"stopped","configuration":{"id":"buildkit","image":{"reference":"ghcr.io/apple/builder:0.1"}}}
+]`
+	got, err := parseAppleList([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Container{Name: "snapem-web-dev-3fa9c1", Image: "docker.io/library/node:lts-slim", Status: "running", Running: true, Ports: []PortMapping{{"5173", "5173"}}}
+	if len(got) != 1 || got[0].Name != want.Name || got[0].Image != want.Image || !got[0].Running || !slices.Equal(got[0].Ports, want.Ports) {
+		t.Errorf("parseAppleList() = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestDetachArgs(t *testing.T) {
+	opts := &RunOptions{Image: "node:lts-slim", Name: "snapem-web-dev-3fa9c1", Detach: true, Command: []string{"npm", "run", "dev"}}
+	if got := (&DockerRuntime{}).buildArgs(opts); !slices.Equal(got[:4], []string{"run", "-d", "--name", "snapem-web-dev-3fa9c1"}) {
+		t.Errorf("docker buildArgs() = %v", got)
+	}
+	if got := (&AppleRuntime{}).buildArgs(opts); !slices.Equal(got[:4], []string{"run", "--detach", "--name", "snapem-web-dev-3fa9c1"}) {
+		t.Errorf("apple buildArgs() = %v", got)
+	}
+}
+
+func TestGraceSeconds(t *testing.T) {
+	for d, want := range map[time.Duration]string{0: "1", 500 * time.Millisecond: "1", 10 * time.Second: "10", 2500 * time.Millisecond: "3"} {
+		if got := graceSeconds(d); got != want {
+			t.Errorf("graceSeconds(%s) = %s, want %s", d, got, want)
+		}
+	}
+}
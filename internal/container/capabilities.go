@@ -0,0 +1,72 @@
+package container
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Capability is an optional runtime feature that depends on the runtime
+// or its version
+type Capability string
+
+const (
+	// CapVolumeConsistency allows cached/delegated/consistent mount options
+	CapVolumeConsistency Capability = "volume-consistency"
+
+	// CapAttach allows reattaching the terminal to a background container
+	CapAttach Capability = "attach"
+)
+
+// CapabilityProvider is implemented by runtimes that can report optional
+// features. Runtimes that do not implement it support none of them.
+type CapabilityProvider interface {
+	Supports(c Capability) bool
+}
+
+// Supports returns true if the runtime reports the capability
+func Supports(r Runtime, c Capability) bool {
+	p, ok := r.(CapabilityProvider)
+	return ok && p.Supports(c)
+}
+
+// volumeOptionCapabilities maps each accepted volume option to the
+// capability a runtime needs to honor it
+var volumeOptionCapabilities = map[string]Capability{
+	"cached":     CapVolumeConsistency,
+	"delegated":  CapVolumeConsistency,
+	"consistent": CapVolumeConsistency,
+}
+
+// VolumeOptions lists the accepted container.volume_options values
+func VolumeOptions() []string {
+	opts := make([]string, 0, len(volumeOptionCapabilities))
+	for opt := range volumeOptionCapabilities {
+		opts = append(opts, opt)
+	}
+	slices.Sort(opts)
+	return opts
+}
+
+// ValidateVolumeOptions returns an error for option names snapem does not know
+func ValidateVolumeOptions(options []string) error {
+	for _, opt := range options {
+		if _, ok := volumeOptionCapabilities[opt]; !ok {
+			return fmt.Errorf("unknown volume option %q (expected %s)", opt, strings.Join(VolumeOptions(), ", "))
+		}
+	}
+	return nil
+}
+
+// FilterVolumeOptions splits options into those the runtime supports and
+// those it would reject
+func FilterVolumeOptions(r Runtime, options []string) (supported, dropped []string) {
+	for _, opt := range options {
+		if Supports(r, volumeOptionCapabilities[opt]) {
+			supported = append(supported, opt)
+		} else {
+			dropped = append(dropped, opt)
+		}
+	}
+	return supported, dropped
+}
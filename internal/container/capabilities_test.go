@@ -0,0 +1,50 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterVolumeOptions(t *testing.T) {
+	docker := &DockerRuntime{}
+	docker.versionOnce.Do(func() { docker.version = [2]int{24, 0} })
+
+	oldDocker := &DockerRuntime{}
+	oldDocker.versionOnce.Do(func() { oldDocker.version = [2]int{17, 3} })
+
+	tests := []struct {
+		name      string
+		runtime   Runtime
+		supported []string
+		dropped   []string
+	}{
+		{"docker", docker, []string{"cached", "delegated"}, nil},
+		{"old docker", oldDocker, nil, []string{"cached", "delegated"}},
+		{"apple", &AppleRuntime{}, nil, []string{"cached", "delegated"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supported, dropped := FilterVolumeOptions(tt.runtime, []string{"cached", "delegated"})
+			if !slices.Equal(supported, tt.supported) || !slices.Equal(dropped, tt.dropped) {
+				t.Errorf("FilterVolumeOptions() = %v, %v, want %v, %v", supported, dropped, tt.supported, tt.dropped)
+			}
+		})
+	}
+
+	if err := ValidateVolumeOptions([]string{"cached", "fast"}); err == nil {
+		t.Error("expected error for unknown option")
+	}
+}
+
+func TestDockerVolumeOptionArgs(t *testing.T) {
+	opts := &RunOptions{
+		Image:   "node:lts-slim",
+		Volumes: []VolumeMount{{HostPath: "/src", ContainerPath: "/app", ReadOnly: true, Options: []string{"cached"}}},
+	}
+
+	got := (&DockerRuntime{}).buildArgs(opts)
+	if !slices.Contains(got, "/src:/app:ro,cached") {
+		t.Errorf("buildArgs() = %v, want mount with ro,cached", got)
+	}
+}
@@ -0,0 +1,333 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/positronico/snapem/internal/errors"
+	"golang.org/x/term"
+)
+
+const (
+	dockerBinary = "docker"
+)
+
+// DockerRuntime implements the Runtime interface for the Docker CLI
+type DockerRuntime struct {
+	binaryPath string
+
+	versionOnce sync.Once
+	version     [2]int
+}
+
+// NewDockerRuntime creates a new Docker runtime
+func NewDockerRuntime() *DockerRuntime {
+	path, _ := exec.LookPath(dockerBinary)
+	return &DockerRuntime{
+		binaryPath: path,
+	}
+}
+
+// Name returns the runtime name
+func (r *DockerRuntime) Name() string {
+	return "Docker"
+}
+
+// IsAvailable checks if the docker CLI is installed
+func (r *DockerRuntime) IsAvailable() bool {
+	return r.binaryPath != ""
+}
+
+// Supports reports optional features for the installed Docker version
+func (r *DockerRuntime) Supports(c Capability) bool {
+	switch c {
+	case CapVolumeConsistency:
+		// Consistency options were added in Docker 17.04
+		major, minor := r.clientVersion()
+		return major > 17 || (major == 17 && minor >= 4)
+	case CapAttach:
+		return true
+	}
+	return false
+}
+
+// Version returns the docker CLI's version
+func (r *DockerRuntime) Version() (string, error) {
+	out, err := exec.Command(r.binaryPath, "version", "--format", "{{.Client.Version}}").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ResolveDigest pulls the image and returns the digest it was pulled by
+func (r *DockerRuntime) ResolveDigest(ctx context.Context, image string) (string, error) {
+	if out, err := exec.CommandContext(ctx, r.binaryPath, "pull", "--quiet", image).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to pull %s: %s", image, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.CommandContext(ctx, r.binaryPath, "image", "inspect", "--format", "{{json .RepoDigests}}", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s: %w", image, err)
+	}
+	return parseRepoDigests(out)
+}
+
+// clientVersion returns the docker CLI's major and minor version, or zeros
+// if it cannot be determined
+func (r *DockerRuntime) clientVersion() (int, int) {
+	r.versionOnce.Do(func() {
+		if !r.IsAvailable() {
+			return
+		}
+		version, err := r.Version()
+		if err != nil {
+			return
+		}
+		fmt.Sscanf(version, "%d.%d", &r.version[0], &r.version[1])
+	})
+	return r.version[0], r.version[1]
+}
+
+// Run executes a command in a Docker container
+func (r *DockerRuntime) Run(ctx context.Context, opts *RunOptions) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	// Check if stdin is a terminal - only use TTY flags if it is and output
+	// is not being captured
+	isTTY := term.IsTerminal(int(os.Stdin.Fd()))
+	if !isTTY || opts.Stdout != nil {
+		opts.Interactive = false
+		opts.TTY = false
+	}
+
+	// Name every container so one left behind can be found and stopped
+	if opts.Name == "" {
+		opts.Name = ForegroundName(opts)
+	}
+
+	args := r.buildArgs(opts)
+	cmd := exec.Command(r.binaryPath, args...)
+
+	// Connect stdio; a detached run only prints the container ID
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if opts.Stdout != nil {
+		cmd.Stdout = opts.Stdout
+	} else if opts.Detach {
+		cmd.Stdout = io.Discard
+	}
+	if opts.Stderr != nil {
+		cmd.Stderr = opts.Stderr
+	}
+
+	// Run the command, stopping the container if snapem is interrupted
+	var err error
+	if opts.Detach {
+		err = cmd.Run()
+	} else {
+		err = runForeground(ctx, cmd, func() error {
+			return r.Stop(context.Background(), opts.Name, stopGrace)
+		})
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// Return the exit code from the container
+			return &errors.SnapemError{
+				Code:    exitErr.ExitCode(),
+				Message: "container command failed",
+				Cause:   err,
+			}
+		}
+		return errors.ContainerError(err)
+	}
+
+	return nil
+}
+
+// buildArgs constructs the docker CLI arguments
+func (r *DockerRuntime) buildArgs(opts *RunOptions) []string {
+	args := []string{"run"}
+
+	// Remove container after exit
+	if opts.Remove {
+		args = append(args, "--rm")
+	}
+
+	// Run in the background
+	if opts.Detach {
+		args = append(args, "-d")
+	}
+
+	// Interactive mode and TTY allocation
+	switch {
+	case opts.Interactive && opts.TTY:
+		args = append(args, "-it")
+	case opts.Interactive:
+		args = append(args, "-i")
+	case opts.TTY:
+		args = append(args, "-t")
+	}
+
+	// Container name
+	if opts.Name != "" {
+		args = append(args, "--name", opts.Name)
+	}
+
+	// Volume mounts
+	for _, v := range opts.Volumes {
+		mount := fmt.Sprintf("%s:%s", v.HostPath, v.ContainerPath)
+		var mountOpts []string
+		if v.ReadOnly {
+			mountOpts = append(mountOpts, "ro")
+		}
+		mountOpts = append(mountOpts, v.Options...)
+		if len(mountOpts) > 0 {
+			mount += ":" + strings.Join(mountOpts, ",")
+		}
+		args = append(args, "-v", mount)
+	}
+
+	// In-memory mounts; --mount leaves them executable, unlike --tmpfs
+	for _, p := range opts.Tmpfs {
+		args = append(args, "--mount", "type=tmpfs,destination="+p)
+	}
+
+	// Working directory
+	if opts.WorkDir != "" {
+		args = append(args, "-w", opts.WorkDir)
+	}
+
+	// Port mappings (format: host-port:container-port)
+	for _, p := range opts.Ports {
+		args = append(args, "-p", fmt.Sprintf("%s:%s", p.HostPort, p.ContainerPort))
+	}
+
+	// Network mode
+	// NetworkHost means "has network access" rather than Docker's host
+	// networking, which ignores -p and is unavailable on Docker Desktop for
+	// macOS. The default bridge network matches the Apple runtime.
+	switch opts.Network {
+	case NetworkNone:
+		args = append(args, "--network", "none")
+	}
+
+	// User
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+
+	// Resource limits
+	if opts.Memory != "" {
+		args = append(args, "--memory", opts.Memory)
+	}
+	if opts.CPUs != "" {
+		args = append(args, "--cpus", opts.CPUs)
+	}
+
+	// Environment variables, sorted for a stable command line
+	for _, k := range sortedKeys(opts.Environment) {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, opts.Environment[k]))
+	}
+
+	// Image
+	args = append(args, opts.Image)
+
+	// Command
+	args = append(args, opts.Command...)
+
+	return args
+}
+
+// ImageExists checks if the image is present locally
+func (r *DockerRuntime) ImageExists(ctx context.Context, image string) (bool, error) {
+	if !r.IsAvailable() {
+		return false, errors.ContainerNotAvailableError()
+	}
+
+	cmd := exec.CommandContext(ctx, r.binaryPath, "image", "inspect", "--format", "{{.Id}}", image)
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, errors.ContainerError(err)
+	}
+	return true, nil
+}
+
+// Pull downloads the image, streaming progress to stderr
+func (r *DockerRuntime) Pull(ctx context.Context, image string) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	cmd := exec.CommandContext(ctx, r.binaryPath, "pull", image)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(errors.ExitContainerError, "failed to pull "+image, err)
+	}
+	return nil
+}
+
+// List returns snapem's background containers
+func (r *DockerRuntime) List(ctx context.Context) ([]Container, error) {
+	if !r.IsAvailable() {
+		return nil, errors.ContainerNotAvailableError()
+	}
+
+	out, err := exec.CommandContext(ctx, r.binaryPath, "ps", "--all", "--filter", "name=^"+NamePrefix, "--format", "{{json .}}").Output()
+	if err != nil {
+		return nil, errors.ContainerError(err)
+	}
+	return parseDockerList(out)
+}
+
+// Logs streams a container's output to the terminal
+func (r *DockerRuntime) Logs(ctx context.Context, name string, follow bool) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "--follow")
+	}
+	return runStdio(ctx, r.binaryPath, append(args, name)...)
+}
+
+// Stop stops and removes a container. docker stop sends SIGTERM and then
+// SIGKILL once --time seconds have passed.
+func (r *DockerRuntime) Stop(ctx context.Context, name string, grace time.Duration) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	return stopAndRemove(ctx, r.binaryPath, name,
+		[]string{"stop", "--time", graceSeconds(grace), name},
+		[]string{"rm", "--force", name})
+}
+
+// Attach connects the terminal to a running container; ctrl-p ctrl-q
+// detaches again
+func (r *DockerRuntime) Attach(ctx context.Context, name string) error {
+	if !r.IsAvailable() {
+		return errors.ContainerNotAvailableError()
+	}
+
+	return runStdio(ctx, r.binaryPath, "attach", name)
+}
+
+// CommandString returns the full command as a string for display
+func (r *DockerRuntime) CommandString(opts *RunOptions) string {
+	args := r.buildArgs(maskEnvironment(opts))
+	return dockerBinary + " " + shellJoin(args)
+}
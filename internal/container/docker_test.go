@@ -0,0 +1,47 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDockerBuildArgs(t *testing.T) {
+	opts := &RunOptions{
+		Image:       "node:lts-slim",
+		Command:     []string{"npm", "install"},
+		WorkDir:     "/app",
+		Network:     NetworkNone,
+		Interactive: true,
+		TTY:         true,
+		Remove:      true,
+		Volumes:     []VolumeMount{{HostPath: "/src/app", ContainerPath: "/app"}},
+		Ports:       []PortMapping{{HostPort: "8080", ContainerPort: "80"}},
+		Environment: map[string]string{"NODE_ENV": "production", "CI": "1", "GREETING": "hello world"},
+	}
+
+	want := []string{
+		"run", "--rm", "-it",
+		"-v", "/src/app:/app",
+		"-w", "/app",
+		"-p", "8080:80",
+		"--network", "none",
+		"-e", "CI=1", "-e", "GREETING=hello world", "-e", "NODE_ENV=production",
+		"node:lts-slim", "npm", "install",
+	}
+
+	got := (&DockerRuntime{}).buildArgs(opts)
+	if !slices.Equal(got, want) {
+		t.Errorf("buildArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestNewRuntime(t *testing.T) {
+	for _, name := range []string{"auto", "", "apple", "docker"} {
+		if _, err := NewRuntime(name); err != nil {
+			t.Errorf("NewRuntime(%q) returned error: %v", name, err)
+		}
+	}
+	if _, err := NewRuntime("podman"); err == nil {
+		t.Error("NewRuntime(\"podman\") should fail")
+	}
+}
@@ -0,0 +1,50 @@
+package container
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/positronico/snapem/internal/redact"
+)
+
+// HostEnvironment resolves container.environment entries: a bare NAME
+// passes the host's value through if it is set, and NAME=value sets a
+// literal value
+func HostEnvironment(entries []string, lookup func(string) (string, bool)) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range entries {
+		name, value, literal := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !literal {
+			var ok bool
+			if value, ok = lookup(name); !ok {
+				continue
+			}
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// maskEnvironment returns a copy of opts for display, with values of
+// secret-looking variables replaced by redact.Mask
+func maskEnvironment(opts *RunOptions) *RunOptions {
+	masked := *opts
+	masked.Environment = make(map[string]string, len(opts.Environment))
+	for k, v := range opts.Environment {
+		if redact.SecretName(k) || redact.String(v) != v {
+			v = redact.Mask
+		}
+		masked.Environment[k] = v
+	}
+	return &masked
+}
+
+// sortedKeys returns the variable names of env in order
+func sortedKeys(env map[string]string) []string {
+	return slices.Sorted(maps.Keys(env))
+}
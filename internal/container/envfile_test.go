@@ -0,0 +1,55 @@
+package container
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	input := `# Local overrides
+API_URL=http://localhost:4000
+export NODE_ENV=development
+GREETING="hello world" # comment
+MULTI="line one\nline \"two\""
+LITERAL='no $expansion \n here'
+QUERY=a=b&c=d
+HASH=abc#def
+TRAILING=value   # comment
+EMPTY=
+  SPACED = padded
+`
+	got, err := ParseEnvFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseEnvFile() error = %v", err)
+	}
+	want := map[string]string{
+		"API_URL":  "http://localhost:4000",
+		"NODE_ENV": "development",
+		"GREETING": "hello world",
+		"MULTI":    "line one\nline \"two\"",
+		"LITERAL":  `no $expansion \n here`,
+		"QUERY":    "a=b&c=d",
+		"HASH":     "abc#def",
+		"TRAILING": "value",
+		"EMPTY":    "",
+		"SPACED":   "padded",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("ParseEnvFile() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"NO_EQUALS", "1BAD=x", `OPEN="unterminated`, `JUNK='a' b`} {
+		if _, err := ParseEnvFile(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseEnvFile(%q) error = nil", bad)
+		}
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"run", "-e", "GREETING=hello world", "-e", "QUERY=a=b", "-e", "Q=it's", "-e", "EMPTY=", "", "node:lts-slim"})
+	want := `run -e 'GREETING=hello world' -e QUERY=a=b -e 'Q=it'\''s' -e EMPTY= '' node:lts-slim`
+	if got != want {
+		t.Errorf("shellJoin() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// stopGrace is how long an interrupted container gets to exit before it
+// is stopped
+var stopGrace = 5 * time.Second
+
+// ForegroundName returns the name for a container snapem waits on: the
+// project mounted at the working directory and snapem's process ID, so a
+// container left behind can be traced to its project and found by clean
+func ForegroundName(opts *RunOptions) string {
+	project := "run"
+	for _, v := range opts.Volumes {
+		if v.ContainerPath == opts.WorkDir {
+			project = filepath.Base(v.HostPath)
+		}
+	}
+	return fmt.Sprintf("%s%s-%d", NamePrefix, nameSafe(project), os.Getpid())
+}
+
+// runForeground runs a runtime CLI command that runs a container and
+// waits for it. On SIGINT or SIGTERM, or when ctx is done, the signal is
+// passed on to the CLI and the container gets stopGrace to exit; then
+// stop stops and removes it, since the CLI exiting does not always take
+// the container with it.
+func runForeground(ctx context.Context, cmd *exec.Cmd, stop func() error) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var sig os.Signal
+	select {
+	case err := <-done:
+		return err
+	case sig = <-signals:
+	case <-ctx.Done():
+		sig = syscall.SIGTERM
+	}
+
+	cmd.Process.Signal(sig)
+	var err error
+	exited := false
+	select {
+	case err = <-done:
+		exited = true
+	case <-time.After(stopGrace):
+	}
+	stop() // the container may already be gone
+
+	if !exited {
+		select {
+		case err = <-done:
+		case <-time.After(stopGrace):
+			cmd.Process.Kill()
+			err = <-done
+		}
+	}
+	if err == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunForeground(t *testing.T) {
+	defer func(grace time.Duration) { stopGrace = grace }(stopGrace)
+	stopGrace = 200 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		script string
+		cancel bool
+		code   int
+	}{
+		{"exits on its own", "exit 4", false, 4},
+		{"signal is passed on", `trap "exit 3" TERM; sleep 10 & wait`, true, 3},
+		{"killed when it ignores the signal", `trap "" TERM; sleep 10 & wait; sleep 10 & wait`, true, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				time.AfterFunc(100*time.Millisecond, cancel)
+			}
+			stopped := false
+			start := time.Now()
+			err := runForeground(ctx, exec.Command("sh", "-c", tt.script), func() error {
+				stopped = true
+				return nil
+			})
+
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != tt.code {
+				t.Errorf("runForeground() = %v, want exit code %d", err, tt.code)
+			}
+			if stopped != tt.cancel {
+				t.Errorf("stop called = %v, want %v", stopped, tt.cancel)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestForegroundName(t *testing.T) {
+	opts := &RunOptions{WorkDir: "/app", Volumes: []VolumeMount{
+		{HostPath: "/home/dev/.cache/npm", ContainerPath: "/snapem/cache"},
+		{HostPath: "/home/dev/My Site", ContainerPath: "/app"},
+	}}
+	if got := ForegroundName(opts); !strings.HasPrefix(got, NamePrefix+"my-site-") {
+		t.Errorf("ForegroundName() = %q", got)
+	}
+	if got := ForegroundName(&RunOptions{}); !strings.HasPrefix(got, NamePrefix+"run-") {
+		t.Errorf("ForegroundName() without a project = %q", got)
+	}
+}
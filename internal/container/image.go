@@ -0,0 +1,95 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// digestPattern matches the digest part of a digest-pinned image reference
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// IsDigestPinned returns true if the image reference names a digest, as in
+// node:lts-slim@sha256:<hex>
+func IsDigestPinned(image string) bool {
+	_, digest, ok := strings.Cut(image, "@")
+	return ok && digestPattern.MatchString(digest)
+}
+
+// ValidateImage returns an error for references with a malformed digest
+func ValidateImage(image string) error {
+	if strings.Contains(image, "@") && !IsDigestPinned(image) {
+		return fmt.Errorf("image %q has an invalid digest (expected @sha256: followed by 64 hex digits)", image)
+	}
+	return nil
+}
+
+// StripDigest returns the image reference without its digest
+func StripDigest(image string) string {
+	name, _, _ := strings.Cut(image, "@")
+	return name
+}
+
+// DigestResolver is implemented by runtimes that can look up the current
+// digest of an image tag
+type DigestResolver interface {
+	// ResolveDigest pulls the image and returns its digest (sha256:<hex>)
+	ResolveDigest(ctx context.Context, image string) (string, error)
+}
+
+// ResolveDigest returns the current digest of an image tag, or an error if
+// the runtime cannot resolve digests
+func ResolveDigest(ctx context.Context, r Runtime, image string) (string, error) {
+	resolver, ok := r.(DigestResolver)
+	if !ok {
+		return "", fmt.Errorf("%s cannot resolve image digests", r.Name())
+	}
+	digest, err := resolver.ResolveDigest(ctx, StripDigest(image))
+	if err != nil {
+		return "", err
+	}
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("%s reported an unexpected digest %q for %s", r.Name(), digest, image)
+	}
+	return digest, nil
+}
+
+// parseRepoDigests returns the digest from docker image inspect's
+// RepoDigests, e.g. ["node@sha256:<hex>"]
+func parseRepoDigests(out []byte) (string, error) {
+	var digests []string
+	if err := json.Unmarshal(out, &digests); err != nil {
+		return "", fmt.Errorf("failed to parse image digests: %w", err)
+	}
+	for _, ref := range digests {
+		if _, digest, ok := strings.Cut(ref, "@"); ok {
+			return digest, nil
+		}
+	}
+	return "", fmt.Errorf("image has no registry digest")
+}
+
+// parseAppleInspect returns the index digest from container image inspect
+func parseAppleInspect(out []byte) (string, error) {
+	type descriptor struct {
+		Digest string `json:"digest"`
+	}
+	var images []struct {
+		Index      *descriptor `json:"index"`
+		Descriptor *descriptor `json:"descriptor"`
+	}
+	if err := json.Unmarshal(out, &images); err != nil {
+		return "", fmt.Errorf("failed to parse image details: %w", err)
+	}
+	for _, image := range images {
+		switch {
+		case image.Index != nil && image.Index.Digest != "":
+			return image.Index.Digest, nil
+		case image.Descriptor != nil && image.Descriptor.Digest != "":
+			return image.Descriptor.Digest, nil
+		}
+	}
+	return "", fmt.Errorf("image has no registry digest")
+}
@@ -0,0 +1,50 @@
+package container
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestIsDigestPinned(t *testing.T) {
+	tests := []struct {
+		image   string
+		pinned  bool
+		invalid bool
+	}{
+		{"node:lts-slim", false, false},
+		{"node:lts-slim@" + testDigest, true, false},
+		{"ghcr.io/acme/node@" + testDigest, true, false},
+		{"localhost:5000/node:20", false, false},
+		{"node:lts-slim@sha256:abc", false, true},
+		{"node@" + strings.ToUpper(testDigest), false, true},
+	}
+	for _, tt := range tests {
+		if got := IsDigestPinned(tt.image); got != tt.pinned {
+			t.Errorf("IsDigestPinned(%q) = %v, want %v", tt.image, got, tt.pinned)
+		}
+		if err := ValidateImage(tt.image); (err != nil) != tt.invalid {
+			t.Errorf("ValidateImage(%q) error = %v, want invalid %v", tt.image, err, tt.invalid)
+		}
+	}
+
+	if got := StripDigest("node:lts-slim@" + testDigest); got != "node:lts-slim" {
+		t.Errorf("StripDigest() = %q, want node:lts-slim", got)
+	}
+}
+
+func TestParseDigests(t *testing.T) {
+	docker, err := parseRepoDigests([]byte(`["node@` + testDigest + `"]` + "\n"))
+	if err != nil || docker != testDigest {
+		t.Errorf("parseRepoDigests() = %q, %v", docker, err)
+	}
+	if _, err := parseRepoDigests([]byte(`[]`)); err == nil {
+		t.Error("parseRepoDigests() error = nil for a locally built image")
+	}
+
+	apple, err := parseAppleInspect([]byte(`[{"name": "docker.io/library/node:lts-slim", "index": {"digest": "` + testDigest + `", "size": 1609}}]`))
+	if err != nil || apple != testDigest {
+		t.Errorf("parseAppleInspect() = %q, %v", apple, err)
+	}
+}
@@ -0,0 +1,80 @@
+package container
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	imageCacheFile = "images.json"
+
+	// imageCacheTTL is how long an image seen locally is assumed to still be
+	// there. A stale entry only costs the runtime's own pull on run.
+	imageCacheTTL = 24 * time.Hour
+)
+
+// ImageCache remembers which images were present locally, so the check
+// before each run does not start the runtime's CLI every time
+type ImageCache struct {
+	path   string
+	images map[string]time.Time
+	now    func() time.Time
+}
+
+// LoadImageCache reads the image cache in dir. A missing or unreadable
+// cache is treated as empty; an empty dir disables persistence.
+func LoadImageCache(dir string) *ImageCache {
+	c := &ImageCache{
+		images: make(map[string]time.Time),
+		now:    time.Now,
+	}
+	if dir == "" {
+		return c
+	}
+	c.path = filepath.Join(dir, imageCacheFile)
+
+	if data, err := os.ReadFile(c.path); err == nil {
+		json.Unmarshal(data, &c.images)
+	}
+	return c
+}
+
+// Has reports whether the image was recently seen locally in the runtime
+func (c *ImageCache) Has(r Runtime, image string) bool {
+	seen, ok := c.images[imageCacheKey(r, image)]
+	return ok && c.now().Sub(seen) < imageCacheTTL
+}
+
+// Add records that the image is present locally in the runtime
+func (c *ImageCache) Add(r Runtime, image string) {
+	c.images[imageCacheKey(r, image)] = c.now().UTC()
+}
+
+// Save persists the cache, dropping expired entries
+func (c *ImageCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	for key, seen := range c.images {
+		if c.now().Sub(seen) >= imageCacheTTL {
+			delete(c.images, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(c.images, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// imageCacheKey scopes an image to a runtime; Docker and the Apple
+// runtime keep separate image stores
+func imageCacheKey(r Runtime, image string) string {
+	return r.Name() + " " + image
+}
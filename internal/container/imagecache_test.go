@@ -0,0 +1,33 @@
+package container
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImageCache(t *testing.T) {
+	dir := t.TempDir()
+	docker, apple := &DockerRuntime{}, &AppleRuntime{}
+
+	cache := LoadImageCache(dir)
+	if cache.Has(docker, "node:lts-slim") {
+		t.Fatal("Has() = true on an empty cache")
+	}
+	cache.Add(docker, "node:lts-slim")
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded := LoadImageCache(dir)
+	if !reloaded.Has(docker, "node:lts-slim") {
+		t.Error("Has() = false after reload")
+	}
+	if reloaded.Has(apple, "node:lts-slim") {
+		t.Error("Has() = true for a different runtime")
+	}
+
+	reloaded.now = func() time.Time { return time.Now().Add(imageCacheTTL) }
+	if reloaded.Has(docker, "node:lts-slim") {
+		t.Error("Has() = true for an expired entry")
+	}
+}
@@ -0,0 +1,42 @@
+package container
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// memoryPattern matches a memory size such as 512m or 2g
+var memoryPattern = regexp.MustCompile(`^[0-9]+[bkmgBKMG]?$`)
+
+// ValidateMemory returns an error unless memory is empty or a size in
+// bytes with an optional b, k, m or g suffix
+func ValidateMemory(memory string) error {
+	if memory != "" && !memoryPattern.MatchString(memory) {
+		return fmt.Errorf("invalid memory limit %q (expected a size such as 512m or 2g)", memory)
+	}
+	return nil
+}
+
+// ValidateCPUs returns an error unless cpus is empty or a positive number
+func ValidateCPUs(cpus string) error {
+	if cpus == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(cpus, 64)
+	if err != nil || !(n > 0) || math.IsInf(n, 0) {
+		return fmt.Errorf("invalid CPU limit %q (expected a positive number such as 2 or 1.5)", cpus)
+	}
+	return nil
+}
+
+// wholeCPUs rounds a validated CPU limit up to whole CPUs, for runtimes
+// that cannot assign fractions of one
+func wholeCPUs(cpus string) string {
+	n, err := strconv.ParseFloat(cpus, 64)
+	if err != nil {
+		return cpus
+	}
+	return strconv.Itoa(int(math.Ceil(n)))
+}
@@ -0,0 +1,44 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateLimits(t *testing.T) {
+	for _, memory := range []string{"", "512m", "2g", "2G", "1073741824"} {
+		if err := ValidateMemory(memory); err != nil {
+			t.Errorf("ValidateMemory(%q) error = %v", memory, err)
+		}
+	}
+	for _, memory := range []string{"2gb", "two", "-1g", "1.5g", "2 g"} {
+		if err := ValidateMemory(memory); err == nil {
+			t.Errorf("ValidateMemory(%q) error = nil", memory)
+		}
+	}
+
+	for _, cpus := range []string{"", "2", "1.5", "0.25"} {
+		if err := ValidateCPUs(cpus); err != nil {
+			t.Errorf("ValidateCPUs(%q) error = %v", cpus, err)
+		}
+	}
+	for _, cpus := range []string{"0", "-1", "two", "Inf", "NaN"} {
+		if err := ValidateCPUs(cpus); err == nil {
+			t.Errorf("ValidateCPUs(%q) error = nil", cpus)
+		}
+	}
+}
+
+func TestLimitArgs(t *testing.T) {
+	opts := &RunOptions{Image: "node:lts-slim", Memory: "2g", CPUs: "1.5"}
+
+	docker := (&DockerRuntime{}).buildArgs(opts)
+	if want := []string{"run", "--memory", "2g", "--cpus", "1.5", "node:lts-slim"}; !slices.Equal(docker, want) {
+		t.Errorf("Docker buildArgs() = %v, want %v", docker, want)
+	}
+
+	apple := (&AppleRuntime{}).buildArgs(opts)
+	if want := []string{"run", "--memory", "2g", "--cpus", "2", "node:lts-slim"}; !slices.Equal(apple, want) {
+		t.Errorf("Apple buildArgs() = %v, want %v", apple, want)
+	}
+}
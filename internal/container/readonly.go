@@ -0,0 +1,44 @@
+package container
+
+import (
+	"path"
+	"path/filepath"
+)
+
+// nodeModulesSeed is where the host's node_modules is mounted read-only
+// when the project source is read-only
+const nodeModulesSeed = "/snapem/node_modules"
+
+// seedScript copies the read-only node_modules into the writable one, then
+// runs the original command. Ownership is not preserved, so the copy also
+// works for a non-root container.user.
+const seedScript = `cp -RP ` + nodeModulesSeed + `/. "$SNAPEM_NODE_MODULES"/ && exec "$@"`
+
+// ReadOnlySource mounts the project at WorkDir read-only. node_modules and
+// /tmp become writable tmpfs mounts that are discarded with the container;
+// if hostNodeModules is set, its contents are copied in before the command
+// runs, so tools that write caches there still work.
+func (o *RunOptions) ReadOnlySource(hostNodeModules string) {
+	for i := range o.Volumes {
+		if o.Volumes[i].ContainerPath == o.WorkDir {
+			o.Volumes[i].ReadOnly = true
+		}
+	}
+
+	nodeModules := path.Join(o.WorkDir, "node_modules")
+	o.Tmpfs = append(o.Tmpfs, nodeModules, "/tmp")
+	if hostNodeModules == "" {
+		return
+	}
+
+	o.Volumes = append(o.Volumes, VolumeMount{
+		HostPath:      filepath.Clean(hostNodeModules),
+		ContainerPath: nodeModulesSeed,
+		ReadOnly:      true,
+	})
+	if o.Environment == nil {
+		o.Environment = make(map[string]string)
+	}
+	o.Environment["SNAPEM_NODE_MODULES"] = nodeModules
+	o.Command = append([]string{"sh", "-c", seedScript, "sh"}, o.Command...)
+}
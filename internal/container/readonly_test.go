@@ -0,0 +1,42 @@
+package container
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReadOnlySource(t *testing.T) {
+	opts := BuildNpmOptions("/src/app", "node:lts-slim", NetworkHost, "test")
+	opts.ReadOnlySource("/src/app/node_modules")
+
+	if !opts.Volumes[0].ReadOnly {
+		t.Error("project mount is writable")
+	}
+	seed := VolumeMount{HostPath: "/src/app/node_modules", ContainerPath: nodeModulesSeed, ReadOnly: true}
+	if len(opts.Volumes) != 2 || opts.Volumes[1].HostPath != seed.HostPath || opts.Volumes[1].ContainerPath != seed.ContainerPath || !opts.Volumes[1].ReadOnly {
+		t.Errorf("Volumes = %+v, want the project and a read-only node_modules seed", opts.Volumes)
+	}
+	if want := []string{"/app/node_modules", "/tmp"}; !slices.Equal(opts.Tmpfs, want) {
+		t.Errorf("Tmpfs = %v, want %v", opts.Tmpfs, want)
+	}
+	if want := []string{"sh", "-c", seedScript, "sh", "npm", "test"}; !slices.Equal(opts.Command, want) {
+		t.Errorf("Command = %q, want %q", opts.Command, want)
+	}
+	if opts.Environment["SNAPEM_NODE_MODULES"] != "/app/node_modules" {
+		t.Errorf("SNAPEM_NODE_MODULES = %q", opts.Environment["SNAPEM_NODE_MODULES"])
+	}
+
+	args := (&DockerRuntime{}).buildArgs(opts)
+	for _, want := range []string{"/src/app:/app:ro", "type=tmpfs,destination=/app/node_modules"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("Docker buildArgs() = %v, missing %q", args, want)
+		}
+	}
+
+	// Without a node_modules to seed, the command runs unchanged
+	bare := BuildNpmOptions("/src/app", "node:lts-slim", NetworkHost, "test")
+	bare.ReadOnlySource("")
+	if len(bare.Volumes) != 1 || !slices.Equal(bare.Command, []string{"npm", "test"}) {
+		t.Errorf("ReadOnlySource(\"\") = volumes %+v, command %q", bare.Volumes, bare.Command)
+	}
+}
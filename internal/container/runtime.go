@@ -2,6 +2,10 @@ package container
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 )
 
 // Runtime defines the interface for container execution
@@ -14,6 +18,50 @@ type Runtime interface {
 
 	// Name returns the runtime name
 	Name() string
+
+	// CommandString returns the full command as a string for display
+	CommandString(opts *RunOptions) string
+
+	// ImageExists checks if the image is present locally
+	ImageExists(ctx context.Context, image string) (bool, error)
+
+	// Pull downloads the image, streaming the runtime's progress output
+	Pull(ctx context.Context, image string) error
+
+	// List returns the containers snapem started in the background,
+	// running or stopped
+	List(ctx context.Context) ([]Container, error)
+
+	// Logs streams a container's output; follow keeps streaming until the
+	// container stops or ctx is cancelled
+	Logs(ctx context.Context, name string, follow bool) error
+
+	// Stop sends SIGTERM, then SIGKILL once grace has passed, and removes
+	// the container
+	Stop(ctx context.Context, name string, grace time.Duration) error
+
+	// Attach connects the terminal to a running container
+	Attach(ctx context.Context, name string) error
+}
+
+// VersionReporter is implemented by runtimes that can report the version of
+// their CLI
+type VersionReporter interface {
+	Version() (string, error)
+}
+
+// Version returns the runtime's version string, or "unknown" if the runtime
+// cannot report it
+func Version(r Runtime) string {
+	v, ok := r.(VersionReporter)
+	if !ok || !r.IsAvailable() {
+		return "unknown"
+	}
+	version, err := v.Version()
+	if err != nil || version == "" {
+		return "unknown"
+	}
+	return version
 }
 
 // RunOptions configures container execution
@@ -30,6 +78,10 @@ type RunOptions struct {
 	// Volumes are the volume mounts
 	Volumes []VolumeMount
 
+	// Tmpfs are container paths that get an empty, writable in-memory
+	// mount discarded with the container
+	Tmpfs []string
+
 	// Ports are the port mappings (host:container)
 	Ports []PortMapping
 
@@ -39,6 +91,16 @@ type RunOptions struct {
 	// Environment variables to pass to container
 	Environment map[string]string
 
+	// Memory limits the container's memory, e.g. "2g"; empty means no limit
+	Memory string
+
+	// CPUs limits the number of CPUs, e.g. "2" or "1.5"; empty means no limit
+	CPUs string
+
+	// User is the user to run as, e.g. "501:20" or "node"; empty means the
+	// image's default user
+	User string
+
 	// Interactive enables stdin
 	Interactive bool
 
@@ -50,6 +112,18 @@ type RunOptions struct {
 
 	// Name is an optional container name
 	Name string
+
+	// Detach starts the container in the background and returns once it
+	// is running
+	Detach bool
+
+	// Stdout, when set, receives the command's standard output instead of
+	// the terminal. No TTY is allocated, so output stays machine-readable.
+	Stdout io.Writer
+
+	// Stderr, when set, receives the command's standard error instead of
+	// the terminal
+	Stderr io.Writer
 }
 
 // PortMapping represents a port mapping from host to container
@@ -63,6 +137,10 @@ type VolumeMount struct {
 	HostPath      string
 	ContainerPath string
 	ReadOnly      bool
+
+	// Options are extra mount options such as "cached"; only options the
+	// runtime supports should be set (see FilterVolumeOptions)
+	Options []string
 }
 
 // NetworkMode defines network configuration
@@ -91,3 +169,52 @@ func DefaultRunOptions() *RunOptions {
 		Environment: make(map[string]string),
 	}
 }
+
+// Runtimes lists the accepted container.runtime values
+var Runtimes = []string{"auto", "apple", "docker"}
+
+// NewRuntime returns the runtime selected by name. "auto" (or empty) prefers
+// the Apple container CLI and falls back to Docker; if neither is installed
+// the Apple runtime is returned so the caller reports it as unavailable.
+func NewRuntime(name string) (Runtime, error) {
+	switch name {
+	case "apple":
+		return NewAppleRuntime(), nil
+	case "docker":
+		return NewDockerRuntime(), nil
+	case "auto", "":
+		apple := NewAppleRuntime()
+		if apple.IsAvailable() {
+			return apple, nil
+		}
+		if docker := NewDockerRuntime(); docker.IsAvailable() {
+			return docker, nil
+		}
+		return apple, nil
+	}
+	return nil, fmt.Errorf("unknown container runtime %q (expected %s)", name, strings.Join(Runtimes, ", "))
+}
+
+// shellJoin joins arguments for display, single-quoting those the shell
+// would split or interpret, so the shown command can be pasted as is
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes an argument if it contains characters outside a safe set
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,*", r))
+	}) < 0
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,44 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UserHost is the container.user value that runs as the host user
+const UserHost = "host"
+
+// containerHome is HOME for numeric users, who have no home directory in
+// the image; npm and yarn keep their caches under it
+const containerHome = "/tmp"
+
+// ResolveUser turns a container.user value into the user to run as: "host"
+// becomes the current uid:gid and anything else is passed through. It
+// returns "" when the host user cannot be determined.
+func ResolveUser(spec string) string {
+	if spec != UserHost {
+		return spec
+	}
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid < 0 || gid < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", uid, gid)
+}
+
+// SetUser runs the container as user. A numeric non-root user gets a
+// writable HOME so npm does not try to write its cache to /root.
+func (o *RunOptions) SetUser(user string) {
+	o.User = user
+	uid, _, _ := strings.Cut(user, ":")
+	if uid == "" || uid == "0" || strings.TrimLeft(uid, "0123456789") != "" {
+		return
+	}
+	if o.Environment == nil {
+		o.Environment = make(map[string]string)
+	}
+	if _, ok := o.Environment["HOME"]; !ok {
+		o.Environment["HOME"] = containerHome
+	}
+}
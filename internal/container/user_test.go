@@ -0,0 +1,41 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestResolveUser(t *testing.T) {
+	if got, want := ResolveUser("host"), fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()); got != want {
+		t.Errorf("ResolveUser(host) = %q, want %q", got, want)
+	}
+	if got := ResolveUser("node"); got != "node" {
+		t.Errorf("ResolveUser(node) = %q, want node", got)
+	}
+}
+
+func TestSetUser(t *testing.T) {
+	tests := []struct {
+		user string
+		home string
+	}{
+		{"501:20", containerHome},
+		{"1000", containerHome},
+		{"node", ""},
+		{"0:0", ""},
+	}
+	for _, tt := range tests {
+		opts := DefaultRunOptions()
+		opts.SetUser(tt.user)
+		if opts.User != tt.user || opts.Environment["HOME"] != tt.home {
+			t.Errorf("SetUser(%q): User = %q, HOME = %q, want HOME %q", tt.user, opts.User, opts.Environment["HOME"], tt.home)
+		}
+	}
+
+	opts := &RunOptions{Environment: map[string]string{"HOME": "/work"}}
+	opts.SetUser("501:20")
+	if opts.Environment["HOME"] != "/work" {
+		t.Errorf("SetUser() replaced a configured HOME with %q", opts.Environment["HOME"])
+	}
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 )
 
@@ -41,6 +42,16 @@ func (e *SnapemError) ExitCode() int {
 	return e.Code
 }
 
+// ExitCodeFor returns the process exit code for an error returned by a
+// command, falling back to ExitGeneralError for non-snapem errors
+func ExitCodeFor(err error) int {
+	var se *SnapemError
+	if stderrors.As(err, &se) && se.Code != ExitSuccess {
+		return se.Code
+	}
+	return ExitGeneralError
+}
+
 // New creates a new SnapemError
 func New(code int, message string) *SnapemError {
 	return &SnapemError{
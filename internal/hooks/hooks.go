@@ -0,0 +1,156 @@
+// Package hooks runs user-defined shell commands when snapem events occur.
+// Each command receives a JSON payload on stdin. Hooks are best effort:
+// failures and timeouts are logged and never change snapem's exit code.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+// Event names a point in snapem's lifecycle that can trigger a hook
+type Event string
+
+const (
+	// ScanComplete fires after every security scan
+	ScanComplete Event = "scan_complete"
+
+	// InstallBlocked fires when policy stops an install
+	InstallBlocked Event = "install_blocked"
+
+	// OverrideUsed fires when an install proceeds despite blocking findings
+	OverrideUsed Event = "override_used"
+
+	// InstallComplete fires after a successful install
+	InstallComplete Event = "install_complete"
+)
+
+// Events lists the supported hook events
+var Events = []Event{ScanComplete, InstallBlocked, OverrideUsed, InstallComplete}
+
+// defaultTimeout bounds a hook when hooks.timeout is not set
+const defaultTimeout = 10 * time.Second
+
+// Payload is the JSON document written to a hook's stdin
+type Payload struct {
+	SchemaVersion int       `json:"schema_version"`
+	Event         Event     `json:"event"`
+	Timestamp     time.Time `json:"timestamp"`
+	ProjectDir    string    `json:"project_dir"`
+	Data          any       `json:"data"`
+}
+
+// InstallData is the payload data for install_blocked, override_used and
+// install_complete
+type InstallData struct {
+	Packages       []string         `json:"packages"`
+	PackageManager string           `json:"package_manager"`
+	Reason         string           `json:"reason,omitempty"`
+	Scan           *report.Document `json:"scan,omitempty"`
+}
+
+// Runner executes configured hooks
+type Runner struct {
+	commands map[Event]string
+	timeout  time.Duration
+	log      func(string)
+}
+
+// NewRunner creates a runner for the configured hooks. log receives hook
+// output and failures; it is typically the verbose logger.
+func NewRunner(cfg config.HooksConfig, log func(string)) *Runner {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Runner{
+		commands: map[Event]string{
+			ScanComplete:    cfg.ScanComplete,
+			InstallBlocked:  cfg.InstallBlocked,
+			OverrideUsed:    cfg.OverrideUsed,
+			InstallComplete: cfg.InstallComplete,
+		},
+		timeout: timeout,
+		log:     log,
+	}
+}
+
+// Parse validates an event name
+func Parse(name string) (Event, error) {
+	for _, e := range Events {
+		if Event(name) == e {
+			return e, nil
+		}
+	}
+	names := make([]string, len(Events))
+	for i, e := range Events {
+		names[i] = string(e)
+	}
+	return "", fmt.Errorf("unknown hook event %q (expected %s)", name, strings.Join(names, ", "))
+}
+
+// Configured returns true if a command is set for the event
+func (r *Runner) Configured(event Event) bool {
+	return r != nil && r.commands[event] != ""
+}
+
+// Fire runs the hook for event, if any, and waits for it to finish or time
+// out. The returned error is for diagnostics only; callers in the install
+// and scan paths ignore it.
+func (r *Runner) Fire(ctx context.Context, event Event, projectDir string, data any) error {
+	if !r.Configured(event) {
+		return nil
+	}
+
+	payload, err := json.Marshal(Payload{
+		SchemaVersion: report.SchemaVersion,
+		Event:         event,
+		Timestamp:     time.Now().UTC(),
+		ProjectDir:    projectDir,
+		Data:          data,
+	})
+	if err != nil {
+		return r.fail(event, fmt.Errorf("failed to encode payload: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.commands[event])
+	cmd.Dir = projectDir
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	for _, line := range strings.Split(strings.TrimRight(output.String(), "\n"), "\n") {
+		if line != "" {
+			r.log(fmt.Sprintf("hook %s: %s", event, line))
+		}
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return r.fail(event, fmt.Errorf("timed out after %s", r.timeout))
+	}
+	if err != nil {
+		return r.fail(event, err)
+	}
+	return nil
+}
+
+// fail logs a hook failure and returns it
+func (r *Runner) fail(event Event, err error) error {
+	err = fmt.Errorf("hook %s failed: %w", event, err)
+	r.log(err.Error())
+	return err
+}
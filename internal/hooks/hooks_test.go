@@ -0,0 +1,94 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+)
+
+func TestFireWritesPayloadToStdin(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "payload.json")
+
+	var logged []string
+	runner := NewRunner(config.HooksConfig{ScanComplete: "cat > " + out + "; echo done"}, func(s string) {
+		logged = append(logged, s)
+	})
+
+	if err := runner.Fire(context.Background(), ScanComplete, dir, Synthetic(ScanComplete)); err != nil {
+		t.Fatalf("Fire returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		SchemaVersion int    `json:"schema_version"`
+		Event         string `json:"event"`
+		ProjectDir    string `json:"project_dir"`
+		Data          struct {
+			SchemaVersion int `json:"schema_version"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload.Event != "scan_complete" || payload.ProjectDir != dir || payload.SchemaVersion == 0 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+	if payload.Data.SchemaVersion != payload.SchemaVersion {
+		t.Errorf("scan document schema_version %d does not match payload %d", payload.Data.SchemaVersion, payload.SchemaVersion)
+	}
+
+	if len(logged) != 1 || logged[0] != "hook scan_complete: done" {
+		t.Errorf("logged = %q, want hook output", logged)
+	}
+}
+
+func TestFireFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"non-zero exit", "exit 3", "exit status 3"},
+		{"timeout", "sleep 5", "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(config.HooksConfig{
+				Timeout:        100 * time.Millisecond,
+				InstallBlocked: tt.command,
+			}, func(string) {})
+
+			start := time.Now()
+			err := runner.Fire(context.Background(), InstallBlocked, t.TempDir(), Synthetic(InstallBlocked))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Fire() error = %v, want %q", err, tt.want)
+			}
+			if time.Since(start) > 3*time.Second {
+				t.Errorf("hook was not bounded by its timeout")
+			}
+		})
+	}
+}
+
+func TestUnconfiguredEventIsNoop(t *testing.T) {
+	var runner *Runner
+	if err := runner.Fire(context.Background(), InstallComplete, t.TempDir(), nil); err != nil {
+		t.Errorf("nil runner returned error: %v", err)
+	}
+
+	runner = NewRunner(config.HooksConfig{}, func(string) { t.Error("unexpected log") })
+	if err := runner.Fire(context.Background(), InstallComplete, t.TempDir(), nil); err != nil {
+		t.Errorf("unconfigured event returned error: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package hooks
+
+import (
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+// Synthetic returns sample payload data for an event, used by
+// `snapem hooks test` so hook scripts can be developed without a real scan
+func Synthetic(event Event) any {
+	result := &types.AggregatedResult{
+		TotalPackages: 1,
+		TotalFindings: 1,
+		HasCritical:   true,
+		Results: []*types.ScanResult{
+			{
+				Scanner:  "Synthetic",
+				Packages: 1,
+				Findings: []types.Finding{
+					{
+						Package:  "snapem-hook-test",
+						Version:  "0.0.0",
+						Type:     types.FindingTypeCVE,
+						Severity: types.SeverityCritical,
+						Title:    "Synthetic finding for hook testing",
+						ID:       "SNAPEM-HOOK-TEST",
+					},
+				},
+			},
+		},
+	}
+	scan := report.NewDocument(result)
+
+	switch event {
+	case ScanComplete:
+		return scan
+	case InstallComplete:
+		return InstallData{Packages: []string{"snapem-hook-test@0.0.0"}, PackageManager: "npm", Scan: scan}
+	default:
+		return InstallData{
+			Packages:       []string{"snapem-hook-test@0.0.0"},
+			PackageManager: "npm",
+			Reason:         "critical vulnerabilities detected",
+			Scan:           scan,
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package httpclient builds the HTTP clients snapem talks to registries and
+// advisory APIs with, honoring the configured proxy and CA bundle.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var (
+	// proxy is scanning.proxy; nil uses HTTP_PROXY and HTTPS_PROXY
+	proxy *url.URL
+
+	// rootCAs adds scanning.ca_bundle to the system roots; nil uses the
+	// system roots alone
+	rootCAs *x509.CertPool
+)
+
+// Configure sets the proxy and extra CA certificates for clients created
+// afterwards. An empty proxyURL falls back to HTTP_PROXY and HTTPS_PROXY;
+// NO_PROXY applies either way. caBundle is a PEM file added to the system
+// roots, for proxies that intercept TLS.
+func Configure(proxyURL, caBundle string) error {
+	proxy, rootCAs = nil, nil
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q", proxyURL)
+		}
+		proxy = u
+	}
+
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return fmt.Errorf("CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA bundle: no PEM certificates in %s", caBundle)
+		}
+		rootCAs = pool
+	}
+	return nil
+}
+
+// New returns a client that retries failed requests up to three times
+func New() *http.Client {
+	retryClient := retryablehttp.NewClient()
+	retryClient.RetryMax = 3
+	retryClient.Logger = nil // Disable logging
+	retryClient.HTTPClient.Transport = transport()
+	return retryClient.StandardClient()
+}
+
+// NewNoRetry returns a client that fails on the first error, for requests
+// that have a fallback such as a cached copy
+func NewNoRetry(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: transport(), Timeout: timeout}
+}
+
+// transport returns a pooled transport using the configured proxy and CAs
+func transport() *http.Transport {
+	t := cleanhttp.DefaultPooledTransport()
+	t.Proxy = proxyFunc
+	if rootCAs != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+	}
+	return t
+}
+
+// proxyFunc picks the proxy for a request
+func proxyFunc(req *http.Request) (*url.URL, error) {
+	if proxy == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+	if bypassProxy(req.URL.Hostname(), noProxy()) {
+		return nil, nil
+	}
+	return proxy, nil
+}
+
+// ProxyFor describes how requests to rawURL leave the machine: the proxy's
+// address, or "direct"
+func ProxyFor(rawURL string) string {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return "direct"
+	}
+	u, err := proxyFunc(req)
+	if err != nil || u == nil {
+		return "direct"
+	}
+	return u.Redacted()
+}
+
+// Reachable makes a single request to rawURL. Any HTTP response, even an
+// error status, means the endpoint can be reached.
+func Reachable(ctx context.Context, rawURL string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: transport()}).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// noProxy returns NO_PROXY or no_proxy
+func noProxy() string {
+	if v := os.Getenv("NO_PROXY"); v != "" {
+		return v
+	}
+	return os.Getenv("no_proxy")
+}
+
+// bypassProxy returns true if host matches a NO_PROXY entry: *, a host
+// name, a domain suffix such as .corp.example, an IP or a CIDR range
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
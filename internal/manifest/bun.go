@@ -0,0 +1,265 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// BunLock represents a parsed text bun.lock (JSONC, bun 1.1.39+)
+type BunLock struct {
+	LockfileVersion int                     `json:"lockfileVersion"`
+	Workspaces      map[string]bunWorkspace `json:"workspaces"`
+
+	// Packages maps an install path ("react", "parent/dep") to the
+	// [name@version, registry, info, integrity] tuple bun writes
+	Packages map[string][]json.RawMessage `json:"packages"`
+}
+
+// bunWorkspace lists the direct dependencies of a workspace package
+type bunWorkspace struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// bunPackageInfo is the metadata object in a packages tuple
+type bunPackageInfo struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// HasBunTextLockfile returns true if a text bun.lock exists
+func (p *Parser) HasBunTextLockfile() bool {
+	_, err := os.Stat(filepath.Join(p.projectDir, "bun.lock"))
+	return err == nil
+}
+
+// ParseBunLockfile reads and parses bun.lock
+func (p *Parser) ParseBunLockfile() (*BunLock, error) {
+	data, err := os.ReadFile(filepath.Join(p.projectDir, "bun.lock"))
+	if err != nil {
+		return nil, errors.ManifestError("failed to read bun.lock", err)
+	}
+
+	var lockfile BunLock
+	if err := json.Unmarshal(stripJSONC(data), &lockfile); err != nil {
+		return nil, errors.ManifestError("failed to parse bun.lock", err)
+	}
+
+	return &lockfile, nil
+}
+
+// ResolvedPackages returns the registry packages of a class in the
+// lockfile with their exact version. bun.lock has no dev, optional or
+// peer flags, so packages are classified by walking the graph from the
+// workspaces' direct dependencies.
+func (l *BunLock) ResolvedPackages(class DependencyClass) []Package {
+	var classes map[string]lockedClass
+	if class != DependenciesAll {
+		classes = l.graph().classify()
+	}
+
+	seen := make(map[string]bool)
+	var packages []Package
+	for key := range l.Packages {
+		if classes != nil && !class.includes(classOf(classes, key)) {
+			continue
+		}
+		name, version := l.resolved(key)
+		if name == "" || version == "" {
+			continue
+		}
+		id := name + "@" + version
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		packages = append(packages, Package{
+			Name:      name,
+			Version:   version,
+			Ecosystem: "npm",
+		})
+	}
+
+	return packages
+}
+
+// resolved returns the name and exact version of a packages entry, skipping
+// workspace, link, file and git sources
+func (l *BunLock) resolved(key string) (name, version string) {
+	entry := l.Packages[key]
+	if len(entry) == 0 {
+		return "", ""
+	}
+
+	var ident string
+	if err := json.Unmarshal(entry[0], &ident); err != nil {
+		return "", ""
+	}
+
+	// The last @ separates the version; index 0 would be a bare scope
+	idx := strings.LastIndex(ident, "@")
+	if idx <= 0 {
+		return "", ""
+	}
+	name, version = ident[:idx], ident[idx+1:]
+	if strings.Contains(version, ":") || strings.Contains(version, "/") {
+		return "", ""
+	}
+
+	return name, version
+}
+
+// info decodes the metadata object of a packages entry
+func (l *BunLock) info(key string) bunPackageInfo {
+	var info bunPackageInfo
+	entry := l.Packages[key]
+	for _, raw := range entry[min(1, len(entry)):] {
+		// The metadata is the only object in the tuple
+		if len(raw) > 0 && raw[0] == '{' {
+			_ = json.Unmarshal(raw, &info)
+			break
+		}
+	}
+	return info
+}
+
+// lookup finds the packages key a dependency of parent resolves to. Bun
+// nests conflicting versions under their dependent ("parent/dep"), so the
+// most specific path wins.
+func (l *BunLock) lookup(parent, dep string) (string, bool) {
+	for p := parent; p != ""; {
+		if _, ok := l.Packages[p+"/"+dep]; ok {
+			return p + "/" + dep, true
+		}
+		idx := strings.LastIndex(p, "/")
+		if idx == -1 {
+			break
+		}
+		p = p[:idx]
+	}
+	_, ok := l.Packages[dep]
+	return dep, ok
+}
+
+// graph returns the package graph, rooted at every workspace's direct
+// dependencies
+func (l *BunLock) graph() lockGraph[string] {
+	var g lockGraph[string]
+	roots := func(deps map[string]string) []string {
+		var out []string
+		for name := range deps {
+			if _, ok := l.Packages[name]; ok {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+	for _, ws := range l.Workspaces {
+		g.prod = append(g.prod, roots(ws.Dependencies)...)
+		g.dev = append(g.dev, roots(ws.DevDependencies)...)
+		g.optional = append(g.optional, roots(ws.OptionalDependencies)...)
+		g.peer = append(g.peer, roots(ws.PeerDependencies)...)
+	}
+
+	g.edges = func(key string) (deps, optional, peer []string) {
+		resolve := func(names map[string]string) []string {
+			var out []string
+			for name := range names {
+				if dep, ok := l.lookup(key, name); ok {
+					out = append(out, dep)
+				}
+			}
+			return out
+		}
+		info := l.info(key)
+		return resolve(info.Dependencies), resolve(info.OptionalDependencies), resolve(info.PeerDependencies)
+	}
+	return g
+}
+
+// stripJSONC removes comments and trailing commas so JSONC decodes as JSON
+func stripJSONC(data []byte) []byte {
+	return stripTrailingCommas(stripComments(data))
+}
+
+// stripComments removes // and /* */ comments outside of strings
+func stripComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end == -1 {
+				return out
+			}
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// stripTrailingCommas drops commas directly before a closing } or ]
+func stripTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		} else if c == ',' {
+			next := bytes.TrimLeft(data[i+1:], " \t\r\n")
+			if len(next) > 0 && (next[0] == '}' || next[0] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+
+	return out
+}
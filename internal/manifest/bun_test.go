@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const bunLockText = `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "app",
+      "dependencies": {
+        "react-dom": "^18.2.0",
+        "my-lib": "workspace:*",
+      },
+      "devDependencies": {
+        "@babel/core": "^7.24.0",
+      },
+    },
+  },
+  // Nested entries pin a different version for one dependent
+  "packages": {
+    "@babel/core": ["@babel/core@7.24.0", "", { "dependencies": { "debug": "^4.1.0" } }, "sha512-abc"],
+    "@babel/core/debug": ["debug@4.3.4", "", {}, "sha512-def"],
+    "debug": ["debug@2.6.9", "", {}, "sha512-ghi"],
+    "my-lib": ["my-lib@workspace:packages/my-lib"],
+    "react": ["react@18.2.0", "", {}, "sha512-jkl"],
+    "react-dom": ["react-dom@18.2.0", "", { "dependencies": { "react": "^18.2.0", "debug": "^2.6.9" } }, "sha512-mno"],
+  }
+}
+`
+
+func TestBunResolvedPackages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "app"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bun.lock"), []byte(bunLockText), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewParser(dir)
+	all, err := parser.GetDependencies(true)
+	if err != nil {
+		t.Fatalf("GetDependencies returned error: %v", err)
+	}
+	wantAll := []string{"@babel/core@7.24.0", "debug@2.6.9", "debug@4.3.4", "react-dom@18.2.0", "react@18.2.0"}
+	if got := packageIDs(all); !slices.Equal(got, wantAll) {
+		t.Errorf("with dev = %v, want %v", got, wantAll)
+	}
+
+	prod, err := parser.GetDependencies(false)
+	if err != nil {
+		t.Fatalf("GetDependencies returned error: %v", err)
+	}
+	wantProd := []string{"debug@2.6.9", "react-dom@18.2.0", "react@18.2.0"}
+	if got := packageIDs(prod); !slices.Equal(got, wantProd) {
+		t.Errorf("without dev = %v, want %v", got, wantProd)
+	}
+
+	dev, err := parser.GetDependenciesFiltered(DependenciesDev)
+	if err != nil {
+		t.Fatalf("GetDependenciesFiltered returned error: %v", err)
+	}
+	wantDev := []string{"@babel/core@7.24.0", "debug@4.3.4"}
+	if got := packageIDs(dev); !slices.Equal(got, wantDev) {
+		t.Errorf("dev only = %v, want %v", got, wantDev)
+	}
+}
+
+func TestBinaryBunLockfile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "app"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bun.lockb"), []byte{0x23, 0x21}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewParser(dir).GetDependencies(true); err == nil {
+		t.Error("expected an error for a binary-only bun lockfile")
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	in := `{"a": "x // not a comment", /* block */ "b": [1, 2,], // line
+}`
+	want := `{"a": "x // not a comment",  "b": [1, 2] 
+}`
+	if got := string(stripJSONC([]byte(in))); got != want {
+		t.Errorf("stripJSONC() = %q, want %q", got, want)
+	}
+}
+
+const bunLockClasses = `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "app",
+      "dependencies": { "react": "^18.2.0" },
+      "devDependencies": { "jest": "^29.0.0" },
+      "optionalDependencies": { "fsevents": "^2.3.0" },
+      "peerDependencies": { "react-dom": "^18.2.0" },
+    },
+  },
+  "packages": {
+    "fsevents": ["fsevents@2.3.3", "", {}, "sha512-a"],
+    "jest": ["jest@29.7.0", "", { "dependencies": { "fsevents": "^2.3.0" } }, "sha512-b"],
+    "loose-envify": ["loose-envify@1.4.0", "", {}, "sha512-c"],
+    "react": ["react@18.2.0", "", { "optionalDependencies": { "loose-envify": "^1.4.0" }, "peerDependencies": { "scheduler": "^0.23.0" } }, "sha512-d"],
+    "react-dom": ["react-dom@18.2.0", "", {}, "sha512-e"],
+    "scheduler": ["scheduler@0.23.0", "", {}, "sha512-f"],
+  }
+}
+`
+
+// TestBunDependencyClasses classifies packages by how the workspaces'
+// direct dependencies reach them
+func TestBunDependencyClasses(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "app"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bun.lock"), []byte(bunLockClasses), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewParser(dir)
+	for class, want := range map[DependencyClass][]string{
+		DependenciesProd:     {"react@18.2.0"},
+		DependenciesOptional: {"fsevents@2.3.3", "loose-envify@1.4.0"},
+		DependenciesDev:      {"fsevents@2.3.3", "jest@29.7.0"},
+		DependenciesPeer:     {"react-dom@18.2.0", "scheduler@0.23.0"},
+	} {
+		got, err := parser.GetDependenciesFiltered(class)
+		if err != nil {
+			t.Fatalf("GetDependenciesFiltered returned error: %v", err)
+		}
+		if ids := packageIDs(got); !slices.Equal(ids, want) {
+			t.Errorf("class %d = %v, want %v", class, ids, want)
+		}
+	}
+}
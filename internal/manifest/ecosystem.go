@@ -0,0 +1,38 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// purlTypes maps ecosystem names accepted from the user to the package URL
+// types Package.Ecosystem holds
+var purlTypes = map[string]string{
+	"npm":       "npm",
+	"pypi":      "pypi",
+	"go":        "golang",
+	"golang":    "golang",
+	"cargo":     "cargo",
+	"crates.io": "cargo",
+	"maven":     "maven",
+	"nuget":     "nuget",
+	"gem":       "gem",
+	"rubygems":  "gem",
+	"composer":  "composer",
+	"packagist": "composer",
+	"pub":       "pub",
+	"hex":       "hex",
+}
+
+// Ecosystems lists the ecosystem names NormalizeEcosystem accepts, one per
+// package URL type
+var Ecosystems = []string{"npm", "pypi", "go", "cargo", "maven", "nuget", "gem", "composer", "pub", "hex"}
+
+// NormalizeEcosystem returns the package URL type for an ecosystem name,
+// e.g. "go" -> "golang" and "crates.io" -> "cargo"
+func NormalizeEcosystem(name string) (string, error) {
+	if t, ok := purlTypes[strings.ToLower(name)]; ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown ecosystem %q (expected %s)", name, strings.Join(Ecosystems, ", "))
+}
@@ -0,0 +1,220 @@
+package manifest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// DependencyGraph links the entries of package-lock.json to the entries
+// their dependencies resolve to, following Node's node_modules lookup
+type DependencyGraph struct {
+	lock  *PackageLock
+	edges map[string][]string
+
+	// roots are the project itself ("") and its workspace directories
+	roots []string
+}
+
+// Chain is one path through the dependency tree, from a direct dependency
+// down to a package
+type Chain struct {
+	// Workspace is the workspace directory the chain starts in, or "" for
+	// the root package
+	Workspace string
+
+	Packages []Package
+}
+
+// String formats the chain as "a@1.0.0 > b@2.0.0"
+func (c Chain) String() string {
+	parts := make([]string, len(c.Packages))
+	for i, pkg := range c.Packages {
+		parts[i] = pkg.Name + "@" + pkg.Version
+	}
+	return strings.Join(parts, " > ")
+}
+
+// DependencyGraph builds the dependency graph from package-lock.json
+func (p *Parser) DependencyGraph() (*DependencyGraph, error) {
+	lockfile, err := p.ParseLockfile()
+	if err != nil {
+		return nil, err
+	}
+	if lockfile == nil || len(lockfile.Packages) == 0 {
+		return nil, errors.ManifestError("the dependency graph needs package-lock.json or npm-shrinkwrap.json", nil)
+	}
+	return newDependencyGraph(lockfile), nil
+}
+
+// newDependencyGraph resolves every declared dependency of every lockfile
+// entry to the entry Node would load
+func newDependencyGraph(lock *PackageLock) *DependencyGraph {
+	g := &DependencyGraph{lock: lock, edges: make(map[string][]string)}
+
+	for path, entry := range lock.Packages {
+		if !strings.Contains(path, "node_modules/") {
+			g.roots = append(g.roots, path)
+		}
+
+		names := dependencyNames(entry, path == "" || !strings.Contains(path, "node_modules/"))
+		for _, name := range names {
+			if child, ok := lock.resolve(path, name); ok {
+				g.edges[path] = append(g.edges[path], child)
+			}
+		}
+	}
+	sort.Strings(g.roots)
+	return g
+}
+
+// dependencyNames returns the sorted names of an entry's dependencies.
+// devDependencies only count for the project and its workspaces; for
+// installed packages they are not installed.
+func dependencyNames(entry PackageLockPkg, root bool) []string {
+	sections := []map[string]string{entry.Dependencies, entry.OptionalDependencies, entry.PeerDependencies}
+	if root {
+		sections = append(sections, entry.DevDependencies)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, deps := range sections {
+		for name := range deps {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve returns the lockfile path a dependency of the entry at from
+// loads: the nearest node_modules/<name> walking up the tree. Links to
+// workspaces resolve to the workspace directory.
+func (l *PackageLock) resolve(from, name string) (string, bool) {
+	dir := from
+	for {
+		path := "node_modules/" + name
+		if dir != "" {
+			path = dir + "/" + path
+		}
+		if entry, ok := l.Packages[path]; ok {
+			if entry.Link {
+				_, ok := l.Packages[entry.Resolved]
+				return entry.Resolved, ok
+			}
+			return path, true
+		}
+		if dir == "" {
+			return "", false
+		}
+
+		// Step out of the innermost node_modules, or from a workspace
+		// directory to the project root
+		i := strings.LastIndex(dir, "node_modules/")
+		if i <= 0 {
+			dir = ""
+		} else {
+			dir = dir[:i-1]
+		}
+	}
+}
+
+// Why returns the shortest chain from each direct dependency to each
+// installed copy of the named package. An empty version matches any
+// version.
+func (g *DependencyGraph) Why(name, version string) []Chain {
+	targets := make(map[string]bool)
+	for path, entry := range g.lock.Packages {
+		if strings.Contains(path, "node_modules/") && extractPackageName(path) == name && (version == "" || entry.Version == version) {
+			targets[path] = true
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	var chains []Chain
+	seen := make(map[string]bool)
+	for _, root := range g.roots {
+		for _, direct := range g.edges[root] {
+			// Workspaces are roots of their own
+			if !strings.Contains(direct, "node_modules/") {
+				continue
+			}
+			for _, path := range g.shortestPaths(direct, targets) {
+				chain := Chain{Workspace: root, Packages: g.packages(path)}
+				key := root + " " + chain.String()
+				if !seen[key] {
+					seen[key] = true
+					chains = append(chains, chain)
+				}
+			}
+		}
+	}
+	return chains
+}
+
+// Introducers returns the direct dependencies that pull in a package,
+// excluding the package itself when it is a direct dependency
+func (g *DependencyGraph) Introducers(name, version string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, chain := range g.Why(name, version) {
+		if len(chain.Packages) < 2 {
+			continue
+		}
+		if direct := chain.Packages[0].Name; !seen[direct] {
+			seen[direct] = true
+			names = append(names, direct)
+		}
+	}
+	return names
+}
+
+// shortestPaths does a breadth-first search from start and returns the
+// shortest path to each target reachable from it
+func (g *DependencyGraph) shortestPaths(start string, targets map[string]bool) [][]string {
+	parent := map[string]string{start: ""}
+	queue := []string{start}
+	var found [][]string
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if targets[node] {
+			var path []string
+			for n := node; n != ""; n = parent[n] {
+				path = append([]string{n}, path...)
+			}
+			found = append(found, path)
+		}
+
+		for _, child := range g.edges[node] {
+			if _, visited := parent[child]; !visited {
+				parent[child] = node
+				queue = append(queue, child)
+			}
+		}
+	}
+	return found
+}
+
+// packages turns lockfile paths into packages
+func (g *DependencyGraph) packages(paths []string) []Package {
+	packages := make([]Package, len(paths))
+	for i, path := range paths {
+		entry := g.lock.Packages[path]
+		name := extractPackageName(path)
+		if name == "" {
+			name = entry.Name
+		}
+		packages[i] = Package{Name: name, Version: entry.Version, Ecosystem: "npm"}
+	}
+	return packages
+}
@@ -0,0 +1,54 @@
+package manifest
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestDependencyGraphWhy(t *testing.T) {
+	const lockfile = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"mkdirp": "^0.5.5", "optimist": "^0.6.1"}, "devDependencies": {"minimist": "^1.2.8"}},
+    "node_modules/mkdirp": {"version": "0.5.5", "dependencies": {"minimist": "^1.2.5"}},
+    "node_modules/mkdirp/node_modules/minimist": {"version": "1.2.5"},
+    "node_modules/optimist": {"version": "0.6.1", "dependencies": {"minimist": "~0.0.1", "wordwrap": "~0.0.2"}},
+    "node_modules/optimist/node_modules/minimist": {"version": "0.0.10"},
+    "node_modules/wordwrap": {"version": "0.0.3"},
+    "node_modules/minimist": {"version": "1.2.8", "dev": true}
+  }
+}`
+	var lock PackageLock
+	if err := json.Unmarshal([]byte(lockfile), &lock); err != nil {
+		t.Fatal(err)
+	}
+	graph := newDependencyGraph(&lock)
+
+	tests := []struct {
+		name, version string
+		want          []string
+	}{
+		{"minimist", "", []string{"minimist@1.2.8", "mkdirp@0.5.5 > minimist@1.2.5", "optimist@0.6.1 > minimist@0.0.10"}},
+		{"minimist", "1.2.5", []string{"mkdirp@0.5.5 > minimist@1.2.5"}},
+		{"wordwrap", "", []string{"optimist@0.6.1 > wordwrap@0.0.3"}},
+		{"left-pad", "", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, chain := range graph.Why(tt.name, tt.version) {
+			got = append(got, chain.String())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Why(%q, %q) = %v, want %v", tt.name, tt.version, got, tt.want)
+		}
+	}
+
+	if got := graph.Introducers("minimist", "1.2.8"); len(got) != 0 {
+		t.Errorf("Introducers(direct) = %v, want none", got)
+	}
+	if got := graph.Introducers("minimist", "0.0.10"); !slices.Equal(got, []string{"optimist"}) {
+		t.Errorf("Introducers(minimist@0.0.10) = %v, want [optimist]", got)
+	}
+}
@@ -0,0 +1,157 @@
+package manifest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// installScripts are the lifecycle scripts npm runs when a package is installed
+var installScripts = []string{"preinstall", "install", "postinstall"}
+
+// LocalPackage is a package installed from a tarball or directory on disk
+type LocalPackage struct {
+	// Spec is the argument as given, e.g. "./my-lib-1.2.3.tgz"
+	Spec string
+
+	// Path is the absolute path of the tarball or directory
+	Path string
+
+	// Tarball is true for .tgz/.tar.gz/.tar files
+	Tarball bool
+
+	Name    string
+	Version string
+	Scripts map[string]string
+}
+
+// IsLocalSpec returns true if an install argument refers to a file or
+// directory rather than a registry package
+func IsLocalSpec(spec string) bool {
+	if strings.HasPrefix(spec, "file:") {
+		return true
+	}
+	if spec == "." || spec == ".." {
+		return true
+	}
+	for _, prefix := range []string{"./", "../", "/", "~/"} {
+		if strings.HasPrefix(spec, prefix) {
+			return true
+		}
+	}
+	return isTarball(spec)
+}
+
+// isTarball returns true for the archive extensions npm accepts
+func isTarball(path string) bool {
+	for _, ext := range []string{".tgz", ".tar.gz", ".tar"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadLocalPackage resolves a local spec relative to baseDir and reads the
+// package.json inside it
+func ReadLocalPackage(spec, baseDir string) (*LocalPackage, error) {
+	path := strings.TrimPrefix(spec, "file:")
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.ManifestError(fmt.Sprintf("cannot resolve %s", spec), err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.ManifestError(fmt.Sprintf("local package %s not found", spec), err)
+	}
+
+	pkg := &LocalPackage{Spec: spec, Path: path, Tarball: !info.IsDir()}
+
+	var data []byte
+	if info.IsDir() {
+		data, err = os.ReadFile(filepath.Join(path, "package.json"))
+		if err != nil {
+			return nil, errors.ManifestError(fmt.Sprintf("%s has no package.json", spec), err)
+		}
+	} else {
+		data, err = readTarballManifest(path)
+		if err != nil {
+			return nil, errors.ManifestError(fmt.Sprintf("failed to read package.json from %s", spec), err)
+		}
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.ManifestError(fmt.Sprintf("failed to parse package.json in %s", spec), err)
+	}
+	if m.Name == "" || m.Version == "" {
+		return nil, errors.ManifestError(fmt.Sprintf("package.json in %s has no name or version", spec), nil)
+	}
+
+	pkg.Name = m.Name
+	pkg.Version = m.Version
+	pkg.Scripts = m.Scripts
+	return pkg, nil
+}
+
+// InstallScripts returns the lifecycle scripts the package runs on install
+func (p *LocalPackage) InstallScripts() []string {
+	var scripts []string
+	for _, name := range installScripts {
+		if p.Scripts[name] != "" {
+			scripts = append(scripts, name)
+		}
+	}
+	return scripts
+}
+
+// readTarballManifest returns the top-level package.json of an npm tarball.
+// npm pack puts it at "package/package.json", but some tools use another
+// top-level directory name.
+func readTarballManifest(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(path, ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no package.json in archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if dir, file := filepath.Split(name); file == "package.json" && strings.Count(dir, "/") == 1 {
+			return io.ReadAll(io.LimitReader(tr, 1<<20))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package manifest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIsLocalSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"./my-lib-1.2.3.tgz", true},
+		{"../local-pkg", true},
+		{"/abs/path/pkg", true},
+		{"~/pkgs/lib", true},
+		{"file:../lib", true},
+		{"my-lib-1.2.3.tgz", true},
+		{".", true},
+		{"lodash", false},
+		{"lodash@4.17.20", false},
+		{"@types/node", false},
+		{"@types/node@20.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			if got := IsLocalSpec(tt.spec); got != tt.want {
+				t.Errorf("IsLocalSpec(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLocalPackage(t *testing.T) {
+	base := t.TempDir()
+	manifest := `{"name": "my-lib", "version": "1.2.3", "scripts": {"postinstall": "node setup.js", "test": "jest"}}`
+
+	dir := filepath.Join(base, "local-pkg")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeTarball(t, filepath.Join(base, "my-lib-1.2.3.tgz"), map[string]string{
+		"package/README.md":               "# my-lib",
+		"package/lib/nested/package.json": `{"name": "nested", "version": "0.0.1"}`,
+		"package/package.json":            manifest,
+	})
+
+	project := filepath.Join(base, "project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec    string
+		tarball bool
+		wantErr bool
+	}{
+		{spec: "../local-pkg"},
+		{spec: "file:../local-pkg"},
+		{spec: "../my-lib-1.2.3.tgz", tarball: true},
+		{spec: filepath.Join(base, "my-lib-1.2.3.tgz"), tarball: true},
+		{spec: "../missing", wantErr: true},
+		{spec: "../project", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			pkg, err := ReadLocalPackage(tt.spec, project)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadLocalPackage() error = %v", err)
+			}
+			if pkg.Name != "my-lib" || pkg.Version != "1.2.3" || pkg.Tarball != tt.tarball {
+				t.Errorf("got %s@%s tarball=%v", pkg.Name, pkg.Version, pkg.Tarball)
+			}
+			if !filepath.IsAbs(pkg.Path) {
+				t.Errorf("Path %q is not absolute", pkg.Path)
+			}
+			if got := pkg.InstallScripts(); !slices.Equal(got, []string{"postinstall"}) {
+				t.Errorf("InstallScripts() = %v", got)
+			}
+		})
+	}
+}
+
+func writeTarball(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	// Nested package.json first, to check only the top-level one is used
+	slices.Sort(names)
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
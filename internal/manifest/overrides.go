@@ -0,0 +1,91 @@
+package manifest
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// OverrideVersions returns the versions package.json forces with npm
+// overrides or Yarn resolutions, keyed by package name. A nested override
+// such as {"foo": {"bar": "2.0.0"}} or a resolution for "foo/bar" counts
+// for its leaf package, bar. npm's "$name" references resolve to the
+// project's own range for that dependency.
+func (m *Manifest) OverrideVersions() map[string]string {
+	versions := make(map[string]string)
+
+	for key, version := range m.Resolutions {
+		if name := resolutionName(key); name != "" {
+			versions[name] = version
+		}
+	}
+
+	if len(m.Overrides) > 0 {
+		var overrides map[string]json.RawMessage
+		if err := json.Unmarshal(m.Overrides, &overrides); err == nil {
+			m.collectOverrides(overrides, versions)
+		}
+	}
+	return versions
+}
+
+// collectOverrides adds one level of npm overrides to versions, recursing
+// into nested objects. The "." key of a nested object overrides the
+// package itself.
+func (m *Manifest) collectOverrides(overrides map[string]json.RawMessage, versions map[string]string) {
+	for key, raw := range overrides {
+		name := packageSelectorName(key)
+
+		var version string
+		if err := json.Unmarshal(raw, &version); err == nil {
+			if name != "" {
+				versions[name] = m.overrideVersion(version)
+			}
+			continue
+		}
+
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &nested); err != nil {
+			continue
+		}
+		if self, ok := nested["."]; ok && name != "" {
+			if err := json.Unmarshal(self, &version); err == nil {
+				versions[name] = m.overrideVersion(version)
+			}
+		}
+		delete(nested, ".")
+		m.collectOverrides(nested, versions)
+	}
+}
+
+// overrideVersion resolves a "$name" reference to the project's range for
+// that dependency
+func (m *Manifest) overrideVersion(version string) string {
+	ref, ok := strings.CutPrefix(version, "$")
+	if !ok {
+		return version
+	}
+	if v, ok := m.Dependencies[ref]; ok {
+		return v
+	}
+	return m.DevDependencies[ref]
+}
+
+// packageSelectorName strips a version from an npm override key such as
+// "foo@1.x" or "@scope/foo@^2"
+func packageSelectorName(key string) string {
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// resolutionName returns the leaf package of a Yarn resolution key such
+// as "minimist", "**/minimist", "foo/bar" or "foo/@scope/bar"
+func resolutionName(key string) string {
+	parts := strings.Split(key, "/")
+	leaf := parts[len(parts)-1]
+	if len(parts) >= 2 && strings.HasPrefix(parts[len(parts)-2], "@") {
+		leaf = parts[len(parts)-2] + "/" + leaf
+	}
+	return packageSelectorName(leaf)
+}
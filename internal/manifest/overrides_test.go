@@ -0,0 +1,52 @@
+package manifest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOverrideVersions(t *testing.T) {
+	const pkg = `{
+  "dependencies": {"minimist": "^1.2.0", "foo": "^1.0.0", "react": "^18.2.0", "lodash": "^4.17.0"},
+  "overrides": {
+    "minimist": "1.2.8",
+    "foo": {".": "1.1.0", "bar": "2.0.0", "baz@1": {"qux": "3.0.0"}},
+    "react-dom": "$react",
+    "@scope/pkg@^2": "2.1.0"
+  },
+  "resolutions": {"**/lodash": "4.17.21", "webpack/@babel/core": "7.24.0"}
+}`
+	var m Manifest
+	if err := json.Unmarshal([]byte(pkg), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"minimist":    "1.2.8",
+		"foo":         "1.1.0",
+		"bar":         "2.0.0",
+		"qux":         "3.0.0",
+		"react-dom":   "^18.2.0",
+		"@scope/pkg":  "2.1.0",
+		"lodash":      "4.17.21",
+		"@babel/core": "7.24.0",
+	}
+	got := m.OverrideVersions()
+	if len(got) != len(want) {
+		t.Errorf("OverrideVersions() = %v, want %v", got, want)
+	}
+	for name, version := range want {
+		if got[name] != version {
+			t.Errorf("OverrideVersions()[%q] = %q, want %q", name, got[name], version)
+		}
+	}
+
+	for _, p := range m.packages(DependenciesAll) {
+		if p.Name == "minimist" && (p.Version != "1.2.8" || !p.Overridden) {
+			t.Errorf("minimist = %+v, want the override", p)
+		}
+		if p.Name == "react" && p.Overridden {
+			t.Errorf("react = %+v, want no override", p)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package manifest
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,6 +15,23 @@ type Package struct {
 	Name      string `json:"name"`
 	Version   string `json:"version"`
 	Ecosystem string `json:"ecosystem"`
+
+	// Resolved and Integrity are the tarball URL and subresource integrity
+	// hash recorded in package-lock.json, when known
+	Resolved  string `json:"resolved,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
+
+	// Direct is set for packages listed in package.json, or in a
+	// workspace's package.json, rather than pulled in by another package
+	Direct bool `json:"direct"`
+
+	// Overridden is set when package.json overrides or resolutions
+	// replaced the declared version
+	Overridden bool `json:"-"`
+
+	// New is set for packages about to be installed that the project does
+	// not have yet
+	New bool `json:"-"`
 }
 
 // PURL returns the Package URL for this package
@@ -28,22 +46,221 @@ type Manifest struct {
 	Scripts         map[string]string `json:"scripts"`
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
+
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+
+	// Engines maps runtimes such as node to the version ranges the
+	// package supports
+	Engines map[string]string `json:"engines"`
+
+	// Workspaces holds the workspaces globs, as an array or an object;
+	// see WorkspacePatterns
+	Workspaces json.RawMessage `json:"workspaces"`
+
+	// Overrides (npm) and Resolutions (Yarn) force versions anywhere in
+	// the tree; see OverrideVersions
+	Overrides   json.RawMessage   `json:"overrides"`
+	Resolutions map[string]string `json:"resolutions"`
 }
 
-// PackageLock represents a parsed package-lock.json
+// PackageLock represents a parsed package-lock.json or npm-shrinkwrap.json
 type PackageLock struct {
 	Name            string                    `json:"name"`
 	Version         string                    `json:"version"`
 	LockfileVersion int                       `json:"lockfileVersion"`
 	Packages        map[string]PackageLockPkg `json:"packages"`
+
+	// Dependencies is the nested tree of lockfileVersion 1. ParseLockfile
+	// flattens it into Packages.
+	Dependencies map[string]PackageLockDep `json:"dependencies"`
+}
+
+// PackageLockDep is a package in the lockfileVersion 1 tree
+type PackageLockDep struct {
+	Version      string                    `json:"version"`
+	Resolved     string                    `json:"resolved"`
+	Integrity    string                    `json:"integrity"`
+	Dev          bool                      `json:"dev"`
+	Optional     bool                      `json:"optional"`
+	Requires     map[string]string         `json:"requires"`
+	Dependencies map[string]PackageLockDep `json:"dependencies"`
 }
 
 // PackageLockPkg represents a package in the lockfile
 type PackageLockPkg struct {
+	Name      string `json:"name"`
 	Version   string `json:"version"`
 	Resolved  string `json:"resolved"`
 	Integrity string `json:"integrity"`
-	Dev       bool   `json:"dev"`
+
+	// Dev, Optional, DevOptional and Peer mark entries only needed by
+	// those kinds of dependencies
+	Dev         bool `json:"dev"`
+	Optional    bool `json:"optional"`
+	DevOptional bool `json:"devOptional"`
+	Peer        bool `json:"peer"`
+
+	// Link marks a symlink to a workspace; Resolved holds its directory
+	Link bool `json:"link"`
+
+	// The dependency ranges the package declares, used to walk the tree
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// DependencyClass selects dependencies by the package.json section that
+// brings them in. Classes combine with |.
+type DependencyClass int
+
+const (
+	// DependenciesProd selects packages needed in production
+	DependenciesProd DependencyClass = 1 << iota
+
+	// DependenciesDev selects packages needed only for development
+	DependenciesDev
+
+	// DependenciesOptional selects packages needed only as optional
+	// dependencies
+	DependenciesOptional
+
+	// DependenciesPeer selects packages needed only as peer dependencies
+	DependenciesPeer
+
+	// DependenciesAll selects every dependency
+	DependenciesAll = DependenciesProd | DependenciesDev | DependenciesOptional | DependenciesPeer
+)
+
+// dependencyClassNames maps --include values to classes
+var dependencyClassNames = map[string]DependencyClass{
+	"all":      DependenciesAll,
+	"prod":     DependenciesProd,
+	"dev":      DependenciesDev,
+	"optional": DependenciesOptional,
+	"peer":     DependenciesPeer,
+}
+
+// ParseDependencyClass parses an --include value: a comma-separated list
+// of all, prod, dev, optional and peer
+func ParseDependencyClass(s string) (DependencyClass, error) {
+	if s == "" {
+		return DependenciesAll, nil
+	}
+
+	var class DependencyClass
+	for _, name := range strings.Split(s, ",") {
+		c, ok := dependencyClassNames[strings.TrimSpace(name)]
+		if !ok {
+			return DependenciesAll, fmt.Errorf("invalid dependency class %q (expected all, prod, dev, optional or peer)", name)
+		}
+		class |= c
+	}
+	return class, nil
+}
+
+// lockedClass records why a lockfile entry is installed, with npm's
+// flags: Dev, Optional and Peer entries are needed only by dev, optional
+// or peer dependencies, and DevOptional ones by dev or optional ones but
+// not by production. Entries without a flag are needed in production.
+type lockedClass struct {
+	Dev, Optional, DevOptional, Peer bool
+}
+
+// includesLocked reports whether a package-lock.json entry belongs to the
+// class
+func (c DependencyClass) includesLocked(pkg PackageLockPkg) bool {
+	return c.includes(lockedClass{Dev: pkg.Dev, Optional: pkg.Optional, DevOptional: pkg.DevOptional, Peer: pkg.Peer})
+}
+
+// includes reports whether an entry with the given flags belongs to the
+// class
+func (c DependencyClass) includes(e lockedClass) bool {
+	switch {
+	case e.DevOptional:
+		return c&(DependenciesDev|DependenciesOptional) != 0
+	case e.Dev:
+		return c&DependenciesDev != 0
+	case e.Optional:
+		return c&DependenciesOptional != 0
+	case e.Peer:
+		return c&DependenciesPeer != 0
+	default:
+		return c&DependenciesProd != 0
+	}
+}
+
+// lockGraph is the dependency graph of a lockfile that does not record
+// npm's flags, keyed by whatever identifies an entry in that format
+type lockGraph[K comparable] struct {
+	// Direct dependencies of the project, by section
+	prod, dev, optional, peer []K
+
+	// edges returns the entries an entry depends on, by section
+	edges func(K) (deps, optional, peer []K)
+}
+
+// classify derives npm's flags for every entry reachable from the direct
+// dependencies by walking the graph the way npm does when it writes them.
+// Entries reached only through peer dependencies are Peer; unreachable
+// entries are left out and treated as dev by the callers.
+func (g lockGraph[K]) classify() map[K]lockedClass {
+	walk := func(optional, peer bool, roots ...[]K) map[K]bool {
+		reached := make(map[K]bool)
+		var queue []K
+		for _, r := range roots {
+			queue = append(queue, r...)
+		}
+		for len(queue) > 0 {
+			key := queue[0]
+			queue = queue[1:]
+			if reached[key] {
+				continue
+			}
+			reached[key] = true
+			deps, optionalDeps, peerDeps := g.edges(key)
+			queue = append(queue, deps...)
+			if optional {
+				queue = append(queue, optionalDeps...)
+			}
+			if peer {
+				queue = append(queue, peerDeps...)
+			}
+		}
+		return reached
+	}
+
+	prod := walk(false, false, g.prod)
+	nonDev := walk(true, false, g.prod, g.optional)
+	dev := walk(true, false, g.dev)
+	all := walk(true, true, g.prod, g.optional, g.dev, g.peer)
+
+	classes := make(map[K]lockedClass, len(all))
+	for key := range all {
+		switch {
+		case prod[key]:
+			classes[key] = lockedClass{}
+		case nonDev[key] && dev[key]:
+			classes[key] = lockedClass{DevOptional: true}
+		case nonDev[key]:
+			classes[key] = lockedClass{Optional: true}
+		case dev[key]:
+			classes[key] = lockedClass{Dev: true}
+		default:
+			classes[key] = lockedClass{Peer: true}
+		}
+	}
+	return classes
+}
+
+// classOf returns an entry's flags from classify, treating an entry no
+// dependency reaches as dev
+func classOf[K comparable](classes map[K]lockedClass, key K) lockedClass {
+	if class, ok := classes[key]; ok {
+		return class
+	}
+	return lockedClass{Dev: true}
 }
 
 // Parser handles manifest file parsing
@@ -58,6 +275,11 @@ func NewParser(projectDir string) *Parser {
 	}
 }
 
+// Dir returns the project directory the parser reads
+func (p *Parser) Dir() string {
+	return p.projectDir
+}
+
 // ParseManifest reads and parses package.json
 func (p *Parser) ParseManifest() (*Manifest, error) {
 	path := filepath.Join(p.projectDir, "package.json")
@@ -74,10 +296,21 @@ func (p *Parser) ParseManifest() (*Manifest, error) {
 	return &manifest, nil
 }
 
-// ParseLockfile reads and parses package-lock.json
+// LockfileName returns the npm lockfile of the project: npm-shrinkwrap.json
+// if there is one, since npm prefers it, otherwise package-lock.json
+func (p *Parser) LockfileName() string {
+	if _, err := os.Stat(filepath.Join(p.projectDir, "npm-shrinkwrap.json")); err == nil {
+		return "npm-shrinkwrap.json"
+	}
+	return "package-lock.json"
+}
+
+// ParseLockfile reads and parses the npm lockfile. A lockfileVersion 1
+// tree is flattened into Packages, keyed by node_modules path as in later
+// versions.
 func (p *Parser) ParseLockfile() (*PackageLock, error) {
-	path := filepath.Join(p.projectDir, "package-lock.json")
-	data, err := os.ReadFile(path)
+	name := p.LockfileName()
+	data, err := os.ReadFile(filepath.Join(p.projectDir, name))
 	if err != nil {
 		// Lockfile might not exist, which is okay
 		return nil, nil
@@ -85,44 +318,158 @@ func (p *Parser) ParseLockfile() (*PackageLock, error) {
 
 	var lockfile PackageLock
 	if err := json.Unmarshal(data, &lockfile); err != nil {
-		return nil, errors.ManifestError("failed to parse package-lock.json", err)
+		return nil, errors.ManifestError("failed to parse "+name, err)
+	}
+
+	if len(lockfile.Packages) == 0 && len(lockfile.Dependencies) > 0 {
+		root := PackageLockPkg{Name: lockfile.Name, Version: lockfile.Version}
+		if manifest, err := p.ParseManifest(); err == nil {
+			root.Dependencies = manifest.Dependencies
+			root.DevDependencies = manifest.DevDependencies
+		}
+		lockfile.Packages = map[string]PackageLockPkg{"": root}
+		flattenV1(lockfile.Packages, "", lockfile.Dependencies)
 	}
 
 	return &lockfile, nil
 }
 
-// HasLockfile returns true if a lockfile exists
+// InstalledPackages returns the name@version of every package npm last
+// installed into node_modules, from its hidden lockfile. ok is false if
+// there is no hidden lockfile, as after a fresh clone or with other
+// package managers.
+func (p *Parser) InstalledPackages() (installed map[string]bool, ok bool) {
+	data, err := os.ReadFile(filepath.Join(p.projectDir, "node_modules", ".package-lock.json"))
+	if err != nil {
+		return nil, false
+	}
+	var lockfile PackageLock
+	if err := json.Unmarshal(data, &lockfile); err != nil {
+		return nil, false
+	}
+
+	installed = make(map[string]bool, len(lockfile.Packages))
+	for path, pkg := range lockfile.Packages {
+		if !strings.Contains(path, "node_modules/") || pkg.Link {
+			continue
+		}
+		installed[extractPackageName(path)+"@"+pkg.Version] = true
+	}
+	return installed, true
+}
+
+// flattenV1 adds a lockfileVersion 1 dependency tree to packages under the
+// node_modules paths its entries are installed at
+func flattenV1(packages map[string]PackageLockPkg, dir string, deps map[string]PackageLockDep) {
+	for name, dep := range deps {
+		path := "node_modules/" + name
+		if dir != "" {
+			path = dir + "/" + path
+		}
+		packages[path] = PackageLockPkg{
+			Version:      dep.Version,
+			Resolved:     dep.Resolved,
+			Integrity:    dep.Integrity,
+			Dev:          dep.Dev,
+			Optional:     dep.Optional,
+			Dependencies: dep.Requires,
+		}
+		flattenV1(packages, path, dep.Dependencies)
+	}
+}
+
+// HasLockfile returns true if package-lock.json or npm-shrinkwrap.json
+// exists
 func (p *Parser) HasLockfile() bool {
-	_, err := os.Stat(filepath.Join(p.projectDir, "package-lock.json"))
+	_, err := os.Stat(filepath.Join(p.projectDir, p.LockfileName()))
 	return err == nil
 }
 
-// HasBunLockfile returns true if a bun.lockb exists
+// HasBunLockfile returns true if a bun.lock or bun.lockb exists
 func (p *Parser) HasBunLockfile() bool {
+	if p.HasBunTextLockfile() {
+		return true
+	}
 	_, err := os.Stat(filepath.Join(p.projectDir, "bun.lockb"))
 	return err == nil
 }
 
-// GetDependencies extracts all dependencies from manifest and lockfile
+// HasYarnLockfile returns true if a yarn.lock exists
+func (p *Parser) HasYarnLockfile() bool {
+	_, err := os.Stat(filepath.Join(p.projectDir, "yarn.lock"))
+	return err == nil
+}
+
+// IsYarnBerry returns true if the project uses Yarn 2+ (.yarnrc.yml present)
+func (p *Parser) IsYarnBerry() bool {
+	_, err := os.Stat(filepath.Join(p.projectDir, ".yarnrc.yml"))
+	return err == nil
+}
+
+// GetDependencies extracts all dependencies from manifest and lockfile,
+// with devDependencies only if includeDev is set
 func (p *Parser) GetDependencies(includeDev bool) ([]Package, error) {
+	if includeDev {
+		return p.GetDependenciesFiltered(DependenciesAll)
+	}
+	return p.GetDependenciesFiltered(DependenciesProd)
+}
+
+// GetDependenciesFiltered extracts the dependencies of a class from the
+// lockfile, or from package.json when there is no lockfile. Lockfile
+// packages reachable from both production and dev dependencies count as
+// production.
+func (p *Parser) GetDependenciesFiltered(class DependencyClass) ([]Package, error) {
 	manifest, err := p.ParseManifest()
 	if err != nil {
 		return nil, err
 	}
 
-	lockfile, _ := p.ParseLockfile() // Ignore error, lockfile is optional
+	// pnpm projects record exact versions in pnpm-lock.yaml
+	if p.HasPnpmLockfile() {
+		pnpmLock, err := p.ParsePnpmLockfile()
+		if err != nil {
+			return nil, err
+		}
+		return markDirect(pnpmLock.ResolvedPackages(class), p.directNames(manifest)), nil
+	}
 
-	var packages []Package
+	// Bun projects record exact versions in bun.lock; the older binary
+	// bun.lockb format cannot be read
+	if p.HasBunTextLockfile() {
+		bunLock, err := p.ParseBunLockfile()
+		if err != nil {
+			return nil, err
+		}
+		return markDirect(bunLock.ResolvedPackages(class), p.directNames(manifest)), nil
+	}
+	if p.HasBunLockfile() && !p.HasLockfile() {
+		return nil, errors.ManifestError("bun.lockb is a binary lockfile and cannot be scanned; run `bun install --save-text-lockfile` to write bun.lock", nil)
+	}
+
+	// Yarn projects record exact versions in yarn.lock
+	if p.HasYarnLockfile() && !p.HasLockfile() {
+		yarnLock, err := p.ParseYarnLockfile()
+		if err != nil {
+			return nil, err
+		}
+		return markDirect(yarnLock.ResolvedPackages(manifest, class), p.directNames(manifest)), nil
+	}
+
+	lockfile, _ := p.ParseLockfile() // Ignore error, lockfile is optional
 
 	// If we have a lockfile, use exact versions from it
-	if lockfile != nil && lockfile.LockfileVersion >= 2 {
+	if lockfile != nil && len(lockfile.Packages) > 0 {
+		direct := p.directNames(manifest)
+		index := make(map[string]int)
+		var packages []Package
 		for pkgPath, pkgInfo := range lockfile.Packages {
-			// Skip root package
-			if pkgPath == "" {
+			// Skip the root package and workspace directories, which are
+			// local sources rather than installed packages
+			if pkgPath == "" || !strings.Contains(pkgPath, "node_modules/") {
 				continue
 			}
-			// Skip dev dependencies if not included
-			if pkgInfo.Dev && !includeDev {
+			if !class.includesLocked(pkgInfo) {
 				continue
 			}
 			// Extract package name from path
@@ -132,63 +479,138 @@ func (p *Parser) GetDependencies(includeDev bool) ([]Package, error) {
 			if name == "" || pkgInfo.Version == "" {
 				continue
 			}
+			// Nested copies are never the ones package.json asks for
+			isDirect := direct[name] && strings.Count(pkgPath, "node_modules/") == 1
+
+			// The same version can be installed at several nesting levels
+			key := name + "@" + pkgInfo.Version
+			if i, ok := index[key]; ok {
+				packages[i].Direct = packages[i].Direct || isDirect
+				continue
+			}
+			index[key] = len(packages)
 			packages = append(packages, Package{
 				Name:      name,
 				Version:   pkgInfo.Version,
 				Ecosystem: "npm",
+				Resolved:  pkgInfo.Resolved,
+				Integrity: pkgInfo.Integrity,
+				Direct:    isDirect,
 			})
 		}
-	} else {
-		// Fall back to manifest versions (may include ranges)
-		for name, version := range manifest.Dependencies {
-			packages = append(packages, Package{
-				Name:      name,
-				Version:   cleanVersion(version),
-				Ecosystem: "npm",
-			})
-		}
-		if includeDev {
-			for name, version := range manifest.DevDependencies {
-				packages = append(packages, Package{
-					Name:      name,
-					Version:   cleanVersion(version),
-					Ecosystem: "npm",
-				})
-			}
-		}
+		return p.addWorkspaceDependencies(packages, class)
 	}
 
-	return packages, nil
+	// Fall back to manifest versions (may include ranges)
+	return p.addWorkspaceDependencies(manifest.packages(class), class)
+}
+
+// addWorkspaceDependencies merges in the dependencies of workspace
+// packages that the lockfile or root package.json does not cover
+func (p *Parser) addWorkspaceDependencies(packages []Package, class DependencyClass) ([]Package, error) {
+	workspaces, err := p.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	return withWorkspaces(packages, workspaces, class), nil
 }
 
 // GetDirectDependencies returns only direct dependencies from package.json
 func (p *Parser) GetDirectDependencies(includeDev bool) ([]Package, error) {
+	if includeDev {
+		return p.GetDirectDependenciesFiltered(DependenciesAll)
+	}
+	return p.GetDirectDependenciesFiltered(DependenciesProd)
+}
+
+// GetDirectDependenciesFiltered returns the direct dependencies of a class
+// from package.json
+func (p *Parser) GetDirectDependenciesFiltered(class DependencyClass) ([]Package, error) {
 	manifest, err := p.ParseManifest()
 	if err != nil {
 		return nil, err
 	}
+	return manifest.packages(class), nil
+}
 
-	var packages []Package
-
-	for name, version := range manifest.Dependencies {
-		packages = append(packages, Package{
-			Name:      name,
-			Version:   cleanVersion(version),
-			Ecosystem: "npm",
-		})
-	}
+// packages returns the manifest's dependencies of a class, with range
+// prefixes stripped. workspace: references are local and skipped, and
+// overrides or resolutions replace the declared range.
+func (m *Manifest) packages(class DependencyClass) []Package {
+	overrides := m.OverrideVersions()
 
-	if includeDev {
-		for name, version := range manifest.DevDependencies {
-			packages = append(packages, Package{
+	// A name listed in several sections is scanned once, under the first
+	// of dependencies, optionalDependencies, devDependencies and
+	// peerDependencies; peer dependencies are often also dev dependencies
+	var packages []Package
+	seen := make(map[string]bool)
+	add := func(deps map[string]string) {
+		for name, version := range deps {
+			if strings.HasPrefix(version, "workspace:") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			pkg := Package{
 				Name:      name,
 				Version:   cleanVersion(version),
 				Ecosystem: "npm",
-			})
+				Direct:    true,
+			}
+			if override, ok := overrides[name]; ok {
+				pkg.Version = cleanVersion(override)
+				pkg.Overridden = true
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	if class&DependenciesProd != 0 {
+		add(m.Dependencies)
+	}
+	if class&DependenciesOptional != 0 {
+		add(m.OptionalDependencies)
+	}
+	if class&DependenciesDev != 0 {
+		add(m.DevDependencies)
+	}
+	if class&DependenciesPeer != 0 {
+		add(m.PeerDependencies)
+	}
+	return packages
+}
+
+// directNames returns the names listed in dependencies or devDependencies
+// of package.json or of a workspace's package.json
+func (p *Parser) directNames(root *Manifest) map[string]bool {
+	manifests := []*Manifest{root}
+	if workspaces, err := p.Workspaces(); err == nil {
+		for _, ws := range workspaces {
+			manifests = append(manifests, ws.Manifest)
 		}
 	}
 
-	return packages, nil
+	names := make(map[string]bool)
+	for _, m := range manifests {
+		for name := range m.Dependencies {
+			names[name] = true
+		}
+		for _, deps := range []map[string]string{m.DevDependencies, m.OptionalDependencies, m.PeerDependencies} {
+			for name := range deps {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// markDirect marks the packages whose names are in direct. Lockfiles other
+// than package-lock.json do not say which copy is the top-level one, so
+// every version of a listed name counts as direct.
+func markDirect(packages []Package, direct map[string]bool) []Package {
+	for i := range packages {
+		packages[i].Direct = direct[packages[i].Name]
+	}
+	return packages
 }
 
 // cleanVersion removes version prefixes like ^ and ~
@@ -207,10 +629,13 @@ func cleanVersion(version string) string {
 
 // DetectPackageManager determines which package manager to use
 func (p *Parser) DetectPackageManager() string {
-	// Check for bun.lockb first
+	// Check for bun.lock or bun.lockb first
 	if p.HasBunLockfile() {
 		return "bun"
 	}
+	if p.HasYarnLockfile() {
+		return "yarn"
+	}
 	// Default to npm
 	return "npm"
 }
@@ -1,6 +1,11 @@
 package manifest
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
 
 func TestExtractPackageName(t *testing.T) {
 	tests := []struct {
@@ -36,3 +41,311 @@ func TestExtractPackageName(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDependenciesFiltered(t *testing.T) {
+	const manifest = `{
+  "name": "app",
+  "dependencies": {"express": "^4.18.2"},
+  "devDependencies": {"jest": "~29.7.0"}
+}`
+	const lockfile = `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/debug": {"version": "2.6.9"},
+    "node_modules/jest": {"version": "29.7.0", "dev": true},
+    "node_modules/jest/node_modules/chalk": {"version": "4.1.2", "dev": true}
+  }
+}`
+
+	tests := []struct {
+		name     string
+		lockfile bool
+		want     map[DependencyClass][]string
+	}{
+		{
+			name:     "package-lock.json",
+			lockfile: true,
+			want: map[DependencyClass][]string{
+				DependenciesAll:  {"chalk@4.1.2", "debug@2.6.9", "express@4.18.2", "jest@29.7.0"},
+				DependenciesProd: {"debug@2.6.9", "express@4.18.2"},
+				DependenciesDev:  {"chalk@4.1.2", "jest@29.7.0"},
+			},
+		},
+		{
+			name: "package.json only",
+			want: map[DependencyClass][]string{
+				DependenciesAll:  {"express@4.18.2", "jest@29.7.0"},
+				DependenciesProd: {"express@4.18.2"},
+				DependenciesDev:  {"jest@29.7.0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.lockfile {
+				if err := os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(lockfile), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			parser := NewParser(dir)
+			for class, want := range tt.want {
+				packages, err := parser.GetDependenciesFiltered(class)
+				if err != nil {
+					t.Fatalf("GetDependenciesFiltered(%d) error = %v", class, err)
+				}
+				if got := packageIDs(packages); !slices.Equal(got, want) {
+					t.Errorf("GetDependenciesFiltered(%d) = %v, want %v", class, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDependencyClass(t *testing.T) {
+	for input, want := range map[string]DependencyClass{
+		"all":           DependenciesAll,
+		"prod":          DependenciesProd,
+		"dev":           DependenciesDev,
+		"prod,optional": DependenciesProd | DependenciesOptional,
+		"peer, dev":     DependenciesPeer | DependenciesDev,
+	} {
+		if got, err := ParseDependencyClass(input); err != nil || got != want {
+			t.Errorf("ParseDependencyClass(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"production", "prod,"} {
+		if _, err := ParseDependencyClass(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestDirectDependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json": `{"name": "app", "dependencies": {"express": "^4.18.2", "debug": "^4.3.4"}}`,
+		"package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/express/node_modules/debug": {"version": "2.6.9"},
+    "node_modules/debug": {"version": "4.3.4"},
+    "node_modules/ms": {"version": "2.1.3"}
+  }
+}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, err := NewParser(dir).GetDependencies(true)
+	if err != nil {
+		t.Fatalf("GetDependencies() error = %v", err)
+	}
+	var direct []string
+	for _, pkg := range packages {
+		if pkg.Direct {
+			direct = append(direct, pkg.Name+"@"+pkg.Version)
+		}
+	}
+	slices.Sort(direct)
+	if want := []string{"debug@4.3.4", "express@4.18.2"}; !slices.Equal(direct, want) {
+		t.Errorf("direct = %v, want %v", direct, want)
+	}
+}
+
+func TestLockfileV1(t *testing.T) {
+	const lockfile = `{
+  "name": "app",
+  "lockfileVersion": 1,
+  "requires": true,
+  "dependencies": {
+    "express": {
+      "version": "4.16.0",
+      "resolved": "https://registry.npmjs.org/express/-/express-4.16.0.tgz",
+      "integrity": "sha512-abc==",
+      "requires": {"debug": "2.6.9"},
+      "dependencies": {
+        "debug": {"version": "2.6.9", "requires": {"ms": "2.0.0"}}
+      }
+    },
+    "other": {
+      "version": "1.0.0",
+      "dependencies": {
+        "debug": {"version": "2.6.9"}
+      }
+    },
+    "ms": {"version": "2.0.0"},
+    "jest": {"version": "23.6.0", "dev": true}
+  }
+}`
+
+	for _, name := range []string{"package-lock.json", "npm-shrinkwrap.json"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "app", "dependencies": {"express": "4.16.0"}}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(lockfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			parser := NewParser(dir)
+			if !parser.HasLockfile() || parser.LockfileName() != name {
+				t.Fatalf("LockfileName() = %q, HasLockfile() = %v", parser.LockfileName(), parser.HasLockfile())
+			}
+
+			for class, want := range map[DependencyClass][]string{
+				DependenciesAll:  {"debug@2.6.9", "express@4.16.0", "jest@23.6.0", "ms@2.0.0", "other@1.0.0"},
+				DependenciesProd: {"debug@2.6.9", "express@4.16.0", "ms@2.0.0", "other@1.0.0"},
+			} {
+				packages, err := parser.GetDependenciesFiltered(class)
+				if err != nil {
+					t.Fatalf("GetDependenciesFiltered(%d) error = %v", class, err)
+				}
+				if got := packageIDs(packages); !slices.Equal(got, want) {
+					t.Errorf("GetDependenciesFiltered(%d) = %v, want %v", class, got, want)
+				}
+			}
+
+			graph, err := parser.DependencyGraph()
+			if err != nil {
+				t.Fatalf("DependencyGraph() error = %v", err)
+			}
+			var chains []string
+			for _, chain := range graph.Why("ms", "") {
+				chains = append(chains, chain.String())
+			}
+			if want := []string{"express@4.16.0 > debug@2.6.9 > ms@2.0.0"}; !slices.Equal(chains, want) {
+				t.Errorf("Why(ms) = %v, want %v", chains, want)
+			}
+		})
+	}
+}
+
+func TestOptionalAndPeerDependencies(t *testing.T) {
+	const manifest = `{
+  "name": "plugin",
+  "dependencies": {"chalk": "^5.3.0"},
+  "devDependencies": {"eslint": "^8.57.0", "jest": "^29.7.0"},
+  "optionalDependencies": {"fsevents": "^2.3.3"},
+  "peerDependencies": {"eslint": "^8.0.0"}
+}`
+	const lockfile = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "plugin"},
+    "node_modules/chalk": {"version": "5.3.0"},
+    "node_modules/eslint": {"version": "8.57.0", "dev": true},
+    "node_modules/jest": {"version": "29.7.0", "dev": true},
+    "node_modules/fsevents": {"version": "2.3.3", "optional": true},
+    "node_modules/react": {"version": "18.2.0", "peer": true},
+    "node_modules/nan": {"version": "2.19.0", "devOptional": true}
+  }
+}`
+
+	tests := []struct {
+		name     string
+		lockfile bool
+		want     map[string][]string
+	}{
+		{
+			name:     "package-lock.json",
+			lockfile: true,
+			want: map[string][]string{
+				"all":           {"chalk@5.3.0", "eslint@8.57.0", "fsevents@2.3.3", "jest@29.7.0", "nan@2.19.0", "react@18.2.0"},
+				"prod":          {"chalk@5.3.0"},
+				"prod,optional": {"chalk@5.3.0", "fsevents@2.3.3", "nan@2.19.0"},
+				"peer":          {"react@18.2.0"},
+			},
+		},
+		{
+			name: "package.json only",
+			want: map[string][]string{
+				"all":           {"chalk@5.3.0", "eslint@8.57.0", "fsevents@2.3.3", "jest@29.7.0"},
+				"prod,optional": {"chalk@5.3.0", "fsevents@2.3.3"},
+				"peer":          {"eslint@8.0.0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.lockfile {
+				if err := os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(lockfile), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			parser := NewParser(dir)
+			for include, want := range tt.want {
+				class, err := ParseDependencyClass(include)
+				if err != nil {
+					t.Fatal(err)
+				}
+				packages, err := parser.GetDependenciesFiltered(class)
+				if err != nil {
+					t.Fatalf("GetDependenciesFiltered(%s) error = %v", include, err)
+				}
+				if got := packageIDs(packages); !slices.Equal(got, want) {
+					t.Errorf("GetDependenciesFiltered(%s) = %v, want %v", include, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInstalledPackages(t *testing.T) {
+	dir := t.TempDir()
+	parser := NewParser(dir)
+	if _, ok := parser.InstalledPackages(); ok {
+		t.Fatal("InstalledPackages() ok = true without node_modules")
+	}
+
+	const hidden = `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/express/node_modules/debug": {"version": "2.6.9"},
+    "node_modules/@babel/core": {"version": "7.24.0"},
+    "node_modules/local": {"resolved": "packages/local", "link": true},
+    "packages/local": {"version": "1.0.0"}
+  }
+}`
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "node_modules", ".package-lock.json"), []byte(hidden), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installed, ok := parser.InstalledPackages()
+	if !ok {
+		t.Fatal("InstalledPackages() ok = false")
+	}
+	var got []string
+	for id := range installed {
+		got = append(got, id)
+	}
+	slices.Sort(got)
+	if want := []string{"@babel/core@7.24.0", "debug@2.6.9", "express@4.18.2"}; !slices.Equal(got, want) {
+		t.Errorf("InstalledPackages() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// PnpmLock represents a parsed pnpm-lock.yaml (lockfile v5, v6 and v9)
+type PnpmLock struct {
+	LockfileVersion string                  `yaml:"lockfileVersion"`
+	Importers       map[string]pnpmImporter `yaml:"importers"`
+	Packages        map[string]pnpmPackage  `yaml:"packages"`
+	Snapshots       map[string]pnpmSnapshot `yaml:"snapshots"`
+}
+
+// pnpmImporter lists the direct dependencies of a workspace project
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmDepRef `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmDepRef `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmDepRef `yaml:"optionalDependencies"`
+}
+
+// pnpmPackage is an entry in the packages section. Before v9, dev is
+// true for dev-only and false for production-only packages, and left out
+// for packages both need.
+type pnpmPackage struct {
+	Dev      *bool `yaml:"dev"`
+	Optional bool  `yaml:"optional"`
+}
+
+// pnpmSnapshot is an entry in the v9 snapshots section
+type pnpmSnapshot struct {
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// pnpmDepRef is an importer dependency, written either as a bare version
+// (v5) or as a {specifier, version} mapping (v6+)
+type pnpmDepRef struct {
+	Version string
+}
+
+// UnmarshalYAML accepts both dependency reference forms
+func (r *pnpmDepRef) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Version = node.Value
+		return nil
+	}
+	var ref struct {
+		Version string `yaml:"version"`
+	}
+	if err := node.Decode(&ref); err != nil {
+		return err
+	}
+	r.Version = ref.Version
+	return nil
+}
+
+// HasPnpmLockfile returns true if a pnpm-lock.yaml exists
+func (p *Parser) HasPnpmLockfile() bool {
+	_, err := os.Stat(filepath.Join(p.projectDir, "pnpm-lock.yaml"))
+	return err == nil
+}
+
+// ParsePnpmLockfile reads and parses pnpm-lock.yaml
+func (p *Parser) ParsePnpmLockfile() (*PnpmLock, error) {
+	data, err := os.ReadFile(filepath.Join(p.projectDir, "pnpm-lock.yaml"))
+	if err != nil {
+		return nil, errors.ManifestError("failed to read pnpm-lock.yaml", err)
+	}
+
+	var lockfile PnpmLock
+	if err := yaml.Unmarshal(data, &lockfile); err != nil {
+		return nil, errors.ManifestError("failed to parse pnpm-lock.yaml", err)
+	}
+
+	return &lockfile, nil
+}
+
+// ResolvedPackages returns the packages of a class in the lockfile with
+// their exact version, deduplicated across peer-dependency variants. pnpm
+// records resolved peers as ordinary dependencies, so no package is
+// needed only as a peer.
+func (l *PnpmLock) ResolvedPackages(class DependencyClass) []Package {
+	legacy := l.isLegacy()
+	seen := make(map[string]bool)
+	var packages []Package
+
+	addPackage := func(key string, locked lockedClass) {
+		name, version := parsePnpmKey(key, legacy)
+		if name == "" || version == "" {
+			return
+		}
+		if !class.includes(locked) {
+			return
+		}
+		id := name + "@" + version
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		packages = append(packages, Package{
+			Name:      name,
+			Version:   version,
+			Ecosystem: "npm",
+		})
+	}
+
+	// v9 moved the dependency graph into snapshots and dropped the dev and
+	// optional flags, so they have to be derived from the importers
+	if len(l.Snapshots) > 0 {
+		var classes map[string]lockedClass
+		if class != DependenciesAll {
+			classes = l.graph().classify()
+		}
+		for key := range l.Snapshots {
+			addPackage(key, classOf(classes, key))
+		}
+		return packages
+	}
+
+	for key, pkg := range l.Packages {
+		addPackage(key, lockedClass{Dev: pkg.Dev != nil && *pkg.Dev, Optional: pkg.Optional})
+	}
+	return packages
+}
+
+// graph returns the v9 snapshot graph, rooted at every importer's direct
+// dependencies
+func (l *PnpmLock) graph() lockGraph[string] {
+	var g lockGraph[string]
+	for _, importer := range l.Importers {
+		for name, ref := range importer.Dependencies {
+			g.prod = append(g.prod, name+"@"+ref.Version)
+		}
+		for name, ref := range importer.DevDependencies {
+			g.dev = append(g.dev, name+"@"+ref.Version)
+		}
+		for name, ref := range importer.OptionalDependencies {
+			g.optional = append(g.optional, name+"@"+ref.Version)
+		}
+	}
+
+	keys := func(deps map[string]string) []string {
+		out := make([]string, 0, len(deps))
+		for name, version := range deps {
+			out = append(out, name+"@"+version)
+		}
+		return out
+	}
+	g.edges = func(key string) (deps, optional, peer []string) {
+		snapshot := l.Snapshots[key]
+		return keys(snapshot.Dependencies), keys(snapshot.OptionalDependencies), nil
+	}
+	return g
+}
+
+// parsePnpmKey extracts the package name and exact version from a pnpm
+// packages/snapshots key, dropping peer-dependency suffixes. Lockfile v5
+// separates name and version with a slash instead of an @.
+// Examples:
+//   - "/lodash@4.17.21" -> "lodash", "4.17.21" (v6)
+//   - "/@babel/core@7.24.0(supports-color@5.5.0)" -> "@babel/core", "7.24.0" (v6)
+//   - "@babel/core@7.24.0" -> "@babel/core", "7.24.0" (v9)
+//   - "/lodash/4.17.21" -> "lodash", "4.17.21" (v5)
+//   - "/react-dom/18.2.0_react@18.2.0" -> "react-dom", "18.2.0" (v5)
+func parsePnpmKey(key string, legacy bool) (name, version string) {
+	key = strings.TrimPrefix(key, "/")
+
+	if legacy {
+		// Scoped names span two path segments
+		segments := 1
+		if strings.HasPrefix(key, "@") {
+			segments = 2
+		}
+		parts := strings.SplitN(key, "/", segments+1)
+		if len(parts) != segments+1 {
+			return "", ""
+		}
+		name = strings.Join(parts[:segments], "/")
+		version = parts[segments]
+		if idx := strings.IndexAny(version, "_("); idx != -1 {
+			version = version[:idx]
+		}
+	} else {
+		// Drop "(peer@1.0.0)" suffixes
+		if idx := strings.Index(key, "("); idx != -1 {
+			key = key[:idx]
+		}
+
+		// The last @ separates the version; index 0 would be a bare scope
+		idx := strings.LastIndex(key, "@")
+		if idx <= 0 {
+			return "", ""
+		}
+		name, version = key[:idx], key[idx+1:]
+	}
+
+	// Non-registry sources (link:, file:, tarball URLs) have no semver version
+	if strings.Contains(version, ":") || strings.Contains(name, ":") {
+		return "", ""
+	}
+
+	return name, version
+}
+
+// isLegacy returns true for lockfile v5 and older
+func (l *PnpmLock) isLegacy() bool {
+	major, err := strconv.Atoi(strings.SplitN(l.LockfileVersion, ".", 2)[0])
+	return err == nil && major < 6
+}
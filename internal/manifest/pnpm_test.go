@@ -0,0 +1,243 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+func TestParsePnpmKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		legacy  bool
+		name    string
+		version string
+	}{
+		// v6 keys
+		{"/lodash@4.17.21", false, "lodash", "4.17.21"},
+		{"/@babel/core@7.24.0", false, "@babel/core", "7.24.0"},
+		{"/@babel/core@7.24.0(supports-color@5.5.0)", false, "@babel/core", "7.24.0"},
+		{"/react-dom@18.2.0(react@18.2.0)", false, "react-dom", "18.2.0"},
+
+		// v9 keys
+		{"lodash@4.17.21", false, "lodash", "4.17.21"},
+		{"@vitejs/plugin-react@4.2.1(vite@5.1.0(@types/node@20.11.0))", false, "@vitejs/plugin-react", "4.2.1"},
+
+		// v5 keys
+		{"/lodash/4.17.21", true, "lodash", "4.17.21"},
+		{"/@babel/core/7.24.0", true, "@babel/core", "7.24.0"},
+		{"/react-dom/18.2.0_react@18.2.0", true, "react-dom", "18.2.0"},
+
+		// Non-registry sources are skipped
+		{"/my-lib@file:../my-lib", false, "", ""},
+		{"@scope", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			name, version := parsePnpmKey(tt.key, tt.legacy)
+			if name != tt.name || version != tt.version {
+				t.Errorf("parsePnpmKey(%q) = %q, %q, want %q, %q", tt.key, name, version, tt.name, tt.version)
+			}
+		})
+	}
+}
+
+const pnpmLockV6 = `lockfileVersion: '6.0'
+
+dependencies:
+  react-dom:
+    specifier: ^18.2.0
+    version: 18.2.0(react@18.2.0)
+
+devDependencies:
+  '@babel/core':
+    specifier: ^7.24.0
+    version: 7.24.0
+
+packages:
+
+  /@babel/core@7.24.0:
+    resolution: {integrity: sha512-abc}
+    dev: true
+
+  /react@18.2.0:
+    resolution: {integrity: sha512-def}
+    dev: false
+
+  /react-dom@18.2.0(react@18.2.0):
+    resolution: {integrity: sha512-ghi}
+    dev: false
+`
+
+const pnpmLockV9 = `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react-dom:
+        specifier: ^18.2.0
+        version: 18.2.0(react@18.2.0)
+    devDependencies:
+      '@babel/core':
+        specifier: ^7.24.0
+        version: 7.24.0
+
+packages:
+
+  '@babel/core@7.24.0':
+    resolution: {integrity: sha512-abc}
+
+  react@18.2.0:
+    resolution: {integrity: sha512-def}
+
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-ghi}
+
+snapshots:
+
+  '@babel/core@7.24.0': {}
+
+  react@18.2.0: {}
+
+  react-dom@18.2.0(react@18.2.0):
+    dependencies:
+      react: 18.2.0
+`
+
+func TestPnpmResolvedPackages(t *testing.T) {
+	for _, lock := range []struct {
+		name    string
+		content string
+	}{
+		{"v6", pnpmLockV6},
+		{"v9", pnpmLockV9},
+	} {
+		t.Run(lock.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "pnpm-lock.yaml"), []byte(lock.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := NewParser(dir).ParsePnpmLockfile()
+			if err != nil {
+				t.Fatalf("ParsePnpmLockfile returned error: %v", err)
+			}
+
+			all := packageIDs(parsed.ResolvedPackages(DependenciesAll))
+			wantAll := []string{"@babel/core@7.24.0", "react-dom@18.2.0", "react@18.2.0"}
+			if !slices.Equal(all, wantAll) {
+				t.Errorf("with dev = %v, want %v", all, wantAll)
+			}
+
+			prod := packageIDs(parsed.ResolvedPackages(DependenciesProd))
+			wantProd := []string{"react-dom@18.2.0", "react@18.2.0"}
+			if !slices.Equal(prod, wantProd) {
+				t.Errorf("without dev = %v, want %v", prod, wantProd)
+			}
+
+			dev := packageIDs(parsed.ResolvedPackages(DependenciesDev))
+			wantDev := []string{"@babel/core@7.24.0"}
+			if !slices.Equal(dev, wantDev) {
+				t.Errorf("dev only = %v, want %v", dev, wantDev)
+			}
+		})
+	}
+}
+
+func packageIDs(packages []Package) []string {
+	var ids []string
+	for _, p := range packages {
+		ids = append(ids, p.Name+"@"+p.Version)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+const pnpmLockClassesV9 = `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react:
+        specifier: ^18.2.0
+        version: 18.2.0
+    devDependencies:
+      jest:
+        specifier: ^29.0.0
+        version: 29.7.0
+    optionalDependencies:
+      fsevents:
+        specifier: ^2.3.0
+        version: 2.3.3
+
+snapshots:
+
+  fsevents@2.3.3: {}
+
+  jest@29.7.0:
+    dependencies:
+      fsevents: 2.3.3
+
+  loose-envify@1.4.0: {}
+
+  react@18.2.0:
+    optionalDependencies:
+      loose-envify: 1.4.0
+`
+
+const pnpmLockClassesV6 = `lockfileVersion: '6.0'
+
+packages:
+
+  /fsevents@2.3.3:
+    dev: false
+    optional: true
+
+  /jest@29.7.0:
+    dev: true
+
+  /react@18.2.0:
+    dev: false
+`
+
+// TestPnpmDependencyClasses selects optional packages, derived from the
+// v9 graph or read from the flags of older lockfiles
+func TestPnpmDependencyClasses(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		content string
+		want    map[DependencyClass][]string
+	}{
+		{"v9", pnpmLockClassesV9, map[DependencyClass][]string{
+			DependenciesProd:     {"react@18.2.0"},
+			DependenciesOptional: {"fsevents@2.3.3", "loose-envify@1.4.0"},
+			DependenciesDev:      {"fsevents@2.3.3", "jest@29.7.0"},
+			DependenciesPeer:     nil,
+		}},
+		{"v6", pnpmLockClassesV6, map[DependencyClass][]string{
+			DependenciesProd:     {"react@18.2.0"},
+			DependenciesOptional: {"fsevents@2.3.3"},
+			DependenciesDev:      {"jest@29.7.0"},
+			DependenciesPeer:     nil,
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var lock PnpmLock
+			if err := yaml.Unmarshal([]byte(tt.content), &lock); err != nil {
+				t.Fatal(err)
+			}
+			for class, want := range tt.want {
+				if got := packageIDs(lock.ResolvedPackages(class)); !slices.Equal(got, want) {
+					t.Errorf("class %d = %v, want %v", class, got, want)
+				}
+			}
+		})
+	}
+}
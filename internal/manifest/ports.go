@@ -1,8 +1,12 @@
 package manifest
 
 import (
+	"maps"
+	"path"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 // FrameworkPort maps frameworks to their default development ports
@@ -41,69 +45,226 @@ var FrameworkPort = map[string]int{
 	"parcel":              1234,
 }
 
-// DetectPort attempts to detect the development server port from package.json
-func (p *Parser) DetectPort() int {
+// portPatterns match a port a command sets explicitly
+var portPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`--port[=\s]+(\d+)`),
+	regexp.MustCompile(`-p[=\s]+(\d+)`),
+	regexp.MustCompile(`PORT=(\d+)`),
+	regexp.MustCompile(`:(\d{4,5})`), // matches :3000, :8080, etc.
+}
+
+// frameworkPriority is the order in which frameworks found in the
+// dependencies contribute their default ports, more specific ones first
+var frameworkPriority = []string{
+	"next", "remix", "gatsby", "nuxt", "@sveltejs/kit", "astro",
+	"@angular/cli", "vite", "@vitejs/plugin-vue", "@vitejs/plugin-react",
+	"react-scripts", "@vue/cli-service", "parcel", "webpack-dev-server",
+	"express", "fastify", "koa", "hono",
+}
+
+// binaryFrameworks maps dev server binaries named differently from their
+// package to the package; other binaries are looked up in FrameworkPort
+// as they are
+var binaryFrameworks = map[string]string{
+	"ng":   "@angular/cli",
+	"nuxi": "nuxt",
+}
+
+// commandPrefixes are wrappers skipped to find the binary a command runs
+var commandPrefixes = map[string]bool{
+	"cross-env": true, "env": true, "npx": true, "bunx": true, "dotenv": true,
+}
+
+// scriptRunners run other scripts named by their arguments
+var scriptRunners = map[string]bool{
+	"npm-run-all": true, "npm-run-all2": true, "run-p": true, "run-s": true,
+}
+
+// packageManagers run another script with "run <script>" or "<script>"
+var packageManagers = map[string]bool{
+	"npm": true, "pnpm": true, "yarn": true, "bun": true,
+}
+
+// DetectPorts returns the ports a development script listens on: every
+// port its commands set explicitly, and the default port of each dev
+// server it starts without one. Scripts it runs through npm-run-all,
+// concurrently or "npm run" are followed one level deep. If the script
+// names no port, the default ports of the frameworks in the dependencies
+// are returned instead.
+func (p *Parser) DetectPorts(script string) []int {
 	pkg, err := p.ParseManifest()
 	if err != nil {
-		return 0
+		return nil
 	}
 
-	// First, check scripts for explicit port configurations
-	if port := detectPortFromScripts(pkg.Scripts); port > 0 {
-		return port
+	d := portDetector{scripts: pkg.Scripts}
+	d.script(script, 0)
+	if len(d.ports) > 0 {
+		return d.ports
 	}
 
-	// Then, check dependencies for known frameworks
-	allDeps := make(map[string]string)
-	for k, v := range pkg.Dependencies {
-		allDeps[k] = v
+	for _, framework := range frameworkPriority {
+		_, dep := pkg.Dependencies[framework]
+		_, devDep := pkg.DevDependencies[framework]
+		if dep || devDep {
+			d.add(FrameworkPort[framework])
+		}
 	}
-	for k, v := range pkg.DevDependencies {
-		allDeps[k] = v
+	return d.ports
+}
+
+// portDetector collects the ports of a script and the scripts it runs
+type portDetector struct {
+	scripts map[string]string
+	ports   []int
+}
+
+// add records a port once
+func (d *portDetector) add(port int) {
+	if port > 0 && port < 65536 && !slices.Contains(d.ports, port) {
+		d.ports = append(d.ports, port)
 	}
+}
 
-	// Check in priority order (more specific frameworks first)
-	priorityOrder := []string{
-		"next", "remix", "gatsby", "nuxt", "@sveltejs/kit", "astro",
-		"@angular/cli", "vite", "@vitejs/plugin-vue", "@vitejs/plugin-react",
-		"react-scripts", "@vue/cli-service", "parcel", "webpack-dev-server",
-		"express", "fastify", "koa", "hono",
+// script collects the ports of a script's commands; depth counts the
+// scripts followed to reach it
+func (d *portDetector) script(name string, depth int) {
+	command, ok := d.scripts[name]
+	if !ok {
+		return
+	}
+	for _, words := range splitCommands(command) {
+		d.command(words, depth)
 	}
+}
 
-	for _, framework := range priorityOrder {
-		if _, exists := allDeps[framework]; exists {
-			if port, ok := FrameworkPort[framework]; ok {
-				return port
+// command collects the ports of one command
+func (d *portDetector) command(words []string, depth int) {
+	// Skip variable assignments and wrappers such as cross-env
+	i := 0
+	for i < len(words) && (strings.Contains(words[i], "=") || commandPrefixes[words[i]]) {
+		i++
+	}
+	if i == len(words) {
+		d.explicitPorts(words)
+		return
+	}
+	bin, args := words[i], words[i+1:]
+
+	switch {
+	case bin == "concurrently":
+		d.explicitPorts(words[:i])
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+			if ref, ok := strings.CutPrefix(arg, "npm:"); ok {
+				d.reference(ref, depth)
+				continue
+			}
+			for _, words := range splitCommands(arg) {
+				d.command(words, depth)
 			}
 		}
+	case scriptRunners[bin]:
+		d.explicitPorts(words[:i])
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-") {
+				d.reference(arg, depth)
+			}
+		}
+	case packageManagers[bin]:
+		d.explicitPorts(words[:i])
+		if len(args) > 0 && (args[0] == "run" || args[0] == "run-script") {
+			args = args[1:]
+		}
+		if len(args) > 0 {
+			d.reference(args[0], depth)
+		}
+	case !d.explicitPorts(words) && (len(args) == 0 || args[0] != "build"):
+		// A dev server started without a port listens on its default
+		framework := bin
+		if f, ok := binaryFrameworks[bin]; ok {
+			framework = f
+		}
+		d.add(FrameworkPort[framework])
 	}
+}
 
-	return 0
+// explicitPorts records every port set in words and returns true if there
+// was one
+func (d *portDetector) explicitPorts(words []string) bool {
+	found := false
+	for _, pattern := range portPatterns {
+		for _, m := range pattern.FindAllStringSubmatch(strings.Join(words, " "), -1) {
+			if port, err := strconv.Atoi(m[1]); err == nil {
+				d.add(port)
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// reference follows the scripts a pattern such as dev:web or dev:* names,
+// unless the script being read was itself reached through a reference
+func (d *portDetector) reference(pattern string, depth int) {
+	if depth > 0 {
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(d.scripts)) {
+		// npm-run-all globs treat : like / in a path
+		if ok, _ := path.Match(strings.ReplaceAll(pattern, ":", "/"), strings.ReplaceAll(name, ":", "/")); ok {
+			d.script(name, depth+1)
+		}
+	}
 }
 
-// detectPortFromScripts looks for port patterns in npm scripts
-func detectPortFromScripts(scripts map[string]string) int {
-	// Common dev script names
-	devScripts := []string{"dev", "start", "serve", "develop"}
+// splitCommands splits a script into the words of each command it runs,
+// separated by &&, ||, ;, & or |. Quoted words are kept whole, without
+// their quotes.
+func splitCommands(script string) [][]string {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
 
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`--port[=\s]+(\d+)`),
-		regexp.MustCompile(`-p[=\s]+(\d+)`),
-		regexp.MustCompile(`PORT=(\d+)`),
-		regexp.MustCompile(`:(\d{4,5})`), // matches :3000, :8080, etc.
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
 	}
 
-	for _, scriptName := range devScripts {
-		if script, ok := scripts[scriptName]; ok {
-			for _, pattern := range patterns {
-				if matches := pattern.FindStringSubmatch(script); len(matches) > 1 {
-					if port, err := strconv.Atoi(matches[1]); err == nil && port > 0 && port < 65536 {
-						return port
-					}
-				}
+	for _, r := range script {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
 			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			endWord()
+		case r == '&' || r == '|' || r == ';':
+			endCommand()
+		default:
+			word.WriteRune(r)
+			inWord = true
 		}
 	}
-
-	return 0
+	endCommand()
+	return commands
 }
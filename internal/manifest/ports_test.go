@@ -0,0 +1,113 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		scripts map[string]string
+		deps    map[string]string
+		script  string
+		want    []int
+	}{
+		{
+			name:    "explicit port",
+			scripts: map[string]string{"dev": "next dev -p 4000"},
+			deps:    map[string]string{"next": "14.0.0"},
+			script:  "dev",
+			want:    []int{4000},
+		},
+		{
+			name:    "concurrently with a default and an explicit port",
+			scripts: map[string]string{"dev": `concurrently "next dev" "node server.js --port 4000"`},
+			deps:    map[string]string{"next": "14.0.0", "express": "4.0.0"},
+			script:  "dev",
+			want:    []int{3000, 4000},
+		},
+		{
+			name:    "every match in a command",
+			scripts: map[string]string{"dev": "PORT=3001 node server.js --inspect=0.0.0.0:9229"},
+			script:  "dev",
+			want:    []int{3001, 9229},
+		},
+		{
+			name: "npm-run-all glob one level deep",
+			scripts: map[string]string{
+				"dev":      "npm-run-all --parallel dev:*",
+				"dev:web":  "vite",
+				"dev:api":  "PORT=8787 wrangler dev",
+				"dev:docs": "npm run docs",
+				"docs":     "astro dev",
+			},
+			deps:   map[string]string{"vite": "5.0.0"},
+			script: "dev",
+			want:   []int{8787, 5173},
+		},
+		{
+			name: "npm: references and npm run",
+			scripts: map[string]string{
+				"dev":     `concurrently -k "npm:dev:api" "npm run dev:web"`,
+				"dev:web": "ng serve",
+				"dev:api": "nodemon api.js --port=4001",
+			},
+			script: "dev",
+			want:   []int{4001, 4200},
+		},
+		{
+			name:    "build commands have no port",
+			scripts: map[string]string{"start": "vite build && node server.js -p 8080"},
+			script:  "start",
+			want:    []int{8080},
+		},
+		{
+			name:    "framework defaults when the script names no port",
+			scripts: map[string]string{"dev": "node scripts/dev.mjs"},
+			deps:    map[string]string{"express": "4.0.0", "vite": "5.0.0", "@vitejs/plugin-react": "4.0.0"},
+			script:  "dev",
+			want:    []int{5173, 3000},
+		},
+		{
+			name:    "missing script",
+			scripts: map[string]string{},
+			script:  "dev",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			data, _ := json.Marshal(map[string]any{"scripts": tt.scripts, "dependencies": tt.deps})
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := NewParser(dir).DetectPorts(tt.script); !slices.Equal(got, tt.want) {
+				t.Errorf("DetectPorts(%q) = %v, want %v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	got := splitCommands(`cross-env NODE_ENV=dev concurrently "next dev" 'tsc -w' && echo done; a|b`)
+	want := [][]string{
+		{"cross-env", "NODE_ENV=dev", "concurrently", "next dev", "tsc -w"},
+		{"echo", "done"},
+		{"a"},
+		{"b"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("splitCommands() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("command %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// Workspace is a package in an npm, Yarn or bun workspace
+type Workspace struct {
+	Name string
+
+	// Dir is the workspace directory relative to the project, with
+	// forward slashes, e.g. "packages/api"
+	Dir string
+
+	Manifest *Manifest
+}
+
+// WorkspacePatterns returns the globs of the package.json workspaces field,
+// which is either an array or an object with a packages array
+func (m *Manifest) WorkspacePatterns() []string {
+	if len(m.Workspaces) == 0 {
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(m.Workspaces, &patterns); err == nil {
+		return patterns
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(m.Workspaces, &object); err == nil {
+		return object.Packages
+	}
+	return nil
+}
+
+// Workspaces returns the workspace packages matched by the root
+// package.json's workspaces globs, sorted by directory. Matched
+// directories without a package.json are skipped.
+func (p *Parser) Workspaces() ([]Workspace, error) {
+	root, err := p.ParseManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var workspaces []Workspace
+	for _, pattern := range root.WorkspacePatterns() {
+		matches, err := filepath.Glob(filepath.Join(p.projectDir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, errors.ManifestError(fmt.Sprintf("invalid workspaces pattern %q", pattern), err)
+		}
+		for _, dir := range matches {
+			rel, err := filepath.Rel(p.projectDir, dir)
+			if err != nil || seen[rel] {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+			if err != nil {
+				continue
+			}
+			var m Manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				return nil, errors.ManifestError("failed to parse "+filepath.Join(rel, "package.json"), err)
+			}
+
+			seen[rel] = true
+			name := m.Name
+			if name == "" {
+				name = filepath.Base(dir)
+			}
+			workspaces = append(workspaces, Workspace{Name: name, Dir: filepath.ToSlash(rel), Manifest: &m})
+		}
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Dir < workspaces[j].Dir })
+	return workspaces, nil
+}
+
+// FindWorkspace returns the workspace with the given name or directory
+func FindWorkspace(workspaces []Workspace, name string) (Workspace, bool) {
+	for _, ws := range workspaces {
+		if ws.Name == name || ws.Dir == strings.TrimSuffix(filepath.ToSlash(name), "/") {
+			return ws, true
+		}
+	}
+	return Workspace{}, false
+}
+
+// WorkspaceDependencies returns the direct dependencies of one workspace,
+// at the versions package-lock.json records for it when there is one
+func (p *Parser) WorkspaceDependencies(ws Workspace, class DependencyClass) ([]Package, error) {
+	workspaces, err := p.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	internal := workspaceNames(workspaces)
+
+	lockfile, _ := p.ParseLockfile()
+
+	var packages []Package
+	for _, pkg := range ws.Manifest.packages(class) {
+		if internal[pkg.Name] {
+			continue
+		}
+		if lockfile != nil {
+			if locked, ok := lockfile.lookup(ws.Dir, pkg.Name); ok {
+				pkg.Version = locked.Version
+				pkg.Resolved = locked.Resolved
+				pkg.Integrity = locked.Integrity
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// lookup returns the lockfile entry a workspace resolves a dependency to:
+// the workspace's own node_modules first, then the hoisted copy
+func (l *PackageLock) lookup(dir, name string) (PackageLockPkg, bool) {
+	for _, path := range []string{dir + "/node_modules/" + name, "node_modules/" + name} {
+		if entry, ok := l.Packages[path]; ok && entry.Version != "" {
+			return entry, true
+		}
+	}
+	return PackageLockPkg{}, false
+}
+
+// workspaceNames returns the set of workspace package names
+func workspaceNames(workspaces []Workspace) map[string]bool {
+	names := make(map[string]bool, len(workspaces))
+	for _, ws := range workspaces {
+		names[ws.Name] = true
+	}
+	return names
+}
+
+// withWorkspaces adds the workspaces' dependencies that are not already in
+// packages, skipping references to other workspaces
+func withWorkspaces(packages []Package, workspaces []Workspace, class DependencyClass) []Package {
+	internal := workspaceNames(workspaces)
+	have := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		have[pkg.Name] = true
+	}
+
+	for _, ws := range workspaces {
+		for _, pkg := range ws.Manifest.packages(class) {
+			if internal[pkg.Name] || have[pkg.Name] {
+				continue
+			}
+			have[pkg.Name] = true
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
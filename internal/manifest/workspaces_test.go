@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWorkspacePatterns(t *testing.T) {
+	tests := []struct {
+		json string
+		want []string
+	}{
+		{`{}`, nil},
+		{`{"workspaces": ["packages/*", "tools/cli"]}`, []string{"packages/*", "tools/cli"}},
+		{`{"workspaces": {"packages": ["apps/*"], "nohoist": ["**/react"]}}`, []string{"apps/*"}},
+	}
+
+	for _, tt := range tests {
+		var m Manifest
+		if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.WorkspacePatterns(); !slices.Equal(got, tt.want) {
+			t.Errorf("WorkspacePatterns(%s) = %v, want %v", tt.json, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":                 `{"name": "root", "workspaces": ["packages/*"], "devDependencies": {"typescript": "^5.4.0"}}`,
+		"packages/api/package.json":    `{"name": "@acme/api", "dependencies": {"express": "^4.18.2", "@acme/shared": "workspace:*"}}`,
+		"packages/shared/package.json": `{"name": "@acme/shared", "dependencies": {"zod": "3.23.8"}}`,
+		"packages/notes/README.md":     `not a workspace`,
+		"package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "root"},
+    "packages/api": {"name": "@acme/api", "version": "1.0.0"},
+    "node_modules/@acme/api": {"resolved": "packages/api", "link": true},
+    "node_modules/express": {"version": "4.18.2"},
+    "packages/api/node_modules/express": {"version": "4.19.0"},
+    "node_modules/typescript": {"version": "5.4.5", "dev": true}
+  }
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parser := NewParser(dir)
+	workspaces, err := parser.Workspaces()
+	if err != nil {
+		t.Fatalf("Workspaces() error = %v", err)
+	}
+	var names []string
+	for _, ws := range workspaces {
+		names = append(names, ws.Name+"="+ws.Dir)
+	}
+	if want := []string{"@acme/api=packages/api", "@acme/shared=packages/shared"}; !slices.Equal(names, want) {
+		t.Fatalf("Workspaces() = %v, want %v", names, want)
+	}
+
+	// The lockfile has no entry for zod, so it comes from the manifest
+	packages, err := parser.GetDependencies(true)
+	if err != nil {
+		t.Fatalf("GetDependencies() error = %v", err)
+	}
+	if got, want := packageIDs(packages), []string{"express@4.18.2", "express@4.19.0", "typescript@5.4.5", "zod@3.23.8"}; !slices.Equal(got, want) {
+		t.Errorf("GetDependencies() = %v, want %v", got, want)
+	}
+
+	api, ok := FindWorkspace(workspaces, "packages/api/")
+	if !ok {
+		t.Fatal("FindWorkspace(packages/api/) not found")
+	}
+	packages, err = parser.WorkspaceDependencies(api, DependenciesAll)
+	if err != nil {
+		t.Fatalf("WorkspaceDependencies() error = %v", err)
+	}
+	if got, want := packageIDs(packages), []string{"express@4.19.0"}; !slices.Equal(got, want) {
+		t.Errorf("WorkspaceDependencies() = %v, want %v", got, want)
+	}
+}
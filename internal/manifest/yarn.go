@@ -0,0 +1,289 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+
+	"github.com/positronico/snapem/internal/errors"
+)
+
+// YarnLock represents a parsed yarn.lock in either the classic (v1) or the
+// berry (Yarn 2+, YAML) format
+type YarnLock struct {
+	Berry   bool
+	Entries []YarnEntry
+
+	// byDescriptor maps "name@range" descriptors to their entry index
+	byDescriptor map[string]int
+}
+
+// YarnEntry is a single resolved package in yarn.lock
+type YarnEntry struct {
+	Name                 string
+	Version              string
+	Dependencies         map[string]string
+	OptionalDependencies map[string]string
+
+	// local marks workspace, link, portal and file entries
+	local bool
+}
+
+// yarnBerryEntry is the YAML shape of a berry lockfile entry
+type yarnBerryEntry struct {
+	Version              string            `yaml:"version"`
+	Resolution           string            `yaml:"resolution"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// ParseYarnLockfile reads and parses yarn.lock
+func (p *Parser) ParseYarnLockfile() (*YarnLock, error) {
+	data, err := os.ReadFile(filepath.Join(p.projectDir, "yarn.lock"))
+	if err != nil {
+		return nil, errors.ManifestError("failed to read yarn.lock", err)
+	}
+
+	lock := &YarnLock{byDescriptor: make(map[string]int)}
+
+	// Berry lockfiles carry a __metadata block; classic ones never do
+	if bytes.Contains(data, []byte("\n__metadata:")) || bytes.HasPrefix(data, []byte("__metadata:")) {
+		lock.Berry = true
+		err = lock.parseBerry(data)
+	} else {
+		err = lock.parseClassic(data)
+	}
+	if err != nil {
+		return nil, errors.ManifestError("failed to parse yarn.lock", err)
+	}
+
+	return lock, nil
+}
+
+// parseBerry decodes the YAML format used by Yarn 2 and later
+func (l *YarnLock) parseBerry(data []byte) error {
+	var raw map[string]yarnBerryEntry
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for key, entry := range raw {
+		if key == "__metadata" {
+			continue
+		}
+
+		_, resolution := splitYarnDescriptor(entry.Resolution)
+		l.add(splitYarnKey(key), YarnEntry{
+			Version:              entry.Version,
+			Dependencies:         entry.Dependencies,
+			OptionalDependencies: entry.OptionalDependencies,
+			local:                isLocalYarnRange(resolution),
+		})
+	}
+
+	return nil
+}
+
+// parseClassic reads the indentation-based format used by Yarn 1:
+//
+//	"@babel/core@^7.0.0", "@babel/core@^7.24.0":
+//	  version "7.24.0"
+//	  dependencies:
+//	    debug "^4.1.0"
+func (l *YarnLock) parseClassic(data []byte) error {
+	var (
+		descriptors []string
+		entry       YarnEntry
+		deps        *map[string]string
+	)
+
+	flush := func() {
+		if len(descriptors) > 0 {
+			l.add(descriptors, entry)
+		}
+		descriptors = nil
+		entry = YarnEntry{}
+		deps = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case indent == 0:
+			flush()
+			descriptors = splitYarnKey(strings.TrimSuffix(trimmed, ":"))
+
+		case indent == 2:
+			deps = nil
+			key, value, _ := strings.Cut(trimmed, " ")
+			switch key {
+			case "version":
+				entry.Version = unquoteYarn(value)
+			case "dependencies:":
+				deps = &entry.Dependencies
+			case "optionalDependencies:":
+				deps = &entry.OptionalDependencies
+			}
+
+		case deps != nil:
+			name, rng, ok := strings.Cut(trimmed, " ")
+			if !ok {
+				continue
+			}
+			if *deps == nil {
+				*deps = make(map[string]string)
+			}
+			(*deps)[unquoteYarn(name)] = unquoteYarn(rng)
+		}
+	}
+	flush()
+
+	return scanner.Err()
+}
+
+// add records an entry under all of its descriptors
+func (l *YarnLock) add(descriptors []string, entry YarnEntry) {
+	if len(descriptors) == 0 {
+		return
+	}
+
+	name, rng := splitYarnDescriptor(descriptors[0])
+	entry.Name = name
+	if isLocalYarnRange(rng) {
+		entry.local = true
+	}
+
+	l.Entries = append(l.Entries, entry)
+	for _, d := range descriptors {
+		l.byDescriptor[d] = len(l.Entries) - 1
+	}
+}
+
+// lookup finds the entry a dependency range resolved to. Berry descriptors
+// carry an explicit protocol that package.json ranges usually omit.
+func (l *YarnLock) lookup(name, rng string) (int, bool) {
+	if idx, ok := l.byDescriptor[name+"@"+rng]; ok {
+		return idx, true
+	}
+	if l.Berry {
+		idx, ok := l.byDescriptor[name+"@npm:"+rng]
+		return idx, ok
+	}
+	return 0, false
+}
+
+// ResolvedPackages returns the registry packages of a class in the
+// lockfile with their exact version. Yarn does not mark dev, optional or
+// peer dependencies, so packages are classified by walking the lockfile
+// from the manifest's direct dependencies. Yarn never installs a package
+// for another's peer dependency, so only the manifest's own peers can be
+// peer-only.
+func (l *YarnLock) ResolvedPackages(manifest *Manifest, class DependencyClass) []Package {
+	var classes map[int]lockedClass
+	if class != DependenciesAll {
+		classes = l.graph(manifest).classify()
+	}
+
+	seen := make(map[string]bool)
+	var packages []Package
+	for i, entry := range l.Entries {
+		if entry.local || entry.Name == "" || entry.Version == "" {
+			continue
+		}
+		if classes != nil && !class.includes(classOf(classes, i)) {
+			continue
+		}
+		id := entry.Name + "@" + entry.Version
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		packages = append(packages, Package{
+			Name:      entry.Name,
+			Version:   entry.Version,
+			Ecosystem: "npm",
+		})
+	}
+
+	return packages
+}
+
+// graph returns the lockfile's dependency graph, rooted at the manifest's
+// direct dependencies
+func (l *YarnLock) graph(manifest *Manifest) lockGraph[int] {
+	resolve := func(deps map[string]string) []int {
+		var out []int
+		for name, rng := range deps {
+			if idx, ok := l.lookup(name, rng); ok {
+				out = append(out, idx)
+			}
+		}
+		return out
+	}
+
+	return lockGraph[int]{
+		prod:     resolve(manifest.Dependencies),
+		dev:      resolve(manifest.DevDependencies),
+		optional: resolve(manifest.OptionalDependencies),
+		peer:     resolve(manifest.PeerDependencies),
+		edges: func(idx int) (deps, optional, peer []int) {
+			entry := l.Entries[idx]
+			return resolve(entry.Dependencies), resolve(entry.OptionalDependencies), nil
+		},
+	}
+}
+
+// splitYarnKey splits an entry key into its descriptors
+// e.g., `"lodash@^4.17.0", lodash@^4.17.21` -> ["lodash@^4.17.0", "lodash@^4.17.21"]
+func splitYarnKey(key string) []string {
+	var descriptors []string
+	for _, part := range strings.Split(key, ",") {
+		if d := unquoteYarn(strings.TrimSpace(part)); d != "" {
+			descriptors = append(descriptors, d)
+		}
+	}
+	return descriptors
+}
+
+// splitYarnDescriptor separates a descriptor into name and range
+// e.g., "@babel/core@npm:^7.24.0" -> "@babel/core", "npm:^7.24.0"
+func splitYarnDescriptor(descriptor string) (name, rng string) {
+	// Index 0 would be the scope prefix, not the separator
+	idx := strings.Index(descriptor[min(1, len(descriptor)):], "@")
+	if idx == -1 {
+		return descriptor, ""
+	}
+	idx++
+	return descriptor[:idx], descriptor[idx+1:]
+}
+
+// isLocalYarnRange returns true for ranges that point inside the project
+func isLocalYarnRange(rng string) bool {
+	for _, prefix := range []string{"workspace:", "link:", "portal:", "file:"} {
+		if strings.HasPrefix(rng, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unquoteYarn strips the double quotes yarn puts around some values
+func unquoteYarn(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
@@ -0,0 +1,265 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const yarnManifest = `{
+  "name": "app",
+  "dependencies": {"react-dom": "^18.2.0", "@scope/lib": "^1.0.0"},
+  "devDependencies": {"@babel/core": "^7.24.0"}
+}`
+
+const yarnLockClassic = `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/core@^7.24.0":
+  version "7.24.0"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.24.0.tgz"
+  integrity sha512-abc
+  dependencies:
+    debug "^4.1.0"
+
+"@scope/lib@^1.0.0", "@scope/lib@^1.2.0":
+  version "1.2.3"
+  resolved "https://registry.yarnpkg.com/@scope/lib/-/lib-1.2.3.tgz"
+
+debug@^4.1.0:
+  version "4.3.4"
+  resolved "https://registry.yarnpkg.com/debug/-/debug-4.3.4.tgz"
+
+react-dom@^18.2.0:
+  version "18.2.0"
+  resolved "https://registry.yarnpkg.com/react-dom/-/react-dom-18.2.0.tgz"
+  dependencies:
+    react "^18.2.0"
+
+react@^18.2.0:
+  version "18.2.0"
+  resolved "https://registry.yarnpkg.com/react/-/react-18.2.0.tgz"
+`
+
+const yarnLockBerry = `# This file is generated by running "yarn install" inside your project.
+
+__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"@babel/core@npm:^7.24.0":
+  version: 7.24.0
+  resolution: "@babel/core@npm:7.24.0"
+  dependencies:
+    debug: "npm:^4.1.0"
+  languageName: node
+  linkType: hard
+
+"@scope/lib@npm:^1.0.0, @scope/lib@npm:^1.2.0":
+  version: 1.2.3
+  resolution: "@scope/lib@npm:1.2.3"
+  languageName: node
+  linkType: hard
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  languageName: unknown
+  linkType: soft
+
+"debug@npm:^4.1.0":
+  version: 4.3.4
+  resolution: "debug@npm:4.3.4"
+  languageName: node
+  linkType: hard
+
+"react-dom@npm:^18.2.0":
+  version: 18.2.0
+  resolution: "react-dom@npm:18.2.0"
+  dependencies:
+    react: "npm:^18.2.0"
+  languageName: node
+  linkType: hard
+
+"react@npm:^18.2.0":
+  version: 18.2.0
+  resolution: "react@npm:18.2.0"
+  languageName: node
+  linkType: hard
+`
+
+func TestYarnResolvedPackages(t *testing.T) {
+	for _, lock := range []struct {
+		name    string
+		content string
+		berry   bool
+	}{
+		{"classic", yarnLockClassic, false},
+		{"berry", yarnLockBerry, true},
+	} {
+		t.Run(lock.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(yarnManifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "yarn.lock"), []byte(lock.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			parser := NewParser(dir)
+			parsed, err := parser.ParseYarnLockfile()
+			if err != nil {
+				t.Fatalf("ParseYarnLockfile returned error: %v", err)
+			}
+			if parsed.Berry != lock.berry {
+				t.Errorf("Berry = %v, want %v", parsed.Berry, lock.berry)
+			}
+
+			all, err := parser.GetDependencies(true)
+			if err != nil {
+				t.Fatalf("GetDependencies returned error: %v", err)
+			}
+			wantAll := []string{"@babel/core@7.24.0", "@scope/lib@1.2.3", "debug@4.3.4", "react-dom@18.2.0", "react@18.2.0"}
+			if got := packageIDs(all); !slices.Equal(got, wantAll) {
+				t.Errorf("with dev = %v, want %v", got, wantAll)
+			}
+
+			prod, err := parser.GetDependencies(false)
+			if err != nil {
+				t.Fatalf("GetDependencies returned error: %v", err)
+			}
+			wantProd := []string{"@scope/lib@1.2.3", "react-dom@18.2.0", "react@18.2.0"}
+			if got := packageIDs(prod); !slices.Equal(got, wantProd) {
+				t.Errorf("without dev = %v, want %v", got, wantProd)
+			}
+
+			dev, err := parser.GetDependenciesFiltered(DependenciesDev)
+			if err != nil {
+				t.Fatalf("GetDependenciesFiltered returned error: %v", err)
+			}
+			wantDev := []string{"@babel/core@7.24.0", "debug@4.3.4"}
+			if got := packageIDs(dev); !slices.Equal(got, wantDev) {
+				t.Errorf("dev only = %v, want %v", got, wantDev)
+			}
+		})
+	}
+}
+
+func TestSplitYarnDescriptor(t *testing.T) {
+	tests := []struct {
+		descriptor string
+		name       string
+		rng        string
+	}{
+		{"lodash@^4.17.21", "lodash", "^4.17.21"},
+		{"@babel/core@npm:^7.24.0", "@babel/core", "npm:^7.24.0"},
+		{"app@workspace:.", "app", "workspace:."},
+		{"lodash", "lodash", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.descriptor, func(t *testing.T) {
+			name, rng := splitYarnDescriptor(tt.descriptor)
+			if name != tt.name || rng != tt.rng {
+				t.Errorf("splitYarnDescriptor(%q) = %q, %q, want %q, %q", tt.descriptor, name, rng, tt.name, tt.rng)
+			}
+		})
+	}
+}
+
+const yarnClassesManifest = `{
+  "name": "app",
+  "dependencies": {"react": "^18.2.0"},
+  "devDependencies": {"jest": "^29.0.0"},
+  "optionalDependencies": {"fsevents": "^2.3.0"},
+  "peerDependencies": {"react-dom": "^18.2.0"}
+}`
+
+const yarnClassesClassic = `# yarn lockfile v1
+
+
+fsevents@^2.3.0:
+  version "2.3.3"
+
+jest@^29.0.0:
+  version "29.7.0"
+  dependencies:
+    fsevents "^2.3.0"
+
+loose-envify@^1.4.0:
+  version "1.4.0"
+
+react-dom@^18.2.0:
+  version "18.2.0"
+
+react@^18.2.0:
+  version "18.2.0"
+  optionalDependencies:
+    loose-envify "^1.4.0"
+`
+
+const yarnClassesBerry = `__metadata:
+  version: 8
+
+"fsevents@npm:^2.3.0":
+  version: 2.3.3
+  resolution: "fsevents@npm:2.3.3"
+
+"jest@npm:^29.0.0":
+  version: 29.7.0
+  resolution: "jest@npm:29.7.0"
+  dependencies:
+    fsevents: "npm:^2.3.0"
+
+"loose-envify@npm:^1.4.0":
+  version: 1.4.0
+  resolution: "loose-envify@npm:1.4.0"
+
+"react-dom@npm:^18.2.0":
+  version: 18.2.0
+  resolution: "react-dom@npm:18.2.0"
+
+"react@npm:^18.2.0":
+  version: 18.2.0
+  resolution: "react@npm:18.2.0"
+  optionalDependencies:
+    loose-envify: "npm:^1.4.0"
+`
+
+// TestYarnDependencyClasses classifies packages by how the manifest's
+// direct dependencies reach them
+func TestYarnDependencyClasses(t *testing.T) {
+	want := map[DependencyClass][]string{
+		DependenciesProd:     {"react@18.2.0"},
+		DependenciesOptional: {"fsevents@2.3.3", "loose-envify@1.4.0"},
+		DependenciesDev:      {"fsevents@2.3.3", "jest@29.7.0"},
+		DependenciesPeer:     {"react-dom@18.2.0"},
+	}
+	for _, lock := range []struct{ name, content string }{
+		{"classic", yarnClassesClassic},
+		{"berry", yarnClassesBerry},
+	} {
+		t.Run(lock.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(yarnClassesManifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "yarn.lock"), []byte(lock.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			parser := NewParser(dir)
+			for class, want := range want {
+				got, err := parser.GetDependenciesFiltered(class)
+				if err != nil {
+					t.Fatalf("GetDependenciesFiltered returned error: %v", err)
+				}
+				if ids := packageIDs(got); !slices.Equal(ids, want) {
+					t.Errorf("class %d = %v, want %v", class, ids, want)
+				}
+			}
+		})
+	}
+}
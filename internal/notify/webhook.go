@@ -0,0 +1,199 @@
+// Package notify tells a webhook when a scan blocks a command or a user
+// forces past a block, so security teams see both as they happen.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/redact"
+)
+
+// FormatSlack renders the notification as a Slack message; any other
+// format sends the Payload as it is
+const FormatSlack = "slack"
+
+// defaultTimeout bounds each delivery attempt when the config sets none
+const defaultTimeout = 5 * time.Second
+
+// Payload is the JSON document posted for an event
+type Payload struct {
+	// Event is the decision: "blocked" or "forced"
+	Event    string        `json:"event"`
+	Time     time.Time     `json:"time"`
+	Command  string        `json:"command"`
+	Project  string        `json:"project"`
+	User     string        `json:"user,omitempty"`
+	Packages []string      `json:"packages,omitempty"`
+	Findings audit.Summary `json:"findings"`
+	Flagged  []string      `json:"flagged,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+}
+
+// NewPayload describes an audit entry
+func NewPayload(e audit.Entry) Payload {
+	return Payload{
+		Event:    string(e.Decision),
+		Time:     e.Time,
+		Command:  e.Command,
+		Project:  e.Project,
+		User:     e.User,
+		Packages: e.Packages,
+		Findings: e.Findings,
+		Flagged:  e.Flagged,
+		Reason:   e.Reason,
+	}
+}
+
+// Webhook posts notifications to a URL
+type Webhook struct {
+	url     string
+	format  string
+	timeout time.Duration
+	client  *http.Client
+	warn    func(string)
+}
+
+// NewWebhook returns a webhook for the config, or nil, which sends
+// nothing, if no URL is set. warn receives delivery failures, which never
+// fail the command being reported.
+func NewWebhook(cfg config.WebhookConfig, warn func(string)) *Webhook {
+	if cfg.URL == "" {
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Webhook{
+		url:     cfg.URL,
+		format:  cfg.Format,
+		timeout: timeout,
+		client:  httpclient.NewNoRetry(timeout),
+		warn:    warn,
+	}
+}
+
+// Notify posts an entry that blocked a command or forced past a block.
+// Allowed entries are not sent. A failed delivery is retried once.
+func (w *Webhook) Notify(ctx context.Context, e audit.Entry) {
+	if w == nil || (e.Decision != audit.DecisionBlocked && e.Decision != audit.DecisionForced) {
+		return
+	}
+	body, err := w.body(NewPayload(e))
+	if err != nil {
+		w.warnf("Could not build the webhook notification: %v", err)
+		return
+	}
+
+	retry, err := w.post(ctx, body)
+	if err != nil && retry {
+		_, err = w.post(ctx, body)
+	}
+	if err != nil {
+		w.warnf("Could not notify the webhook: %s", redact.String(err.Error()))
+	}
+}
+
+// body encodes the payload in the configured format
+func (w *Webhook) body(p Payload) ([]byte, error) {
+	if w.format == FormatSlack {
+		return json.Marshal(slackMessage{Text: SlackText(p)})
+	}
+	return json.Marshal(p)
+}
+
+// post sends one request. retry is true for failures that may pass on a
+// second attempt: network errors and 5xx or 429 responses.
+func (w *Webhook) post(ctx context.Context, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "snapem")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+func (w *Webhook) warnf(format string, args ...any) {
+	if w.warn != nil {
+		w.warn(fmt.Sprintf(format, args...))
+	}
+}
+
+// slackMessage is a Slack incoming webhook message
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// SlackText renders a payload as a Slack message, marking the findings
+// with an emoji for each severity
+func SlackText(p Payload) string {
+	var b strings.Builder
+	if p.Event == string(audit.DecisionForced) {
+		fmt.Fprintf(&b, ":warning: *snapem %s forced past a block*", p.Command)
+	} else {
+		fmt.Fprintf(&b, ":no_entry: *snapem blocked %s*", p.Command)
+	}
+	fmt.Fprintf(&b, " in `%s`", p.Project)
+	if p.User != "" {
+		fmt.Fprintf(&b, " by %s", p.User)
+	}
+
+	if len(p.Packages) > 0 {
+		fmt.Fprintf(&b, "\nPackages: %s", strings.Join(p.Packages, ", "))
+	}
+	if p.Findings.Total > 0 {
+		var counts []string
+		for _, c := range []struct {
+			n     int
+			emoji string
+			name  string
+		}{
+			{p.Findings.Malware, ":skull:", "malware"},
+			{p.Findings.Critical, ":red_circle:", "critical"},
+			{p.Findings.High, ":large_orange_circle:", "high"},
+			{p.Findings.Medium, ":large_yellow_circle:", "medium"},
+			{p.Findings.Low, ":white_circle:", "low"},
+		} {
+			if c.n > 0 {
+				counts = append(counts, fmt.Sprintf("%s %d %s", c.emoji, c.n, c.name))
+			}
+		}
+		fmt.Fprintf(&b, "\nFindings: %d", p.Findings.Total)
+		if len(counts) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(counts, ", "))
+		}
+	}
+	if len(p.Flagged) > 0 {
+		fmt.Fprintf(&b, "\nFlagged: %s", strings.Join(p.Flagged, ", "))
+	}
+	if p.Reason != "" {
+		fmt.Fprintf(&b, "\nReason: %s", p.Reason)
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/audit"
+	"github.com/positronico/snapem/internal/config"
+)
+
+var blocked = audit.Entry{
+	Time:     time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	Command:  "install",
+	Project:  "/work/app",
+	User:     "dev",
+	Packages: []string{"evil-pkg"},
+	Findings: audit.Summary{Total: 3, Malware: 1, High: 2},
+	Flagged:  []string{"evil-pkg@1.0.0"},
+	Decision: audit.DecisionBlocked,
+	Reason:   "security policy blocked installation",
+}
+
+// server records request bodies and answers with the given statuses in
+// turn, then 200
+func server(t *testing.T, statuses ...int) (*httptest.Server, *[]string) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) <= len(statuses) {
+			w.WriteHeader(statuses[len(bodies)-1])
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s, &bodies
+}
+
+func TestNotifyJSON(t *testing.T) {
+	s, bodies := server(t)
+	var warnings []string
+	w := NewWebhook(config.WebhookConfig{URL: s.URL, Format: "json"}, func(msg string) { warnings = append(warnings, msg) })
+
+	w.Notify(context.Background(), blocked)
+	allowed := blocked
+	allowed.Decision = audit.DecisionAllowed
+	w.Notify(context.Background(), allowed)
+
+	if len(*bodies) != 1 {
+		t.Fatalf("got %d requests, want 1 (allowed entries are not sent)", len(*bodies))
+	}
+	var got Payload
+	if err := json.Unmarshal([]byte((*bodies)[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != "blocked" || got.User != "dev" || got.Findings.Malware != 1 || got.Packages[0] != "evil-pkg" {
+		t.Errorf("payload = %+v", got)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestNotifySlack(t *testing.T) {
+	s, bodies := server(t)
+	forced := blocked
+	forced.Decision = audit.DecisionForced
+	NewWebhook(config.WebhookConfig{URL: s.URL, Format: FormatSlack}, nil).Notify(context.Background(), forced)
+
+	var msg slackMessage
+	if len(*bodies) != 1 || json.Unmarshal([]byte((*bodies)[0]), &msg) != nil {
+		t.Fatalf("bodies = %q", *bodies)
+	}
+	for _, want := range []string{":warning: *snapem install forced past a block*", "by dev", ":skull: 1 malware", ":large_orange_circle: 2 high", "Flagged: evil-pkg@1.0.0"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("message %q does not contain %q", msg.Text, want)
+		}
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		warns    bool
+	}{
+		{"retried once", []int{http.StatusBadGateway}, 2, false},
+		{"gives up after the retry", []int{http.StatusBadGateway, http.StatusServiceUnavailable}, 2, true},
+		{"client error is not retried", []int{http.StatusNotFound}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, bodies := server(t, tt.statuses...)
+			var warnings []string
+			NewWebhook(config.WebhookConfig{URL: s.URL}, func(msg string) { warnings = append(warnings, msg) }).Notify(context.Background(), blocked)
+
+			if len(*bodies) != tt.requests {
+				t.Errorf("got %d requests, want %d", len(*bodies), tt.requests)
+			}
+			if (len(warnings) > 0) != tt.warns {
+				t.Errorf("warnings = %q, want a warning: %v", warnings, tt.warns)
+			}
+		})
+	}
+}
+
+func TestNewWebhookDisabled(t *testing.T) {
+	w := NewWebhook(config.WebhookConfig{}, nil)
+	if w != nil {
+		t.Fatal("NewWebhook() without a URL should return nil")
+	}
+	w.Notify(context.Background(), blocked) // a nil webhook sends nothing
+}
@@ -7,13 +7,40 @@ import (
 	"github.com/positronico/snapem/internal/manifest"
 )
 
+// InstallOptions holds the options for an install command
+type InstallOptions struct {
+	// SaveDev records the packages as devDependencies
+	SaveDev bool
+
+	// IgnoreScripts skips the lifecycle scripts of installed packages
+	IgnoreScripts bool
+
+	// Workspace restricts the install to one workspace package, by name
+	Workspace string
+}
+
 // Manager defines the interface for package managers
 type Manager interface {
 	// Name returns the package manager name
 	Name() string
 
 	// InstallCommand returns the container command for install
-	InstallCommand(packages []string, saveDev bool) []string
+	InstallCommand(packages []string, opts InstallOptions) []string
+
+	// CleanInstallCommand returns the container command for installing
+	// exactly what the lockfile records, failing if it is out of sync
+	CleanInstallCommand() []string
+
+	// UninstallCommand returns the container command for removing packages
+	UninstallCommand(packages []string, saveDev bool) []string
+
+	// UpdateCommand returns the container command for updating packages
+	// within their ranges, or every package when none are given
+	UpdateCommand(packages []string) []string
+
+	// OutdatedCommand returns the container command listing outdated
+	// packages, or nil if the manager has no such command
+	OutdatedCommand() []string
 
 	// RunCommand returns the container command for running a script
 	RunCommand(script string, args []string) []string
@@ -21,8 +48,39 @@ type Manager interface {
 	// ExecCommand returns the container command for executing an arbitrary command
 	ExecCommand(command []string) []string
 
+	// DlxCommand returns the container command for running a package's
+	// binary without installing it in the project, like npx. bin names
+	// the binary when it differs from the package; empty runs the
+	// package's own.
+	DlxCommand(pkg, bin string, args []string) []string
+
 	// Image returns the default container image
 	Image() string
+
+	// CachePath returns the directory the package manager keeps its
+	// download cache in inside the container
+	CachePath() string
+}
+
+// cacheRoot holds the package manager caches inside the container. It is
+// outside /root so the cache also works for a non-root container.user.
+const cacheRoot = "/snapem/cache"
+
+// cacheEnv names the variable that points each package manager at its
+// cache, whatever HOME is
+var cacheEnv = map[string]string{
+	"npm":  "npm_config_cache",
+	"bun":  "BUN_INSTALL_CACHE_DIR",
+	"yarn": "YARN_CACHE_FOLDER",
+}
+
+// Names lists the supported package managers
+var Names = []string{"npm", "bun", "yarn"}
+
+// CacheDir returns the host directory under baseDir that persists a
+// package manager's cache between containers
+func CacheDir(baseDir, name string) string {
+	return filepath.Join(baseDir, name+"-cache")
 }
 
 // NPM implements the Manager interface for npm
@@ -44,8 +102,29 @@ func (n *NPM) Name() string {
 }
 
 // InstallCommand returns npm install command
-func (n *NPM) InstallCommand(packages []string, saveDev bool) []string {
+func (n *NPM) InstallCommand(packages []string, opts InstallOptions) []string {
 	cmd := []string{"npm", "install"}
+	if opts.SaveDev {
+		cmd = append(cmd, "--save-dev")
+	}
+	if opts.IgnoreScripts {
+		cmd = append(cmd, "--ignore-scripts")
+	}
+	if opts.Workspace != "" {
+		cmd = append(cmd, "--workspace="+opts.Workspace)
+	}
+	cmd = append(cmd, packages...)
+	return cmd
+}
+
+// CleanInstallCommand returns npm ci command
+func (n *NPM) CleanInstallCommand() []string {
+	return []string{"npm", "ci"}
+}
+
+// UninstallCommand returns npm uninstall command
+func (n *NPM) UninstallCommand(packages []string, saveDev bool) []string {
+	cmd := []string{"npm", "uninstall"}
 	if saveDev {
 		cmd = append(cmd, "--save-dev")
 	}
@@ -53,6 +132,17 @@ func (n *NPM) InstallCommand(packages []string, saveDev bool) []string {
 	return cmd
 }
 
+// UpdateCommand returns npm update command
+func (n *NPM) UpdateCommand(packages []string) []string {
+	return append([]string{"npm", "update"}, packages...)
+}
+
+// OutdatedCommand returns npm outdated with JSON output, including the
+// dependency type
+func (n *NPM) OutdatedCommand() []string {
+	return []string{"npm", "outdated", "--json", "--long"}
+}
+
 // RunCommand returns npm run command wrapped for clean signal handling
 func (n *NPM) RunCommand(script string, args []string) []string {
 	// Build the npm command
@@ -72,11 +162,30 @@ func (n *NPM) ExecCommand(command []string) []string {
 	return command
 }
 
+// DlxCommand returns npx, answering yes to its install prompt
+func (n *NPM) DlxCommand(pkg, bin string, args []string) []string {
+	return npxCommand(pkg, bin, args)
+}
+
+// npxCommand returns npx --yes for a package, or with --package for a
+// binary it provides
+func npxCommand(pkg, bin string, args []string) []string {
+	if bin == "" {
+		return append([]string{"npx", "--yes", pkg}, args...)
+	}
+	return append([]string{"npx", "--yes", "--package=" + pkg, "--", bin}, args...)
+}
+
 // Image returns the npm container image
 func (n *NPM) Image() string {
 	return n.image
 }
 
+// CachePath returns the npm cache directory in the container
+func (n *NPM) CachePath() string {
+	return cacheRoot + "/npm"
+}
+
 // Bun implements the Manager interface for bun
 type Bun struct {
 	image string
@@ -96,15 +205,44 @@ func (b *Bun) Name() string {
 }
 
 // InstallCommand returns bun install command
-func (b *Bun) InstallCommand(packages []string, saveDev bool) []string {
+func (b *Bun) InstallCommand(packages []string, opts InstallOptions) []string {
 	cmd := []string{"bun", "install"}
-	if saveDev {
+	if opts.SaveDev {
 		cmd = append(cmd, "--dev")
 	}
+	if opts.IgnoreScripts {
+		cmd = append(cmd, "--ignore-scripts")
+	}
+	if opts.Workspace != "" {
+		cmd = append(cmd, "--filter", opts.Workspace)
+	}
+	cmd = append(cmd, packages...)
+	return cmd
+}
+
+// CleanInstallCommand returns bun install with a frozen lockfile
+func (b *Bun) CleanInstallCommand() []string {
+	return []string{"bun", "install", "--frozen-lockfile"}
+}
+
+// UninstallCommand returns bun remove command. bun removes the package
+// from whichever dependency list has it, so saveDev is not needed.
+func (b *Bun) UninstallCommand(packages []string, saveDev bool) []string {
+	cmd := []string{"bun", "remove"}
 	cmd = append(cmd, packages...)
 	return cmd
 }
 
+// UpdateCommand returns bun update command
+func (b *Bun) UpdateCommand(packages []string) []string {
+	return append([]string{"bun", "update"}, packages...)
+}
+
+// OutdatedCommand returns bun outdated, which only prints a table
+func (b *Bun) OutdatedCommand() []string {
+	return []string{"bun", "outdated"}
+}
+
 // RunCommand returns bun run command
 func (b *Bun) RunCommand(script string, args []string) []string {
 	cmd := []string{"bun", "run", script}
@@ -117,15 +255,153 @@ func (b *Bun) ExecCommand(command []string) []string {
 	return command
 }
 
+// DlxCommand returns bunx
+func (b *Bun) DlxCommand(pkg, bin string, args []string) []string {
+	if bin == "" {
+		return append([]string{"bunx", pkg}, args...)
+	}
+	return append([]string{"bunx", "--package", pkg, bin}, args...)
+}
+
 // Image returns the bun container image
 func (b *Bun) Image() string {
 	return b.image
 }
 
+// CachePath returns the bun cache directory in the container
+func (b *Bun) CachePath() string {
+	return cacheRoot + "/bun"
+}
+
+// Yarn implements the Manager interface for yarn (classic and berry)
+type Yarn struct {
+	image string
+	berry bool
+}
+
+// NewYarn creates a new yarn manager. berry selects Yarn 2+ semantics.
+func NewYarn(image string, berry bool) *Yarn {
+	if image == "" {
+		image = "node:lts-slim"
+	}
+	return &Yarn{image: image, berry: berry}
+}
+
+// Name returns "yarn"
+func (y *Yarn) Name() string {
+	return "yarn"
+}
+
+// InstallCommand returns yarn install, or yarn add when packages are given.
+// A workspace only applies to yarn add; yarn install always installs the
+// whole project.
+func (y *Yarn) InstallCommand(packages []string, opts InstallOptions) []string {
+	cmd := []string{"yarn", "install"}
+	if len(packages) > 0 {
+		cmd = []string{"yarn", "add"}
+		if opts.Workspace != "" {
+			cmd = []string{"yarn", "workspace", opts.Workspace, "add"}
+		}
+	}
+
+	if opts.SaveDev && len(packages) > 0 {
+		// Berry only documents the short form; classic uses --dev
+		if y.berry {
+			cmd = append(cmd, "-D")
+		} else {
+			cmd = append(cmd, "--dev")
+		}
+	}
+	if opts.IgnoreScripts {
+		// Berry has no --ignore-scripts; skip-build mode skips the
+		// lifecycle scripts of dependencies
+		if y.berry {
+			cmd = append(cmd, "--mode=skip-build")
+		} else {
+			cmd = append(cmd, "--ignore-scripts")
+		}
+	}
+	cmd = append(cmd, packages...)
+	return cmd
+}
+
+// CleanInstallCommand returns yarn install with a frozen lockfile
+func (y *Yarn) CleanInstallCommand() []string {
+	if y.berry {
+		return []string{"yarn", "install", "--immutable"}
+	}
+	return []string{"yarn", "install", "--frozen-lockfile"}
+}
+
+// UninstallCommand returns yarn remove command. yarn removes the package
+// from whichever dependency list has it, so saveDev is not needed.
+func (y *Yarn) UninstallCommand(packages []string, saveDev bool) []string {
+	cmd := []string{"yarn", "remove"}
+	cmd = append(cmd, packages...)
+	return cmd
+}
+
+// UpdateCommand returns yarn upgrade, or yarn up for berry, which needs a
+// pattern to update everything
+func (y *Yarn) UpdateCommand(packages []string) []string {
+	if !y.berry {
+		return append([]string{"yarn", "upgrade"}, packages...)
+	}
+	if len(packages) == 0 {
+		packages = []string{"*"}
+	}
+	return append([]string{"yarn", "up"}, packages...)
+}
+
+// OutdatedCommand returns yarn outdated with JSON output. Berry has no
+// outdated command.
+func (y *Yarn) OutdatedCommand() []string {
+	if y.berry {
+		return nil
+	}
+	return []string{"yarn", "outdated", "--json"}
+}
+
+// RunCommand returns yarn run command
+func (y *Yarn) RunCommand(script string, args []string) []string {
+	cmd := []string{"yarn", "run", script}
+	cmd = append(cmd, args...)
+	return cmd
+}
+
+// ExecCommand returns the command as-is for exec
+func (y *Yarn) ExecCommand(command []string) []string {
+	return command
+}
+
+// DlxCommand returns yarn dlx for berry. Classic yarn has no dlx, so it
+// uses npx, which ships with the same Node.js image.
+func (y *Yarn) DlxCommand(pkg, bin string, args []string) []string {
+	if !y.berry {
+		return npxCommand(pkg, bin, args)
+	}
+	if bin == "" {
+		return append([]string{"yarn", "dlx", pkg}, args...)
+	}
+	return append([]string{"yarn", "dlx", "-p", pkg, bin}, args...)
+}
+
+// Image returns the yarn container image
+func (y *Yarn) Image() string {
+	return y.image
+}
+
+// CachePath returns the yarn cache directory in the container
+func (y *Yarn) CachePath() string {
+	return cacheRoot + "/yarn"
+}
+
 // Detect determines which package manager to use based on the project
 func Detect(projectDir string, preferred string, images map[string]string) Manager {
 	npmImage := images["npm"]
 	bunImage := images["bun"]
+	yarnImage := images["yarn"]
+	parser := manifest.NewParser(projectDir)
 
 	// If user specified a preference, use it
 	switch preferred {
@@ -133,23 +409,28 @@ func Detect(projectDir string, preferred string, images map[string]string) Manag
 		return NewNPM(npmImage)
 	case "bun":
 		return NewBun(bunImage)
+	case "yarn":
+		return NewYarn(yarnImage, parser.IsYarnBerry())
 	}
 
 	// Auto-detect based on lockfiles
-	parser := manifest.NewParser(projectDir)
 	if parser.HasBunLockfile() {
 		return NewBun(bunImage)
 	}
+	if parser.HasYarnLockfile() {
+		return NewYarn(yarnImage, parser.IsYarnBerry())
+	}
 
 	// Default to npm
 	return NewNPM(npmImage)
 }
 
-// BuildContainerOptions creates container run options for the given manager and command
-func BuildContainerOptions(mgr Manager, projectDir string, network container.NetworkMode, command []string) *container.RunOptions {
+// BuildContainerOptions creates container run options for the given manager
+// and command. A non-empty cacheDir is mounted as the manager's cache.
+func BuildContainerOptions(mgr Manager, projectDir, cacheDir string, network container.NetworkMode, command []string) *container.RunOptions {
 	absPath, _ := filepath.Abs(projectDir)
 
-	return &container.RunOptions{
+	opts := &container.RunOptions{
 		Image:       mgr.Image(),
 		Command:     command,
 		WorkDir:     "/app",
@@ -166,4 +447,15 @@ func BuildContainerOptions(mgr Manager, projectDir string, network container.Net
 		},
 		Environment: make(map[string]string),
 	}
+
+	if cacheDir != "" {
+		opts.Volumes = append(opts.Volumes, container.VolumeMount{
+			HostPath:      cacheDir,
+			ContainerPath: mgr.CachePath(),
+		})
+		if name, ok := cacheEnv[mgr.Name()]; ok {
+			opts.Environment[name] = mgr.CachePath()
+		}
+	}
+	return opts
 }
@@ -0,0 +1,92 @@
+package pkgmanager
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstallCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		mgr      Manager
+		packages []string
+		opts     InstallOptions
+		want     string
+	}{
+		{"npm", NewNPM(""), nil, InstallOptions{}, "npm install"},
+		{"npm dev", NewNPM(""), []string{"jest"}, InstallOptions{SaveDev: true}, "npm install --save-dev jest"},
+		{"npm ignore scripts", NewNPM(""), []string{"lodash"}, InstallOptions{IgnoreScripts: true}, "npm install --ignore-scripts lodash"},
+		{"bun ignore scripts", NewBun(""), nil, InstallOptions{IgnoreScripts: true}, "bun install --ignore-scripts"},
+		{"bun dev", NewBun(""), []string{"jest"}, InstallOptions{SaveDev: true, IgnoreScripts: true}, "bun install --dev --ignore-scripts jest"},
+		{"yarn", NewYarn("", false), nil, InstallOptions{SaveDev: true}, "yarn install"},
+		{"yarn add", NewYarn("", false), []string{"jest"}, InstallOptions{SaveDev: true}, "yarn add --dev jest"},
+		{"yarn ignore scripts", NewYarn("", false), nil, InstallOptions{IgnoreScripts: true}, "yarn install --ignore-scripts"},
+		{"yarn berry ignore scripts", NewYarn("", true), []string{"jest"}, InstallOptions{SaveDev: true, IgnoreScripts: true}, "yarn add -D --mode=skip-build jest"},
+		{"npm workspace", NewNPM(""), []string{"zod"}, InstallOptions{Workspace: "api"}, "npm install --workspace=api zod"},
+		{"bun workspace", NewBun(""), nil, InstallOptions{Workspace: "api"}, "bun install --filter api"},
+		{"yarn workspace", NewYarn("", false), []string{"zod"}, InstallOptions{Workspace: "api"}, "yarn workspace api add zod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(tt.mgr.InstallCommand(tt.packages, tt.opts), " ")
+			if got != tt.want {
+				t.Errorf("InstallCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDlxCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		mgr  Manager
+		pkg  string
+		bin  string
+		want string
+	}{
+		{"npm", NewNPM(""), "create-vite@5.2.0", "", "npx --yes create-vite@5.2.0 my-app"},
+		{"npm binary", NewNPM(""), "@angular/cli@17.0.0", "ng", "npx --yes --package=@angular/cli@17.0.0 -- ng my-app"},
+		{"bun", NewBun(""), "create-vite@5.2.0", "", "bunx create-vite@5.2.0 my-app"},
+		{"bun binary", NewBun(""), "@angular/cli@17.0.0", "ng", "bunx --package @angular/cli@17.0.0 ng my-app"},
+		{"yarn classic", NewYarn("", false), "create-vite@5.2.0", "", "npx --yes create-vite@5.2.0 my-app"},
+		{"yarn berry", NewYarn("", true), "create-vite@5.2.0", "", "yarn dlx create-vite@5.2.0 my-app"},
+		{"yarn berry binary", NewYarn("", true), "@angular/cli@17.0.0", "ng", "yarn dlx -p @angular/cli@17.0.0 ng my-app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(tt.mgr.DlxCommand(tt.pkg, tt.bin, []string{"my-app"}), " ")
+			if got != tt.want {
+				t.Errorf("DlxCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContainerOptionsCache(t *testing.T) {
+	tests := []struct {
+		mgr  Manager
+		path string
+		env  string
+	}{
+		{NewNPM(""), "/snapem/cache/npm", "npm_config_cache"},
+		{NewBun(""), "/snapem/cache/bun", "BUN_INSTALL_CACHE_DIR"},
+		{NewYarn("", true), "/snapem/cache/yarn", "YARN_CACHE_FOLDER"},
+	}
+	for _, tt := range tests {
+		cacheDir := CacheDir("/cache/snapem", tt.mgr.Name())
+		opts := BuildContainerOptions(tt.mgr, "/src/app", cacheDir, "host", []string{"true"})
+		if len(opts.Volumes) != 2 || opts.Volumes[1].HostPath != "/cache/snapem/"+tt.mgr.Name()+"-cache" || opts.Volumes[1].ContainerPath != tt.path {
+			t.Errorf("%s: Volumes = %+v, want the cache mounted at %s", tt.mgr.Name(), opts.Volumes, tt.path)
+		}
+		if opts.Environment[tt.env] != tt.path {
+			t.Errorf("%s: %s = %q, want %s", tt.mgr.Name(), tt.env, opts.Environment[tt.env], tt.path)
+		}
+	}
+
+	opts := BuildContainerOptions(NewNPM(""), "/src/app", "", "host", []string{"true"})
+	if len(opts.Volumes) != 1 || len(opts.Environment) != 0 {
+		t.Errorf("without a cache dir: Volumes = %+v, Environment = %v", opts.Volumes, opts.Environment)
+	}
+}
@@ -0,0 +1,121 @@
+package pkgmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+// ltsCodenames maps the lts/<codename> aliases nvm accepts to their major
+// versions
+var ltsCodenames = map[string]int{
+	"argon":    4,
+	"boron":    6,
+	"carbon":   8,
+	"dubnium":  10,
+	"erbium":   12,
+	"fermium":  14,
+	"gallium":  16,
+	"hydrogen": 18,
+	"iron":     20,
+	"jod":      22,
+	"krypton":  24,
+}
+
+// maxNodeMajor bounds the majors tried against an engines.node range
+const maxNodeMajor = 40
+
+// NodeImage returns the node:<major>-slim image for the Node.js version the
+// project asks for in .nvmrc, .node-version or engines.node, in that order,
+// along with the file that decided it. ok is false if the project does not
+// ask for a specific major version, such as with lts/* or an open range
+// like >=18; the configured image is then left alone.
+func NodeImage(projectDir string) (image, source string, ok bool) {
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		data, err := os.ReadFile(filepath.Join(projectDir, name))
+		if err != nil {
+			continue
+		}
+		if major, ok := versionFileMajor(string(data)); ok {
+			return nodeImage(major), name, true
+		}
+		return "", "", false
+	}
+
+	m, err := manifest.NewParser(projectDir).ParseManifest()
+	if err != nil || m.Engines["node"] == "" {
+		return "", "", false
+	}
+	if major, ok := rangeMajor(m.Engines["node"]); ok {
+		return nodeImage(major), "package.json engines.node", true
+	}
+	return "", "", false
+}
+
+// nodeImage returns the slim image tag for a major version
+func nodeImage(major int) string {
+	return fmt.Sprintf("node:%d-slim", major)
+}
+
+// versionFileMajor reads the major version from the contents of an .nvmrc
+// or .node-version file: a version such as v18.17.0 or 20, or an lts/
+// codename
+func versionFileMajor(contents string) (int, bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(contents), "\n")
+	line = strings.ToLower(strings.TrimSpace(line))
+
+	if codename, ok := strings.CutPrefix(line, "lts/"); ok {
+		major, ok := ltsCodenames[codename]
+		return major, ok
+	}
+
+	v, err := semver.NewVersion(line)
+	if err != nil {
+		return 0, false
+	}
+	return int(v.Major()), true
+}
+
+// rangeMajor picks the major version for an engines.node range: the
+// highest even (LTS) major it allows, or else the highest major. A range
+// with no upper bound does not name a major.
+func rangeMajor(spec string) (int, bool) {
+	constraint, err := semver.NewConstraint(spec)
+	if err != nil {
+		return 0, false
+	}
+	if constraint.Check(semver.MustParse(strconv.Itoa(maxNodeMajor*10) + ".0.0")) {
+		return 0, false
+	}
+
+	best := 0
+	for major := maxNodeMajor; major > 0; major-- {
+		if !allowsMajor(constraint, major) {
+			continue
+		}
+		if major%2 == 0 {
+			return major, true
+		}
+		if best == 0 {
+			best = major
+		}
+	}
+	return best, best != 0
+}
+
+// allowsMajor reports whether any release of a major version satisfies the
+// constraint
+func allowsMajor(constraint *semver.Constraints, major int) bool {
+	for minor := 0; minor < 100; minor++ {
+		if constraint.Check(semver.New(uint64(major), uint64(minor), 99, "", "")) {
+			return true
+		}
+	}
+	return false
+}
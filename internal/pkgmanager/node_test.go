@@ -0,0 +1,87 @@
+package pkgmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNodeImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantImage  string
+		wantSource string
+	}{
+		{
+			name:       "nvmrc version",
+			files:      map[string]string{".nvmrc": "v18.17.0\n"},
+			wantImage:  "node:18-slim",
+			wantSource: ".nvmrc",
+		},
+		{
+			name:       "nvmrc lts codename",
+			files:      map[string]string{".nvmrc": "lts/iron"},
+			wantImage:  "node:20-slim",
+			wantSource: ".nvmrc",
+		},
+		{
+			name:  "nvmrc lts alias keeps the configured image",
+			files: map[string]string{".nvmrc": "lts/*", "package.json": `{"engines": {"node": "^18"}}`},
+		},
+		{
+			name:       "node-version",
+			files:      map[string]string{".node-version": "22"},
+			wantImage:  "node:22-slim",
+			wantSource: ".node-version",
+		},
+		{
+			name:       "nvmrc wins over node-version",
+			files:      map[string]string{".nvmrc": "16", ".node-version": "22"},
+			wantImage:  "node:16-slim",
+			wantSource: ".nvmrc",
+		},
+		{
+			name:       "engines caret",
+			files:      map[string]string{"package.json": `{"engines": {"node": "^18.12.0"}}`},
+			wantImage:  "node:18-slim",
+			wantSource: "package.json engines.node",
+		},
+		{
+			name:       "engines bounded range prefers LTS",
+			files:      map[string]string{"package.json": `{"engines": {"node": ">=16 <20"}}`},
+			wantImage:  "node:18-slim",
+			wantSource: "package.json engines.node",
+		},
+		{
+			name:       "engines tilde minor",
+			files:      map[string]string{"package.json": `{"engines": {"node": "~21.1"}}`},
+			wantImage:  "node:21-slim",
+			wantSource: "package.json engines.node",
+		},
+		{
+			name:  "engines open range",
+			files: map[string]string{"package.json": `{"engines": {"node": ">=18"}}`},
+		},
+		{
+			name:  "nothing",
+			files: map[string]string{"package.json": `{"name": "app"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			image, source, ok := NodeImage(dir)
+			if ok != (tt.wantImage != "") || image != tt.wantImage || source != tt.wantSource {
+				t.Errorf("NodeImage() = %q, %q, %v, want %q, %q", image, source, ok, tt.wantImage, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package pkgmanager
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoStructuredOutput is returned by ParseOutdated for package managers
+// whose outdated command only prints a human-readable table
+var ErrNoStructuredOutput = errors.New("no machine-readable outdated output")
+
+// OutdatedPackage is a dependency with a newer version available
+type OutdatedPackage struct {
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Wanted  string `json:"wanted"`
+	Latest  string `json:"latest"`
+
+	// Type is the package.json section, e.g. "dependencies"
+	Type string `json:"type"`
+}
+
+// ParseOutdated parses the output of the manager's OutdatedCommand, sorted
+// by name
+func ParseOutdated(mgr Manager, out []byte) ([]OutdatedPackage, error) {
+	var (
+		packages []OutdatedPackage
+		err      error
+	)
+	switch mgr.Name() {
+	case "npm":
+		packages, err = parseNPMOutdated(out)
+	case "yarn":
+		packages, err = parseYarnOutdated(out)
+	default:
+		return nil, ErrNoStructuredOutput
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages, nil
+}
+
+// npmOutdatedEntry is one package in npm outdated --json --long
+type npmOutdatedEntry struct {
+	Current string `json:"current"`
+	Wanted  string `json:"wanted"`
+	Latest  string `json:"latest"`
+	Type    string `json:"type"`
+}
+
+// parseNPMOutdated reads npm's object keyed by package name. A package
+// installed at several locations maps to an array of entries.
+func parseNPMOutdated(out []byte) ([]OutdatedPackage, error) {
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse npm outdated output: %w", err)
+	}
+
+	var packages []OutdatedPackage
+	for name, msg := range raw {
+		var entries []npmOutdatedEntry
+		if bytes.HasPrefix(bytes.TrimSpace(msg), []byte("[")) {
+			if err := json.Unmarshal(msg, &entries); err != nil {
+				return nil, fmt.Errorf("failed to parse npm outdated entry %s: %w", name, err)
+			}
+		} else {
+			var entry npmOutdatedEntry
+			if err := json.Unmarshal(msg, &entry); err != nil {
+				return nil, fmt.Errorf("failed to parse npm outdated entry %s: %w", name, err)
+			}
+			entries = append(entries, entry)
+		}
+		for _, e := range entries {
+			packages = append(packages, OutdatedPackage{
+				Name:    name,
+				Current: e.Current,
+				Wanted:  e.Wanted,
+				Latest:  e.Latest,
+				Type:    e.Type,
+			})
+		}
+	}
+	return packages, nil
+}
+
+// yarnOutdatedLine is one line of yarn classic's newline-delimited JSON
+type yarnOutdatedLine struct {
+	Type string `json:"type"`
+	Data struct {
+		Head []string   `json:"head"`
+		Body [][]string `json:"body"`
+	} `json:"data"`
+}
+
+// parseYarnOutdated reads the table line of yarn outdated --json, whose
+// columns are named in its head
+func parseYarnOutdated(out []byte) ([]OutdatedPackage, error) {
+	var packages []OutdatedPackage
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var line yarnOutdatedLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil || line.Type != "table" {
+			continue
+		}
+
+		column := make(map[string]int)
+		for i, h := range line.Data.Head {
+			column[h] = i
+		}
+		cell := func(row []string, name string) string {
+			if i, ok := column[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+
+		for _, row := range line.Data.Body {
+			packages = append(packages, OutdatedPackage{
+				Name:    cell(row, "Package"),
+				Current: cell(row, "Current"),
+				Wanted:  cell(row, "Wanted"),
+				Latest:  cell(row, "Latest"),
+				Type:    cell(row, "Package Type"),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read yarn outdated output: %w", err)
+	}
+	return packages, nil
+}
@@ -0,0 +1,71 @@
+package pkgmanager
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseOutdated(t *testing.T) {
+	tests := []struct {
+		name string
+		mgr  Manager
+		out  string
+		want []string
+	}{
+		{
+			name: "npm",
+			mgr:  NewNPM(""),
+			out: `{
+  "lodash": {"current": "4.17.20", "wanted": "4.17.21", "latest": "4.17.21", "dependent": "app", "location": "node_modules/lodash", "type": "dependencies"},
+  "debug": [
+    {"current": "2.6.8", "wanted": "2.6.9", "latest": "4.3.5", "type": "dependencies"},
+    {"current": "4.3.4", "wanted": "4.3.5", "latest": "4.3.5", "type": "devDependencies"}
+  ]
+}`,
+			want: []string{
+				"debug 2.6.8 2.6.9 4.3.5 dependencies",
+				"debug 4.3.4 4.3.5 4.3.5 devDependencies",
+				"lodash 4.17.20 4.17.21 4.17.21 dependencies",
+			},
+		},
+		{
+			name: "npm up to date",
+			mgr:  NewNPM(""),
+			out:  "",
+			want: nil,
+		},
+		{
+			name: "yarn classic",
+			mgr:  NewYarn("", false),
+			out: `{"type":"info","data":"Color legend"}
+{"type":"table","data":{"head":["Package","Current","Wanted","Latest","Package Type","URL"],"body":[["left-pad","1.2.0","1.3.0","1.3.0","devDependencies","https://github.com/stevemao/left-pad"]]}}`,
+			want: []string{"left-pad 1.2.0 1.3.0 1.3.0 devDependencies"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packages, err := ParseOutdated(tt.mgr, []byte(tt.out))
+			if err != nil {
+				t.Fatalf("ParseOutdated() error = %v", err)
+			}
+			var got []string
+			for _, p := range packages {
+				got = append(got, fmt.Sprintf("%s %s %s %s %s", p.Name, p.Current, p.Wanted, p.Latest, p.Type))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ParseOutdated() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutdatedUnsupported(t *testing.T) {
+	if _, err := ParseOutdated(NewBun(""), []byte("| Package | Current |")); !errors.Is(err, ErrNoStructuredOutput) {
+		t.Errorf("bun error = %v, want ErrNoStructuredOutput", err)
+	}
+	if _, err := ParseOutdated(NewNPM(""), []byte("not json")); err == nil {
+		t.Error("expected error for invalid npm output")
+	}
+}
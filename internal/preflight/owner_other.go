@@ -0,0 +1,8 @@
+//go:build !unix
+
+package preflight
+
+// fileOwner is not implemented on this platform
+func fileOwner(path string) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package preflight
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the uid that owns the file at path
+func fileOwner(path string) (int, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
@@ -0,0 +1,205 @@
+// Package preflight checks the host before a project directory is mounted
+// into a container: sync-managed folders, path characters the runtime's
+// volume parsing mishandles, free disk space, and files a container left
+// owned by root.
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Level is the severity of a preflight problem
+type Level string
+
+const (
+	// LevelWarning problems are reported but do not stop the command
+	LevelWarning Level = "warning"
+
+	// LevelError problems stop the command unless --no-preflight is given
+	LevelError Level = "error"
+)
+
+// getuid returns the host user's uid; replaced in tests
+var getuid = os.Getuid
+
+// estimatedPackageSize is a rough average of an installed npm package on disk
+const estimatedPackageSize = 1 << 20
+
+// Problem describes a failed check
+type Problem struct {
+	Check   string
+	Level   Level
+	Message string
+	Hint    string
+}
+
+// Options controls which checks run
+type Options struct {
+	ProjectDir string
+
+	// Packages is the number of packages about to be installed; 0 skips
+	// the disk space check
+	Packages int
+
+	// DiskHeadroom is the multiple of the estimated install size that must
+	// be free on the project's filesystem
+	DiskHeadroom float64
+
+	// ContainerUser is the configured container.user; empty means the
+	// image's default user, often root
+	ContainerUser string
+}
+
+// Run performs all checks and returns the problems found
+func Run(opts Options) []Problem {
+	var problems []Problem
+	for _, check := range []func(Options) *Problem{checkSyncFolder, checkPath, checkDiskSpace, checkOwnership} {
+		if p := check(opts); p != nil {
+			problems = append(problems, *p)
+		}
+	}
+	return problems
+}
+
+// HasErrors returns true if any problem is an error
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Level == LevelError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSyncFolder warns when the project lives in an iCloud or Dropbox
+// managed folder, where placeholder files and sync events break installs
+func checkSyncFolder(opts Options) *Problem {
+	dir := filepath.Clean(opts.ProjectDir)
+
+	var service string
+	home, _ := os.UserHomeDir()
+	if home != "" && isWithin(dir, filepath.Join(home, "Library", "Mobile Documents")) {
+		service = "iCloud Drive"
+	} else if strings.Contains(dir, "Dropbox") {
+		service = "Dropbox"
+	}
+	if service == "" {
+		return nil
+	}
+
+	return &Problem{
+		Check:   "sync-folder",
+		Level:   LevelWarning,
+		Message: fmt.Sprintf("Project is in a %s folder; synced files can break container installs", service),
+		Hint:    "Move the project outside the synced folder or exclude node_modules from sync",
+	}
+}
+
+// checkPath rejects paths that the runtime's -v host:container parsing
+// splits or mistakes for flags
+func checkPath(opts Options) *Problem {
+	dir := filepath.Clean(opts.ProjectDir)
+
+	if strings.Contains(dir, ":") {
+		return &Problem{
+			Check:   "path",
+			Level:   LevelError,
+			Message: fmt.Sprintf("Project path %q contains a colon, which breaks volume mounts", dir),
+			Hint:    "Rename the directory or move the project to a path without colons",
+		}
+	}
+
+	for _, elem := range strings.Split(dir, string(filepath.Separator)) {
+		if strings.HasPrefix(elem, "-") {
+			return &Problem{
+				Check:   "path",
+				Level:   LevelError,
+				Message: fmt.Sprintf("Project path element %q starts with a dash, which the container runtime can read as a flag", elem),
+				Hint:    "Rename the directory so it does not start with a dash",
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkDiskSpace fails when free space is below the headroom multiple of
+// the estimated install size
+func checkDiskSpace(opts Options) *Problem {
+	if opts.Packages <= 0 || opts.DiskHeadroom <= 0 {
+		return nil
+	}
+
+	free, err := freeBytes(opts.ProjectDir)
+	if err != nil {
+		return nil // Unknown free space is not worth blocking on
+	}
+
+	required := uint64(float64(opts.Packages*estimatedPackageSize) * opts.DiskHeadroom)
+	if free >= required {
+		return nil
+	}
+
+	return &Problem{
+		Check:   "disk-space",
+		Level:   LevelError,
+		Message: fmt.Sprintf("Only %s free; installing %d packages needs about %s", formatBytes(free), opts.Packages, formatBytes(required)),
+		Hint:    "Free up disk space or lower preflight.disk_headroom",
+	}
+}
+
+// checkOwnership warns when top-level project entries such as node_modules
+// are owned by root, which a container running as root leaves behind and
+// which host-side tools then cannot modify
+func checkOwnership(opts Options) *Problem {
+	uid := getuid()
+	if uid <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(opts.ProjectDir)
+	if err != nil {
+		return nil
+	}
+	var owned []string
+	for _, entry := range entries {
+		if owner, ok := fileOwner(filepath.Join(opts.ProjectDir, entry.Name())); ok && owner == 0 {
+			owned = append(owned, entry.Name())
+		}
+	}
+	if len(owned) == 0 {
+		return nil
+	}
+	if len(owned) > 3 {
+		owned = append(owned[:3], "...")
+	}
+
+	hint := "Set container.user: host in snapem.yaml so the container writes files as you, then run: sudo chown -R $(id -u):$(id -g) ."
+	if opts.ContainerUser != "" {
+		hint = "Reclaim the files with: sudo chown -R $(id -u):$(id -g) ."
+	}
+	return &Problem{
+		Check:   "ownership",
+		Level:   LevelWarning,
+		Message: fmt.Sprintf("Project files are owned by root (%s); host tools may fail to change them", strings.Join(owned, ", ")),
+		Hint:    hint,
+	}
+}
+
+// isWithin returns true if path is dir or inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// formatBytes renders a byte count in MB or GB
+func formatBytes(n uint64) string {
+	const mb = 1 << 20
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", n/mb)
+}
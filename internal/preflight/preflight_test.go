@@ -0,0 +1,92 @@
+package preflight
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckPath(t *testing.T) {
+	tests := []struct {
+		dir     string
+		wantErr bool
+	}{
+		{"/Users/dev/projects/app", false},
+		{"/Users/dev/projects/app:v2", true},
+		{"/Users/dev/-scratch/app", true},
+		{"/Users/dev/my-app", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			p := checkPath(Options{ProjectDir: tt.dir})
+			if (p != nil) != tt.wantErr {
+				t.Errorf("checkPath(%q) = %v, want error %v", tt.dir, p, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSyncFolder(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		dir      string
+		wantWarn bool
+	}{
+		{filepath.Join(home, "Library", "Mobile Documents", "com~apple~CloudDocs", "app"), true},
+		{filepath.Join(home, "Dropbox", "app"), true},
+		{filepath.Join(home, "Library", "Mobile Documents-old", "app"), false},
+		{filepath.Join(home, "code", "app"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			p := checkSyncFolder(Options{ProjectDir: tt.dir})
+			if (p != nil) != tt.wantWarn {
+				t.Errorf("checkSyncFolder(%q) = %v, want warning %v", tt.dir, p, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+
+	if p := checkDiskSpace(Options{ProjectDir: dir, Packages: 1, DiskHeadroom: 2}); p != nil {
+		t.Errorf("small install failed disk check: %v", p)
+	}
+
+	// No filesystem has an exabyte free
+	if p := checkDiskSpace(Options{ProjectDir: dir, Packages: 1 << 40, DiskHeadroom: 2}); p == nil {
+		t.Error("huge install passed disk check")
+	}
+}
+
+func TestCheckOwnership(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("needs root to create root-owned files")
+	}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { getuid = os.Getuid }()
+	getuid = func() int { return 501 }
+
+	p := checkOwnership(Options{ProjectDir: dir})
+	if p == nil || !strings.Contains(p.Message, "node_modules") || !strings.Contains(p.Hint, "container.user: host") {
+		t.Fatalf("checkOwnership() = %+v, want a warning suggesting container.user", p)
+	}
+	if p := checkOwnership(Options{ProjectDir: dir, ContainerUser: "host"}); p == nil || strings.Contains(p.Hint, "container.user") {
+		t.Errorf("checkOwnership() with a container user = %+v, want only the chown hint", p)
+	}
+
+	getuid = func() int { return 0 }
+	if p := checkOwnership(Options{ProjectDir: dir}); p != nil {
+		t.Errorf("checkOwnership() as root = %+v, want nil", p)
+	}
+}
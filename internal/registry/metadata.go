@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// abbreviatedMetadata is the compact packument format npm itself uses
+	// for installs; it has dist-tags and each version's dependencies,
+	// deprecation message and dist, attestations included
+	abbreviatedMetadata = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8"
+
+	// fullMetadata is the complete packument, which adds publish times,
+	// the repository and each version's maintainers and scripts
+	fullMetadata = "application/json"
+)
+
+// ErrUnreachable is returned when the registry cannot be reached at all
+var ErrUnreachable = errors.New("npm registry unreachable")
+
+// Packument is the part of the registry's package document snapem reads.
+// Time and Repository are only set in the full document.
+type Packument struct {
+	DistTags   map[string]string  `json:"dist-tags"`
+	Time       map[string]string  `json:"time"`
+	Repository json.RawMessage    `json:"repository"`
+	Versions   map[string]Version `json:"versions"`
+}
+
+// Version is the metadata of one published version. Maintainers and
+// Scripts are only set in the full document and the version document.
+type Version struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+
+	// Deprecated is usually the message, but old documents may hold
+	// other JSON values; see DeprecationMessage
+	Deprecated json.RawMessage `json:"deprecated"`
+
+	Maintainers      []Maintainer      `json:"maintainers"`
+	Scripts          map[string]string `json:"scripts"`
+	HasInstallScript bool              `json:"hasInstallScript"`
+	Dist             Dist              `json:"dist"`
+}
+
+// Maintainer is an npm user allowed to publish the package
+type Maintainer struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Dist describes a version's tarball
+type Dist struct {
+	Tarball   string `json:"tarball"`
+	Shasum    string `json:"shasum"`
+	Integrity string `json:"integrity"`
+
+	// Attestations is set when the version was published with an npm
+	// provenance attestation
+	Attestations *struct {
+		URL string `json:"url"`
+	} `json:"attestations"`
+}
+
+// DeprecationMessage returns the version's deprecation message, or "" if
+// it is not deprecated
+func (v Version) DeprecationMessage() string {
+	var message string
+	if len(v.Deprecated) == 0 || json.Unmarshal(v.Deprecated, &message) != nil {
+		return ""
+	}
+	return message
+}
+
+// call is a registry request shared by everyone asking for the same
+// document; done is closed once doc and err are set
+type call struct {
+	done chan struct{}
+	doc  any
+	err  error
+}
+
+// Packument returns the abbreviated package document, or nil if the
+// registry does not know the package. Each document is fetched once per
+// client, however many scanners ask for it.
+func (c *Client) Packument(ctx context.Context, name string) (*Packument, error) {
+	return memoized[Packument](c, ctx, name, name, abbreviatedMetadata, url.PathEscape(name))
+}
+
+// FullPackument returns the full package document, or nil if the registry
+// does not know the package. It is much larger than the abbreviated one,
+// so it is best kept to the packages about to be installed.
+func (c *Client) FullPackument(ctx context.Context, name string) (*Packument, error) {
+	return memoized[Packument](c, ctx, name, name, fullMetadata, url.PathEscape(name))
+}
+
+// Version returns the document of one version of a package, or nil if
+// the registry does not know it
+func (c *Client) Version(ctx context.Context, name, version string) (*Version, error) {
+	return memoized[Version](c, ctx, name, name+"@"+version, fullMetadata, url.PathEscape(name)+"/"+url.PathEscape(version))
+}
+
+// memoized returns the document at path in name's registry, fetching it
+// only if no earlier or concurrent call did. Failed fetches are not kept,
+// so a later call tries again. what names the document in errors.
+func memoized[T any](c *Client, ctx context.Context, name, what, accept, path string) (*T, error) {
+	key := accept + " " + path
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[key] = cl
+	}
+	c.mu.Unlock()
+
+	if ok {
+		select {
+		case <-cl.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		cl.doc, cl.err = fetch[T](c, ctx, name, what, accept, path)
+		if cl.err != nil {
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+		}
+		close(cl.done)
+	}
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	return cl.doc.(*T), nil
+}
+
+// fetch downloads and decodes a document, returning nil if the registry
+// does not have it
+func fetch[T any](c *Client, ctx context.Context, name, what, accept, path string) (*T, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.registries.URL(name)+"/"+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("npm registry returned status %d for %s", resp.StatusCode, what)
+	}
+
+	var doc T
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode registry response for %s: %w", what, err)
+	}
+	return &doc, nil
+}
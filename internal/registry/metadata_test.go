@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPackumentMemoized(t *testing.T) {
+	var requests, failures atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/flaky" && failures.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"dist-tags": {"latest": "1.0.0"}, "versions": {"1.0.0": {"deprecated": "use v2"}}}`))
+	}))
+	defer server.Close()
+	client := NewClient(Registries{Default: server.URL})
+	client.SetHTTPClient(http.DefaultClient)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Packument(context.Background(), "lodash"); err != nil {
+				t.Errorf("Packument() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	doc, err := client.Packument(context.Background(), "lodash")
+	if err != nil {
+		t.Fatalf("Packument() error = %v", err)
+	}
+	if got := doc.Versions["1.0.0"].DeprecationMessage(); got != "use v2" {
+		t.Errorf("DeprecationMessage() = %q, want %q", got, "use v2")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("11 lookups made %d requests, want 1", n)
+	}
+
+	// The full document is a different response
+	if _, err := client.FullPackument(context.Background(), "lodash"); err != nil {
+		t.Fatalf("FullPackument() error = %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want the full document fetched separately", n)
+	}
+
+	// Failures are not kept
+	if _, err := client.Packument(context.Background(), "flaky"); err == nil {
+		t.Fatal("Packument() of a failing registry succeeded")
+	}
+	if _, err := client.Packument(context.Background(), "flaky"); err != nil {
+		t.Errorf("Packument() retry error = %v", err)
+	}
+}
+
+func TestPackumentUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	client := NewClient(Registries{Default: server.URL})
+	client.SetHTTPClient(http.DefaultClient)
+
+	if _, err := client.Version(context.Background(), "lodash", "4.17.21"); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Version() error = %v, want ErrUnreachable", err)
+	}
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// Registries maps packages to the registry that serves them
+type Registries struct {
+	// Default serves unscoped packages and scopes without a mapping; empty
+	// means the public registry
+	Default string
+
+	// Scopes maps a scope such as @mycorp to its registry
+	Scopes map[string]string
+}
+
+// URL returns the base URL of the registry serving the package, without a
+// trailing slash
+func (r Registries) URL(name string) string {
+	base := r.Default
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if scoped, ok := r.Scopes[scope]; ok {
+			base = scoped
+		}
+	}
+	if base == "" {
+		base = DefaultURL
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// IsPublic returns true if the package comes from the public npm registry
+func (r Registries) IsPublic(name string) bool {
+	return r.URL(name) == DefaultURL
+}
+
+// Npmrc holds the registry settings of an .npmrc file
+type Npmrc struct {
+	Registry string
+	Scopes   map[string]string
+}
+
+// ReadNpmrc reads the registry settings of the .npmrc at path. A missing
+// file has no settings.
+func ReadNpmrc(path string) (Npmrc, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Npmrc{}, nil
+	}
+	if err != nil {
+		return Npmrc{}, err
+	}
+	defer f.Close()
+	return ParseNpmrc(f)
+}
+
+// ParseNpmrc reads registry= and @scope:registry= settings in .npmrc
+// format, expanding ${VAR} references as npm does. Other settings,
+// including auth tokens, are ignored.
+func ParseNpmrc(r io.Reader) (Npmrc, error) {
+	rc := Npmrc{Scopes: make(map[string]string)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = os.ExpandEnv(strings.Trim(strings.TrimSpace(value), `"'`))
+
+		switch {
+		case key == "registry":
+			rc.Registry = value
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			rc.Scopes[strings.TrimSuffix(key, ":registry")] = value
+		}
+	}
+	return rc, scanner.Err()
+}
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNpmrc(t *testing.T) {
+	t.Setenv("REGISTRY_HOST", "npm.acme.internal")
+	rc, err := ParseNpmrc(strings.NewReader(`# company registries
+registry = https://verdaccio.acme.internal/
+@acme:registry=https://${REGISTRY_HOST}/api/npm/
+; @old:registry=https://old.acme.internal/
+//npm.acme.internal/:_authToken=secret
+always-auth=true
+`))
+	if err != nil {
+		t.Fatalf("ParseNpmrc() error = %v", err)
+	}
+	if rc.Registry != "https://verdaccio.acme.internal/" {
+		t.Errorf("Registry = %q", rc.Registry)
+	}
+	if len(rc.Scopes) != 1 || rc.Scopes["@acme"] != "https://npm.acme.internal/api/npm/" {
+		t.Errorf("Scopes = %v", rc.Scopes)
+	}
+}
+
+func TestRegistriesURL(t *testing.T) {
+	r := Registries{
+		Default: "https://verdaccio.acme.internal/",
+		Scopes:  map[string]string{"@acme": "https://npm.acme.internal/"},
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"lodash", "https://verdaccio.acme.internal"},
+		{"@acme/ui", "https://npm.acme.internal"},
+		{"@types/node", "https://verdaccio.acme.internal"},
+		{"@acme", "https://verdaccio.acme.internal"},
+	}
+	for _, tt := range tests {
+		if got := r.URL(tt.name); got != tt.want {
+			t.Errorf("URL(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if !(Registries{}).IsPublic("lodash") {
+		t.Error("IsPublic() = false for the default registries")
+	}
+	if r.IsPublic("@acme/ui") {
+		t.Error("IsPublic() = true for a scoped private registry")
+	}
+}
@@ -0,0 +1,290 @@
+// Package registry fetches npm package metadata, once per document, for
+// the scanners that read it, and resolves package specs to concrete
+// versions against it.
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+const (
+	// DefaultURL is the public npm registry
+	DefaultURL = "https://registry.npmjs.org"
+
+	// workers bounds concurrent registry requests
+	workers = 8
+)
+
+// ErrNotFound is returned when the registry has no package or version
+// matching a spec
+var ErrNotFound = errors.New("not found in the registry")
+
+// Client fetches package metadata from npm registries and resolves
+// package specs against it. Share one client to fetch each document once.
+type Client struct {
+	httpClient *http.Client
+	registries Registries
+
+	// calls memoizes fetched documents by Accept header and path
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// NewClient creates a client that fetches each package from its registry
+func NewClient(registries Registries) *Client {
+	return &Client{
+		httpClient: httpclient.New(),
+		registries: registries,
+		calls:      make(map[string]*call),
+	}
+}
+
+// SetHTTPClient replaces the HTTP client, e.g. to turn off retries
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// URL returns the registry the client fetches a package from
+func (c *Client) URL(name string) string {
+	return c.registries.URL(name)
+}
+
+// Resolved is a package spec resolved to a published version
+type Resolved struct {
+	Name    string
+	Version string
+
+	// Dependencies are the version's declared dependency ranges, including
+	// optional ones
+	Dependencies map[string]string
+}
+
+// Resolve returns the version a spec installs: a dist-tag such as latest
+// (the default), an exact version, or the highest version in a semver
+// range, preferring the latest tag when it satisfies the range as npm
+// does. It returns an error if the package or a matching version does not
+// exist.
+func (c *Client) Resolve(ctx context.Context, name, spec string) (*Resolved, error) {
+	doc, err := c.Packument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+
+	version, ok := doc.resolve(spec)
+	if !ok {
+		return nil, fmt.Errorf("%s@%s: %w", name, spec, ErrNotFound)
+	}
+	meta := doc.Versions[version]
+	deps := make(map[string]string, len(meta.Dependencies)+len(meta.OptionalDependencies))
+	for dep, spec := range meta.Dependencies {
+		deps[dep] = spec
+	}
+	for dep, spec := range meta.OptionalDependencies {
+		deps[dep] = spec
+	}
+	return &Resolved{Name: name, Version: version, Dependencies: deps}, nil
+}
+
+// resolve picks the version a spec refers to
+func (p *Packument) resolve(spec string) (string, bool) {
+	if spec == "" || spec == "*" {
+		spec = "latest"
+	}
+	if v, ok := p.DistTags[spec]; ok {
+		return v, true
+	}
+	if _, ok := p.Versions[spec]; ok {
+		return spec, true
+	}
+
+	constraint, err := semver.NewConstraint(spec)
+	if err != nil {
+		return "", false
+	}
+	if latest, ok := p.DistTags["latest"]; ok {
+		if v, err := semver.NewVersion(latest); err == nil && constraint.Check(v) {
+			return latest, true
+		}
+	}
+
+	var best *semver.Version
+	var bestRaw string
+	for raw := range p.Versions {
+		v, err := semver.NewVersion(raw)
+		if err != nil || !constraint.Check(v) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best, bestRaw = v, raw
+		}
+	}
+	return bestRaw, best != nil
+}
+
+// Limits bound the dependency tree ResolveTree walks
+type Limits struct {
+	// MaxDepth is the number of dependency levels below the requested
+	// packages; 0 resolves only the requested packages
+	MaxDepth int
+
+	// MaxPackages caps the packages in the tree; 0 means no cap
+	MaxPackages int
+}
+
+// Tree is the resolved dependency closure of the requested packages
+type Tree struct {
+	Packages []manifest.Package
+
+	// Truncated is set when a limit stopped the walk before the whole
+	// closure was resolved
+	Truncated bool
+}
+
+// ResolveTree resolves the given packages, whose versions may be tags or
+// ranges, and walks their dependencies level by level, so a scan covers
+// everything an install is about to add. Requested packages that cannot be
+// resolved are kept unchanged, and dependencies that cannot be resolved,
+// such as git or aliased specs, are left out. progress, if set, is called
+// with the number of packages resolved so far after each level. The error
+// reports the first registry failure; the returned tree is still usable.
+func (c *Client) ResolveTree(ctx context.Context, packages []manifest.Package, limits Limits, progress func(resolved int)) (*Tree, error) {
+	tree := &Tree{}
+	visited := make(map[string]bool)
+	queued := make(map[string]bool)
+	var firstErr error
+
+	level := packages
+	for depth := 0; len(level) > 0; depth++ {
+		var next []manifest.Package
+		for _, r := range c.resolveAll(ctx, level) {
+			if r.err != nil && firstErr == nil && !errors.Is(r.err, ErrNotFound) {
+				firstErr = r.err
+			}
+			if depth > 0 && r.resolved == nil {
+				continue
+			}
+
+			key := r.pkg.Name + "@" + r.pkg.Version
+			if visited[key] {
+				continue
+			}
+			if depth > 0 && limits.MaxPackages > 0 && len(tree.Packages) >= limits.MaxPackages {
+				tree.Truncated = true
+				break
+			}
+			visited[key] = true
+			tree.Packages = append(tree.Packages, r.pkg)
+
+			if r.resolved == nil || len(r.resolved.Dependencies) == 0 {
+				continue
+			}
+			if depth >= limits.MaxDepth {
+				tree.Truncated = true
+				continue
+			}
+			for _, name := range slices.Sorted(maps.Keys(r.resolved.Dependencies)) {
+				spec := r.resolved.Dependencies[name]
+				if queued[name+"@"+spec] {
+					continue
+				}
+				queued[name+"@"+spec] = true
+				// A dependency of a package about to be installed is
+				// installed with it, so it is new as well
+				next = append(next, manifest.Package{Name: name, Version: spec, Ecosystem: "npm", New: r.pkg.New})
+			}
+		}
+
+		if progress != nil {
+			progress(len(tree.Packages))
+		}
+		if limits.MaxPackages > 0 && len(tree.Packages) >= limits.MaxPackages && len(next) > 0 {
+			tree.Truncated = true
+			break
+		}
+		level = next
+	}
+	return tree, firstErr
+}
+
+// resolution is the outcome of resolving one package
+type resolution struct {
+	pkg      manifest.Package
+	resolved *Resolved
+	err      error
+}
+
+// resolveAll resolves packages concurrently, keeping their order
+func (c *Client) resolveAll(ctx context.Context, packages []manifest.Package) []resolution {
+	results := make([]resolution, len(packages))
+	parallel(len(packages), func(i int) {
+		results[i].pkg = packages[i]
+		resolved, err := c.Resolve(ctx, packages[i].Name, packages[i].Version)
+		if err != nil {
+			results[i].err = err
+			return
+		}
+		results[i].resolved = resolved
+		results[i].pkg.Version = resolved.Version
+	})
+	return results
+}
+
+// FetchAll calls fetch once for each package name with all of its
+// versions, a bounded number of names at a time, and returns the results
+// and errors by name. Scanners use it so versions of one package share
+// one document and one registry request.
+func FetchAll[T any](ctx context.Context, packages []manifest.Package, fetch func(ctx context.Context, name string, versions []string) (T, error)) (map[string]T, map[string]error) {
+	var names []string
+	versions := make(map[string][]string)
+	for _, pkg := range packages {
+		if _, ok := versions[pkg.Name]; !ok {
+			names = append(names, pkg.Name)
+		}
+		versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+	}
+
+	results := make([]T, len(names))
+	errs := make([]error, len(names))
+	parallel(len(names), func(i int) {
+		results[i], errs[i] = fetch(ctx, names[i], versions[names[i]])
+	})
+
+	byName := make(map[string]T, len(names))
+	errsByName := make(map[string]error)
+	for i, name := range names {
+		byName[name] = results[i]
+		if errs[i] != nil {
+			errsByName[name] = errs[i]
+		}
+	}
+	return byName, errsByName
+}
+
+// parallel calls fn for each index below n, at most workers at a time
+func parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,190 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	docs := map[string]string{
+		"/lodash": `{"dist-tags": {"latest": "4.17.21", "next": "5.0.0-beta.1"}, "versions": {
+			"4.17.20": {}, "4.17.21": {}, "5.0.0-beta.1": {}}}`,
+		"/express": `{"dist-tags": {"latest": "4.19.2"}, "versions": {
+			"3.21.2": {"dependencies": {"debug": "~2.2.0"}},
+			"4.18.0": {"dependencies": {"debug": "2.6.9"}},
+			"4.19.2": {"dependencies": {"debug": "2.6.9", "lodash": "^4.17.0", "forked": "git+https://example.com/forked.git"}}}}`,
+		"/debug":         `{"dist-tags": {"latest": "4.3.4"}, "versions": {"2.2.0": {}, "2.6.9": {"dependencies": {"ms": "2.0.0"}}, "4.3.4": {}}}`,
+		"/ms":            `{"dist-tags": {"latest": "2.1.3"}, "versions": {"2.0.0": {"optionalDependencies": {"lodash": "4.17.20"}}, "2.1.3": {}}}`,
+		"/@types%2Fnode": `{"dist-tags": {"latest": "20.1.0"}, "versions": {"20.1.0": {}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolve(t *testing.T) {
+	client := NewClient(Registries{Default: newTestServer(t).URL})
+
+	tests := []struct {
+		name, spec string
+		want       string
+	}{
+		{"lodash", "latest", "4.17.21"},
+		{"lodash", "", "4.17.21"},
+		{"lodash", "next", "5.0.0-beta.1"},
+		{"lodash", "4.17.20", "4.17.20"},
+		{"lodash", "^4.0.0", "4.17.21"},
+		{"lodash", "~4.17.20 <4.17.21", "4.17.20"},
+		{"express", "^3.0.0", "3.21.2"},
+		{"@types/node", "*", "20.1.0"},
+	}
+	for _, tt := range tests {
+		got, err := client.Resolve(context.Background(), tt.name, tt.spec)
+		if err != nil {
+			t.Errorf("Resolve(%q, %q) error = %v", tt.name, tt.spec, err)
+			continue
+		}
+		if got.Version != tt.want {
+			t.Errorf("Resolve(%q, %q) = %s, want %s", tt.name, tt.spec, got.Version, tt.want)
+		}
+	}
+
+	for _, spec := range []struct{ name, spec string }{{"missing", "latest"}, {"lodash", "^9.0.0"}} {
+		if _, err := client.Resolve(context.Background(), spec.name, spec.spec); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Resolve(%q, %q) error = %v, want ErrNotFound", spec.name, spec.spec, err)
+		}
+	}
+}
+
+func TestResolveTree(t *testing.T) {
+	requested := []manifest.Package{
+		{Name: "express", Version: "latest", Ecosystem: "npm", Direct: true, New: true},
+		{Name: "lodash", Version: "^4.17.0", Ecosystem: "npm", Direct: true, New: true},
+		{Name: "unpublished", Version: "1.0.0", Ecosystem: "npm", Direct: true, New: true},
+	}
+
+	tests := []struct {
+		name      string
+		limits    Limits
+		want      []string
+		truncated bool
+	}{
+		{
+			name:   "whole tree",
+			limits: Limits{MaxDepth: 10},
+			want:   []string{"express@4.19.2", "lodash@4.17.21", "unpublished@1.0.0", "debug@2.6.9", "ms@2.0.0", "lodash@4.17.20"},
+		},
+		{
+			name:      "depth limit",
+			limits:    Limits{MaxDepth: 1},
+			want:      []string{"express@4.19.2", "lodash@4.17.21", "unpublished@1.0.0", "debug@2.6.9"},
+			truncated: true,
+		},
+		{
+			name:      "requested only",
+			limits:    Limits{},
+			want:      []string{"express@4.19.2", "lodash@4.17.21", "unpublished@1.0.0"},
+			truncated: true,
+		},
+		{
+			name:      "package limit",
+			limits:    Limits{MaxDepth: 10, MaxPackages: 4},
+			want:      []string{"express@4.19.2", "lodash@4.17.21", "unpublished@1.0.0", "debug@2.6.9"},
+			truncated: true,
+		},
+	}
+
+	server := newTestServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var progress []int
+			tree, err := NewClient(Registries{Default: server.URL}).ResolveTree(context.Background(), requested, tt.limits, func(resolved int) {
+				progress = append(progress, resolved)
+			})
+			if err != nil {
+				t.Fatalf("ResolveTree() error = %v", err)
+			}
+
+			var got []string
+			for _, pkg := range tree.Packages {
+				got = append(got, pkg.Name+"@"+pkg.Version)
+				if pkg.Direct != (len(got) <= len(requested)) {
+					t.Errorf("%s@%s Direct = %v", pkg.Name, pkg.Version, pkg.Direct)
+				}
+				if !pkg.New {
+					t.Errorf("%s@%s New = false, want dependencies of new packages new too", pkg.Name, pkg.Version)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ResolveTree() = %v, want %v", got, tt.want)
+			}
+			if tree.Truncated != tt.truncated {
+				t.Errorf("Truncated = %v, want %v", tree.Truncated, tt.truncated)
+			}
+			if len(progress) == 0 || progress[len(progress)-1] != len(tt.want) {
+				t.Errorf("progress = %v, want to end at %d", progress, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestResolveTreeUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client := NewClient(Registries{Default: url})
+	client.httpClient = http.DefaultClient // skip retries
+
+	packages := []manifest.Package{{Name: "lodash", Version: "latest", Ecosystem: "npm", Direct: true}}
+	tree, err := client.ResolveTree(context.Background(), packages, Limits{MaxDepth: 10}, nil)
+	if err == nil {
+		t.Fatal("ResolveTree() error = nil, want registry failure")
+	}
+	if len(tree.Packages) != 1 || tree.Packages[0].Version != "latest" {
+		t.Errorf("ResolveTree() = %+v, want the requested package unchanged", tree.Packages)
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.20"},
+		{Name: "express", Version: "4.19.2"},
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "missing", Version: "1.0.0"},
+	}
+
+	var calls atomic.Int32
+	results, errs := FetchAll(context.Background(), packages, func(ctx context.Context, name string, versions []string) ([]string, error) {
+		calls.Add(1)
+		if name == "missing" {
+			return nil, ErrNotFound
+		}
+		return versions, nil
+	})
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("fetch called %d times, want once per name", n)
+	}
+	if got := results["lodash"]; !slices.Equal(got, []string{"4.17.20", "4.17.21"}) {
+		t.Errorf("lodash versions = %v, want both", got)
+	}
+	if len(errs) != 1 || !errors.Is(errs["missing"], ErrNotFound) {
+		t.Errorf("errs = %v, want only missing", errs)
+	}
+}
@@ -0,0 +1,249 @@
+// Package sbom builds software bills of materials from a project's
+// resolved dependencies.
+package sbom
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDataLicense = "CC0-1.0"
+	spdxNamespace   = "https://github.com/positronico/snapem/spdx/"
+
+	// spdxNoAssertion marks a field whose value snapem does not know
+	spdxNoAssertion = "NOASSERTION"
+
+	documentID = "SPDXRef-DOCUMENT"
+	rootID     = "SPDXRef-RootPackage"
+)
+
+// spdxChecksumAlgorithms maps subresource integrity hash names to SPDX
+// checksum algorithms
+var spdxChecksumAlgorithms = map[string]string{
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+	"sha384": "SHA384",
+	"sha512": "SHA512",
+}
+
+// invalidIDChars matches characters not allowed in an SPDX identifier
+var invalidIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// Project describes the package the SBOM is for
+type Project struct {
+	Name    string
+	Version string
+
+	// Tool is the creator recorded in the document, e.g. "snapem-1.2.0"
+	Tool string
+
+	// Created is the document creation time
+	Created time.Time
+
+	// Registries tells which registry each package comes from; purls of
+	// packages from a private registry carry its repository_url
+	Registries registry.Registries
+}
+
+// SPDXDocument is an SPDX 2.3 document in its JSON serialization
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXCreationInfo records when and by what the document was created
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is one package in the document
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+// SPDXChecksum is a package checksum
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// SPDXExternalRef points to the package in another system, here its purl
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// SPDXRelationship links two elements of the document
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// NewSPDX builds an SPDX document for the project and its dependencies,
+// sorted by name. The document DESCRIBES the root package, which
+// DEPENDS_ON every dependency.
+func NewSPDX(project Project, packages []manifest.Package) *SPDXDocument {
+	name := project.Name
+	if name == "" {
+		name = "project"
+	}
+
+	// Lockfile order is not stable, so sort for reproducible output
+	sorted := slices.Clone(packages)
+	slices.SortFunc(sorted, func(a, b manifest.Package) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Version, b.Version))
+	})
+
+	doc := &SPDXDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            documentID,
+		Name:              name,
+		DocumentNamespace: spdxNamespace + spdxIDPart(name) + "-" + namespaceHash(project, sorted),
+		CreationInfo: SPDXCreationInfo{
+			Created:  project.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + project.Tool},
+		},
+		Packages: []SPDXPackage{{
+			SPDXID:           rootID,
+			Name:             name,
+			VersionInfo:      project.Version,
+			DownloadLocation: spdxNoAssertion,
+		}},
+		Relationships: []SPDXRelationship{{
+			SPDXElementID:      documentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: rootID,
+		}},
+	}
+
+	seen := make(map[string]bool)
+	ids := make(map[string]bool)
+	for _, pkg := range sorted {
+		if seen[pkg.Name+"@"+pkg.Version] {
+			continue
+		}
+		seen[pkg.Name+"@"+pkg.Version] = true
+
+		// Names that differ only in disallowed characters would collide
+		id := "SPDXRef-Package-" + spdxIDPart(pkg.Name) + "-" + spdxIDPart(pkg.Version)
+		for base, n := id, 2; ids[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		ids[id] = true
+
+		download := pkg.Resolved
+		if download == "" {
+			download = spdxNoAssertion
+		}
+
+		doc.Packages = append(doc.Packages, SPDXPackage{
+			SPDXID:           id,
+			Name:             pkg.Name,
+			VersionInfo:      pkg.Version,
+			DownloadLocation: download,
+			Checksums:        integrityChecksums(pkg.Integrity),
+			ExternalRefs: []SPDXExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  purl(pkg, project.Registries),
+			}},
+		})
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementID:      rootID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return doc
+}
+
+// purl returns the package's purl, qualified with the registry it comes
+// from when that is not the public npm registry
+func purl(pkg manifest.Package, registries registry.Registries) string {
+	if registries.IsPublic(pkg.Name) {
+		return pkg.PURL()
+	}
+	return pkg.PURL() + "?repository_url=" + url.QueryEscape(registries.URL(pkg.Name))
+}
+
+// Write writes the document as indented JSON
+func (d *SPDXDocument) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// integrityChecksums converts a subresource integrity value such as
+// "sha512-<base64>" into hex checksums, skipping unknown algorithms
+func integrityChecksums(integrity string) []SPDXChecksum {
+	var checksums []SPDXChecksum
+	for _, entry := range strings.Fields(integrity) {
+		alg, value, ok := strings.Cut(entry, "-")
+		if !ok {
+			continue
+		}
+		algorithm, ok := spdxChecksumAlgorithms[alg]
+		if !ok {
+			continue
+		}
+		// Strip integrity options, e.g. "sha512-abc?foo"
+		value, _, _ = strings.Cut(value, "?")
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		checksums = append(checksums, SPDXChecksum{
+			Algorithm:     algorithm,
+			ChecksumValue: hex.EncodeToString(raw),
+		})
+	}
+	return checksums
+}
+
+// spdxIDPart replaces characters SPDX identifiers do not allow
+func spdxIDPart(s string) string {
+	return strings.Trim(invalidIDChars.ReplaceAllString(s, "-"), "-")
+}
+
+// namespaceHash makes the document namespace unique per project, package
+// set and creation time
+func namespaceHash(project Project, packages []manifest.Package) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s@%s\x00%s\x00", project.Name, project.Version, project.Created.UTC().Format(time.RFC3339Nano))
+	for _, pkg := range packages {
+		fmt.Fprintf(h, "%s@%s\x00", pkg.Name, pkg.Version)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
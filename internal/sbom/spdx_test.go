@@ -0,0 +1,118 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+)
+
+func TestNewSPDX(t *testing.T) {
+	project := Project{
+		Name:    "@acme/web",
+		Version: "1.0.0",
+		Tool:    "snapem-1.2.0",
+		Created: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm", Resolved: "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz", Integrity: "sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk="},
+		{Name: "@babel/core", Version: "7.24.0", Ecosystem: "npm"},
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+	}
+
+	doc := NewSPDX(project, packages)
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.SPDXID != "SPDXRef-DOCUMENT" {
+		t.Errorf("header = %s %s", doc.SPDXVersion, doc.SPDXID)
+	}
+	if doc.CreationInfo.Created != "2026-03-01T12:00:00Z" {
+		t.Errorf("created = %s", doc.CreationInfo.Created)
+	}
+	if len(doc.CreationInfo.Creators) != 1 || doc.CreationInfo.Creators[0] != "Tool: snapem-1.2.0" {
+		t.Errorf("creators = %v", doc.CreationInfo.Creators)
+	}
+
+	// Root plus two unique dependencies, sorted by name
+	if len(doc.Packages) != 3 {
+		t.Fatalf("got %d packages, want 3", len(doc.Packages))
+	}
+	babel, lodash := doc.Packages[1], doc.Packages[2]
+	if babel.SPDXID != "SPDXRef-Package-babel-core-7.24.0" {
+		t.Errorf("babel SPDXID = %s", babel.SPDXID)
+	}
+	if babel.DownloadLocation != "NOASSERTION" || len(babel.Checksums) != 0 {
+		t.Errorf("babel without lockfile data = %+v", babel)
+	}
+	if lodash.DownloadLocation != packages[0].Resolved {
+		t.Errorf("lodash downloadLocation = %s", lodash.DownloadLocation)
+	}
+	if len(lodash.Checksums) != 1 || lodash.Checksums[0].Algorithm != "SHA1" || lodash.Checksums[0].ChecksumValue != "da39a3ee5e6b4b0d3255bfef95601890afd80709" {
+		t.Errorf("lodash checksums = %+v", lodash.Checksums)
+	}
+	if got := lodash.ExternalRefs[0].ReferenceLocator; got != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("lodash purl = %s", got)
+	}
+
+	rel := doc.Relationships[0]
+	if rel.SPDXElementID != "SPDXRef-DOCUMENT" || rel.RelationshipType != "DESCRIBES" || rel.RelatedSPDXElement != doc.Packages[0].SPDXID {
+		t.Errorf("first relationship = %+v", rel)
+	}
+	if len(doc.Relationships) != 3 {
+		t.Errorf("got %d relationships, want 3", len(doc.Relationships))
+	}
+
+	// The namespace is stable for the same input, whatever the order
+	reordered := NewSPDX(project, []manifest.Package{packages[2], packages[1], packages[0]})
+	if reordered.DocumentNamespace != doc.DocumentNamespace {
+		t.Errorf("namespace changed with package order")
+	}
+
+	// Packages from a private registry name it in their purl
+	project.Registries = registry.Registries{Scopes: map[string]string{"@babel": "https://npm.acme.internal/"}}
+	private := NewSPDX(project, packages)
+	if got := private.Packages[1].ExternalRefs[0].ReferenceLocator; got != "pkg:npm/@babel/core@7.24.0?repository_url=https%3A%2F%2Fnpm.acme.internal" {
+		t.Errorf("babel purl = %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"spdxElementId"`) {
+		t.Error("relationships not serialized with SPDX field names")
+	}
+}
+
+func TestIntegrityChecksums(t *testing.T) {
+	tests := []struct {
+		name      string
+		integrity string
+		want      []string
+	}{
+		{"empty", "", nil},
+		{"sha1", "sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk=", []string{"SHA1:da39a3ee5e6b4b0d3255bfef95601890afd80709"}},
+		{"unknown algorithm", "md5-1B2M2Y8AsgTpgAmY7PhCfg==", nil},
+		{"invalid base64", "sha512-!!!", nil},
+		{"multiple", "md5-1B2M2Y8AsgTpgAmY7PhCfg== sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk=", []string{"SHA1:da39a3ee5e6b4b0d3255bfef95601890afd80709"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range integrityChecksums(tt.integrity) {
+				got = append(got, c.Algorithm+":"+c.ChecksumValue)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("integrityChecksums(%q) = %v, want %v", tt.integrity, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// Cache stores per-package scan findings on disk
+type Cache struct {
+	dir        string
+	ttl        time.Duration
+	registries registry.Registries
+}
+
+// entry is the on-disk representation of a cached package result
+type entry struct {
+	Scanner   string          `json:"scanner"`
+	Ecosystem string          `json:"ecosystem"`
+	Registry  string          `json:"registry,omitempty"`
+	Package   string          `json:"package"`
+	Version   string          `json:"version"`
+	Findings  []types.Finding `json:"findings"`
+	CachedAt  time.Time       `json:"cached_at"`
+}
+
+// New creates a cache rooted at the configured directory
+func New(cfg config.CacheConfig) *Cache {
+	return &Cache{
+		dir: filepath.Join(cfg.Directory, "scan"),
+		ttl: cfg.TTL,
+	}
+}
+
+// SetRegistries sets the registries npm packages resolve from, so the same
+// name and version from a private registry never shares an entry with the
+// public one. The public registry is assumed until it is called.
+func (c *Cache) SetRegistries(registries registry.Registries) {
+	c.registries = registries
+}
+
+// Get returns the cached findings for a package if present and not expired
+func (c *Cache) Get(scanner string, pkg manifest.Package) ([]types.Finding, bool) {
+	data, err := os.ReadFile(c.path(scanner, pkg))
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+
+	// Guard against hash collisions and stale formats
+	if e.Scanner != scanner || e.Ecosystem != pkg.Ecosystem || e.Registry != c.source(pkg) ||
+		e.Package != pkg.Name || e.Version != pkg.Version {
+		return nil, false
+	}
+
+	if c.ttl > 0 && time.Since(e.CachedAt) > c.ttl {
+		return nil, false
+	}
+
+	return e.Findings, true
+}
+
+// Put stores the findings for a package. The file is written to a temp
+// file and renamed into place so concurrent snapem processes sharing the
+// cache directory never observe a partial write.
+func (c *Cache) Put(scanner string, pkg manifest.Package, findings []types.Finding) error {
+	if findings == nil {
+		findings = []types.Finding{}
+	}
+
+	data, err := json.Marshal(entry{
+		Scanner:   scanner,
+		Ecosystem: pkg.Ecosystem,
+		Registry:  c.source(pkg),
+		Package:   pkg.Name,
+		Version:   pkg.Version,
+		Findings:  findings,
+		CachedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeAtomic(c.path(scanner, pkg), data)
+}
+
+// valueEntry is the on-disk representation of a cached arbitrary value
+type valueEntry struct {
+	Namespace string          `json:"namespace"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	CachedAt  time.Time       `json:"cached_at"`
+}
+
+// Load decodes the value cached under namespace and key into v. It returns
+// false if there is no entry, the entry expired, or it does not decode.
+func (c *Cache) Load(namespace, key string, v any) bool {
+	data, err := os.ReadFile(c.valuePath(namespace, key))
+	if err != nil {
+		return false
+	}
+
+	var e valueEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if e.Namespace != namespace || e.Key != key {
+		return false
+	}
+	if c.ttl > 0 && time.Since(e.CachedAt) > c.ttl {
+		return false
+	}
+
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Store caches v under namespace and key, for data that is not a list of
+// findings (registry metadata, for example)
+func (c *Cache) Store(namespace, key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(valueEntry{
+		Namespace: namespace,
+		Key:       key,
+		Value:     value,
+		CachedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeAtomic(c.valuePath(namespace, key), data)
+}
+
+// writeAtomic writes data to a temp file and renames it into place
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Clear removes every cached result
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+// Key identifies a package version by ecosystem and, for npm, the registry
+// serving it, for values cached with Store
+func (c *Cache) Key(pkg manifest.Package) string {
+	return pkg.Ecosystem + "\x00" + c.source(pkg) + "\x00" + pkg.Name + "\x00" + pkg.Version
+}
+
+// source returns the registry serving an npm package, or a package without
+// an ecosystem; other ecosystems have a single source
+func (c *Cache) source(pkg manifest.Package) string {
+	if pkg.Ecosystem != "" && pkg.Ecosystem != "npm" {
+		return ""
+	}
+	return c.registries.URL(pkg.Name)
+}
+
+// path returns the cache file for a scanner and package version
+func (c *Cache) path(scanner string, pkg manifest.Package) string {
+	sum := sha256.Sum256([]byte(scanner + "\x00" + c.Key(pkg)))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// valuePath returns the cache file for a namespaced value
+func (c *Cache) valuePath(namespace, key string) string {
+	sum := sha256.Sum256([]byte("value\x00" + namespace + "\x00" + key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name+".json")
+}
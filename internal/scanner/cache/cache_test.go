@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	c := New(config.CacheConfig{Directory: t.TempDir(), TTL: time.Hour})
+	pkg := manifest.Package{Name: "@babel/core", Version: "7.24.0", Ecosystem: "npm"}
+
+	if _, ok := c.Get("Google OSV", pkg); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	findings := []types.Finding{{Package: pkg.Name, Version: pkg.Version, ID: "GHSA-1234"}}
+	if err := c.Put("Google OSV", pkg, findings); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	got, ok := c.Get("Google OSV", pkg)
+	if !ok || len(got) != 1 || got[0].ID != "GHSA-1234" {
+		t.Errorf("Get = %v, %v; want cached finding", got, ok)
+	}
+
+	// Different scanner and version must not share entries
+	if _, ok := c.Get("Socket.dev", pkg); ok {
+		t.Error("expected miss for different scanner")
+	}
+	if _, ok := c.Get("Google OSV", manifest.Package{Name: pkg.Name, Version: "7.25.0"}); ok {
+		t.Error("expected miss for different version")
+	}
+}
+
+// TestCacheSource keeps entries apart that differ only by ecosystem or by
+// the registry serving them
+func TestCacheSource(t *testing.T) {
+	dir := t.TempDir()
+	c := New(config.CacheConfig{Directory: dir, TTL: time.Hour})
+	npm := manifest.Package{Name: "requests", Version: "2.31.0", Ecosystem: "npm"}
+	pypi := manifest.Package{Name: "requests", Version: "2.31.0", Ecosystem: "pypi"}
+
+	findings := []types.Finding{{Package: pypi.Name, Version: pypi.Version, ID: "GHSA-9wx4-h78v-vm56"}}
+	if err := c.Put("Google OSV", pypi, findings); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if got, ok := c.Get("Google OSV", npm); ok {
+		t.Errorf("npm Get = %v, want miss for an entry cached for PyPI", got)
+	}
+	if err := c.Put("Google OSV", npm, nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if got, ok := c.Get("Google OSV", pypi); !ok || len(got) != 1 {
+		t.Errorf("PyPI Get = %v, %v; want its own finding", got, ok)
+	}
+
+	// The same cache directory behind a private registry
+	private := New(config.CacheConfig{Directory: dir, TTL: time.Hour})
+	private.SetRegistries(registry.Registries{Default: "https://npm.internal.example.com"})
+	if _, ok := private.Get("Google OSV", npm); ok {
+		t.Error("expected miss for a package cached from the public registry")
+	}
+	if _, ok := private.Get("Google OSV", pypi); !ok {
+		t.Error("expected hit for PyPI, which does not use npm registries")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := New(config.CacheConfig{Directory: t.TempDir(), TTL: time.Nanosecond})
+	pkg := manifest.Package{Name: "lodash", Version: "4.17.21"}
+
+	if err := c.Put("Google OSV", pkg, nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if _, ok := c.Get("Google OSV", pkg); ok {
+		t.Error("expected expired entry to miss")
+	}
+}
+
+func TestCacheConcurrentPut(t *testing.T) {
+	dir := t.TempDir()
+	pkg := manifest.Package{Name: "lodash", Version: "4.17.21"}
+
+	// Separate Cache values simulate separate processes sharing a directory
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := New(config.CacheConfig{Directory: dir, TTL: time.Hour})
+			if err := c.Put("Google OSV", pkg, nil); err != nil {
+				t.Errorf("Put failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := New(config.CacheConfig{Directory: dir, TTL: time.Hour})
+	if _, ok := c.Get("Google OSV", pkg); !ok {
+		t.Error("expected hit after concurrent writes")
+	}
+}
+
+func TestCacheValueRoundTrip(t *testing.T) {
+	c := New(config.CacheConfig{Directory: t.TempDir(), TTL: time.Hour})
+
+	type meta struct {
+		Latest string `json:"latest"`
+	}
+
+	var got meta
+	if c.Load("registry", "lodash", &got) {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	if err := c.Store("registry", "lodash", meta{Latest: "4.17.21"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if !c.Load("registry", "lodash", &got) || got.Latest != "4.17.21" {
+		t.Errorf("Load = %+v; want cached value", got)
+	}
+	if c.Load("other", "lodash", &got) {
+		t.Error("expected miss for different namespace")
+	}
+}
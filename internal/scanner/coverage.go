@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"sync"
+
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// EcosystemSupporter is implemented by scanners that only cover some
+// ecosystems. Scanners that do not implement it are assumed to cover all.
+type EcosystemSupporter interface {
+	SupportsEcosystem(ecosystem string) bool
+}
+
+// coverageTracker records coverage outcomes from concurrent scanners
+type coverageTracker struct {
+	mu       sync.Mutex
+	coverage *types.Coverage
+	index    map[string]int
+}
+
+// newCoverageTracker starts tracking the input packages for the named
+// scanners
+func newCoverageTracker(packages []manifest.Package, scanners []string) *coverageTracker {
+	t := &coverageTracker{
+		coverage: &types.Coverage{
+			Scanners: scanners,
+			Packages: make([]types.PackageCoverage, 0, len(packages)),
+		},
+		index: make(map[string]int, len(packages)),
+	}
+	for _, pkg := range packages {
+		key := pkg.Name + "@" + pkg.Version
+		if _, ok := t.index[key]; ok {
+			continue
+		}
+		t.index[key] = len(t.coverage.Packages)
+		t.coverage.Packages = append(t.coverage.Packages, types.PackageCoverage{
+			Package:  pkg.Name,
+			Version:  pkg.Version,
+			Scanners: make(map[string]types.CoverageReason, len(scanners)),
+		})
+	}
+	return t
+}
+
+// set records the reason for every package in packages
+func (t *coverageTracker) set(scanner string, packages []manifest.Package, reason types.CoverageReason) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pkg := range packages {
+		if i, ok := t.index[pkg.Name+"@"+pkg.Version]; ok {
+			t.coverage.Packages[i].Scanners[scanner] = reason
+		}
+	}
+}
+
+// result returns the collected coverage
+func (t *coverageTracker) result() *types.Coverage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.coverage
+}
+
+// selectedPackages returns the packages a scanner looks at
+func selectedPackages(s Scanner, packages []manifest.Package) []manifest.Package {
+	ss, ok := s.(SelectiveScanner)
+	if !ok {
+		return packages
+	}
+	var selected []manifest.Package
+	for _, pkg := range packages {
+		if ss.Selects(pkg) {
+			selected = append(selected, pkg)
+		}
+	}
+	return selected
+}
+
+// supportedPackages splits packages by whether the scanner covers their
+// ecosystem
+func supportedPackages(s Scanner, packages []manifest.Package) (supported, unsupported []manifest.Package) {
+	es, ok := s.(EcosystemSupporter)
+	if !ok {
+		return packages, nil
+	}
+	for _, pkg := range packages {
+		if es.SupportsEcosystem(pkg.Ecosystem) {
+			supported = append(supported, pkg)
+		} else {
+			unsupported = append(unsupported, pkg)
+		}
+	}
+	return supported, unsupported
+}
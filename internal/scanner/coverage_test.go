@@ -0,0 +1,199 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+type fakeScanner struct {
+	name       string
+	available  bool
+	err        error
+	ecosystems []string
+}
+
+func (f *fakeScanner) Name() string      { return f.name }
+func (f *fakeScanner) IsAvailable() bool { return f.available }
+
+func (f *fakeScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ScanResult{Scanner: f.name, Packages: len(packages)}, nil
+}
+
+// ecosystemScanner only covers the listed ecosystems
+type ecosystemScanner struct {
+	fakeScanner
+}
+
+func (e *ecosystemScanner) SupportsEcosystem(ecosystem string) bool {
+	for _, eco := range e.ecosystems {
+		if eco == ecosystem {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScanCoverage(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanning.Policy.Allowlist = []config.AllowlistEntry{{Package: "trusted"}}
+
+	o := NewOrchestrator(cfg)
+	o.SetScanners(
+		&fakeScanner{name: "ok", available: true},
+		&fakeScanner{name: "broken", available: true, err: errors.New("timeout")},
+		&fakeScanner{name: "offline"},
+		&ecosystemScanner{fakeScanner{name: "pypi-only", available: true, ecosystems: []string{"PyPI"}}},
+	)
+	o.disabled = []string{"off"}
+
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "trusted", Version: "1.0.0", Ecosystem: "npm"},
+	}
+
+	result, err := o.Scan(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Coverage == nil {
+		t.Fatal("Coverage is nil")
+	}
+
+	want := map[string]map[string]types.CoverageReason{
+		"lodash": {
+			"ok":        types.CoverageChecked,
+			"broken":    types.CoverageFailed,
+			"offline":   types.CoverageUnavailable,
+			"pypi-only": types.CoverageUnsupported,
+			"off":       types.CoverageDisabled,
+		},
+		"trusted": {
+			"ok":        types.CoverageExcluded,
+			"broken":    types.CoverageExcluded,
+			"offline":   types.CoverageExcluded,
+			"pypi-only": types.CoverageExcluded,
+			"off":       types.CoverageExcluded,
+		},
+	}
+
+	if len(result.Coverage.Packages) != len(want) {
+		t.Fatalf("got %d packages, want %d", len(result.Coverage.Packages), len(want))
+	}
+	for _, p := range result.Coverage.Packages {
+		for scanner, reason := range want[p.Package] {
+			if got := p.Scanners[scanner]; got != reason {
+				t.Errorf("%s/%s = %q, want %q", p.Package, scanner, got, reason)
+			}
+		}
+	}
+
+	summary := result.Coverage.Summary()
+	if summary.FullyCovered != 0 || summary.Percent != 0 {
+		t.Errorf("summary = %+v, want nothing fully covered", summary)
+	}
+	if summary.Reasons[types.CoverageExcluded] != 5 {
+		t.Errorf("excluded = %d, want 5", summary.Reasons[types.CoverageExcluded])
+	}
+}
+
+// newOnlyScanner only looks at new packages and records what it was given
+type newOnlyScanner struct {
+	fakeScanner
+	scanned []string
+}
+
+func (n *newOnlyScanner) Selects(pkg manifest.Package) bool { return pkg.New }
+
+func (n *newOnlyScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	for _, pkg := range packages {
+		n.scanned = append(n.scanned, pkg.Name)
+	}
+	return n.fakeScanner.Scan(ctx, packages)
+}
+
+func TestScanSelectiveScanner(t *testing.T) {
+	o := NewOrchestrator(&config.Config{})
+	selective := &newOnlyScanner{fakeScanner: fakeScanner{name: "new-only", available: true}}
+	o.SetScanners(&fakeScanner{name: "ok", available: true}, selective)
+
+	result, err := o.Scan(context.Background(), []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "left-pad", Version: "1.3.0", Ecosystem: "npm", New: true},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(selective.scanned) != 1 || selective.scanned[0] != "left-pad" {
+		t.Errorf("scanned = %v, want [left-pad]", selective.scanned)
+	}
+	for _, p := range result.Coverage.Packages {
+		reason, ok := p.Scanners["new-only"]
+		if p.Package == "lodash" && ok {
+			t.Errorf("lodash coverage = %q, want no entry", reason)
+		}
+		if !p.Covered() {
+			t.Errorf("%s not covered: %v", p.Package, p.Scanners)
+		}
+	}
+}
+
+// partialScanner fails on the listed packages and checks the rest
+type partialScanner struct {
+	fakeScanner
+	failing string
+	scanned int
+}
+
+func (p *partialScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	p.scanned += len(packages)
+	result := &ScanResult{Scanner: p.name, Packages: len(packages)}
+	for _, pkg := range packages {
+		if pkg.Name == p.failing {
+			result.Failed = append(result.Failed, types.PackageError{Package: pkg.Name, Version: pkg.Version, Message: "bad gateway"})
+		}
+	}
+	return result, nil
+}
+
+// TestScanPackageFailed records packages a scanner could not check as
+// failed, keeps them out of the cache, and does not fail the scanner
+func TestScanPackageFailed(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanning.Cache = config.CacheConfig{Enabled: true, Directory: t.TempDir(), TTL: time.Hour}
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "broken", Version: "1.0.0", Ecosystem: "npm"},
+	}
+	partial := &partialScanner{fakeScanner: fakeScanner{name: "partial", available: true}, failing: "broken"}
+
+	for range 2 {
+		o := NewOrchestrator(cfg)
+		o.SetScanners(partial)
+		result, err := o.Scan(context.Background(), packages)
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if len(result.Errors) != 0 {
+			t.Errorf("Errors = %+v, want none", result.Errors)
+		}
+		for _, p := range result.Coverage.Packages {
+			if got := p.Scanners["partial"]; (got == types.CoverageFailed) != (p.Package == "broken") {
+				t.Errorf("%s coverage = %q", p.Package, got)
+			}
+		}
+	}
+	// lodash came from the cache the second time; broken was retried
+	if partial.scanned != 3 {
+		t.Errorf("scanned %d packages, want 3", partial.scanned)
+	}
+}
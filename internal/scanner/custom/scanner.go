@@ -0,0 +1,155 @@
+// Package custom runs external scanners configured in scanning.custom. A
+// custom scanner is a command that reads the packages to scan as JSON on
+// stdin and writes a scan result as JSON on stdout, so teams can plug in
+// their own scanning services without changing snapem.
+package custom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/redact"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// defaultTimeout bounds a scan when the config sets no timeout
+const defaultTimeout = 60 * time.Second
+
+// maxStderr is the most of a failed command's stderr quoted in its error
+const maxStderr = 500
+
+// severities are the severities a finding may have
+var severities = []types.Severity{
+	types.SeverityCritical, types.SeverityHigh, types.SeverityMedium, types.SeverityLow, types.SeverityInfo,
+}
+
+// Request is the JSON document a custom scanner reads on stdin
+type Request struct {
+	Packages []manifest.Package `json:"packages"`
+}
+
+// Scanner runs one configured command
+type Scanner struct {
+	cfg     config.CustomScannerConfig
+	timeout time.Duration
+}
+
+// NewScanner creates a scanner for a scanning.custom entry
+func NewScanner(cfg config.CustomScannerConfig) *Scanner {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Scanner{cfg: cfg, timeout: timeout}
+}
+
+// Name returns the name set in the config
+func (s *Scanner) Name() string {
+	return s.cfg.Name
+}
+
+// IsAvailable returns true if the command can be found
+func (s *Scanner) IsAvailable() bool {
+	_, err := exec.LookPath(s.cfg.Command)
+	return err == nil
+}
+
+// Scan runs the command with the packages on stdin and reads its findings
+// from stdout. A non-zero exit, a timeout or output that is not a valid
+// scan result fails the scan.
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	input, err := json.Marshal(Request{Packages: packages})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.cfg.Command, s.cfg.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on children that keep the output pipes open
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return nil, fmt.Errorf("%s timed out after %s", s.cfg.Command, s.timeout)
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case errors.As(err, &exitErr):
+			return nil, fmt.Errorf("%s exited with status %d%s", s.cfg.Command, exitErr.ExitCode(), stderrSuffix(stderr.String()))
+		default:
+			return nil, fmt.Errorf("failed to run %s: %w", s.cfg.Command, err)
+		}
+	}
+
+	result, err := parseResult(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.cfg.Command, err)
+	}
+	result.Scanner = s.Name()
+	if result.Packages == 0 {
+		result.Packages = len(packages)
+	}
+	result.ScanDuration = time.Since(start)
+	return result, nil
+}
+
+// parseResult decodes and checks a scan result written by a command
+func parseResult(output []byte) (*types.ScanResult, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, fmt.Errorf("no output; expected a JSON scan result on stdout")
+	}
+	var result types.ScanResult
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("invalid JSON output: %v (output starts %q)", err, truncate(string(output), 80))
+	}
+
+	for i, f := range result.Findings {
+		switch {
+		case f.Package == "":
+			return nil, fmt.Errorf("finding %d has no package", i)
+		case !slices.Contains(types.FindingTypes, f.Type):
+			return nil, fmt.Errorf("finding %d (%s): unknown type %q", i, f.Package, f.Type)
+		case !slices.Contains(severities, f.Severity):
+			return nil, fmt.Errorf("finding %d (%s): unknown severity %q", i, f.Package, f.Severity)
+		}
+	}
+	return &result, nil
+}
+
+// stderrSuffix quotes the end of a failed command's stderr, where the
+// reason usually is
+func stderrSuffix(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	if len(stderr) > maxStderr {
+		stderr = "..." + stderr[len(stderr)-maxStderr:]
+	}
+	return ": " + redact.String(stderr)
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
@@ -0,0 +1,82 @@
+package custom
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// script returns a scanner running a shell script
+func script(body string, timeout time.Duration) *Scanner {
+	return NewScanner(config.CustomScannerConfig{
+		Name:    "Acme",
+		Command: "sh",
+		Args:    []string{"-c", body},
+		Timeout: timeout,
+	})
+}
+
+func TestScan(t *testing.T) {
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"},
+		{Name: "left-pad", Version: "1.3.0", Ecosystem: "npm"},
+	}
+
+	// The script echoes the package names it read as a finding title
+	s := script(`names=$(grep -o '"name":"[^"]*"' | cut -d'"' -f4 | tr '\n' ' ')
+printf '{"findings": [{"package": "lodash", "version": "4.17.20", "type": "cve", "severity": "high", "title": "%s", "id": "ACME-1"}]}' "$names"`, 0)
+	if !s.IsAvailable() {
+		t.Skip("sh not found")
+	}
+
+	result, err := s.Scan(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Scanner != "Acme" || result.Packages != 2 {
+		t.Errorf("result = %+v, want scanner Acme and 2 packages", result)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("findings = %+v, want 1", result.Findings)
+	}
+	f := result.Findings[0]
+	if f.ID != "ACME-1" || f.Severity != types.SeverityHigh || f.Title != "lodash left-pad " {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		want    string
+	}{
+		{"exit status", "echo 'token rejected' >&2; exit 3", 0, "exited with status 3: token rejected"},
+		{"malformed JSON", "echo 'not json'", 0, "invalid JSON output"},
+		{"no output", "true", 0, "no output"},
+		{"unknown severity", `echo '{"findings": [{"package": "a", "type": "cve", "severity": "severe"}]}'`, 0, `unknown severity "severe"`},
+		{"unknown type", `echo '{"findings": [{"package": "a", "type": "bug", "severity": "low"}]}'`, 0, `unknown type "bug"`},
+		{"timeout", "sleep 5", 100 * time.Millisecond, "timed out after 100ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := script(tt.script, tt.timeout).Scan(context.Background(), []manifest.Package{{Name: "a", Version: "1.0.0"}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Scan() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAvailable(t *testing.T) {
+	s := NewScanner(config.CustomScannerConfig{Name: "Missing", Command: "snapem-no-such-scanner"})
+	if s.IsAvailable() {
+		t.Error("IsAvailable() = true for a command that does not exist")
+	}
+}
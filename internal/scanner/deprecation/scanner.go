@@ -0,0 +1,153 @@
+// Package deprecation reports package versions their maintainers have
+// deprecated on npm, with the deprecation message.
+package deprecation
+
+import (
+	"context"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/scanner/cache"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// cacheNamespace keys the cached deprecation messages of a package
+const cacheNamespace = "deprecation"
+
+// ScannerName labels versions their maintainers deprecated
+const ScannerName = "Deprecation"
+
+// Scanner looks up deprecation messages in the npm registry
+type Scanner struct {
+	metadata *registry.Client
+	cache    *cache.Cache
+	severity types.Severity
+	timeout  time.Duration
+}
+
+// NewScanner creates a deprecation scanner that reads package documents
+// through metadata. c may be nil to disable caching.
+func NewScanner(cfg config.DeprecatedConfig, metadata *registry.Client, c *cache.Cache) *Scanner {
+	severity := types.Severity(cfg.Severity)
+	if severity == "" {
+		severity = types.SeverityLow
+	}
+	return &Scanner{
+		metadata: metadata,
+		cache:    c,
+		severity: severity,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return ScannerName
+}
+
+// IsAvailable returns true; deprecation messages are part of the public
+// package document
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// SupportsEcosystem returns true for npm, whose registry records a
+// deprecation per version
+func (s *Scanner) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// Scan reports each deprecated package version. Versions unknown to the
+// registry are skipped, and versions whose package document cannot be
+// fetched are listed as failed. It returns an error only if no version
+// could be checked.
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	deprecations, errs := registry.FetchAll(ctx, packages, s.lookup)
+
+	findings := []types.Finding{}
+	var failed []types.PackageError
+	var lastErr error
+	for _, pkg := range packages {
+		if err := errs[pkg.Name]; err != nil {
+			failed = append(failed, types.PackageError{Package: pkg.Name, Version: pkg.Version, Message: err.Error()})
+			lastErr = err
+			continue
+		}
+		if message := deprecations[pkg.Name][pkg.Version]; message != "" {
+			findings = append(findings, types.Finding{
+				Package:     pkg.Name,
+				Version:     pkg.Version,
+				Type:        types.FindingTypeQuality,
+				Severity:    s.severity,
+				Title:       "Deprecated",
+				Description: message,
+				Deprecated:  message,
+				Remediation: "Follow the deprecation message, usually by upgrading or switching packages",
+			})
+		}
+	}
+	if len(packages) > 0 && len(failed) == len(packages) {
+		return nil, lastErr
+	}
+
+	return &types.ScanResult{
+		Scanner:      s.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		Failed:       failed,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// lookup returns the deprecation message of every published version of a
+// package, "" for versions that are not deprecated, or nil if the
+// registry does not know the package. Cached messages are used while they
+// list every scanned version; a version published since they were cached
+// needs a fresh document.
+func (s *Scanner) lookup(ctx context.Context, name string, scanned []string) (map[string]string, error) {
+	key := s.metadata.URL(name)
+
+	var messages map[string]string
+	if s.cache != nil && s.cache.Load(cacheNamespace, key, &messages) && listsAll(messages, scanned) {
+		return messages, nil
+	}
+
+	doc, err := s.fetch(ctx, name)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	messages = make(map[string]string, len(doc.Versions))
+	for version, v := range doc.Versions {
+		messages[version] = v.DeprecationMessage()
+	}
+	if s.cache != nil {
+		_ = s.cache.Store(cacheNamespace, key, messages)
+	}
+	return messages, nil
+}
+
+// listsAll reports whether messages has an entry for every version
+func listsAll(messages map[string]string, versions []string) bool {
+	for _, version := range versions {
+		if _, ok := messages[version]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fetch returns the abbreviated package document, which carries each
+// version's deprecation message, or nil if the registry does not know the
+// package
+func (s *Scanner) fetch(ctx context.Context, name string) (*registry.Packument, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	return s.metadata.Packument(ctx, name)
+}
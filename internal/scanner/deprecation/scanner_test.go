@@ -0,0 +1,137 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/scanner/cache"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestScan(t *testing.T) {
+	const message = "request has been deprecated, see https://github.com/request/request/issues/3142"
+	docs := map[string]string{
+		"/request": `{"versions": {"2.88.2": {"deprecated": "` + message + `"}}}`,
+		"/lodash":  `{"versions": {"4.17.21": {}, "1.0.0": {"deprecated": false}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		cfg      config.DeprecatedConfig
+		severity types.Severity
+	}{
+		{"default severity", config.DeprecatedConfig{}, types.SeverityLow},
+		{"configured severity", config.DeprecatedConfig{Severity: "medium"}, types.SeverityMedium},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(tt.cfg, registry.NewClient(registry.Registries{Default: server.URL}), nil)
+
+			result, err := s.Scan(context.Background(), []manifest.Package{
+				{Name: "request", Version: "2.88.2"},
+				{Name: "lodash", Version: "4.17.21"},
+				{Name: "lodash", Version: "1.0.0"},
+				{Name: "missing", Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if len(result.Findings) != 1 {
+				t.Fatalf("Scan() findings = %+v, want 1", result.Findings)
+			}
+			f := result.Findings[0]
+			if f.Package != "request" || f.Type != types.FindingTypeQuality || f.Severity != tt.severity {
+				t.Errorf("finding = %+v", f)
+			}
+			if f.Deprecated != message {
+				t.Errorf("Deprecated = %q", f.Deprecated)
+			}
+		})
+	}
+}
+
+// TestScanPackageError lists the versions of a package the registry fails
+// on as not checked, and still checks the others
+func TestScanPackageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"versions": {"2.88.2": {"deprecated": "use something else"}}}`))
+	}))
+	defer server.Close()
+
+	s := NewScanner(config.DeprecatedConfig{}, registry.NewClient(registry.Registries{Default: server.URL}), nil)
+
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "broken", Version: "1.0.0"},
+		{Name: "request", Version: "2.88.2"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Package != "request" {
+		t.Errorf("Scan() findings = %+v, want request", result.Findings)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Package != "broken" {
+		t.Errorf("Scan() failed = %+v, want broken", result.Failed)
+	}
+
+	if _, err := s.Scan(context.Background(), []manifest.Package{{Name: "broken", Version: "1.0.0"}}); err == nil {
+		t.Error("Scan() expected error when no package could be checked")
+	}
+}
+
+// TestScanCache reuses cached deprecation messages across runs until a
+// scanned version is missing from them
+func TestScanCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"versions": {"2.88.1": {}, "2.88.2": {"deprecated": "use something else"}}}`))
+	}))
+	defer server.Close()
+
+	c := cache.New(config.CacheConfig{Directory: t.TempDir(), TTL: time.Hour})
+	scan := func(version string) int {
+		t.Helper()
+		s := NewScanner(config.DeprecatedConfig{}, registry.NewClient(registry.Registries{Default: server.URL}), c)
+		result, err := s.Scan(context.Background(), []manifest.Package{{Name: "request", Version: version}})
+		if err != nil {
+			t.Fatalf("Scan(%s) error = %v", version, err)
+		}
+		return len(result.Findings)
+	}
+
+	if got := scan("2.88.2"); got != 1 {
+		t.Errorf("first scan: %d findings, want 1", got)
+	}
+	if got := scan("2.88.1"); got != 0 {
+		t.Errorf("cached scan: %d findings, want 0", got)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("%d registry requests after a cached scan, want 1", got)
+	}
+
+	scan("3.0.0")
+	if got := requests.Load(); got != 2 {
+		t.Errorf("%d registry requests for a version missing from the cache, want 2", got)
+	}
+}
@@ -0,0 +1,264 @@
+// Package freshness rates how actively direct dependencies are maintained,
+// using npm registry publish dates and, when a GitHub token is configured,
+// whether the source repository is archived.
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/scanner/cache"
+	"github.com/positronico/snapem/internal/types"
+)
+
+const (
+	githubURL = "https://api.github.com"
+
+	// cacheNamespace keys cached package metadata
+	cacheNamespace = "freshness"
+)
+
+// Checker looks up maintenance metadata for packages
+type Checker struct {
+	metadata    *registry.Client
+	httpClient  *http.Client
+	githubURL   string
+	githubToken string
+	agingDays   int
+	staleDays   int
+	timeout     time.Duration
+	cache       *cache.Cache
+	now         func() time.Time
+}
+
+// metadata is the cacheable part of a package's health
+type metadata struct {
+	LastPublish time.Time `json:"last_publish"`
+	Repository  string    `json:"repository"`
+	Archived    bool      `json:"archived"`
+}
+
+// NewChecker creates a freshness checker that reads package documents
+// through metadata. c may be nil to disable caching.
+func NewChecker(cfg config.FreshnessConfig, metadata *registry.Client, c *cache.Cache) *Checker {
+	return &Checker{
+		metadata:    metadata,
+		httpClient:  httpclient.New(),
+		githubURL:   githubURL,
+		githubToken: cfg.GitHubToken,
+		agingDays:   cfg.AgingDays,
+		staleDays:   cfg.StaleDays,
+		timeout:     cfg.Timeout,
+		cache:       c,
+		now:         time.Now,
+	}
+}
+
+// Check rates each package. advisories maps "name@version" to the number
+// of open advisories found by the security scan.
+func (c *Checker) Check(ctx context.Context, packages []manifest.Package, advisories map[string]int) []types.DependencyHealth {
+	meta, errs := registry.FetchAll(ctx, packages, func(ctx context.Context, name string, _ []string) (metadata, error) {
+		return c.lookup(ctx, name)
+	})
+
+	health := make([]types.DependencyHealth, len(packages))
+	for i, pkg := range packages {
+		health[i] = types.DependencyHealth{
+			Package: pkg.Name,
+			Version: pkg.Version,
+			Status:  types.FreshnessUnknown,
+		}
+		if errs[pkg.Name] == nil {
+			c.rate(&health[i], meta[pkg.Name])
+		}
+		health[i].OpenAdvisories = advisories[pkg.Name+"@"+pkg.Version]
+	}
+	return health
+}
+
+// Findings returns a quality finding for every stale dependency
+func Findings(health []types.DependencyHealth) []types.Finding {
+	var findings []types.Finding
+	for _, h := range health {
+		if h.Status != types.FreshnessStale {
+			continue
+		}
+
+		title := fmt.Sprintf("Stale dependency: no release in %d days", h.DaysSinceRelease)
+		if h.Archived {
+			title = "Stale dependency: source repository is archived"
+		}
+		findings = append(findings, types.Finding{
+			Package:     h.Package,
+			Version:     h.Version,
+			Type:        types.FindingTypeQuality,
+			Severity:    types.SeverityLow,
+			Title:       title,
+			Description: fmt.Sprintf("Last published %s", h.LastPublish.Format("2006-01-02")),
+			Remediation: "Consider replacing it with an actively maintained alternative",
+		})
+	}
+	return findings
+}
+
+// lookup loads a package's metadata from the cache, or fetches and
+// caches it
+func (c *Checker) lookup(ctx context.Context, name string) (metadata, error) {
+	var meta metadata
+	if c.cache != nil && c.cache.Load(cacheNamespace, name, &meta) {
+		return meta, nil
+	}
+
+	meta, err := c.fetch(ctx, name)
+	if err != nil {
+		return metadata{}, err
+	}
+	if c.cache != nil {
+		_ = c.cache.Store(cacheNamespace, name, meta)
+	}
+	return meta, nil
+}
+
+// rate fills in a package's health from its metadata
+func (c *Checker) rate(health *types.DependencyHealth, meta metadata) {
+	health.LastPublish = meta.LastPublish
+	health.Repository = meta.Repository
+	health.Archived = meta.Archived
+	if !meta.LastPublish.IsZero() {
+		health.DaysSinceRelease = int(c.now().Sub(meta.LastPublish).Hours() / 24)
+		health.Status = c.status(*health)
+	}
+}
+
+// status applies the configured thresholds
+func (c *Checker) status(h types.DependencyHealth) types.FreshnessStatus {
+	switch {
+	case h.Archived:
+		return types.FreshnessStale
+	case c.staleDays > 0 && h.DaysSinceRelease >= c.staleDays:
+		return types.FreshnessStale
+	case c.agingDays > 0 && h.DaysSinceRelease >= c.agingDays:
+		return types.FreshnessAging
+	default:
+		return types.FreshnessFresh
+	}
+}
+
+// fetch queries the registry and, when possible, GitHub
+func (c *Checker) fetch(ctx context.Context, name string) (metadata, error) {
+	doc, err := c.packument(ctx, name)
+	if err != nil {
+		return metadata{}, err
+	}
+	if doc == nil {
+		return metadata{}, fmt.Errorf("%s: %w", name, registry.ErrNotFound)
+	}
+
+	meta := metadata{Repository: repositoryURL(doc.Repository)}
+
+	// Prefer the publish date of the latest dist-tag; fall back to the
+	// registry's modification time
+	published := doc.Time[doc.DistTags["latest"]]
+	if published == "" {
+		published = doc.Time["modified"]
+	}
+	if t, err := time.Parse(time.RFC3339, published); err == nil {
+		meta.LastPublish = t
+	}
+
+	if owner, repo, ok := githubRepo(meta.Repository); ok && c.githubToken != "" {
+		var gh struct {
+			Archived bool `json:"archived"`
+		}
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/%s", c.githubURL, owner, repo), c.githubToken, &gh); err == nil {
+			meta.Archived = gh.Archived
+		}
+	}
+
+	return meta, nil
+}
+
+// packument returns the full package document, which carries publish times
+// and the repository
+func (c *Checker) packument(ctx context.Context, name string) (*registry.Packument, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.metadata.FullPackument(ctx, name)
+}
+
+// getJSON performs a GET request and decodes the JSON response
+func (c *Checker) getJSON(ctx context.Context, endpoint, token string, v any) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// repositoryURL extracts the URL from a package.json repository field,
+// which is either a string or a {type, url} object
+func repositoryURL(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var obj struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.URL
+	}
+	return ""
+}
+
+var githubRepoPattern = regexp.MustCompile(`github\.com[/:]([^/]+)/([^/#]+?)(?:\.git)?(?:[/#].*)?$`)
+
+// githubRepo parses owner and repo from the common repository URL forms:
+// "git+https://github.com/o/r.git", "git@github.com:o/r.git", "github:o/r"
+// and the bare "o/r" shorthand
+func githubRepo(repository string) (owner, repo string, ok bool) {
+	if m := githubRepoPattern.FindStringSubmatch(repository); m != nil {
+		return m[1], m[2], true
+	}
+
+	shorthand := strings.TrimPrefix(repository, "github:")
+	parts := strings.Split(shorthand, "/")
+	if len(parts) == 2 && parts[0] != "" && parts[1] != "" && !strings.Contains(shorthand, ":") {
+		return parts[0], parts[1], true
+	}
+
+	return "", "", false
+}
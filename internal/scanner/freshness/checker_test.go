@@ -0,0 +1,109 @@
+package freshness
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestGitHubRepo(t *testing.T) {
+	tests := []struct {
+		repository string
+		owner      string
+		repo       string
+		ok         bool
+	}{
+		{"git+https://github.com/lodash/lodash.git", "lodash", "lodash", true},
+		{"git@github.com:expressjs/express.git", "expressjs", "express", true},
+		{"https://github.com/babel/babel/tree/main/packages/babel-core", "babel", "babel", true},
+		{"github:facebook/react", "facebook", "react", true},
+		{"facebook/react", "facebook", "react", true},
+		{"https://gitlab.com/foo/bar.git", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			owner, repo, ok := githubRepo(tt.repository)
+			if owner != tt.owner || repo != tt.repo || ok != tt.ok {
+				t.Errorf("githubRepo(%q) = %q, %q, %v, want %q, %q, %v", tt.repository, owner, repo, ok, tt.owner, tt.repo, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	published := map[string]time.Time{
+		"fresh-pkg":    now.AddDate(0, -1, 0),
+		"aging-pkg":    now.AddDate(-1, 0, 0),
+		"stale-pkg":    now.AddDate(-3, 0, 0),
+		"archived-pkg": now.AddDate(0, -1, 0),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/registry/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		ts, ok := published[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"dist-tags": {"latest": "1.0.0"}, "time": {"1.0.0": %q}, "repository": {"type": "git", "url": "git+https://github.com/acme/%s.git"}}`,
+			ts.Format(time.RFC3339), name)
+	})
+	mux.HandleFunc("/github/repos/acme/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"archived": %v}`, r.PathValue("repo") == "archived-pkg")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker := NewChecker(config.FreshnessConfig{AgingDays: 180, StaleDays: 730, GitHubToken: "token"}, registry.NewClient(registry.Registries{Default: server.URL + "/registry"}), nil)
+	checker.githubURL = server.URL + "/github"
+	checker.now = func() time.Time { return now }
+
+	packages := []manifest.Package{
+		{Name: "fresh-pkg", Version: "1.0.0"},
+		{Name: "aging-pkg", Version: "1.0.0"},
+		{Name: "stale-pkg", Version: "1.0.0"},
+		{Name: "archived-pkg", Version: "1.0.0"},
+		{Name: "missing-pkg", Version: "1.0.0"},
+	}
+	health := checker.Check(context.Background(), packages, map[string]int{"stale-pkg@1.0.0": 2})
+
+	want := []types.FreshnessStatus{
+		types.FreshnessFresh,
+		types.FreshnessAging,
+		types.FreshnessStale,
+		types.FreshnessStale,
+		types.FreshnessUnknown,
+	}
+	for i, h := range health {
+		if h.Status != want[i] {
+			t.Errorf("%s status = %s, want %s", h.Package, h.Status, want[i])
+		}
+	}
+	if health[2].OpenAdvisories != 2 {
+		t.Errorf("stale-pkg advisories = %d, want 2", health[2].OpenAdvisories)
+	}
+	if !health[3].Archived {
+		t.Error("archived-pkg not marked archived")
+	}
+
+	findings := Findings(health)
+	if len(findings) != 2 || findings[0].Type != types.FindingTypeQuality {
+		t.Errorf("Findings() = %+v, want two quality findings", findings)
+	}
+}
@@ -0,0 +1,315 @@
+package ghsa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/redact"
+	"github.com/positronico/snapem/internal/types"
+)
+
+const (
+	baseURL = "https://api.github.com/graphql"
+
+	// batchSize bounds the packages queried per GraphQL request
+	batchSize = 50
+
+	// maxVulnerabilities is the most advisories fetched per package
+	maxVulnerabilities = 100
+)
+
+// ScannerName labels results from the GitHub Advisory Database
+const ScannerName = "GitHub Advisories"
+
+// Client queries the GitHub Advisory Database through the GraphQL API
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	timeout    time.Duration
+}
+
+// NewClient creates a new GitHub Advisory Database client
+func NewClient(cfg config.GitHubConfig) *Client {
+	return &Client{
+		httpClient: httpclient.New(),
+		baseURL:    baseURL,
+		token:      cfg.Token,
+		timeout:    cfg.Timeout,
+	}
+}
+
+// Name returns the scanner name
+func (c *Client) Name() string {
+	return ScannerName
+}
+
+// IsAvailable returns true if a GitHub token is configured; the GraphQL API
+// does not accept anonymous requests
+func (c *Client) IsAvailable() bool {
+	return c.token != ""
+}
+
+// SupportsEcosystem returns true for npm, the only ecosystem queried
+func (c *Client) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// Scan queries GitHub advisories for each package and reports those whose
+// vulnerable range includes the scanned version
+func (c *Client) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	// Versions of one package share a query
+	var names []string
+	seen := make(map[string]bool)
+	for _, pkg := range packages {
+		if !seen[pkg.Name] {
+			seen[pkg.Name] = true
+			names = append(names, pkg.Name)
+		}
+	}
+
+	advisories := make(map[string][]vulnerability)
+	for i := 0; i < len(names); i += batchSize {
+		batch := names[i:min(i+batchSize, len(names))]
+		found, err := c.queryBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for name, vulns := range found {
+			advisories[name] = vulns
+		}
+	}
+
+	findings := []types.Finding{}
+	for _, pkg := range packages {
+		findings = append(findings, convertToFindings(pkg, advisories[pkg.Name])...)
+	}
+
+	return &types.ScanResult{
+		Scanner:      c.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// queryBatch fetches the advisories for up to batchSize package names in a
+// single GraphQL request, one aliased field per package
+func (c *Client) queryBatch(ctx context.Context, names []string) (map[string][]vulnerability, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := graphQLRequest{
+		Query:     buildQuery(len(names)),
+		Variables: make(map[string]string, len(names)),
+	}
+	for i, name := range names {
+		req.Variables[fmt.Sprintf("p%d", i)] = name
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "bearer "+c.token)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("invalid GitHub token")
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return nil, fmt.Errorf("GitHub API rate limit exceeded")
+	default:
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, redact.String(string(respBody)))
+	}
+
+	var gqlResp graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		return nil, fmt.Errorf("GitHub API error: %s", gqlResp.Errors[0].Message)
+	}
+
+	found := make(map[string][]vulnerability, len(names))
+	for i, name := range names {
+		if conn, ok := gqlResp.Data[fmt.Sprintf("p%d", i)]; ok {
+			found[name] = conn.Nodes
+		}
+	}
+	return found, nil
+}
+
+// buildQuery returns a query with one securityVulnerabilities field per
+// package, aliased p0, p1, ... and bound to the variable of the same name
+func buildQuery(n int) string {
+	var params, fields []string
+	for i := 0; i < n; i++ {
+		params = append(params, fmt.Sprintf("$p%d: String!", i))
+		fields = append(fields, fmt.Sprintf("p%d: securityVulnerabilities(ecosystem: NPM, package: $p%d, first: %d) { ...vuln }", i, i, maxVulnerabilities))
+	}
+
+	return "query(" + strings.Join(params, ", ") + ") {\n  " + strings.Join(fields, "\n  ") + "\n}\n" + vulnFragment
+}
+
+const vulnFragment = `fragment vuln on SecurityVulnerabilityConnection {
+  nodes {
+    severity
+    vulnerableVersionRange
+    firstPatchedVersion { identifier }
+    advisory {
+      ghsaId
+      identifiers { type value }
+      summary
+      description
+      permalink
+      withdrawnAt
+      references { url }
+    }
+  }
+}`
+
+// convertToFindings returns a finding for each advisory whose vulnerable
+// range includes the package version. Versions that are not valid semver,
+// such as "latest", cannot be matched and produce no findings.
+func convertToFindings(pkg manifest.Package, vulns []vulnerability) []types.Finding {
+	version, err := semver.NewVersion(pkg.Version)
+	if err != nil {
+		return nil
+	}
+
+	var findings []types.Finding
+	seen := make(map[string]bool)
+	for _, v := range vulns {
+		if v.Advisory.WithdrawnAt != "" || seen[v.Advisory.GHSAID] {
+			continue
+		}
+		constraint, err := semver.NewConstraint(v.VulnerableVersionRange)
+		if err != nil || !constraint.Check(version) {
+			continue
+		}
+		seen[v.Advisory.GHSAID] = true
+
+		finding := types.Finding{
+			Package:     pkg.Name,
+			Version:     pkg.Version,
+			Type:        types.FindingTypeCVE,
+			Severity:    mapSeverity(v.Severity),
+			Title:       v.Advisory.Summary,
+			Description: v.Advisory.Description,
+			ID:          v.Advisory.GHSAID,
+			Aliases:     v.Advisory.aliases(),
+		}
+		if v.FirstPatchedVersion != nil && v.FirstPatchedVersion.Identifier != "" {
+			finding.FixedIn = v.FirstPatchedVersion.Identifier
+			finding.Remediation = "Upgrade to " + v.FirstPatchedVersion.Identifier + " or later"
+		}
+		if v.Advisory.Permalink != "" {
+			finding.References = append(finding.References, v.Advisory.Permalink)
+		}
+		for _, ref := range v.Advisory.References {
+			if ref.URL != v.Advisory.Permalink {
+				finding.References = append(finding.References, ref.URL)
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].ID < findings[j].ID })
+	return findings
+}
+
+func mapSeverity(severity string) types.Severity {
+	switch severity {
+	case "CRITICAL":
+		return types.SeverityCritical
+	case "HIGH":
+		return types.SeverityHigh
+	case "MODERATE":
+		return types.SeverityMedium
+	case "LOW":
+		return types.SeverityLow
+	default:
+		return types.SeverityInfo
+	}
+}
+
+// Request/Response types
+
+type graphQLRequest struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data   map[string]connection `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
+
+type connection struct {
+	Nodes []vulnerability `json:"nodes"`
+}
+
+type vulnerability struct {
+	Severity               string `json:"severity"`
+	VulnerableVersionRange string `json:"vulnerableVersionRange"`
+	FirstPatchedVersion    *struct {
+		Identifier string `json:"identifier"`
+	} `json:"firstPatchedVersion"`
+	Advisory advisory `json:"advisory"`
+}
+
+type advisory struct {
+	GHSAID      string `json:"ghsaId"`
+	Identifiers []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"identifiers"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Permalink   string `json:"permalink"`
+	WithdrawnAt string `json:"withdrawnAt"`
+	References  []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+}
+
+// aliases returns the advisory's identifiers other than its GHSA ID
+func (a advisory) aliases() []string {
+	var ids []string
+	for _, id := range a.Identifiers {
+		if id.Value != "" && id.Value != a.GHSAID {
+			ids = append(ids, id.Value)
+		}
+	}
+	return ids
+}
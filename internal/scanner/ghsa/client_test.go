@@ -0,0 +1,122 @@
+package ghsa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestScan(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("Authorization"); got != "bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(req.Query, "securityVulnerabilities(ecosystem: NPM, package: $p0") {
+			t.Errorf("query does not alias packages:\n%s", req.Query)
+		}
+
+		data := make(map[string]any)
+		for alias, name := range req.Variables {
+			var nodes []map[string]any
+			if name == "lodash" {
+				nodes = []map[string]any{
+					{
+						"severity":               "HIGH",
+						"vulnerableVersionRange": "< 4.17.21",
+						"firstPatchedVersion":    map[string]string{"identifier": "4.17.21"},
+						"advisory": map[string]any{
+							"ghsaId":     "GHSA-35jh-r3h4-6jhm",
+							"summary":    "Command Injection in lodash",
+							"permalink":  "https://github.com/advisories/GHSA-35jh-r3h4-6jhm",
+							"references": []map[string]string{{"url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}},
+						},
+					},
+					{
+						"severity":               "MODERATE",
+						"vulnerableVersionRange": ">= 4.0.0, < 4.17.19",
+						"advisory":               map[string]any{"ghsaId": "GHSA-p6mc-m468-83gw", "summary": "Prototype Pollution"},
+					},
+					{
+						"severity":               "CRITICAL",
+						"vulnerableVersionRange": "< 5.0.0",
+						"advisory":               map[string]any{"ghsaId": "GHSA-withdrawn", "withdrawnAt": "2024-01-01T00:00:00Z"},
+					},
+				}
+			}
+			data[alias] = map[string]any{"nodes": nodes}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	c := NewClient(config.GitHubConfig{Token: "test-token", Timeout: 5 * time.Second})
+	c.baseURL = server.URL
+
+	var packages []manifest.Package
+	packages = append(packages,
+		manifest.Package{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"},
+		manifest.Package{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		manifest.Package{Name: "lodash", Version: "4.17.15", Ecosystem: "npm"},
+	)
+	for i := 0; i < batchSize; i++ {
+		packages = append(packages, manifest.Package{Name: fmt.Sprintf("pkg-%d", i), Version: "1.0.0", Ecosystem: "npm"})
+	}
+
+	result, err := c.Scan(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 batches", requests)
+	}
+
+	var got []string
+	for _, f := range result.Findings {
+		got = append(got, fmt.Sprintf("%s@%s %s %s", f.Package, f.Version, f.ID, f.Severity))
+	}
+	want := []string{
+		"lodash@4.17.20 GHSA-35jh-r3h4-6jhm high",
+		"lodash@4.17.15 GHSA-35jh-r3h4-6jhm high",
+		"lodash@4.17.15 GHSA-p6mc-m468-83gw medium",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	f := result.Findings[0]
+	if f.Type != types.FindingTypeCVE || f.Remediation != "Upgrade to 4.17.21 or later" || len(f.References) != 2 {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestScanGraphQLError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errors": [{"message": "API rate limit exceeded"}]}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(config.GitHubConfig{Token: "test-token", Timeout: 5 * time.Second})
+	c.baseURL = server.URL
+
+	_, err := c.Scan(context.Background(), []manifest.Package{{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}})
+	if err == nil || !strings.Contains(err.Error(), "API rate limit exceeded") {
+		t.Errorf("Scan() error = %v, want the GraphQL error", err)
+	}
+}
@@ -0,0 +1,282 @@
+// Package heuristics flags supply-chain risk signals in npm registry
+// metadata for packages about to be installed: very recent releases, few
+// downloads, a single maintainer, and maintainer email domain changes.
+package heuristics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/httpclient"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+const downloadsURL = "https://api.npmjs.org/downloads/point/last-week"
+
+// ScannerName labels risk signals found in registry metadata
+const ScannerName = "Heuristics"
+
+// Scanner checks registry metadata of new packages for risk signals
+type Scanner struct {
+	metadata     *registry.Client
+	httpClient   *http.Client
+	downloadsURL string
+	cfg          config.HeuristicsConfig
+	now          func() time.Time
+}
+
+// NewScanner creates a heuristics scanner that reads package documents
+// through metadata
+func NewScanner(cfg config.HeuristicsConfig, metadata *registry.Client) *Scanner {
+	return &Scanner{
+		metadata:     metadata,
+		httpClient:   httpclient.New(),
+		downloadsURL: downloadsURL,
+		cfg:          cfg,
+		now:          time.Now,
+	}
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return ScannerName
+}
+
+// IsAvailable returns true; neither the registry nor the downloads API
+// needs a token
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// SupportsEcosystem returns true for npm; download counts and maintainer
+// emails come from npm's APIs
+func (s *Scanner) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// Selects returns true for packages about to be installed; the full
+// registry documents are too large to fetch for a whole project
+func (s *Scanner) Selects(pkg manifest.Package) bool {
+	return pkg.New
+}
+
+// Volatile returns true; a release's age changes every day
+func (s *Scanner) Volatile() bool {
+	return true
+}
+
+// metadata is what the scanner learned about one package name
+type metadata struct {
+	doc *registry.Packument
+
+	// downloads is the last week's download count, or -1 if unknown
+	downloads int
+}
+
+// Scan reports the risk signals found for each package version. Versions
+// unknown to the registry are skipped.
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	meta, errs := registry.FetchAll(ctx, packages, func(ctx context.Context, name string, _ []string) (*metadata, error) {
+		return s.lookup(ctx, name)
+	})
+
+	findings := []types.Finding{}
+	for _, pkg := range packages {
+		if err := errs[pkg.Name]; err != nil {
+			return nil, err
+		}
+		findings = append(findings, s.check(pkg, meta[pkg.Name])...)
+	}
+
+	return &types.ScanResult{
+		Scanner:      s.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// check returns the findings for one package version
+func (s *Scanner) check(pkg manifest.Package, m *metadata) []types.Finding {
+	if m == nil || m.doc == nil {
+		return nil
+	}
+	version, ok := m.doc.Versions[pkg.Version]
+	if !ok {
+		return nil
+	}
+
+	finding := func(typ types.FindingType, severity, defaultSeverity types.Severity, title, description string) types.Finding {
+		if severity == "" {
+			severity = defaultSeverity
+		}
+		return types.Finding{
+			Package:     pkg.Name,
+			Version:     pkg.Version,
+			Type:        typ,
+			Severity:    severity,
+			Title:       title,
+			Description: description,
+		}
+	}
+	sev := s.cfg.Severity
+
+	var findings []types.Finding
+	if published, ok := published(m.doc, pkg.Version); ok && s.cfg.NewVersionDays > 0 {
+		age := s.now().Sub(published)
+		if age < time.Duration(s.cfg.NewVersionDays)*24*time.Hour {
+			f := finding(types.FindingTypeQuality, types.Severity(sev.NewVersion), types.SeverityMedium,
+				"Published "+describeAge(age),
+				fmt.Sprintf("%s was published on %s; most malicious releases are caught within days", pkg.Version, published.UTC().Format("2006-01-02")))
+			f.Remediation = "Wait for the release to age, or install an earlier version"
+			findings = append(findings, f)
+		}
+	}
+
+	if m.downloads >= 0 && m.downloads < s.cfg.MinWeeklyDownloads {
+		findings = append(findings, finding(types.FindingTypeQuality, types.Severity(sev.LowDownloads), types.SeverityLow,
+			fmt.Sprintf("%d weekly downloads", m.downloads),
+			fmt.Sprintf("Fewer than %d downloads last week; little-used packages get little scrutiny", s.cfg.MinWeeklyDownloads)))
+	}
+
+	if len(version.Maintainers) == 1 {
+		findings = append(findings, finding(types.FindingTypeMaintainer, types.Severity(sev.SingleMaintainer), types.SeverityInfo,
+			"Single maintainer",
+			fmt.Sprintf("Only %s can publish this package; one compromised account is enough to release a malicious version", version.Maintainers[0].Name)))
+	}
+
+	if previous := previous(m.doc, pkg.Version); previous != "" {
+		before := emailDomains(m.doc.Versions[previous].Maintainers)
+		after := emailDomains(version.Maintainers)
+		if len(before) > 0 && len(after) > 0 && !slices.Equal(before, after) {
+			findings = append(findings, finding(types.FindingTypeMaintainer, types.Severity(sev.MaintainerChange), types.SeverityHigh,
+				"Maintainer email domain changed since "+previous,
+				fmt.Sprintf("Maintainer email domains changed from %s to %s; the account may have been taken over through an expired domain",
+					strings.Join(before, ", "), strings.Join(after, ", "))))
+		}
+	}
+	return findings
+}
+
+// published returns when a version was published
+func published(doc *registry.Packument, version string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, doc.Time[version])
+	return t, err == nil
+}
+
+// previous returns the version published last before version, or "" if
+// there is none
+func previous(doc *registry.Packument, version string) string {
+	current, ok := published(doc, version)
+	if !ok {
+		return ""
+	}
+
+	var best time.Time
+	var bestRaw string
+	for raw := range doc.Versions {
+		t, ok := published(doc, raw)
+		if !ok || !t.Before(current) {
+			continue
+		}
+		if bestRaw == "" || t.After(best) {
+			best, bestRaw = t, raw
+		}
+	}
+	return bestRaw
+}
+
+// emailDomains returns the sorted, distinct email domains of maintainers
+func emailDomains(maintainers []registry.Maintainer) []string {
+	var domains []string
+	for _, m := range maintainers {
+		_, domain, ok := strings.Cut(m.Email, "@")
+		if ok && domain != "" {
+			domains = append(domains, strings.ToLower(domain))
+		}
+	}
+	slices.Sort(domains)
+	return slices.Compact(domains)
+}
+
+// describeAge renders a release age in days
+func describeAge(age time.Duration) string {
+	switch days := int(age.Hours() / 24); days {
+	case 0:
+		return "less than a day ago"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+// lookup fetches the full registry document, which carries publish times
+// and maintainers, and, if the check is enabled, the download count of a
+// package
+func (s *Scanner) lookup(ctx context.Context, name string) (*metadata, error) {
+	if s.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+		defer cancel()
+	}
+
+	doc, err := s.metadata.FullPackument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	m := &metadata{doc: doc, downloads: -1}
+	if m.doc == nil || s.cfg.MinWeeklyDownloads <= 0 {
+		return m, nil
+	}
+
+	var downloads *struct {
+		Downloads int `json:"downloads"`
+	}
+	if err := s.get(ctx, s.downloadsURL+"/"+name, &downloads); err != nil {
+		return nil, err
+	}
+	if downloads != nil {
+		m.downloads = downloads.Downloads
+	}
+	return m, nil
+}
+
+// get decodes the JSON document at u into out, leaving out unchanged if
+// the server has no such document
+func (s *Scanner) get(ctx context.Context, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query npm downloads API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("npm downloads API returned status %d for %s", resp.StatusCode, u)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode downloads response for %s: %w", u, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package heuristics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestScan(t *testing.T) {
+	docs := map[string]string{
+		"/registry/fresh": `{
+			"time": {"created": "2020-01-01T00:00:00.000Z", "1.0.0": "2020-01-01T00:00:00.000Z", "1.0.1": "2026-10-14T08:00:00.000Z"},
+			"versions": {
+				"1.0.0": {"maintainers": [{"name": "alice", "email": "alice@example.com"}, {"name": "bob", "email": "bob@example.com"}]},
+				"1.0.1": {"maintainers": [{"name": "alice", "email": "alice@example.net"}, {"name": "bob", "email": "bob@example.com"}]}
+			}}`,
+		"/registry/solo": `{
+			"time": {"2.0.0": "2024-05-01T00:00:00.000Z"},
+			"versions": {"2.0.0": {"maintainers": [{"name": "carol", "email": "carol@example.org"}]}}}`,
+		"/downloads/fresh": `{"downloads": 1500000}`,
+		"/downloads/solo":  `{"downloads": 12}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	s := NewScanner(config.HeuristicsConfig{
+		NewVersionDays:     7,
+		MinWeeklyDownloads: 100,
+		Severity:           config.HeuristicsSeverityConfig{SingleMaintainer: "low"},
+	}, registry.NewClient(registry.Registries{Default: server.URL + "/registry"}))
+	s.downloadsURL = server.URL + "/downloads"
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "fresh", Version: "1.0.1", New: true},
+		{Name: "solo", Version: "2.0.0", New: true},
+		{Name: "missing", Version: "1.0.0", New: true},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	want := []struct {
+		pkg      string
+		typ      types.FindingType
+		severity types.Severity
+		title    string
+	}{
+		{"fresh", types.FindingTypeQuality, types.SeverityMedium, "Published 2 days ago"},
+		{"fresh", types.FindingTypeMaintainer, types.SeverityHigh, "Maintainer email domain changed since 1.0.0"},
+		{"solo", types.FindingTypeQuality, types.SeverityLow, "12 weekly downloads"},
+		{"solo", types.FindingTypeMaintainer, types.SeverityLow, "Single maintainer"},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("Scan() findings = %+v, want %d", result.Findings, len(want))
+	}
+	for i, w := range want {
+		f := result.Findings[i]
+		if f.Package != w.pkg || f.Type != w.typ || f.Severity != w.severity || f.Title != w.title {
+			t.Errorf("finding %d = %s %s %s %q, want %s %s %s %q", i, f.Package, f.Type, f.Severity, f.Title, w.pkg, w.typ, w.severity, w.title)
+		}
+	}
+	if !strings.Contains(result.Findings[1].Description, "example.com, example.net") {
+		t.Errorf("domain change description = %q", result.Findings[1].Description)
+	}
+}
+
+func TestSelects(t *testing.T) {
+	s := NewScanner(config.HeuristicsConfig{}, registry.NewClient(registry.Registries{}))
+	if s.Selects(manifest.Package{Name: "lodash"}) {
+		t.Error("Selects() = true for a package already in the project")
+	}
+	if !s.Selects(manifest.Package{Name: "lodash", New: true}) {
+		t.Error("Selects() = false for a new package")
+	}
+}
@@ -1,20 +1,39 @@
 package scanner
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/positronico/snapem/internal/config"
 	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/scanner/cache"
+	"github.com/positronico/snapem/internal/scanner/custom"
+	"github.com/positronico/snapem/internal/scanner/deprecation"
+	"github.com/positronico/snapem/internal/scanner/ghsa"
+	"github.com/positronico/snapem/internal/scanner/heuristics"
 	"github.com/positronico/snapem/internal/scanner/osv"
+	"github.com/positronico/snapem/internal/scanner/provenance"
+	"github.com/positronico/snapem/internal/scanner/releaseage"
+	"github.com/positronico/snapem/internal/scanner/scripts"
 	"github.com/positronico/snapem/internal/scanner/socket"
+	"github.com/positronico/snapem/internal/scanner/typosquat"
+	"github.com/positronico/snapem/internal/types"
 )
 
 // Orchestrator coordinates multiple security scanners
 type Orchestrator struct {
 	scanners []Scanner
 	config   *config.Config
+	cache    *cache.Cache
+
+	// disabled names scanners turned off in the config, for coverage
+	disabled []string
 }
 
 // NewOrchestrator creates a new scanner orchestrator
@@ -23,17 +42,70 @@ func NewOrchestrator(cfg *config.Config) *Orchestrator {
 		config: cfg,
 	}
 
+	// Registry scanners share a client, so each package document is
+	// fetched once per run
+	metadata := registry.NewClient(cfg.PackageManager.Registries())
+
+	if cfg.Scanning.Cache.Enabled && cfg.Scanning.Cache.Directory != "" {
+		o.cache = cache.New(cfg.Scanning.Cache)
+		o.cache.SetRegistries(cfg.PackageManager.Registries())
+	}
+
 	// Add enabled scanners
 	if cfg.Scanning.Socket.Enabled {
 		o.scanners = append(o.scanners, socket.NewClient(cfg.Scanning.Socket))
+	} else {
+		o.disabled = append(o.disabled, socket.ScannerName)
 	}
 	if cfg.Scanning.OSV.Enabled {
 		o.scanners = append(o.scanners, osv.NewClient(cfg.Scanning.OSV))
+	} else {
+		o.disabled = append(o.disabled, osv.ScannerName)
+	}
+	if cfg.Scanning.Typosquat.Enabled {
+		o.scanners = append(o.scanners, typosquat.NewScanner(cfg.Scanning.Typosquat))
+	} else {
+		o.disabled = append(o.disabled, typosquat.ScannerName)
+	}
+	if cfg.Scanning.InstallScripts.Enabled {
+		o.scanners = append(o.scanners, scripts.NewScanner(cfg.Scanning.InstallScripts, metadata))
+	} else {
+		o.disabled = append(o.disabled, scripts.ScannerName)
+	}
+	if cfg.Scanning.Deprecated.Enabled {
+		o.scanners = append(o.scanners, deprecation.NewScanner(cfg.Scanning.Deprecated, metadata, o.cache))
+	} else {
+		o.disabled = append(o.disabled, deprecation.ScannerName)
+	}
+	// GitHub advisories and provenance checks are opt-in, so leaving them
+	// off does not count against coverage
+	if cfg.Scanning.GitHub.Enabled {
+		o.scanners = append(o.scanners, ghsa.NewClient(cfg.Scanning.GitHub))
+	}
+	if cfg.Scanning.Provenance.Enabled || cfg.Scanning.Provenance.Require {
+		o.scanners = append(o.scanners, provenance.NewScanner(cfg.Scanning.Provenance, metadata))
+	}
+	// Heuristics only look at packages being installed, so they never
+	// count against the coverage of the project's dependencies
+	if cfg.Scanning.Heuristics.Enabled {
+		o.scanners = append(o.scanners, heuristics.NewScanner(cfg.Scanning.Heuristics, metadata))
+	}
+	if cfg.Scanning.Policy.MinReleaseAge > 0 && cfg.Scanning.Policy.ReleaseAge != "ignore" {
+		o.scanners = append(o.scanners, releaseage.NewScanner(cfg.Scanning.Policy.MinReleaseAge, metadata))
+	}
+	for _, c := range cfg.Scanning.Custom {
+		o.scanners = append(o.scanners, custom.NewScanner(c))
 	}
 
 	return o
 }
 
+// SetScanners replaces the configured scanners
+func (o *Orchestrator) SetScanners(scanners ...Scanner) {
+	o.scanners = scanners
+	o.disabled = nil
+}
+
 // Scan runs all configured scanners concurrently
 func (o *Orchestrator) Scan(ctx context.Context, packages []manifest.Package) (*AggregatedResult, error) {
 	start := time.Now()
@@ -49,11 +121,13 @@ func (o *Orchestrator) Scan(ctx context.Context, packages []manifest.Package) (*
 
 	// Filter out allowlisted packages
 	filteredPackages := o.filterAllowlisted(packages)
+	coverage := o.startCoverage(packages, filteredPackages)
 
 	// Run scanners concurrently
 	var wg sync.WaitGroup
-	resultsChan := make(chan *ScanResult, len(o.scanners))
-	errChan := make(chan error, len(o.scanners))
+	resultsChan := make(chan *ScanResult, 2*len(o.scanners))
+	errChan := make(chan ScannerError, len(o.scanners))
+	hits := newHitCounter()
 
 	for _, s := range o.scanners {
 		if !s.IsAvailable() {
@@ -62,12 +136,14 @@ func (o *Orchestrator) Scan(ctx context.Context, packages []manifest.Package) (*
 		wg.Add(1)
 		go func(scanner Scanner) {
 			defer wg.Done()
-			result, err := scanner.Scan(ctx, filteredPackages)
+			results, err := o.runScanner(ctx, scanner, filteredPackages, coverage, hits)
 			if err != nil {
-				errChan <- err
+				errChan <- ScannerError{Scanner: scanner.Name(), Message: err.Error()}
 				return
 			}
-			resultsChan <- result
+			for _, result := range results {
+				resultsChan <- result
+			}
 		}(s)
 	}
 
@@ -80,7 +156,7 @@ func (o *Orchestrator) Scan(ctx context.Context, packages []manifest.Package) (*
 
 	// Collect results
 	var results []*ScanResult
-	var firstErr error
+	var errs []ScannerError
 
 	for {
 		select {
@@ -93,8 +169,8 @@ func (o *Orchestrator) Scan(ctx context.Context, packages []manifest.Package) (*
 		case err, ok := <-errChan:
 			if !ok {
 				errChan = nil
-			} else if firstErr == nil {
-				firstErr = err
+			} else {
+				errs = append(errs, err)
 			}
 		}
 
@@ -104,42 +180,34 @@ func (o *Orchestrator) Scan(ctx context.Context, packages []manifest.Package) (*
 	}
 
 	// If all scanners failed, return error
-	if len(results) == 0 && firstErr != nil {
-		return nil, firstErr
+	if len(results) == 0 && len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	o.sortResults(results)
+	if policy := o.blocklistResult(packages); policy != nil {
+		results = append(results, policy)
+	}
+	if low := o.lowScoreResult(results); low != nil {
+		results = append(results, low)
 	}
 
 	// Aggregate results
-	aggregated := o.aggregate(results)
+	aggregated := o.aggregate(results, packages)
 	aggregated.TotalPackages = len(filteredPackages)
+	aggregated.CacheHits = hits.total()
 	aggregated.Duration = time.Since(start)
-
-	// Filter out blocklisted packages (add findings for them)
-	for _, pkg := range packages {
-		if o.config.IsPackageBlocklisted(pkg.Name) {
-			aggregated.Results = append(aggregated.Results, &ScanResult{
-				Scanner:  "policy",
-				Packages: 1,
-				Findings: []Finding{
-					{
-						Package:     pkg.Name,
-						Version:     pkg.Version,
-						Type:        FindingTypeMalware,
-						Severity:    SeverityCritical,
-						Title:       "Blocklisted package",
-						Description: "This package is in your blocklist",
-					},
-				},
-			})
-			aggregated.HasMalware = true
-			aggregated.TotalFindings++
-		}
-	}
+	aggregated.Coverage = coverage.result()
+	aggregated.Errors = sortedScannerErrors(errs)
 
 	return aggregated, nil
 }
 
-// ScanWithProgress runs scanners and reports progress via callback
-func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest.Package, onProgress func(scanner string, done bool)) (*AggregatedResult, error) {
+// ScanWithProgress runs scanners and reports progress via callback. Each
+// scanner is reported once when it starts and once when it is done, with
+// completed and total of 0; scanners that count packages as they go, such
+// as OSV, report those counts in between.
+func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest.Package, onProgress func(scanner string, completed, total int, done bool)) (*AggregatedResult, error) {
 	start := time.Now()
 
 	if len(packages) == 0 {
@@ -152,10 +220,12 @@ func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest
 	}
 
 	filteredPackages := o.filterAllowlisted(packages)
+	coverage := o.startCoverage(packages, filteredPackages)
 
 	var wg sync.WaitGroup
-	resultsChan := make(chan *ScanResult, len(o.scanners))
-	errChan := make(chan error, len(o.scanners))
+	resultsChan := make(chan *ScanResult, 2*len(o.scanners))
+	errChan := make(chan ScannerError, len(o.scanners))
+	hits := newHitCounter()
 
 	for _, s := range o.scanners {
 		if !s.IsAvailable() {
@@ -164,18 +234,24 @@ func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest
 		wg.Add(1)
 		go func(scanner Scanner) {
 			defer wg.Done()
+			scanCtx := ctx
 			if onProgress != nil {
-				onProgress(scanner.Name(), false)
+				onProgress(scanner.Name(), 0, 0, false)
+				scanCtx = types.WithProgress(ctx, func(completed, total int) {
+					onProgress(scanner.Name(), completed, total, false)
+				})
 			}
-			result, err := scanner.Scan(ctx, filteredPackages)
+			results, err := o.runScanner(scanCtx, scanner, filteredPackages, coverage, hits)
 			if onProgress != nil {
-				onProgress(scanner.Name(), true)
+				onProgress(scanner.Name(), 0, 0, true)
 			}
 			if err != nil {
-				errChan <- err
+				errChan <- ScannerError{Scanner: scanner.Name(), Message: err.Error()}
 				return
 			}
-			resultsChan <- result
+			for _, result := range results {
+				resultsChan <- result
+			}
 		}(s)
 	}
 
@@ -186,7 +262,7 @@ func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest
 	}()
 
 	var results []*ScanResult
-	var firstErr error
+	var errs []ScannerError
 
 	for {
 		select {
@@ -199,8 +275,8 @@ func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest
 		case err, ok := <-errChan:
 			if !ok {
 				errChan = nil
-			} else if firstErr == nil {
-				firstErr = err
+			} else {
+				errs = append(errs, err)
 			}
 		}
 
@@ -209,33 +285,416 @@ func (o *Orchestrator) ScanWithProgress(ctx context.Context, packages []manifest
 		}
 	}
 
-	if len(results) == 0 && firstErr != nil {
-		return nil, firstErr
+	if len(results) == 0 && len(errs) > 0 {
+		return nil, errs[0]
 	}
 
-	aggregated := o.aggregate(results)
+	o.sortResults(results)
+	if policy := o.blocklistResult(packages); policy != nil {
+		results = append(results, policy)
+	}
+	if low := o.lowScoreResult(results); low != nil {
+		results = append(results, low)
+	}
+
+	aggregated := o.aggregate(results, packages)
 	aggregated.TotalPackages = len(filteredPackages)
+	aggregated.CacheHits = hits.total()
 	aggregated.Duration = time.Since(start)
+	aggregated.Coverage = coverage.result()
+	aggregated.Errors = sortedScannerErrors(errs)
 
 	return aggregated, nil
 }
 
+// startCoverage begins coverage tracking, recording the outcomes known
+// before any scanner runs: allowlisted packages and disabled or
+// unavailable scanners
+func (o *Orchestrator) startCoverage(packages, filtered []manifest.Package) *coverageTracker {
+	var names []string
+	for _, s := range o.scanners {
+		names = append(names, s.Name())
+	}
+	names = append(names, o.disabled...)
+
+	coverage := newCoverageTracker(packages, names)
+
+	var excluded []manifest.Package
+	for _, pkg := range packages {
+		if o.config.IsPackageAllowlisted(pkg.Name, pkg.Version) {
+			excluded = append(excluded, pkg)
+		}
+	}
+
+	for _, s := range o.scanners {
+		if !s.IsAvailable() {
+			coverage.set(s.Name(), filtered, types.CoverageUnavailable)
+		}
+		coverage.set(s.Name(), excluded, types.CoverageExcluded)
+	}
+	for _, name := range o.disabled {
+		coverage.set(name, filtered, types.CoverageDisabled)
+		coverage.set(name, excluded, types.CoverageExcluded)
+	}
+
+	return coverage
+}
+
+// runScanner scans the packages in the scanner's ecosystems, through the
+// cache, and records the coverage outcome and cache hits for each package
+func (o *Orchestrator) runScanner(ctx context.Context, s Scanner, packages []manifest.Package, coverage *coverageTracker, hits *hitCounter) ([]*ScanResult, error) {
+	supported, unsupported := supportedPackages(s, selectedPackages(s, packages))
+	coverage.set(s.Name(), unsupported, types.CoverageUnsupported)
+	if len(supported) == 0 {
+		return nil, nil
+	}
+	if o.cache != nil && cacheable(s) {
+		hits.expect(supported)
+	}
+
+	results, cached, err := o.scanWithCache(ctx, s, supported)
+	if err != nil {
+		coverage.set(s.Name(), supported, types.CoverageFailed)
+		return nil, err
+	}
+
+	coverage.set(s.Name(), supported, types.CoverageChecked)
+	coverage.set(s.Name(), cached, types.CoverageCached)
+	for _, result := range results {
+		coverage.set(s.Name(), failedPackages(result, supported), types.CoverageFailed)
+	}
+	hits.add(cached)
+	return results, nil
+}
+
+// failedPackages returns the packages a result lists as not checked
+func failedPackages(result *ScanResult, packages []manifest.Package) []manifest.Package {
+	if len(result.Failed) == 0 {
+		return nil
+	}
+	failed := make(map[string]bool, len(result.Failed))
+	for _, f := range result.Failed {
+		failed[f.Package+"@"+f.Version] = true
+	}
+	var out []manifest.Package
+	for _, pkg := range packages {
+		if failed[pkg.Name+"@"+pkg.Version] {
+			out = append(out, pkg)
+		}
+	}
+	return out
+}
+
+// cacheable reports whether a scanner's results may be cached
+func cacheable(s Scanner) bool {
+	if offline, ok := s.(OfflineScanner); ok && offline.Offline() {
+		return false
+	}
+	if volatile, ok := s.(VolatileScanner); ok && volatile.Volatile() {
+		return false
+	}
+	return true
+}
+
+// scanWithCache runs a scanner on the packages missing from the cache and
+// returns the fresh result alongside a result built from cache hits
+func (o *Orchestrator) scanWithCache(ctx context.Context, s Scanner, packages []manifest.Package) ([]*ScanResult, []manifest.Package, error) {
+	if o.cache == nil || !cacheable(s) {
+		result, err := s.Scan(ctx, packages)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*ScanResult{result}, nil, nil
+	}
+
+	start := time.Now()
+	var misses, hits []manifest.Package
+	cachedFindings := []Finding{}
+	var cachedScores []PackageScore
+
+	for _, pkg := range packages {
+		if findings, ok := o.cache.Get(s.Name(), pkg); ok {
+			hits = append(hits, pkg)
+			cachedFindings = append(cachedFindings, findings...)
+			var score PackageScore
+			if o.cache.Load(scoreNamespace+s.Name(), o.cache.Key(pkg), &score) {
+				cachedScores = append(cachedScores, score)
+			}
+		} else {
+			misses = append(misses, pkg)
+		}
+	}
+
+	var results []*ScanResult
+	if len(hits) > 0 {
+		results = append(results, &ScanResult{
+			Scanner:      s.Name(),
+			Packages:     len(hits),
+			Findings:     cachedFindings,
+			Scores:       cachedScores,
+			ScanDuration: time.Since(start),
+			Cached:       true,
+		})
+	}
+
+	if len(misses) > 0 {
+		// Count cache hits as done in the scanner's progress
+		scanCtx := ctx
+		if len(hits) > 0 {
+			scanCtx = types.WithProgress(ctx, func(completed, total int) {
+				types.ReportProgress(ctx, len(hits)+completed, len(hits)+total)
+			})
+		}
+		result, err := s.Scan(scanCtx, misses)
+		if err != nil {
+			return nil, nil, err
+		}
+		o.storeInCache(s.Name(), misses, result)
+		results = append(results, result)
+	}
+
+	return results, hits, nil
+}
+
+// scoreNamespace prefixes the scanner name in the cache namespace of
+// package scores
+const scoreNamespace = "score/"
+
+// storeInCache records per-package findings and scores from a fresh scan
+// result. Cache write failures are ignored since the cache is only an
+// optimization.
+func (o *Orchestrator) storeInCache(scanner string, packages []manifest.Package, result *ScanResult) {
+	scores := make(map[string]PackageScore)
+	for _, score := range result.Scores {
+		scores[score.Package+"@"+score.Version] = score
+	}
+
+	byPackage := make(map[string][]Finding)
+	for _, f := range result.Findings {
+		key := f.Package + "@" + f.Version
+		byPackage[key] = append(byPackage[key], f)
+	}
+
+	// Packages the scanner could not check are looked up again next time
+	failed := make(map[string]bool, len(result.Failed))
+	for _, f := range result.Failed {
+		failed[f.Package+"@"+f.Version] = true
+	}
+
+	for _, pkg := range packages {
+		key := pkg.Name + "@" + pkg.Version
+		if failed[key] {
+			continue
+		}
+		_ = o.cache.Put(scanner, pkg, byPackage[key])
+		if score, ok := scores[key]; ok {
+			_ = o.cache.Store(scoreNamespace+scanner, o.cache.Key(pkg), score)
+		}
+	}
+}
+
+// hitCounter tracks, per package, how many cacheable scanners checked it
+// and how many of those served it from cache. Offline and volatile
+// scanners, and scanners that did not select a package, never count.
+type hitCounter struct {
+	mu       sync.Mutex
+	expected map[string]int
+	counts   map[string]int
+}
+
+func newHitCounter() *hitCounter {
+	return &hitCounter{expected: make(map[string]int), counts: make(map[string]int)}
+}
+
+// expect records that a cacheable scanner is checking packages
+func (h *hitCounter) expect(packages []manifest.Package) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, pkg := range packages {
+		h.expected[pkg.Name+"@"+pkg.Version]++
+	}
+}
+
+// add records packages a scanner served from cache
+func (h *hitCounter) add(packages []manifest.Package) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, pkg := range packages {
+		h.counts[pkg.Name+"@"+pkg.Version]++
+	}
+}
+
+// total returns the number of packages every cacheable scanner that
+// checked them served from cache
+func (h *hitCounter) total() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	total := 0
+	for key, expected := range h.expected {
+		if h.counts[key] == expected {
+			total++
+		}
+	}
+	return total
+}
+
 func (o *Orchestrator) filterAllowlisted(packages []manifest.Package) []manifest.Package {
 	var filtered []manifest.Package
 	for _, pkg := range packages {
-		if !o.config.IsPackageAllowlisted(pkg.Name) {
+		if !o.config.IsPackageAllowlisted(pkg.Name, pkg.Version) {
 			filtered = append(filtered, pkg)
 		}
 	}
 	return filtered
 }
 
-func (o *Orchestrator) aggregate(results []*ScanResult) *AggregatedResult {
+// blocklistResult returns a critical malware finding for each blocklisted
+// package, or nil if there are none. Every scan entry point adds it before
+// aggregating so the counts and flags include it.
+func (o *Orchestrator) blocklistResult(packages []manifest.Package) *ScanResult {
+	var findings []Finding
+	for _, pkg := range packages {
+		if o.config.IsPackageBlocklisted(pkg.Name, pkg.Version) {
+			findings = append(findings, Finding{
+				Package:     pkg.Name,
+				Version:     pkg.Version,
+				Type:        FindingTypeMalware,
+				Severity:    SeverityCritical,
+				Title:       "Blocklisted package",
+				Description: "This package is in your blocklist",
+			})
+		}
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	return &ScanResult{
+		Scanner:  "policy",
+		Packages: len(findings),
+		Findings: findings,
+	}
+}
+
+// lowScoreResult returns a finding for each package whose score is below
+// scanning.socket.min_score, or nil if there are none. The findings are
+// derived from the scores on every scan, so they are never cached with an
+// old threshold.
+func (o *Orchestrator) lowScoreResult(results []*ScanResult) *ScanResult {
+	minScore := o.config.Scanning.Socket.MinScore
+	if minScore <= 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, result := range results {
+		for _, score := range result.Scores {
+			if score.Overall >= minScore {
+				continue
+			}
+			findings = append(findings, Finding{
+				Package:     score.Package,
+				Version:     score.Version,
+				Type:        FindingTypeLowScore,
+				Severity:    SeverityMedium,
+				Title:       fmt.Sprintf("Socket score %d is below the minimum of %d", score.Overall, minScore),
+				Description: score.Breakdown(),
+			})
+		}
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	return &ScanResult{
+		Scanner:  "policy",
+		Packages: len(findings),
+		Findings: findings,
+	}
+}
+
+// overrideKeys maps scanner names to their key in scanning.overrides
+var overrideKeys = map[string]string{
+	socket.ScannerName:      "socket",
+	osv.ScannerName:         "osv",
+	ghsa.ScannerName:        "github",
+	typosquat.ScannerName:   "typosquat",
+	scripts.ScannerName:     "install_scripts",
+	deprecation.ScannerName: "deprecated",
+	provenance.ScannerName:  "provenance",
+	heuristics.ScannerName:  "heuristics",
+	releaseage.ScannerName:  "release_age",
+}
+
+// applyOverride changes a finding's severity or type as scanning.overrides
+// says, noting the original in its description. Findings are cached as
+// the scanner reported them, so changed overrides apply on the next scan.
+// The policy's own findings are never overridden.
+func (o *Orchestrator) applyOverride(scanner string, f *Finding) {
+	if scanner == "policy" {
+		return
+	}
+	key, ok := overrideKeys[scanner]
+	if !ok {
+		key = strings.ToLower(scanner) // a custom scanner
+	}
+	override, ok := o.config.Scanning.FindingOverride(key, f)
+	if !ok {
+		return
+	}
+	original := fmt.Sprintf("%s %s", f.Severity, f.Type)
+	if override.Severity != "" {
+		f.Severity = Severity(override.Severity)
+	}
+	if override.Type != "" {
+		f.Type = FindingType(override.Type)
+	}
+	note := fmt.Sprintf("(overridden by scanning.overrides: reported as %s)", original)
+	if f.Description == "" {
+		f.Description = note
+	} else {
+		f.Description += " " + note
+	}
+}
+
+func (o *Orchestrator) aggregate(results []*ScanResult, packages []manifest.Package) *AggregatedResult {
 	aggregated := &AggregatedResult{
 		Results: results,
 	}
 
+	// Findings are cached without the package's place in this project's
+	// tree, so mark direct dependencies here
+	direct := make(map[string]bool)
+	for _, pkg := range packages {
+		if pkg.Direct {
+			direct[pkg.Name+"@"+pkg.Version] = true
+		}
+	}
+
+	// Advisory databases overlap: OSV and GitHub both report GHSA IDs, and
+	// Socket.dev reports the CVE of the same advisory. Keep the first
+	// report of an ID or any of its aliases for each package version.
+	reported := make(map[string]bool)
+	for _, result := range results {
+		kept := result.Findings[:0]
+		for _, finding := range result.Findings {
+			o.applyOverride(result.Scanner, &finding)
+			ids := finding.IDs()
+			prefix := finding.Package + "@" + finding.Version + " "
+			if slices.ContainsFunc(ids, func(id string) bool { return reported[prefix+strings.ToUpper(id)] }) {
+				continue
+			}
+			for _, id := range ids {
+				reported[prefix+strings.ToUpper(id)] = true
+			}
+			finding.Direct = direct[finding.Package+"@"+finding.Version]
+			kept = append(kept, finding)
+		}
+		result.Findings = kept
+	}
+
 	for _, result := range results {
+		aggregated.Scores = append(aggregated.Scores, result.Scores...)
 		for _, finding := range result.Findings {
 			aggregated.TotalFindings++
 
@@ -252,6 +711,10 @@ func (o *Orchestrator) aggregate(results []*ScanResult) *AggregatedResult {
 		}
 	}
 
+	slices.SortFunc(aggregated.Scores, func(a, b PackageScore) int {
+		return cmp.Or(strings.Compare(a.Package, b.Package), strings.Compare(a.Version, b.Version))
+	})
+
 	return aggregated
 }
 
@@ -275,3 +738,25 @@ func (o *Orchestrator) AvailableScanners() []string {
 	}
 	return names
 }
+
+// sortResults orders results by scanner, in the order they were set, so
+// that which scanner's report of a shared advisory aggregate keeps does
+// not depend on which finished first. A scanner's cached results stay
+// ahead of its fresh ones.
+func (o *Orchestrator) sortResults(results []*ScanResult) {
+	order := make(map[string]int, len(o.scanners))
+	for i, s := range o.scanners {
+		order[s.Name()] = i
+	}
+	slices.SortStableFunc(results, func(a, b *ScanResult) int {
+		return cmp.Compare(order[a.Scanner], order[b.Scanner])
+	})
+}
+
+// sortedScannerErrors orders failures by scanner so output is stable
+func sortedScannerErrors(errs []ScannerError) []ScannerError {
+	slices.SortFunc(errs, func(a, b ScannerError) int {
+		return strings.Compare(a.Scanner, b.Scanner)
+	})
+	return errs
+}
@@ -0,0 +1,405 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/scanner/socket"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// TestScanPartialFailure keeps the results of working scanners and records
+// the failed ones
+func TestScanPartialFailure(t *testing.T) {
+	packages := []manifest.Package{{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"}}
+
+	tests := []struct {
+		name     string
+		scanners []Scanner
+		wantErr  bool
+		wantErrs []ScannerError
+	}{
+		{
+			name: "one of two fails",
+			scanners: []Scanner{
+				&fakeScanner{name: "ok", available: true},
+				&fakeScanner{name: "socket", available: true, err: errors.New("rate limit exceeded")},
+			},
+			wantErrs: []ScannerError{{Scanner: "socket", Message: "rate limit exceeded"}},
+		},
+		{
+			name: "all fail",
+			scanners: []Scanner{
+				&fakeScanner{name: "osv", available: true, err: errors.New("timeout")},
+				&fakeScanner{name: "socket", available: true, err: errors.New("rate limit exceeded")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOrchestrator(&config.Config{})
+			o.SetScanners(tt.scanners...)
+
+			for _, progress := range []bool{false, true} {
+				var (
+					result *AggregatedResult
+					err    error
+				)
+				if progress {
+					result, err = o.ScanWithProgress(context.Background(), packages, nil)
+				} else {
+					result, err = o.Scan(context.Background(), packages)
+				}
+
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("progress=%v: expected error when every scanner fails", progress)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("progress=%v: error = %v", progress, err)
+				}
+				if len(result.Results) != 1 || result.Results[0].Scanner != "ok" {
+					t.Errorf("progress=%v: results = %+v, want the ok scanner's", progress, result.Results)
+				}
+				if len(result.Errors) != len(tt.wantErrs) || result.Errors[0] != tt.wantErrs[0] {
+					t.Errorf("progress=%v: errors = %+v, want %+v", progress, result.Errors, tt.wantErrs)
+				}
+			}
+		})
+	}
+}
+
+// TestScanBlocklist checks that both scan entry points report blocklisted
+// packages even when every scanner comes back clean, and that overrides
+// cannot reclassify them
+func TestScanBlocklist(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanning.Policy.Blocklist = []string{"event-stream@>=3.3.6"}
+	cfg.Scanning.Overrides = map[string]map[string]config.FindingOverride{
+		"policy": {"malware": {Severity: "low", Type: "quality"}},
+	}
+
+	o := NewOrchestrator(cfg)
+	o.SetScanners(&fakeScanner{name: "ok", available: true})
+
+	packages := []manifest.Package{
+		{Name: "event-stream", Version: "3.3.6", Ecosystem: "npm"},
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+	}
+
+	for _, progress := range []bool{false, true} {
+		var (
+			result *AggregatedResult
+			err    error
+		)
+		if progress {
+			result, err = o.ScanWithProgress(context.Background(), packages, func(string, int, int, bool) {})
+		} else {
+			result, err = o.Scan(context.Background(), packages)
+		}
+		if err != nil {
+			t.Fatalf("progress=%v: error = %v", progress, err)
+		}
+
+		if !result.HasMalware || !result.HasCritical || result.TotalFindings != 1 {
+			t.Errorf("progress=%v: HasMalware=%v HasCritical=%v TotalFindings=%d, want true true 1",
+				progress, result.HasMalware, result.HasCritical, result.TotalFindings)
+		}
+		if malware := result.MalwareFindings(); len(malware) != 1 || malware[0].Package != "event-stream" {
+			t.Errorf("progress=%v: malware findings = %+v", progress, malware)
+		}
+	}
+}
+
+// findingScanner reports one finding with a fixed ID for every package
+type findingScanner struct {
+	fakeScanner
+	id      string
+	aliases []string
+}
+
+func (f *findingScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	result := &ScanResult{Scanner: f.name, Packages: len(packages)}
+	for _, pkg := range packages {
+		result.Findings = append(result.Findings, Finding{Package: pkg.Name, Version: pkg.Version, Type: FindingTypeCVE, Severity: SeverityHigh, ID: f.id, Aliases: f.aliases})
+	}
+	return result, nil
+}
+
+// TestScanDeduplicatesAdvisories reports an advisory found by two scanners
+// once
+func TestScanDeduplicatesAdvisories(t *testing.T) {
+	o := NewOrchestrator(&config.Config{})
+	o.SetScanners(
+		&findingScanner{fakeScanner{name: "osv", available: true}, "GHSA-1", nil},
+		&findingScanner{fakeScanner{name: "github", available: true}, "GHSA-1", nil},
+		&findingScanner{fakeScanner{name: "other", available: true}, "GHSA-2", nil},
+	)
+
+	result, err := o.Scan(context.Background(), []manifest.Package{{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.TotalFindings != 2 || len(result.AllFindings()) != 2 {
+		t.Errorf("TotalFindings = %d, findings = %+v, want GHSA-1 and GHSA-2 once each", result.TotalFindings, result.AllFindings())
+	}
+}
+
+// slowScanner is a findingScanner that takes a while to answer
+type slowScanner struct {
+	findingScanner
+	delay time.Duration
+}
+
+func (s *slowScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	time.Sleep(s.delay)
+	return s.findingScanner.Scan(ctx, packages)
+}
+
+// TestScanResultOrder keeps results in scanner order, so the advisory a
+// slower scanner shares with a faster one is still reported as the first
+// scanner saw it
+func TestScanResultOrder(t *testing.T) {
+	o := NewOrchestrator(&config.Config{})
+	o.SetScanners(
+		&slowScanner{findingScanner{fakeScanner{name: "osv", available: true}, "GHSA-1", []string{"CVE-2021-1"}}, 20 * time.Millisecond},
+		&findingScanner{fakeScanner{name: "github", available: true}, "CVE-2021-1", nil},
+	)
+
+	result, err := o.Scan(context.Background(), []manifest.Package{{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Results) != 2 || result.Results[0].Scanner != "osv" || result.Results[1].Scanner != "github" {
+		t.Fatalf("results not in scanner order: %+v", result.Results)
+	}
+	if findings := result.AllFindings(); len(findings) != 1 || findings[0].ID != "GHSA-1" {
+		t.Errorf("findings = %+v, want osv's GHSA-1", findings)
+	}
+}
+
+// TestScanDeduplicatesAliases reports an advisory once when scanners name
+// it by different IDs
+func TestScanDeduplicatesAliases(t *testing.T) {
+	o := NewOrchestrator(&config.Config{})
+	o.SetScanners(
+		&findingScanner{fakeScanner{name: "osv", available: true}, "GHSA-1", []string{"CVE-2021-1"}},
+		&findingScanner{fakeScanner{name: "socket", available: true}, "alert-key", []string{"cve-2021-1"}},
+		&findingScanner{fakeScanner{name: "github", available: true}, "GHSA-2", []string{"CVE-2021-2"}},
+	)
+
+	result, err := o.Scan(context.Background(), []manifest.Package{{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var ids []string
+	for _, f := range result.AllFindings() {
+		ids = append(ids, f.DisplayID())
+	}
+	if !slices.Equal(ids, []string{"CVE-2021-1", "CVE-2021-2"}) {
+		t.Errorf("findings = %v, want CVE-2021-1 and CVE-2021-2 once each", ids)
+	}
+}
+
+// countingScanner reports progress after each package
+type countingScanner struct {
+	fakeScanner
+}
+
+func (c *countingScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	for i := range packages {
+		types.ReportProgress(ctx, i+1, len(packages))
+	}
+	return &ScanResult{Scanner: c.name, Packages: len(packages)}, nil
+}
+
+// TestScanWithProgressCounts passes a scanner's package counts on between
+// its start and done calls
+func TestScanWithProgressCounts(t *testing.T) {
+	o := NewOrchestrator(&config.Config{})
+	o.SetScanners(&countingScanner{fakeScanner{name: "osv", available: true}})
+
+	packages := []manifest.Package{
+		{Name: "a", Version: "1.0.0", Ecosystem: "npm"},
+		{Name: "b", Version: "1.0.0", Ecosystem: "npm"},
+	}
+
+	type call struct {
+		scanner          string
+		completed, total int
+		done             bool
+	}
+	var calls []call
+	_, err := o.ScanWithProgress(context.Background(), packages, func(scanner string, completed, total int, done bool) {
+		calls = append(calls, call{scanner, completed, total, done})
+	})
+	if err != nil {
+		t.Fatalf("ScanWithProgress() error = %v", err)
+	}
+
+	want := []call{
+		{"osv", 0, 0, false},
+		{"osv", 1, 2, false},
+		{"osv", 2, 2, false},
+		{"osv", 0, 0, true},
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+}
+
+// scoreScanner scores every package 50
+type scoreScanner struct {
+	fakeScanner
+}
+
+func (s *scoreScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	result := &ScanResult{Scanner: s.name, Packages: len(packages)}
+	for _, pkg := range packages {
+		result.Scores = append(result.Scores, types.PackageScore{Package: pkg.Name, Version: pkg.Version, Overall: 50})
+	}
+	return result, nil
+}
+
+// TestScanLowScore reports packages below min_score, including from
+// cached scores, against the threshold of the current scan
+func TestScanLowScore(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanning.Cache = config.CacheConfig{Enabled: true, Directory: t.TempDir(), TTL: time.Hour}
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "chalk", Version: "5.3.0", Ecosystem: "npm"},
+	}
+
+	for _, tt := range []struct {
+		minScore int
+		want     int
+	}{{60, 2}, {40, 0}, {0, 0}} {
+		cfg.Scanning.Socket.MinScore = tt.minScore
+		o := NewOrchestrator(cfg)
+		o.SetScanners(&scoreScanner{fakeScanner{name: "scores", available: true}})
+
+		result, err := o.Scan(context.Background(), packages)
+		if err != nil {
+			t.Fatalf("min_score %d: error = %v", tt.minScore, err)
+		}
+		if got := result.CountByType(FindingTypeLowScore); got != tt.want {
+			t.Errorf("min_score %d: %d low score findings, want %d", tt.minScore, got, tt.want)
+		}
+		if len(result.Scores) != 2 || result.Scores[0].Package != "chalk" {
+			t.Errorf("min_score %d: scores = %+v, want both, sorted", tt.minScore, result.Scores)
+		}
+	}
+}
+
+// TestScanCacheHits serves a repeated scan from cache with the default
+// scanner mix, where the offline and volatile scanners never count
+func TestScanCacheHits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/osv/querybatch" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": []any{}})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.PackageManager.Registry = server.URL + "/registry"
+	cfg.Scanning.Socket.Enabled = true
+	cfg.Scanning.OSV = config.OSVConfig{Enabled: true, BaseURL: server.URL + "/osv", Timeout: time.Second}
+	cfg.Scanning.Typosquat.Enabled = true
+	cfg.Scanning.InstallScripts = config.InstallScriptsConfig{Enabled: true, Timeout: time.Second}
+	cfg.Scanning.Deprecated = config.DeprecatedConfig{Enabled: true, Timeout: time.Second}
+	cfg.Scanning.Heuristics = config.HeuristicsConfig{Enabled: true, Timeout: time.Second}
+	cfg.Scanning.Policy.MinReleaseAge = 72 * time.Hour
+	cfg.Scanning.Cache = config.CacheConfig{Enabled: true, Directory: t.TempDir(), TTL: time.Hour}
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "chalk", Version: "5.3.0", Ecosystem: "npm"},
+	}
+
+	for i, want := range []int{0, len(packages)} {
+		result, err := NewOrchestrator(cfg).Scan(context.Background(), packages)
+		if err != nil {
+			t.Fatalf("scan %d: %v", i+1, err)
+		}
+		if len(result.Errors) > 0 {
+			t.Fatalf("scan %d: scanner errors %+v", i+1, result.Errors)
+		}
+		if result.CacheHits != want {
+			t.Errorf("scan %d: CacheHits = %d, want %d", i+1, result.CacheHits, want)
+		}
+	}
+}
+
+// TestScanOverrides downgrades protestware without touching malware
+func TestScanOverrides(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanning.Overrides = map[string]map[string]config.FindingOverride{
+		"socket": {"protestware": {Severity: "low", Type: "quality"}},
+	}
+	o := NewOrchestrator(cfg)
+	o.SetScanners(&alertScanner{fakeScanner{name: socket.ScannerName, available: true}, "protestware"})
+
+	result, err := o.Scan(context.Background(), []manifest.Package{{Name: "node-ipc", Version: "10.1.1", Ecosystem: "npm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := result.AllFindings()[0]
+	if result.HasMalware || result.HasCritical || f.Severity != SeverityLow || f.Type != FindingTypeQuality {
+		t.Errorf("finding = %+v, want a low quality finding", f)
+	}
+	if !strings.HasSuffix(f.Description, "(overridden by scanning.overrides: reported as critical malware)") {
+		t.Errorf("description = %q", f.Description)
+	}
+
+	o.SetScanners(&alertScanner{fakeScanner{name: socket.ScannerName, available: true}, "malware"})
+	result, err = o.Scan(context.Background(), []manifest.Package{{Name: "evil", Version: "1.0.0", Ecosystem: "npm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasMalware {
+		t.Error("malware should still be reported as malware")
+	}
+}
+
+// alertScanner reports a critical malware finding titled with a Socket
+// alert type for every package
+type alertScanner struct {
+	fakeScanner
+	alert string
+}
+
+func (a *alertScanner) Scan(ctx context.Context, packages []manifest.Package) (*ScanResult, error) {
+	result := &ScanResult{Scanner: a.name, Packages: len(packages)}
+	for _, pkg := range packages {
+		result.Findings = append(result.Findings, Finding{Package: pkg.Name, Version: pkg.Version, Type: FindingTypeMalware, Severity: SeverityCritical, Title: a.alert, Description: "Known " + a.alert})
+	}
+	return result, nil
+}
+
+// TestOverrideKeys checks that every scanner key is documented in config
+func TestOverrideKeys(t *testing.T) {
+	for name, key := range overrideKeys {
+		if !slices.Contains(config.OverrideScanners, key) {
+			t.Errorf("override key %q of %s is not in config.OverrideScanners", key, name)
+		}
+	}
+	if len(overrideKeys) != len(config.OverrideScanners) {
+		t.Errorf("%d override keys, config lists %d", len(overrideKeys), len(config.OverrideScanners))
+	}
+}
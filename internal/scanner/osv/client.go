@@ -7,41 +7,57 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/httpclient"
 	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/redact"
 	"github.com/positronico/snapem/internal/types"
 )
 
 const (
-	baseURL      = "https://api.osv.dev/v1"
-	batchURL     = baseURL + "/querybatch"
-	maxBatchSize = 1000
+	// defaultBaseURL is the OSV API, used when scanning.osv.base_url is
+	// empty
+	defaultBaseURL = "https://api.osv.dev/v1"
+	maxBatchSize   = 1000
+
+	// detailWorkers bounds concurrent vulnerability detail requests
+	detailWorkers = 8
 )
 
 // Client handles Google OSV API interactions
 type Client struct {
-	httpClient *http.Client
-	timeout    time.Duration
+	httpClient    *http.Client
+	baseURL       string
+	timeout       time.Duration
+	maxReferences int
 }
 
 // NewClient creates a new OSV client
 func NewClient(cfg config.OSVConfig) *Client {
-	retryClient := retryablehttp.NewClient()
-	retryClient.RetryMax = 3
-	retryClient.Logger = nil // Disable logging
-
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
 	return &Client{
-		httpClient: retryClient.StandardClient(),
-		timeout:    cfg.Timeout,
+		httpClient:    httpclient.New(),
+		baseURL:       baseURL,
+		timeout:       cfg.Timeout,
+		maxReferences: cfg.MaxReferences,
 	}
 }
 
+// ScannerName labels results from the OSV database
+const ScannerName = "Google OSV"
+
 // Name returns the scanner name
 func (c *Client) Name() string {
-	return "Google OSV"
+	return ScannerName
 }
 
 // IsAvailable returns true (OSV API is always available, no auth required)
@@ -68,21 +84,37 @@ func (c *Client) Scan(ctx context.Context, packages []manifest.Package) (*types.
 	}
 
 	for i, pkg := range packages {
+		ecosystem, err := Ecosystem(pkg.Ecosystem)
+		if err != nil {
+			return nil, fmt.Errorf("%s@%s: %w", pkg.Name, pkg.Version, err)
+		}
 		req.Queries[i] = query{
 			Package: packageInfo{
 				Name:      pkg.Name,
-				Ecosystem: "npm",
+				Ecosystem: ecosystem,
 			},
 			Version: pkg.Version,
 		}
 	}
 
-	// Execute request
-	resp, err := c.doBatchQuery(ctx, req)
-	if err != nil {
-		return nil, err
+	// The batch endpoint takes at most maxBatchSize queries per request
+	resp := &batchResponse{}
+	for start := 0; start < len(req.Queries); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(req.Queries))
+		chunk, err := c.doBatchQuery(ctx, batchRequest{Queries: req.Queries[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		// Keep results aligned with packages if a response comes up short
+		results := make([]queryResult, end-start)
+		copy(results, chunk.Results)
+		resp.Results = append(resp.Results, results...)
+		types.ReportProgress(ctx, end, len(req.Queries))
 	}
 
+	// The batch endpoint returns little more than IDs
+	c.fillDetails(ctx, resp)
+
 	// Convert to findings
 	findings := c.convertToFindings(packages, resp)
 
@@ -103,7 +135,7 @@ func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) (*batchResp
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", batchURL, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/querybatch", bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -118,7 +150,7 @@ func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) (*batchResp
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OSV API returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("OSV API returned status %d: %s", resp.StatusCode, redact.String(string(respBody)))
 	}
 
 	var batchResp batchResponse
@@ -129,6 +161,82 @@ func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) (*batchResp
 	return &batchResp, nil
 }
 
+// fillDetails replaces ID-only vulnerabilities in resp with their full
+// records. Each ID is fetched once per scan however many packages share
+// it. Lookups that fail leave the vulnerability as it was.
+func (c *Client) fillDetails(ctx context.Context, resp *batchResponse) {
+	details := make(map[string]*vulnerability)
+	for _, result := range resp.Results {
+		for _, vuln := range result.Vulns {
+			if vuln.Summary == "" && vuln.Details == "" {
+				details[vuln.ID] = nil
+			}
+		}
+	}
+	if len(details) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, detailWorkers)
+	)
+	for id := range details {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			vuln, err := c.getVulnerability(ctx, id)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			details[id] = vuln
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range resp.Results {
+		for i, vuln := range result.Vulns {
+			if detail := details[vuln.ID]; detail != nil {
+				result.Vulns[i] = *detail
+			}
+		}
+	}
+}
+
+// getVulnerability fetches the full record for one vulnerability ID
+func (c *Client) getVulnerability(ctx context.Context, id string) (*vulnerability, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/vulns/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSV API returned status %d for %s", resp.StatusCode, id)
+	}
+
+	var vuln vulnerability
+	if err := json.NewDecoder(resp.Body).Decode(&vuln); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &vuln, nil
+}
+
 func (c *Client) convertToFindings(packages []manifest.Package, resp *batchResponse) []types.Finding {
 	var findings []types.Finding
 
@@ -140,16 +248,24 @@ func (c *Client) convertToFindings(packages []manifest.Package, resp *batchRespo
 
 		for _, vuln := range result.Vulns {
 			severity := c.mapSeverity(vuln)
+			title := vuln.Summary
+			if title == "" {
+				title = vuln.ID
+			}
 			finding := types.Finding{
 				Package:     pkg.Name,
 				Version:     pkg.Version,
 				Type:        types.FindingTypeCVE,
 				Severity:    severity,
-				Title:       vuln.Summary,
+				Title:       title,
 				Description: truncate(vuln.Details, 500),
 				ID:          vuln.ID,
+				Aliases:     vuln.Aliases,
 				References:  c.extractReferences(vuln.References),
 			}
+			fixed, known := fixedVersion(vuln, pkg.Name, pkg.Version)
+			finding.FixedIn = fixed
+			finding.Remediation = remediation(fixed, known)
 			findings = append(findings, finding)
 		}
 	}
@@ -158,18 +274,19 @@ func (c *Client) convertToFindings(packages []manifest.Package, resp *batchRespo
 }
 
 func (c *Client) mapSeverity(vuln vulnerability) types.Severity {
-	// Check CVSS scores first
+	// Prefer a CVSS v3 score, then v2, whatever order OSV lists them in
 	for _, sev := range vuln.Severity {
 		if sev.Type == "CVSS_V3" {
-			score := parseCVSSScore(sev.Score)
-			if score >= 9.0 {
-				return types.SeverityCritical
-			} else if score >= 7.0 {
-				return types.SeverityHigh
-			} else if score >= 4.0 {
-				return types.SeverityMedium
+			if score, ok := cvss3BaseScore(sev.Score); ok {
+				return cvss3Severity(score)
+			}
+		}
+	}
+	for _, sev := range vuln.Severity {
+		if sev.Type == "CVSS_V2" {
+			if score, ok := cvss2BaseScore(sev.Score); ok {
+				return cvss2Severity(score)
 			}
-			return types.SeverityLow
 		}
 	}
 
@@ -200,63 +317,40 @@ func (c *Client) mapSeverity(vuln vulnerability) types.Severity {
 	return types.SeverityMedium
 }
 
-func (c *Client) extractReferences(refs []reference) []string {
-	var urls []string
-	for _, ref := range refs {
-		if ref.URL != "" {
-			urls = append(urls, ref.URL)
-		}
-	}
-	return urls
+// referencePriority ranks OSV reference types; lower is kept first
+var referencePriority = map[string]int{
+	"ADVISORY": 0,
+	"FIX":      1,
 }
 
-func parseCVSSScore(vector string) float64 {
-	// Simple extraction of base score from CVSS vector
-	// Format: CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H
-	// We'd need to calculate, but for simplicity, let's estimate from vector
-	// A proper implementation would parse and calculate
-
-	// Count high-impact indicators
-	highCount := 0
-	if contains(vector, "/C:H") {
-		highCount++
-	}
-	if contains(vector, "/I:H") {
-		highCount++
-	}
-	if contains(vector, "/A:H") {
-		highCount++
-	}
-	if contains(vector, "/AV:N") {
-		highCount++
-	}
-	if contains(vector, "/PR:N") {
-		highCount++
-	}
-
-	switch {
-	case highCount >= 4:
-		return 9.0
-	case highCount >= 3:
-		return 7.5
-	case highCount >= 2:
-		return 5.0
-	default:
-		return 3.0
+// extractReferences returns reference URLs, advisories and fixes first,
+// capped at maxReferences. Some advisories list hundreds of links.
+func (c *Client) extractReferences(refs []reference) []string {
+	rank := func(ref reference) int {
+		if p, ok := referencePriority[ref.Type]; ok {
+			return p
+		}
+		return len(referencePriority)
 	}
-}
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
-}
+	sorted := slices.Clone(refs)
+	slices.SortStableFunc(sorted, func(a, b reference) int {
+		return rank(a) - rank(b)
+	})
 
-func containsMiddle(s, substr string) bool {
-	for i := 1; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+	var urls []string
+	seen := make(map[string]bool)
+	for _, ref := range sorted {
+		if c.maxReferences > 0 && len(urls) >= c.maxReferences {
+			break
 		}
+		if ref.URL == "" || seen[ref.URL] {
+			continue
+		}
+		seen[ref.URL] = true
+		urls = append(urls, ref.URL)
 	}
-	return false
+	return urls
 }
 
 func truncate(s string, maxLen int) string {
@@ -292,6 +386,7 @@ type queryResult struct {
 
 type vulnerability struct {
 	ID         string      `json:"id"`
+	Aliases    []string    `json:"aliases,omitempty"`
 	Summary    string      `json:"summary"`
 	Details    string      `json:"details"`
 	Severity   []severity  `json:"severity,omitempty"`
@@ -0,0 +1,195 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestExtractReferences(t *testing.T) {
+	var refs []reference
+	for i := 0; i < 50; i++ {
+		refs = append(refs, reference{Type: "WEB", URL: fmt.Sprintf("https://example.com/%d", i)})
+	}
+	refs = append(refs,
+		reference{Type: "FIX", URL: "https://github.com/o/r/commit/abc"},
+		reference{Type: "ADVISORY", URL: "https://github.com/advisories/GHSA-1"},
+		reference{Type: "ADVISORY", URL: "https://github.com/advisories/GHSA-1"},
+	)
+
+	tests := []struct {
+		name string
+		max  int
+		want []string
+	}{
+		{
+			name: "capped prefers advisory and fix",
+			max:  3,
+			want: []string{
+				"https://github.com/advisories/GHSA-1",
+				"https://github.com/o/r/commit/abc",
+				"https://example.com/0",
+			},
+		},
+		{
+			name: "zero keeps all unique",
+			max:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{maxReferences: tt.max}
+			got := c.extractReferences(refs)
+			if tt.want == nil {
+				if len(got) != 52 {
+					t.Errorf("got %d references, want 52", len(got))
+				}
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("extractReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanFetchesDetails(t *testing.T) {
+	var fetches sync.Map
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /querybatch", func(w http.ResponseWriter, r *http.Request) {
+		// Two packages share GHSA-1; GHSA-2 already has details
+		fmt.Fprint(w, `{"results": [
+			{"vulns": [{"id": "GHSA-1"}, {"id": "GHSA-missing"}]},
+			{"vulns": [{"id": "GHSA-1"}, {"id": "GHSA-2", "summary": "Inline summary"}]},
+			{}
+		]}`)
+	})
+	mux.HandleFunc("GET /vulns/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		n, _ := fetches.LoadOrStore(id, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+
+		if id != "GHSA-1" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{
+			"id": "GHSA-1",
+			"aliases": ["CVE-2021-0001"],
+			"summary": "Prototype pollution",
+			"details": "Long description",
+			"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+			"references": [{"type": "ADVISORY", "url": "https://github.com/advisories/GHSA-1"}]
+		}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(config.OSVConfig{Timeout: 5 * time.Second, MaxReferences: 10})
+	c.baseURL = server.URL
+
+	packages := []manifest.Package{
+		{Name: "a", Version: "1.0.0", Ecosystem: "npm"},
+		{Name: "b", Version: "2.0.0", Ecosystem: "npm"},
+		{Name: "c", Version: "3.0.0", Ecosystem: "npm"},
+	}
+	result, err := c.Scan(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(result.Findings) != 4 {
+		t.Fatalf("got %d findings, want 4", len(result.Findings))
+	}
+
+	byKey := make(map[string]types.Finding)
+	for _, f := range result.Findings {
+		byKey[f.Package+"/"+f.ID] = f
+	}
+	for _, key := range []string{"a/GHSA-1", "b/GHSA-1"} {
+		f := byKey[key]
+		if f.Title != "Prototype pollution" || f.Severity != types.SeverityCritical || len(f.References) != 1 {
+			t.Errorf("%s = %+v, want filled-in details", key, f)
+		}
+		if !slices.Equal(f.Aliases, []string{"CVE-2021-0001"}) || f.DisplayID() != "CVE-2021-0001" {
+			t.Errorf("%s aliases = %v, want the CVE", key, f.Aliases)
+		}
+	}
+	if got := byKey["a/GHSA-missing"].Title; got != "GHSA-missing" {
+		t.Errorf("failed lookup title = %q, want the ID", got)
+	}
+	if got := byKey["b/GHSA-2"].Title; got != "Inline summary" {
+		t.Errorf("inline title = %q", got)
+	}
+
+	if n, _ := fetches.Load("GHSA-1"); n.(*atomic.Int32).Load() != 1 {
+		t.Errorf("GHSA-1 fetched %d times, want 1", n.(*atomic.Int32).Load())
+	}
+	if _, ok := fetches.Load("GHSA-2"); ok {
+		t.Error("GHSA-2 fetched although the batch response had details")
+	}
+}
+
+func TestScanReportsProgressPerBatch(t *testing.T) {
+	var sizes []int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /querybatch", func(w http.ResponseWriter, r *http.Request) {
+		var req batchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sizes = append(sizes, len(req.Queries))
+
+		// Only the last package of the scan is vulnerable
+		resp := batchResponse{Results: make([]queryResult, len(req.Queries))}
+		for i, q := range req.Queries {
+			if q.Package.Name == "pkg-2499" {
+				resp.Results[i].Vulns = []vulnerability{{ID: "GHSA-1", Summary: "Bad"}}
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(config.OSVConfig{Timeout: 5 * time.Second})
+	c.baseURL = server.URL
+
+	packages := make([]manifest.Package, 2500)
+	for i := range packages {
+		packages[i] = manifest.Package{Name: fmt.Sprintf("pkg-%d", i), Version: "1.0.0", Ecosystem: "npm"}
+	}
+
+	var progress [][2]int
+	ctx := types.WithProgress(context.Background(), func(completed, total int) {
+		progress = append(progress, [2]int{completed, total})
+	})
+	result, err := c.Scan(ctx, packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if want := []int{1000, 1000, 500}; !slices.Equal(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+	if want := [][2]int{{1000, 2500}, {2000, 2500}, {2500, 2500}}; !slices.Equal(progress, want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Package != "pkg-2499" {
+		t.Errorf("findings = %+v, want one for pkg-2499", result.Findings)
+	}
+}
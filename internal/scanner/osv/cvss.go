@@ -0,0 +1,174 @@
+package osv
+
+import (
+	"math"
+	"strings"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// CVSS v3 metric weights from the v3.1 specification, section 7.4
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3PrivilegesRequired depends on whether the scope changes
+var cvss3PrivilegesRequired = map[bool]map[string]float64{
+	false: {"N": 0.85, "L": 0.62, "H": 0.27},
+	true:  {"N": 0.85, "L": 0.68, "H": 0.5},
+}
+
+// CVSS v2 metric weights from the v2 guide, section 3.2.1
+var cvss2Weights = map[string]map[string]float64{
+	"AV": {"L": 0.395, "A": 0.646, "N": 1.0},
+	"AC": {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au": {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":  {"N": 0, "P": 0.275, "C": 0.660},
+	"I":  {"N": 0, "P": 0.275, "C": 0.660},
+	"A":  {"N": 0, "P": 0.275, "C": 0.660},
+}
+
+// parseVector splits "PREFIX/K:V/K:V" into metrics. Unknown metrics are
+// kept so callers can ignore temporal and environmental ones.
+func parseVector(vector string) map[string]string {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		metrics[key] = value
+	}
+	return metrics
+}
+
+// cvss3BaseScore computes the base score of a CVSS v3.0 or v3.1 vector
+// such as "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". ok is false when
+// the vector is not v3 or a base metric is missing or invalid.
+func cvss3BaseScore(vector string) (score float64, ok bool) {
+	var roundup func(float64) float64
+	switch {
+	case strings.HasPrefix(vector, "CVSS:3.1/"):
+		roundup = roundup31
+	case strings.HasPrefix(vector, "CVSS:3.0/"):
+		roundup = roundup30
+	default:
+		return 0, false
+	}
+
+	metrics := parseVector(vector)
+
+	var scopeChanged bool
+	switch metrics["S"] {
+	case "U":
+	case "C":
+		scopeChanged = true
+	default:
+		return 0, false
+	}
+
+	w := make(map[string]float64, len(cvss3Weights)+1)
+	for metric, values := range cvss3Weights {
+		v, found := values[metrics[metric]]
+		if !found {
+			return 0, false
+		}
+		w[metric] = v
+	}
+	pr, found := cvss3PrivilegesRequired[scopeChanged][metrics["PR"]]
+	if !found {
+		return 0, false
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * pr * w["UI"]
+
+	if impact <= 0 {
+		return 0, true
+	}
+	if scopeChanged {
+		return roundup(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundup(math.Min(impact+exploitability, 10)), true
+}
+
+// roundup30 rounds up to one decimal as defined by CVSS v3.0
+func roundup30(x float64) float64 {
+	return math.Ceil(x*10) / 10
+}
+
+// roundup31 rounds up to one decimal as defined by CVSS v3.1 (Appendix A),
+// which avoids floating point artifacts such as 4.000001 becoming 4.1
+func roundup31(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// cvss2BaseScore computes the base score of a CVSS v2 vector such as
+// "AV:N/AC:L/Au:N/C:P/I:P/A:P". The "(...)" wrapping used by NVD and a
+// "CVSS:2.0/" prefix are accepted.
+func cvss2BaseScore(vector string) (score float64, ok bool) {
+	vector = strings.TrimSuffix(strings.TrimPrefix(vector, "("), ")")
+	vector = strings.TrimPrefix(vector, "CVSS:2.0/")
+	metrics := parseVector(vector)
+
+	w := make(map[string]float64, len(cvss2Weights))
+	for metric, values := range cvss2Weights {
+		v, found := values[metrics[metric]]
+		if !found {
+			return 0, false
+		}
+		w[metric] = v
+	}
+
+	impact := 10.41 * (1 - (1-w["C"])*(1-w["I"])*(1-w["A"]))
+	exploitability := 20 * w["AV"] * w["AC"] * w["Au"]
+	if impact == 0 {
+		return 0, true
+	}
+
+	score = (0.6*impact + 0.4*exploitability - 1.5) * 1.176
+	return math.Round(score*10) / 10, true
+}
+
+// cvss3Severity maps a v3 base score to its qualitative rating. A score of
+// 0.0 ("None") is reported as low rather than dropped.
+func cvss3Severity(score float64) types.Severity {
+	switch {
+	case score >= 9.0:
+		return types.SeverityCritical
+	case score >= 7.0:
+		return types.SeverityHigh
+	case score >= 4.0:
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
+
+// cvss2Severity maps a v2 base score to the NVD v2 ratings, which have no
+// critical band
+func cvss2Severity(score float64) types.Severity {
+	switch {
+	case score >= 7.0:
+		return types.SeverityHigh
+	case score >= 4.0:
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
@@ -0,0 +1,121 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+		ok     bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", 9.9, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", 7.5, true},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", 7.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:N/A:N", 3.7, true},
+		{"CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.6, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:U/C:H/I:N/A:N", 5.3, true},
+		// Temporal metrics do not affect the base score
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U/RL:O", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", 0, false},
+		{"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0, false},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vector, func(t *testing.T) {
+			got, ok := cvss3BaseScore(tt.vector)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("cvss3BaseScore() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCVSS2BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+		ok     bool
+	}{
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 7.5, true},
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", 10.0, true},
+		{"AV:N/AC:M/Au:N/C:N/I:P/A:N", 4.3, true},
+		{"AV:L/AC:L/Au:N/C:P/I:N/A:N", 2.1, true},
+		{"(AV:N/AC:L/Au:N/C:P/I:P/A:P)", 7.5, true},
+		{"AV:N/AC:L/Au:N/C:N/I:N/A:N", 0, true},
+		{"AV:N/AC:L/Au:N/C:P/I:P", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vector, func(t *testing.T) {
+			got, ok := cvss2BaseScore(tt.vector)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("cvss2BaseScore() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMapSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		severity []severity
+		want     types.Severity
+	}{
+		{
+			name:     "v3 critical",
+			severity: []severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+			want:     types.SeverityCritical,
+		},
+		{
+			// The old heuristic rated this medium
+			name:     "v3 high with only availability impact",
+			severity: []severity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}},
+			want:     types.SeverityHigh,
+		},
+		{
+			name: "v3 preferred over earlier v2",
+			severity: []severity{
+				{Type: "CVSS_V2", Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+				{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:N/A:N"},
+			},
+			want: types.SeverityLow,
+		},
+		{
+			name:     "v2 only",
+			severity: []severity{{Type: "CVSS_V2", Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"}},
+			want:     types.SeverityHigh,
+		},
+		{
+			name: "invalid v3 falls back to v2",
+			severity: []severity{
+				{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N"},
+				{Type: "CVSS_V2", Score: "AV:L/AC:L/Au:N/C:P/I:N/A:N"},
+			},
+			want: types.SeverityLow,
+		},
+		{
+			name:     "no score",
+			severity: nil,
+			want:     types.SeverityMedium,
+		},
+	}
+
+	c := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.mapSeverity(vulnerability{ID: "OSV-1", Severity: tt.severity}); got != tt.want {
+				t.Errorf("mapSeverity() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package osv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ecosystems maps package ecosystems, as package URL types or OSV names in
+// lower case, to the ecosystem names the OSV API expects
+var ecosystems = map[string]string{
+	"npm":          "npm",
+	"pypi":         "PyPI",
+	"golang":       "Go",
+	"go":           "Go",
+	"cargo":        "crates.io",
+	"crates.io":    "crates.io",
+	"maven":        "Maven",
+	"nuget":        "NuGet",
+	"gem":          "RubyGems",
+	"rubygems":     "RubyGems",
+	"composer":     "Packagist",
+	"packagist":    "Packagist",
+	"pub":          "Pub",
+	"hex":          "Hex",
+	"hackage":      "Hackage",
+	"cran":         "CRAN",
+	"swift":        "SwiftURL",
+	"swifturl":     "SwiftURL",
+	"conan":        "ConanCenter",
+	"conancenter":  "ConanCenter",
+	"bioconductor": "Bioconductor",
+}
+
+// Ecosystem returns the OSV ecosystem name for a package's ecosystem.
+// Packages without an ecosystem are treated as npm.
+func Ecosystem(name string) (string, error) {
+	if name == "" {
+		return "npm", nil
+	}
+	if eco, ok := ecosystems[strings.ToLower(name)]; ok {
+		return eco, nil
+	}
+	return "", fmt.Errorf("ecosystem %q is not supported by OSV", name)
+}
+
+// SupportsEcosystem returns true if OSV has advisories for the ecosystem
+func (c *Client) SupportsEcosystem(ecosystem string) bool {
+	_, err := Ecosystem(ecosystem)
+	return err == nil
+}
@@ -0,0 +1,85 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+)
+
+func TestEcosystem(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "npm", false},
+		{"npm", "npm", false},
+		{"pypi", "PyPI", false},
+		{"PyPI", "PyPI", false},
+		{"golang", "Go", false},
+		{"cargo", "crates.io", false},
+		{"crates.io", "crates.io", false},
+		{"maven", "Maven", false},
+		{"nuget", "NuGet", false},
+		{"gem", "RubyGems", false},
+		{"composer", "Packagist", false},
+		{"pub", "Pub", false},
+		{"hex", "Hex", false},
+		{"cran", "CRAN", false},
+		{"cobol", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Ecosystem(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Ecosystem(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Ecosystem(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScanQueriesPackageEcosystem(t *testing.T) {
+	var got []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /querybatch", func(w http.ResponseWriter, r *http.Request) {
+		var req batchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, q := range req.Queries {
+			got = append(got, q.Package.Ecosystem)
+		}
+		fmt.Fprint(w, `{"results": [{}, {}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(config.OSVConfig{Timeout: 5 * time.Second})
+	c.baseURL = server.URL
+
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "requests", Version: "2.31.0", Ecosystem: "pypi"},
+	}
+	if _, err := c.Scan(context.Background(), packages); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if fmt.Sprint(got) != "[npm PyPI]" {
+		t.Errorf("queried ecosystems = %v, want [npm PyPI]", got)
+	}
+
+	if _, err := c.Scan(context.Background(), []manifest.Package{{Name: "x", Version: "1", Ecosystem: "cobol"}}); err == nil {
+		t.Error("expected error for unsupported ecosystem")
+	}
+}
@@ -0,0 +1,55 @@
+package osv
+
+import (
+	"github.com/Masterminds/semver/v3"
+)
+
+// fixedVersion returns the lowest version above the scanned one that OSV
+// lists as fixing the vulnerability. known is false when the record has
+// no affected ranges for the package, or the version is not semver, so
+// nothing can be said either way.
+func fixedVersion(vuln vulnerability, name, version string) (fixed string, known bool) {
+	current, err := semver.NewVersion(version)
+	if err != nil {
+		return "", false
+	}
+
+	var best *semver.Version
+	for _, a := range vuln.Affected {
+		if a.Package.Name != "" && a.Package.Name != name {
+			continue
+		}
+		for _, r := range a.Ranges {
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				continue
+			}
+			known = true
+			for _, e := range r.Events {
+				if e.Fixed == "" {
+					continue
+				}
+				v, err := semver.NewVersion(e.Fixed)
+				if err != nil || !v.GreaterThan(current) {
+					continue
+				}
+				if best == nil || v.LessThan(best) {
+					best, fixed = v, e.Fixed
+				}
+			}
+		}
+	}
+	return fixed, known
+}
+
+// remediation describes how to clear a vulnerability, or "" when OSV does
+// not say
+func remediation(fixed string, known bool) string {
+	switch {
+	case fixed != "":
+		return "Upgrade to " + fixed + " or later"
+	case known:
+		return "No fixed version is available"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,40 @@
+package osv
+
+import "testing"
+
+func TestFixedVersion(t *testing.T) {
+	vuln := vulnerability{Affected: []affected{{
+		Package: packageInfo{Name: "lodash", Ecosystem: "npm"},
+		Ranges: []rangeInfo{
+			{Type: "SEMVER", Events: []event{{Introduced: "0"}, {Fixed: "4.17.21"}}},
+			{Type: "SEMVER", Events: []event{{Introduced: "5.0.0"}, {Fixed: "5.0.3"}, {Introduced: "6.0.0"}}},
+			{Type: "GIT", Events: []event{{Introduced: "0"}, {Fixed: "abc123"}}},
+		},
+	}}}
+
+	tests := []struct {
+		version     string
+		fixed       string
+		known       bool
+		remediation string
+	}{
+		{"4.17.20", "4.17.21", true, "Upgrade to 4.17.21 or later"},
+		{"5.0.1", "5.0.3", true, "Upgrade to 5.0.3 or later"},
+		{"6.1.0", "", true, "No fixed version is available"},
+		{"not-semver", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		fixed, known := fixedVersion(vuln, "lodash", tt.version)
+		if fixed != tt.fixed || known != tt.known {
+			t.Errorf("fixedVersion(%s) = %q, %v, want %q, %v", tt.version, fixed, known, tt.fixed, tt.known)
+		}
+		if got := remediation(fixed, known); got != tt.remediation {
+			t.Errorf("remediation(%s) = %q, want %q", tt.version, got, tt.remediation)
+		}
+	}
+
+	if _, known := fixedVersion(vuln, "underscore", "1.0.0"); known {
+		t.Error("fixedVersion() knows ranges for a package the record does not list")
+	}
+}
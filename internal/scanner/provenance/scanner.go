@@ -0,0 +1,157 @@
+// Package provenance checks whether package versions were published with
+// an npm provenance attestation, and flags versions that dropped it.
+package provenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// ScannerName labels versions published without an attestation
+const ScannerName = "Provenance"
+
+// Scanner looks up provenance attestations in the npm registry
+type Scanner struct {
+	metadata *registry.Client
+	timeout  time.Duration
+}
+
+// NewScanner creates a provenance scanner that reads package documents
+// through metadata
+func NewScanner(cfg config.ProvenanceConfig, metadata *registry.Client) *Scanner {
+	return &Scanner{
+		metadata: metadata,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return ScannerName
+}
+
+// IsAvailable returns true; attestations are listed in the public package
+// document
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// SupportsEcosystem returns true for npm, the only registry that publishes
+// provenance attestations
+func (s *Scanner) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// attested returns the versions of a package published with an
+// attestation
+func attested(doc *registry.Packument) map[string]bool {
+	versions := make(map[string]bool)
+	for v, meta := range doc.Versions {
+		if meta.Dist.Attestations != nil {
+			versions[v] = true
+		}
+	}
+	return versions
+}
+
+// Scan reports each package version without a provenance attestation: as
+// informational, or as high severity if an earlier version had one.
+// Versions unknown to the registry are skipped.
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	docs, errs := registry.FetchAll(ctx, packages, func(ctx context.Context, name string, _ []string) (*registry.Packument, error) {
+		return s.fetch(ctx, name)
+	})
+
+	findings := []types.Finding{}
+	for _, pkg := range packages {
+		if err := errs[pkg.Name]; err != nil {
+			return nil, err
+		}
+		if f := check(pkg, docs[pkg.Name]); f != nil {
+			findings = append(findings, *f)
+		}
+	}
+
+	return &types.ScanResult{
+		Scanner:      s.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// check returns the finding for one package version, or nil if it has an
+// attestation or the registry does not list it
+func check(pkg manifest.Package, doc *registry.Packument) *types.Finding {
+	if doc == nil {
+		return nil
+	}
+	if _, ok := doc.Versions[pkg.Version]; !ok {
+		return nil
+	}
+	attested := attested(doc)
+	if attested[pkg.Version] {
+		return nil
+	}
+
+	finding := &types.Finding{
+		Package:     pkg.Name,
+		Version:     pkg.Version,
+		Type:        types.FindingTypeProvenance,
+		Severity:    types.SeverityInfo,
+		Title:       "No provenance attestation",
+		Description: "This version was published without an npm provenance attestation linking it to its source and build",
+		References:  []string{"https://docs.npmjs.com/generating-provenance-statements"},
+	}
+
+	if previous := lastAttestedBefore(pkg.Version, attested); previous != "" {
+		finding.Severity = types.SeverityHigh
+		finding.Title = "Provenance dropped since " + previous
+		finding.Description = fmt.Sprintf("%s was published with a provenance attestation but %s was not; the publishing workflow or credentials may have changed", previous, pkg.Version)
+		finding.Remediation = "Stay on " + previous + " until the maintainers confirm the release"
+	}
+	return finding
+}
+
+// lastAttestedBefore returns the highest attested version lower than
+// version, or "" if there is none
+func lastAttestedBefore(version string, attested map[string]bool) string {
+	current, err := semver.NewVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	var best *semver.Version
+	var bestRaw string
+	for raw := range attested {
+		v, err := semver.NewVersion(raw)
+		if err != nil || !v.LessThan(current) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best, bestRaw = v, raw
+		}
+	}
+	return bestRaw
+}
+
+// fetch returns the abbreviated package document, which carries each
+// version's dist.attestations, or nil if the registry does not know the
+// package
+func (s *Scanner) fetch(ctx context.Context, name string) (*registry.Packument, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	return s.metadata.Packument(ctx, name)
+}
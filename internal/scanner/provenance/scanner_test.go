@@ -0,0 +1,61 @@
+package provenance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestScan(t *testing.T) {
+	const attested = `{"dist": {"attestations": {"url": "https://registry.npmjs.org/-/npm/v1/attestations/x", "provenance": {"predicateType": "https://slsa.dev/provenance/v1"}}}}`
+	docs := map[string]string{
+		"/signed": `{"versions": {"1.0.0": ` + attested + `, "1.1.0": ` + attested + `, "1.2.0": {"dist": {}}, "2.0.0": ` + attested + `}}`,
+		"/plain":  `{"versions": {"1.0.0": {"dist": {}}}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	s := NewScanner(config.ProvenanceConfig{}, registry.NewClient(registry.Registries{Default: server.URL}))
+
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "signed", Version: "1.1.0"},
+		{Name: "signed", Version: "1.2.0"},
+		{Name: "plain", Version: "1.0.0"},
+		{Name: "plain", Version: "9.9.9"},
+		{Name: "missing", Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(result.Findings) != 2 {
+		t.Fatalf("Scan() findings = %+v, want 2", result.Findings)
+	}
+
+	dropped := result.Findings[0]
+	if dropped.Package != "signed" || dropped.Version != "1.2.0" || dropped.Severity != types.SeverityHigh {
+		t.Errorf("dropped finding = %+v", dropped)
+	}
+	if dropped.Title != "Provenance dropped since 1.1.0" {
+		t.Errorf("Title = %q", dropped.Title)
+	}
+
+	plain := result.Findings[1]
+	if plain.Package != "plain" || plain.Severity != types.SeverityInfo || plain.Type != types.FindingTypeProvenance {
+		t.Errorf("unattested finding = %+v", plain)
+	}
+}
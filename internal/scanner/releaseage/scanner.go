@@ -0,0 +1,141 @@
+// Package releaseage quarantines package versions that were published too
+// recently, since most malicious releases are caught within days.
+package releaseage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+const timeout = 30 * time.Second
+
+// ScannerName labels versions still in the release age quarantine
+const ScannerName = "Release age"
+
+// Scanner looks up publish times in the npm registry
+type Scanner struct {
+	metadata *registry.Client
+	minAge   time.Duration
+	now      func() time.Time
+}
+
+// NewScanner creates a scanner that reports versions younger than minAge,
+// reading publish times through metadata
+func NewScanner(minAge time.Duration, metadata *registry.Client) *Scanner {
+	return &Scanner{
+		metadata: metadata,
+		minAge:   minAge,
+		now:      time.Now,
+	}
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return ScannerName
+}
+
+// IsAvailable returns true; publish times are public
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// SupportsEcosystem returns true for npm, whose registry records each
+// version's publish time
+func (s *Scanner) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// Selects returns true for packages about to be installed; versions
+// already in the project were installed before the policy applied
+func (s *Scanner) Selects(pkg manifest.Package) bool {
+	return pkg.New
+}
+
+// Volatile returns true; a quarantine lifts as time passes
+func (s *Scanner) Volatile() bool {
+	return true
+}
+
+// Scan reports each package version published less than the minimum age
+// ago. Versions unknown to the registry are skipped.
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	times, errs := registry.FetchAll(ctx, packages, func(ctx context.Context, name string, _ []string) (map[string]string, error) {
+		return s.fetch(ctx, name)
+	})
+
+	findings := []types.Finding{}
+	for _, pkg := range packages {
+		if err := errs[pkg.Name]; err != nil {
+			return nil, err
+		}
+		published, err := time.Parse(time.RFC3339, times[pkg.Name][pkg.Version])
+		if err != nil {
+			continue
+		}
+		if f := s.check(pkg, published); f != nil {
+			findings = append(findings, *f)
+		}
+	}
+
+	return &types.ScanResult{
+		Scanner:      s.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// check returns a finding if the version is still in quarantine
+func (s *Scanner) check(pkg manifest.Package, published time.Time) *types.Finding {
+	lifts := published.Add(s.minAge)
+	remaining := lifts.Sub(s.now())
+	if remaining <= 0 {
+		return nil
+	}
+
+	return &types.Finding{
+		Package:  pkg.Name,
+		Version:  pkg.Version,
+		Type:     types.FindingTypeReleaseAge,
+		Severity: types.SeverityMedium,
+		Title:    "Published " + published.UTC().Format("2006-01-02 15:04 UTC") + ", quarantine lifts in " + describeDuration(remaining),
+		Description: fmt.Sprintf("Versions published less than %s ago are quarantined (scanning.policy.min_release_age); this one can be installed from %s",
+			describeDuration(s.minAge), lifts.UTC().Format("2006-01-02 15:04 UTC")),
+		Remediation: "Install an earlier version, wait for the quarantine to lift, or use --force",
+	}
+}
+
+// describeDuration renders a duration in days and hours
+func describeDuration(d time.Duration) string {
+	hours := int(d.Round(time.Hour).Hours())
+	switch {
+	case hours < 1:
+		return "less than an hour"
+	case hours < 48:
+		return fmt.Sprintf("%dh", hours)
+	case hours%24 == 0:
+		return fmt.Sprintf("%dd", hours/24)
+	default:
+		return fmt.Sprintf("%dd %dh", hours/24, hours%24)
+	}
+}
+
+// fetch returns the publish time of each version of a package, or nil if
+// the registry does not know the package
+func (s *Scanner) fetch(ctx context.Context, name string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	doc, err := s.metadata.FullPackument(ctx, name)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return doc.Time, nil
+}
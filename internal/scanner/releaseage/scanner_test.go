@@ -0,0 +1,71 @@
+package releaseage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestScan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lodash" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"time": {
+			"created": "2012-04-23T16:37:11.912Z",
+			"4.17.21": "2021-02-20T15:42:16.891Z",
+			"4.18.0": "2026-10-15T10:00:00.000Z"}}`))
+	}))
+	defer server.Close()
+
+	s := NewScanner(72*time.Hour, registry.NewClient(registry.Registries{Default: server.URL}))
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", New: true},
+		{Name: "lodash", Version: "4.18.0", New: true},
+		{Name: "missing", Version: "1.0.0", New: true},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(result.Findings) != 1 {
+		t.Fatalf("Scan() findings = %+v, want 1", result.Findings)
+	}
+	f := result.Findings[0]
+	if f.Version != "4.18.0" || f.Type != types.FindingTypeReleaseAge {
+		t.Errorf("finding = %+v", f)
+	}
+	if want := "Published 2026-10-15 10:00 UTC, quarantine lifts in 46h"; f.Title != want {
+		t.Errorf("Title = %q, want %q", f.Title, want)
+	}
+	if !strings.Contains(f.Description, "from 2026-10-18 10:00 UTC") {
+		t.Errorf("Description = %q", f.Description)
+	}
+}
+
+func TestDescribeDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{20 * time.Minute, "less than an hour"},
+		{5 * time.Hour, "5h"},
+		{72 * time.Hour, "3d"},
+		{50*time.Hour + 40*time.Minute, "2d 3h"},
+	}
+	for _, tt := range tests {
+		if got := describeDuration(tt.d); got != tt.want {
+			t.Errorf("describeDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
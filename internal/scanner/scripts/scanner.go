@@ -0,0 +1,156 @@
+// Package scripts flags packages whose published version runs lifecycle
+// scripts on install, using the npm registry's version metadata.
+package scripts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// ScannerName labels versions that run scripts on install
+const ScannerName = "Install scripts"
+
+// installScripts are the lifecycle scripts npm runs when installing a
+// package as a dependency, in the order it runs them
+var installScripts = []string{"preinstall", "install", "postinstall"}
+
+// Scanner looks up the lifecycle scripts of each package version
+type Scanner struct {
+	metadata *registry.Client
+	timeout  time.Duration
+}
+
+// NewScanner creates an install script scanner that reads version
+// documents through metadata
+func NewScanner(cfg config.InstallScriptsConfig, metadata *registry.Client) *Scanner {
+	return &Scanner{
+		metadata: metadata,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return ScannerName
+}
+
+// IsAvailable returns true; version documents are public
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// SupportsEcosystem returns true for npm, whose lifecycle scripts are
+// read from the registry
+func (s *Scanner) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// Selects returns true for packages about to be installed; scripts of
+// versions already in the project have run
+func (s *Scanner) Selects(pkg manifest.Package) bool {
+	return pkg.New
+}
+
+// Scan reports each package version that declares an install script.
+// Versions unknown to the registry are skipped, and versions whose
+// document cannot be fetched are listed as failed. It returns an error
+// only if no version could be checked.
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	docs, errs := registry.FetchAll(ctx, packages, s.fetch)
+
+	findings := []types.Finding{}
+	var failed []types.PackageError
+	var lastErr error
+	for _, pkg := range packages {
+		if err := errs[pkg.Name]; err != nil {
+			failed = append(failed, types.PackageError{Package: pkg.Name, Version: pkg.Version, Message: err.Error()})
+			lastErr = err
+			continue
+		}
+		if doc := docs[pkg.Name][pkg.Version]; doc != nil {
+			if f := scriptFinding(pkg, doc); f != nil {
+				findings = append(findings, *f)
+			}
+		}
+	}
+	if len(packages) > 0 && len(failed) == len(packages) {
+		return nil, lastErr
+	}
+
+	return &types.ScanResult{
+		Scanner:      s.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		Failed:       failed,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// scriptFinding builds the finding for a version document, or returns nil
+// if the version has no install scripts
+func scriptFinding(pkg manifest.Package, doc *registry.Version) *types.Finding {
+	var scripts map[string]string
+	var names, lines []string
+	for _, name := range installScripts {
+		if script, ok := doc.Scripts[name]; ok && strings.TrimSpace(script) != "" {
+			if scripts == nil {
+				scripts = make(map[string]string)
+			}
+			scripts[name] = script
+			names = append(names, name)
+			lines = append(lines, name+": "+script)
+		}
+	}
+
+	if len(names) == 0 {
+		if !doc.HasInstallScript {
+			return nil
+		}
+		// The registry sets hasInstallScript for implicit scripts too,
+		// such as node-gyp rebuild for packages with a binding.gyp
+		names = []string{"install"}
+		lines = []string{"install: (implicit, e.g. node-gyp rebuild)"}
+	}
+
+	return &types.Finding{
+		Package:     pkg.Name,
+		Version:     pkg.Version,
+		Type:        types.FindingTypeInstallScript,
+		Severity:    types.SeverityMedium,
+		Title:       fmt.Sprintf("Runs %s script on install", strings.Join(names, ", ")),
+		Description: strings.Join(lines, "; "),
+		Remediation: "Review the script before installing, or install with --ignore-scripts",
+		Scripts:     scripts,
+		References:  []string{"https://www.npmjs.com/package/" + pkg.Name + "/v/" + pkg.Version},
+	}
+}
+
+// fetch queries the registry for the documents of a package's versions,
+// leaving out versions the registry does not know
+func (s *Scanner) fetch(ctx context.Context, name string, versions []string) (map[string]*registry.Version, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	docs := make(map[string]*registry.Version, len(versions))
+	for _, version := range versions {
+		doc, err := s.metadata.Version(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			docs[version] = doc
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,115 @@
+package scripts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestScan(t *testing.T) {
+	docs := map[string]string{
+		"/esbuild/0.20.0":         `{"scripts": {"postinstall": "node install.js", "test": "jest"}}`,
+		"/lodash/4.17.21":         `{"scripts": {"test": "jest"}}`,
+		"/@mycorp%2Fnative/1.0.0": `{"hasInstallScript": true}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	s := NewScanner(config.InstallScriptsConfig{}, registry.NewClient(registry.Registries{Default: server.URL}))
+
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "esbuild", Version: "0.20.0"},
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "@mycorp/native", Version: "1.0.0"},
+		{Name: "unpublished", Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(result.Findings) != 2 {
+		t.Fatalf("Scan() findings = %+v, want 2", result.Findings)
+	}
+
+	esbuild := result.Findings[0]
+	if esbuild.Package != "esbuild" || esbuild.Type != types.FindingTypeInstallScript {
+		t.Errorf("finding = %+v", esbuild)
+	}
+	if esbuild.Title != "Runs postinstall script on install" || esbuild.Description != "postinstall: node install.js" {
+		t.Errorf("finding title/description = %q / %q", esbuild.Title, esbuild.Description)
+	}
+	if len(esbuild.Scripts) != 1 || esbuild.Scripts["postinstall"] != "node install.js" {
+		t.Errorf("Scripts = %v", esbuild.Scripts)
+	}
+
+	native := result.Findings[1]
+	if native.Package != "@mycorp/native" || native.Title != "Runs install script on install" {
+		t.Errorf("implicit install script finding = %+v", native)
+	}
+}
+
+func TestScanRegistryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	s := NewScanner(config.InstallScriptsConfig{}, registry.NewClient(registry.Registries{Default: server.URL}))
+
+	if _, err := s.Scan(context.Background(), []manifest.Package{{Name: "lodash", Version: "4.17.21"}}); err == nil {
+		t.Error("Scan() expected error for registry failure")
+	}
+}
+
+// TestScanPackageError lists a version the registry fails on as not
+// checked, and still checks the others
+func TestScanPackageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken/1.0.0" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"scripts": {"postinstall": "node install.js"}}`))
+	}))
+	defer server.Close()
+
+	s := NewScanner(config.InstallScriptsConfig{}, registry.NewClient(registry.Registries{Default: server.URL}))
+
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "broken", Version: "1.0.0"},
+		{Name: "esbuild", Version: "0.20.0"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Package != "esbuild" {
+		t.Errorf("Scan() findings = %+v, want esbuild", result.Findings)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Package != "broken" {
+		t.Errorf("Scan() failed = %+v, want broken", result.Failed)
+	}
+}
+
+func TestSelects(t *testing.T) {
+	s := NewScanner(config.InstallScriptsConfig{}, nil)
+	if s.Selects(manifest.Package{Name: "esbuild", Version: "0.20.0"}) {
+		t.Error("Selects() = true for a package already in the project")
+	}
+	if !s.Selects(manifest.Package{Name: "esbuild", Version: "0.20.0", New: true}) {
+		t.Error("Selects() = false for a package about to be installed")
+	}
+}
@@ -6,42 +6,60 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strings"
 	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/httpclient"
 	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/redact"
 	"github.com/positronico/snapem/internal/types"
 )
 
-const (
-	baseURL = "https://api.socket.dev/v0"
-)
+// defaultBaseURL is the Socket.dev API, used when scanning.socket.base_url
+// is empty
+const defaultBaseURL = "https://api.socket.dev/v0"
+
+// defaultBatchSize is the most packages sent in one request when
+// scanning.socket.batch_size is unset
+const defaultBatchSize = 1000
 
 // Client handles Socket.dev API interactions
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
 	apiToken   string
 	timeout    time.Duration
+	batchSize  int
 }
 
 // NewClient creates a new Socket.dev client
 func NewClient(cfg config.SocketConfig) *Client {
-	retryClient := retryablehttp.NewClient()
-	retryClient.RetryMax = 3
-	retryClient.Logger = nil // Disable logging
-
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
 	return &Client{
-		httpClient: retryClient.StandardClient(),
+		httpClient: httpclient.New(),
+		baseURL:    baseURL,
 		apiToken:   cfg.APIToken,
 		timeout:    cfg.Timeout,
+		batchSize:  batchSize,
 	}
 }
 
+// ScannerName labels results from the Socket.dev API
+const ScannerName = "Socket.dev"
+
 // Name returns the scanner name
 func (c *Client) Name() string {
-	return "Socket.dev"
+	return ScannerName
 }
 
 // IsAvailable returns true if API token is configured
@@ -71,35 +89,71 @@ func (c *Client) Scan(ctx context.Context, packages []manifest.Package) (*types.
 		}, nil
 	}
 
-	// Build batch request with PURLs
-	req := batchRequest{
-		Packages: make([]packageIdentifier, len(packages)),
-	}
-
-	for i, pkg := range packages {
-		req.Packages[i] = packageIdentifier{
-			PURL: pkg.PURL(),
+	// Large scans are split into batches the API accepts
+	var results []packageResult
+	for i := 0; i < len(packages); i += c.batchSize {
+		batch := packages[i:min(i+c.batchSize, len(packages))]
+		req := batchRequest{
+			Packages: make([]packageIdentifier, len(batch)),
+		}
+		for j, pkg := range batch {
+			req.Packages[j] = packageIdentifier{
+				PURL: pkg.PURL(),
+			}
 		}
-	}
 
-	// Execute request
-	resp, err := c.doBatchQuery(ctx, req)
-	if err != nil {
-		return nil, err
+		resp, err := c.doBatchQuery(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, resp...)
+		types.ReportProgress(ctx, i+len(batch), len(packages))
 	}
 
 	// Convert to findings
-	findings := c.convertToFindings(resp)
+	findings := c.convertToFindings(results)
 
 	return &types.ScanResult{
 		Scanner:      c.Name(),
 		Packages:     len(packages),
 		Findings:     findings,
+		Scores:       c.scores(results),
 		ScanDuration: time.Since(start),
 	}, nil
 }
 
-func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) (*batchResponse, error) {
+// CheckToken makes a cheap authenticated request, so an invalid token is
+// told apart from a network problem before a scan depends on it
+func (c *Client) CheckToken(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/quota", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to query Socket API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return fmt.Errorf("invalid Socket API token")
+	case http.StatusForbidden:
+		return fmt.Errorf("Socket API access denied - check your subscription")
+	default:
+		return fmt.Errorf("Socket API returned status %d", resp.StatusCode)
+	}
+}
+
+// doBatchQuery posts one batch of PURLs and returns the package results
+func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) ([]packageResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
@@ -108,7 +162,7 @@ func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) (*batchResp
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/purl", bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/purl", bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -134,23 +188,45 @@ func (c *Client) doBatchQuery(ctx context.Context, req batchRequest) (*batchResp
 		return nil, fmt.Errorf("Socket API rate limit exceeded")
 	default:
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Socket API returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("Socket API returned status %d: %s", resp.StatusCode, redact.String(string(respBody)))
 	}
 
-	var batchResp batchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
+	results, err := decodeResults(resp.Body)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return results, nil
+}
 
-	return &batchResp, nil
+// decodeResults reads a batch response: either one JSON object with a
+// results array, or newline-delimited JSON with one package per line, as
+// the API streams large responses
+func decodeResults(r io.Reader) ([]packageResult, error) {
+	var results []packageResult
+	dec := json.NewDecoder(r)
+	for {
+		var value batchResponse
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value.Results != nil {
+			results = append(results, value.Results...)
+		} else if value.packageResult.purl() != "" {
+			results = append(results, value.packageResult)
+		}
+	}
 }
 
-func (c *Client) convertToFindings(resp *batchResponse) []types.Finding {
+func (c *Client) convertToFindings(results []packageResult) []types.Finding {
 	var findings []types.Finding
 
-	for _, result := range resp.Results {
+	for _, result := range results {
 		// Parse package name and version from PURL
-		name, version := parsePURL(result.PURL)
+		name, version := parsePURL(result.purl())
 
 		for _, alert := range result.Alerts {
 			findingType := c.mapAlertType(alert.Type)
@@ -165,6 +241,12 @@ func (c *Client) convertToFindings(resp *batchResponse) []types.Finding {
 				Description: alert.Message,
 				ID:          alert.Key,
 			}
+			if findingType == types.FindingTypeLicense {
+				finding.License = alert.license()
+			}
+			if findingType == types.FindingTypeCVE {
+				finding.Aliases = alert.advisoryIDs()
+			}
 			findings = append(findings, finding)
 		}
 	}
@@ -172,6 +254,21 @@ func (c *Client) convertToFindings(resp *batchResponse) []types.Finding {
 	return findings
 }
 
+// scores returns the score of each package the API scored
+func (c *Client) scores(results []packageResult) []types.PackageScore {
+	var scores []types.PackageScore
+	for _, result := range results {
+		if result.Score == nil {
+			continue
+		}
+		name, version := parsePURL(result.purl())
+		score := result.Score.packageScore()
+		score.Package, score.Version = name, version
+		scores = append(scores, score)
+	}
+	return scores
+}
+
 func (c *Client) mapAlertType(alertType string) types.FindingType {
 	switch alertType {
 	case "malware", "potentialVulnerability", "protestware":
@@ -212,10 +309,12 @@ func parsePURL(purl string) (name, version string) {
 		return "", ""
 	}
 
-	// Remove "pkg:npm/" prefix
+	// Remove the "pkg:<type>/" prefix, e.g. pkg:npm/ or pkg:pypi/
 	rest := purl
-	if len(rest) > 8 && rest[:8] == "pkg:npm/" {
-		rest = rest[8:]
+	if strings.HasPrefix(rest, "pkg:") {
+		if _, after, ok := strings.Cut(rest[4:], "/"); ok {
+			rest = after
+		}
 	}
 
 	// Find @ separator
@@ -238,19 +337,108 @@ type packageIdentifier struct {
 	PURL string `json:"purl"`
 }
 
+// batchResponse is one JSON value of a response: the results envelope, or
+// a single package when the response is newline-delimited
 type batchResponse struct {
 	Results []packageResult `json:"results"`
+	packageResult
 }
 
 type packageResult struct {
-	PURL   string  `json:"purl"`
-	Score  float64 `json:"score"`
-	Alerts []alert `json:"alerts,omitempty"`
+	PURL      string  `json:"purl"`
+	Type      string  `json:"type"`
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Version   string  `json:"version"`
+	Score     *score  `json:"score,omitempty"`
+	Alerts    []alert `json:"alerts,omitempty"`
+}
+
+// purl returns the result's PURL, built from its parts if the API left it
+// out
+func (r packageResult) purl() string {
+	if r.PURL != "" || r.Name == "" {
+		return r.PURL
+	}
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	typ := r.Type
+	if typ == "" {
+		typ = "npm"
+	}
+	return "pkg:" + typ + "/" + name + "@" + r.Version
+}
+
+// score holds Socket's scores, from 0 to 1. Older responses give the
+// overall score as a bare number.
+type score struct {
+	Overall       float64 `json:"overall"`
+	SupplyChain   float64 `json:"supplyChain"`
+	Quality       float64 `json:"quality"`
+	Maintenance   float64 `json:"maintenance"`
+	Vulnerability float64 `json:"vulnerability"`
+	License       float64 `json:"license"`
+}
+
+// UnmarshalJSON accepts the scores object or a bare overall score
+func (s *score) UnmarshalJSON(data []byte) error {
+	var overall float64
+	if err := json.Unmarshal(data, &overall); err == nil {
+		*s = score{Overall: overall}
+		return nil
+	}
+	type plain score
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// packageScore converts the scores to 0-100
+func (s score) packageScore() types.PackageScore {
+	return types.PackageScore{
+		Overall:       percent(s.Overall),
+		SupplyChain:   percent(s.SupplyChain),
+		Quality:       percent(s.Quality),
+		Maintenance:   percent(s.Maintenance),
+		Vulnerability: percent(s.Vulnerability),
+		License:       percent(s.License),
+	}
+}
+
+// percent converts a 0-1 score to 0-100; values above 1 are taken to be
+// percentages already
+func percent(v float64) int {
+	if v > 1 {
+		return int(math.Round(min(v, 100)))
+	}
+	return int(math.Round(max(v, 0) * 100))
 }
 
 type alert struct {
-	Key      string `json:"key"`
-	Type     string `json:"type"`
-	Severity string `json:"severity"`
-	Message  string `json:"message"`
+	Key      string         `json:"key"`
+	Type     string         `json:"type"`
+	Severity string         `json:"severity"`
+	Message  string         `json:"message"`
+	Props    map[string]any `json:"props,omitempty"`
+}
+
+// advisoryIDs returns the CVE and GHSA IDs a vulnerability alert reports
+func (a alert) advisoryIDs() []string {
+	var ids []string
+	for _, key := range []string{"cveId", "ghsaId"} {
+		if id, ok := a.Props[key].(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// license returns the SPDX identifier a license alert reports, if any
+func (a alert) license() string {
+	for _, key := range []string{"licenseId", "license"} {
+		if id, ok := a.Props[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
 }
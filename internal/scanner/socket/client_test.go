@@ -0,0 +1,87 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// TestScanBatches splits packages into batches and reads both response
+// formats: the results envelope and newline-delimited packages
+func TestScanBatches(t *testing.T) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req batchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var purls []string
+		for _, p := range req.Packages {
+			purls = append(purls, p.PURL)
+		}
+		batches = append(batches, purls)
+
+		if len(batches) == 1 {
+			fmt.Fprint(w, `{"results": [
+				{"purl": "pkg:npm/left-pad@1.3.0", "score": 0.35, "alerts": [{"key": "k1", "type": "unmaintained", "severity": "low"}]},
+				{"purl": "pkg:npm/lodash@4.17.21", "score": {"overall": 0.92, "supplyChain": 1, "quality": 0.9, "maintenance": 0.8, "vulnerability": 1, "license": 1}}
+			]}`)
+			return
+		}
+		fmt.Fprintln(w, `{"type": "npm", "namespace": "@babel", "name": "core", "version": "7.24.0", "score": {"overall": 0.6, "supplyChain": 0.55}, "alerts": [{"key": "k2", "type": "malware", "severity": "critical"}]}`)
+		fmt.Fprintln(w, `{"type": "npm", "name": "chalk", "version": "5.3.0"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(config.SocketConfig{APIToken: "token", Timeout: 5 * time.Second, BaseURL: server.URL, BatchSize: 2})
+	packages := []manifest.Package{
+		{Name: "left-pad", Version: "1.3.0", Ecosystem: "npm"},
+		{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		{Name: "@babel/core", Version: "7.24.0", Ecosystem: "npm"},
+		{Name: "chalk", Version: "5.3.0", Ecosystem: "npm"},
+	}
+
+	var progress []string
+	ctx := types.WithProgress(context.Background(), func(completed, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", completed, total))
+	})
+	result, err := c.Scan(ctx, packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 2 {
+		t.Errorf("batches = %v, want two of two packages", batches)
+	}
+	if got := strings.Join(progress, " "); got != "2/4 4/4" {
+		t.Errorf("progress = %q", got)
+	}
+
+	if len(result.Findings) != 2 || result.Findings[1].Package != "@babel/core" || result.Findings[1].Type != types.FindingTypeMalware {
+		t.Errorf("findings = %+v", result.Findings)
+	}
+
+	want := []types.PackageScore{
+		{Package: "left-pad", Version: "1.3.0", Overall: 35},
+		{Package: "lodash", Version: "4.17.21", Overall: 92, SupplyChain: 100, Quality: 90, Maintenance: 80, Vulnerability: 100, License: 100},
+		{Package: "@babel/core", Version: "7.24.0", Overall: 60, SupplyChain: 55},
+	}
+	if len(result.Scores) != len(want) {
+		t.Fatalf("scores = %+v, want %d", result.Scores, len(want))
+	}
+	for i := range want {
+		if result.Scores[i] != want[i] {
+			t.Errorf("score %d = %+v, want %+v", i, result.Scores[i], want[i])
+		}
+	}
+}
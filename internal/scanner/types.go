@@ -14,6 +14,8 @@ type (
 	FindingType      = types.FindingType
 	Severity         = types.Severity
 	AggregatedResult = types.AggregatedResult
+	ScannerError     = types.ScannerError
+	PackageScore     = types.PackageScore
 )
 
 // Re-export constants
@@ -25,6 +27,12 @@ const (
 	FindingTypeMaintainer = types.FindingTypeMaintainer
 	FindingTypeQuality    = types.FindingTypeQuality
 
+	FindingTypeInstallScript = types.FindingTypeInstallScript
+	FindingTypeIntegrity     = types.FindingTypeIntegrity
+	FindingTypeProvenance    = types.FindingTypeProvenance
+	FindingTypeReleaseAge    = types.FindingTypeReleaseAge
+	FindingTypeLowScore      = types.FindingTypeLowScore
+
 	SeverityCritical = types.SeverityCritical
 	SeverityHigh     = types.SeverityHigh
 	SeverityMedium   = types.SeverityMedium
@@ -46,3 +54,23 @@ type Scanner interface {
 	// IsAvailable checks if the scanner can be used
 	IsAvailable() bool
 }
+
+// OfflineScanner is implemented by scanners that check packages locally.
+// Their results are cheap to recompute and are never cached.
+type OfflineScanner interface {
+	Offline() bool
+}
+
+// VolatileScanner is implemented by scanners whose results for a package
+// version change over time, such as how recently it was published. Their
+// results are never cached.
+type VolatileScanner interface {
+	Volatile() bool
+}
+
+// SelectiveScanner is implemented by scanners that only look at some of
+// the packages they are given, such as those about to be installed.
+// Packages they skip get no coverage entry.
+type SelectiveScanner interface {
+	Selects(pkg manifest.Package) bool
+}
@@ -0,0 +1,217 @@
+package typosquat
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// ScannerName labels names that look like a popular package
+const ScannerName = "Typosquat"
+
+// minLength is the shortest popular name compared by edit distance. Shorter
+// names are one edit away from too many legitimate packages.
+const minLength = 5
+
+//go:embed popular.txt
+var popularList string
+
+// popular is the bundled set of popular npm package names
+var popular = parseList(popularList)
+
+// Scanner flags dependencies whose names are suspiciously close to a
+// popular package. It works offline against a bundled list.
+type Scanner struct {
+	popular map[string]bool
+	allow   []string
+
+	// normalized maps each normalized popular name to the original
+	normalized map[string]string
+}
+
+// NewScanner creates a typosquat scanner that skips names matching the
+// configured allow patterns
+func NewScanner(cfg config.TyposquatConfig) *Scanner {
+	return newScanner(popular, cfg.Allow)
+}
+
+func newScanner(names []string, allow []string) *Scanner {
+	s := &Scanner{
+		popular:    make(map[string]bool, len(names)),
+		allow:      allow,
+		normalized: make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		s.popular[name] = true
+		if _, ok := s.normalized[normalize(name)]; !ok {
+			s.normalized[normalize(name)] = name
+		}
+	}
+	return s
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return ScannerName
+}
+
+// IsAvailable always returns true; the scanner needs no network or token
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// SupportsEcosystem returns true for npm, the only bundled list
+func (s *Scanner) SupportsEcosystem(ecosystem string) bool {
+	return ecosystem == "" || ecosystem == "npm"
+}
+
+// Offline returns true; results are cheap to recompute and must follow
+// allow list changes, so they are never cached
+func (s *Scanner) Offline() bool {
+	return true
+}
+
+// Scan reports each package whose name resembles a popular package
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	findings := []types.Finding{}
+	for _, pkg := range packages {
+		target, ok := s.Match(pkg.Name)
+		if !ok {
+			continue
+		}
+		findings = append(findings, types.Finding{
+			Package:     pkg.Name,
+			Version:     pkg.Version,
+			Type:        types.FindingTypeTyposquat,
+			Severity:    types.SeverityHigh,
+			Title:       fmt.Sprintf("Possible typosquat of %s", target),
+			Description: fmt.Sprintf("%s is a near-miss spelling of the popular package %s. Check that it is the package you meant to install.", pkg.Name, target),
+			Remediation: fmt.Sprintf("Install %s instead, or add %s to scanning.typosquat.allow if it is intended", target, pkg.Name),
+			References:  []string{"https://www.npmjs.com/package/" + target},
+		})
+	}
+
+	return &types.ScanResult{
+		Scanner:      s.Name(),
+		Packages:     len(packages),
+		Findings:     findings,
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// Match returns the popular package that name appears to imitate. Popular
+// and allowed names never match.
+func (s *Scanner) Match(name string) (string, bool) {
+	if s.popular[name] || s.allowed(name) {
+		return "", false
+	}
+
+	// Same name once separators, -js suffixes and look-alike digits are
+	// ignored, e.g. react-dom-js, expres5, lo-dash
+	if target, ok := s.normalized[normalize(name)]; ok {
+		return target, true
+	}
+
+	// One insertion, deletion or substitution away, e.g. expresss, lodsh
+	var candidates []string
+	for target := range s.popular {
+		if len(target) >= minLength && withinOneEdit(name, target) {
+			candidates = append(candidates, target)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.Strings(candidates)
+	return candidates[0], true
+}
+
+// allowed returns true if name matches an allow pattern
+func (s *Scanner) allowed(name string) bool {
+	for _, pattern := range s.allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// lookalikes maps digits to the letters they are swapped for
+var lookalikes = strings.NewReplacer("0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t")
+
+// normalize reduces a name to the form shared by common substitutions:
+// lowercase, without a node- prefix or js suffix, with look-alike digits
+// replaced and separators removed
+func normalize(name string) string {
+	scope, base := "", strings.ToLower(name)
+	if strings.HasPrefix(base, "@") {
+		if i := strings.Index(base, "/"); i > 0 {
+			scope, base = base[:i+1], base[i+1:]
+		}
+	}
+
+	base = strings.TrimPrefix(base, "node-")
+	for _, suffix := range []string{".js", "-js", "_js", "js"} {
+		if strings.HasSuffix(base, suffix) && len(base) > len(suffix) {
+			base = strings.TrimSuffix(base, suffix)
+			break
+		}
+	}
+	base = lookalikes.Replace(base)
+	base = strings.NewReplacer("-", "", "_", "", ".", "").Replace(base)
+	return scope + base
+}
+
+// withinOneEdit returns true if a and b differ by at most one insertion,
+// deletion or substitution
+func withinOneEdit(a, b string) bool {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > 1 {
+		return false
+	}
+
+	i, j, edits := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(a) == len(b) {
+			j++
+		}
+		i++
+	}
+	return edits+(len(a)-i) <= 1
+}
+
+// parseList reads one name per line, skipping blank lines and # comments
+func parseList(list string) []string {
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package typosquat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestMatch(t *testing.T) {
+	s := newScanner([]string{"express", "lodash", "react-dom", "chalk", "ms", "@babel/core"}, []string{"expres", "@mycorp/*"})
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"expresss", "express"},
+		{"lodsh", "lodash"},
+		{"chalc", "chalk"},
+		{"react-dom-js", "react-dom"},
+		{"reactdom", "react-dom"},
+		{"1odash", "lodash"},
+		{"@babel/c0re", "@babel/core"},
+		{"express", ""},        // the popular package itself
+		{"expres", ""},         // allowed
+		{"@mycorp/lodash", ""}, // allowed by glob
+		{"mx", ""},             // too short for edit distance
+		{"axios", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, ok := s.Match(tt.name)
+			if ok != (tt.target != "") || target != tt.target {
+				t.Errorf("Match(%q) = %q, %v; want %q", tt.name, target, ok, tt.target)
+			}
+		})
+	}
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"lodash", "lodash", true},
+		{"lodash", "lodas", true},
+		{"lodash", "lodashh", true},
+		{"lodash", "lodesh", true},
+		{"lodash", "lodahs", false},
+		{"lodash", "lash", false},
+	}
+
+	for _, tt := range tests {
+		if got := withinOneEdit(tt.a, tt.b); got != tt.want {
+			t.Errorf("withinOneEdit(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	s := NewScanner(config.TyposquatConfig{Enabled: true})
+	result, err := s.Scan(context.Background(), []manifest.Package{
+		{Name: "lodash", Version: "4.17.21"},
+		{Name: "expresss", Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(result.Findings) != 1 {
+		t.Fatalf("Scan() findings = %+v, want 1", result.Findings)
+	}
+	f := result.Findings[0]
+	if f.Package != "expresss" || f.Type != types.FindingTypeTyposquat || f.Severity != types.SeverityHigh {
+		t.Errorf("finding = %+v", f)
+	}
+	if f.Title != "Possible typosquat of express" {
+		t.Errorf("Title = %q", f.Title)
+	}
+}
@@ -0,0 +1,134 @@
+package seen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+const (
+	stateDir  = ".snapem"
+	storeFile = "seen-findings.json"
+)
+
+// Store remembers non-blocking findings that were already shown to the user
+// so repeat installs can summarize them instead of re-printing them. Each
+// workspace remembers its own findings, so installing into one does not
+// forget those of the others.
+type Store struct {
+	path      string
+	workspace string
+	known     map[string]time.Time
+	current   map[string]time.Time
+}
+
+// storeData is the file format. Findings belong to scans of the whole
+// project, and Workspaces to scans limited to one workspace.
+type storeData struct {
+	Findings   map[string]time.Time            `json:"findings"`
+	Workspaces map[string]map[string]time.Time `json:"workspaces,omitempty"`
+}
+
+// Load reads the seen-findings store of a project workspace, or of the
+// whole project if workspace is "". A missing or unreadable store is
+// treated as empty.
+func Load(projectDir, workspace string) *Store {
+	s := &Store{
+		path:      filepath.Join(projectDir, stateDir, storeFile),
+		workspace: workspace,
+		current:   make(map[string]time.Time),
+	}
+
+	s.known = read(s.path).findings(workspace)
+	if s.known == nil {
+		s.known = make(map[string]time.Time)
+	}
+
+	return s
+}
+
+// read returns the stored fingerprints, or none if the file is missing or
+// unreadable
+func read(path string) storeData {
+	var stored storeData
+	if data, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(data, &stored) != nil {
+			return storeData{}
+		}
+	}
+	return stored
+}
+
+// findings returns the fingerprints of a workspace
+func (d storeData) findings(workspace string) map[string]time.Time {
+	if workspace == "" {
+		return d.Findings
+	}
+	return d.Workspaces[workspace]
+}
+
+// setFindings replaces the fingerprints of a workspace
+func (d *storeData) setFindings(workspace string, findings map[string]time.Time) {
+	if workspace == "" {
+		d.Findings = findings
+		return
+	}
+	if d.Workspaces == nil {
+		d.Workspaces = make(map[string]map[string]time.Time)
+	}
+	d.Workspaces[workspace] = findings
+}
+
+// Record marks a finding as seen in this run and reports whether it was
+// already reported in a previous run. A nil Store never suppresses anything.
+func (s *Store) Record(f types.Finding) bool {
+	if s == nil {
+		return false
+	}
+
+	key := Fingerprint(f)
+	firstSeen, ok := s.known[key]
+	if !ok {
+		firstSeen = time.Now().UTC()
+	}
+	s.current[key] = firstSeen
+
+	return ok
+}
+
+// Save persists the findings recorded in this run. Fingerprints of the
+// store's workspace that were not seen again are dropped, so a warning that
+// disappears and later returns is treated as new; other workspaces'
+// fingerprints are kept as they are on disk.
+func (s *Store) Save() error {
+	if s == nil {
+		return nil
+	}
+
+	// Re-read the file so runs in other workspaces since Load are kept
+	stored := read(s.path)
+	stored.setFindings(s.workspace, s.current)
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Fingerprint returns a stable identifier for a finding
+func Fingerprint(f types.Finding) string {
+	id := f.ID
+	if id == "" {
+		id = f.Title
+	}
+	return f.Package + "@" + f.Version + "|" + string(f.Type) + "|" + id
+}
@@ -0,0 +1,169 @@
+package seen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+var findings = []types.Finding{
+	{Package: "lodash", Version: "4.17.20", Type: types.FindingTypeCVE, ID: "GHSA-35jh-r3h4-6jhm", Title: "Command Injection"},
+	{Package: "request", Version: "2.88.2", Type: types.FindingTypeQuality, Title: "Deprecated"},
+	{Package: "minimist", Version: "1.2.5", Type: types.FindingTypeCVE, ID: "GHSA-xvch-5gv4-984h"},
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	s := Load(dir, "")
+	for _, f := range findings {
+		if s.Record(f) {
+			t.Errorf("Record(%s) = true in an empty store", Fingerprint(f))
+		}
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s = Load(dir, "")
+	for _, f := range findings[:2] {
+		if !s.Record(f) {
+			t.Errorf("Record(%s) = false after it was saved", Fingerprint(f))
+		}
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// minimist was not seen in the second run, so it is new again
+	s = Load(dir, "")
+	if s.Record(findings[2]) {
+		t.Error("Record() = true for a finding dropped by the previous run")
+	}
+	if !s.Record(findings[0]) {
+		t.Error("Record() = false for a finding kept by the previous run")
+	}
+}
+
+func TestLoadUnreadable(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"missing", ""},
+		{"corrupt", "{not json"},
+		{"no findings", `{"other": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.contents != "" {
+				path := filepath.Join(dir, stateDir, storeFile)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s := Load(dir, "")
+			if s.Record(findings[0]) {
+				t.Error("Record() = true in an unreadable store")
+			}
+			if err := s.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if !Load(dir, "").Record(findings[0]) {
+				t.Error("Record() = false after saving over an unreadable store")
+			}
+		})
+	}
+}
+
+func TestFingerprintOrder(t *testing.T) {
+	dir := t.TempDir()
+	s := Load(dir, "")
+	for _, f := range findings {
+		s.Record(f)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	first, err := os.ReadFile(filepath.Join(dir, stateDir, storeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another run reports the same findings in reverse order
+	s = Load(dir, "")
+	for i := len(findings) - 1; i >= 0; i-- {
+		if !s.Record(findings[i]) {
+			t.Errorf("Record(%s) = false when findings are reordered", Fingerprint(findings[i]))
+		}
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second, err := os.ReadFile(filepath.Join(dir, stateDir, storeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("store changed when findings were reordered:\n%s\n%s", first, second)
+	}
+
+	// The fingerprint ignores fields that do not identify the finding
+	f := findings[0]
+	f.Severity = types.SeverityCritical
+	f.References = []string{"https://example.com"}
+	if Fingerprint(f) != Fingerprint(findings[0]) {
+		t.Errorf("Fingerprint() = %q, want %q", Fingerprint(f), Fingerprint(findings[0]))
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	if s.Record(findings[0]) {
+		t.Error("Record() on a nil store = true")
+	}
+	if err := s.Save(); err != nil {
+		t.Errorf("Save() on a nil store error = %v", err)
+	}
+}
+
+// TestWorkspaces checks that saving one workspace keeps the findings of
+// another, even one saved after the first was loaded
+func TestWorkspaces(t *testing.T) {
+	dir := t.TempDir()
+
+	web := Load(dir, "web")
+	api := Load(dir, "api")
+	web.Record(findings[0])
+	api.Record(findings[1])
+	if err := web.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := api.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A later web run without lodash drops it from web only
+	web = Load(dir, "web")
+	web.Record(findings[2])
+	if err := web.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if !Load(dir, "api").Record(findings[1]) {
+		t.Error("Record() = false for a finding saved by another workspace")
+	}
+	if Load(dir, "web").Record(findings[0]) {
+		t.Error("Record() = true for a finding dropped by the workspace's last run")
+	}
+	if Load(dir, "").Record(findings[1]) {
+		t.Error("Record() = true in the project store for a workspace's finding")
+	}
+}
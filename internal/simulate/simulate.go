@@ -0,0 +1,186 @@
+// Package simulate injects synthetic failures so CI pipelines can verify how
+// they handle blocked installs, scanner outages and container failures
+// without real vulnerable packages.
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// EnvVar must be set to "1" for --simulate to be accepted
+const EnvVar = "SNAPEM_SIMULATE"
+
+// Mode selects which failure to inject
+type Mode string
+
+const (
+	// None disables simulation
+	None Mode = ""
+
+	// Block produces a synthetic critical malware finding
+	Block Mode = "block"
+
+	// ScannerFailure makes scanning return an error
+	ScannerFailure Mode = "scanner-failure"
+
+	// ContainerFailure makes the container runtime exit non-zero
+	ContainerFailure Mode = "container-failure"
+
+	// Timeout makes scanning fail with a deadline error
+	Timeout Mode = "timeout"
+)
+
+// Modes lists the supported simulation modes
+var Modes = []Mode{Block, ScannerFailure, ContainerFailure, Timeout}
+
+// Parse validates a simulation mode name
+func Parse(name string) (Mode, error) {
+	if name == "" {
+		return None, nil
+	}
+	for _, m := range Modes {
+		if Mode(name) == m {
+			return m, nil
+		}
+	}
+	return None, fmt.Errorf("unknown simulation %q (expected block, scanner-failure, container-failure or timeout)", name)
+}
+
+// Allowed returns true if the simulation gate environment variable is set
+func Allowed() bool {
+	return os.Getenv(EnvVar) == "1"
+}
+
+// AffectsScan returns true if the mode replaces the real scanners
+func (m Mode) AffectsScan() bool {
+	return m == Block || m == ScannerFailure || m == Timeout
+}
+
+// AffectsContainer returns true if the mode replaces the container runtime
+func (m Mode) AffectsContainer() bool {
+	return m == ContainerFailure
+}
+
+// Scanner is a stand-in scanner that produces the simulated scan outcome
+type Scanner struct {
+	mode Mode
+}
+
+// NewScanner creates a simulated scanner for the given mode
+func NewScanner(mode Mode) *Scanner {
+	return &Scanner{mode: mode}
+}
+
+// Name returns the scanner name
+func (s *Scanner) Name() string {
+	return "Simulation (" + string(s.mode) + ")"
+}
+
+// IsAvailable always returns true
+func (s *Scanner) IsAvailable() bool {
+	return true
+}
+
+// Scan returns a synthetic finding or error depending on the mode
+func (s *Scanner) Scan(ctx context.Context, packages []manifest.Package) (*types.ScanResult, error) {
+	start := time.Now()
+
+	switch s.mode {
+	case ScannerFailure:
+		return nil, fmt.Errorf("simulated scanner failure")
+	case Timeout:
+		return nil, fmt.Errorf("simulated scanner timeout: %w", context.DeadlineExceeded)
+	}
+
+	pkg := manifest.Package{Name: "snapem-simulated-package", Version: "0.0.0"}
+	if len(packages) > 0 {
+		pkg = packages[0]
+	}
+
+	return &types.ScanResult{
+		Scanner:  s.Name(),
+		Packages: len(packages),
+		Findings: []types.Finding{
+			{
+				Package:     pkg.Name,
+				Version:     pkg.Version,
+				Type:        types.FindingTypeMalware,
+				Severity:    types.SeverityCritical,
+				Title:       "[SIMULATED] malware",
+				Description: "[SIMULATED] Synthetic finding injected by --simulate block; this package was not actually flagged",
+				ID:          "SNAPEM-SIMULATED",
+			},
+		},
+		ScanDuration: time.Since(start),
+	}, nil
+}
+
+// Runtime is a stand-in container runtime whose commands always fail
+type Runtime struct{}
+
+// NewRuntime creates a simulated failing runtime
+func NewRuntime() *Runtime {
+	return &Runtime{}
+}
+
+// Name returns the runtime name
+func (r *Runtime) Name() string {
+	return "Simulation (container-failure)"
+}
+
+// IsAvailable always returns true
+func (r *Runtime) IsAvailable() bool {
+	return true
+}
+
+// Run returns the error a container exiting non-zero would produce
+func (r *Runtime) Run(ctx context.Context, opts *container.RunOptions) error {
+	return &errors.SnapemError{
+		Code:    errors.ExitGeneralError,
+		Message: "container command failed",
+		Cause:   fmt.Errorf("simulated container failure"),
+	}
+}
+
+// ImageExists returns true so no pull is attempted
+func (r *Runtime) ImageExists(ctx context.Context, image string) (bool, error) {
+	return true, nil
+}
+
+// Pull does nothing
+func (r *Runtime) Pull(ctx context.Context, image string) error {
+	return nil
+}
+
+// List returns no containers
+func (r *Runtime) List(ctx context.Context) ([]container.Container, error) {
+	return nil, nil
+}
+
+// Logs fails like Run
+func (r *Runtime) Logs(ctx context.Context, name string, follow bool) error {
+	return r.Run(ctx, nil)
+}
+
+// Stop fails like Run
+func (r *Runtime) Stop(ctx context.Context, name string, grace time.Duration) error {
+	return r.Run(ctx, nil)
+}
+
+// Attach fails like Run
+func (r *Runtime) Attach(ctx context.Context, name string) error {
+	return r.Run(ctx, nil)
+}
+
+// CommandString describes the command that would have run
+func (r *Runtime) CommandString(opts *container.RunOptions) string {
+	return "[SIMULATED] " + container.NewAppleRuntime().CommandString(opts)
+}
@@ -0,0 +1,115 @@
+package simulate
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/internal/container"
+	"github.com/positronico/snapem/internal/errors"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	for _, m := range Modes {
+		if got, err := Parse(string(m)); err != nil || got != m {
+			t.Errorf("Parse(%q) = %q, %v", m, got, err)
+		}
+	}
+	if got, err := Parse(""); err != nil || got != None {
+		t.Errorf("Parse(\"\") = %q, %v, want None", got, err)
+	}
+	if _, err := Parse("oom"); err == nil || !strings.Contains(err.Error(), "unknown simulation") {
+		t.Errorf("Parse(\"oom\") error = %v", err)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	for value, want := range map[string]bool{"1": true, "": false, "true": false} {
+		t.Setenv(EnvVar, value)
+		if got := Allowed(); got != want {
+			t.Errorf("Allowed() with %s=%q = %v, want %v", EnvVar, value, got, want)
+		}
+	}
+}
+
+func TestModeLayers(t *testing.T) {
+	tests := []struct {
+		mode      Mode
+		scan      bool
+		container bool
+	}{
+		{None, false, false},
+		{Block, true, false},
+		{ScannerFailure, true, false},
+		{Timeout, true, false},
+		{ContainerFailure, false, true},
+	}
+	for _, tt := range tests {
+		if tt.mode.AffectsScan() != tt.scan || tt.mode.AffectsContainer() != tt.container {
+			t.Errorf("%q: AffectsScan() = %v, AffectsContainer() = %v", tt.mode, tt.mode.AffectsScan(), tt.mode.AffectsContainer())
+		}
+	}
+}
+
+func TestScanBlock(t *testing.T) {
+	packages := []manifest.Package{{Name: "left-pad", Version: "1.3.0"}, {Name: "lodash", Version: "4.17.21"}}
+	result, err := NewScanner(Block).Scan(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if result.Scanner != "Simulation (block)" || result.Packages != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("findings = %+v, want one", result.Findings)
+	}
+
+	// The finding must block like real malware but never pass for a real
+	// result
+	f := result.Findings[0]
+	if f.Package != "left-pad" || f.Version != "1.3.0" {
+		t.Errorf("finding is on %s@%s, want the first package", f.Package, f.Version)
+	}
+	if f.Type != types.FindingTypeMalware || f.Severity != types.SeverityCritical {
+		t.Errorf("finding is %s %s, want critical malware", f.Severity, f.Type)
+	}
+	if !strings.HasPrefix(f.Title, "[SIMULATED]") || !strings.HasPrefix(f.Description, "[SIMULATED]") || f.ID != "SNAPEM-SIMULATED" {
+		t.Errorf("finding is not labeled as simulated: %+v", f)
+	}
+
+	result, err = NewScanner(Block).Scan(context.Background(), nil)
+	if err != nil || len(result.Findings) != 1 || result.Findings[0].Package != "snapem-simulated-package" {
+		t.Errorf("Scan() of no packages = %+v, %v, want a placeholder finding", result, err)
+	}
+}
+
+func TestScanFailures(t *testing.T) {
+	_, err := NewScanner(ScannerFailure).Scan(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "simulated") {
+		t.Errorf("scanner-failure error = %v", err)
+	}
+
+	_, err = NewScanner(Timeout).Scan(context.Background(), nil)
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timeout error = %v, want a deadline error", err)
+	}
+}
+
+func TestRuntime(t *testing.T) {
+	r := NewRuntime()
+	opts := &container.RunOptions{Image: "node:22", Command: []string{"npm", "install"}}
+
+	err := r.Run(context.Background(), opts)
+	if code := errors.ExitCodeFor(err); code != errors.ExitGeneralError {
+		t.Errorf("Run() exit code = %d (%v), want %d", code, err, errors.ExitGeneralError)
+	}
+	if got := r.CommandString(opts); !strings.HasPrefix(got, "[SIMULATED] ") || !strings.Contains(got, "npm install") {
+		t.Errorf("CommandString() = %q", got)
+	}
+	if exists, err := r.ImageExists(context.Background(), "node:22"); !exists || err != nil {
+		t.Errorf("ImageExists() = %v, %v, want no pull", exists, err)
+	}
+}
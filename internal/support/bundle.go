@@ -0,0 +1,44 @@
+// Package support builds the zip bundle users attach to bug reports.
+package support
+
+import (
+	"archive/zip"
+	"io"
+	"time"
+
+	"github.com/positronico/snapem/internal/redact"
+)
+
+// File is one entry in a support bundle
+type File struct {
+	// Name is the path inside the zip
+	Name string
+
+	// Description is shown to the user before the bundle is written
+	Description string
+
+	// Content is the file's content before redaction
+	Content string
+}
+
+// Write writes files to w as a zip archive. Every file is passed through
+// redact.String first; nothing reaches the archive unredacted.
+func Write(w io.Writer, files []File, modified time.Time) error {
+	zw := zip.NewWriter(w)
+
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, redact.String(f.Content)); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
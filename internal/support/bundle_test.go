@@ -0,0 +1,45 @@
+package support
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriteRedacts(t *testing.T) {
+	files := []File{
+		{Name: "config.yaml", Content: "scanning.freshness.github_token: ghs_secret # env\nui.color: true\n"},
+		{Name: "environment.txt", Content: "NPM_TOKEN=abc123\n"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, files, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	if len(zr.File) != len(files) {
+		t.Fatalf("got %d files, want %d", len(zr.File), len(files))
+	}
+
+	want := map[string]string{
+		"config.yaml":     "scanning.freshness.github_token: **** # env\nui.color: true\n",
+		"environment.txt": "NPM_TOKEN=****\n",
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", f.Name, err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(got) != want[f.Name] {
+			t.Errorf("%s =\n%q\nwant\n%q", f.Name, got, want[f.Name])
+		}
+	}
+}
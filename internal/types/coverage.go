@@ -0,0 +1,105 @@
+package types
+
+import "sort"
+
+// CoverageReason records how a scanner handled a package
+type CoverageReason string
+
+const (
+	// CoverageChecked means the scanner queried the package in this scan
+	CoverageChecked CoverageReason = "checked"
+
+	// CoverageCached means the scanner's result came from the cache
+	CoverageCached CoverageReason = "cached"
+
+	// CoverageExcluded means the package was skipped by policy (allowlist)
+	CoverageExcluded CoverageReason = "excluded"
+
+	// CoverageDisabled means the scanner is turned off in the config
+	CoverageDisabled CoverageReason = "disabled"
+
+	// CoverageUnavailable means the scanner could not run (e.g. no token)
+	CoverageUnavailable CoverageReason = "unavailable"
+
+	// CoverageFailed means the scanner returned an error
+	CoverageFailed CoverageReason = "failed"
+
+	// CoverageUnsupported means the scanner does not cover the package's
+	// ecosystem
+	CoverageUnsupported CoverageReason = "unsupported_ecosystem"
+)
+
+// Covered returns true if the scanner actually assessed the package
+func (r CoverageReason) Covered() bool {
+	return r == CoverageChecked || r == CoverageCached
+}
+
+// PackageCoverage records how every scanner handled one package
+type PackageCoverage struct {
+	Package  string                    `json:"package"`
+	Version  string                    `json:"version"`
+	Scanners map[string]CoverageReason `json:"scanners"`
+}
+
+// Covered returns true if every scanner assessed the package
+func (p PackageCoverage) Covered() bool {
+	for _, reason := range p.Scanners {
+		if !reason.Covered() {
+			return false
+		}
+	}
+	return len(p.Scanners) > 0
+}
+
+// Coverage records, for every input package, which scanners checked it
+type Coverage struct {
+	// Scanners lists every configured scanner, in order
+	Scanners []string `json:"scanners"`
+
+	// Packages lists the input packages in order
+	Packages []PackageCoverage `json:"packages"`
+}
+
+// CoverageSummary is the compact form of Coverage included in reports
+type CoverageSummary struct {
+	Packages int `json:"packages"`
+
+	// FullyCovered counts packages assessed by every scanner
+	FullyCovered int `json:"fully_covered"`
+
+	// Percent is FullyCovered as a percentage of Packages
+	Percent float64 `json:"percent"`
+
+	// Reasons counts package/scanner pairs by outcome
+	Reasons map[CoverageReason]int `json:"reasons"`
+}
+
+// Summary counts the coverage outcomes
+func (c *Coverage) Summary() CoverageSummary {
+	s := CoverageSummary{
+		Packages: len(c.Packages),
+		Reasons:  make(map[CoverageReason]int),
+	}
+	for _, p := range c.Packages {
+		if p.Covered() {
+			s.FullyCovered++
+		}
+		for _, reason := range p.Scanners {
+			s.Reasons[reason]++
+		}
+	}
+	if s.Packages > 0 {
+		s.Percent = float64(s.FullyCovered*1000/s.Packages) / 10
+	}
+	return s
+}
+
+// SortedReasons returns the summary's reasons in a stable order
+func (s CoverageSummary) SortedReasons() []CoverageReason {
+	reasons := make([]CoverageReason, 0, len(s.Reasons))
+	for r := range s.Reasons {
+		reasons = append(reasons, r)
+	}
+	sort.Slice(reasons, func(i, j int) bool { return reasons[i] < reasons[j] })
+	return reasons
+}
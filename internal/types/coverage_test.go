@@ -0,0 +1,46 @@
+package types
+
+import "testing"
+
+func TestCoverageSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages []PackageCoverage
+		full     int
+		percent  float64
+	}{
+		{
+			name: "all checked or cached",
+			packages: []PackageCoverage{
+				{Package: "a", Scanners: map[string]CoverageReason{"x": CoverageChecked}},
+				{Package: "b", Scanners: map[string]CoverageReason{"x": CoverageCached}},
+			},
+			full:    2,
+			percent: 100,
+		},
+		{
+			name: "one of three failed",
+			packages: []PackageCoverage{
+				{Package: "a", Scanners: map[string]CoverageReason{"x": CoverageChecked}},
+				{Package: "b", Scanners: map[string]CoverageReason{"x": CoverageChecked}},
+				{Package: "c", Scanners: map[string]CoverageReason{"x": CoverageFailed}},
+			},
+			full:    2,
+			percent: 66.6,
+		},
+		{
+			name:     "no packages",
+			packages: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Coverage{Scanners: []string{"x"}, Packages: tt.packages}
+			s := c.Summary()
+			if s.FullyCovered != tt.full || s.Percent != tt.percent {
+				t.Errorf("Summary() = %d (%.1f%%), want %d (%.1f%%)", s.FullyCovered, s.Percent, tt.full, tt.percent)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// FreshnessStatus rates how actively a dependency is maintained
+type FreshnessStatus string
+
+const (
+	FreshnessFresh   FreshnessStatus = "fresh"
+	FreshnessAging   FreshnessStatus = "aging"
+	FreshnessStale   FreshnessStatus = "stale"
+	FreshnessUnknown FreshnessStatus = "unknown"
+)
+
+// DependencyHealth describes the maintenance state of a direct dependency
+type DependencyHealth struct {
+	Package          string          `json:"package"`
+	Version          string          `json:"version"`
+	LastPublish      time.Time       `json:"last_publish,omitempty"`
+	DaysSinceRelease int             `json:"days_since_release"`
+	Repository       string          `json:"repository,omitempty"`
+	Archived         bool            `json:"archived"`
+	OpenAdvisories   int             `json:"open_advisories"`
+	Status           FreshnessStatus `json:"status"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import "context"
+
+// ProgressFunc receives how many of a scanner's packages are done
+type ProgressFunc func(completed, total int)
+
+type progressKey struct{}
+
+// WithProgress returns a context that passes the package counts a scanner
+// reports with ReportProgress to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress tells the caller of a scan how many packages are done.
+// It does nothing unless the context came from WithProgress.
+func ReportProgress(ctx context.Context, completed, total int) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(completed, total)
+	}
+}
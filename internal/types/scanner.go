@@ -1,6 +1,9 @@
 package types
 
 import (
+	"iter"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -11,6 +14,31 @@ type ScanResult struct {
 	Findings     []Finding     `json:"findings"`
 	ScanDuration time.Duration `json:"scan_duration"`
 	Cached       bool          `json:"cached"`
+
+	// Scores holds per-package scores, from scanners that compute them
+	Scores []PackageScore `json:"scores,omitempty"`
+
+	// Failed lists packages the scanner could not check while it checked
+	// the others; they are coverage gaps rather than a scanner failure
+	Failed []PackageError `json:"failed,omitempty"`
+}
+
+// PackageError records a package a scanner could not check
+type PackageError struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	Message string `json:"message"`
+}
+
+// ScannerError records a scanner that failed during a scan
+type ScannerError struct {
+	Scanner string `json:"scanner"`
+	Message string `json:"message"`
+}
+
+// Error implements error
+func (e ScannerError) Error() string {
+	return e.Scanner + ": " + e.Message
 }
 
 // Finding represents a security issue
@@ -24,6 +52,54 @@ type Finding struct {
 	ID          string      `json:"id,omitempty"`
 	References  []string    `json:"references,omitempty"`
 	Remediation string      `json:"remediation,omitempty"`
+
+	// Aliases are other IDs for the same advisory, such as the CVE of a
+	// GHSA advisory
+	Aliases []string `json:"aliases,omitempty"`
+
+	// FixedIn is the lowest version that fixes a vulnerability, when the
+	// advisory names one
+	FixedIn string `json:"fixed_in,omitempty"`
+
+	// License is the SPDX identifier for license findings, when known
+	License string `json:"license,omitempty"`
+
+	// Scripts maps lifecycle script names to their commands for install
+	// script findings
+	Scripts map[string]string `json:"scripts,omitempty"`
+
+	// Deprecated is the registry's deprecation message for deprecated
+	// package findings
+	Deprecated string `json:"deprecated,omitempty"`
+
+	// Direct is set when the package is a direct dependency of the
+	// project rather than a transitive one
+	Direct bool `json:"direct"`
+}
+
+// IDs returns the finding's ID followed by its aliases
+func (f Finding) IDs() []string {
+	var ids []string
+	if f.ID != "" {
+		ids = append(ids, f.ID)
+	}
+	for _, alias := range f.Aliases {
+		if alias != "" && !strings.EqualFold(alias, f.ID) {
+			ids = append(ids, alias)
+		}
+	}
+	return ids
+}
+
+// DisplayID returns the ID to show for the finding: its CVE when it has
+// one, as that is the form most tools and people use, else its ID
+func (f Finding) DisplayID() string {
+	for _, id := range f.IDs() {
+		if strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+			return id
+		}
+	}
+	return f.ID
 }
 
 // FindingType categorizes the type of security issue
@@ -36,8 +112,22 @@ const (
 	FindingTypeLicense    FindingType = "license"
 	FindingTypeMaintainer FindingType = "maintainer"
 	FindingTypeQuality    FindingType = "quality"
+
+	FindingTypeInstallScript FindingType = "install_script"
+	FindingTypeIntegrity     FindingType = "integrity"
+	FindingTypeProvenance    FindingType = "provenance"
+	FindingTypeReleaseAge    FindingType = "release_age"
+	FindingTypeLowScore      FindingType = "low_score"
 )
 
+// FindingTypes lists every finding type
+var FindingTypes = []FindingType{
+	FindingTypeMalware, FindingTypeCVE, FindingTypeTyposquat, FindingTypeLicense,
+	FindingTypeMaintainer, FindingTypeQuality, FindingTypeInstallScript,
+	FindingTypeIntegrity, FindingTypeProvenance, FindingTypeReleaseAge,
+	FindingTypeLowScore,
+}
+
 // Severity levels for findings
 type Severity string
 
@@ -57,66 +147,173 @@ type AggregatedResult struct {
 	HasMalware    bool          `json:"has_malware"`
 	HasCritical   bool          `json:"has_critical"`
 	HasHigh       bool          `json:"has_high"`
+	CacheHits     int           `json:"cache_hits"`
 	Duration      time.Duration `json:"duration"`
+
+	// Freshness is set when the scan includes a dependency freshness check
+	Freshness []DependencyHealth `json:"freshness,omitempty"`
+
+	// Coverage records which scanners checked each input package
+	Coverage *Coverage `json:"coverage,omitempty"`
+
+	// Errors lists scanners that failed while others succeeded
+	Errors []ScannerError `json:"errors,omitempty"`
+
+	// Baselined holds the findings removed because the project's baseline
+	// file acknowledges them; they are not counted in TotalFindings
+	Baselined []Finding `json:"baselined,omitempty"`
+
+	// Scores holds the Socket.dev score of each package that has one,
+	// sorted by package
+	Scores []PackageScore `json:"scores,omitempty"`
+
+	// tally is built on the first count query; Results must not change after
+	tally *findingTally
 }
 
-// CountBySeverity returns the count of findings by severity
-func (ar *AggregatedResult) CountBySeverity(sev Severity) int {
-	count := 0
+// findingTally holds per-severity and per-type finding counts
+type findingTally struct {
+	total      int
+	bySeverity map[Severity]int
+	byType     map[FindingType]int
+}
+
+// counts returns the finding tally, walking the results only once
+func (ar *AggregatedResult) counts() *findingTally {
+	if ar.tally != nil {
+		return ar.tally
+	}
+
+	t := &findingTally{
+		bySeverity: make(map[Severity]int),
+		byType:     make(map[FindingType]int),
+	}
 	for _, result := range ar.Results {
-		for _, finding := range result.Findings {
-			if finding.Severity == sev {
-				count++
-			}
+		for i := range result.Findings {
+			t.total++
+			t.bySeverity[result.Findings[i].Severity]++
+			t.byType[result.Findings[i].Type]++
 		}
 	}
-	return count
+	ar.tally = t
+	return t
+}
+
+// CountBySeverity returns the count of findings by severity
+func (ar *AggregatedResult) CountBySeverity(sev Severity) int {
+	return ar.counts().bySeverity[sev]
 }
 
 // CountByType returns the count of findings by type
 func (ar *AggregatedResult) CountByType(typ FindingType) int {
-	count := 0
-	for _, result := range ar.Results {
-		for _, finding := range result.Findings {
-			if finding.Type == typ {
-				count++
+	return ar.counts().byType[typ]
+}
+
+// Findings iterates over every finding without copying them
+func (ar *AggregatedResult) Findings() iter.Seq[*Finding] {
+	return func(yield func(*Finding) bool) {
+		for _, result := range ar.Results {
+			for i := range result.Findings {
+				if !yield(&result.Findings[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FindingsOfType iterates over findings matching any of the given types
+func (ar *AggregatedResult) FindingsOfType(typs ...FindingType) iter.Seq[*Finding] {
+	return func(yield func(*Finding) bool) {
+		for f := range ar.Findings() {
+			if slices.Contains(typs, f.Type) && !yield(f) {
+				return
 			}
 		}
 	}
-	return count
 }
 
 // AllFindings returns a flat list of all findings
 func (ar *AggregatedResult) AllFindings() []Finding {
-	var findings []Finding
+	findings := make([]Finding, 0, ar.counts().total)
 	for _, result := range ar.Results {
 		findings = append(findings, result.Findings...)
 	}
 	return findings
 }
 
-// MalwareFindings returns only malware findings
-func (ar *AggregatedResult) MalwareFindings() []Finding {
-	var findings []Finding
-	for _, result := range ar.Results {
-		for _, finding := range result.Findings {
-			if finding.Type == FindingTypeMalware || finding.Type == FindingTypeTyposquat {
-				findings = append(findings, finding)
+// Filter returns a copy of the result that keeps only the findings keep
+// accepts, with the totals and flags recomputed
+func (ar *AggregatedResult) Filter(keep func(*Finding) bool) *AggregatedResult {
+	filtered := *ar
+	filtered.tally = nil
+	filtered.TotalFindings = 0
+	filtered.HasMalware, filtered.HasCritical, filtered.HasHigh = false, false, false
+	filtered.Results = make([]*ScanResult, len(ar.Results))
+
+	for i, result := range ar.Results {
+		copied := *result
+		copied.Findings = nil
+		for j := range result.Findings {
+			f := &result.Findings[j]
+			if !keep(f) {
+				continue
+			}
+			copied.Findings = append(copied.Findings, *f)
+			filtered.TotalFindings++
+			if f.Type == FindingTypeMalware || f.Type == FindingTypeTyposquat {
+				filtered.HasMalware = true
+			}
+			switch f.Severity {
+			case SeverityCritical:
+				filtered.HasCritical = true
+			case SeverityHigh:
+				filtered.HasHigh = true
 			}
 		}
+		filtered.Results[i] = &copied
 	}
-	return findings
+	return &filtered
+}
+
+// MalwareFindings returns only malware findings
+func (ar *AggregatedResult) MalwareFindings() []Finding {
+	return ar.collect(FindingTypeMalware, FindingTypeTyposquat)
 }
 
 // CVEFindings returns only CVE findings
 func (ar *AggregatedResult) CVEFindings() []Finding {
-	var findings []Finding
-	for _, result := range ar.Results {
-		for _, finding := range result.Findings {
-			if finding.Type == FindingTypeCVE {
-				findings = append(findings, finding)
-			}
-		}
+	return ar.collect(FindingTypeCVE)
+}
+
+// LicenseFindings returns only license findings
+func (ar *AggregatedResult) LicenseFindings() []Finding {
+	return ar.collect(FindingTypeLicense)
+}
+
+// InstallScriptFindings returns only install script findings
+func (ar *AggregatedResult) InstallScriptFindings() []Finding {
+	return ar.collect(FindingTypeInstallScript)
+}
+
+// ProvenanceFindings returns only provenance findings
+func (ar *AggregatedResult) ProvenanceFindings() []Finding {
+	return ar.collect(FindingTypeProvenance)
+}
+
+// collect copies the findings of the given types into a slice sized up front
+func (ar *AggregatedResult) collect(typs ...FindingType) []Finding {
+	n := 0
+	for _, typ := range typs {
+		n += ar.CountByType(typ)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	findings := make([]Finding, 0, n)
+	for f := range ar.FindingsOfType(typs...) {
+		findings = append(findings, *f)
 	}
 	return findings
 }
@@ -0,0 +1,152 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+// syntheticResult builds a result with n findings spread across two scanners
+func syntheticResult(n int) *AggregatedResult {
+	severities := []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow}
+	typs := []FindingType{FindingTypeCVE, FindingTypeCVE, FindingTypeCVE, FindingTypeMalware, FindingTypeTyposquat}
+
+	results := []*ScanResult{{Scanner: "a"}, {Scanner: "b"}}
+	for i := 0; i < n; i++ {
+		r := results[i%len(results)]
+		r.Findings = append(r.Findings, Finding{
+			Package:    fmt.Sprintf("pkg-%d", i),
+			Version:    "1.0.0",
+			Type:       typs[i%len(typs)],
+			Severity:   severities[i%len(severities)],
+			Title:      "synthetic finding",
+			ID:         fmt.Sprintf("GHSA-%d", i),
+			References: []string{"https://example.com/advisory"},
+		})
+	}
+
+	return &AggregatedResult{Results: results, TotalFindings: n}
+}
+
+func TestAggregatedResultViews(t *testing.T) {
+	result := syntheticResult(20)
+
+	if got := result.CountBySeverity(SeverityCritical); got != 5 {
+		t.Errorf("CountBySeverity(critical) = %d, want 5", got)
+	}
+	if got := result.CountByType(FindingTypeCVE); got != 12 {
+		t.Errorf("CountByType(cve) = %d, want 12", got)
+	}
+	if got := len(result.AllFindings()); got != 20 {
+		t.Errorf("len(AllFindings()) = %d, want 20", got)
+	}
+	if got := len(result.MalwareFindings()); got != 8 {
+		t.Errorf("len(MalwareFindings()) = %d, want 8", got)
+	}
+	if got := len(result.CVEFindings()); got != 12 {
+		t.Errorf("len(CVEFindings()) = %d, want 12", got)
+	}
+
+	seen := 0
+	for range result.FindingsOfType(FindingTypeMalware) {
+		seen++
+		if seen == 2 {
+			break
+		}
+	}
+	if seen != 2 {
+		t.Errorf("iteration did not stop on break, saw %d", seen)
+	}
+}
+
+func TestAggregatedResultFilter(t *testing.T) {
+	result := syntheticResult(20)
+	result.Results[0].Findings[0].Direct = true // critical CVE
+	result.Results[1].Findings[1].Direct = true // low malware
+
+	direct := result.Filter(func(f *Finding) bool { return f.Direct })
+	if direct.TotalFindings != 2 || len(direct.AllFindings()) != 2 {
+		t.Errorf("TotalFindings = %d, want 2", direct.TotalFindings)
+	}
+	if !direct.HasCritical || !direct.HasMalware || direct.HasHigh {
+		t.Errorf("flags = critical %v, malware %v, high %v", direct.HasCritical, direct.HasMalware, direct.HasHigh)
+	}
+	if got := len(result.AllFindings()); got != 20 {
+		t.Errorf("original result changed: %d findings", got)
+	}
+}
+
+func TestFindingIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		finding Finding
+		display string
+		ids     int
+	}{
+		{"no aliases", Finding{ID: "GHSA-1"}, "GHSA-1", 1},
+		{"CVE alias", Finding{ID: "GHSA-1", Aliases: []string{"CVE-2021-1"}}, "CVE-2021-1", 2},
+		{"CVE ID", Finding{ID: "CVE-2021-1", Aliases: []string{"GHSA-1"}}, "CVE-2021-1", 2},
+		{"alias repeats ID", Finding{ID: "GHSA-1", Aliases: []string{"ghsa-1", ""}}, "GHSA-1", 1},
+		{"no ID", Finding{}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.finding.DisplayID(); got != tt.display {
+				t.Errorf("DisplayID() = %q, want %q", got, tt.display)
+			}
+			if got := tt.finding.IDs(); len(got) != tt.ids {
+				t.Errorf("IDs() = %v, want %d IDs", got, tt.ids)
+			}
+		})
+	}
+}
+
+func TestAggregatedResultCountsDoNotAllocate(t *testing.T) {
+	result := syntheticResult(100)
+	result.CountBySeverity(SeverityHigh) // build the tally
+
+	allocs := testing.AllocsPerRun(100, func() {
+		result.CountBySeverity(SeverityCritical)
+		result.CountBySeverity(SeverityHigh)
+		result.CountByType(FindingTypeMalware)
+	})
+	if allocs != 0 {
+		t.Errorf("counting allocated %.0f times, want 0", allocs)
+	}
+}
+
+// BenchmarkSummaryIterators renders a summary the way the text report does:
+// counts from the tally and filtered views through iterators
+func BenchmarkSummaryIterators(b *testing.B) {
+	result := syntheticResult(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		result.tally = nil
+		_ = result.CountBySeverity(SeverityCritical) + result.CountBySeverity(SeverityHigh) +
+			result.CountBySeverity(SeverityMedium) + result.CountBySeverity(SeverityLow)
+		n := 0
+		for range result.FindingsOfType(FindingTypeMalware, FindingTypeTyposquat) {
+			n++
+		}
+		for range result.FindingsOfType(FindingTypeCVE) {
+			n++
+		}
+	}
+}
+
+// BenchmarkSummarySlices renders the same summary through the slice views
+func BenchmarkSummarySlices(b *testing.B) {
+	result := syntheticResult(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for b.Loop() {
+		result.tally = nil
+		_ = result.CountBySeverity(SeverityCritical) + result.CountBySeverity(SeverityHigh) +
+			result.CountBySeverity(SeverityMedium) + result.CountBySeverity(SeverityLow)
+		_ = result.AllFindings()
+		_ = result.MalwareFindings()
+		_ = result.CVEFindings()
+	}
+}
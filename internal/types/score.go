@@ -0,0 +1,23 @@
+package types
+
+import "fmt"
+
+// PackageScore holds Socket.dev's scores for a package version, each from
+// 0 to 100
+type PackageScore struct {
+	Package       string `json:"package"`
+	Version       string `json:"version"`
+	Overall       int    `json:"overall"`
+	SupplyChain   int    `json:"supply_chain"`
+	Quality       int    `json:"quality"`
+	Maintenance   int    `json:"maintenance"`
+	Vulnerability int    `json:"vulnerability"`
+	License       int    `json:"license"`
+}
+
+// Breakdown describes the component scores, e.g. "supply chain 98,
+// quality 71, maintenance 64, vulnerability 100, license 100"
+func (s PackageScore) Breakdown() string {
+	return fmt.Sprintf("supply chain %d, quality %d, maintenance %d, vulnerability %d, license %d",
+		s.SupplyChain, s.Quality, s.Maintenance, s.Vulnerability, s.License)
+}
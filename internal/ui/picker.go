@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PromptSelect lets the user pick one of options with the arrow keys and
+// enter. It returns the index picked, or false if the user cancelled with
+// q, esc or ctrl+c.
+func (u *UI) PromptSelect(message string, options []string) (int, bool) {
+	if len(options) == 0 {
+		return 0, false
+	}
+	m, err := tea.NewProgram(picker{message: message, options: options, color: u.useColor}).Run()
+	if err != nil {
+		return 0, false
+	}
+	p := m.(picker)
+	return p.cursor, p.chosen
+}
+
+// picker is the bubbletea model behind PromptSelect
+type picker struct {
+	message string
+	options []string
+	color   bool
+	cursor  int
+	chosen  bool
+	done    bool
+}
+
+func (p picker) Init() tea.Cmd {
+	return nil
+}
+
+func (p picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+	switch key.String() {
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.options)-1 {
+			p.cursor++
+		}
+	case "enter":
+		p.chosen, p.done = true, true
+		return p, tea.Quit
+	case "q", "esc", "ctrl+c":
+		p.done = true
+		return p, tea.Quit
+	}
+	return p, nil
+}
+
+func (p picker) View() string {
+	if p.done {
+		return ""
+	}
+	var b strings.Builder
+	message, hint := p.message, "(↑/↓ to move, enter to pick, q to cancel)"
+	if p.color {
+		message, hint = StyleBold.Render(message), StyleMuted.Render(hint)
+	}
+	fmt.Fprintf(&b, "%s %s\n", message, hint)
+	for i, option := range p.options {
+		switch {
+		case i != p.cursor:
+			fmt.Fprintf(&b, "    %s\n", option)
+		case p.color:
+			fmt.Fprintf(&b, "  %s %s\n", StyleCyan.Render(">"), StyleCyan.Render(option))
+		default:
+			fmt.Fprintf(&b, "  > %s\n", option)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPickerUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []tea.KeyType
+		cursor int
+		chosen bool
+	}{
+		{"enter picks the first", []tea.KeyType{tea.KeyEnter}, 0, true},
+		{"down moves", []tea.KeyType{tea.KeyDown, tea.KeyDown, tea.KeyEnter}, 2, true},
+		{"stops at the last", []tea.KeyType{tea.KeyDown, tea.KeyDown, tea.KeyDown, tea.KeyEnter}, 2, true},
+		{"stops at the first", []tea.KeyType{tea.KeyUp, tea.KeyDown, tea.KeyUp, tea.KeyUp, tea.KeyEnter}, 0, true},
+		{"esc cancels", []tea.KeyType{tea.KeyDown, tea.KeyEsc}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m tea.Model = picker{options: []string{"build", "dev", "test"}}
+			for _, k := range tt.keys {
+				m, _ = m.Update(tea.KeyMsg{Type: k})
+			}
+			p := m.(picker)
+			if p.cursor != tt.cursor || p.chosen != tt.chosen || !p.done {
+				t.Errorf("cursor = %d, chosen = %v, done = %v; want %d, %v, true", p.cursor, p.chosen, p.done, tt.cursor, tt.chosen)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spinnerFrames animate the line of a running scanner
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is how often the live display is redrawn
+const spinnerInterval = 100 * time.Millisecond
+
+// ScanProgress shows the state of each scanner while a scan runs. On a
+// color terminal it redraws a line per scanner with a spinner, the time
+// spent and, for scanners that count them, the packages done. Otherwise
+// it prints the static ScannerStatus lines.
+type ScanProgress struct {
+	ui   *UI
+	live bool
+
+	mu       sync.Mutex
+	scanners []*scannerState
+	drawn    int // lines drawn last time, redrawn in place
+	frame    int
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// scannerState is the progress of one scanner
+type scannerState struct {
+	name      string
+	start     time.Time
+	elapsed   time.Duration // set once done
+	completed int
+	total     int
+	done      bool
+}
+
+// StartScanProgress begins showing scanner progress. Pass Update as the
+// progress callback of a scan and call Stop once it returns.
+func (u *UI) StartScanProgress() *ScanProgress {
+	p := &ScanProgress{
+		ui:   u,
+		live: u.terminal && u.useColor && !u.quiet && !u.json,
+	}
+	if p.live {
+		p.stop = make(chan struct{})
+		p.stopped = make(chan struct{})
+		go p.animate()
+	}
+	return p
+}
+
+// Update records a scanner's progress: started, packages completed out of
+// total (both 0 if it does not count them), or done
+func (p *ScanProgress) Update(scanner string, completed, total int, done bool) {
+	if !p.live {
+		switch {
+		case done:
+			p.ui.ScannerStatus(scanner, "complete", false)
+		case completed == 0 && total == 0:
+			p.ui.ScannerStatus(scanner, "scanning...", true)
+		}
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.state(scanner)
+	if total > 0 {
+		s.completed, s.total = completed, total
+	}
+	if done && !s.done {
+		s.done = true
+		s.elapsed = time.Since(s.start)
+	}
+}
+
+// Stop draws the final state of every scanner and ends the animation
+func (p *ScanProgress) Stop() {
+	if !p.live {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+}
+
+// state returns the state of scanner, adding it on first use
+func (p *ScanProgress) state(scanner string) *scannerState {
+	for _, s := range p.scanners {
+		if s.name == scanner {
+			return s
+		}
+	}
+	s := &scannerState{name: scanner, start: time.Now()}
+	p.scanners = append(p.scanners, s)
+	return s
+}
+
+// animate redraws the display until Stop is called
+func (p *ScanProgress) animate() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.draw()
+		case <-p.stop:
+			p.draw()
+			return
+		}
+	}
+}
+
+// draw moves back over the lines drawn last time and writes one per scanner
+func (p *ScanProgress) draw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	if p.drawn > 0 {
+		fmt.Fprintf(&b, "\033[%dA", p.drawn)
+	}
+	spinner := StyleCyan.Render(spinnerFrames[p.frame%len(spinnerFrames)])
+	for _, s := range p.scanners {
+		b.WriteString("\r\033[K" + s.line(spinner) + "\n")
+	}
+	io.WriteString(p.ui.out, b.String())
+	p.drawn = len(p.scanners)
+	p.frame++
+}
+
+// line formats a scanner's progress, with spinner while it is running
+func (s *scannerState) line(spinner string) string {
+	if s.done {
+		return "  " + IconSuccess + " " + s.name + ": complete " + StyleMuted.Render(formatElapsed(s.elapsed))
+	}
+	status := "scanning..."
+	if s.total > 0 {
+		status = fmt.Sprintf("scanning... %d/%d packages", s.completed, s.total)
+	}
+	return "  " + spinner + " " + s.name + ": " + StyleMuted.Render(status+" "+formatElapsed(time.Since(s.start)))
+}
+
+// formatElapsed shows a duration to a tenth of a second, e.g. (3.2s)
+func formatElapsed(d time.Duration) string {
+	return fmt.Sprintf("(%.1fs)", d.Seconds())
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestScanProgressStatic(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewWriter(&buf, false, false, true).StartScanProgress()
+	p.Update("Google OSV", 0, 0, false)
+	p.Update("Google OSV", 1000, 2500, false)
+	p.Update("Google OSV", 0, 0, true)
+	p.Stop()
+
+	want := "  [...] Google OSV: scanning...\n  [OK] Google OSV: complete\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestScanProgressQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	u := &UI{quiet: true, out: &buf, errOut: &buf, terminal: true, useColor: true}
+	p := u.StartScanProgress()
+	p.Update("Google OSV", 0, 0, false)
+	p.Update("Google OSV", 0, 0, true)
+	p.Stop()
+
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want none", buf.String())
+	}
+}
+
+func TestScanProgressLive(t *testing.T) {
+	var buf bytes.Buffer
+	u := &UI{out: &buf, errOut: &buf, terminal: true, useColor: true}
+	p := u.StartScanProgress()
+	p.Update("Google OSV", 0, 0, false)
+	p.Update("Socket.dev", 0, 0, false)
+	p.Update("Google OSV", 1000, 2500, false)
+	p.draw()
+	p.Update("Socket.dev", 0, 0, true)
+	p.Stop()
+
+	out := buf.String()
+	for _, want := range []string{
+		"Google OSV: scanning... 1000/2500 packages (",
+		"Socket.dev: complete (",
+		"\033[2A", // redrawn over the two scanner lines
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want it to contain %q", out, want)
+		}
+	}
+}
@@ -1,9 +1,17 @@
 package ui
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+
+	"github.com/positronico/snapem/internal/redact"
 )
 
 var (
@@ -46,16 +54,167 @@ var (
 type UI struct {
 	verbose  bool
 	quiet    bool
-	useColor bool
+	useColor bool // style output on out
+	errColor bool // style output on errOut
+	out      io.Writer
+	errOut   io.Writer
+
+	// terminal allows progress lines to be redrawn in place
+	terminal bool
+
+	// json writes every message as a JSON event on errOut instead
+	json bool
 }
 
-// New creates a new UI instance
+// Log formats accepted by SetLogFormat
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// logFormat is ui.log_format (--log-format) for UIs created afterwards
+var logFormat = LogFormatText
+
+// SetLogFormat selects human-readable text or one JSON event per line for
+// UIs created afterwards. Unknown formats fall back to text.
+func SetLogFormat(format string) {
+	logFormat = LogFormatText
+	if format == LogFormatJSON {
+		logFormat = LogFormatJSON
+	}
+}
+
+// Fields are the structured details of a message, set as JSON event fields
+type Fields struct {
+	Scanner  string `json:"scanner,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Command  string `json:"command,omitempty"`
+}
+
+// event is a message in JSON log output
+type event struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Fields
+}
+
+// New creates a new UI instance writing to stdout and stderr. Each stream
+// is styled only if useColor is set, the environment allows color and the
+// stream is a terminal.
 func New(verbose, quiet, useColor bool) *UI {
+	jsonLog := logFormat == LogFormatJSON
+	color := colorEnabled(useColor) && !jsonLog
+	return &UI{
+		verbose:  verbose,
+		quiet:    quiet,
+		useColor: color && isTerminal(os.Stdout),
+		errColor: color && isTerminal(os.Stderr),
+		out:      os.Stdout,
+		errOut:   os.Stderr,
+		terminal: isTerminal(os.Stdout) && !jsonLog,
+		json:     jsonLog,
+	}
+}
+
+// NewWriter creates a UI instance that writes all output, including errors,
+// to w. Err reports whether the writes failed.
+func NewWriter(w io.Writer, verbose, quiet, useColor bool) *UI {
+	jsonLog := logFormat == LogFormatJSON
+	color := colorEnabled(useColor) && !jsonLog && isTerminal(w)
+	out := &errWriter{w: w}
 	return &UI{
 		verbose:  verbose,
 		quiet:    quiet,
-		useColor: useColor,
+		useColor: color,
+		errColor: color,
+		out:      out,
+		errOut:   out,
+		json:     jsonLog,
+	}
+}
+
+// Err returns the first error writing the output of a UI created by
+// NewWriter; later writes are skipped once one fails
+func (u *UI) Err() error {
+	if w, ok := u.out.(*errWriter); ok {
+		return w.err
+	}
+	return nil
+}
+
+// errWriter passes writes on to w until one fails, and keeps that error
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+// colorEnabled returns false if color is turned off by the caller or by the
+// environment: NO_COLOR (https://no-color.org) or TERM=dumb. Styles render
+// as plain text from then on, so nothing leaks escape sequences.
+func colorEnabled(useColor bool) bool {
+	if useColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" {
+		return true
 	}
+	lipgloss.SetColorProfile(termenv.Ascii)
+	return false
+}
+
+// isTerminal returns true if w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// ColorEnabled returns true if output written directly to w, outside a UI,
+// should be styled: useColor is set, the environment allows color and w
+// is a terminal
+func ColorEnabled(w io.Writer, useColor bool) bool {
+	return colorEnabled(useColor) && isTerminal(w)
+}
+
+// TerminalWidth returns the width in columns of the terminal w writes to,
+// or 0 if w is not a terminal
+func TerminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// emit writes a message: the formatted text to w, or in JSON mode a
+// single-line event with the level, message and fields to errOut
+func (u *UI) emit(w io.Writer, level, msg string, fields Fields, text string) {
+	if !u.json {
+		io.WriteString(w, text)
+		return
+	}
+	line, err := json.Marshal(event{
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Level:   level,
+		Message: msg,
+		Fields:  fields,
+	})
+	if err != nil {
+		return
+	}
+	u.errOut.Write(append(line, '\n'))
 }
 
 // Success prints a success message
@@ -63,32 +222,34 @@ func (u *UI) Success(msg string) {
 	if u.quiet {
 		return
 	}
+	text := "[OK] " + msg + "\n"
 	if u.useColor {
-		os.Stdout.WriteString(IconSuccess + " " + StyleSuccess.Render(msg) + "\n")
-	} else {
-		os.Stdout.WriteString("[OK] " + msg + "\n")
+		text = IconSuccess + " " + StyleSuccess.Render(msg) + "\n"
 	}
+	u.emit(u.out, "success", msg, Fields{}, text)
 }
 
 // Error prints an error message
 func (u *UI) Error(msg string) {
-	if u.useColor {
-		os.Stderr.WriteString(IconError + " " + StyleError.Render(msg) + "\n")
-	} else {
-		os.Stderr.WriteString("[ERROR] " + msg + "\n")
+	msg = redact.String(msg)
+	text := "[ERROR] " + msg + "\n"
+	if u.errColor {
+		text = IconError + " " + StyleError.Render(msg) + "\n"
 	}
+	u.emit(u.errOut, "error", msg, Fields{}, text)
 }
 
 // Warning prints a warning message
 func (u *UI) Warning(msg string) {
+	msg = redact.String(msg)
 	if u.quiet {
 		return
 	}
+	text := "[WARN] " + msg + "\n"
 	if u.useColor {
-		os.Stdout.WriteString(IconWarning + " " + StyleWarning.Render(msg) + "\n")
-	} else {
-		os.Stdout.WriteString("[WARN] " + msg + "\n")
+		text = IconWarning + " " + StyleWarning.Render(msg) + "\n"
 	}
+	u.emit(u.out, "warn", msg, Fields{}, text)
 }
 
 // Info prints an info message
@@ -96,31 +257,44 @@ func (u *UI) Info(msg string) {
 	if u.quiet {
 		return
 	}
+	text := msg + "\n"
 	if u.useColor {
-		os.Stdout.WriteString(StyleInfo.Render(msg) + "\n")
-	} else {
-		os.Stdout.WriteString(msg + "\n")
+		text = StyleInfo.Render(msg) + "\n"
 	}
+	u.emit(u.out, "info", msg, Fields{}, text)
 }
 
 // Verbose prints a message only in verbose mode
 func (u *UI) Verbose(msg string) {
+	msg = redact.String(msg)
 	if !u.verbose {
 		return
 	}
+	text := msg + "\n"
 	if u.useColor {
-		os.Stdout.WriteString(StyleMuted.Render(msg) + "\n")
-	} else {
-		os.Stdout.WriteString(msg + "\n")
+		text = StyleMuted.Render(msg) + "\n"
 	}
+	u.emit(u.out, "debug", msg, Fields{}, text)
 }
 
-// Print prints a plain message
+// Print prints a plain message. Command results go through Print, so it
+// writes to stdout even in JSON mode; blank spacing lines are dropped there.
 func (u *UI) Print(msg string) {
-	if u.quiet {
+	if u.quiet || (u.json && msg == "") {
 		return
 	}
-	os.Stdout.WriteString(msg + "\n")
+	io.WriteString(u.out, msg+"\n")
+}
+
+// SimulationBanner marks output as synthetic. It is written to stderr and
+// shown even in quiet mode so simulated results are never mistaken for real ones.
+func (u *UI) SimulationBanner(mode string) {
+	msg := "SIMULATION MODE (" + mode + "): injected failure, results below are not real"
+	text := "[SIMULATION] " + msg + "\n"
+	if u.errColor {
+		text = IconWarning + " " + StyleWarning.Render(msg) + "\n"
+	}
+	u.emit(u.errOut, "warn", msg, Fields{}, text)
 }
 
 // ScanningHeader prints the scanning header
@@ -128,11 +302,11 @@ func (u *UI) ScanningHeader() {
 	if u.quiet {
 		return
 	}
+	text := "\n[SCAN] Security Scan\n"
 	if u.useColor {
-		os.Stdout.WriteString("\n" + IconShield + " " + StyleBold.Render("Security Scan") + "\n")
-	} else {
-		os.Stdout.WriteString("\n[SCAN] Security Scan\n")
+		text = "\n" + IconShield + " " + StyleBold.Render("Security Scan") + "\n"
 	}
+	u.emit(u.out, "info", "Security Scan", Fields{}, text)
 }
 
 // ScannerStatus prints status for a specific scanner
@@ -141,56 +315,180 @@ func (u *UI) ScannerStatus(scanner string, status string, isRunning bool) {
 		return
 	}
 	prefix := "  "
+	var text string
 	if isRunning {
 		if u.useColor {
-			os.Stdout.WriteString(prefix + IconScanning + " " + scanner + ": " + StyleMuted.Render(status) + "\n")
+			text = prefix + IconScanning + " " + scanner + ": " + StyleMuted.Render(status) + "\n"
 		} else {
-			os.Stdout.WriteString(prefix + "[...] " + scanner + ": " + status + "\n")
+			text = prefix + "[...] " + scanner + ": " + status + "\n"
 		}
 	} else {
 		if u.useColor {
-			os.Stdout.WriteString(prefix + IconSuccess + " " + scanner + ": " + status + "\n")
+			text = prefix + IconSuccess + " " + scanner + ": " + status + "\n"
 		} else {
-			os.Stdout.WriteString(prefix + "[OK] " + scanner + ": " + status + "\n")
+			text = prefix + "[OK] " + scanner + ": " + status + "\n"
 		}
 	}
+	u.emit(u.out, "info", scanner+": "+status, Fields{Scanner: scanner, Status: status}, text)
 }
 
-// ThreatFound prints a threat message
-func (u *UI) ThreatFound(severity, pkg, desc string) {
+// Progress redraws a status line in place on a terminal; elsewhere it
+// prints nothing, and only ProgressDone's final line is shown
+func (u *UI) Progress(msg string) {
+	if u.quiet || !u.terminal {
+		return
+	}
+	io.WriteString(u.out, "\r\033[K  "+msg)
+}
+
+// ProgressDone replaces the status line drawn by Progress with msg
+func (u *UI) ProgressDone(msg string) {
 	if u.quiet {
 		return
 	}
-	var style lipgloss.Style
+	text := "  " + msg + "\n"
+	if u.terminal {
+		text = "\r\033[K" + text
+	}
+	u.emit(u.out, "info", msg, Fields{}, text)
+}
+
+// SeverityStyle returns the style a severity is shown in
+func SeverityStyle(severity string) lipgloss.Style {
 	switch severity {
 	case "critical":
-		style = StyleCritical
+		return StyleCritical
 	case "high":
-		style = StyleHigh
+		return StyleHigh
 	case "medium":
-		style = StyleMedium
-	default:
-		style = StyleLow
+		return StyleMedium
 	}
+	return StyleLow
+}
 
+// ThreatFound prints a threat message
+func (u *UI) ThreatFound(severity, pkg, desc string) {
+	if u.quiet {
+		return
+	}
+	style := SeverityStyle(severity)
+
+	text := "  [" + severity + "] " + pkg + "\n" + "    " + desc + "\n"
 	if u.useColor {
-		os.Stdout.WriteString("  " + style.Render("▶ "+severity) + " " + StyleBold.Render(pkg) + "\n")
-		os.Stdout.WriteString("    " + StyleMuted.Render(desc) + "\n")
-	} else {
-		os.Stdout.WriteString("  [" + severity + "] " + pkg + "\n")
-		os.Stdout.WriteString("    " + desc + "\n")
+		text = "  " + style.Render("▶ "+severity) + " " + StyleBold.Render(pkg) + "\n" +
+			"    " + StyleMuted.Render(desc) + "\n"
+	}
+	u.emit(u.out, "warn", desc, Fields{Package: pkg, Severity: severity}, text)
+}
+
+// FindingGroup prints the header of a package's findings: the package,
+// padded to width, whether it is a direct or transitive dependency, its
+// worst severity and how many findings it has
+func (u *UI) FindingGroup(pkg, dependency, severity string, count, width int) {
+	if u.quiet {
+		return
+	}
+	findings := fmt.Sprintf("%d findings", count)
+	if count == 1 {
+		findings = "1 finding"
 	}
+	text := fmt.Sprintf("  %-*s  %-10s  %-8s  %s\n", width, pkg, dependency, severity, findings)
+	if u.useColor {
+		text = "  " + StyleBold.Render(fmt.Sprintf("%-*s", width, pkg)) + "  " +
+			StyleMuted.Render(fmt.Sprintf("%-10s", dependency)) + "  " +
+			SeverityStyle(severity).Render(fmt.Sprintf("%-8s", severity)) + "  " + findings + "\n"
+	}
+	u.emit(u.out, "info", pkg+": "+findings, Fields{Package: pkg, Severity: severity}, text)
+}
+
+// GroupedThreat prints a finding under its FindingGroup header, followed
+// by any details such as remediation advice
+func (u *UI) GroupedThreat(severity, pkg, desc string, details ...string) {
+	if u.quiet {
+		return
+	}
+	text := "    [" + severity + "] " + desc + "\n"
+	if u.useColor {
+		text = "    " + SeverityStyle(severity).Render("▶ "+severity) + " " + desc + "\n"
+	}
+	for _, detail := range details {
+		if u.useColor {
+			detail = StyleMuted.Render(detail)
+		}
+		text += "      " + detail + "\n"
+	}
+	u.emit(u.out, "warn", desc, Fields{Package: pkg, Severity: severity}, text)
+}
+
+// HistoryEntry prints an audit log entry: when and where a command ran,
+// its decision, and detail lines beneath
+func (u *UI) HistoryEntry(when, command, decision, project string, details ...string) {
+	if u.quiet {
+		return
+	}
+	text := fmt.Sprintf("%s  %-7s  %-7s  %s\n", when, command, decision, project)
+	if u.useColor {
+		style := StyleSuccess
+		switch decision {
+		case "blocked":
+			style = StyleError
+		case "forced":
+			style = StyleWarning
+		}
+		text = StyleMuted.Render(when) + "  " + StyleBold.Render(fmt.Sprintf("%-7s", command)) + "  " +
+			style.Render(fmt.Sprintf("%-7s", decision)) + "  " + project + "\n"
+	}
+	for _, detail := range details {
+		if u.useColor {
+			detail = StyleMuted.Render(detail)
+		}
+		text += "    " + detail + "\n"
+	}
+	u.emit(u.out, "info", command+" "+decision+": "+project, Fields{}, text)
+}
+
+// ScriptEntry prints a package.json script: its name, padded to width,
+// its command and a note such as the port a dev server is published on
+func (u *UI) ScriptEntry(name, command, note string, width int) {
+	if u.quiet {
+		return
+	}
+	text := fmt.Sprintf("  %-*s  %s", width, name, command)
+	if note != "" {
+		text += "  (" + note + ")"
+	}
+	if u.useColor {
+		text = "  " + StyleBold.Render(fmt.Sprintf("%-*s", width, name)) + "  " + StyleMuted.Render(command)
+		if note != "" {
+			text += "  " + StyleCyan.Render(note)
+		}
+	}
+	u.emit(u.out, "info", name+": "+command, Fields{Command: command}, text+"\n")
+}
+
+// ThreatDetail prints an extra line under a threat, such as remediation
+// advice
+func (u *UI) ThreatDetail(detail string) {
+	if u.quiet {
+		return
+	}
+	text := "    " + detail + "\n"
+	if u.useColor {
+		text = "    " + StyleMuted.Render(detail) + "\n"
+	}
+	u.emit(u.out, "info", detail, Fields{}, text)
 }
 
 // ContainerHeader prints the container execution header
 func (u *UI) ContainerHeader(cmd string) {
+	cmd = redact.String(cmd)
 	if u.quiet {
 		return
 	}
+	text := "\n[CONTAINER] " + cmd + "\n\n"
 	if u.useColor {
-		os.Stdout.WriteString("\n" + IconLock + " " + StyleBold.Render("Container Execution") + "\n")
-		os.Stdout.WriteString("  " + StyleMuted.Render(cmd) + "\n\n")
-	} else {
-		os.Stdout.WriteString("\n[CONTAINER] " + cmd + "\n\n")
+		text = "\n" + IconLock + " " + StyleBold.Render("Container Execution") + "\n" +
+			"  " + StyleMuted.Render(cmd) + "\n\n"
 	}
+	u.emit(u.out, "info", "Container Execution", Fields{Command: cmd}, text)
 }
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONLogFormat(t *testing.T) {
+	SetLogFormat(LogFormatJSON)
+	t.Cleanup(func() { SetLogFormat(LogFormatText) })
+
+	var buf bytes.Buffer
+	u := NewWriter(&buf, false, false, true)
+	u.Success("Installed")
+	u.ScannerStatus("OSV", "2 vulnerabilities", false)
+	u.ThreatFound("high", "lodash@4.17.20", "Prototype pollution")
+	u.ContainerHeader("docker run --env NPM_TOKEN=abc123 node:lts-slim npm ci")
+	u.Verbose("hidden unless verbose")
+	u.Print("")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []map[string]string{
+		{"level": "success", "message": "Installed"},
+		{"level": "info", "scanner": "OSV", "status": "2 vulnerabilities"},
+		{"level": "warn", "message": "Prototype pollution", "package": "lodash@4.17.20", "severity": "high"},
+		{"level": "info", "command": "docker run --env NPM_TOKEN=**** node:lts-slim npm ci"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i, line := range lines {
+		var got map[string]string
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d is not JSON: %q", i, line)
+		}
+		if got["time"] == "" {
+			t.Errorf("line %d has no time", i)
+		}
+		for k, v := range want[i] {
+			if got[k] != v {
+				t.Errorf("line %d: %s = %q, want %q", i, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestTextLogFormat(t *testing.T) {
+	var buf bytes.Buffer
+	u := NewWriter(&buf, false, false, false)
+	u.ThreatFound("high", "lodash@4.17.20", "Prototype pollution")
+
+	want := "  [high] lodash@4.17.20\n    Prototype pollution\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		useColor bool
+		noColor  string
+		term     string
+		want     bool
+	}{
+		{"enabled", true, "", "xterm-256color", true},
+		{"turned off", false, "", "xterm-256color", false},
+		{"NO_COLOR", true, "1", "xterm-256color", false},
+		{"dumb terminal", true, "", "dumb", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("TERM", tt.term)
+			if got := colorEnabled(tt.useColor); got != tt.want {
+				t.Errorf("colorEnabled(%v) = %v, want %v", tt.useColor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWriterNotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	u := NewWriter(&buf, false, false, true)
+	u.Success("Installed")
+
+	if got := buf.String(); got != "[OK] Installed\n" {
+		t.Errorf("output = %q, want plain text for a non-terminal writer", got)
+	}
+}
@@ -0,0 +1,264 @@
+// Package verify checks the resolved URLs and integrity hashes recorded in
+// a lockfile against what the npm registry currently publishes.
+package verify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+// ErrUnreachable is returned when the registry cannot be reached at all
+var ErrUnreachable = registry.ErrUnreachable
+
+// Result is the outcome of verifying a set of packages
+type Result struct {
+	// Verified counts packages whose lockfile entry matches the registry
+	Verified int
+
+	// Skipped lists packages with nothing to compare: no integrity hash,
+	// a non-registry source, or no hash algorithm in common
+	Skipped []manifest.Package
+
+	// Missing lists packages the registry does not know
+	Missing []manifest.Package
+
+	// Mismatches holds a critical finding per package whose lockfile
+	// entry disagrees with the registry
+	Mismatches []types.Finding
+}
+
+// Verifier compares lockfile entries with registry metadata
+type Verifier struct {
+	metadata *registry.Client
+	workers  int
+	timeout  time.Duration
+}
+
+// NewVerifier creates a verifier that reads version documents through
+// metadata
+func NewVerifier(cfg config.VerifyConfig, metadata *registry.Client) *Verifier {
+	workers := cfg.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Verifier{
+		metadata: metadata,
+		workers:  workers,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// outcome is the result of checking one package
+type outcome int
+
+const (
+	verified outcome = iota
+	skipped
+	mismatched
+)
+
+// Verify checks every package, fetching each name@version once with
+// bounded concurrency. It returns an error wrapping ErrUnreachable if any
+// registry request fails at the transport level; the remaining requests are
+// abandoned.
+func (v *Verifier) Verify(ctx context.Context, packages []manifest.Package) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Lockfiles list a package once per install location
+	fetches := make(map[string]*versionFetch)
+	for _, pkg := range packages {
+		key := pkg.Name + "@" + pkg.Version
+		if _, ok := fetches[key]; !ok && comparable(pkg) {
+			fetches[key] = &versionFetch{pkg: pkg}
+		}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, v.workers)
+	for _, f := range fetches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var err error
+			if f.dist, err = v.fetch(ctx, f.pkg); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	result := &Result{}
+	for _, pkg := range packages {
+		f, ok := fetches[pkg.Name+"@"+pkg.Version]
+		if !ok {
+			result.Skipped = append(result.Skipped, pkg)
+			continue
+		}
+		if f.dist == nil {
+			result.Missing = append(result.Missing, pkg)
+			continue
+		}
+
+		switch outcome, finding := v.check(pkg, f.dist); outcome {
+		case verified:
+			result.Verified++
+		case skipped:
+			result.Skipped = append(result.Skipped, pkg)
+		case mismatched:
+			result.Mismatches = append(result.Mismatches, *finding)
+		}
+	}
+	return result, nil
+}
+
+// versionFetch holds the registry response for one name@version
+type versionFetch struct {
+	pkg  manifest.Package
+	dist *registry.Dist
+}
+
+// comparable returns true if the lockfile entry has a hash and, when it
+// records a source, that source is a registry tarball
+func comparable(pkg manifest.Package) bool {
+	if pkg.Integrity == "" {
+		return false
+	}
+	return pkg.Resolved == "" || strings.HasPrefix(pkg.Resolved, "http://") || strings.HasPrefix(pkg.Resolved, "https://")
+}
+
+// check compares one lockfile entry with the registry's dist metadata
+func (v *Verifier) check(pkg manifest.Package, d *registry.Dist) (outcome, *types.Finding) {
+	// Tarball URLs are only comparable when the lockfile resolved against
+	// the registry being queried, not a mirror
+	if pkg.Resolved != "" && sameHost(pkg.Resolved, v.metadata.URL(pkg.Name)) && pkg.Resolved != d.Tarball {
+		return mismatched, &types.Finding{
+			Package:     pkg.Name,
+			Version:     pkg.Version,
+			Type:        types.FindingTypeIntegrity,
+			Severity:    types.SeverityCritical,
+			Title:       "Resolved URL does not match the registry",
+			Description: fmt.Sprintf("lockfile resolves %s, registry publishes %s", pkg.Resolved, d.Tarball),
+			Remediation: mismatchRemediation,
+		}
+	}
+
+	match, ok := integrityMatches(pkg.Integrity, registryHashes(d))
+	if !ok {
+		return skipped, nil
+	}
+	if !match {
+		return mismatched, &types.Finding{
+			Package:     pkg.Name,
+			Version:     pkg.Version,
+			Type:        types.FindingTypeIntegrity,
+			Severity:    types.SeverityCritical,
+			Title:       "Integrity hash does not match the registry",
+			Description: fmt.Sprintf("lockfile records %s, registry publishes %s", pkg.Integrity, d.Integrity),
+			Remediation: mismatchRemediation,
+		}
+	}
+	return verified, nil
+}
+
+const mismatchRemediation = "Do not install. Find out who changed package-lock.json, and regenerate the entry from the registry if the change is unexpected"
+
+// registryHashes returns the registry's digests by algorithm. Old versions
+// only publish a hex sha1 shasum.
+func registryHashes(d *registry.Dist) map[string][]string {
+	hashes := parseSRI(d.Integrity)
+	if d.Shasum != "" {
+		if raw, err := hex.DecodeString(d.Shasum); err == nil {
+			hashes["sha1"] = append(hashes["sha1"], base64.StdEncoding.EncodeToString(raw))
+		}
+	}
+	return hashes
+}
+
+// parseSRI splits a subresource integrity string, such as
+// "sha512-abc== sha1-def=", into digests by algorithm
+func parseSRI(sri string) map[string][]string {
+	hashes := make(map[string][]string)
+	for _, token := range strings.Fields(sri) {
+		token, _, _ = strings.Cut(token, "?")
+		alg, digest, ok := strings.Cut(token, "-")
+		if ok && digest != "" {
+			hashes[alg] = append(hashes[alg], digest)
+		}
+	}
+	return hashes
+}
+
+// integrityMatches compares the lockfile hashes with the registry's on the
+// algorithms both record. ok is false if they share none.
+func integrityMatches(lockfile string, registry map[string][]string) (match, ok bool) {
+	for alg, digests := range parseSRI(lockfile) {
+		published, found := registry[alg]
+		if !found {
+			continue
+		}
+		ok = true
+		for _, digest := range digests {
+			for _, p := range published {
+				if digest == p {
+					return true, true
+				}
+			}
+		}
+	}
+	return false, ok
+}
+
+// sameHost returns true if both URLs have the same host
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Host, ub.Host)
+}
+
+// fetch returns the dist metadata for the package version, or nil if the
+// registry does not know it
+func (v *Verifier) fetch(ctx context.Context, pkg manifest.Package) (*registry.Dist, error) {
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	doc, err := v.metadata.Version(ctx, pkg.Name, pkg.Version)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return &doc.Dist, nil
+}
@@ -0,0 +1,107 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/positronico/snapem/internal/config"
+	"github.com/positronico/snapem/internal/manifest"
+	"github.com/positronico/snapem/internal/registry"
+	"github.com/positronico/snapem/internal/types"
+)
+
+func TestVerify(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lodash/4.17.21":
+			fmt.Fprintf(w, `{"dist": {"tarball": "%s/lodash/-/lodash-4.17.21.tgz", "integrity": "sha512-good==", "shasum": "0123abcd"}}`, server.URL)
+		case "/left-pad/1.3.0":
+			fmt.Fprintf(w, `{"dist": {"tarball": "%s/left-pad/-/left-pad-1.3.0.tgz", "integrity": "sha512-good=="}}`, server.URL)
+		case "/debug/2.6.9":
+			fmt.Fprintf(w, `{"dist": {"tarball": "%s/debug/-/debug-2.6.9.tgz", "integrity": "sha512-good=="}}`, server.URL)
+		case "/old/1.0.0":
+			fmt.Fprintf(w, `{"dist": {"tarball": "%s/old/-/old-1.0.0.tgz", "shasum": "0123abcd"}}`, server.URL)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	v := NewVerifier(config.VerifyConfig{Concurrency: 4}, registry.NewClient(registry.Registries{Default: server.URL}))
+
+	packages := []manifest.Package{
+		{Name: "lodash", Version: "4.17.21", Resolved: server.URL + "/lodash/-/lodash-4.17.21.tgz", Integrity: "sha512-good=="},
+		{Name: "lodash", Version: "4.17.21", Resolved: server.URL + "/lodash/-/lodash-4.17.21.tgz", Integrity: "sha512-good=="},
+		{Name: "left-pad", Version: "1.3.0", Resolved: server.URL + "/left-pad/-/left-pad-1.3.0.tgz", Integrity: "sha512-evil=="},
+		{Name: "debug", Version: "2.6.9", Resolved: server.URL + "/evil/-/debug-2.6.9.tgz", Integrity: "sha512-good=="},
+		{Name: "old", Version: "1.0.0", Resolved: "https://mirror.example.com/old-1.0.0.tgz", Integrity: "sha1-ASOrzQ=="},
+		{Name: "mine", Version: "1.0.0", Resolved: "file:../mine"},
+		{Name: "private", Version: "1.0.0", Integrity: "sha512-good=="},
+	}
+
+	result, err := v.Verify(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if result.Verified != 3 {
+		t.Errorf("Verified = %d, want 3 (lodash twice, old by shasum)", result.Verified)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Name != "mine" {
+		t.Errorf("Skipped = %v, want [mine]", result.Skipped)
+	}
+	if len(result.Missing) != 1 || result.Missing[0].Name != "private" {
+		t.Errorf("Missing = %v, want [private]", result.Missing)
+	}
+
+	if len(result.Mismatches) != 2 {
+		t.Fatalf("Mismatches = %+v, want 2", result.Mismatches)
+	}
+	for i, want := range []string{"Integrity hash does not match the registry", "Resolved URL does not match the registry"} {
+		f := result.Mismatches[i]
+		if f.Title != want || f.Type != types.FindingTypeIntegrity || f.Severity != types.SeverityCritical {
+			t.Errorf("Mismatches[%d] = %+v, want %q", i, f, want)
+		}
+	}
+}
+
+func TestVerifyUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	metadata := registry.NewClient(registry.Registries{Default: server.URL})
+	metadata.SetHTTPClient(http.DefaultClient)
+	v := NewVerifier(config.VerifyConfig{Concurrency: 1}, metadata)
+
+	_, err := v.Verify(context.Background(), []manifest.Package{{Name: "lodash", Version: "4.17.21", Integrity: "sha512-good=="}})
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Verify() error = %v, want ErrUnreachable", err)
+	}
+}
+
+func TestIntegrityMatches(t *testing.T) {
+	registry := map[string][]string{"sha512": {"abc=="}, "sha1": {"def="}}
+
+	tests := []struct {
+		lockfile  string
+		match, ok bool
+	}{
+		{"sha512-abc==", true, true},
+		{"sha512-xyz==", false, true},
+		{"sha1-def=", true, true},
+		{"sha256-abc==", false, false},
+		{"sha256-abc== sha512-abc==", true, true},
+	}
+
+	for _, tt := range tests {
+		match, ok := integrityMatches(tt.lockfile, registry)
+		if match != tt.match || ok != tt.ok {
+			t.Errorf("integrityMatches(%q) = %v, %v, want %v, %v", tt.lockfile, match, ok, tt.match, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package report_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/positronico/snapem/pkg/report/reporttest"
+)
+
+// ticketRenderer is a renderer written outside snapem, using only the
+// names pkg/report exports
+type ticketRenderer struct{}
+
+func (ticketRenderer) Render(w io.Writer, r *report.Report) error {
+	blockers, others := 0, 0
+	for _, f := range r.Result.AllFindings() {
+		p := priority(f)
+		if p == "P0" || p == "P1" {
+			blockers++
+		} else {
+			others++
+		}
+		if _, err := fmt.Fprintf(w, "%s %s@%s: %s\n", p, f.Package, f.Version, f.Title); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d blocker(s), %d other finding(s)\n", blockers, others)
+	return err
+}
+
+// priority maps a finding to a ticket priority
+func priority(f report.Finding) string {
+	switch f.Severity {
+	case report.SeverityCritical:
+		if f.Type == report.FindingTypeMalware {
+			return "P0"
+		}
+		return "P1"
+	case report.SeverityHigh:
+		return "P1"
+	case report.SeverityMedium:
+		return "P2"
+	default:
+		return "P3"
+	}
+}
+
+func TestTicketRenderer(t *testing.T) {
+	reporttest.Run(t, ticketRenderer{})
+}
+
+func Example_renderer() {
+	r := &report.Report{
+		Result: &report.AggregatedResult{
+			Results: []*report.ScanResult{{
+				Scanner:  "Google OSV",
+				Packages: 2,
+				Findings: []report.Finding{
+					{Package: "lodash", Version: "4.17.20", Type: report.FindingTypeCVE, Severity: report.SeverityHigh, Title: "Command Injection in lodash"},
+					{Package: "minimist", Version: "1.2.5", Type: report.FindingTypeCVE, Severity: report.SeverityLow, Title: "Prototype Pollution in minimist"},
+				},
+			}},
+		},
+	}
+
+	ticketRenderer{}.Render(os.Stdout, r)
+	// Output:
+	// P1 lodash@4.17.20: Command Injection in lodash
+	// P3 minimist@1.2.5: Prototype Pollution in minimist
+	// 1 blocker(s), 1 other finding(s)
+}
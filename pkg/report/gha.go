@@ -0,0 +1,68 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// GHAMatcher is the GitHub Actions problem matcher for the gha-matcher
+// format. Its owner is GHAMatcherOwner.
+//
+//go:embed gha-matcher.json
+var GHAMatcher []byte
+
+// GHAMatcherOwner is the owner name used to add and remove the matcher
+const GHAMatcherOwner = "snapem"
+
+// ghaManifest is the file findings are attributed to
+const ghaManifest = "package.json"
+
+// ghaMatcherRenderer writes one line per finding in the form matched by
+// GHAMatcher:
+//
+//	snapem: package.json: error: [GHSA-35jh-r3h4-6jhm] lodash@4.17.20 (high): Command Injection in lodash
+type ghaMatcherRenderer struct{}
+
+// Render writes the finding lines followed by an unmatched summary line
+func (ghaMatcherRenderer) Render(w io.Writer, r *Report) error {
+	result := r.Result
+
+	for _, f := range result.AllFindings() {
+		id := f.DisplayID()
+		if id == "" {
+			id = string(f.Type)
+		}
+		if _, err := fmt.Fprintf(w, "snapem: %s: %s: [%s] %s@%s (%s): %s\n",
+			ghaManifest, ghaLevel(f), id, f.Package, f.Version, f.Severity, ghaMessage(f)); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "snapem: %d finding(s) in %d package(s)\n", result.TotalFindings, result.TotalPackages)
+	return err
+}
+
+// ghaLevel maps a finding to an annotation level. Malware and high or
+// critical vulnerabilities are errors; everything else is a warning.
+func ghaLevel(f types.Finding) string {
+	if f.Type == types.FindingTypeMalware || f.Type == types.FindingTypeTyposquat {
+		return "error"
+	}
+	if f.Severity == types.SeverityCritical || f.Severity == types.SeverityHigh {
+		return "error"
+	}
+	return "warning"
+}
+
+// ghaMessage returns a single-line message for a finding
+func ghaMessage(f types.Finding) string {
+	msg := f.Title
+	if msg == "" {
+		msg = f.Description
+	}
+	return strings.Join(strings.Fields(msg), " ")
+}
@@ -0,0 +1,68 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/positronico/snapem/pkg/report/reporttest"
+)
+
+func TestGHAMatcherLinesMatch(t *testing.T) {
+	var matcher struct {
+		ProblemMatcher []struct {
+			Owner   string `json:"owner"`
+			Pattern []struct {
+				Regexp   string `json:"regexp"`
+				File     int    `json:"file"`
+				Severity int    `json:"severity"`
+				Code     int    `json:"code"`
+				Message  int    `json:"message"`
+			} `json:"pattern"`
+		} `json:"problemMatcher"`
+	}
+	if err := json.Unmarshal(report.GHAMatcher, &matcher); err != nil {
+		t.Fatalf("matcher is not valid JSON: %v", err)
+	}
+	if matcher.ProblemMatcher[0].Owner != report.GHAMatcherOwner {
+		t.Errorf("owner = %q, want %q", matcher.ProblemMatcher[0].Owner, report.GHAMatcherOwner)
+	}
+	p := matcher.ProblemMatcher[0].Pattern[0]
+	re := regexp.MustCompile(p.Regexp)
+
+	r, err := report.Get("gha-matcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := r.Render(&buf, reporttest.CannedReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	type annotation struct{ file, severity, code, message string }
+	var got []annotation
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		got = append(got, annotation{m[p.File], m[p.Severity], m[p.Code], m[p.Message]})
+	}
+
+	want := []annotation{
+		{"package.json", "error", "socket-123", "evil-pkg@1.0.0 (critical): malware"},
+		{"package.json", "error", "GHSA-35jh-r3h4-6jhm", "lodash@4.17.20 (high): Command Injection in lodash"},
+		{"package.json", "warning", "GHSA-xvch-5gv4-984h", "minimist@1.2.5 (low): Prototype Pollution in minimist"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("matched %d lines, want %d:\n%s", len(got), len(want), buf.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("annotation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// SchemaVersion is the version of the JSON document layout. Hook payloads
+// embed the same document and share this version; bump it on any
+// incompatible change to either.
+const SchemaVersion = 1
+
+// jsonRenderer renders the machine-readable JSON report
+type jsonRenderer struct{}
+
+// Document is the JSON document written by the json format
+type Document struct {
+	SchemaVersion int             `json:"schema_version"`
+	Packages      int             `json:"packages_scanned"`
+	CacheHits     int             `json:"cache_hits"`
+	Findings      []types.Finding `json:"findings"`
+	Summary       Summary         `json:"summary"`
+
+	Freshness []types.DependencyHealth `json:"freshness,omitempty"`
+
+	// Coverage counts how each scanner handled each package
+	Coverage *types.CoverageSummary `json:"coverage,omitempty"`
+
+	// ScannerErrors lists scanners that failed; the findings are partial
+	ScannerErrors []types.ScannerError `json:"scanner_errors,omitempty"`
+
+	// Baselined lists findings acknowledged in the baseline file, left out
+	// of Findings and Summary
+	Baselined []types.Finding `json:"baselined,omitempty"`
+
+	// Scores lists the Socket.dev score of each package that has one
+	Scores []types.PackageScore `json:"scores,omitempty"`
+}
+
+// Summary holds finding counts by severity and category
+type Summary struct {
+	Total    int `json:"total"`
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Malware  int `json:"malware"`
+	License  int `json:"license"`
+
+	InstallScripts int `json:"install_scripts"`
+	Provenance     int `json:"provenance"`
+}
+
+// NewDocument builds the JSON document for a scan result. Findings and
+// dependencies are sorted, so the same scan gives the same document.
+func NewDocument(result *types.AggregatedResult) *Document {
+	doc := &Document{
+		SchemaVersion: SchemaVersion,
+		Packages:      result.TotalPackages,
+		CacheHits:     result.CacheHits,
+		Findings:      sortedFindings(result.AllFindings()),
+		Summary: Summary{
+			Total:    result.TotalFindings,
+			Critical: result.CountBySeverity(types.SeverityCritical),
+			High:     result.CountBySeverity(types.SeverityHigh),
+			Medium:   result.CountBySeverity(types.SeverityMedium),
+			Low:      result.CountBySeverity(types.SeverityLow),
+			Malware:  result.CountByType(types.FindingTypeMalware) + result.CountByType(types.FindingTypeTyposquat),
+			License:  result.CountByType(types.FindingTypeLicense),
+
+			InstallScripts: result.CountByType(types.FindingTypeInstallScript),
+			Provenance:     result.CountByType(types.FindingTypeProvenance),
+		},
+		Freshness:     sortedHealth(result.Freshness),
+		ScannerErrors: result.Errors,
+		Baselined:     sortedFindings(result.Baselined),
+		Scores:        result.Scores,
+	}
+	if result.Coverage != nil {
+		summary := result.Coverage.Summary()
+		doc.Coverage = &summary
+	}
+	return doc
+}
+
+// sortedHealth returns a copy of health sorted by package and version
+func sortedHealth(health []types.DependencyHealth) []types.DependencyHealth {
+	if health == nil {
+		return nil
+	}
+	sorted := slices.Clone(health)
+	slices.SortStableFunc(sorted, func(a, b types.DependencyHealth) int {
+		return cmp.Or(cmp.Compare(a.Package, b.Package), cmp.Compare(a.Version, b.Version))
+	})
+	return sorted
+}
+
+// Render writes the report as indented JSON
+func (jsonRenderer) Render(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewDocument(r.Result))
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownRenderer writes a summary line and a GitHub-flavored markdown
+// table of the findings, for pasting into pull requests and comments
+type markdownRenderer struct{}
+
+// Render writes the summary line, followed by the table when there are
+// findings. Advisory IDs link to the advisory's first reference.
+func (markdownRenderer) Render(w io.Writer, r *Report) error {
+	findings := tableFindings(r.Result)
+	if len(findings) == 0 {
+		_, err := fmt.Fprintf(w, "**snapem:** no security issues found in %d packages%s\n", r.Result.TotalPackages, baselineNote(r.Result))
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**snapem:** %s\n\n", findingSummary(r.Result, findings))
+	b.WriteString("| Package | Version | Severity | ID | Title | Remediation |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, f := range findings {
+		id := markdownEscape(findingID(f))
+		if len(f.References) > 0 {
+			id = "[" + id + "](" + f.References[0] + ")"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			f.Package,
+			markdownEscape(f.Version),
+			f.Severity,
+			id,
+			markdownEscape(oneLine(findingTitle(f))),
+			markdownEscape(oneLine(f.Remediation)))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape keeps text from breaking out of a table cell or being
+// read as markup
+var markdownEscape = strings.NewReplacer(
+	`|`, `\|`,
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`<`, `&lt;`,
+	`>`, `&gt;`,
+	`[`, `\[`,
+	`]`, `\]`,
+).Replace
@@ -0,0 +1,38 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/positronico/snapem/pkg/report/reporttest"
+)
+
+func TestMarkdown(t *testing.T) {
+	renderer, err := report.Get("markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := reporttest.CannedReport()
+	r.Result.Results[1].Findings[1].Title = "Pollution via __proto__ | constructor"
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, r); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"**snapem:** 3 findings in 3 packages (1 critical, 1 high, 1 low)",
+		"",
+		"| Package | Version | Severity | ID | Title | Remediation |",
+		"| --- | --- | --- | --- | --- | --- |",
+		"| `evil-pkg` | 1.0.0 | critical | socket-123 | Known malicious package |  |",
+		"| `lodash` | 4.17.20 | high | [GHSA-35jh-r3h4-6jhm](https://github.com/advisories/GHSA-35jh-r3h4-6jhm) | Command Injection in lodash |  |",
+		"| `minimist` | 1.2.5 | low | GHSA-xvch-5gv4-984h | Pollution via \\_\\_proto\\_\\_ \\| constructor |  |",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,150 @@
+// Package report renders scan results in the output formats snapem supports.
+//
+// Each format is a Renderer registered under a name. The CLI resolves the
+// --format flag through the registry, so registering a renderer is enough to
+// make a new format available:
+//
+//	type ticketRenderer struct{}
+//
+//	func (ticketRenderer) Render(w io.Writer, r *report.Report) error {
+//		for _, f := range r.Result.AllFindings() {
+//			fmt.Fprintf(w, "* [%s] %s@%s: %s\n", f.Severity, f.Package, f.Version, f.Title)
+//		}
+//		return nil
+//	}
+//
+//	func init() {
+//		report.Register("ticket", ticketRenderer{})
+//	}
+//
+// Renderers should pass the conformance checks in the reporttest package:
+//
+//	func TestTicketRenderer(t *testing.T) {
+//		reporttest.RunRegistered(t, "ticket")
+//	}
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+// Report is the input handed to renderers
+type Report struct {
+	// Result holds the aggregated scan findings
+	Result *AggregatedResult
+
+	// Verbose, Quiet and Color are presentation hints for human-readable
+	// renderers; machine formats ignore them
+	Verbose bool
+	Quiet   bool
+	Color   bool
+
+	// Coverage asks human-readable renderers for the per-package coverage
+	// table
+	Coverage bool
+
+	// Via maps name@version of transitive packages to the direct
+	// dependencies that pull them in, for annotating findings
+	Via map[string][]string
+
+	// Changes maps name@version to how a package changed since the git ref
+	// of a diff scan, e.g. "new package" or "upgraded from 1.2.0", for
+	// labelling findings
+	Changes map[string]string
+
+	// GroupBy arranges findings in human-readable renderers: GroupByPackage
+	// (the default when empty) or GroupBySeverity
+	GroupBy string
+}
+
+// Ways of grouping findings in human-readable output
+const (
+	GroupByPackage  = "package"
+	GroupBySeverity = "severity"
+)
+
+// GroupByModes lists the accepted GroupBy values
+var GroupByModes = []string{GroupByPackage, GroupBySeverity}
+
+// Renderer writes a report in a specific output format
+type Renderer interface {
+	Render(w io.Writer, report *Report) error
+}
+
+var (
+	mu        sync.RWMutex
+	renderers = make(map[string]Renderer)
+)
+
+// Register makes a renderer available under the given format name,
+// replacing any renderer previously registered under that name
+func Register(name string, r Renderer) {
+	mu.Lock()
+	defer mu.Unlock()
+	renderers[name] = r
+}
+
+// Get returns the renderer registered for a format name
+func Get(name string) (Renderer, error) {
+	mu.RLock()
+	r, ok := renderers[name]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available formats: %s)", name, strings.Join(Formats(), ", "))
+	}
+	return r, nil
+}
+
+// Formats returns the registered format names in sorted order
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compareFindings orders findings by package, version, severity, type, ID
+// and title, so that reports written to files do not depend on which
+// scanner finished first
+func compareFindings(a, b *types.Finding) int {
+	return cmp.Or(
+		cmp.Compare(a.Package, b.Package),
+		cmp.Compare(a.Version, b.Version),
+		cmp.Compare(types.SeverityOrder(a.Severity), types.SeverityOrder(b.Severity)),
+		cmp.Compare(a.Type, b.Type),
+		cmp.Compare(a.ID, b.ID),
+		cmp.Compare(a.Title, b.Title),
+	)
+}
+
+// sortedFindings returns a copy of findings in compareFindings order
+func sortedFindings(findings []types.Finding) []types.Finding {
+	sorted := slices.Clone(findings)
+	slices.SortStableFunc(sorted, func(a, b types.Finding) int {
+		return compareFindings(&a, &b)
+	})
+	return sorted
+}
+
+func init() {
+	Register("text", textRenderer{})
+	Register("json", jsonRenderer{})
+	Register("gha-matcher", ghaMatcherRenderer{})
+	Register("sarif", sarifRenderer{})
+	Register("table", tableRenderer{})
+	Register("markdown", markdownRenderer{})
+}
@@ -0,0 +1,27 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/positronico/snapem/pkg/report/reporttest"
+)
+
+func TestRenderersConformance(t *testing.T) {
+	for _, name := range report.Formats() {
+		t.Run(name, func(t *testing.T) {
+			reporttest.RunRegistered(t, name)
+		})
+	}
+}
+
+func TestGetUnknownFormat(t *testing.T) {
+	_, err := report.Get("confluence")
+	if err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+	if !strings.Contains(err.Error(), "available formats: "+strings.Join(report.Formats(), ", ")) {
+		t.Errorf("error %q does not list available formats", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package reporttest provides conformance checks for report renderers
+package reporttest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/pkg/report"
+)
+
+// CannedReport returns a fixed report covering malware, CVE, freshness and
+// clean results
+func CannedReport() *report.Report {
+	return &report.Report{
+		Result: &types.AggregatedResult{
+			Results: []*types.ScanResult{
+				{
+					Scanner:  "Socket.dev",
+					Packages: 3,
+					Findings: []types.Finding{
+						{
+							Package:     "evil-pkg",
+							Version:     "1.0.0",
+							Type:        types.FindingTypeMalware,
+							Severity:    types.SeverityCritical,
+							Title:       "malware",
+							Description: "Known malicious package",
+							ID:          "socket-123",
+						},
+					},
+				},
+				{
+					Scanner:  "Google OSV",
+					Packages: 3,
+					Findings: []types.Finding{
+						{
+							Package:     "lodash",
+							Version:     "4.17.20",
+							Type:        types.FindingTypeCVE,
+							Severity:    types.SeverityHigh,
+							Title:       "Command Injection in lodash",
+							Description: "lodash versions prior to 4.17.21 are vulnerable to Command Injection",
+							ID:          "GHSA-35jh-r3h4-6jhm",
+							References:  []string{"https://github.com/advisories/GHSA-35jh-r3h4-6jhm"},
+						},
+						{
+							Package:  "minimist",
+							Version:  "1.2.5",
+							Type:     types.FindingTypeCVE,
+							Severity: types.SeverityLow,
+							Title:    "Prototype Pollution in minimist",
+							ID:       "GHSA-xvch-5gv4-984h",
+						},
+					},
+				},
+			},
+			TotalPackages: 3,
+			TotalFindings: 3,
+			HasMalware:    true,
+			HasCritical:   true,
+			HasHigh:       true,
+			Duration:      1500 * time.Millisecond,
+			Freshness: []types.DependencyHealth{
+				{
+					Package:          "lodash",
+					Version:          "4.17.20",
+					LastPublish:      time.Date(2021, 2, 20, 0, 0, 0, 0, time.UTC),
+					DaysSinceRelease: 1700,
+					Repository:       "git+https://github.com/lodash/lodash.git",
+					OpenAdvisories:   1,
+					Status:           types.FreshnessStale,
+				},
+			},
+		},
+	}
+}
+
+// EmptyReport returns a report of a scan that found nothing
+func EmptyReport() *report.Report {
+	return &report.Report{Result: &types.AggregatedResult{TotalPackages: 3}}
+}
+
+// checks are the conformance checks Run runs, by subtest name
+var checks = []struct {
+	name  string
+	check func(report.Renderer) error
+}{
+	{"canned", checkCanned},
+	{"empty", checkEmpty},
+	{"write error", checkWriteError},
+}
+
+// Run checks that a renderer names every finding of the canned report,
+// is deterministic for identical input, handles a report without
+// findings, and returns the error of a failed write
+func Run(t *testing.T, r report.Renderer) {
+	t.Helper()
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.check(r); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// RunRegistered runs the checks of Run on the renderer registered under
+// name, after checking that the registry lists it and returns it intact
+func RunRegistered(t *testing.T, name string) {
+	t.Helper()
+	r, err := registered(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Run(t, r)
+}
+
+// registered looks up name in the registry
+func registered(name string) (report.Renderer, error) {
+	if !slices.Contains(report.Formats(), name) {
+		return nil, fmt.Errorf("format %q is not in report.Formats()", name)
+	}
+	r, err := report.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("report.Get(%q): %w", name, err)
+	}
+	if r == nil {
+		return nil, fmt.Errorf("report.Get(%q) returned a nil renderer", name)
+	}
+	return r, nil
+}
+
+// checkCanned renders the canned report twice and checks that the output
+// is identical and names the package of every finding
+func checkCanned(r report.Renderer) error {
+	var first, second bytes.Buffer
+	if err := r.Render(&first, CannedReport()); err != nil {
+		return fmt.Errorf("Render returned error: %w", err)
+	}
+	if err := r.Render(&second, CannedReport()); err != nil {
+		return fmt.Errorf("second Render returned error: %w", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		return errors.New("Render is not deterministic for identical input")
+	}
+	for _, f := range CannedReport().Result.AllFindings() {
+		if !strings.Contains(first.String(), f.Package) {
+			return fmt.Errorf("output does not mention %s@%s (%s)", f.Package, f.Version, f.ID)
+		}
+	}
+	return nil
+}
+
+// checkEmpty renders a report without findings, which must still produce
+// output, so that an empty file is never mistaken for a clean scan
+func checkEmpty(r report.Renderer) error {
+	var buf bytes.Buffer
+	if err := r.Render(&buf, EmptyReport()); err != nil {
+		return fmt.Errorf("Render of empty report returned error: %w", err)
+	}
+	if buf.Len() == 0 {
+		return errors.New("Render of empty report produced no output")
+	}
+	return nil
+}
+
+// errWrite is the error failingWriter returns
+var errWrite = errors.New("disk full")
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+// checkWriteError renders to a writer that fails and checks that Render
+// returns its error
+func checkWriteError(r report.Renderer) error {
+	for _, rep := range []*report.Report{CannedReport(), EmptyReport()} {
+		if err := r.Render(failingWriter{}, rep); !errors.Is(err, errWrite) {
+			return fmt.Errorf("Render to a failing writer returned %v, want the write error", err)
+		}
+	}
+	return nil
+}
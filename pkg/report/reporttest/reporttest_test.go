@@ -0,0 +1,123 @@
+package reporttest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/pkg/report"
+)
+
+// listRenderer writes one line per finding, or a clean line
+type listRenderer struct{}
+
+func (listRenderer) Render(w io.Writer, r *report.Report) error {
+	findings := r.Result.AllFindings()
+	if len(findings) == 0 {
+		_, err := fmt.Fprintf(w, "clean: %d packages\n", r.Result.TotalPackages)
+		return err
+	}
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s@%s %s\n", f.Package, f.Version, f.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstOnlyRenderer drops every finding but the first
+type firstOnlyRenderer struct{}
+
+func (firstOnlyRenderer) Render(w io.Writer, r *report.Report) error {
+	if findings := r.Result.AllFindings(); len(findings) > 0 {
+		fmt.Fprintf(w, "%s@%s %s\n", findings[0].Package, findings[0].Version, findings[0].ID)
+	}
+	_, err := io.WriteString(w, "end\n")
+	return err
+}
+
+// countingRenderer writes a different line on every call
+type countingRenderer struct{ calls *int }
+
+func (c countingRenderer) Render(w io.Writer, r *report.Report) error {
+	*c.calls++
+	var b bytes.Buffer
+	listRenderer{}.Render(&b, r)
+	_, err := fmt.Fprintf(w, "%srun %d\n", b.String(), *c.calls)
+	return err
+}
+
+// silentRenderer writes nothing for a clean report
+type silentRenderer struct{}
+
+func (silentRenderer) Render(w io.Writer, r *report.Report) error {
+	if len(r.Result.AllFindings()) == 0 {
+		return nil
+	}
+	return listRenderer{}.Render(w, r)
+}
+
+// carelessRenderer ignores write errors
+type carelessRenderer struct{}
+
+func (carelessRenderer) Render(w io.Writer, r *report.Report) error {
+	listRenderer{}.Render(w, r)
+	return nil
+}
+
+func TestChecks(t *testing.T) {
+	calls := 0
+	tests := []struct {
+		name     string
+		renderer report.Renderer
+		canned   string
+		empty    string
+		writeErr string
+	}{
+		{name: "conforming", renderer: listRenderer{}},
+		{name: "drops findings", renderer: firstOnlyRenderer{}, canned: "does not mention lodash"},
+		{name: "nondeterministic", renderer: countingRenderer{&calls}, canned: "not deterministic"},
+		{name: "silent when clean", renderer: silentRenderer{}, empty: "no output", writeErr: "want the write error"},
+		{name: "ignores write errors", renderer: carelessRenderer{}, writeErr: "want the write error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []struct {
+				name  string
+				check func(report.Renderer) error
+				want  string
+			}{
+				{"canned", checkCanned, tt.canned},
+				{"empty", checkEmpty, tt.empty},
+				{"write error", checkWriteError, tt.writeErr},
+			} {
+				err := c.check(tt.renderer)
+				switch {
+				case c.want == "" && err != nil:
+					t.Errorf("%s check error = %v, want none", c.name, err)
+				case c.want != "" && (err == nil || !strings.Contains(err.Error(), c.want)):
+					t.Errorf("%s check error = %v, want %q", c.name, err, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	report.Register("reporttest-list", listRenderer{})
+
+	r, err := registered("reporttest-list")
+	if err != nil {
+		t.Fatalf("registered() error = %v", err)
+	}
+	if _, ok := r.(listRenderer); !ok {
+		t.Errorf("registered() = %T, want the registered listRenderer", r)
+	}
+	if _, err := registered("reporttest-missing"); err == nil || !strings.Contains(err.Error(), "not in report.Formats()") {
+		t.Errorf("registered() of a missing format error = %v", err)
+	}
+
+	RunRegistered(t, "reporttest-list")
+}
@@ -0,0 +1,188 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/positronico/snapem/internal/types"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/positronico/snapem"
+)
+
+// sarifSecuritySeverity is the score GitHub code scanning uses to rank
+// alerts, chosen inside each severity's CVSS band
+var sarifSecuritySeverity = map[types.Severity]string{
+	types.SeverityCritical: "9.5",
+	types.SeverityHigh:     "8.0",
+	types.SeverityMedium:   "5.5",
+	types.SeverityLow:      "2.0",
+	types.SeverityInfo:     "0.0",
+}
+
+// sarifRenderer renders a SARIF 2.1.0 log for code scanning upload
+type sarifRenderer struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string          `json:"id"`
+	ShortDescription sarifMessage    `json:"shortDescription"`
+	FullDescription  *sarifMessage   `json:"fullDescription,omitempty"`
+	HelpURI          string          `json:"helpUri,omitempty"`
+	Help             *sarifMessage   `json:"help,omitempty"`
+	Properties       sarifProperties `json:"properties"`
+}
+
+type sarifProperties struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Render writes the SARIF log as indented JSON. Findings sharing an
+// advisory ID share one rule.
+func (sarifRenderer) Render(w io.Writer, r *Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "snapem",
+			InformationURI: sarifToolURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, f := range sortedFindings(r.Result.AllFindings()) {
+		id := sarifRuleID(f)
+		idx, ok := ruleIndex[id]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(id, f))
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    id,
+			RuleIndex: idx,
+			Level:     sarifLevel(f),
+			Message:   sarifMessage{Text: f.Package + "@" + f.Version + ": " + ghaMessage(f)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: ghaManifest},
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				"snapem/v1": f.Package + "@" + f.Version + "/" + id,
+			},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleID returns the advisory ID, or a stable ID for findings
+// without one
+func sarifRuleID(f types.Finding) string {
+	if f.ID != "" {
+		return f.ID
+	}
+	return "snapem/" + string(f.Type)
+}
+
+// newSARIFRule describes the advisory behind a finding
+func newSARIFRule(id string, f types.Finding) sarifRule {
+	title := f.Title
+	if title == "" {
+		title = id
+	}
+
+	rule := sarifRule{
+		ID:               id,
+		ShortDescription: sarifMessage{Text: title},
+		Properties: sarifProperties{
+			Tags:             []string{"security", string(f.Type)},
+			SecuritySeverity: sarifSecuritySeverity[f.Severity],
+		},
+	}
+	if f.Description != "" {
+		rule.FullDescription = &sarifMessage{Text: f.Description}
+	}
+	if len(f.References) > 0 {
+		rule.HelpURI = f.References[0]
+		help := "References:"
+		for _, ref := range f.References {
+			help += "\n" + ref
+		}
+		rule.Help = &sarifMessage{Text: help}
+	}
+	return rule
+}
+
+// sarifLevel maps severity to a SARIF result level; malware is always an
+// error
+func sarifLevel(f types.Finding) string {
+	if f.Type == types.FindingTypeMalware || f.Type == types.FindingTypeTyposquat {
+		return "error"
+	}
+	switch f.Severity {
+	case types.SeverityCritical, types.SeverityHigh:
+		return "error"
+	case types.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
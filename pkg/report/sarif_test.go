@@ -0,0 +1,172 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/positronico/snapem/pkg/report/reporttest"
+)
+
+// loadSARIFSchema loads the OASIS SARIF 2.1.0 schema from testdata
+func loadSARIFSchema(t *testing.T) *schema {
+	t.Helper()
+
+	s, err := loadSchema("sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSARIFValidatesAgainstSchema(t *testing.T) {
+	s := loadSARIFSchema(t)
+	r, err := report.Get("sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports := map[string]*report.Report{
+		"canned": reporttest.CannedReport(),
+		"empty":  {Result: &types.AggregatedResult{}},
+	}
+	for name, rep := range reports {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := r.Render(&buf, rep); err != nil {
+				t.Fatal(err)
+			}
+			errs, err := s.validate(buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) > 0 {
+				t.Errorf("SARIF output does not validate:\n%s", strings.Join(errs, "\n"))
+			}
+		})
+	}
+}
+
+func TestSARIFSchemaRejectsInvalid(t *testing.T) {
+	s := loadSARIFSchema(t)
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "enum",
+			doc:  `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "snapem"}}, "results": [{"message": {"text": "x"}, "level": "critical"}]}]}`,
+			want: "/runs/0/results/0/level",
+		},
+		{
+			name: "type",
+			doc:  `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "snapem"}}, "results": [{"message": {"text": "x"}, "ruleIndex": "0"}]}]}`,
+			want: "/runs/0/results/0/ruleIndex",
+		},
+		{
+			name: "additionalProperties",
+			doc:  `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "snapem", "severity": "high"}}}]}`,
+			want: "/runs/0/tool/driver",
+		},
+		{
+			name: "required",
+			doc:  `{"version": "2.1.0", "runs": [{"tool": {"driver": {}}}]}`,
+			want: "/runs/0/tool/driver",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := s.validate([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.ContainsFunc(errs, func(e string) bool { return strings.HasPrefix(e, tt.want+":") }) {
+				t.Errorf("errors = %v, want one at %s", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestSARIFContent(t *testing.T) {
+	rep := reporttest.CannedReport()
+	// A second package affected by the same advisory shares its rule
+	rep.Result.Results[1].Findings = append(rep.Result.Results[1].Findings, types.Finding{
+		Package:  "lodash-es",
+		Version:  "4.17.20",
+		Type:     types.FindingTypeCVE,
+		Severity: types.SeverityHigh,
+		Title:    "Command Injection in lodash",
+		ID:       "GHSA-35jh-r3h4-6jhm",
+	})
+
+	r, _ := report.Get("sarif")
+	var buf bytes.Buffer
+	if err := r.Render(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID      string `json:"id"`
+						HelpURI string `json:"helpUri"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("got %d rules, want 3 (shared advisory deduplicated)", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 4 {
+		t.Fatalf("got %d results, want 4", len(run.Results))
+	}
+
+	wantLevels := map[string]string{
+		"socket-123":          "error",
+		"GHSA-35jh-r3h4-6jhm": "error",
+		"GHSA-xvch-5gv4-984h": "note",
+	}
+	for _, res := range run.Results {
+		if got := run.Tool.Driver.Rules[res.RuleIndex].ID; got != res.RuleID {
+			t.Errorf("ruleIndex %d points at %s, want %s", res.RuleIndex, got, res.RuleID)
+		}
+		if res.Level != wantLevels[res.RuleID] {
+			t.Errorf("%s level = %s, want %s", res.RuleID, res.Level, wantLevels[res.RuleID])
+		}
+		if res.Locations[0].PhysicalLocation.ArtifactLocation.URI != "package.json" {
+			t.Errorf("%s location = %+v", res.RuleID, res.Locations)
+		}
+	}
+
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ID == "GHSA-35jh-r3h4-6jhm" && rule.HelpURI != "https://github.com/advisories/GHSA-35jh-r3h4-6jhm" {
+			t.Errorf("helpUri = %q", rule.HelpURI)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package report_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// schema is a JSON Schema draft-04 validator covering the keywords the
+// SARIF schema uses: $ref into definitions, type, enum, required,
+// properties, additionalProperties, items, minItems, uniqueItems, minimum,
+// maximum, pattern, anyOf and oneOf. format is not asserted.
+type schema struct {
+	root map[string]any
+}
+
+// loadSchema reads a schema from testdata
+func loadSchema(name string) (*schema, error) {
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		return nil, err
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return &schema{root: root}, nil
+}
+
+// validate returns every violation of the schema in the JSON document
+func (s *schema) validate(data []byte) ([]string, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var errs []string
+	s.check(s.root, doc, "", &errs)
+	return errs, nil
+}
+
+func (s *schema) check(node map[string]any, v any, path string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, fmt.Sprintf("%s: %s", "/"+strings.TrimPrefix(path, "/"), fmt.Sprintf(format, args...)))
+	}
+
+	// In draft-04 a $ref replaces the rest of the schema
+	if ref, ok := node["$ref"].(string); ok {
+		name, ok := strings.CutPrefix(ref, "#/definitions/")
+		def, _ := s.root["definitions"].(map[string]any)[name].(map[string]any)
+		if !ok || def == nil {
+			fail("unresolved $ref %s", ref)
+			return
+		}
+		s.check(def, v, path, errs)
+		return
+	}
+
+	if t, ok := node["type"]; ok {
+		types, ok := t.([]any)
+		if !ok {
+			types = []any{t}
+		}
+		if !slices.ContainsFunc(types, func(t any) bool { return hasType(v, t.(string)) }) {
+			fail("%s is not of type %v", jsonType(v), t)
+			return
+		}
+	}
+	if enum, ok := node["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+			fail("%v is not one of %v", v, enum)
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		options, ok := node[keyword].([]any)
+		if !ok {
+			continue
+		}
+		matched := 0
+		for _, option := range options {
+			var optionErrs []string
+			s.check(option.(map[string]any), v, path, &optionErrs)
+			if len(optionErrs) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 || keyword == "oneOf" && matched > 1 {
+			fail("%d schemas of %s match", matched, keyword)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		required, _ := node["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				fail("missing required property %s", name)
+			}
+		}
+		properties, _ := node["properties"].(map[string]any)
+		for name, value := range v {
+			if property, ok := properties[name].(map[string]any); ok {
+				s.check(property, value, path+"/"+name, errs)
+				continue
+			}
+			switch additional := node["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("additional property %s is not allowed", name)
+				}
+			case map[string]any:
+				s.check(additional, value, path+"/"+name, errs)
+			}
+		}
+	case []any:
+		if minItems, ok := node["minItems"].(float64); ok && float64(len(v)) < minItems {
+			fail("%d items, want at least %v", len(v), minItems)
+		}
+		if unique, _ := node["uniqueItems"].(bool); unique {
+			for i := range v {
+				for j := range i {
+					if reflect.DeepEqual(v[i], v[j]) {
+						fail("items %d and %d are equal", j, i)
+					}
+				}
+			}
+		}
+		if items, ok := node["items"].(map[string]any); ok {
+			for i, item := range v {
+				s.check(items, item, fmt.Sprintf("%s/%d", path, i), errs)
+			}
+		}
+	case float64:
+		if minimum, ok := node["minimum"].(float64); ok && v < minimum {
+			fail("%v is less than %v", v, minimum)
+		}
+		if maximum, ok := node["maximum"].(float64); ok && v > maximum {
+			fail("%v is greater than %v", v, maximum)
+		}
+	case string:
+		if pattern, ok := node["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			fail("%q does not match %s", v, pattern)
+		}
+	}
+}
+
+// hasType reports whether a decoded JSON value is of a schema type
+func hasType(v any, t string) bool {
+	if t == "integer" {
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return jsonType(v) == t
+}
+
+// jsonType returns the schema type name of a decoded JSON value
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
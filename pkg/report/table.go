@@ -0,0 +1,213 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// tableHeaders are the columns of the table and markdown formats
+var tableHeaders = []string{"PACKAGE", "VERSION", "SEVERITY", "ID", "TITLE", "REMEDIATION"}
+
+// shrinkableColumns are the table columns cut short to fit the terminal;
+// the ID column never is
+var shrinkableColumns = []int{0, 4, 5}
+
+// minColumnWidth is the narrowest a shrinkable column is cut to, unless
+// its header is wider
+const minColumnWidth = 8
+
+// tableRenderer writes one row per finding in aligned columns: a bordered
+// table on a color terminal, plain columns otherwise
+type tableRenderer struct{}
+
+// Render writes the findings table followed by a summary line
+func (tableRenderer) Render(w io.Writer, r *Report) error {
+	findings := tableFindings(r.Result)
+	if len(findings) == 0 {
+		_, err := fmt.Fprintf(w, "No security issues found in %d packages%s\n", r.Result.TotalPackages, baselineNote(r.Result))
+		return err
+	}
+
+	rows := make([][]string, len(findings))
+	for i, f := range findings {
+		rows[i] = tableRow(f)
+	}
+
+	color := ui.ColorEnabled(w, r.Color)
+	var out string
+	if color {
+		// Borders and one space of padding each side of every column
+		fitColumns(rows, ui.TerminalWidth(w), 3*len(tableHeaders)+1)
+		out = table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(ui.StyleMuted).
+			Headers(tableHeaders...).
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				style := lipgloss.NewStyle().Padding(0, 1)
+				switch {
+				case row == table.HeaderRow:
+					return style.Bold(true)
+				case col == 2:
+					return style.Inherit(ui.SeverityStyle(rows[row][col]))
+				}
+				return style
+			}).
+			String() + "\n"
+	} else {
+		fitColumns(rows, ui.TerminalWidth(w), 2*(len(tableHeaders)-1))
+		out = plainTable(append([][]string{tableHeaders}, rows...))
+	}
+
+	if _, err := io.WriteString(w, out); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", findingSummary(r.Result, findings))
+	return err
+}
+
+// tableFindings returns the findings listed by the table formats, worst
+// first, then by package. Packages that merely lack provenance are left
+// out, as in the text format.
+func tableFindings(result *types.AggregatedResult) []*types.Finding {
+	var findings []*types.Finding
+	for f := range result.Findings() {
+		if f.Type == types.FindingTypeProvenance && f.Severity == types.SeverityInfo {
+			continue
+		}
+		findings = append(findings, f)
+	}
+	slices.SortStableFunc(findings, func(a, b *types.Finding) int {
+		if d := types.SeverityOrder(a.Severity) - types.SeverityOrder(b.Severity); d != 0 {
+			return d
+		}
+		return compareFindings(a, b)
+	})
+	return findings
+}
+
+// tableRow returns the cells of a finding, in tableHeaders order
+func tableRow(f *types.Finding) []string {
+	return []string{f.Package, f.Version, string(f.Severity), findingID(f), oneLine(findingTitle(f)), oneLine(f.Remediation)}
+}
+
+// findingID returns the advisory ID of a finding, or its type when it has
+// none
+func findingID(f *types.Finding) string {
+	if f.ID != "" {
+		return f.DisplayID()
+	}
+	return string(f.Type)
+}
+
+// findingSummary counts the listed findings by severity, e.g.
+// "3 findings in 120 packages (1 critical, 2 high)"
+func findingSummary(result *types.AggregatedResult, findings []*types.Finding) string {
+	counts := make(map[types.Severity]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, sev := range []types.Severity{types.SeverityCritical, types.SeverityHigh, types.SeverityMedium, types.SeverityLow, types.SeverityInfo} {
+		if counts[sev] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[sev], sev))
+		}
+	}
+
+	noun := "findings"
+	if len(findings) == 1 {
+		noun = "finding"
+	}
+	return fmt.Sprintf("%d %s in %d packages (%s)%s", len(findings), noun, result.TotalPackages, strings.Join(parts, ", "), baselineNote(result))
+}
+
+// baselineNote counts the findings left out because the baseline file
+// acknowledges them, e.g. "; 4 in the baseline"
+func baselineNote(result *types.AggregatedResult) string {
+	if len(result.Baselined) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("; %d in the baseline", len(result.Baselined))
+}
+
+// fitColumns cuts the shrinkable columns of rows, widest first, until a
+// row and the table's overhead fit in width. A width of 0 leaves the rows
+// as they are.
+func fitColumns(rows [][]string, width, overhead int) {
+	if width <= 0 {
+		return
+	}
+	widths := columnWidths(append([][]string{tableHeaders}, rows...))
+	total := overhead
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > width {
+		widest := -1
+		for _, col := range shrinkableColumns {
+			floor := max(minColumnWidth, len(tableHeaders[col]))
+			if widths[col] > floor && (widest < 0 || widths[col] > widths[widest]) {
+				widest = col
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	for _, row := range rows {
+		for col, cell := range row {
+			row[col] = truncate(cell, widths[col])
+		}
+	}
+}
+
+// columnWidths returns the width of the widest cell in each column
+func columnWidths(rows [][]string) []int {
+	widths := make([]int, len(tableHeaders))
+	for _, row := range rows {
+		for col, cell := range row {
+			widths[col] = max(widths[col], lipgloss.Width(cell))
+		}
+	}
+	return widths
+}
+
+// truncate cuts s to width columns, ending it with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// plainTable aligns rows in columns two spaces apart
+func plainTable(rows [][]string) string {
+	widths := columnWidths(rows)
+	var b strings.Builder
+	for _, row := range rows {
+		var line strings.Builder
+		for col, cell := range row {
+			line.WriteString(cell + strings.Repeat(" ", widths[col]-lipgloss.Width(cell)+2))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	return b.String()
+}
+
+// oneLine joins the lines of s with spaces, for a table cell
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
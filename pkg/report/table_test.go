@@ -0,0 +1,47 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFitColumns(t *testing.T) {
+	rows := [][]string{
+		{"@scope/a-very-long-package-name", "1.0.0", "high", "GHSA-35jh-r3h4-6jhm", "Command Injection in a very long advisory title", "Upgrade to 4.17.21 or later"},
+	}
+	fitColumns(rows, 80, 2*(len(tableHeaders)-1))
+
+	row := rows[0]
+	if row[3] != "GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("ID = %q, want it untruncated", row[3])
+	}
+	width := 2 * (len(row) - 1)
+	for _, cell := range row {
+		width += len([]rune(cell))
+	}
+	if width > 80 {
+		t.Errorf("row is %d columns wide, want at most 80: %q", width, row)
+	}
+	if !strings.HasSuffix(row[4], "…") {
+		t.Errorf("title = %q, want it cut with an ellipsis", row[4])
+	}
+
+	unchanged := [][]string{{"lodash", "4.17.20", "high", "GHSA-1", "Title", ""}}
+	fitColumns(unchanged, 0, 10)
+	if unchanged[0][0] != "lodash" || unchanged[0][4] != "Title" {
+		t.Errorf("width 0 changed the row: %q", unchanged[0])
+	}
+}
+
+func TestPlainTable(t *testing.T) {
+	got := plainTable([][]string{
+		tableHeaders,
+		{"lodash", "4.17.20", "high", "GHSA-1", "Command Injection", ""},
+	})
+
+	want := "PACKAGE  VERSION  SEVERITY  ID      TITLE              REMEDIATION\n" +
+		"lodash   4.17.20  high      GHSA-1  Command Injection\n"
+	if got != want {
+		t.Errorf("plainTable() =\n%s\nwant\n%s", got, want)
+	}
+}
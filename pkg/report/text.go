@@ -0,0 +1,511 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/internal/ui"
+)
+
+// textRenderer renders the human-readable scan summary
+type textRenderer struct{}
+
+// Render writes the scan summary and the findings, grouped by package or,
+// with GroupBySeverity, by category
+func (textRenderer) Render(w io.Writer, r *Report) error {
+	display := ui.NewWriter(w, r.Verbose, r.Quiet, r.Color)
+	result := r.Result
+
+	display.Print("")
+	summary := fmt.Sprintf("Scanned %d packages in %s", result.TotalPackages, result.Duration.Round(1e6))
+	if result.CacheHits > 0 {
+		summary += fmt.Sprintf(" (%d from cache)", result.CacheHits)
+	}
+	display.Print(summary)
+
+	if result.Coverage != nil {
+		renderCoverage(display, result.Coverage, r.Coverage)
+	}
+
+	if len(result.Freshness) > 0 {
+		renderFreshness(display, result.Freshness)
+	}
+
+	if r.Verbose && len(result.Scores) > 0 {
+		renderScores(display, result.Scores)
+	}
+
+	if result.TotalFindings == 0 {
+		if len(result.Baselined) > 0 {
+			display.Success("No new security issues found")
+		} else {
+			display.Success("No security issues found")
+		}
+		r.renderBaselined(display)
+		return display.Err()
+	}
+
+	display.Print(fmt.Sprintf("\nFound %d issue(s):", result.TotalFindings))
+
+	// Summary counts
+	critical := result.CountBySeverity(types.SeverityCritical)
+	high := result.CountBySeverity(types.SeverityHigh)
+	medium := result.CountBySeverity(types.SeverityMedium)
+	low := result.CountBySeverity(types.SeverityLow)
+	malware := result.CountByType(types.FindingTypeMalware) + result.CountByType(types.FindingTypeTyposquat)
+
+	license := result.CountByType(types.FindingTypeLicense)
+	scripts := result.CountByType(types.FindingTypeInstallScript)
+
+	if malware > 0 {
+		display.Error(fmt.Sprintf("  Malware/Supply Chain: %d", malware))
+	}
+	if license > 0 {
+		display.Warning(fmt.Sprintf("  License: %d", license))
+	}
+	if scripts > 0 {
+		display.Warning(fmt.Sprintf("  Install scripts: %d", scripts))
+	}
+	if critical > 0 {
+		display.Error(fmt.Sprintf("  Critical: %d", critical))
+	}
+	if high > 0 {
+		display.Warning(fmt.Sprintf("  High: %d", high))
+	}
+	if medium > 0 {
+		display.Info(fmt.Sprintf("  Medium: %d", medium))
+	}
+	if low > 0 {
+		display.Verbose(fmt.Sprintf("  Low: %d", low))
+	}
+
+	if r.GroupBy != GroupBySeverity {
+		r.renderPackageGroups(display, result)
+		r.renderBaselined(display)
+		return display.Err()
+	}
+
+	// Findings in direct dependencies come first; they are the ones the
+	// project can fix by changing package.json
+	for _, group := range []struct {
+		title  string
+		direct bool
+	}{{"Direct dependencies", true}, {"Transitive dependencies", false}} {
+		var findings []*types.Finding
+		for f := range result.Findings() {
+			if f.Direct == group.direct {
+				findings = append(findings, f)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+		display.Print("")
+		display.Print(fmt.Sprintf("%s (%d):", group.title, len(findings)))
+		r.renderFindings(display, findings)
+	}
+
+	r.renderBaselined(display)
+	return display.Err()
+}
+
+// renderBaselined notes the findings acknowledged in the baseline file,
+// listing them only in verbose mode
+func (r *Report) renderBaselined(display *ui.UI) {
+	baselined := r.Result.Baselined
+	if len(baselined) == 0 {
+		return
+	}
+	display.Print("")
+	if !r.Verbose {
+		display.Info(fmt.Sprintf("Baseline (%d suppressed): use --verbose to list, or --no-baseline to report them", len(baselined)))
+		return
+	}
+	display.Info(fmt.Sprintf("Baseline (%d suppressed):", len(baselined)))
+	for i := range baselined {
+		f := &baselined[i]
+		display.Print(fmt.Sprintf("    [%s] %s: %s", f.Severity, r.packageLabel(f), findingDescription(f)))
+	}
+}
+
+// maxGroupFindings caps the findings listed under a package outside
+// verbose mode
+const maxGroupFindings = 5
+
+// packageGroup holds the findings of one package version
+type packageGroup struct {
+	label    string
+	direct   bool
+	worst    types.Severity
+	findings []*types.Finding
+}
+
+// renderPackageGroups writes a header line per package with findings,
+// worst severity first, and the package's findings beneath it
+func (r *Report) renderPackageGroups(display *ui.UI, result *types.AggregatedResult) {
+	groups, unattested := r.groupByPackage(result)
+	if len(groups) > 0 {
+		width := 0
+		for _, g := range groups {
+			width = max(width, len(g.label))
+		}
+
+		display.Print("")
+		display.Print(fmt.Sprintf("Packages with findings (%d):", len(groups)))
+		for _, g := range groups {
+			dependency := "transitive"
+			if g.direct {
+				dependency = "direct"
+			}
+			display.FindingGroup(g.label, dependency, string(g.worst), len(g.findings), width)
+
+			shown := g.findings
+			if !r.Verbose && len(shown) > maxGroupFindings {
+				shown = shown[:maxGroupFindings]
+			}
+			for _, f := range shown {
+				display.GroupedThreat(string(f.Severity), g.label, findingDescription(f), findingDetails(f)...)
+			}
+			if hidden := len(g.findings) - len(shown); hidden > 0 {
+				display.Print(fmt.Sprintf("    … and %d more (use --verbose)", hidden))
+			}
+		}
+	}
+
+	if unattested > 0 {
+		display.Print("")
+		display.Info(fmt.Sprintf("  %d package(s) have no provenance attestation", unattested))
+	}
+}
+
+// groupByPackage collects findings by package version, sorted by worst
+// severity and then by name, each group's findings worst first. Packages
+// that merely lack provenance are only counted, as in the category view.
+func (r *Report) groupByPackage(result *types.AggregatedResult) ([]*packageGroup, int) {
+	var groups []*packageGroup
+	byLabel := make(map[string]*packageGroup)
+	unattested := 0
+	for f := range result.Findings() {
+		if f.Type == types.FindingTypeProvenance && f.Severity == types.SeverityInfo {
+			unattested++
+			continue
+		}
+		label := r.packageLabel(f)
+		g, ok := byLabel[label]
+		if !ok {
+			g = &packageGroup{label: label, worst: f.Severity}
+			byLabel[label] = g
+			groups = append(groups, g)
+		}
+		g.direct = g.direct || f.Direct
+		if types.SeverityOrder(f.Severity) < types.SeverityOrder(g.worst) {
+			g.worst = f.Severity
+		}
+		g.findings = append(g.findings, f)
+	}
+
+	for _, g := range groups {
+		slices.SortStableFunc(g.findings, func(a, b *types.Finding) int {
+			return types.SeverityOrder(a.Severity) - types.SeverityOrder(b.Severity)
+		})
+	}
+	slices.SortStableFunc(groups, func(a, b *packageGroup) int {
+		if d := types.SeverityOrder(a.worst) - types.SeverityOrder(b.worst); d != 0 {
+			return d
+		}
+		return strings.Compare(a.label, b.label)
+	})
+	return groups, unattested
+}
+
+// findingDescription is the one-line summary of a finding under its
+// package, the same text the category view shows
+func findingDescription(f *types.Finding) string {
+	if f.Type == types.FindingTypeCVE && f.ID != "" {
+		return f.DisplayID() + ": " + f.Title
+	}
+	return findingTitle(f)
+}
+
+// findingTitle is the most telling short text of a finding: the scanner's
+// description where its title is generic, such as for malware
+func findingTitle(f *types.Finding) string {
+	switch {
+	case f.Type == types.FindingTypeMalware || f.Type == types.FindingTypeTyposquat || f.Type == types.FindingTypeInstallScript:
+		return f.Description
+	case f.Type == types.FindingTypeLicense && f.License != "":
+		return f.License + ": " + f.Description
+	case f.Type == types.FindingTypeLicense:
+		return f.Description
+	case f.Deprecated != "":
+		return "Deprecated: " + f.Deprecated
+	}
+	return f.Title
+}
+
+// findingDetails returns the lines the category view prints under a
+// finding: remediation for CVEs and the explanation of risk signals
+func findingDetails(f *types.Finding) []string {
+	switch {
+	case f.Type == types.FindingTypeCVE && f.Remediation != "":
+		return []string{f.Remediation}
+	case (f.Type == types.FindingTypeMaintainer || f.Type == types.FindingTypeQuality || f.Type == types.FindingTypeLowScore) && f.Deprecated == "" && f.Description != "":
+		return []string{f.Description}
+	}
+	return nil
+}
+
+// renderFindings writes findings grouped by category
+func (r *Report) renderFindings(display *ui.UI, findings []*types.Finding) {
+	// Display malware findings
+	if malware := ofType(findings, types.FindingTypeMalware, types.FindingTypeTyposquat); len(malware) > 0 {
+		display.Print("")
+		display.Error("Malware/Supply Chain Threats:")
+		for _, f := range malware {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Description)
+		}
+	}
+
+	// Display CVE findings by severity
+	if cves := ofType(findings, types.FindingTypeCVE); len(cves) > 0 {
+		display.Print("")
+		display.Warning("Vulnerabilities (CVEs):")
+
+		severities := []types.Severity{
+			types.SeverityCritical,
+			types.SeverityHigh,
+			types.SeverityMedium,
+			types.SeverityLow,
+		}
+
+		for _, sev := range severities {
+			for _, f := range cves {
+				if f.Severity == sev {
+					desc := f.Title
+					if f.ID != "" {
+						desc = f.DisplayID() + ": " + f.Title
+					}
+					display.ThreatFound(string(sev), r.packageLabel(f), desc)
+					if f.Remediation != "" {
+						display.ThreatDetail(f.Remediation)
+					}
+				}
+			}
+		}
+	}
+
+	// Display license findings
+	if licenses := ofType(findings, types.FindingTypeLicense); len(licenses) > 0 {
+		display.Print("")
+		display.Warning("License Issues:")
+		for _, f := range licenses {
+			desc := f.Description
+			if f.License != "" {
+				desc = f.License + ": " + f.Description
+			}
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), desc)
+		}
+	}
+
+	// Display install scripts
+	if scripts := ofType(findings, types.FindingTypeInstallScript); len(scripts) > 0 {
+		display.Print("")
+		display.Warning("Install Scripts:")
+		for _, f := range scripts {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Description)
+		}
+	}
+
+	// Display versions that dropped provenance; versions that never had
+	// it are only counted
+	if provenance := ofType(findings, types.FindingTypeProvenance); len(provenance) > 0 {
+		var unattested int
+		var dropped []*types.Finding
+		for _, f := range provenance {
+			if f.Severity == types.SeverityInfo {
+				unattested++
+			} else {
+				dropped = append(dropped, f)
+			}
+		}
+		display.Print("")
+		display.Warning("Provenance:")
+		for _, f := range dropped {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Title)
+		}
+		if unattested > 0 {
+			display.Info(fmt.Sprintf("  %d package(s) have no provenance attestation", unattested))
+		}
+	}
+
+	// Display versions inside the min_release_age quarantine
+	if quarantined := ofType(findings, types.FindingTypeReleaseAge); len(quarantined) > 0 {
+		display.Print("")
+		display.Warning("Release Age Quarantine:")
+		for _, f := range quarantined {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Title)
+		}
+	}
+
+	// Display packages below scanning.socket.min_score
+	if low := ofType(findings, types.FindingTypeLowScore); len(low) > 0 {
+		display.Print("")
+		display.Warning("Low Socket Scores:")
+		for _, f := range low {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Title)
+			display.ThreatDetail(f.Description)
+		}
+	}
+
+	// Display deprecated packages apart from other quality findings, so
+	// they are not lost among them
+	signals, deprecated := splitDeprecated(ofType(findings, types.FindingTypeMaintainer, types.FindingTypeQuality))
+	if len(deprecated) > 0 {
+		display.Print("")
+		display.Warning("Deprecated Packages:")
+		for _, f := range deprecated {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Deprecated)
+		}
+	}
+
+	// Display registry metadata risk signals
+	if len(signals) > 0 {
+		display.Print("")
+		display.Warning("Risk Signals:")
+		for _, f := range signals {
+			display.ThreatFound(string(f.Severity), r.packageLabel(f), f.Title)
+			display.ThreatDetail(f.Description)
+		}
+	}
+}
+
+// splitDeprecated separates deprecation findings from the others
+func splitDeprecated(findings []*types.Finding) (others, deprecated []*types.Finding) {
+	for _, f := range findings {
+		if f.Deprecated != "" {
+			deprecated = append(deprecated, f)
+		} else {
+			others = append(others, f)
+		}
+	}
+	return others, deprecated
+}
+
+// ofType returns the findings matching any of the given types
+func ofType(findings []*types.Finding, typs ...types.FindingType) []*types.Finding {
+	var matched []*types.Finding
+	for _, f := range findings {
+		if slices.Contains(typs, f.Type) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// renderFreshness writes one line per direct dependency with its maintenance
+// status, stalest first
+func renderFreshness(display *ui.UI, health []types.DependencyHealth) {
+	sorted := slices.Clone(health)
+	slices.SortStableFunc(sorted, func(a, b types.DependencyHealth) int {
+		return b.DaysSinceRelease - a.DaysSinceRelease
+	})
+
+	display.Print("")
+	display.Print("Dependency freshness:")
+	for _, h := range sorted {
+		released := "unknown"
+		if !h.LastPublish.IsZero() {
+			released = fmt.Sprintf("%s (%dd ago)", h.LastPublish.Format("2006-01-02"), h.DaysSinceRelease)
+		}
+
+		line := fmt.Sprintf("  %-8s %-40s last release %s", h.Status, h.Package+"@"+h.Version, released)
+		if h.Archived {
+			line += ", repository archived"
+		}
+		if h.OpenAdvisories > 0 {
+			line += fmt.Sprintf(", %d open advisories", h.OpenAdvisories)
+		}
+
+		switch h.Status {
+		case types.FreshnessStale:
+			display.Warning(line)
+		case types.FreshnessAging:
+			display.Info(line)
+		default:
+			display.Print(line)
+		}
+	}
+}
+
+// renderScores writes the Socket.dev score of each package, lowest first
+func renderScores(display *ui.UI, scores []types.PackageScore) {
+	sorted := slices.Clone(scores)
+	slices.SortStableFunc(sorted, func(a, b types.PackageScore) int {
+		return a.Overall - b.Overall
+	})
+
+	display.Print("")
+	display.Print("Socket scores:")
+	for _, s := range sorted {
+		display.Print(fmt.Sprintf("  %3d  %-40s %s", s.Overall, s.Package+"@"+s.Version, s.Breakdown()))
+	}
+}
+
+// renderCoverage warns when some packages were not assessed by every
+// scanner and, when asked, lists how each scanner handled each package
+func renderCoverage(display *ui.UI, coverage *types.Coverage, table bool) {
+	summary := coverage.Summary()
+	if summary.FullyCovered < summary.Packages {
+		line := fmt.Sprintf("Coverage: %d of %d packages checked by every scanner (%.1f%%)", summary.FullyCovered, summary.Packages, summary.Percent)
+		var reasons []string
+		for _, reason := range summary.SortedReasons() {
+			if !reason.Covered() {
+				reasons = append(reasons, fmt.Sprintf("%d %s", summary.Reasons[reason], reason))
+			}
+		}
+		if len(reasons) > 0 {
+			line += "; skipped: " + strings.Join(reasons, ", ")
+		}
+		display.Warning(line)
+	}
+
+	if !table {
+		return
+	}
+
+	display.Print("")
+	header := fmt.Sprintf("  %-40s", "PACKAGE")
+	for _, name := range coverage.Scanners {
+		header += fmt.Sprintf(" %-22s", name)
+	}
+	display.Print(header)
+	for _, p := range coverage.Packages {
+		line := fmt.Sprintf("  %-40s", p.Package+"@"+p.Version)
+		for _, name := range coverage.Scanners {
+			reason := p.Scanners[name]
+			if reason == "" {
+				reason = "-"
+			}
+			line += fmt.Sprintf(" %-22s", reason)
+		}
+		display.Print(line)
+	}
+}
+
+// packageLabel returns name@version, followed by how the package changed
+// in a diff scan and, in verbose mode, by the direct dependencies that
+// pull in a transitive package
+func (r *Report) packageLabel(f *types.Finding) string {
+	key := f.Package + "@" + f.Version
+	label := key
+	if change := r.Changes[key]; change != "" {
+		label += " (" + change + ")"
+	}
+	if via := r.Via[key]; r.Verbose && len(via) > 0 {
+		label += " (via " + strings.Join(via, ", ") + ")"
+	}
+	return label
+}
@@ -0,0 +1,132 @@
+package report_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/positronico/snapem/internal/types"
+	"github.com/positronico/snapem/pkg/report"
+	"github.com/positronico/snapem/pkg/report/reporttest"
+)
+
+// renderText renders r with the text renderer
+func renderText(t *testing.T, r *report.Report) string {
+	t.Helper()
+	renderer, err := report.Get("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, r); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestTextGroupByPackage(t *testing.T) {
+	r := reporttest.CannedReport()
+	var cves []types.Finding
+	for i := range 7 {
+		cves = append(cves, types.Finding{
+			Package:  "minimist",
+			Version:  "1.2.5",
+			Type:     types.FindingTypeCVE,
+			Severity: types.SeverityMedium,
+			Title:    fmt.Sprintf("Issue %d", i),
+			ID:       fmt.Sprintf("GHSA-%d", i),
+			Direct:   true,
+		})
+	}
+	r.Result.Results = append(r.Result.Results, &types.ScanResult{Scanner: "extra", Findings: cves})
+	r.Result.TotalFindings += len(cves)
+
+	out := renderText(t, r)
+
+	// Worst severity first; minimist's low finding sorts after its medium ones
+	var headers []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "    ") && strings.Contains(line, " finding") {
+			headers = append(headers, strings.Join(strings.Fields(line), " "))
+		}
+	}
+	want := []string{
+		"evil-pkg@1.0.0 transitive critical 1 finding",
+		"lodash@4.17.20 transitive high 1 finding",
+		"minimist@1.2.5 direct medium 8 findings",
+	}
+	if strings.Join(headers, "\n") != strings.Join(want, "\n") {
+		t.Errorf("headers = %q, want %q", headers, want)
+	}
+
+	for _, s := range []string{
+		"Packages with findings (3):",
+		"    [high] GHSA-35jh-r3h4-6jhm: Command Injection in lodash\n",
+		"    … and 3 more (use --verbose)\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "GHSA-xvch-5gv4-984h") {
+		t.Error("low finding shown although the group is capped at five")
+	}
+
+	r.Verbose = true
+	if out := renderText(t, r); !strings.Contains(out, "GHSA-xvch-5gv4-984h") || strings.Contains(out, "more (use --verbose)") {
+		t.Errorf("verbose output should list every finding:\n%s", out)
+	}
+}
+
+func TestTextGroupBySeverity(t *testing.T) {
+	r := reporttest.CannedReport()
+	r.GroupBy = report.GroupBySeverity
+
+	out := renderText(t, r)
+	for _, s := range []string{"Transitive dependencies (3):", "Malware/Supply Chain Threats:", "Vulnerabilities (CVEs):"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "Packages with findings") {
+		t.Errorf("severity grouping printed package groups:\n%s", out)
+	}
+}
+
+func TestTextChangeLabels(t *testing.T) {
+	r := reporttest.CannedReport()
+	r.Changes = map[string]string{
+		"lodash@4.17.20": "upgraded from 4.17.15",
+		"evil-pkg@1.0.0": "new package",
+	}
+
+	out := renderText(t, r)
+	for _, s := range []string{"lodash@4.17.20 (upgraded from 4.17.15)", "evil-pkg@1.0.0 (new package)"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "minimist@1.2.5 (") {
+		t.Errorf("unchanged package labelled:\n%s", out)
+	}
+}
+
+func TestTextScores(t *testing.T) {
+	r := reporttest.CannedReport()
+	r.Result.Scores = []types.PackageScore{
+		{Package: "lodash", Version: "4.17.20", Overall: 92},
+		{Package: "evil-pkg", Version: "1.0.0", Overall: 12},
+	}
+
+	if out := renderText(t, r); strings.Contains(out, "Socket scores") {
+		t.Errorf("scores listed without verbose:\n%s", out)
+	}
+
+	r.Verbose = true
+	out := renderText(t, r)
+	low, high := strings.Index(out, "evil-pkg@1.0.0"), strings.Index(out, " 92  lodash@4.17.20")
+	if !strings.Contains(out, "Socket scores:") || low < 0 || high < low {
+		t.Errorf("want scores listed lowest first:\n%s", out)
+	}
+}
@@ -0,0 +1,74 @@
+package report
+
+import "github.com/positronico/snapem/internal/types"
+
+// The scan result types a Report carries, named here so renderers outside
+// snapem can spell every type reachable from AggregatedResult
+type (
+	AggregatedResult = types.AggregatedResult
+	ScanResult       = types.ScanResult
+	Finding          = types.Finding
+	FindingType      = types.FindingType
+	Severity         = types.Severity
+	ScannerError     = types.ScannerError
+	PackageError     = types.PackageError
+	PackageScore     = types.PackageScore
+	DependencyHealth = types.DependencyHealth
+	FreshnessStatus  = types.FreshnessStatus
+	Coverage         = types.Coverage
+	CoverageSummary  = types.CoverageSummary
+	CoverageReason   = types.CoverageReason
+	PackageCoverage  = types.PackageCoverage
+)
+
+// Finding types
+const (
+	FindingTypeMalware    = types.FindingTypeMalware
+	FindingTypeCVE        = types.FindingTypeCVE
+	FindingTypeTyposquat  = types.FindingTypeTyposquat
+	FindingTypeLicense    = types.FindingTypeLicense
+	FindingTypeMaintainer = types.FindingTypeMaintainer
+	FindingTypeQuality    = types.FindingTypeQuality
+
+	FindingTypeInstallScript = types.FindingTypeInstallScript
+	FindingTypeIntegrity     = types.FindingTypeIntegrity
+	FindingTypeProvenance    = types.FindingTypeProvenance
+	FindingTypeReleaseAge    = types.FindingTypeReleaseAge
+	FindingTypeLowScore      = types.FindingTypeLowScore
+)
+
+// Severities, most severe first
+const (
+	SeverityCritical = types.SeverityCritical
+	SeverityHigh     = types.SeverityHigh
+	SeverityMedium   = types.SeverityMedium
+	SeverityLow      = types.SeverityLow
+	SeverityInfo     = types.SeverityInfo
+)
+
+// Freshness statuses of a dependency
+const (
+	FreshnessFresh   = types.FreshnessFresh
+	FreshnessAging   = types.FreshnessAging
+	FreshnessStale   = types.FreshnessStale
+	FreshnessUnknown = types.FreshnessUnknown
+)
+
+// Coverage reasons, how a scanner handled a package
+const (
+	CoverageChecked     = types.CoverageChecked
+	CoverageCached      = types.CoverageCached
+	CoverageExcluded    = types.CoverageExcluded
+	CoverageDisabled    = types.CoverageDisabled
+	CoverageUnavailable = types.CoverageUnavailable
+	CoverageFailed      = types.CoverageFailed
+	CoverageUnsupported = types.CoverageUnsupported
+)
+
+var (
+	// FindingTypes lists every finding type
+	FindingTypes = types.FindingTypes
+
+	// SeverityOrder ranks a severity, 0 for the most severe
+	SeverityOrder = types.SeverityOrder
+)